@@ -612,6 +612,37 @@ func (hs *HTTPServer) declareFixedRoles() error {
 		publicDashboardsWriterRole, featuremgmtReaderRole, featuremgmtWriterRole, libraryPanelsCreatorRole,
 		libraryPanelsReaderRole, libraryPanelsWriterRole, libraryPanelsGeneralReaderRole, libraryPanelsGeneralWriterRole}
 
+	if hs.Features.IsEnabledGlobally(featuremgmt.FlagLivePipeline) {
+		livePipelineReaderRole := ac.RoleRegistration{
+			Role: ac.RoleDTO{
+				Name:        "fixed:live.pipeline:reader",
+				DisplayName: "Reader",
+				Description: "Read Live pipeline channel rules and write configs.",
+				Group:       "Live",
+				Permissions: []ac.Permission{
+					{Action: ac.ActionLivePipelineRead},
+				},
+			},
+			Grants: []string{string(org.RoleAdmin)},
+		}
+
+		livePipelineWriterRole := ac.RoleRegistration{
+			Role: ac.RoleDTO{
+				Name:        "fixed:live.pipeline:writer",
+				DisplayName: "Writer",
+				Description: "Create, update and delete Live pipeline channel rules and write configs.",
+				Group:       "Live",
+				Permissions: []ac.Permission{
+					{Action: ac.ActionLivePipelineRead},
+					{Action: ac.ActionLivePipelineWrite},
+				},
+			},
+			Grants: []string{string(org.RoleAdmin)},
+		}
+
+		roles = append(roles, livePipelineReaderRole, livePipelineWriterRole)
+	}
+
 	if hs.Features.IsEnabled(context.Background(), featuremgmt.FlagAnnotationPermissionUpdate) {
 		allAnnotationsReaderRole := ac.RoleRegistration{
 			Role: ac.RoleDTO{
@@ -557,6 +557,24 @@ func (hs *HTTPServer) registerRoutes() {
 
 			// Some channels may have info
 			liveRoute.Get("/info/*", routing.Wrap(hs.Live.HandleInfoHTTP))
+
+			if hs.Features.IsEnabledGlobally(featuremgmt.FlagLivePipeline) {
+				// POST data to be processed by the channel rule of the channel.
+				liveRoute.Post("/pipeline/push/*", authorize(ac.EvalPermission(ac.ActionLivePipelineWrite)), hs.LivePushGateway.HandlePipelinePush)
+				liveRoute.Post("/pipeline-convert-test", authorize(ac.EvalPermission(ac.ActionLivePipelineRead)), routing.Wrap(hs.Live.HandlePipelineConvertTestHTTP))
+				liveRoute.Get("/pipeline-entities", authorize(ac.EvalPermission(ac.ActionLivePipelineRead)), routing.Wrap(hs.Live.HandlePipelineEntitiesListHTTP))
+
+				liveRoute.Get("/channel-rules", authorize(ac.EvalPermission(ac.ActionLivePipelineRead)), routing.Wrap(hs.Live.HandleChannelRulesListHTTP))
+				liveRoute.Get("/channel-rules/versions", authorize(ac.EvalPermission(ac.ActionLivePipelineRead)), routing.Wrap(hs.Live.HandleChannelRuleVersionsListHTTP))
+				liveRoute.Post("/channel-rules", authorize(ac.EvalPermission(ac.ActionLivePipelineWrite)), routing.Wrap(hs.Live.HandleChannelRulesPostHTTP))
+				liveRoute.Put("/channel-rules", authorize(ac.EvalPermission(ac.ActionLivePipelineWrite)), routing.Wrap(hs.Live.HandleChannelRulesPutHTTP))
+				liveRoute.Delete("/channel-rules", authorize(ac.EvalPermission(ac.ActionLivePipelineWrite)), routing.Wrap(hs.Live.HandleChannelRulesDeleteHTTP))
+
+				liveRoute.Get("/write-configs", authorize(ac.EvalPermission(ac.ActionLivePipelineRead)), routing.Wrap(hs.Live.HandleWriteConfigsListHTTP))
+				liveRoute.Post("/write-configs", authorize(ac.EvalPermission(ac.ActionLivePipelineWrite)), routing.Wrap(hs.Live.HandleWriteConfigsPostHTTP))
+				liveRoute.Put("/write-configs", authorize(ac.EvalPermission(ac.ActionLivePipelineWrite)), routing.Wrap(hs.Live.HandleWriteConfigsPutHTTP))
+				liveRoute.Delete("/write-configs", authorize(ac.EvalPermission(ac.ActionLivePipelineWrite)), routing.Wrap(hs.Live.HandleWriteConfigsDeleteHTTP))
+			}
 		}, requestmeta.SetSLOGroup(requestmeta.SLOGroupNone))
 
 		// short urls
@@ -79,6 +79,13 @@ type MetricRequest struct {
 	Queries []*simplejson.Json `json:"queries"`
 	// required: false
 	Debug bool `json:"debug"`
+	// Explain returns the intermediate results and execution time of each query and expression
+	// in a response with the refId `_explain` when the request contains expressions.
+	// required: false
+	Explain bool `json:"explain"`
+	// ExplainHidden includes the hidden queries and expressions in the explanation.
+	// required: false
+	ExplainHidden bool `json:"explainHidden"`
 }
 
 func (mr *MetricRequest) GetUniqueDatasourceTypes() []string {
@@ -102,10 +109,12 @@ func (mr *MetricRequest) GetUniqueDatasourceTypes() []string {
 
 func (mr *MetricRequest) CloneWithQueries(queries []*simplejson.Json) MetricRequest {
 	return MetricRequest{
-		From:    mr.From,
-		To:      mr.To,
-		Queries: queries,
-		Debug:   mr.Debug,
+		From:          mr.From,
+		To:            mr.To,
+		Queries:       queries,
+		Debug:         mr.Debug,
+		Explain:       mr.Explain,
+		ExplainHidden: mr.ExplainHidden,
 	}
 }
 
@@ -0,0 +1,372 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// AnomalyBandLabel is the label added to the band series returned by AnomalyCommand. Its value is "upper" or "lower".
+// The score series keeps the labels of the input series, so it can be reduced and used in a threshold directly.
+const AnomalyBandLabel = "anomaly_band"
+
+const (
+	defaultAnomalySensitivity = 3.0
+	defaultHoltWintersAlpha   = 0.5
+	defaultHoltWintersBeta    = 0.1
+	defaultHoltWintersGamma   = 0.1
+
+	// madScale makes the median absolute deviation comparable to the standard deviation of normally distributed data.
+	madScale = 1.4826
+)
+
+// AnomalyCommand detects anomalies in the series of another query or expression.
+// For each input series it returns a score series, which is the distance of each point from the baseline in standard deviations,
+// and the upper and lower bands of the expected values.
+type AnomalyCommand struct {
+	RefID       string
+	VarToDetect string
+	Algorithm   AnomalyAlgorithm
+	Window      time.Duration
+	Season      time.Duration
+	Sensitivity float64
+	Alpha       float64
+	Beta        float64
+	Gamma       float64
+	Output      AnomalyOutput
+}
+
+// NewAnomalyCommand creates a new AnomalyCommand.
+func NewAnomalyCommand(refID string, q AnomalyQuery) (*AnomalyCommand, error) {
+	varToDetect, err := getReferenceVar(q.Expression, refID)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &AnomalyCommand{
+		RefID:       refID,
+		VarToDetect: varToDetect,
+		Algorithm:   q.Algorithm,
+		Sensitivity: defaultAnomalySensitivity,
+		Alpha:       defaultHoltWintersAlpha,
+		Beta:        defaultHoltWintersBeta,
+		Gamma:       defaultHoltWintersGamma,
+		Output:      q.Output,
+	}
+	if cmd.Output == "" {
+		cmd.Output = AnomalyOutputAll
+	}
+	switch cmd.Output {
+	case AnomalyOutputAll, AnomalyOutputScore, AnomalyOutputBands:
+	default:
+		return nil, fmt.Errorf("unsupported anomaly output '%s', expected one of [%s, %s, %s]", q.Output, AnomalyOutputAll, AnomalyOutputScore, AnomalyOutputBands)
+	}
+
+	if q.Season != "" {
+		cmd.Season, err = gtime.ParseDuration(q.Season)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse anomaly season '%v': %w", q.Season, err)
+		}
+		if cmd.Season <= 0 {
+			return nil, fmt.Errorf("anomaly season must be positive, got '%v'", q.Season)
+		}
+	}
+
+	switch q.Algorithm {
+	case AnomalyZScore, AnomalyMAD:
+		if q.Window == "" {
+			return nil, fmt.Errorf("window is required by the anomaly algorithm '%s'", q.Algorithm)
+		}
+		cmd.Window, err = gtime.ParseDuration(q.Window)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse anomaly window '%v': %w", q.Window, err)
+		}
+		if cmd.Window <= 0 {
+			return nil, fmt.Errorf("anomaly window must be positive, got '%v'", q.Window)
+		}
+	case AnomalyHoltWinters:
+		for _, p := range []struct {
+			name  string
+			value *float64
+			dst   *float64
+		}{{"alpha", q.Alpha, &cmd.Alpha}, {"beta", q.Beta, &cmd.Beta}, {"gamma", q.Gamma, &cmd.Gamma}} {
+			if p.value == nil {
+				continue
+			}
+			if *p.value < 0 || *p.value > 1 {
+				return nil, fmt.Errorf("%s must be between 0 and 1, got %v", p.name, *p.value)
+			}
+			*p.dst = *p.value
+		}
+	default:
+		return nil, fmt.Errorf("unsupported anomaly algorithm '%s', expected one of [%s, %s, %s]", q.Algorithm, AnomalyZScore, AnomalyMAD, AnomalyHoltWinters)
+	}
+
+	if q.Sensitivity != nil {
+		if *q.Sensitivity <= 0 {
+			return nil, fmt.Errorf("sensitivity must be greater than 0, got %v", *q.Sensitivity)
+		}
+		cmd.Sensitivity = *q.Sensitivity
+	}
+	return cmd, nil
+}
+
+// UnmarshalAnomalyCommand creates an AnomalyCommand from Grafana's frontend query.
+func UnmarshalAnomalyCommand(rn *rawNode) (*AnomalyCommand, error) {
+	q := AnomalyQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the anomaly command: %w", err)
+	}
+	return NewAnomalyCommand(rn.RefID, q)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ac *AnomalyCommand) NeedsVars() []string {
+	return []string{ac.VarToDetect}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ac *AnomalyCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteAnomaly")
+	defer span.End()
+	span.SetAttributes(attribute.String("algorithm", string(ac.Algorithm)))
+
+	newRes := mathexp.Results{}
+	for _, val := range vars[ac.VarToDetect].Values {
+		if val == nil {
+			continue
+		}
+		switch v := val.(type) {
+		case mathexp.Series:
+			newRes.Values = append(newRes.Values, ac.detect(v)...)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+			return newRes, nil
+		default:
+			return newRes, fmt.Errorf("can only detect anomalies in type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+func (ac *AnomalyCommand) Type() string {
+	return TypeAnomaly.String()
+}
+
+// band is the expected value of a point and the spread around it.
+type band struct {
+	center float64
+	spread float64
+}
+
+// detect returns the score, upper and lower band series of s according to the output of the command.
+func (ac *AnomalyCommand) detect(s mathexp.Series) mathexp.Values {
+	points := sortedPoints(s)
+	var bands []*band
+	switch ac.Algorithm {
+	case AnomalyZScore, AnomalyMAD:
+		bands = ac.baselineBands(points)
+	case AnomalyHoltWinters:
+		bands = ac.holtWintersBands(points)
+	}
+
+	labels := s.GetLabels()
+	score := mathexp.NewSeries(ac.RefID, labels, len(points))
+	upperLabels, lowerLabels := labels.Copy(), labels.Copy()
+	if upperLabels == nil {
+		upperLabels, lowerLabels = map[string]string{}, map[string]string{}
+	}
+	upperLabels[AnomalyBandLabel] = "upper"
+	lowerLabels[AnomalyBandLabel] = "lower"
+	upper := mathexp.NewSeries(ac.RefID, upperLabels, len(points))
+	lower := mathexp.NewSeries(ac.RefID, lowerLabels, len(points))
+
+	for i, p := range points {
+		var sc, up, lo *float64
+		if b := bands[i]; b != nil {
+			u, l := b.center+ac.Sensitivity*b.spread, b.center-ac.Sensitivity*b.spread
+			up, lo = &u, &l
+			if mathexp.HasValue(p.value) {
+				f := anomalyScore(*p.value, *b)
+				sc = &f
+			}
+		}
+		score.SetPoint(i, p.time, sc)
+		upper.SetPoint(i, p.time, up)
+		lower.SetPoint(i, p.time, lo)
+	}
+
+	switch ac.Output {
+	case AnomalyOutputScore:
+		return mathexp.Values{score}
+	case AnomalyOutputBands:
+		return mathexp.Values{upper, lower}
+	default:
+		return mathexp.Values{score, upper, lower}
+	}
+}
+
+// anomalyScore is the distance of v from the center of the band in units of spread.
+func anomalyScore(v float64, b band) float64 {
+	d := math.Abs(v - b.center)
+	if b.spread == 0 {
+		if d == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return d / b.spread
+}
+
+// baselineBands calculates the band of each point from the values of the points in its baseline windows.
+func (ac *AnomalyCommand) baselineBands(points []point) []*band {
+	bands := make([]*band, len(points))
+	baseline := make([]float64, 0)
+	for i, p := range points {
+		baseline = baseline[:0]
+		add := func(from, to time.Time) {
+			// points in (from, to), excluding the point itself
+			start := sort.Search(len(points), func(j int) bool { return points[j].time.After(from) })
+			for j := start; j < len(points) && points[j].time.Before(to); j++ {
+				if mathexp.HasValue(points[j].value) {
+					baseline = append(baseline, *points[j].value)
+				}
+			}
+		}
+		if ac.Season == 0 {
+			add(p.time.Add(-ac.Window), p.time)
+		} else {
+			for center := p.time.Add(-ac.Season); !center.Add(ac.Window / 2).Before(points[0].time); center = center.Add(-ac.Season) {
+				add(center.Add(-ac.Window/2), center.Add(ac.Window/2))
+			}
+		}
+		if len(baseline) < 2 {
+			continue
+		}
+		if ac.Algorithm == AnomalyMAD {
+			m := reduceValues(mathexp.Median, baseline)
+			deviations := make([]float64, len(baseline))
+			for j, v := range baseline {
+				deviations[j] = math.Abs(v - m)
+			}
+			bands[i] = &band{center: m, spread: madScale * reduceValues(mathexp.Median, deviations)}
+		} else {
+			bands[i] = &band{center: reduceValues(mathexp.Avg, baseline), spread: reduceValues(mathexp.StdDev, baseline)}
+		}
+	}
+	return bands
+}
+
+// holtWintersBands calculates the band of each point as the Holt-Winters forecast plus/minus the root mean square
+// of the previous forecast errors. Without a season, double exponential smoothing is used.
+// Points without a value are skipped.
+func (ac *AnomalyCommand) holtWintersBands(points []point) []*band {
+	bands := make([]*band, len(points))
+	idx := make([]int, 0, len(points))
+	for i, p := range points {
+		if mathexp.HasValue(p.value) {
+			idx = append(idx, i)
+		}
+	}
+	value := func(i int) float64 { return *points[idx[i]].value }
+
+	seasonLen := 0
+	if ac.Season > 0 && len(idx) > 1 {
+		deltas := make([]float64, 0, len(idx)-1)
+		for i := 1; i < len(idx); i++ {
+			deltas = append(deltas, float64(points[idx[i]].time.Sub(points[idx[i-1]].time)))
+		}
+		if step := reduceValues(mathexp.Median, deltas); step > 0 {
+			seasonLen = int(math.Round(float64(ac.Season) / step))
+		}
+	}
+
+	var level, trend float64
+	var seasonal []float64
+	start := 0
+	if seasonLen >= 2 {
+		// two full seasons are needed to estimate the initial trend
+		if len(idx) < 2*seasonLen+1 {
+			return bands
+		}
+		var first, second float64
+		for i := 0; i < seasonLen; i++ {
+			first += value(i)
+			second += value(i + seasonLen)
+		}
+		first, second = first/float64(seasonLen), second/float64(seasonLen)
+		level = first
+		trend = (second - first) / float64(seasonLen)
+		seasonal = make([]float64, seasonLen)
+		for i := 0; i < seasonLen; i++ {
+			seasonal[i] = value(i) - first
+		}
+		start = seasonLen
+	} else {
+		if len(idx) < 3 {
+			return bands
+		}
+		level = value(0)
+		trend = value(1) - value(0)
+		start = 1
+	}
+
+	var sumSquares float64
+	var count int
+	for i := start; i < len(idx); i++ {
+		forecast := level + trend
+		s := 0.0
+		if seasonal != nil {
+			s = seasonal[i%seasonLen]
+			forecast += s
+		}
+		v := value(i)
+		if count >= 2 {
+			bands[idx[i]] = &band{center: forecast, spread: math.Sqrt(sumSquares / float64(count))}
+		}
+		residual := v - forecast
+		sumSquares += residual * residual
+		count++
+
+		prevLevel := level
+		level = ac.Alpha*(v-s) + (1-ac.Alpha)*(level+trend)
+		trend = ac.Beta*(level-prevLevel) + (1-ac.Beta)*trend
+		if seasonal != nil {
+			seasonal[i%seasonLen] = ac.Gamma*(v-level) + (1-ac.Gamma)*s
+		}
+	}
+	return bands
+}
+
+type point struct {
+	time  time.Time
+	value *float64
+}
+
+// sortedPoints returns the points of the series sorted by time.
+func sortedPoints(s mathexp.Series) []point {
+	s = s.SortedByTime()
+	points := make([]point, s.Len())
+	for i := range points {
+		t, v := s.GetPoint(i)
+		points[i] = point{time: t, value: v}
+	}
+	return points
+}
+
+// reduceValues applies the reducer to the values.
+func reduceValues(rFunc mathexp.ReducerFunc, values []float64) float64 {
+	fv := mathexp.Float64Field(*data.NewField("", nil, values))
+	return *rFunc(&fv)
+}
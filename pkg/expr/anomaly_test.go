@@ -0,0 +1,200 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestNewAnomalyCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   AnomalyQuery
+		isError bool
+	}{
+		{
+			name:  "zscore with window",
+			query: AnomalyQuery{Expression: "$A", Algorithm: AnomalyZScore, Window: "1h"},
+		},
+		{
+			name:  "mad with season",
+			query: AnomalyQuery{Expression: "A", Algorithm: AnomalyMAD, Window: "1h", Season: "1d"},
+		},
+		{
+			name:  "holt winters without window",
+			query: AnomalyQuery{Expression: "$A", Algorithm: AnomalyHoltWinters, Alpha: util.Pointer(0.3)},
+		},
+		{
+			name:    "missing expression",
+			query:   AnomalyQuery{Algorithm: AnomalyZScore, Window: "1h"},
+			isError: true,
+		},
+		{
+			name:    "unknown algorithm",
+			query:   AnomalyQuery{Expression: "$A", Algorithm: "prophet"},
+			isError: true,
+		},
+		{
+			name:    "zscore without window",
+			query:   AnomalyQuery{Expression: "$A", Algorithm: AnomalyZScore},
+			isError: true,
+		},
+		{
+			name:    "invalid season",
+			query:   AnomalyQuery{Expression: "$A", Algorithm: AnomalyHoltWinters, Season: "daily"},
+			isError: true,
+		},
+		{
+			name:    "smoothing factor out of range",
+			query:   AnomalyQuery{Expression: "$A", Algorithm: AnomalyHoltWinters, Beta: util.Pointer(1.5)},
+			isError: true,
+		},
+		{
+			name:    "sensitivity is not positive",
+			query:   AnomalyQuery{Expression: "$A", Algorithm: AnomalyZScore, Window: "1h", Sensitivity: util.Pointer(0.0)},
+			isError: true,
+		},
+		{
+			name:    "unknown output",
+			query:   AnomalyQuery{Expression: "$A", Algorithm: AnomalyZScore, Window: "1h", Output: "forecast"},
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := NewAnomalyCommand("B", tt.query)
+			if tt.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []string{"A"}, cmd.NeedsVars())
+		})
+	}
+}
+
+func TestUnmarshalAnomalyCommand(t *testing.T) {
+	raw := []byte(`{"type": "anomaly", "expression": "$A", "algorithm": "mad", "window": "30m", "sensitivity": 2, "output": "score"}`)
+	q := map[string]any{}
+	require.NoError(t, json.Unmarshal(raw, &q))
+
+	cmd, err := UnmarshalAnomalyCommand(&rawNode{RefID: "B", Query: q, QueryRaw: raw})
+	require.NoError(t, err)
+	require.Equal(t, AnomalyMAD, cmd.Algorithm)
+	require.Equal(t, 30*time.Minute, cmd.Window)
+	require.Equal(t, 2.0, cmd.Sensitivity)
+	require.Equal(t, AnomalyOutputScore, cmd.Output)
+}
+
+func TestAnomalyCommandExecute(t *testing.T) {
+	start := time.Unix(0, 0)
+	makeSeries := func(values ...float64) mathexp.Series {
+		s := mathexp.NewSeries("A", data.Labels{"host": "a"}, len(values))
+		for i, v := range values {
+			s.SetPoint(i, start.Add(time.Duration(i)*time.Minute), util.Pointer(v))
+		}
+		return s
+	}
+	execute := func(t *testing.T, q AnomalyQuery, vals ...mathexp.Value) mathexp.Results {
+		t.Helper()
+		q.Expression = "$A"
+		cmd, err := NewAnomalyCommand("B", q)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{"A": mathexp.Results{Values: vals}}, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("zscore flags the outlier", func(t *testing.T) {
+		res := execute(t, AnomalyQuery{Algorithm: AnomalyZScore, Window: "5m"}, makeSeries(10, 12, 10, 12, 10, 12, 50, 12))
+		require.Len(t, res.Values, 3)
+
+		score := res.Values[0].(mathexp.Series)
+		require.Equal(t, data.Labels{"host": "a"}, score.GetLabels())
+		// not enough points in the baseline
+		require.Nil(t, score.GetValue(0))
+		require.Nil(t, score.GetValue(1))
+		// 10 and 12 have a mean of 11 and a standard deviation of 1
+		require.InDelta(t, 1, *score.GetValue(2), 1e-9)
+		require.InDelta(t, 39, *score.GetValue(6), 1e-9)
+
+		upper := res.Values[1].(mathexp.Series)
+		lower := res.Values[2].(mathexp.Series)
+		require.Equal(t, data.Labels{"host": "a", AnomalyBandLabel: "upper"}, upper.GetLabels())
+		require.Equal(t, data.Labels{"host": "a", AnomalyBandLabel: "lower"}, lower.GetLabels())
+		require.InDelta(t, 14, *upper.GetValue(2), 1e-9)
+		require.InDelta(t, 8, *lower.GetValue(2), 1e-9)
+	})
+
+	t.Run("mad is robust to outliers in the baseline", func(t *testing.T) {
+		res := execute(t, AnomalyQuery{Algorithm: AnomalyMAD, Window: "10m", Output: AnomalyOutputScore}, makeSeries(10, 11, 1000, 10, 11, 10, 11))
+		require.Len(t, res.Values, 1)
+		score := res.Values[0].(mathexp.Series)
+		require.Greater(t, *score.GetValue(2), 3.0)
+		require.Less(t, *score.GetValue(6), 3.0)
+	})
+
+	t.Run("seasonal baseline uses the same time of previous seasons", func(t *testing.T) {
+		// a season of 4 points where the third point is always high
+		res := execute(t, AnomalyQuery{Algorithm: AnomalyZScore, Window: "1m", Season: "4m", Output: AnomalyOutputScore},
+			makeSeries(1, 1, 10, 1, 2, 1, 12, 2, 1, 1, 11, 30))
+		score := res.Values[0].(mathexp.Series)
+		require.Nil(t, score.GetValue(6))
+		require.InDelta(t, 0, *score.GetValue(10), 1e-9)
+		// the high value at the end of the season is unexpected
+		require.Greater(t, *score.GetValue(11), 3.0)
+	})
+
+	t.Run("holt winters follows the trend", func(t *testing.T) {
+		values := make([]float64, 30)
+		for i := range values {
+			values[i] = float64(i*2) + float64(i%2)
+		}
+		values[25] = 200
+		res := execute(t, AnomalyQuery{Algorithm: AnomalyHoltWinters}, makeSeries(values...))
+		score := res.Values[0].(mathexp.Series)
+		upper := res.Values[1].(mathexp.Series)
+		require.Nil(t, score.GetValue(0))
+		require.Less(t, *score.GetValue(20), 3.0)
+		require.Greater(t, *score.GetValue(25), 3.0)
+		require.Greater(t, *upper.GetValue(20), values[20])
+	})
+
+	t.Run("holt winters with season", func(t *testing.T) {
+		values := make([]float64, 40)
+		for i := range values {
+			values[i] = []float64{1, 5, 9, 5}[i%4]
+		}
+		values[38] = 1
+		res := execute(t, AnomalyQuery{Algorithm: AnomalyHoltWinters, Season: "4m", Output: AnomalyOutputScore}, makeSeries(values...))
+		score := res.Values[0].(mathexp.Series)
+		require.Nil(t, score.GetValue(5))
+		require.InDelta(t, 0, *score.GetValue(30), 0.5)
+		require.Greater(t, *score.GetValue(38), 3.0)
+	})
+
+	t.Run("returns no data when input is no data", func(t *testing.T) {
+		res := execute(t, AnomalyQuery{Algorithm: AnomalyZScore, Window: "5m"}, mathexp.NoData{}.New())
+		require.Len(t, res.Values, 1)
+		require.Equal(t, parse.TypeNoData, res.Values[0].Type())
+	})
+
+	t.Run("fails when input is a number", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", AnomalyQuery{Expression: "$A", Algorithm: AnomalyZScore, Window: "5m"})
+		require.NoError(t, err)
+		_, err = cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNumber("A", nil)}},
+		}, tracing.InitializeTracerForTest())
+		require.Error(t, err)
+	})
+}
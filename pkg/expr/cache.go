@@ -0,0 +1,333 @@
+package expr
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// queryCache caches the frames returned by data source queries, so that identical queries of different
+// requests, e.g. alert rules evaluated in the same tick, only query the data source once.
+// The frames are stored encoded with Arrow, so that each request gets its own copy that it can modify.
+type queryCache struct {
+	ttl       time.Duration
+	maxBytes  int64
+	alignment time.Duration
+	timeout   time.Duration
+	metrics   *metrics
+	now       func() time.Time
+
+	mu      sync.Mutex
+	size    int64
+	entries map[string]*list.Element
+	lru     *list.List
+
+	inflight singleflight.Group
+}
+
+type queryCacheEntry struct {
+	key     string
+	frames  [][]byte
+	size    int64
+	expires time.Time
+}
+
+// newQueryCache returns the cache configured for data source queries, or nil if it is disabled.
+func newQueryCache(cfg *setting.Cfg, m *metrics) *queryCache {
+	if cfg == nil || !cfg.ExpressionsQueryCacheEnabled || cfg.ExpressionsQueryCacheTTL <= 0 || cfg.ExpressionsQueryCacheMaxBytes <= 0 {
+		return nil
+	}
+	return &queryCache{
+		ttl:       cfg.ExpressionsQueryCacheTTL,
+		maxBytes:  cfg.ExpressionsQueryCacheMaxBytes,
+		alignment: cfg.ExpressionsQueryCacheTimeAlignment,
+		timeout:   cfg.ExpressionsQueryCacheQueryTimeout,
+		metrics:   m,
+		now:       time.Now,
+		entries:   make(map[string]*list.Element),
+		lru:       list.New(),
+	}
+}
+
+// key returns the fingerprint of the query of a node. It returns false if the query cannot be cached.
+// The time range is aligned, so that queries with almost the same time range share the cached frames.
+func (c *queryCache) key(dn *DSNode, now time.Time) (string, bool) {
+	if c == nil || dn.datasource == nil {
+		return "", false
+	}
+
+	// the refID is not part of the key, so that the same query can be shared by requests with different refIDs.
+	// The query is marshaled again to sort the keys of the model.
+	model := map[string]any{}
+	if err := json.Unmarshal(dn.query, &model); err != nil {
+		return "", false
+	}
+	delete(model, "refId")
+	query, err := json.Marshal(model)
+	if err != nil {
+		return "", false
+	}
+
+	var user string
+	if dn.request.User != nil {
+		user = dn.request.User.GetCacheKey()
+	}
+
+	tr := dn.timeRange.AbsoluteTime(now)
+	from, to := tr.From, tr.To
+	if c.alignment > 0 {
+		from, to = from.Truncate(c.alignment), to.Truncate(c.alignment)
+	}
+
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%d\x00%s\x00%d\x00%s\x00%s\x00%d\x00%d\x00%d\x00%d\x00",
+		dn.orgID, dn.datasource.UID, dn.datasource.Version, user, dn.queryType, dn.intervalMS, dn.maxDP, from.UnixNano(), to.UnixNano())
+	// the headers are forwarded to the data source, e.g. for OAuth pass-through, and can change what it returns
+	headers := make([]string, 0, len(dn.request.Headers))
+	for name := range dn.request.Headers {
+		headers = append(headers, name)
+	}
+	sort.Strings(headers)
+	for _, name := range headers {
+		_, _ = fmt.Fprintf(h, "%s\x00%s\x00", name, dn.request.Headers[name])
+	}
+	_, _ = h.Write(query)
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// queryFrames queries the data source for the frames of a node, or returns them from the cache if it is enabled.
+func (s *Service) queryFrames(ctx context.Context, logger *log.ConcreteLogger, dn *DSNode, now time.Time, req *backend.QueryDataRequest) (data.Frames, error) {
+	query := func(ctx context.Context) (data.Frames, error) {
+		resp, err := s.dataService.QueryData(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return getResponseFrame(logger, resp, dn.refID)
+	}
+	key, ok := s.queryCache.key(dn, now)
+	if !ok {
+		return query(ctx)
+	}
+	return s.queryCache.fetch(ctx, key, dn.refID, query)
+}
+
+// get returns a copy of the cached frames for the key, with the refID set to refID.
+func (c *queryCache) get(key, refID string) (data.Frames, bool) {
+	c.mu.Lock()
+	var encoded [][]byte
+	if e, ok := c.entries[key]; ok {
+		entry := e.Value.(*queryCacheEntry)
+		if c.now().Before(entry.expires) {
+			c.lru.MoveToFront(e)
+			encoded = entry.frames
+		} else {
+			c.remove(e)
+		}
+	}
+	c.mu.Unlock()
+
+	if encoded == nil {
+		c.metrics.queryCacheRequests.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+	frames, err := decodeFrames(encoded, refID)
+	if err != nil {
+		logger.Warn("Failed to decode cached frames", "error", err)
+		c.metrics.queryCacheRequests.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+	c.metrics.queryCacheRequests.WithLabelValues("hit").Inc()
+	return frames, true
+}
+
+// fetch returns the cached frames for the key, or queries them with the query function and caches them.
+// Concurrent fetches of the same key wait for a single query.
+func (c *queryCache) fetch(ctx context.Context, key, refID string, query func(context.Context) (data.Frames, error)) (data.Frames, error) {
+	if frames, ok := c.get(key, refID); ok {
+		return frames, nil
+	}
+	encoded, err := c.share(ctx, key, func(ctx context.Context) (any, error) {
+		frames, err := query(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return c.store(key, frames)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decodeFrames(encoded.([][]byte), refID)
+}
+
+// queryResult is the result of a single query of a request with several queries.
+type queryResult struct {
+	frames data.Frames
+	err    error
+}
+
+type encodedResult struct {
+	encoded [][]byte
+	err     error
+}
+
+// fetchGroup queries the frames of several queries, given as the cache keys by refID, in a single call of the
+// query function and caches them. The query function gets one refID for each distinct key.
+// Concurrent fetches of the same keys wait for a single query.
+func (c *queryCache) fetchGroup(ctx context.Context, keys map[string]string, query func(ctx context.Context, refIDs []string) (map[string]queryResult, error)) (map[string]queryResult, error) {
+	refIDByKey := make(map[string]string, len(keys))
+	for refID, key := range keys {
+		if other, ok := refIDByKey[key]; !ok || refID < other {
+			refIDByKey[key] = refID
+		}
+	}
+	distinct := make([]string, 0, len(refIDByKey))
+	for key := range refIDByKey {
+		distinct = append(distinct, key)
+	}
+	sort.Strings(distinct)
+
+	shared, err := c.share(ctx, strings.Join(distinct, ","), func(ctx context.Context) (any, error) {
+		refIDs := make([]string, 0, len(distinct))
+		for _, key := range distinct {
+			refIDs = append(refIDs, refIDByKey[key])
+		}
+		results, err := query(ctx, refIDs)
+		if err != nil {
+			return nil, err
+		}
+		encodedByKey := make(map[string]encodedResult, len(distinct))
+		for _, key := range distinct {
+			r := results[refIDByKey[key]]
+			if r.err != nil {
+				encodedByKey[key] = encodedResult{err: r.err}
+				continue
+			}
+			encoded, err := c.store(key, r.frames)
+			encodedByKey[key] = encodedResult{encoded: encoded, err: err}
+		}
+		return encodedByKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	encodedByKey := shared.(map[string]encodedResult)
+	results := make(map[string]queryResult, len(keys))
+	for refID, key := range keys {
+		r := encodedByKey[key]
+		if r.err != nil {
+			results[refID] = queryResult{err: r.err}
+			continue
+		}
+		frames, err := decodeFrames(r.encoded, refID)
+		results[refID] = queryResult{frames: frames, err: err}
+	}
+	return results, nil
+}
+
+// share runs fn once for concurrent calls with the same key. Since other calls wait for it, fn does not
+// get cancelled with the context of the call that runs it, but it has its own timeout.
+// Each call stops waiting when its own context is done.
+func (c *queryCache) share(ctx context.Context, key string, fn func(context.Context) (any, error)) (any, error) {
+	ch := c.inflight.DoChan(key, func() (any, error) {
+		ctx := context.WithoutCancel(ctx)
+		if c.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+		}
+		return fn(ctx)
+	})
+	select {
+	case res := <-ch:
+		return res.Val, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// store encodes the frames and caches them for the key. It returns the encoded frames, or an error
+// if they cannot be encoded.
+func (c *queryCache) store(key string, frames data.Frames) ([][]byte, error) {
+	encoded, err := encodeFrames(frames)
+	if err != nil {
+		return nil, err
+	}
+	c.set(key, encoded)
+	return encoded, nil
+}
+
+func (c *queryCache) set(key string, encoded [][]byte) {
+	var size int64
+	for _, b := range encoded {
+		size += int64(len(b))
+	}
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+	c.entries[key] = c.lru.PushFront(&queryCacheEntry{
+		key:     key,
+		frames:  encoded,
+		size:    size,
+		expires: c.now().Add(c.ttl),
+	})
+	c.size += size
+	for c.size > c.maxBytes {
+		c.remove(c.lru.Back())
+		c.metrics.queryCacheEvictions.Inc()
+	}
+	c.metrics.queryCacheBytes.Set(float64(c.size))
+}
+
+// remove removes an element from the cache. The caller must hold the lock.
+func (c *queryCache) remove(e *list.Element) {
+	entry := c.lru.Remove(e).(*queryCacheEntry)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+	c.metrics.queryCacheBytes.Set(float64(c.size))
+}
+
+func encodeFrames(frames data.Frames) ([][]byte, error) {
+	encoded := make([][]byte, 0, len(frames))
+	for _, frame := range frames {
+		b, err := frame.MarshalArrow()
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, b)
+	}
+	return encoded, nil
+}
+
+func decodeFrames(encoded [][]byte, refID string) (data.Frames, error) {
+	frames := make(data.Frames, 0, len(encoded))
+	for _, b := range encoded {
+		frame, err := data.UnmarshalArrowFrame(b)
+		if err != nil {
+			return nil, err
+		}
+		frame.RefID = refID
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
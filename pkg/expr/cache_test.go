@@ -0,0 +1,369 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	datafakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginconfig"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func newTestQueryCache(t *testing.T, maxBytes int64) *queryCache {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.ExpressionsQueryCacheEnabled = true
+	cfg.ExpressionsQueryCacheTTL = time.Minute
+	cfg.ExpressionsQueryCacheMaxBytes = maxBytes
+	cfg.ExpressionsQueryCacheTimeAlignment = 10 * time.Second
+	c := newQueryCache(cfg, newMetrics(nil))
+	require.NotNil(t, c)
+	return c
+}
+
+func TestNewQueryCache(t *testing.T) {
+	require.Nil(t, newQueryCache(nil, newMetrics(nil)))
+	require.Nil(t, newQueryCache(setting.NewCfg(), newMetrics(nil)))
+
+	var c *queryCache
+	_, ok := c.key(&DSNode{}, time.Now())
+	require.False(t, ok)
+}
+
+func TestQueryCacheKey(t *testing.T) {
+	c := newTestQueryCache(t, 1024)
+	now := time.Unix(1000, 0)
+	node := func(refID, query string, from time.Duration) *DSNode {
+		return &DSNode{
+			baseNode:   baseNode{refID: refID},
+			query:      json.RawMessage(query),
+			datasource: &datasources.DataSource{UID: "ds", Type: "test"},
+			orgID:      1,
+			timeRange:  RelativeTimeRange{From: from},
+		}
+	}
+	key := func(dn *DSNode) string {
+		k, ok := c.key(dn, now)
+		require.True(t, ok)
+		return k
+	}
+
+	base := key(node("A", `{"refId": "A", "expr": "up", "range": true}`, -time.Hour))
+	require.Equal(t, base, key(node("B", `{"range": true, "expr": "up", "refId": "B"}`, -time.Hour)), "refId and order of the model must be ignored")
+	require.Equal(t, base, key(node("A", `{"expr": "up", "range": true}`, -time.Hour+5*time.Second)), "time range must be aligned")
+	require.NotEqual(t, base, key(node("A", `{"expr": "down", "range": true}`, -time.Hour)))
+	require.NotEqual(t, base, key(node("A", `{"expr": "up", "range": true}`, -2*time.Hour)))
+
+	other := node("A", `{"expr": "up", "range": true}`, -time.Hour)
+	other.datasource = &datasources.DataSource{UID: "other", Type: "test"}
+	require.NotEqual(t, base, key(other))
+
+	withHeaders := node("A", `{"expr": "up", "range": true}`, -time.Hour)
+	withHeaders.request.Headers = map[string]string{"Authorization": "Bearer a"}
+	require.NotEqual(t, base, key(withHeaders), "forwarded headers must be part of the key")
+	withOtherHeaders := node("A", `{"expr": "up", "range": true}`, -time.Hour)
+	withOtherHeaders.request.Headers = map[string]string{"Authorization": "Bearer b"}
+	require.NotEqual(t, key(withHeaders), key(withOtherHeaders))
+
+	_, ok := c.key(node("A", `not json`, -time.Hour), now)
+	require.False(t, ok)
+}
+
+func TestQueryCacheGetSet(t *testing.T) {
+	store := func(t *testing.T, c *queryCache, key string, frames data.Frames) {
+		t.Helper()
+		_, err := c.store(key, frames)
+		require.NoError(t, err)
+	}
+	frame := func(v float64) data.Frames {
+		return data.Frames{data.NewFrame("",
+			data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+			data.NewField("value", data.Labels{"host": "a"}, []*float64{fp(v)}))}
+	}
+
+	t.Run("returns a copy of the frames with the refID", func(t *testing.T) {
+		c := newTestQueryCache(t, 1<<20)
+		_, ok := c.get("k", "A")
+		require.False(t, ok)
+
+		store(t, c, "k", frame(1))
+		got, ok := c.get("k", "B")
+		require.True(t, ok)
+		require.Len(t, got, 1)
+		require.Equal(t, "B", got[0].RefID)
+		require.Equal(t, 1.0, *got[0].Fields[1].At(0).(*float64))
+
+		got[0].Fields[1].Set(0, fp(2))
+		again, _ := c.get("k", "B")
+		require.Equal(t, 1.0, *again[0].Fields[1].At(0).(*float64))
+
+		require.Equal(t, 2.0, testutil.ToFloat64(c.metrics.queryCacheRequests.WithLabelValues("hit")))
+		require.Equal(t, 1.0, testutil.ToFloat64(c.metrics.queryCacheRequests.WithLabelValues("miss")))
+	})
+
+	t.Run("entries expire after the ttl", func(t *testing.T) {
+		c := newTestQueryCache(t, 1<<20)
+		now := time.Unix(0, 0)
+		c.now = func() time.Time { return now }
+		store(t, c, "k", frame(1))
+		now = now.Add(time.Minute)
+		_, ok := c.get("k", "A")
+		require.False(t, ok)
+		require.Zero(t, c.size)
+	})
+
+	t.Run("least recently used entries are evicted", func(t *testing.T) {
+		encoded, err := encodeFrames(frame(1))
+		require.NoError(t, err)
+		size := int64(len(encoded[0]))
+
+		c := newTestQueryCache(t, 2*size)
+		store(t, c, "a", frame(1))
+		store(t, c, "b", frame(2))
+		_, ok := c.get("a", "A")
+		require.True(t, ok)
+		store(t, c, "c", frame(3))
+
+		_, ok = c.get("b", "A")
+		require.False(t, ok)
+		_, ok = c.get("a", "A")
+		require.True(t, ok)
+		require.Equal(t, 2*size, c.size)
+		require.Equal(t, 1.0, testutil.ToFloat64(c.metrics.queryCacheEvictions))
+		require.Equal(t, float64(2*size), testutil.ToFloat64(c.metrics.queryCacheBytes))
+	})
+
+	t.Run("frames larger than the cache are not cached", func(t *testing.T) {
+		c := newTestQueryCache(t, 10)
+		store(t, c, "k", frame(1))
+		_, ok := c.get("k", "A")
+		require.False(t, ok)
+	})
+}
+
+func TestQueryCacheFetch(t *testing.T) {
+	c := newTestQueryCache(t, 1<<20)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	query := func(context.Context) (data.Frames, error) {
+		calls.Add(1)
+		<-release
+		return data.Frames{data.NewFrame("", data.NewField("value", nil, []float64{1}))}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]data.Frames, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			frames, err := c.fetch(context.Background(), "k", "A", query)
+			require.NoError(t, err)
+			results[i] = frames
+		}(i)
+	}
+	// give the goroutines time to wait for the same query
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(1), calls.Load())
+	for _, frames := range results {
+		require.Len(t, frames, 1)
+		require.Equal(t, "A", frames[0].RefID)
+	}
+	require.NotSame(t, results[0][0], results[1][0])
+
+	// queries that fail are not cached
+	_, err := c.fetch(context.Background(), "failed", "A", func(context.Context) (data.Frames, error) { return nil, context.Canceled })
+	require.ErrorIs(t, err, context.Canceled)
+	_, ok := c.get("failed", "A")
+	require.False(t, ok)
+}
+
+func TestQueryCacheFetchDetachesContext(t *testing.T) {
+	c := newTestQueryCache(t, 1<<20)
+	c.timeout = time.Minute
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var queryErr error
+	query := func(ctx context.Context) (data.Frames, error) {
+		close(started)
+		<-release
+		queryErr = ctx.Err()
+		return data.Frames{data.NewFrame("", data.NewField("value", nil, []float64{1}))}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := c.fetch(ctx, "k", "A", query)
+		first <- err
+	}()
+	<-started
+
+	second := make(chan data.Frames)
+	go func() {
+		frames, err := c.fetch(context.Background(), "k", "B", query)
+		require.NoError(t, err)
+		second <- frames
+	}()
+	// give the second fetch time to wait for the same query
+	time.Sleep(50 * time.Millisecond)
+
+	// the caller that started the query stops waiting, but the query is not cancelled for the other caller
+	cancel()
+	require.ErrorIs(t, <-first, context.Canceled)
+	close(release)
+	frames := <-second
+	require.NoError(t, queryErr)
+	require.Len(t, frames, 1)
+	require.Equal(t, "B", frames[0].RefID)
+	_, ok := c.get("k", "A")
+	require.True(t, ok)
+}
+
+func TestQueryCacheFetchGroup(t *testing.T) {
+	c := newTestQueryCache(t, 1<<20)
+
+	var queried [][]string
+	query := func(_ context.Context, refIDs []string) (map[string]queryResult, error) {
+		queried = append(queried, refIDs)
+		results := make(map[string]queryResult, len(refIDs))
+		for _, refID := range refIDs {
+			if refID == "C" {
+				results[refID] = queryResult{err: errors.New("failed")}
+				continue
+			}
+			results[refID] = queryResult{frames: data.Frames{data.NewFrame("", data.NewField("value", nil, []string{refID}))}}
+		}
+		return results, nil
+	}
+
+	// A and B are the same query, so it is only sent once
+	results, err := c.fetchGroup(context.Background(), map[string]string{"A": "k1", "B": "k1", "C": "k2"}, query)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"A", "C"}}, queried)
+	require.Len(t, results, 3)
+	for _, refID := range []string{"A", "B"} {
+		require.NoError(t, results[refID].err)
+		require.Equal(t, refID, results[refID].frames[0].RefID)
+		require.Equal(t, "A", results[refID].frames[0].Fields[0].At(0))
+	}
+	require.Error(t, results["C"].err)
+
+	_, ok := c.get("k1", "A")
+	require.True(t, ok)
+	_, ok = c.get("k2", "C")
+	require.False(t, ok, "failed queries must not be cached")
+
+	_, err = c.fetchGroup(context.Background(), map[string]string{"A": "k3"}, func(context.Context, []string) (map[string]queryResult, error) {
+		return nil, context.DeadlineExceeded
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+type countingEndpoint struct {
+	mockEndpoint
+	calls atomic.Int32
+}
+
+func (ce *countingEndpoint) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	ce.calls.Add(int32(len(req.Queries)))
+	resp := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		// the endpoint returns the same frames for any refID
+		resp.Responses[q.RefID] = ce.Responses["A"]
+	}
+	return resp, nil
+}
+
+func TestServiceQueryCache(t *testing.T) {
+	for _, groupByDS := range []bool{false, true} {
+		t.Run(map[bool]string{false: "single queries", true: "grouped queries"}[groupByDS], func(t *testing.T) {
+			me := &countingEndpoint{mockEndpoint: mockEndpoint{
+				Responses: map[string]backend.DataResponse{
+					"A": {Frames: data.Frames{data.NewFrame("test",
+						data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+						data.NewField("value", data.Labels{"test": "label"}, []*float64{fp(2)}))}},
+				},
+			}}
+
+			pCtxProvider := plugincontext.ProvideService(setting.NewCfg(), nil, &pluginstore.FakePluginStore{
+				PluginList: []pluginstore.Plugin{
+					{JSONData: plugins.JSONData{ID: "test"}},
+				},
+			}, &datafakes.FakeCacheService{}, &datafakes.FakeDataSourceService{}, nil, pluginconfig.NewFakePluginRequestConfigProvider())
+
+			features := featuremgmt.WithFeatures()
+			if groupByDS {
+				features = featuremgmt.WithFeatures(featuremgmt.FlagSseGroupByDatasource)
+			}
+			s := Service{
+				cfg:          setting.NewCfg(),
+				dataService:  me,
+				pCtxProvider: pCtxProvider,
+				features:     features,
+				tracer:       tracing.InitializeTracerForTest(),
+				metrics:      newMetrics(nil),
+				converter: &ResultConverter{
+					Features: features,
+					Tracer:   tracing.InitializeTracerForTest(),
+				},
+				queryCache: newTestQueryCache(t, 1<<20),
+			}
+
+			request := func(refID string) *Request {
+				return &Request{User: &user.SignedInUser{}, Queries: []Query{
+					{
+						RefID: refID,
+						DataSource: &datasources.DataSource{
+							OrgID: 1,
+							UID:   "test",
+							Type:  "test",
+						},
+						JSON:      json.RawMessage(`{ "datasource": { "uid": "1" }, "intervalMs": 1000, "maxDataPoints": 1000, "refId": "` + refID + `" }`),
+						TimeRange: AbsoluteTimeRange{From: time.Unix(0, 0), To: time.Unix(3600, 0)},
+					},
+					{
+						RefID:      "Z",
+						DataSource: dataSourceModel(),
+						JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$` + refID + ` * 2" }`),
+					},
+				}}
+			}
+
+			for _, refID := range []string{"A", "B"} {
+				pl, err := s.BuildPipeline(request(refID))
+				require.NoError(t, err)
+				explanation := newPipelineExplanation(pl)
+				res, err := s.ExecutePipeline(withExplanation(context.Background(), explanation), time.Now(), pl)
+				require.NoError(t, err)
+				require.Positive(t, explanation.node(refID).DurationMs, "cache hits must record the duration")
+				require.NoError(t, res.Responses[refID].Error)
+				require.Equal(t, refID, res.Responses[refID].Frames[0].RefID)
+				require.Equal(t, 4.0, *res.Responses["Z"].Frames[0].Fields[1].At(0).(*float64))
+			}
+			require.Equal(t, int32(1), me.calls.Load())
+		})
+	}
+}
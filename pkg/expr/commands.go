@@ -83,20 +83,22 @@ func (gm *MathCommand) Type() string {
 // ReduceCommand is an expression command for reduction of a timeseries such as a min, mean, or max.
 type ReduceCommand struct {
 	Reducer      mathexp.ReducerID
+	Options      mathexp.ReduceOptions
 	VarToReduce  string
 	refID        string
 	seriesMapper mathexp.ReduceMapper
 }
 
 // NewReduceCommand creates a new ReduceCMD.
-func NewReduceCommand(refID string, reducer mathexp.ReducerID, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
-	_, err := mathexp.GetReduceFunc(reducer)
+func NewReduceCommand(refID string, reducer mathexp.ReducerID, opts mathexp.ReduceOptions, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
+	_, err := mathexp.GetReduceFuncWithOptions(reducer, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	return &ReduceCommand{
 		Reducer:      reducer,
+		Options:      opts,
 		VarToReduce:  varToReduce,
 		refID:        refID,
 		seriesMapper: mapper,
@@ -125,6 +127,11 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 	}
 	redFunc := mathexp.ReducerID(strings.ToLower(redString))
 
+	opts, err := unmarshalReduceOptions(rn, redFunc)
+	if err != nil {
+		return nil, err
+	}
+
 	var mapper mathexp.ReduceMapper = nil
 	settings, ok := rn.Query["settings"]
 	if ok {
@@ -154,7 +161,25 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 			return nil, fmt.Errorf("field settings must be an object, got %T for refId %v", s, rn.RefID)
 		}
 	}
-	return NewReduceCommand(rn.RefID, redFunc, varToReduce, mapper)
+	return NewReduceCommand(rn.RefID, redFunc, opts, varToReduce, mapper)
+}
+
+// unmarshalReduceOptions reads the parameters of the reducer from Grafana's frontend query.
+func unmarshalReduceOptions(rn *rawNode, reducer mathexp.ReducerID) (mathexp.ReduceOptions, error) {
+	opts := mathexp.ReduceOptions{}
+	if reducer != mathexp.ReducerPercentile {
+		return opts, nil
+	}
+	rawPercentile, ok := rn.Query["percentile"]
+	if !ok {
+		return opts, errors.New("percentile must be specified when reducer is 'percentile'")
+	}
+	percentile, ok := rawPercentile.(float64)
+	if !ok {
+		return opts, fmt.Errorf("percentile must be a number, got %T", rawPercentile)
+	}
+	opts.Percentile = percentile
+	return opts, nil
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -170,12 +195,15 @@ func (gr *ReduceCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.
 	defer span.End()
 
 	span.SetAttributes(attribute.String("reducer", string(gr.Reducer)))
+	if gr.Reducer == mathexp.ReducerPercentile {
+		span.SetAttributes(attribute.Float64("percentile", gr.Options.Percentile))
+	}
 
 	newRes := mathexp.Results{}
 	for i, val := range vars[gr.VarToReduce].Values {
 		switch v := val.(type) {
 		case mathexp.Series:
-			num, err := v.Reduce(gr.refID, gr.Reducer, gr.seriesMapper)
+			num, err := v.Reduce(gr.refID, gr.Reducer, gr.Options, gr.seriesMapper)
 			if err != nil {
 				return newRes, err
 			}
@@ -215,22 +243,27 @@ type ResampleCommand struct {
 	Window        time.Duration
 	VarToResample string
 	Downsampler   mathexp.ReducerID
+	Options       mathexp.ReduceOptions
 	Upsampler     mathexp.Upsampler
 	TimeRange     TimeRange
 	refID         string
 }
 
 // NewResampleCommand creates a new ResampleCMD.
-func NewResampleCommand(refID, rawWindow, varToResample string, downsampler mathexp.ReducerID, upsampler mathexp.Upsampler, tr TimeRange) (*ResampleCommand, error) {
+func NewResampleCommand(refID, rawWindow, varToResample string, downsampler mathexp.ReducerID, opts mathexp.ReduceOptions, upsampler mathexp.Upsampler, tr TimeRange) (*ResampleCommand, error) {
 	// TODO: validate reducer here, before execution
 	window, err := gtime.ParseDuration(rawWindow)
 	if err != nil {
 		return nil, fmt.Errorf(`failed to parse resample "window" duration field %q: %w`, window, err)
 	}
+	if err := opts.Validate(downsampler); err != nil {
+		return nil, err
+	}
 	return &ResampleCommand{
 		Window:        window,
 		VarToResample: varToResample,
 		Downsampler:   downsampler,
+		Options:       opts,
 		Upsampler:     upsampler,
 		TimeRange:     tr,
 		refID:         refID,
@@ -280,9 +313,15 @@ func UnmarshalResampleCommand(rn *rawNode) (*ResampleCommand, error) {
 		return nil, fmt.Errorf("expected resample downsampler to be a string, got type %T", upsampler)
 	}
 
+	opts, err := unmarshalReduceOptions(rn, mathexp.ReducerID(downsampler))
+	if err != nil {
+		return nil, err
+	}
+
 	return NewResampleCommand(rn.RefID, window,
 		varToResample,
 		mathexp.ReducerID(downsampler),
+		opts,
 		mathexp.Upsampler(upsampler),
 		rn.TimeRange)
 }
@@ -306,7 +345,7 @@ func (gr *ResampleCommand) Execute(ctx context.Context, now time.Time, vars math
 		}
 		switch v := val.(type) {
 		case mathexp.Series:
-			num, err := v.Resample(gr.refID, gr.Window, gr.Downsampler, gr.Upsampler, timeRange.From, timeRange.To)
+			num, err := v.Resample(gr.refID, gr.Window, gr.Downsampler, gr.Options, gr.Upsampler, timeRange.From, timeRange.To)
 			if err != nil {
 				return newRes, err
 			}
@@ -343,6 +382,18 @@ const (
 	TypeThreshold
 	// TypeSQL is the CMDType for running SQL expressions
 	TypeSQL
+	// TypeAnomaly is the CMDType for detecting anomalies in series
+	TypeAnomaly
+	// TypeForecast is the CMDType for forecasting series
+	TypeForecast
+	// TypeJoin is the CMDType for joining series by time
+	TypeJoin
+	// TypeSeriesToRows is the CMDType for converting series to rows of a table
+	TypeSeriesToRows
+	// TypeLabelsToFields is the CMDType for converting labels to fields of a table
+	TypeLabelsToFields
+	// TypeRenameFields is the CMDType for renaming fields
+	TypeRenameFields
 )
 
 func (gt CommandType) String() string {
@@ -359,6 +410,18 @@ func (gt CommandType) String() string {
 		return "threshold"
 	case TypeSQL:
 		return "sql"
+	case TypeAnomaly:
+		return "anomaly"
+	case TypeForecast:
+		return "forecast"
+	case TypeJoin:
+		return "join"
+	case TypeSeriesToRows:
+		return "series_to_rows"
+	case TypeLabelsToFields:
+		return "labels_to_fields"
+	case TypeRenameFields:
+		return "rename_fields"
 	default:
 		return "unknown"
 	}
@@ -379,6 +442,18 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeThreshold, nil
 	case "sql":
 		return TypeSQL, nil
+	case "anomaly":
+		return TypeAnomaly, nil
+	case "forecast":
+		return TypeForecast, nil
+	case "join":
+		return TypeJoin, nil
+	case "series_to_rows":
+		return TypeSeriesToRows, nil
+	case "labels_to_fields":
+		return TypeLabelsToFields, nil
+	case "rename_fields":
+		return TypeRenameFields, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
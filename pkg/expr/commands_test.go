@@ -99,11 +99,66 @@ func Test_UnmarshalReduceCommand_Settings(t *testing.T) {
 	}
 }
 
+func Test_UnmarshalReduceCommand_Percentile(t *testing.T) {
+	var tests = []struct {
+		name            string
+		query           string
+		isError         bool
+		expectedOptions mathexp.ReduceOptions
+	}{
+		{
+			name:            "percentile is read from the query",
+			query:           `{ "expression" : "$A", "reducer": "percentile", "percentile": 95 }`,
+			expectedOptions: mathexp.ReduceOptions{Percentile: 95},
+		},
+		{
+			name:    "error when percentile is not specified",
+			query:   `{ "expression" : "$A", "reducer": "percentile" }`,
+			isError: true,
+		},
+		{
+			name:    "error when percentile is out of range",
+			query:   `{ "expression" : "$A", "reducer": "percentile", "percentile": 120 }`,
+			isError: true,
+		},
+		{
+			name:    "error when percentile is not a number",
+			query:   `{ "expression" : "$A", "reducer": "percentile", "percentile": "95" }`,
+			isError: true,
+		},
+		{
+			name:            "percentile is ignored for other reducers",
+			query:           `{ "expression" : "$A", "reducer": "median", "percentile": 95 }`,
+			expectedOptions: mathexp.ReduceOptions{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var qmap = make(map[string]any)
+			require.NoError(t, json.Unmarshal([]byte(test.query), &qmap))
+
+			cmd, err := UnmarshalReduceCommand(&rawNode{
+				RefID:     "A",
+				Query:     qmap,
+				TimeRange: RelativeTimeRange{},
+			})
+
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedOptions, cmd.Options)
+		})
+	}
+}
+
 func TestReduceExecute(t *testing.T) {
 	varToReduce := util.GenerateShortUID()
 
 	t.Run("when mapper is nil", func(t *testing.T) {
-		cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, nil)
+		cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), mathexp.ReduceOptions{}, varToReduce, nil)
 		require.NoError(t, err)
 
 		t.Run("should noop if Number", func(t *testing.T) {
@@ -161,7 +216,7 @@ func TestReduceExecute(t *testing.T) {
 		}
 
 		t.Run("drop all non numbers if mapper is DropNonNumber", func(t *testing.T) {
-			cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, &mathexp.DropNonNumber{})
+			cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), mathexp.ReduceOptions{}, varToReduce, &mathexp.DropNonNumber{})
 			require.NoError(t, err)
 			execute, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
 			require.NoError(t, err)
@@ -169,7 +224,7 @@ func TestReduceExecute(t *testing.T) {
 		})
 
 		t.Run("replace all non numbers if mapper is ReplaceNonNumberWithValue", func(t *testing.T) {
-			cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, &mathexp.ReplaceNonNumberWithValue{Value: 1})
+			cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), mathexp.ReduceOptions{}, varToReduce, &mathexp.ReplaceNonNumberWithValue{Value: 1})
 			require.NoError(t, err)
 			execute, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
 			require.NoError(t, err)
@@ -192,7 +247,7 @@ func TestReduceExecute(t *testing.T) {
 				Values: noData,
 			},
 		}
-		cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, nil)
+		cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), mathexp.ReduceOptions{}, varToReduce, nil)
 		require.NoError(t, err)
 		results, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
 		require.NoError(t, err)
@@ -221,7 +276,7 @@ func TestResampleCommand_Execute(t *testing.T) {
 		From: -10 * time.Second,
 		To:   0,
 	}
-	cmd, err := NewResampleCommand(util.GenerateShortUID(), "1s", varToReduce, "sum", "pad", tr)
+	cmd, err := NewResampleCommand(util.GenerateShortUID(), "1s", varToReduce, "sum", mathexp.ReduceOptions{}, "pad", tr)
 	require.NoError(t, err)
 
 	var tests = []struct {
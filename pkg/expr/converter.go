@@ -76,6 +76,10 @@ func (c *ResultConverter) Convert(ctx context.Context,
 		// This check should be removed once inconsistencies in data source responses are solved.
 		if schema.Type == data.TimeSeriesTypeNot && datasourceType == datasources.DS_INFLUXDB {
 			logger.Warn("Ignoring InfluxDB data frame due to missing numeric fields")
+			nodeExplanationFromContext(ctx).addNotice(data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("ignored data frame '%s' due to missing numeric fields", frame.Name),
+			})
 			continue
 		}
 
@@ -0,0 +1,273 @@
+package expr
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// ExplainRefID is the refID of the response that holds the explanation of the pipeline
+// when a request is executed in explain mode. Queries cannot use it in explain mode.
+const ExplainRefID = "_explain"
+
+const (
+	// maxExplainValues limits the number of values of a node output that are included in the explanation.
+	maxExplainValues = 10
+	// maxExplainRows limits the number of rows of each value that are included in the explanation.
+	maxExplainRows = 20
+)
+
+// PipelineExplanation describes how each node of a pipeline was executed, so that the intermediate
+// results of an expression can be inspected without reproducing the queries manually.
+type PipelineExplanation struct {
+	// Nodes are in the order of the pipeline.
+	Nodes []*NodeExplanation `json:"nodes"`
+	// Edges is the dependency graph of the pipeline.
+	Edges      []ExplainEdge `json:"edges"`
+	DurationMs float64       `json:"durationMs"`
+}
+
+// ExplainEdge is an edge of the dependency graph. The node From is an input of the node To.
+type ExplainEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// NodeExplanation describes the execution of a single node.
+type NodeExplanation struct {
+	RefID    string `json:"refId"`
+	NodeType string `json:"nodeType"`
+	// Type is the command type of expressions, or the data source type of queries.
+	Type          string   `json:"type,omitempty"`
+	DatasourceUID string   `json:"datasourceUid,omitempty"`
+	Inputs        []string `json:"inputs,omitempty"`
+	// DurationMs is the execution time of the node. Data source queries that are grouped
+	// into a single request share the duration of the request.
+	DurationMs float64 `json:"durationMs"`
+	// ResponseType is how the response of a data source was converted, e.g. "vector" or "dataplane-timeseries-multi".
+	ResponseType string         `json:"responseType,omitempty"`
+	Notices      []data.Notice  `json:"notices,omitempty"`
+	Outputs      []ExplainValue `json:"outputs,omitempty"`
+	// TruncatedOutputs is the number of output values that are not included in Outputs.
+	TruncatedOutputs int    `json:"truncatedOutputs,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// ExplainValue is a summary of a value returned by a node. Frame is truncated to the first rows of the value.
+type ExplainValue struct {
+	Type   string      `json:"type"`
+	Labels data.Labels `json:"labels,omitempty"`
+	Rows   int         `json:"rows"`
+	Frame  *data.Frame `json:"frame,omitempty"`
+}
+
+func newPipelineExplanation(pipeline DataPipeline) *PipelineExplanation {
+	e := &PipelineExplanation{
+		Nodes: make([]*NodeExplanation, 0, len(pipeline)),
+		Edges: []ExplainEdge{},
+	}
+	refIDs := make(map[string]struct{}, len(pipeline))
+	for _, node := range pipeline {
+		refIDs[node.RefID()] = struct{}{}
+	}
+	for _, node := range pipeline {
+		n := &NodeExplanation{
+			RefID:    node.RefID(),
+			NodeType: node.NodeType().String(),
+			Inputs:   node.NeedsVars(),
+		}
+		switch t := node.(type) {
+		case *CMDNode:
+			if t.Command != nil {
+				n.Type = t.Command.Type()
+			}
+		case *DSNode:
+			if t.datasource != nil {
+				n.Type = t.datasource.Type
+				n.DatasourceUID = t.datasource.UID
+			}
+		case *MLNode:
+			n.Type = t.command.Type()
+		}
+		e.Nodes = append(e.Nodes, n)
+
+		for _, input := range n.Inputs {
+			// SQL expressions can reference tables that are not in the pipeline
+			if _, ok := refIDs[input]; ok {
+				e.Edges = append(e.Edges, ExplainEdge{From: input, To: n.RefID})
+			}
+		}
+	}
+	return e
+}
+
+// node returns the explanation of the node with the refID, or nil if there is none.
+func (e *PipelineExplanation) node(refID string) *NodeExplanation {
+	if e == nil {
+		return nil
+	}
+	for _, n := range e.Nodes {
+		if n.RefID == refID {
+			return n
+		}
+	}
+	return nil
+}
+
+// removeNodes removes the nodes with the refIDs, and their edges, from the explanation.
+func (e *PipelineExplanation) removeNodes(refIDs map[string]struct{}) {
+	if len(refIDs) == 0 {
+		return
+	}
+	nodes := e.Nodes[:0]
+	for _, n := range e.Nodes {
+		if _, ok := refIDs[n.RefID]; !ok {
+			nodes = append(nodes, n)
+		}
+	}
+	e.Nodes = nodes
+	edges := e.Edges[:0]
+	for _, edge := range e.Edges {
+		_, from := refIDs[edge.From]
+		_, to := refIDs[edge.To]
+		if !from && !to {
+			edges = append(edges, edge)
+		}
+	}
+	e.Edges = edges
+}
+
+// Frame returns the explanation as a table with a row per node. The full explanation is in the custom metadata of the frame.
+func (e *PipelineExplanation) Frame() *data.Frame {
+	var (
+		refIDs     = make([]string, 0, len(e.Nodes))
+		nodeTypes  = make([]string, 0, len(e.Nodes))
+		types      = make([]string, 0, len(e.Nodes))
+		inputs     = make([]string, 0, len(e.Nodes))
+		durations  = make([]float64, 0, len(e.Nodes))
+		outputs    = make([]int64, 0, len(e.Nodes))
+		notices    = make([]int64, 0, len(e.Nodes))
+		errorTexts = make([]string, 0, len(e.Nodes))
+	)
+	for _, n := range e.Nodes {
+		refIDs = append(refIDs, n.RefID)
+		nodeTypes = append(nodeTypes, n.NodeType)
+		types = append(types, n.Type)
+		inputs = append(inputs, strings.Join(n.Inputs, ","))
+		durations = append(durations, n.DurationMs)
+		outputs = append(outputs, int64(len(n.Outputs)+n.TruncatedOutputs))
+		notices = append(notices, int64(len(n.Notices)))
+		errorTexts = append(errorTexts, n.Error)
+	}
+	frame := data.NewFrame("explain",
+		data.NewField("refId", nil, refIDs),
+		data.NewField("nodeType", nil, nodeTypes),
+		data.NewField("type", nil, types),
+		data.NewField("inputs", nil, inputs),
+		data.NewField("durationMs", nil, durations),
+		data.NewField("outputs", nil, outputs),
+		data.NewField("notices", nil, notices),
+		data.NewField("error", nil, errorTexts),
+	)
+	frame.RefID = ExplainRefID
+	frame.SetMeta(&data.FrameMeta{Custom: e})
+	return frame
+}
+
+func (n *NodeExplanation) setDuration(d time.Duration) {
+	if n == nil {
+		return
+	}
+	n.DurationMs = float64(d.Nanoseconds()) / float64(time.Millisecond)
+}
+
+func (n *NodeExplanation) setResponseType(responseType string) {
+	if n == nil {
+		return
+	}
+	n.ResponseType = responseType
+}
+
+func (n *NodeExplanation) addNotice(notice data.Notice) {
+	if n == nil {
+		return
+	}
+	n.Notices = append(n.Notices, notice)
+}
+
+// setResults adds the outputs of the node to the explanation, including the notices of the values,
+// e.g. the items dropped from unions in math expressions.
+func (n *NodeExplanation) setResults(res mathexp.Results) {
+	if n == nil {
+		return
+	}
+	if res.Error != nil {
+		n.Error = res.Error.Error()
+	}
+	for i, v := range res.Values {
+		if v == nil {
+			continue
+		}
+		frame := v.AsDataFrame()
+		if frame != nil && frame.Meta != nil {
+			n.Notices = append(n.Notices, frame.Meta.Notices...)
+		}
+		if i >= maxExplainValues {
+			n.TruncatedOutputs++
+			continue
+		}
+		ev := ExplainValue{
+			Type:   v.Type().String(),
+			Labels: v.GetLabels(),
+		}
+		if frame != nil {
+			ev.Rows = frame.Rows()
+			ev.Frame = truncateFrame(frame, maxExplainRows)
+		}
+		n.Outputs = append(n.Outputs, ev)
+	}
+}
+
+// truncateFrame returns a copy of the frame with the first rows, or the frame itself if it is not longer than rows.
+func truncateFrame(frame *data.Frame, rows int) *data.Frame {
+	if frame.Rows() <= rows {
+		return frame
+	}
+	out := frame.EmptyCopy()
+	for i := 0; i < rows; i++ {
+		out.AppendRow(frame.RowCopy(i)...)
+	}
+	return out
+}
+
+type explanationKey struct{}
+
+type nodeExplanationKey struct{}
+
+// withExplanation returns a context that enables explain mode when a pipeline is executed with it.
+func withExplanation(ctx context.Context, e *PipelineExplanation) context.Context {
+	return context.WithValue(ctx, explanationKey{}, e)
+}
+
+func explanationFromContext(ctx context.Context) *PipelineExplanation {
+	e, _ := ctx.Value(explanationKey{}).(*PipelineExplanation)
+	return e
+}
+
+// withNodeExplanation returns a context for the execution of a node, so that the
+// notices of the data source response conversion can be added to its explanation.
+func withNodeExplanation(ctx context.Context, n *NodeExplanation) context.Context {
+	if n == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, nodeExplanationKey{}, n)
+}
+
+func nodeExplanationFromContext(ctx context.Context) *NodeExplanation {
+	n, _ := ctx.Value(nodeExplanationKey{}).(*NodeExplanation)
+	return n
+}
@@ -0,0 +1,178 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	datafakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginconfig"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestTransformDataExplain(t *testing.T) {
+	series := func(host string, v float64) *data.Frame {
+		return data.NewFrame("",
+			data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+			data.NewField("value", data.Labels{"host": host}, []*float64{fp(v)}))
+	}
+	me := &mockEndpoint{
+		Responses: map[string]backend.DataResponse{
+			"A": {Frames: data.Frames{series("a", 1), series("b", 2)}},
+			"B": {Frames: data.Frames{series("a", 10), series("c", 20)}},
+		},
+	}
+
+	pCtxProvider := plugincontext.ProvideService(setting.NewCfg(), nil, &pluginstore.FakePluginStore{
+		PluginList: []pluginstore.Plugin{
+			{JSONData: plugins.JSONData{ID: "test"}},
+		},
+	}, &datafakes.FakeCacheService{}, &datafakes.FakeDataSourceService{}, nil, pluginconfig.NewFakePluginRequestConfigProvider())
+
+	cfg := setting.NewCfg()
+	cfg.ExpressionsEnabled = true
+	features := featuremgmt.WithFeatures()
+	s := Service{
+		cfg:          cfg,
+		dataService:  me,
+		pCtxProvider: pCtxProvider,
+		features:     features,
+		tracer:       tracing.InitializeTracerForTest(),
+		metrics:      newMetrics(nil),
+		converter: &ResultConverter{
+			Features: features,
+			Tracer:   tracing.InitializeTracerForTest(),
+		},
+	}
+
+	dsQuery := func(refID string) Query {
+		return Query{
+			RefID: refID,
+			DataSource: &datasources.DataSource{
+				OrgID: 1,
+				UID:   "test",
+				Type:  "test",
+			},
+			JSON:      json.RawMessage(`{ "datasource": { "uid": "1" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+			TimeRange: AbsoluteTimeRange{},
+		}
+	}
+	queries := []Query{
+		dsQuery("A"),
+		dsQuery("B"),
+		{
+			RefID:      "C",
+			DataSource: dataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$A + $B" }`),
+		},
+	}
+
+	t.Run("explanation is not returned by default", func(t *testing.T) {
+		res, err := s.TransformData(context.Background(), time.Now(), &Request{Queries: queries, User: &user.SignedInUser{}})
+		require.NoError(t, err)
+		require.NotContains(t, res.Responses, ExplainRefID)
+	})
+
+	t.Run("explanation is returned in explain mode", func(t *testing.T) {
+		res, err := s.TransformData(context.Background(), time.Now(), &Request{Queries: queries, User: &user.SignedInUser{}, Explain: true})
+		require.NoError(t, err)
+		require.Contains(t, res.Responses, "C")
+		require.Contains(t, res.Responses, ExplainRefID)
+
+		frames := res.Responses[ExplainRefID].Frames
+		require.Len(t, frames, 1)
+		require.Equal(t, 3, frames[0].Rows())
+		explanation, ok := frames[0].Meta.Custom.(*PipelineExplanation)
+		require.True(t, ok)
+
+		require.Equal(t, []ExplainEdge{{From: "A", To: "C"}, {From: "B", To: "C"}}, explanation.Edges)
+		require.Len(t, explanation.Nodes, 3)
+
+		a := explanation.node("A")
+		require.Equal(t, TypeDatasourceNode.String(), a.NodeType)
+		require.Equal(t, "test", a.Type)
+		require.Equal(t, "multi frame series", a.ResponseType)
+		require.Len(t, a.Outputs, 2)
+
+		c := explanation.node("C")
+		require.Equal(t, TypeMath.String(), c.Type)
+		require.Equal(t, []string{"A", "B"}, c.Inputs)
+		require.Len(t, c.Outputs, 1)
+		require.Equal(t, data.Labels{"host": "a"}, c.Outputs[0].Labels)
+		// the series that do not match are dropped from the union
+		require.Len(t, c.Notices, 1)
+		require.Contains(t, c.Notices[0].Text, "2 items dropped")
+
+		_, err = json.Marshal(frames[0])
+		require.NoError(t, err)
+	})
+
+	withHidden := make([]Query, len(queries))
+	copy(withHidden, queries)
+	withHidden[0].JSON = json.RawMessage(`{ "datasource": { "uid": "1" }, "intervalMs": 1000, "maxDataPoints": 1000, "hide": true }`)
+
+	t.Run("hidden queries are not explained", func(t *testing.T) {
+		res, err := s.TransformData(context.Background(), time.Now(), &Request{Queries: withHidden, User: &user.SignedInUser{}, Explain: true})
+		require.NoError(t, err)
+		require.NotContains(t, res.Responses, "A")
+
+		explanation := res.Responses[ExplainRefID].Frames[0].Meta.Custom.(*PipelineExplanation)
+		require.Nil(t, explanation.node("A"))
+		require.NotNil(t, explanation.node("B"))
+		require.Equal(t, []ExplainEdge{{From: "B", To: "C"}}, explanation.Edges)
+	})
+
+	t.Run("hidden queries are explained when requested", func(t *testing.T) {
+		res, err := s.TransformData(context.Background(), time.Now(), &Request{Queries: withHidden, User: &user.SignedInUser{}, Explain: true, ExplainHidden: true})
+		require.NoError(t, err)
+		require.NotContains(t, res.Responses, "A")
+
+		explanation := res.Responses[ExplainRefID].Frames[0].Meta.Custom.(*PipelineExplanation)
+		require.NotNil(t, explanation.node("A"))
+		require.Len(t, explanation.Edges, 2)
+	})
+
+	t.Run("explain refId cannot be used by queries in explain mode", func(t *testing.T) {
+		reserved := append([]Query{dsQuery(ExplainRefID)}, queries...)
+		_, err := s.TransformData(context.Background(), time.Now(), &Request{Queries: reserved, User: &user.SignedInUser{}, Explain: true})
+		require.ErrorContains(t, err, "refId _explain is reserved")
+	})
+}
+
+func TestNodeExplanationSetResults(t *testing.T) {
+	values := make(mathexp.Values, 0, maxExplainValues+2)
+	for i := 0; i < maxExplainValues+2; i++ {
+		s := mathexp.NewSeries("A", nil, maxExplainRows+5)
+		for j := 0; j < s.Len(); j++ {
+			s.SetPoint(j, time.Unix(int64(j), 0), fp(float64(j)))
+		}
+		values = append(values, s)
+	}
+
+	n := &NodeExplanation{RefID: "A"}
+	n.setResults(mathexp.Results{Values: values})
+	require.Len(t, n.Outputs, maxExplainValues)
+	require.Equal(t, 2, n.TruncatedOutputs)
+	require.Equal(t, maxExplainRows+5, n.Outputs[0].Rows)
+	require.Equal(t, maxExplainRows, n.Outputs[0].Frame.Rows())
+	// the values are not modified
+	require.Equal(t, maxExplainRows+5, values[0].(mathexp.Series).Len())
+
+	// nil explanations are ignored when not in explain mode
+	var empty *NodeExplanation
+	empty.setResults(mathexp.Results{Values: values})
+	require.Nil(t, explanationFromContext(context.Background()).node("A"))
+}
@@ -0,0 +1,304 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+const (
+	defaultForecastAlpha = 0.5
+	defaultForecastBeta  = 0.1
+	// maxForecastPoints limits the size of the forecasted series when the interval is small compared to the horizon.
+	maxForecastPoints = 10000
+)
+
+// ForecastCommand projects the series of another query or expression into the future.
+// Depending on the output, it returns the forecasted series, the forecasted value at the horizon,
+// or the time until the forecast reaches a threshold. The numbers can be used in a ThresholdCommand.
+type ForecastCommand struct {
+	RefID         string
+	VarToForecast string
+	Method        ForecastMethod
+	Horizon       time.Duration
+	Interval      time.Duration
+	Threshold     *float64
+	Alpha         float64
+	Beta          float64
+	Output        ForecastOutput
+}
+
+// NewForecastCommand creates a new ForecastCommand.
+func NewForecastCommand(refID string, q ForecastQuery) (*ForecastCommand, error) {
+	varToForecast, err := getReferenceVar(q.Expression, refID)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &ForecastCommand{
+		RefID:         refID,
+		VarToForecast: varToForecast,
+		Method:        q.Method,
+		Threshold:     q.Threshold,
+		Alpha:         defaultForecastAlpha,
+		Beta:          defaultForecastBeta,
+		Output:        q.Output,
+	}
+	if cmd.Method == "" {
+		cmd.Method = ForecastLinear
+	}
+	switch cmd.Method {
+	case ForecastLinear:
+	case ForecastHolt:
+		for _, p := range []struct {
+			name  string
+			value *float64
+			dst   *float64
+		}{{"alpha", q.Alpha, &cmd.Alpha}, {"beta", q.Beta, &cmd.Beta}} {
+			if p.value == nil {
+				continue
+			}
+			if *p.value < 0 || *p.value > 1 {
+				return nil, fmt.Errorf("%s must be between 0 and 1, got %v", p.name, *p.value)
+			}
+			*p.dst = *p.value
+		}
+	default:
+		return nil, fmt.Errorf("unsupported forecast method '%s', expected one of [%s, %s]", q.Method, ForecastLinear, ForecastHolt)
+	}
+
+	if cmd.Output == "" {
+		cmd.Output = ForecastOutputSeries
+	}
+	switch cmd.Output {
+	case ForecastOutputSeries, ForecastOutputValue:
+	case ForecastOutputTimeToThreshold:
+		if q.Threshold == nil {
+			return nil, fmt.Errorf("threshold is required by the forecast output '%s'", cmd.Output)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported forecast output '%s', expected one of [%s, %s, %s]", q.Output, ForecastOutputSeries, ForecastOutputValue, ForecastOutputTimeToThreshold)
+	}
+
+	// the time to threshold does not depend on the horizon
+	if q.Horizon != "" || cmd.Output != ForecastOutputTimeToThreshold {
+		cmd.Horizon, err = gtime.ParseDuration(q.Horizon)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse forecast horizon '%v': %w", q.Horizon, err)
+		}
+		if cmd.Horizon <= 0 {
+			return nil, fmt.Errorf("forecast horizon must be positive, got '%v'", q.Horizon)
+		}
+	}
+	if q.Interval != "" {
+		cmd.Interval, err = gtime.ParseDuration(q.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse forecast interval '%v': %w", q.Interval, err)
+		}
+		if cmd.Interval <= 0 {
+			return nil, fmt.Errorf("forecast interval must be positive, got '%v'", q.Interval)
+		}
+	}
+	return cmd, nil
+}
+
+// UnmarshalForecastCommand creates a ForecastCommand from Grafana's frontend query.
+func UnmarshalForecastCommand(rn *rawNode) (*ForecastCommand, error) {
+	q := ForecastQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the forecast command: %w", err)
+	}
+	return NewForecastCommand(rn.RefID, q)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (fc *ForecastCommand) NeedsVars() []string {
+	return []string{fc.VarToForecast}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (fc *ForecastCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteForecast")
+	defer span.End()
+	span.SetAttributes(attribute.String("method", string(fc.Method)), attribute.String("output", string(fc.Output)))
+
+	newRes := mathexp.Results{}
+	for _, val := range vars[fc.VarToForecast].Values {
+		if val == nil {
+			continue
+		}
+		switch v := val.(type) {
+		case mathexp.Series:
+			newRes.Values = append(newRes.Values, fc.forecast(v))
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+			return newRes, nil
+		default:
+			return newRes, fmt.Errorf("can only forecast type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+func (fc *ForecastCommand) Type() string {
+	return TypeForecast.String()
+}
+
+// trendLine is a line fitted to a series. The value at time t is value + slope * (t - at) in seconds.
+type trendLine struct {
+	at    time.Time
+	value float64
+	slope float64
+}
+
+func (l trendLine) valueAt(t time.Time) float64 {
+	return l.value + l.slope*t.Sub(l.at).Seconds()
+}
+
+// timeTo returns the seconds from the start of the line until it reaches the threshold.
+// Returns 0 if the line is already at the threshold or past it in the direction of the slope,
+// i.e. above a threshold for a rising line or below it for a falling line.
+// Returns +Inf if the line is flat and never reaches the threshold.
+func (l trendLine) timeTo(threshold float64) float64 {
+	d := threshold - l.value
+	if d == 0 {
+		return 0
+	}
+	if l.slope == 0 {
+		return math.Inf(1)
+	}
+	t := d / l.slope
+	if t < 0 {
+		return 0
+	}
+	return t
+}
+
+// forecast returns the result of the command for a single series.
+func (fc *ForecastCommand) forecast(s mathexp.Series) mathexp.Value {
+	points := make([]point, 0, s.Len())
+	for _, p := range sortedPoints(s) {
+		if mathexp.HasValue(p.value) {
+			points = append(points, p)
+		}
+	}
+
+	var line *trendLine
+	switch fc.Method {
+	case ForecastHolt:
+		line = fc.holt(points)
+	default:
+		line = linearRegression(points)
+	}
+
+	switch fc.Output {
+	case ForecastOutputValue, ForecastOutputTimeToThreshold:
+		n := mathexp.NewNumber(fc.RefID, s.GetLabels())
+		if line != nil {
+			var v float64
+			if fc.Output == ForecastOutputValue {
+				v = line.valueAt(line.at.Add(fc.Horizon))
+			} else {
+				v = line.timeTo(*fc.Threshold)
+			}
+			n.SetValue(&v)
+		}
+		return n
+	default:
+		if line == nil {
+			return mathexp.NewSeries(fc.RefID, s.GetLabels(), 0)
+		}
+		interval := fc.Interval
+		if interval == 0 {
+			interval = medianInterval(points)
+		}
+		count := int(fc.Horizon / interval)
+		if count > maxForecastPoints {
+			count = maxForecastPoints
+		}
+		series := mathexp.NewSeries(fc.RefID, s.GetLabels(), count)
+		for i := 0; i < count; i++ {
+			t := line.at.Add(time.Duration(i+1) * interval)
+			v := line.valueAt(t)
+			series.SetPoint(i, t, &v)
+		}
+		return series
+	}
+}
+
+// linearRegression fits a line to the points with the least squares method.
+// The line starts at the last point. Returns nil if there are less than two points.
+func linearRegression(points []point) *trendLine {
+	if len(points) < 2 {
+		return nil
+	}
+	last := points[len(points)-1].time
+	var sumX, sumY, sumXY, sumXX float64
+	for _, p := range points {
+		x := p.time.Sub(last).Seconds()
+		y := *p.value
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	n := float64(len(points))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		// all points have the same time
+		return nil
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	intercept := (sumY - slope*sumX) / n
+	return &trendLine{at: last, value: intercept, slope: slope}
+}
+
+// holt fits a line to the points with double exponential smoothing. The line starts at the last point.
+// Returns nil if there are less than two points.
+func (fc *ForecastCommand) holt(points []point) *trendLine {
+	if len(points) < 2 {
+		return nil
+	}
+	// the trend is per second because the points might not be evenly spaced
+	level := *points[0].value
+	var trend float64
+	for i := 1; i < len(points); i++ {
+		dt := points[i].time.Sub(points[i-1].time).Seconds()
+		if dt <= 0 {
+			continue
+		}
+		v := *points[i].value
+		if i == 1 {
+			trend = (v - level) / dt
+			level = v
+			continue
+		}
+		prevLevel := level
+		level = fc.Alpha*v + (1-fc.Alpha)*(level+trend*dt)
+		trend = fc.Beta*(level-prevLevel)/dt + (1-fc.Beta)*trend
+	}
+	return &trendLine{at: points[len(points)-1].time, value: level, slope: trend}
+}
+
+// medianInterval returns the median time between consecutive points, or one second if it cannot be determined.
+func medianInterval(points []point) time.Duration {
+	deltas := make([]float64, 0, len(points))
+	for i := 1; i < len(points); i++ {
+		if d := points[i].time.Sub(points[i-1].time); d > 0 {
+			deltas = append(deltas, float64(d))
+		}
+	}
+	if len(deltas) == 0 {
+		return time.Second
+	}
+	return time.Duration(reduceValues(mathexp.Median, deltas))
+}
@@ -0,0 +1,186 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestNewForecastCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   ForecastQuery
+		isError bool
+	}{
+		{
+			name:  "linear by default",
+			query: ForecastQuery{Expression: "$A", Horizon: "4h"},
+		},
+		{
+			name:  "holt with smoothing factors",
+			query: ForecastQuery{Expression: "A", Method: ForecastHolt, Horizon: "1d", Alpha: util.Pointer(0.8), Beta: util.Pointer(0.2)},
+		},
+		{
+			name:  "time to threshold without horizon",
+			query: ForecastQuery{Expression: "$A", Threshold: util.Pointer(100.0), Output: ForecastOutputTimeToThreshold},
+		},
+		{
+			name:    "missing horizon",
+			query:   ForecastQuery{Expression: "$A"},
+			isError: true,
+		},
+		{
+			name:    "invalid interval",
+			query:   ForecastQuery{Expression: "$A", Horizon: "4h", Interval: "-1m"},
+			isError: true,
+		},
+		{
+			name:    "unknown method",
+			query:   ForecastQuery{Expression: "$A", Method: "arima", Horizon: "4h"},
+			isError: true,
+		},
+		{
+			name:    "smoothing factor out of range",
+			query:   ForecastQuery{Expression: "$A", Method: ForecastHolt, Horizon: "4h", Alpha: util.Pointer(2.0)},
+			isError: true,
+		},
+		{
+			name:    "time to threshold without threshold",
+			query:   ForecastQuery{Expression: "$A", Horizon: "4h", Output: ForecastOutputTimeToThreshold},
+			isError: true,
+		},
+		{
+			name:    "unknown output",
+			query:   ForecastQuery{Expression: "$A", Horizon: "4h", Output: "table"},
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := NewForecastCommand("B", tt.query)
+			if tt.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []string{"A"}, cmd.NeedsVars())
+		})
+	}
+}
+
+func TestUnmarshalForecastCommand(t *testing.T) {
+	raw := []byte(`{"type": "forecast", "expression": "$A", "method": "holt", "horizon": "4h", "output": "value"}`)
+	q := map[string]any{}
+	require.NoError(t, json.Unmarshal(raw, &q))
+
+	cmd, err := UnmarshalForecastCommand(&rawNode{RefID: "B", Query: q, QueryRaw: raw})
+	require.NoError(t, err)
+	require.Equal(t, ForecastHolt, cmd.Method)
+	require.Equal(t, 4*time.Hour, cmd.Horizon)
+	require.Equal(t, ForecastOutputValue, cmd.Output)
+}
+
+func TestForecastCommandExecute(t *testing.T) {
+	start := time.Unix(0, 0)
+	labels := data.Labels{"device": "sda"}
+	// grows by 1 every minute, from 10 to 19
+	series := mathexp.NewSeries("A", labels, 10)
+	for i := 0; i < 10; i++ {
+		series.SetPoint(i, start.Add(time.Duration(i)*time.Minute), util.Pointer(float64(10+i)))
+	}
+	execute := func(t *testing.T, q ForecastQuery, vals ...mathexp.Value) mathexp.Results {
+		t.Helper()
+		q.Expression = "$A"
+		cmd, err := NewForecastCommand("B", q)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{"A": mathexp.Results{Values: vals}}, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		return res
+	}
+
+	for _, method := range []ForecastMethod{ForecastLinear, ForecastHolt} {
+		t.Run(string(method), func(t *testing.T) {
+			t.Run("series", func(t *testing.T) {
+				res := execute(t, ForecastQuery{Method: method, Horizon: "5m"}, series)
+				require.Len(t, res.Values, 1)
+				s := res.Values[0].(mathexp.Series)
+				require.Equal(t, labels, s.GetLabels())
+				require.Equal(t, 5, s.Len())
+				tm, v := s.GetPoint(4)
+				require.Equal(t, start.Add(14*time.Minute), tm)
+				require.InDelta(t, 24, *v, 1e-9)
+			})
+
+			t.Run("value at horizon", func(t *testing.T) {
+				res := execute(t, ForecastQuery{Method: method, Horizon: "1h", Output: ForecastOutputValue}, series)
+				n := res.Values[0].(mathexp.Number)
+				require.Equal(t, labels, n.GetLabels())
+				require.InDelta(t, 79, *n.GetFloat64Value(), 1e-9)
+			})
+
+			t.Run("time to threshold", func(t *testing.T) {
+				res := execute(t, ForecastQuery{Method: method, Threshold: util.Pointer(100.0), Output: ForecastOutputTimeToThreshold}, series)
+				require.InDelta(t, 81*60, *res.Values[0].(mathexp.Number).GetFloat64Value(), 1e-6)
+
+				// the series is already past the threshold and keeps rising
+				res = execute(t, ForecastQuery{Method: method, Threshold: util.Pointer(0.0), Output: ForecastOutputTimeToThreshold}, series)
+				require.Equal(t, 0.0, *res.Values[0].(mathexp.Number).GetFloat64Value())
+			})
+		})
+	}
+
+	t.Run("can be used in a threshold", func(t *testing.T) {
+		res := execute(t, ForecastQuery{Horizon: "1h", Output: ForecastOutputValue}, series)
+		threshold, err := NewThresholdCommand("C", "B", ThresholdIsAbove, []float64{75})
+		require.NoError(t, err)
+		res, err = threshold.Execute(context.Background(), time.Now(), mathexp.Vars{"B": res}, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Equal(t, 1.0, *res.Values[0].(mathexp.Number).GetFloat64Value())
+	})
+
+	t.Run("not enough points", func(t *testing.T) {
+		single := mathexp.NewSeries("A", labels, 1)
+		single.SetPoint(0, start, util.Pointer(1.0))
+		res := execute(t, ForecastQuery{Horizon: "1h", Output: ForecastOutputValue}, single)
+		require.Nil(t, res.Values[0].(mathexp.Number).GetFloat64Value())
+	})
+
+	t.Run("returns no data when input is no data", func(t *testing.T) {
+		res := execute(t, ForecastQuery{Horizon: "1h"}, mathexp.NoData{}.New())
+		require.Len(t, res.Values, 1)
+		require.Equal(t, parse.TypeNoData, res.Values[0].Type())
+	})
+}
+
+func TestTrendLineTimeTo(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	tests := []struct {
+		name      string
+		line      trendLine
+		threshold float64
+		expected  float64
+	}{
+		{name: "rising line below threshold", line: trendLine{at: at, value: 10, slope: 2}, threshold: 20, expected: 5},
+		{name: "falling line above threshold", line: trendLine{at: at, value: 10, slope: -2}, threshold: 0, expected: 5},
+		{name: "at threshold", line: trendLine{at: at, value: 10, slope: 2}, threshold: 10, expected: 0},
+		{name: "rising line above threshold", line: trendLine{at: at, value: 30, slope: 2}, threshold: 20, expected: 0},
+		{name: "falling line below threshold", line: trendLine{at: at, value: -10, slope: -2}, threshold: 0, expected: 0},
+		{name: "flat line", line: trendLine{at: at, value: 10}, threshold: 20, expected: math.Inf(1)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.line.timeTo(tc.threshold))
+		})
+	}
+}
@@ -62,6 +62,7 @@ type DataPipeline []Node
 // map of the refId of the of each command
 func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (mathexp.Vars, error) {
 	vars := make(mathexp.Vars)
+	explanation := explanationFromContext(c)
 
 	groupByDSFlag := s.features.IsEnabled(c, featuremgmt.FlagSseGroupByDatasource)
 	// Execute datasource nodes first, and grouped by datasource.
@@ -75,6 +76,9 @@ func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (m
 		}
 
 		executeDSNodesGrouped(c, now, vars, s, dsNodes)
+		for _, dn := range dsNodes {
+			explanation.node(dn.RefID()).setResults(vars[dn.RefID()])
+		}
 	}
 
 	s.allowLongFrames = hasSqlExpression(*dp)
@@ -99,6 +103,7 @@ func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (m
 			}
 		}
 		if hasDepError {
+			explanation.node(node.RefID()).setResults(vars[node.RefID()])
 			continue
 		}
 
@@ -115,12 +120,16 @@ func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (m
 			return vars, makeUnexpectedNodeTypeError(node.RefID(), node.NodeType().String())
 		}
 
-		res, err := execNode.Execute(c, now, vars, s)
+		nodeExplanation := explanation.node(node.RefID())
+		start := time.Now()
+		res, err := execNode.Execute(withNodeExplanation(c, nodeExplanation), now, vars, s)
+		nodeExplanation.setDuration(time.Since(start))
 		if err != nil {
 			res.Error = err
 		}
 
 		vars[node.RefID()] = res
+		nodeExplanation.setResults(res)
 	}
 	return vars, nil
 }
@@ -279,7 +288,7 @@ func (s *Service) buildGraph(req *Request) (*simple.DirectedGraph, error) {
 		case TypeDatasourceNode:
 			node, err = s.buildDSNode(dp, rn, req)
 		case TypeCMDNode:
-			node, err = buildCMDNode(rn, s.features)
+			node, err = buildCMDNode(rn, s.features, s.sqlEngine)
 		case TypeMLNode:
 			if s.features.IsEnabledGlobally(featuremgmt.FlagMlExpressions) {
 				node, err = s.buildMLNode(dp, rn, req)
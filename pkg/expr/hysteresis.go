@@ -82,6 +82,9 @@ func (h HysteresisCommand) Type() string {
 }
 
 func NewHysteresisCommand(refID string, referenceVar string, loadCondition ThresholdCommand, unloadCondition ThresholdCommand, l Fingerprints) (*HysteresisCommand, error) {
+	if err := validateRangeHysteresis(loadCondition, unloadCondition); err != nil {
+		return nil, err
+	}
 	return &HysteresisCommand{
 		RefID:                  refID,
 		LoadingThresholdFunc:   loadCondition,
@@ -91,6 +94,32 @@ func NewHysteresisCommand(refID string, referenceVar string, loadCondition Thres
 	}, nil
 }
 
+// validateRangeHysteresis checks that the recovery range of a range threshold is the opposite of the loading range and
+// lies on the firing side of it: a metric that fires when outside of [a, b] recovers only when it is back within a range
+// inside [a, b], and a metric that fires when within [a, b] recovers only when it is outside of a range that contains [a, b].
+// Otherwise, a metric would recover at a value at which it fires again. Thresholds that are not ranges are not checked.
+func validateRangeHysteresis(loadCondition ThresholdCommand, unloadCondition ThresholdCommand) error {
+	switch load := loadCondition.predicate.(type) {
+	case outsideRangePredicate:
+		unload, ok := unloadCondition.predicate.(withinRangePredicate)
+		if !ok {
+			return fmt.Errorf("the recovery threshold of threshold function '%s' must be '%s', got '%s'", ThresholdIsOutsideRange, ThresholdIsWithinRange, unloadCondition.ThresholdFunc)
+		}
+		if unload.left < load.left || unload.right > load.right {
+			return fmt.Errorf("the recovery range [%v, %v] must be within the range [%v, %v]", unload.left, unload.right, load.left, load.right)
+		}
+	case withinRangePredicate:
+		unload, ok := unloadCondition.predicate.(outsideRangePredicate)
+		if !ok {
+			return fmt.Errorf("the recovery threshold of threshold function '%s' must be '%s', got '%s'", ThresholdIsWithinRange, ThresholdIsOutsideRange, unloadCondition.ThresholdFunc)
+		}
+		if unload.left > load.left || unload.right < load.right {
+			return fmt.Errorf("the recovery range [%v, %v] must contain the range [%v, %v]", unload.left, unload.right, load.left, load.right)
+		}
+	}
+	return nil
+}
+
 // FingerprintsFromFrame converts data.Frame to Fingerprints.
 // The input data frame must have a single field of uint64 type.
 // Returns error if the input data frame has invalid format
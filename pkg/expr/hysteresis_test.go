@@ -11,6 +11,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func TestHysteresisExecute(t *testing.T) {
@@ -119,6 +120,115 @@ func TestHysteresisExecute(t *testing.T) {
 	}
 }
 
+func TestHysteresisExecuteRange(t *testing.T) {
+	series := func(label string, values ...float64) mathexp.Series {
+		s := mathexp.NewSeries("B", data.Labels{"label": label}, len(values))
+		for i := range values {
+			s.SetPoint(i, time.Unix(int64(i), 0), &values[i])
+		}
+		return s
+	}
+	number := func(label string, value float64) mathexp.Number {
+		n := mathexp.NewNumber("B", data.Labels{"label": label})
+		n.SetValue(&value)
+		return n
+	}
+
+	// alert when the value is outside [10, 90] for 2 consecutive points,
+	// and recover only when it is back within [20, 80] for 2 consecutive points.
+	loading, err := NewThresholdCommandFromEvaluator("B", "A", ConditionEvalJSON{
+		Type:        ThresholdIsOutsideRange,
+		Params:      []float64{10, 90},
+		Consecutive: util.Pointer(2),
+	})
+	require.NoError(t, err)
+	unloading, err := NewThresholdCommandFromEvaluator("B", "A", ConditionEvalJSON{
+		Type:        ThresholdIsWithinRange,
+		Params:      []float64{20, 80},
+		Consecutive: util.Pointer(2),
+	})
+	require.NoError(t, err)
+	unloading.Invert = true
+
+	cmd, err := NewHysteresisCommand("B", "A", *loading, *unloading, Fingerprints{
+		data.Labels{"label": "firing-recovering"}.Fingerprint(): {},
+		data.Labels{"label": "firing-recovered"}.Fingerprint():  {},
+	})
+	require.NoError(t, err)
+
+	result, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{
+			series("flapping", 50, 95, 50, 95),
+			series("breaching", 50, 50, 95, 95),
+			series("breached", 50, 95, 95, 50),
+			series("firing-recovering", 95, 85, 75, 85),
+			series("firing-recovered", 95, 85, 75, 70),
+		}},
+	}, tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+	require.ElementsMatch(t, mathexp.Values{
+		number("flapping", 0),
+		number("breaching", 1),
+		number("breached", 0),
+		number("firing-recovering", 1),
+		number("firing-recovered", 0),
+	}, result.Values)
+}
+
+func TestNewHysteresisCommandRange(t *testing.T) {
+	testCases := []struct {
+		name          string
+		loading       ConditionEvalJSON
+		unloading     ConditionEvalJSON
+		expectedError string
+	}{
+		{
+			name:      "recovery range within the outside range",
+			loading:   ConditionEvalJSON{Type: ThresholdIsOutsideRange, Params: []float64{10, 90}},
+			unloading: ConditionEvalJSON{Type: ThresholdIsWithinRange, Params: []float64{20, 80}},
+		},
+		{
+			name:      "recovery range that contains the within range",
+			loading:   ConditionEvalJSON{Type: ThresholdIsWithinRange, Params: []float64{20, 80}},
+			unloading: ConditionEvalJSON{Type: ThresholdIsOutsideRange, Params: []float64{10, 90}},
+		},
+		{
+			name:          "recovery range that exceeds the outside range",
+			loading:       ConditionEvalJSON{Type: ThresholdIsOutsideRange, Params: []float64{10, 90}},
+			unloading:     ConditionEvalJSON{Type: ThresholdIsWithinRange, Params: []float64{5, 80}},
+			expectedError: "the recovery range [5, 80] must be within the range [10, 90]",
+		},
+		{
+			name:          "recovery range that does not contain the within range",
+			loading:       ConditionEvalJSON{Type: ThresholdIsWithinRange, Params: []float64{20, 80}},
+			unloading:     ConditionEvalJSON{Type: ThresholdIsOutsideRange, Params: []float64{30, 90}},
+			expectedError: "the recovery range [30, 90] must contain the range [20, 80]",
+		},
+		{
+			name:          "recovery threshold that is not the opposite range",
+			loading:       ConditionEvalJSON{Type: ThresholdIsOutsideRange, Params: []float64{10, 90}},
+			unloading:     ConditionEvalJSON{Type: ThresholdIsBelow, Params: []float64{80}},
+			expectedError: "the recovery threshold of threshold function 'outside_range' must be 'within_range', got 'lt'",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			loading, err := NewThresholdCommandFromEvaluator("B", "A", tc.loading)
+			require.NoError(t, err)
+			unloading, err := NewThresholdCommandFromEvaluator("B", "A", tc.unloading)
+			require.NoError(t, err)
+			unloading.Invert = true
+
+			_, err = NewHysteresisCommand("B", "A", *loading, *unloading, nil)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
 func TestLoadedDimensionsFromFrame(t *testing.T) {
 	correctType := &data.FrameMeta{Type: "fingerprints", TypeVersion: data.FrameTypeVersion{1, 0}}
 	testCases := []struct {
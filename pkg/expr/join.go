@@ -0,0 +1,158 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// JoinCommand joins the series of one or more queries or expressions by time into a single wide table,
+// like the join by field transformation of the frontend. The value fields are named by the refID of the
+// query they come from and keep their labels.
+type JoinCommand struct {
+	RefID      string
+	VarsToJoin []string
+	Mode       JoinMode
+}
+
+// NewJoinCommand creates a new JoinCommand.
+func NewJoinCommand(refID string, q JoinQuery) (*JoinCommand, error) {
+	if len(q.Expressions) == 0 {
+		return nil, fmt.Errorf("join requires at least one expression")
+	}
+	cmd := &JoinCommand{
+		RefID:      refID,
+		VarsToJoin: make([]string, 0, len(q.Expressions)),
+		Mode:       q.Mode,
+	}
+	seen := make(map[string]struct{}, len(q.Expressions))
+	for _, e := range q.Expressions {
+		v, err := getReferenceVar(e, refID)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[v]; ok {
+			return nil, fmt.Errorf("'%s' is joined more than once", v)
+		}
+		seen[v] = struct{}{}
+		cmd.VarsToJoin = append(cmd.VarsToJoin, v)
+	}
+
+	if cmd.Mode == "" {
+		cmd.Mode = JoinOuter
+	}
+	switch cmd.Mode {
+	case JoinOuter, JoinInner:
+	default:
+		return nil, fmt.Errorf("unsupported join mode '%s', expected one of [%s, %s]", q.Mode, JoinOuter, JoinInner)
+	}
+	return cmd, nil
+}
+
+// UnmarshalJoinCommand creates a JoinCommand from Grafana's frontend query.
+func UnmarshalJoinCommand(rn *rawNode) (*JoinCommand, error) {
+	q := JoinQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the join command: %w", err)
+	}
+	return NewJoinCommand(rn.RefID, q)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (jc *JoinCommand) NeedsVars() []string {
+	return jc.VarsToJoin
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (jc *JoinCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteJoin")
+	defer span.End()
+	span.SetAttributes(attribute.String("mode", string(jc.Mode)), attribute.StringSlice("refIds", jc.VarsToJoin))
+
+	type joinSeries struct {
+		refID  string
+		series mathexp.Series
+	}
+	var toJoin []joinSeries
+	for _, refID := range jc.VarsToJoin {
+		for _, val := range vars[refID].Values {
+			if val == nil {
+				continue
+			}
+			switch v := val.(type) {
+			case mathexp.Series:
+				toJoin = append(toJoin, joinSeries{refID: refID, series: v})
+			case mathexp.NoData:
+				// the other series can still be joined
+			default:
+				return mathexp.Results{}, fmt.Errorf("can only join type series, got type %v in '%s'", val.Type(), refID)
+			}
+		}
+	}
+	if len(toJoin) == 0 {
+		return mathexp.Results{Values: mathexp.Values{mathexp.NoData{}.New()}}, nil
+	}
+
+	// the values of each series by time, and the number of series that have each time
+	byTime := make([]map[int64]*float64, len(toJoin))
+	counts := make(map[int64]int)
+	for i, s := range toJoin {
+		byTime[i] = make(map[int64]*float64, s.series.Len())
+		for j := 0; j < s.series.Len(); j++ {
+			t, v := s.series.GetPoint(j)
+			key := t.UnixNano()
+			if _, ok := byTime[i][key]; !ok {
+				counts[key]++
+			}
+			byTime[i][key] = v
+		}
+	}
+
+	times := make([]int64, 0, len(counts))
+	for t, count := range counts {
+		if jc.Mode == JoinInner && count != len(toJoin) {
+			continue
+		}
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	timeField := data.NewField("Time", nil, make([]time.Time, len(times)))
+	for i, t := range times {
+		timeField.Set(i, time.Unix(0, t).UTC())
+	}
+	fields := make([]*data.Field, 0, len(toJoin)+1)
+	fields = append(fields, timeField)
+	for i, s := range toJoin {
+		values := make([]*float64, len(times))
+		for j, t := range times {
+			values[j] = byTime[i][t]
+		}
+		fields = append(fields, data.NewField(s.refID, s.series.GetLabels().Copy(), values))
+	}
+
+	frame := data.NewFrame(jc.RefID, fields...)
+	frame.RefID = jc.RefID
+	frame.SetMeta(&data.FrameMeta{
+		Type:        data.FrameTypeTimeSeriesWide,
+		TypeVersion: data.FrameTypeVersion{0, 1},
+	})
+	if frame.Rows() == 0 {
+		return mathexp.Results{Values: mathexp.Values{mathexp.NoData{Frame: frame}}}, nil
+	}
+	return mathexp.Results{Values: mathexp.Values{mathexp.TableData{Frame: frame}}}, nil
+}
+
+func (jc *JoinCommand) Type() string {
+	return TypeJoin.String()
+}
@@ -0,0 +1,157 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestNewJoinCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   JoinQuery
+		isError bool
+	}{
+		{
+			name:  "default mode",
+			query: JoinQuery{Expressions: []string{"$A", "B"}},
+		},
+		{
+			name:  "inner join",
+			query: JoinQuery{Expressions: []string{"$A", "$B"}, Mode: JoinInner},
+		},
+		{
+			name:    "no expressions",
+			query:   JoinQuery{},
+			isError: true,
+		},
+		{
+			name:    "empty expression",
+			query:   JoinQuery{Expressions: []string{"$A", "$"}},
+			isError: true,
+		},
+		{
+			name:    "duplicate expression",
+			query:   JoinQuery{Expressions: []string{"$A", "A"}},
+			isError: true,
+		},
+		{
+			name:    "unknown mode",
+			query:   JoinQuery{Expressions: []string{"$A", "$B"}, Mode: "left"},
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := NewJoinCommand("C", tt.query)
+			if tt.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []string{"A", "B"}, cmd.NeedsVars())
+		})
+	}
+}
+
+func TestUnmarshalJoinCommand(t *testing.T) {
+	raw := []byte(`{"type": "join", "expressions": ["$A", "$B"], "mode": "inner"}`)
+	q := map[string]any{}
+	require.NoError(t, json.Unmarshal(raw, &q))
+
+	cmd, err := UnmarshalJoinCommand(&rawNode{RefID: "C", Query: q, QueryRaw: raw})
+	require.NoError(t, err)
+	require.Equal(t, []string{"A", "B"}, cmd.VarsToJoin)
+	require.Equal(t, JoinInner, cmd.Mode)
+}
+
+func TestJoinCommandExecute(t *testing.T) {
+	// makeSeries returns a series with a point every 5 seconds from the start
+	makeSeries := func(refID string, labels data.Labels, start int64, values ...float64) mathexp.Series {
+		s := mathexp.NewSeries(refID, labels, len(values))
+		for i := range values {
+			s.SetPoint(i, time.Unix(start+int64(i)*5, 0), &values[i])
+		}
+		return s
+	}
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{
+			makeSeries("A", data.Labels{"host": "a"}, 5, 1, 2),
+		}},
+		"B": mathexp.Results{Values: mathexp.Values{
+			makeSeries("B", data.Labels{"host": "b"}, 10, 20, 30),
+		}},
+		"C": mathexp.Results{Values: mathexp.Values{mathexp.NoData{}.New()}},
+		"D": mathexp.Results{Values: mathexp.Values{mathexp.NewNumber("D", nil)}},
+	}
+	execute := func(t *testing.T, q JoinQuery) (mathexp.Results, error) {
+		t.Helper()
+		cmd, err := NewJoinCommand("E", q)
+		require.NoError(t, err)
+		return cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+	}
+
+	t.Run("outer join keeps all times", func(t *testing.T) {
+		res, err := execute(t, JoinQuery{Expressions: []string{"$A", "$B"}})
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, parse.TypeTableData, res.Values[0].Type())
+
+		frame := res.Values[0].AsDataFrame()
+		require.Equal(t, 3, frame.Rows())
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, time.Unix(5, 0).UTC(), frame.Fields[0].At(0))
+		require.Equal(t, "A", frame.Fields[1].Name)
+		require.Equal(t, data.Labels{"host": "a"}, frame.Fields[1].Labels)
+		require.Equal(t, "B", frame.Fields[2].Name)
+		require.Equal(t, []*float64{util.Pointer(1.0), util.Pointer(2.0), nil}, fieldValues(frame.Fields[1]))
+		require.Equal(t, []*float64{nil, util.Pointer(20.0), util.Pointer(30.0)}, fieldValues(frame.Fields[2]))
+		require.Equal(t, data.FrameTypeTimeSeriesWide, frame.Meta.Type)
+	})
+
+	t.Run("inner join keeps the common times", func(t *testing.T) {
+		res, err := execute(t, JoinQuery{Expressions: []string{"$A", "$B"}, Mode: JoinInner})
+		require.NoError(t, err)
+		frame := res.Values[0].AsDataFrame()
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, time.Unix(10, 0).UTC(), frame.Fields[0].At(0))
+		require.Equal(t, 20.0, *frame.Fields[2].At(0).(*float64))
+	})
+
+	t.Run("no data is skipped", func(t *testing.T) {
+		res, err := execute(t, JoinQuery{Expressions: []string{"$A", "$C"}})
+		require.NoError(t, err)
+		frame := res.Values[0].AsDataFrame()
+		require.Len(t, frame.Fields, 2)
+		require.Equal(t, 2, frame.Rows())
+	})
+
+	t.Run("returns no data when there are no series", func(t *testing.T) {
+		res, err := execute(t, JoinQuery{Expressions: []string{"$C"}})
+		require.NoError(t, err)
+		require.Equal(t, parse.TypeNoData, res.Values[0].Type())
+	})
+
+	t.Run("fails when input is a number", func(t *testing.T) {
+		_, err := execute(t, JoinQuery{Expressions: []string{"$A", "$D"}})
+		require.Error(t, err)
+	})
+}
+
+func fieldValues(f *data.Field) []*float64 {
+	values := make([]*float64, f.Len())
+	for i := range values {
+		values[i] = f.At(i).(*float64)
+	}
+	return values
+}
@@ -0,0 +1,269 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// aggregation combines the values of several series or numbers into one.
+type aggregation struct {
+	name string
+	// add accumulates a value into the running aggregate, which starts with the first value.
+	add func(agg, v float64) float64
+	// result turns the running aggregate of count values into the final value.
+	result func(agg float64, count int) float64
+}
+
+var (
+	aggSum = aggregation{
+		name:   "sum",
+		add:    func(agg, v float64) float64 { return agg + v },
+		result: func(agg float64, _ int) float64 { return agg },
+	}
+	aggAvg = aggregation{
+		name:   "avg",
+		add:    func(agg, v float64) float64 { return agg + v },
+		result: func(agg float64, count int) float64 { return agg / float64(count) },
+	}
+	aggMin = aggregation{
+		name:   "min",
+		add:    math.Min,
+		result: func(agg float64, _ int) float64 { return agg },
+	}
+	aggMax = aggregation{
+		name:   "max",
+		add:    math.Max,
+		result: func(agg float64, _ int) float64 { return agg },
+	}
+	aggCount = aggregation{
+		name:   "count",
+		add:    func(agg, _ float64) float64 { return agg },
+		result: func(_ float64, count int) float64 { return float64(count) },
+	}
+)
+
+// aggregator accumulates values for one group or one timestamp of a group.
+type aggregator struct {
+	agg   float64
+	count int
+}
+
+func (a *aggregator) add(fn aggregation, f *float64) {
+	if !HasValue(f) {
+		return
+	}
+	if a.count == 0 {
+		a.agg = *f
+	} else {
+		a.agg = fn.add(a.agg, *f)
+	}
+	a.count++
+}
+
+func (a *aggregator) value(fn aggregation) *float64 {
+	if a.count == 0 {
+		if fn.name == aggCount.name {
+			f := float64(0)
+			return &f
+		}
+		return nil
+	}
+	f := fn.result(a.agg, a.count)
+	return &f
+}
+
+// aggregateBy returns a function that aggregates a NumberSet or SeriesSet into one item per distinct set of values
+// of the given labels. If without is true, items are grouped by all labels except the given ones.
+func aggregateBy(fn aggregation, without bool) func(e *State, varSet Results, labels string) (Results, error) {
+	return func(e *State, varSet Results, labels string) (Results, error) {
+		names := parseLabelNames(labels)
+		groupLabels := func(l data.Labels) data.Labels {
+			return groupingLabels(l, names, without)
+		}
+		return aggregate(e, fn, varSet, groupLabels)
+	}
+}
+
+// aggregate groups the values in varSet by the labels returned by groupLabels and aggregates each group.
+// Series in a group are merged by timestamp. Null and NaN values are ignored.
+func aggregate(e *State, fn aggregation, varSet Results, groupLabels func(data.Labels) data.Labels) (Results, error) {
+	newRes := Results{}
+	type group struct {
+		labels data.Labels
+		values []Value
+	}
+	groups := make(map[string]*group)
+	order := make([]string, 0)
+	valType := parse.TypeNoData
+	for _, val := range varSet.Values {
+		switch val.Type() {
+		case parse.TypeNoData:
+			continue
+		case parse.TypeScalar:
+			// there is nothing to aggregate a scalar with
+			newRes.Values = append(newRes.Values, val)
+			continue
+		case parse.TypeNumberSet, parse.TypeSeriesSet:
+		default:
+			return newRes, fmt.Errorf("%s: can not aggregate type %v", fn.name, val.Type())
+		}
+		if valType != parse.TypeNoData && valType != val.Type() {
+			return newRes, fmt.Errorf("%s: can not aggregate %v with %v", fn.name, valType, val.Type())
+		}
+		valType = val.Type()
+		l := groupLabels(val.GetLabels())
+		key := l.String()
+		g, ok := groups[key]
+		if !ok {
+			g = &group{labels: l}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.values = append(g.values, val)
+	}
+
+	if len(order) == 0 && len(newRes.Values) == 0 {
+		newRes.Values = append(newRes.Values, NewNoData())
+		return newRes, nil
+	}
+
+	for _, key := range order {
+		g := groups[key]
+		switch valType {
+		case parse.TypeNumberSet:
+			agg := aggregator{}
+			for _, v := range g.values {
+				agg.add(fn, v.(Number).GetFloat64Value())
+			}
+			n := NewNumber(e.RefID, g.labels)
+			n.SetValue(agg.value(fn))
+			newRes.Values = append(newRes.Values, n)
+		case parse.TypeSeriesSet:
+			// the points are merged by their instant, since times with different locations are different map keys.
+			points := make(map[int64]*aggregator)
+			var times []time.Time
+			for _, v := range g.values {
+				s := v.(Series)
+				for i := 0; i < s.Len(); i++ {
+					t, f := s.GetPoint(i)
+					agg, ok := points[t.UnixNano()]
+					if !ok {
+						agg = &aggregator{}
+						points[t.UnixNano()] = agg
+						times = append(times, t)
+					}
+					agg.add(fn, f)
+				}
+			}
+			sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+			s := NewSeries(e.RefID, g.labels, len(times))
+			for i, t := range times {
+				s.SetPoint(i, t, points[t.UnixNano()].value(fn))
+			}
+			newRes.Values = append(newRes.Values, s)
+		}
+	}
+	return newRes, nil
+}
+
+// topK returns a function that keeps the k items of a NumberSet or SeriesSet with the highest (or lowest if bottom is true)
+// value. Series are ranked by the average of their values. Items without a value are ranked last.
+// If k is 0 or there are no items, the result is NoData.
+func topK(name string, bottom bool) func(e *State, varSet Results, kArg Results) (Results, error) {
+	return func(e *State, varSet Results, kArg Results) (Results, error) {
+		newRes := Results{}
+		kF, err := scalarArg(name, kArg)
+		if err != nil {
+			return newRes, err
+		}
+		if kF < 0 || kF != math.Trunc(kF) {
+			return newRes, fmt.Errorf("%s: k must be a non-negative integer, got %v", name, kF)
+		}
+		k := int(kF)
+
+		type ranked struct {
+			val   Value
+			score *float64
+		}
+		items := make([]ranked, 0, len(varSet.Values))
+		for _, val := range varSet.Values {
+			switch v := val.(type) {
+			case Number:
+				items = append(items, ranked{val: v, score: v.GetFloat64Value()})
+			case Series:
+				agg := aggregator{}
+				for i := 0; i < v.Len(); i++ {
+					agg.add(aggAvg, v.GetValue(i))
+				}
+				items = append(items, ranked{val: v, score: agg.value(aggAvg)})
+			case NoData:
+				continue
+			default:
+				return newRes, fmt.Errorf("%s: expected %v or %v, got %v", name, parse.TypeNumberSet, parse.TypeSeriesSet, val.Type())
+			}
+		}
+		if len(items) == 0 || k == 0 {
+			return NewNoDataResults(), nil
+		}
+
+		sort.SliceStable(items, func(i, j int) bool {
+			a, b := items[i].score, items[j].score
+			if !HasValue(a) {
+				return false
+			}
+			if !HasValue(b) {
+				return true
+			}
+			if bottom {
+				return *a < *b
+			}
+			return *a > *b
+		})
+		if k > len(items) {
+			k = len(items)
+		}
+		for _, item := range items[:k] {
+			newRes.Values = append(newRes.Values, item.val)
+		}
+		return newRes, nil
+	}
+}
+
+// groupingLabels returns the labels of l that are in names, or all other labels if without is true.
+func groupingLabels(l data.Labels, names []string, without bool) data.Labels {
+	result := data.Labels{}
+	if without {
+		for k, v := range l {
+			result[k] = v
+		}
+		for _, name := range names {
+			delete(result, name)
+		}
+		return result
+	}
+	for _, name := range names {
+		if v, ok := l[name]; ok {
+			result[name] = v
+		}
+	}
+	return result
+}
+
+// parseLabelNames parses a comma separated list of label names, e.g. "cluster, namespace".
+func parseLabelNames(labels string) []string {
+	names := make([]string, 0)
+	for _, name := range strings.Split(labels, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,175 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestAggregateFuncs(t *testing.T) {
+	seriesSet := Vars{
+		"A": resultValuesNoErr(
+			makeSeries("", data.Labels{"cluster": "a", "pod": "1"},
+				tp{time.Unix(5, 0), float64Pointer(1)},
+				tp{time.Unix(10, 0), float64Pointer(2)}),
+			makeSeries("", data.Labels{"cluster": "a", "pod": "2"},
+				tp{time.Unix(10, 0), float64Pointer(3)},
+				tp{time.Unix(15, 0), nil}),
+			makeSeries("", data.Labels{"cluster": "b", "pod": "1"},
+				tp{time.Unix(5, 0), float64Pointer(10)}),
+		),
+	}
+	numberSet := Vars{
+		"A": resultValuesNoErr(
+			makeNumber("", data.Labels{"cluster": "a", "pod": "1"}, float64Pointer(1)),
+			makeNumber("", data.Labels{"cluster": "a", "pod": "2"}, float64Pointer(3)),
+			makeNumber("", data.Labels{"cluster": "b", "pod": "1"}, float64Pointer(10)),
+			makeNumber("", data.Labels{"cluster": "b", "pod": "2"}, nil),
+		),
+	}
+
+	var tests = []struct {
+		name    string
+		expr    string
+		vars    Vars
+		results Results
+	}{
+		{
+			name: "sum_by on series merges timestamps",
+			expr: `sum_by($A, "cluster")`,
+			vars: seriesSet,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"cluster": "a"},
+					tp{time.Unix(5, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), float64Pointer(5)},
+					tp{time.Unix(15, 0), nil}),
+				makeSeries("", data.Labels{"cluster": "b"},
+					tp{time.Unix(5, 0), float64Pointer(10)}),
+			),
+		},
+		{
+			name: "max_without on series",
+			expr: `max_without($A, "cluster")`,
+			vars: seriesSet,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"pod": "1"},
+					tp{time.Unix(5, 0), float64Pointer(10)},
+					tp{time.Unix(10, 0), float64Pointer(2)}),
+				makeSeries("", data.Labels{"pod": "2"},
+					tp{time.Unix(10, 0), float64Pointer(3)},
+					tp{time.Unix(15, 0), nil}),
+			),
+		},
+		{
+			name: "avg_by on numbers ignores nulls",
+			expr: `avg_by($A, "cluster")`,
+			vars: numberSet,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"cluster": "a"}, float64Pointer(2)),
+				makeNumber("", data.Labels{"cluster": "b"}, float64Pointer(10)),
+			),
+		},
+		{
+			name: "count_by without labels aggregates everything",
+			expr: `count_by($A, "")`,
+			vars: numberSet,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{}, float64Pointer(3)),
+			),
+		},
+		{
+			name: "min_by with several labels",
+			expr: `min_by($A, "cluster, pod")`,
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeNumber("", data.Labels{"cluster": "a", "pod": "1", "host": "x"}, float64Pointer(4)),
+					makeNumber("", data.Labels{"cluster": "a", "pod": "1", "host": "y"}, float64Pointer(2)),
+				),
+			},
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"cluster": "a", "pod": "1"}, float64Pointer(2)),
+			),
+		},
+		{
+			name: "topk on numbers ranks nulls last",
+			expr: `topk($A, 2)`,
+			vars: numberSet,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"cluster": "b", "pod": "1"}, float64Pointer(10)),
+				makeNumber("", data.Labels{"cluster": "a", "pod": "2"}, float64Pointer(3)),
+			),
+		},
+		{
+			name: "bottomk on series ranks by average",
+			expr: `bottomk($A, 1)`,
+			vars: seriesSet,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"cluster": "a", "pod": "1"},
+					tp{time.Unix(5, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), float64Pointer(2)}),
+			),
+		},
+		{
+			name:    "topk with k 0 returns no data",
+			expr:    `topk($A, 0)`,
+			vars:    numberSet,
+			results: NewNoDataResults(),
+		},
+		{
+			name:    "bottomk with k 0 returns no data",
+			expr:    `bottomk($A, 0)`,
+			vars:    seriesSet,
+			results: NewNoDataResults(),
+		},
+		{
+			name: "sum_by on no data",
+			expr: `sum_by($A, "cluster")`,
+			vars: Vars{
+				"A": resultValuesNoErr(NewNoData()),
+			},
+			results: resultValuesNoErr(NewNoData()),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+			require.Equal(t, tt.results, res)
+		})
+	}
+
+	t.Run("sum_by merges points of the same instant in different locations", func(t *testing.T) {
+		vars := Vars{
+			"A": resultValuesNoErr(
+				makeSeries("", data.Labels{"pod": "1"}, tp{time.Unix(5, 0).UTC(), float64Pointer(1)}),
+				makeSeries("", data.Labels{"pod": "2"}, tp{time.Unix(5, 0).In(time.FixedZone("CET", 3600)), float64Pointer(2)}),
+			),
+		}
+		e, err := New(`sum_by($A, "")`)
+		require.NoError(t, err)
+		res, err := e.Execute("", vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		s := res.Values[0].(Series)
+		require.Equal(t, 1, s.Len())
+		require.Equal(t, 3.0, *s.GetValue(0))
+	})
+
+	t.Run("topk with a negative k should error", func(t *testing.T) {
+		e, err := New(`topk($A, -1)`)
+		require.NoError(t, err)
+		_, err = e.Execute("", numberSet, tracing.InitializeTracerForTest())
+		require.Error(t, err)
+	})
+
+	t.Run("sum_by without labels argument should error", func(t *testing.T) {
+		_, err := New(`sum_by($A)`)
+		require.Error(t, err)
+	})
+}
@@ -1,7 +1,11 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
@@ -68,6 +72,66 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"clamp": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clamp,
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+	},
+	"cumsum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      cumSum,
+	},
+	"diff": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      diff,
+	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      rate,
+	},
+	"shift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      shift,
+	},
+	"sum_by":        aggregateFunc(aggregateBy(aggSum, false)),
+	"sum_without":   aggregateFunc(aggregateBy(aggSum, true)),
+	"avg_by":        aggregateFunc(aggregateBy(aggAvg, false)),
+	"avg_without":   aggregateFunc(aggregateBy(aggAvg, true)),
+	"min_by":        aggregateFunc(aggregateBy(aggMin, false)),
+	"min_without":   aggregateFunc(aggregateBy(aggMin, true)),
+	"max_by":        aggregateFunc(aggregateBy(aggMax, false)),
+	"max_without":   aggregateFunc(aggregateBy(aggMax, true)),
+	"count_by":      aggregateFunc(aggregateBy(aggCount, false)),
+	"count_without": aggregateFunc(aggregateBy(aggCount, true)),
+	"topk": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             topK("topk", false),
+	},
+	"bottomk": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             topK("bottomk", true),
+	},
+}
+
+// aggregateFunc declares a grouping aggregation that takes a NumberSet or SeriesSet
+// and a comma separated list of label names, e.g. sum_by($A, "cluster").
+func aggregateFunc(f func(e *State, varSet Results, labels string) (Results, error)) parse.Func {
+	return parse.Func{
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		F:             f,
+	}
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
@@ -307,3 +371,203 @@ func floor(e *State, varSet Results) (Results, error) {
 	}
 	return newRes, nil
 }
+
+// clamp limits the value for each result in NumberSet, SeriesSet, or Scalar to the range [lo, hi].
+// Null and NaN values are returned as is.
+func clamp(e *State, varSet Results, lo, hi Results) (Results, error) {
+	newRes := Results{}
+	loF, err := scalarArg("clamp", lo)
+	if err != nil {
+		return newRes, err
+	}
+	hiF, err := scalarArg("clamp", hi)
+	if err != nil {
+		return newRes, err
+	}
+	if loF > hiF {
+		return newRes, fmt.Errorf("clamp: lower bound %v is greater than upper bound %v", loF, hiF)
+	}
+	for _, res := range varSet.Values {
+		newVal, err := perNullableFloat(e, res, func(f *float64) *float64 {
+			if f == nil || math.IsNaN(*f) {
+				return f
+			}
+			nF := math.Min(math.Max(*f, loF), hiF)
+			return &nF
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// movingAvg returns for each point of each series the average of the points within the preceding window,
+// including the point itself. Null and NaN values are ignored, a window without any number is null.
+func movingAvg(e *State, varSet Results, rawWindow string) (Results, error) {
+	window, err := durationArg("moving_avg", rawWindow)
+	if err != nil {
+		return Results{}, err
+	}
+	if window <= 0 {
+		return Results{}, fmt.Errorf("moving_avg: window must be positive, got %s", rawWindow)
+	}
+	return perSeries(e, "moving_avg", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		var sum float64
+		var count int
+		start := 0
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if HasValue(f) {
+				sum += *f
+				count++
+			}
+			for ; !s.GetTime(start).After(t.Add(-window)); start++ {
+				if v := s.GetValue(start); HasValue(v) {
+					sum -= *v
+					count--
+				}
+			}
+			var avg *float64
+			if count > 0 {
+				a := sum / float64(count)
+				avg = &a
+			}
+			newSeries.SetPoint(i, t, avg)
+		}
+		return newSeries
+	})
+}
+
+// cumSum returns the running total of each series. Null and NaN points are returned as is
+// and do not contribute to the total.
+func cumSum(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "cumsum", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		var sum float64
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if !HasValue(f) {
+				newSeries.SetPoint(i, t, f)
+				continue
+			}
+			sum += *f
+			nF := sum
+			newSeries.SetPoint(i, t, &nF)
+		}
+		return newSeries
+	})
+}
+
+// diff returns the difference between each point of each series and the previous number in the series.
+// The first number is null. Null and NaN points are returned as is.
+func diff(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "diff", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		var prev *float64
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if !HasValue(f) {
+				newSeries.SetPoint(i, t, f)
+				continue
+			}
+			var d *float64
+			if prev != nil {
+				nF := *f - *prev
+				d = &nF
+			}
+			newSeries.SetPoint(i, t, d)
+			prev = f
+		}
+		return newSeries
+	})
+}
+
+// rate returns the per-second rate of increase between each point of each series and the previous number
+// in the series. Like the rate reducer, a decrease is treated as a counter reset.
+// The first number is null. Null and NaN points are returned as is.
+func rate(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "rate", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		var prev *float64
+		var prevT time.Time
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if !HasValue(f) {
+				newSeries.SetPoint(i, t, f)
+				continue
+			}
+			var r *float64
+			if seconds := t.Sub(prevT).Seconds(); prev != nil && seconds > 0 {
+				increase := *f - *prev
+				if *f < *prev {
+					increase = *f
+				}
+				nF := increase / seconds
+				r = &nF
+			}
+			newSeries.SetPoint(i, t, r)
+			prev, prevT = f, t
+		}
+		return newSeries
+	})
+}
+
+// shift moves each series in time by the given duration. A positive duration moves points into the future.
+func shift(e *State, varSet Results, rawOffset string) (Results, error) {
+	offset, err := durationArg("shift", rawOffset)
+	if err != nil {
+		return Results{}, err
+	}
+	return perSeries(e, "shift", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			newSeries.SetPoint(i, t.Add(offset), f)
+		}
+		return newSeries
+	})
+}
+
+// perSeries passes each Series in varSet, sorted by time in ascending order, to seriesF.
+// NoData is passed through, other types are an error.
+func perSeries(e *State, name string, varSet Results, seriesF func(s Series) Series) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		switch v := res.(type) {
+		case Series:
+			newRes.Values = append(newRes.Values, seriesF(v.SortedByTime()))
+		case NoData:
+			newRes.Values = append(newRes.Values, NewNoData())
+		default:
+			return newRes, fmt.Errorf("%s: expected %v, got %v", name, parse.TypeSeriesSet, res.Type())
+		}
+	}
+	return newRes, nil
+}
+
+func scalarArg(name string, res Results) (float64, error) {
+	if len(res.Values) != 1 || res.Values[0].Type() != parse.TypeScalar {
+		return 0, fmt.Errorf("%s: expected a single scalar argument", name)
+	}
+	f := res.Values[0].(Scalar).GetFloat64Value()
+	if f == nil {
+		return 0, fmt.Errorf("%s: scalar argument is null", name)
+	}
+	return *f, nil
+}
+
+func durationArg(name string, raw string) (time.Duration, error) {
+	d, err := gtime.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to parse duration %q: %w", name, raw, err)
+	}
+	return d, nil
+}
+
+// HasValue returns true if f is neither null nor NaN.
+func HasValue(f *float64) bool {
+	return f != nil && !math.IsNaN(*f)
+}
@@ -140,3 +140,192 @@ func TestIsNumberFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestSeriesWindowFuncs(t *testing.T) {
+	input := Vars{
+		"A": resultValuesNoErr(
+			makeSeries("", nil,
+				tp{time.Unix(0, 0), float64Pointer(1)},
+				tp{time.Unix(60, 0), float64Pointer(3)},
+				tp{time.Unix(120, 0), nil},
+				tp{time.Unix(180, 0), float64Pointer(8)},
+				tp{time.Unix(240, 0), float64Pointer(2)}),
+		),
+	}
+
+	var tests = []struct {
+		name     string
+		expr     string
+		vars     Vars
+		newErrIs require.ErrorAssertionFunc
+		results  Results
+	}{
+		{
+			name: "moving_avg over a window ignores nulls",
+			expr: "moving_avg($A, 2m)",
+			vars: input,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(60, 0), float64Pointer(2)},
+					tp{time.Unix(120, 0), float64Pointer(3)},
+					tp{time.Unix(180, 0), float64Pointer(8)},
+					tp{time.Unix(240, 0), float64Pointer(5)}),
+			),
+		},
+		{
+			name: "moving_avg with a quoted window",
+			expr: `moving_avg($A, "1m")`,
+			vars: input,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(60, 0), float64Pointer(3)},
+					tp{time.Unix(120, 0), nil},
+					tp{time.Unix(180, 0), float64Pointer(8)},
+					tp{time.Unix(240, 0), float64Pointer(2)}),
+			),
+		},
+		{
+			name: "cumsum skips nulls",
+			expr: "cumsum($A)",
+			vars: input,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(60, 0), float64Pointer(4)},
+					tp{time.Unix(120, 0), nil},
+					tp{time.Unix(180, 0), float64Pointer(12)},
+					tp{time.Unix(240, 0), float64Pointer(14)}),
+			),
+		},
+		{
+			name: "diff against the previous number",
+			expr: "diff($A)",
+			vars: input,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), nil},
+					tp{time.Unix(60, 0), float64Pointer(2)},
+					tp{time.Unix(120, 0), nil},
+					tp{time.Unix(180, 0), float64Pointer(5)},
+					tp{time.Unix(240, 0), float64Pointer(-6)}),
+			),
+		},
+		{
+			name: "rate treats a decrease as a counter reset",
+			expr: "rate($A)",
+			vars: input,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), nil},
+					tp{time.Unix(60, 0), float64Pointer(2.0 / 60)},
+					tp{time.Unix(120, 0), nil},
+					tp{time.Unix(180, 0), float64Pointer(5.0 / 120)},
+					tp{time.Unix(240, 0), float64Pointer(2.0 / 60)}),
+			),
+		},
+		{
+			name: "shift moves points in time",
+			expr: "shift($A, 1h)",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", nil, tp{time.Unix(0, 0), float64Pointer(1)}),
+				),
+			},
+			results: resultValuesNoErr(
+				makeSeries("", nil, tp{time.Unix(3600, 0), float64Pointer(1)}),
+			),
+		},
+		{
+			name: "clamp on series keeps nulls",
+			expr: "clamp($A, 2, 5)",
+			vars: input,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(2)},
+					tp{time.Unix(60, 0), float64Pointer(3)},
+					tp{time.Unix(120, 0), nil},
+					tp{time.Unix(180, 0), float64Pointer(5)},
+					tp{time.Unix(240, 0), float64Pointer(2)}),
+			),
+		},
+		{
+			name: "clamp on number with negative bound",
+			expr: "clamp($A, -1, 1)",
+			vars: Vars{
+				"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(-7))),
+			},
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(-1))),
+		},
+		{
+			name: "cumsum sorts unsorted series by time",
+			expr: "cumsum($A)",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", nil,
+						tp{time.Unix(60, 0), float64Pointer(3)},
+						tp{time.Unix(0, 0), float64Pointer(1)}),
+				),
+			},
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(60, 0), float64Pointer(4)}),
+			),
+		},
+		{
+			name:     "moving_avg with a fractional window should error",
+			expr:     "moving_avg($A, 1.5d)",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "moving_avg without a window should error",
+			expr:     "moving_avg($A)",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "shift with a number instead of a duration should error",
+			expr:     "shift($A, 5)",
+			newErrIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			if tt.newErrIs != nil {
+				tt.newErrIs(t, err)
+				return
+			}
+			require.NoError(t, err)
+			res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+			require.Equal(t, tt.results, res)
+		})
+	}
+
+	t.Run("series functions on a number should error", func(t *testing.T) {
+		e, err := New("cumsum($A)")
+		require.NoError(t, err)
+		_, err = e.Execute("", Vars{
+			"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(1))),
+		}, tracing.InitializeTracerForTest())
+		require.Error(t, err)
+	})
+
+	t.Run("clamp with lower bound greater than upper bound should error", func(t *testing.T) {
+		e, err := New("clamp($A, 5, 2)")
+		require.NoError(t, err)
+		_, err = e.Execute("", input, tracing.InitializeTracerForTest())
+		require.Error(t, err)
+	})
+}
+
+func TestFuncArgumentSeparators(t *testing.T) {
+	_, err := New("clamp($A 1, 2)")
+	require.Error(t, err)
+	_, err = New("clamp($A, 1, 2,)")
+	require.Error(t, err)
+	_, err = New("nan()")
+	require.NoError(t, err)
+}
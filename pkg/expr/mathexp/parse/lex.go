@@ -56,8 +56,9 @@ const (
 	itemRightParen
 	itemString
 	itemFunc
-	itemVar // e.g. $A
-	itemPow // '**'
+	itemVar      // e.g. $A
+	itemPow      // '**'
+	itemDuration // e.g. 5m
 )
 
 const eof = -1
@@ -210,10 +211,23 @@ func lexNumber(l *lexer) stateFn {
 	if !l.scanNumber() {
 		return l.errorf("bad number syntax: %q", l.input[l.start:l.pos])
 	}
+	// A number directly followed by a unit is a duration, e.g. 5m or 1h.
+	// Only integers can have a unit, since fractions like 1.5d are not supported by the duration parser.
+	number := l.input[l.start:l.pos]
+	if l.accept(durationUnits) {
+		l.acceptRun(durationUnits)
+		if strings.Trim(number, "0123456789") != "" || unicode.IsLetter(l.peek()) {
+			return l.errorf("bad duration syntax: %q", l.input[l.start:l.pos])
+		}
+		l.emit(itemDuration)
+		return lexItem
+	}
 	l.emit(itemNumber)
 	return lexItem
 }
 
+const durationUnits = "nuµsmhdwyM"
+
 func (l *lexer) scanNumber() bool {
 	// Is it hex?
 	digits := "0123456789"
@@ -33,6 +33,7 @@ var itemName = map[itemType]string{
 	itemRightParen: ")",
 	itemString:     "string",
 	itemFunc:       "func",
+	itemDuration:   "duration",
 }
 
 func (i itemType) String() string {
@@ -113,7 +114,28 @@ var lexTests = []lexTest{
 		{itemVar, 0, "$A"},
 		tEOF,
 	}},
+	{"durations", "5m 1h 500ms", []item{
+		{itemDuration, 0, "5m"},
+		{itemDuration, 0, "1h"},
+		{itemDuration, 0, "500ms"},
+		tEOF,
+	}},
+	{"func with duration", "moving_avg($A, 5m)", []item{
+		{itemFunc, 0, "moving_avg"},
+		{itemLeftParen, 0, "("},
+		{itemVar, 0, "$A"},
+		{itemComma, 0, ","},
+		{itemDuration, 0, "5m"},
+		{itemRightParen, 0, ")"},
+		tEOF,
+	}},
 	// errors
+	{"bad duration", "5mx", []item{
+		{itemError, 0, `bad duration syntax: "5m"`},
+	}},
+	{"fractional duration", "1.5d", []item{
+		{itemError, 0, `bad duration syntax: "1.5d"`},
+	}},
 	{"unclosed quote", "\"", []item{
 		{itemError, 0, "unterminated string"},
 	}},
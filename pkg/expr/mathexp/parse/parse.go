@@ -174,7 +174,7 @@ E -> F {( "**" ) F}
 F -> v | "(" O ")" | "!" O | "-" O
 v -> number | func(..) | queryVar
 Func -> name "(" param {"," param} ")"
-param -> number | "string" | queryVar
+param -> number | "string" | duration | queryVar
 */
 
 // expr:
@@ -314,6 +314,10 @@ func (t *Tree) Func() (f *FuncNode) {
 	}
 	f = newFunc(token.pos, token.val, funcv)
 	t.expect(itemLeftParen, "func")
+	if t.peek().typ == itemRightParen {
+		t.next()
+		return
+	}
 	for {
 		switch token = t.next(); token.typ {
 		default:
@@ -329,8 +333,17 @@ func (t *Tree) Func() (f *FuncNode) {
 				t.errorf("Unquoting error: %s", err)
 			}
 			f.append(newString(token.pos, token.val, s))
+		case itemDuration:
+			// durations are passed to functions as strings, e.g. moving_avg($A, 5m)
+			f.append(newString(token.pos, token.val, token.val))
+		}
+		switch token = t.next(); token.typ {
+		case itemComma:
+			// next argument
 		case itemRightParen:
 			return
+		default:
+			t.unexpected(token, "func")
 		}
 	}
 }
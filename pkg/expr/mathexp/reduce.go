@@ -3,6 +3,7 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"sort"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -20,11 +21,39 @@ const (
 	ReducerMax   ReducerID = "max"
 	ReducerCount ReducerID = "count"
 	ReducerLast  ReducerID = "last"
+
+	ReducerFirst        ReducerID = "first"
+	ReducerMedian       ReducerID = "median"
+	ReducerPercentile   ReducerID = "percentile"
+	ReducerStdDev       ReducerID = "stddev"
+	ReducerVariance     ReducerID = "variance"
+	ReducerDelta        ReducerID = "delta"
+	ReducerIncrease     ReducerID = "increase"
+	ReducerRate         ReducerID = "rate"
+	ReducerCountNonNull ReducerID = "count_non_null"
 )
 
 // GetSupportedReduceFuncs returns collection of supported function names
 func GetSupportedReduceFuncs() []ReducerID {
-	return []ReducerID{ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast}
+	return []ReducerID{
+		ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast,
+		ReducerFirst, ReducerMedian, ReducerPercentile, ReducerStdDev, ReducerVariance,
+		ReducerDelta, ReducerIncrease, ReducerRate, ReducerCountNonNull,
+	}
+}
+
+// ReduceOptions holds the parameters of reducers that need them.
+type ReduceOptions struct {
+	// Percentile is the percentile (0-100) calculated by the percentile reducer.
+	Percentile float64
+}
+
+// Validate checks that the options are valid for the given reducer.
+func (o ReduceOptions) Validate(rFunc ReducerID) error {
+	if rFunc == ReducerPercentile && (math.IsNaN(o.Percentile) || o.Percentile < 0 || o.Percentile > 100) {
+		return fmt.Errorf("percentile must be between 0 and 100, got %v", o.Percentile)
+	}
+	return nil
 }
 
 func Sum(fv *Float64Field) *float64 {
@@ -98,7 +127,136 @@ func Last(fv *Float64Field) *float64 {
 	return fv.GetValue(fv.Len() - 1)
 }
 
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+// CountNonNull returns the number of values that are neither null nor NaN.
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v != nil && !math.IsNaN(*v) {
+			f++
+		}
+	}
+	return &f
+}
+
+// Median returns the 50th percentile of the values.
+func Median(fv *Float64Field) *float64 {
+	return Percentile(50)(fv)
+}
+
+// Percentile returns a reducer that calculates the p-th percentile (0-100) of the values,
+// interpolating linearly between the closest ranks.
+func Percentile(p float64) ReducerFunc {
+	return func(fv *Float64Field) *float64 {
+		vals, ok := numbers(fv)
+		if !ok || len(vals) == 0 {
+			nan := math.NaN()
+			return &nan
+		}
+		sort.Float64s(vals)
+		rank := p / 100 * float64(len(vals)-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+		f := vals[lower] + (vals[upper]-vals[lower])*(rank-float64(lower))
+		return &f
+	}
+}
+
+// Variance returns the population variance of the values.
+func Variance(fv *Float64Field) *float64 {
+	vals, ok := numbers(fv)
+	if !ok || len(vals) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	var mean float64
+	for _, v := range vals {
+		mean += v
+	}
+	mean /= float64(len(vals))
+	var f float64
+	for _, v := range vals {
+		f += (v - mean) * (v - mean)
+	}
+	f /= float64(len(vals))
+	return &f
+}
+
+// StdDev returns the population standard deviation of the values.
+func StdDev(fv *Float64Field) *float64 {
+	f := Variance(fv)
+	*f = math.Sqrt(*f)
+	return f
+}
+
+// Delta returns the difference between the last and the first value.
+func Delta(fv *Float64Field) *float64 {
+	vals, ok := numbers(fv)
+	if !ok || len(vals) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	f := vals[len(vals)-1] - vals[0]
+	return &f
+}
+
+// Increase returns the increase of a counter over the values. A value lower than the previous one
+// is treated as a counter reset, like in Prometheus.
+func Increase(fv *Float64Field) *float64 {
+	vals, ok := numbers(fv)
+	if !ok || len(vals) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	var f float64
+	for i := 1; i < len(vals); i++ {
+		if vals[i] < vals[i-1] {
+			f += vals[i]
+			continue
+		}
+		f += vals[i] - vals[i-1]
+	}
+	return &f
+}
+
+// numbers returns the values of the field. It returns false if any value is null or NaN.
+func numbers(fv *Float64Field) ([]float64, bool) {
+	vals := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return nil, false
+		}
+		vals = append(vals, *v)
+	}
+	return vals, true
+}
+
+// GetReduceFunc returns the reducer function for the given reducer.
+// Reducers that need parameters, such as percentile, are not supported. Use GetReduceFuncWithOptions for them.
 func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
+	if rFunc == ReducerPercentile {
+		return nil, fmt.Errorf("reduction %v requires a percentile, use GetReduceFuncWithOptions", rFunc)
+	}
+	return GetReduceFuncWithOptions(rFunc, ReduceOptions{})
+}
+
+// GetReduceFuncWithOptions returns the reducer function for the given reducer and options.
+// The rate reducer depends on timestamps and therefore is not available as ReducerFunc,
+// it returns the increase of the values, which Series.Reduce divides by the time span of the series.
+func GetReduceFuncWithOptions(rFunc ReducerID, opts ReduceOptions) (ReducerFunc, error) {
+	if err := opts.Validate(rFunc); err != nil {
+		return nil, err
+	}
 	switch rFunc {
 	case ReducerSum:
 		return Sum, nil
@@ -112,6 +270,22 @@ func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 		return Count, nil
 	case ReducerLast:
 		return Last, nil
+	case ReducerFirst:
+		return First, nil
+	case ReducerMedian:
+		return Median, nil
+	case ReducerPercentile:
+		return Percentile(opts.Percentile), nil
+	case ReducerStdDev:
+		return StdDev, nil
+	case ReducerVariance:
+		return Variance, nil
+	case ReducerDelta:
+		return Delta, nil
+	case ReducerIncrease, ReducerRate:
+		return Increase, nil
+	case ReducerCountNonNull:
+		return CountNonNull, nil
 	default:
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
@@ -120,24 +294,20 @@ func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 // Reduce turns the Series into a Number based on the given reduction function
 // if ReduceMapper is defined it applies it to the provided series and performs reduction of the resulting series.
 // Otherwise, the reduction operation is done against the original series.
-func (s Series) Reduce(refID string, rFunc ReducerID, mapper ReduceMapper) (Number, error) {
+func (s Series) Reduce(refID string, rFunc ReducerID, opts ReduceOptions, mapper ReduceMapper) (Number, error) {
 	var l data.Labels
 	if s.GetLabels() != nil {
 		l = s.GetLabels().Copy()
 	}
 	number := NewNumber(refID, l)
-	var f *float64
 	series := s
 	if mapper != nil {
 		series = mapSeries(s, mapper)
 	}
-	fVec := series.Frame.Fields[seriesTypeValIdx]
-	floatField := Float64Field(*fVec)
-	reduceFunc, err := GetReduceFunc(rFunc)
+	f, err := series.reduce(rFunc, opts)
 	if err != nil {
 		return number, fmt.Errorf("invalid expression '%s': %w", refID, err)
 	}
-	f = reduceFunc(&floatField)
 	if f != nil && mapper != nil {
 		f = mapper.MapOutput(f)
 	}
@@ -145,6 +315,31 @@ func (s Series) Reduce(refID string, rFunc ReducerID, mapper ReduceMapper) (Numb
 	return number, nil
 }
 
+// reduce applies the reducer to the values of the series.
+func (s Series) reduce(rFunc ReducerID, opts ReduceOptions) (*float64, error) {
+	reduceFunc, err := GetReduceFuncWithOptions(rFunc, opts)
+	if err != nil {
+		return nil, err
+	}
+	fVec := s.Frame.Fields[seriesTypeValIdx]
+	floatField := Float64Field(*fVec)
+	f := reduceFunc(&floatField)
+	if rFunc == ReducerRate && f != nil && !math.IsNaN(*f) {
+		if s.Len() < 2 {
+			nan := math.NaN()
+			return &nan, nil
+		}
+		seconds := s.GetTime(s.Len() - 1).Sub(s.GetTime(0)).Seconds()
+		if seconds <= 0 {
+			nan := math.NaN()
+			return &nan, nil
+		}
+		rate := *f / seconds
+		f = &rate
+	}
+	return f, nil
+}
+
 type ReduceMapper interface {
 	MapInput(s *float64) *float64
 	MapOutput(v *float64) *float64
@@ -186,7 +186,7 @@ func TestSeriesReduce(t *testing.T) {
 			results := Results{}
 			seriesSet := tt.vars[tt.varToReduce]
 			for _, series := range seriesSet.Values {
-				ns, err := series.Value().(*Series).Reduce("", tt.red, nil)
+				ns, err := series.Value().(*Series).Reduce("", tt.red, ReduceOptions{}, nil)
 				tt.errIs(t, err)
 				if err != nil {
 					return
@@ -285,7 +285,7 @@ func TestSeriesReduceDropNN(t *testing.T) {
 			results := Results{}
 			seriesSet := tt.vars[tt.varToReduce]
 			for _, series := range seriesSet.Values {
-				ns, err := series.Value().(*Series).Reduce("", tt.red, DropNonNumber{})
+				ns, err := series.Value().(*Series).Reduce("", tt.red, ReduceOptions{}, DropNonNumber{})
 				require.NoError(t, err)
 				results.Values = append(results.Values, ns)
 			}
@@ -372,7 +372,7 @@ func TestSeriesReduceReplaceNN(t *testing.T) {
 			results := Results{}
 			seriesSet := tt.vars[tt.varToReduce]
 			for _, series := range seriesSet.Values {
-				ns, err := series.Value().(*Series).Reduce("", tt.red, ReplaceNonNumberWithValue{Value: replaceWith})
+				ns, err := series.Value().(*Series).Reduce("", tt.red, ReduceOptions{}, ReplaceNonNumberWithValue{Value: replaceWith})
 				require.NoError(t, err)
 				results.Values = append(results.Values, ns)
 			}
@@ -386,3 +386,143 @@ func TestSeriesReduceReplaceNN(t *testing.T) {
 		})
 	}
 }
+
+func TestGetReduceFunc(t *testing.T) {
+	f, err := GetReduceFunc(ReducerMedian)
+	require.NoError(t, err)
+	require.NotNil(t, f)
+
+	_, err = GetReduceFunc(ReducerPercentile)
+	require.ErrorContains(t, err, "requires a percentile")
+
+	f, err = GetReduceFuncWithOptions(ReducerPercentile, ReduceOptions{Percentile: 95})
+	require.NoError(t, err)
+	require.NotNil(t, f)
+}
+
+func TestSeriesReduceStatistics(t *testing.T) {
+	series := makeSeries("temp", nil,
+		tp{time.Unix(0, 0), float64Pointer(4)},
+		tp{time.Unix(10, 0), float64Pointer(1)},
+		tp{time.Unix(20, 0), float64Pointer(3)},
+		tp{time.Unix(30, 0), float64Pointer(2)},
+		tp{time.Unix(40, 0), float64Pointer(10)},
+	)
+
+	var tests = []struct {
+		name     string
+		red      ReducerID
+		opts     ReduceOptions
+		series   Series
+		expected *float64
+	}{
+		{
+			name:     "first",
+			red:      ReducerFirst,
+			series:   series,
+			expected: float64Pointer(4),
+		},
+		{
+			name:     "first empty series",
+			red:      ReducerFirst,
+			series:   makeSeries("temp", nil),
+			expected: NaN,
+		},
+		{
+			name:     "median",
+			red:      ReducerMedian,
+			series:   series,
+			expected: float64Pointer(3),
+		},
+		{
+			name:     "percentile interpolates between ranks",
+			red:      ReducerPercentile,
+			opts:     ReduceOptions{Percentile: 90},
+			series:   series,
+			expected: float64Pointer(7.6),
+		},
+		{
+			name:     "percentile 0 is the minimum",
+			red:      ReducerPercentile,
+			opts:     ReduceOptions{Percentile: 0},
+			series:   series,
+			expected: float64Pointer(1),
+		},
+		{
+			name:     "percentile of empty series",
+			red:      ReducerPercentile,
+			opts:     ReduceOptions{Percentile: 95},
+			series:   makeSeries("temp", nil),
+			expected: NaN,
+		},
+		{
+			name:     "variance",
+			red:      ReducerVariance,
+			series:   series,
+			expected: float64Pointer(10),
+		},
+		{
+			name:     "stddev",
+			red:      ReducerStdDev,
+			series:   series,
+			expected: float64Pointer(math.Sqrt(10)),
+		},
+		{
+			name:     "stddev with a nil value",
+			red:      ReducerStdDev,
+			series:   seriesWithNil["A"].Values[0].(Series),
+			expected: NaN,
+		},
+		{
+			name:     "delta",
+			red:      ReducerDelta,
+			series:   series,
+			expected: float64Pointer(6),
+		},
+		{
+			name:     "increase handles counter resets",
+			red:      ReducerIncrease,
+			series:   series,
+			expected: float64Pointer(13),
+		},
+		{
+			name:     "rate is increase per second",
+			red:      ReducerRate,
+			series:   series,
+			expected: float64Pointer(0.325),
+		},
+		{
+			name: "rate of single point",
+			red:  ReducerRate,
+			series: makeSeries("temp", nil,
+				tp{time.Unix(0, 0), float64Pointer(4)},
+			),
+			expected: NaN,
+		},
+		{
+			name:     "count_non_null",
+			red:      ReducerCountNonNull,
+			series:   seriesNonNumbers["A"].Values[0].(Series),
+			expected: float64Pointer(2),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := tt.series.Reduce("", tt.red, tt.opts, nil)
+			require.NoError(t, err)
+			actual := n.GetFloat64Value()
+			require.NotNil(t, actual)
+			if math.IsNaN(*tt.expected) {
+				require.True(t, math.IsNaN(*actual))
+				return
+			}
+			require.InDelta(t, *tt.expected, *actual, 1e-9)
+		})
+	}
+
+	t.Run("percentile out of range should error", func(t *testing.T) {
+		_, err := series.Reduce("", ReducerPercentile, ReduceOptions{Percentile: 101}, nil)
+		require.Error(t, err)
+	})
+}
@@ -23,7 +23,7 @@ const (
 )
 
 // Resample turns the Series into a Number based on the given reduction function
-func (s Series) Resample(refID string, interval time.Duration, downsampler ReducerID, upsampler Upsampler, from, to time.Time) (Series, error) {
+func (s Series) Resample(refID string, interval time.Duration, downsampler ReducerID, opts ReduceOptions, upsampler Upsampler, from, to time.Time) (Series, error) {
 	newSeriesLength := int(float64(to.Sub(from).Nanoseconds()) / float64(interval.Nanoseconds()))
 	if newSeriesLength <= 0 {
 		return s, fmt.Errorf("the series cannot be sampled further; the time range is shorter than the interval")
@@ -35,6 +35,7 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 	t := from
 	for !t.After(to) && idx <= newSeriesLength {
 		vals := make([]*float64, 0)
+		times := make([]time.Time, 0)
 		sIdx := bookmark
 		for {
 			if sIdx == s.Len() {
@@ -48,6 +49,7 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 			sIdx++
 			lastSeen = v
 			vals = append(vals, v)
+			times = append(times, st)
 		}
 		var value *float64
 		if len(vals) == 0 { // upsampling
@@ -72,22 +74,13 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 		} else if len(vals) == 1 {
 			value = vals[0]
 		} else { // downsampling
-			fVec := data.NewField("", s.GetLabels(), vals)
-			ff := Float64Field(*fVec)
-			var tmp *float64
-			switch downsampler {
-			case ReducerSum:
-				tmp = Sum(&ff)
-			case ReducerMean:
-				tmp = Avg(&ff)
-			case ReducerMin:
-				tmp = Min(&ff)
-			case ReducerMax:
-				tmp = Max(&ff)
-			case ReducerLast:
-				tmp = Last(&ff)
-			default:
-				return s, fmt.Errorf("downsampling %v not implemented", downsampler)
+			window := Series{Frame: data.NewFrame("",
+				data.NewField("Time", nil, times),
+				data.NewField("", s.GetLabels(), vals),
+			)}
+			tmp, err := window.reduce(downsampler, opts)
+			if err != nil {
+				return s, fmt.Errorf("invalid downsampler: %w", err)
 			}
 			value = tmp
 		}
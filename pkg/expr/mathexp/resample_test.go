@@ -277,10 +277,36 @@ func TestResampleSeries(t *testing.T) {
 				time.Unix(9, 0), float64Pointer(0),
 			}),
 		},
+		{
+			name:        "resample series: downsampling (rate / fillna)",
+			interval:    time.Second * 4,
+			downsampler: "rate",
+			upsampler:   "fillna",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(8, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(6),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(8),
+			}, tp{
+				time.Unix(8, 0), float64Pointer(9),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(4, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(8, 0), float64Pointer(0.5),
+			}),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			series, err := tt.seriesToResample.Resample("", tt.interval, tt.downsampler, tt.upsampler, tt.timeRange.From, tt.timeRange.To)
+			series, err := tt.seriesToResample.Resample("", tt.interval, tt.downsampler, ReduceOptions{}, tt.upsampler, tt.timeRange.From, tt.timeRange.To)
 			if tt.series.Frame == nil {
 				require.Error(t, err)
 			} else {
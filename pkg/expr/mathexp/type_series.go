@@ -257,6 +257,23 @@ func (s Series) SortByTime(desc bool) {
 	sort.Sort(SortSeriesByTime(s))
 }
 
+// SortedByTime returns the series if it is sorted by time from oldest to newest, or else a sorted copy of it,
+// so that the results of the query or expression it comes from are not modified.
+func (s Series) SortedByTime() Series {
+	for i := 1; i < s.Len(); i++ {
+		if s.GetTime(i).Before(s.GetTime(i - 1)) {
+			newSeries := NewSeries(s.GetName(), s.GetLabels(), s.Len())
+			for j := 0; j < s.Len(); j++ {
+				t, f := s.GetPoint(j)
+				newSeries.SetPoint(j, t, f)
+			}
+			newSeries.SortByTime(false)
+			return newSeries
+		}
+	}
+	return s
+}
+
 // SortSeriesByTime allows a Series to be sorted by time
 // the sort interface will panic if any timestamps are null
 type SortSeriesByTime Series
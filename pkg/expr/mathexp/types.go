@@ -18,6 +18,11 @@ func (r Results) IsNoData() bool {
 	return len(r.Values) == 0 || len(r.Values) == 1 && r.Values[0].Type() == parse.TypeNoData
 }
 
+// NewNoDataResults returns Results that contain a single NoData value.
+func NewNoDataResults() Results {
+	return Results{Values: Values{NewNoData()}}
+}
+
 // Values is a slice of Value interfaces
 type Values []Value
 
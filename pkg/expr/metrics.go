@@ -12,6 +12,10 @@ const (
 type metrics struct {
 	dsRequests *prometheus.CounterVec
 
+	queryCacheRequests  *prometheus.CounterVec
+	queryCacheEvictions prometheus.Counter
+	queryCacheBytes     prometheus.Gauge
+
 	// older metric
 	expressionsQuerySummary *prometheus.SummaryVec
 }
@@ -25,6 +29,27 @@ func newMetrics(reg prometheus.Registerer) *metrics {
 			Help:      "Number of datasource queries made via server side expression requests",
 		}, []string{"error", "dataplane", "datasource_type"}),
 
+		queryCacheRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "ds_query_cache_requests_total",
+			Help:      "Number of lookups of datasource queries in the query cache of server side expressions, by hit or miss",
+		}, []string{"result"}),
+
+		queryCacheEvictions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "ds_query_cache_evictions_total",
+			Help:      "Number of datasource query results evicted from the query cache of server side expressions to stay within its size limit",
+		}),
+
+		queryCacheBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "ds_query_cache_size_bytes",
+			Help:      "Size of the datasource query results in the query cache of server side expressions",
+		}),
+
 		// older (No Namespace or Subsystem)
 		expressionsQuerySummary: prometheus.NewSummaryVec(
 			prometheus.SummaryOpts{
@@ -39,6 +64,9 @@ func newMetrics(reg prometheus.Registerer) *metrics {
 	if reg != nil {
 		reg.MustRegister(
 			m.dsRequests,
+			m.queryCacheRequests,
+			m.queryCacheEvictions,
+			m.queryCacheBytes,
 			m.expressionsQuerySummary,
 		)
 	}
@@ -85,6 +85,7 @@ func (m *MLNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s *
 			respStatus = "failure"
 		}
 		logger.Debug("Data source queried", "responseType", responseType)
+		nodeExplanationFromContext(ctx).setResponseType(responseType)
 		useDataplane := strings.HasPrefix("dataplane-", responseType)
 		s.metrics.dsRequests.WithLabelValues(respStatus, fmt.Sprintf("%t", useDataplane), mlPluginID).Inc()
 	}()
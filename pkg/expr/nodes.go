@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	sdkdata "github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/data/utils/jsoniter"
 	data "github.com/grafana/grafana-plugin-sdk-go/experimental/apis/data/v0alpha1"
 	"go.opentelemetry.io/otel/attribute"
@@ -17,6 +19,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/expr/classic"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/sql"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -106,7 +109,7 @@ func (gn *CMDNode) Execute(ctx context.Context, now time.Time, vars mathexp.Vars
 	return gn.Command.Execute(ctx, now, vars, s.tracer)
 }
 
-func buildCMDNode(rn *rawNode, toggles featuremgmt.FeatureToggles) (*CMDNode, error) {
+func buildCMDNode(rn *rawNode, toggles featuremgmt.FeatureToggles, sqlEngine sql.Engine) (*CMDNode, error) {
 	commandType, err := GetExpressionCommandType(rn.Query)
 	if err != nil {
 		return nil, fmt.Errorf("invalid command type in expression '%v': %w", rn.RefID, err)
@@ -129,7 +132,7 @@ func buildCMDNode(rn *rawNode, toggles featuremgmt.FeatureToggles) (*CMDNode, er
 		// NOTE: this structure of this is weird now, because it is targeting a structure
 		// where this is actually run in the root loop, however we want to verify the individual
 		// node parsing before changing the full tree parser
-		reader := NewExpressionQueryReader(toggles)
+		reader := &ExpressionQueryReader{features: toggles, sqlEngine: sqlEngine}
 		iter, err := jsoniter.ParseBytes(jsoniter.ConfigDefault, rn.QueryRaw)
 		if err != nil {
 			return nil, err
@@ -157,7 +160,19 @@ func buildCMDNode(rn *rawNode, toggles featuremgmt.FeatureToggles) (*CMDNode, er
 	case TypeThreshold:
 		node.Command, err = UnmarshalThresholdCommand(rn, toggles)
 	case TypeSQL:
-		node.Command, err = UnmarshalSQLCommand(rn)
+		node.Command, err = UnmarshalSQLCommand(rn, sqlEngine)
+	case TypeAnomaly:
+		node.Command, err = UnmarshalAnomalyCommand(rn)
+	case TypeForecast:
+		node.Command, err = UnmarshalForecastCommand(rn)
+	case TypeJoin:
+		node.Command, err = UnmarshalJoinCommand(rn)
+	case TypeSeriesToRows:
+		node.Command, err = UnmarshalSeriesToRowsCommand(rn)
+	case TypeLabelsToFields:
+		node.Command, err = UnmarshalLabelsToFieldsCommand(rn)
+	case TypeRenameFields:
+		node.Command, err = UnmarshalRenameFieldsCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in expression '%v' not implemented", commandType, rn.RefID)
 	}
@@ -285,22 +300,6 @@ func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars
 				attribute.String("datasource.uid", firstNode.datasource.UID),
 			)
 
-			req := &backend.QueryDataRequest{
-				PluginContext: pCtx,
-				Headers:       firstNode.request.Headers,
-			}
-
-			for _, dn := range nodeGroup {
-				req.Queries = append(req.Queries, backend.DataQuery{
-					RefID:         dn.refID,
-					MaxDataPoints: dn.maxDP,
-					Interval:      time.Duration(int64(time.Millisecond) * dn.intervalMS),
-					JSON:          dn.query,
-					TimeRange:     dn.timeRange.AbsoluteTime(now),
-					QueryType:     dn.queryType,
-				})
-			}
-
 			instrument := func(e error, rt string) {
 				respStatus := "success"
 				responseType := rt
@@ -315,30 +314,109 @@ func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars
 				s.metrics.dsRequests.WithLabelValues(respStatus, fmt.Sprintf("%t", useDataplane), firstNode.datasource.Type).Inc()
 			}
 
-			resp, err := s.dataService.QueryData(ctx, req)
-			if err != nil {
-				for _, dn := range nodeGroup {
-					vars[dn.refID] = mathexp.Results{Error: MakeQueryError(firstNode.refID, firstNode.datasource.UID, err)}
+			convert := func(dn *DSNode, dataFrames []*sdkdata.Frame) {
+				nodeExplanation := explanationFromContext(ctx).node(dn.refID)
+				var result mathexp.Results
+				responseType, result, err := s.converter.Convert(withNodeExplanation(ctx, nodeExplanation), dn.datasource.Type, dataFrames, s.allowLongFrames)
+				if err != nil {
+					result.Error = makeConversionError(dn.RefID(), err)
 				}
-				instrument(err, "")
-				return
+				nodeExplanation.setResponseType(responseType)
+				instrument(err, responseType)
+				vars[dn.refID] = result
 			}
 
+			// cacheable queries are shared with other requests, the others are sent to the data source directly
+			cacheKeys := make(map[string]string, len(nodeGroup))
+			uncached := make([]*DSNode, 0, len(nodeGroup))
+			byRefID := make(map[string]*DSNode, len(nodeGroup))
 			for _, dn := range nodeGroup {
-				dataFrames, err := getResponseFrame(logger, resp, dn.refID)
+				byRefID[dn.refID] = dn
+				key, ok := s.queryCache.key(dn, now)
+				if !ok {
+					uncached = append(uncached, dn)
+					continue
+				}
+				start := time.Now()
+				if dataFrames, ok := s.queryCache.get(key, dn.refID); ok {
+					explanationFromContext(ctx).node(dn.refID).setDuration(time.Since(start))
+					convert(dn, dataFrames)
+					continue
+				}
+				cacheKeys[dn.refID] = key
+			}
+
+			query := func(ctx context.Context, nodes []*DSNode) (map[string]queryResult, error) {
+				req := &backend.QueryDataRequest{
+					PluginContext: pCtx,
+					Headers:       firstNode.request.Headers,
+				}
+				for _, dn := range nodes {
+					req.Queries = append(req.Queries, backend.DataQuery{
+						RefID:         dn.refID,
+						MaxDataPoints: dn.maxDP,
+						Interval:      time.Duration(int64(time.Millisecond) * dn.intervalMS),
+						JSON:          dn.query,
+						TimeRange:     dn.timeRange.AbsoluteTime(now),
+						QueryType:     dn.queryType,
+					})
+				}
+				resp, err := s.dataService.QueryData(ctx, req)
 				if err != nil {
-					vars[dn.refID] = mathexp.Results{Error: MakeQueryError(dn.refID, dn.datasource.UID, err)}
-					instrument(err, "")
-					return
+					return nil, err
+				}
+				results := make(map[string]queryResult, len(nodes))
+				for _, dn := range nodes {
+					dataFrames, err := getResponseFrame(logger, resp, dn.refID)
+					results[dn.refID] = queryResult{frames: dataFrames, err: err}
 				}
+				return results, nil
+			}
 
-				var result mathexp.Results
-				responseType, result, err := s.converter.Convert(ctx, dn.datasource.Type, dataFrames, s.allowLongFrames)
+			// setResults sets the results of the queried nodes, in the order of the group
+			setResults := func(queried func(dn *DSNode) bool, results map[string]queryResult, err error, duration time.Duration) {
+				for _, dn := range nodeGroup {
+					if !queried(dn) {
+						continue
+					}
+					explanationFromContext(ctx).node(dn.refID).setDuration(duration)
+					if err != nil {
+						vars[dn.refID] = mathexp.Results{Error: MakeQueryError(firstNode.refID, firstNode.datasource.UID, err)}
+						continue
+					}
+					if r := results[dn.refID]; r.err != nil {
+						vars[dn.refID] = mathexp.Results{Error: MakeQueryError(dn.refID, dn.datasource.UID, r.err)}
+						instrument(r.err, "")
+					} else {
+						convert(dn, r.frames)
+					}
+				}
 				if err != nil {
-					result.Error = makeConversionError(dn.RefID(), err)
+					instrument(err, "")
 				}
-				instrument(err, responseType)
-				vars[dn.refID] = result
+			}
+
+			if len(cacheKeys) > 0 {
+				start := time.Now()
+				results, err := s.queryCache.fetchGroup(ctx, cacheKeys, func(ctx context.Context, refIDs []string) (map[string]queryResult, error) {
+					nodes := make([]*DSNode, 0, len(refIDs))
+					for _, refID := range refIDs {
+						nodes = append(nodes, byRefID[refID])
+					}
+					return query(ctx, nodes)
+				})
+				setResults(func(dn *DSNode) bool {
+					_, ok := cacheKeys[dn.refID]
+					return ok
+				}, results, err, time.Since(start))
+			}
+
+			if len(uncached) > 0 {
+				start := time.Now()
+				results, err := query(ctx, uncached)
+				setResults(func(dn *DSNode) bool {
+					return slices.Contains(uncached, dn)
+				}, results, err, time.Since(start))
 			}
 		}()
 	}
@@ -386,16 +464,12 @@ func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s
 			span.RecordError(e)
 		}
 		logger.Debug("Data source queried", "responseType", responseType)
+		nodeExplanationFromContext(ctx).setResponseType(responseType)
 		useDataplane := strings.HasPrefix(responseType, "dataplane-")
 		s.metrics.dsRequests.WithLabelValues(respStatus, fmt.Sprintf("%t", useDataplane), dn.datasource.Type).Inc()
 	}()
 
-	resp, err := s.dataService.QueryData(ctx, req)
-	if err != nil {
-		return mathexp.Results{}, MakeQueryError(dn.refID, dn.datasource.UID, err)
-	}
-
-	dataFrames, err := getResponseFrame(logger, resp, dn.refID)
+	dataFrames, err := s.queryFrames(ctx, logger, dn, now, req)
 	if err != nil {
 		return mathexp.Results{}, MakeQueryError(dn.refID, dn.datasource.UID, err)
 	}
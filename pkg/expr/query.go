@@ -29,6 +29,24 @@ const (
 
 	// SQL query via DuckDB
 	QueryTypeSQL QueryType = "sql"
+
+	// Detect anomalies in query results
+	QueryTypeAnomaly QueryType = "anomaly"
+
+	// Forecast query results
+	QueryTypeForecast QueryType = "forecast"
+
+	// Join series by time
+	QueryTypeJoin QueryType = "join"
+
+	// Convert series to rows of a table
+	QueryTypeSeriesToRows QueryType = "series_to_rows"
+
+	// Convert labels to fields of a table
+	QueryTypeLabelsToFields QueryType = "labels_to_fields"
+
+	// Rename fields of query results
+	QueryTypeRenameFields QueryType = "rename_fields"
 )
 
 type MathQuery struct {
@@ -43,6 +61,9 @@ type ReduceQuery struct {
 	// The reducer
 	Reducer mathexp.ReducerID `json:"reducer"`
 
+	// The percentile (0-100) to calculate. Required when the reducer is percentile
+	Percentile *float64 `json:"percentile,omitempty" jsonschema:"minimum=0,maximum=100,example=95"`
+
 	// Reducer Options
 	Settings *ReduceSettings `json:"settings,omitempty"`
 }
@@ -58,6 +79,9 @@ type ResampleQuery struct {
 	// The downsample function
 	Downsampler mathexp.ReducerID `json:"downsampler"`
 
+	// The percentile (0-100) to calculate. Required when the downsampler is percentile
+	Percentile *float64 `json:"percentile,omitempty" jsonschema:"minimum=0,maximum=100,example=95"`
+
 	// The upsample function
 	Upsampler mathexp.Upsampler `json:"upsampler"`
 }
@@ -79,6 +103,167 @@ type SQLExpression struct {
 	Expression string `json:"expression" jsonschema:"minLength=1,example=SELECT * FROM A LIMIT 1"`
 }
 
+// QueryType = anomaly
+type AnomalyQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The detection algorithm
+	Algorithm AnomalyAlgorithm `json:"algorithm"`
+
+	// The time window of the baseline. Required by the zscore and mad algorithms.
+	// Without a season, the window precedes each point. With a season, the window is centered on the same time in previous seasons.
+	Window string `json:"window,omitempty" jsonschema:"example=1h,example=30m"`
+
+	// The length of the season, e.g. 1d for daily patterns
+	Season string `json:"season,omitempty" jsonschema:"example=1d,example=1w"`
+
+	// The width of the bands in standard deviations, default 3
+	Sensitivity *float64 `json:"sensitivity,omitempty" jsonschema:"minimum=0,example=3"`
+
+	// Holt-Winters smoothing factor of the level, default 0.5
+	Alpha *float64 `json:"alpha,omitempty" jsonschema:"minimum=0,maximum=1"`
+
+	// Holt-Winters smoothing factor of the trend, default 0.1
+	Beta *float64 `json:"beta,omitempty" jsonschema:"minimum=0,maximum=1"`
+
+	// Holt-Winters smoothing factor of the season, default 0.1
+	Gamma *float64 `json:"gamma,omitempty" jsonschema:"minimum=0,maximum=1"`
+
+	// The series to return, default all
+	Output AnomalyOutput `json:"output,omitempty"`
+}
+
+// The anomaly detection algorithm
+// +enum
+type AnomalyAlgorithm string
+
+const (
+	// Bands of the mean plus/minus the standard deviation of the baseline
+	AnomalyZScore AnomalyAlgorithm = "zscore"
+
+	// Bands of the median plus/minus the median absolute deviation of the baseline
+	AnomalyMAD AnomalyAlgorithm = "mad"
+
+	// Bands around the Holt-Winters forecast
+	AnomalyHoltWinters AnomalyAlgorithm = "holt_winters"
+)
+
+// The series returned by the anomaly detection
+// +enum
+type AnomalyOutput string
+
+const (
+	// The anomaly score and the upper and lower bands
+	AnomalyOutputAll AnomalyOutput = "all"
+
+	// Only the anomaly score
+	AnomalyOutputScore AnomalyOutput = "score"
+
+	// Only the upper and lower bands
+	AnomalyOutputBands AnomalyOutput = "bands"
+)
+
+// QueryType = forecast
+type ForecastQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The forecasting method, default linear
+	Method ForecastMethod `json:"method,omitempty"`
+
+	// How far to forecast past the last point of the series. Not used by the time_to_threshold output
+	Horizon string `json:"horizon,omitempty" jsonschema:"example=4h,example=1d"`
+
+	// The time between the forecasted points. Defaults to the median interval of the series
+	Interval string `json:"interval,omitempty" jsonschema:"example=1m"`
+
+	// The value to calculate the time to. Required when the output is time_to_threshold
+	Threshold *float64 `json:"threshold,omitempty" jsonschema:"example=0"`
+
+	// Double exponential smoothing factor of the level, default 0.5
+	Alpha *float64 `json:"alpha,omitempty" jsonschema:"minimum=0,maximum=1"`
+
+	// Double exponential smoothing factor of the trend, default 0.1
+	Beta *float64 `json:"beta,omitempty" jsonschema:"minimum=0,maximum=1"`
+
+	// The result of the forecast, default series
+	Output ForecastOutput `json:"output,omitempty"`
+}
+
+// The forecasting method
+// +enum
+type ForecastMethod string
+
+const (
+	// Least squares linear regression
+	ForecastLinear ForecastMethod = "linear"
+
+	// Double exponential smoothing (Holt's linear trend)
+	ForecastHolt ForecastMethod = "holt"
+)
+
+// The result of the forecast
+// +enum
+type ForecastOutput string
+
+const (
+	// A series of the forecasted values up to the horizon
+	ForecastOutputSeries ForecastOutput = "series"
+
+	// A number with the forecasted value at the horizon
+	ForecastOutputValue ForecastOutput = "value"
+
+	// A number with the seconds until the forecast reaches the threshold, 0 if it is already past it, +Inf if it is flat
+	ForecastOutputTimeToThreshold ForecastOutput = "time_to_threshold"
+)
+
+// QueryType = join
+type JoinQuery struct {
+	// References to the query results to join
+	Expressions []string `json:"expressions" jsonschema:"minItems=1"`
+
+	// How the series are joined, default outer
+	Mode JoinMode `json:"mode,omitempty"`
+}
+
+// How the series are joined by time
+// +enum
+type JoinMode string
+
+const (
+	// Keep the times of all series, the values of the series without the time are null
+	JoinOuter JoinMode = "outer"
+
+	// Keep only the times that are in all series
+	JoinInner JoinMode = "inner"
+)
+
+// QueryType = series_to_rows
+type SeriesToRowsQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+}
+
+// QueryType = labels_to_fields
+type LabelsToFieldsQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The labels to convert to fields, default all.
+	// The fields of the labels named Time or Value are prefixed with label_
+	Labels []string `json:"labels,omitempty"`
+}
+
+// QueryType = rename_fields
+type RenameFieldsQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The new names of the fields by their current name
+	Renames map[string]string `json:"renames"`
+}
+
 //-------------------------------
 // Non-query commands
 //-------------------------------
@@ -11,6 +11,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/expr/classic"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func TestQueryTypeDefinitions(t *testing.T) {
@@ -27,6 +28,11 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				reflect.TypeOf(ReduceModeDrop),       // pick an example value (not the root)
 				reflect.TypeOf(ThresholdIsAbove),
 				reflect.TypeOf(classic.ConditionOperatorAnd),
+				reflect.TypeOf(AnomalyZScore),
+				reflect.TypeOf(AnomalyOutputAll),
+				reflect.TypeOf(ForecastLinear),
+				reflect.TypeOf(ForecastOutputSeries),
+				reflect.TypeOf(JoinOuter),
 			},
 		})
 	require.NoError(t, err)
@@ -63,6 +69,14 @@ func TestQueryTypeDefinitions(t *testing.T) {
 						},
 					}),
 				},
+				{
+					Name: "get 95th percentile",
+					SaveModel: data.AsUnstructured(ReduceQuery{
+						Expression: "$A",
+						Reducer:    mathexp.ReducerPercentile,
+						Percentile: util.Pointer(95.0),
+					}),
+				},
 			},
 		},
 		schemabuilder.QueryTypeInfo{
@@ -80,6 +94,106 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				},
 			},
 		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeAnomaly),
+			GoType:         reflect.TypeOf(&AnomalyQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "daily seasonal baseline",
+					SaveModel: data.AsUnstructured(AnomalyQuery{
+						Expression: "$A",
+						Algorithm:  AnomalyMAD,
+						Window:     "1h",
+						Season:     "1d",
+					}),
+				},
+				{
+					Name: "Holt-Winters anomaly score",
+					SaveModel: data.AsUnstructured(AnomalyQuery{
+						Expression:  "$A",
+						Algorithm:   AnomalyHoltWinters,
+						Season:      "1d",
+						Sensitivity: util.Pointer(2.5),
+						Output:      AnomalyOutputScore,
+					}),
+				},
+			},
+		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeForecast),
+			GoType:         reflect.TypeOf(&ForecastQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "forecast the next day",
+					SaveModel: data.AsUnstructured(ForecastQuery{
+						Expression: "$A",
+						Method:     ForecastLinear,
+						Horizon:    "1d",
+						Interval:   "1h",
+					}),
+				},
+				{
+					Name: "time until disk is full",
+					SaveModel: data.AsUnstructured(ForecastQuery{
+						Expression: "$A",
+						Method:     ForecastHolt,
+						Threshold:  util.Pointer(100.0),
+						Output:     ForecastOutputTimeToThreshold,
+					}),
+				},
+			},
+		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeJoin),
+			GoType:         reflect.TypeOf(&JoinQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "join A and B by time",
+					SaveModel: data.AsUnstructured(JoinQuery{
+						Expressions: []string{"$A", "$B"},
+						Mode:        JoinOuter,
+					}),
+				},
+			},
+		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeSeriesToRows),
+			GoType:         reflect.TypeOf(&SeriesToRowsQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "convert the series of A to rows",
+					SaveModel: data.AsUnstructured(SeriesToRowsQuery{
+						Expression: "$A",
+					}),
+				},
+			},
+		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeLabelsToFields),
+			GoType:         reflect.TypeOf(&LabelsToFieldsQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "convert the host label of A to a field",
+					SaveModel: data.AsUnstructured(LabelsToFieldsQuery{
+						Expression: "$A",
+						Labels:     []string{"host"},
+					}),
+				},
+			},
+		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeRenameFields),
+			GoType:         reflect.TypeOf(&RenameFieldsQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "rename the value field of A",
+					SaveModel: data.AsUnstructured(RenameFieldsQuery{
+						Expression: "$A",
+						Renames:    map[string]string{"Value": "cpu"},
+					}),
+				},
+			},
+		},
 		schemabuilder.QueryTypeInfo{
 			Discriminators: data.NewDiscriminators("type", QueryTypeSQL),
 			GoType:         reflect.TypeOf(&SQLExpression{}),
@@ -10,7 +10,9 @@ import (
 
 	"github.com/grafana/grafana/pkg/expr/classic"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/sql"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 // Once we are comfortable with the parsing logic, this struct will
@@ -34,11 +36,16 @@ func (q ExpressionQuery) ID() int64 {
 
 type ExpressionQueryReader struct {
 	features featuremgmt.FeatureToggles
+	// sqlEngine runs SQL expressions, the embedded engine is used when nil
+	sqlEngine sql.Engine
 }
 
-func NewExpressionQueryReader(features featuremgmt.FeatureToggles) *ExpressionQueryReader {
+// NewExpressionQueryReader returns a reader of expression queries. SQL expressions run with the engine
+// and limits of the configuration, the same as in the expression service.
+func NewExpressionQueryReader(features featuremgmt.FeatureToggles, cfg *setting.Cfg) *ExpressionQueryReader {
 	return &ExpressionQueryReader{
-		features: features,
+		features:  features,
+		sqlEngine: newSQLEngine(cfg),
 	}
 }
 
@@ -85,10 +92,14 @@ func (h *ExpressionQueryReader) ReadQuery(
 				err = fmt.Errorf("unsupported reduce mode")
 			}
 		}
+		var opts mathexp.ReduceOptions
+		if err == nil {
+			opts, err = getReduceOptions(q.Reducer, q.Percentile)
+		}
 		if err == nil {
 			eq.Properties = q
 			eq.Command, err = NewReduceCommand(common.RefID,
-				q.Reducer, referenceVar, mapper)
+				q.Reducer, opts, referenceVar, mapper)
 		}
 
 	case QueryTypeResample:
@@ -100,6 +111,10 @@ func (h *ExpressionQueryReader) ReadQuery(
 		if err == nil {
 			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
 		}
+		var opts mathexp.ReduceOptions
+		if err == nil {
+			opts, err = getReduceOptions(q.Downsampler, q.Percentile)
+		}
 		if err == nil {
 			tr := gtime.NewTimeRange(common.TimeRange.From, common.TimeRange.To)
 			eq.Properties = q
@@ -107,6 +122,7 @@ func (h *ExpressionQueryReader) ReadQuery(
 				q.Window,
 				referenceVar,
 				q.Downsampler,
+				opts,
 				q.Upsampler,
 				AbsoluteTimeRange{
 					From: tr.GetFromAsTimeUTC(),
@@ -128,7 +144,55 @@ func (h *ExpressionQueryReader) ReadQuery(
 		err = iter.ReadVal(q)
 		if err == nil {
 			eq.Properties = q
-			eq.Command, err = NewSQLCommand(common.RefID, q.Expression)
+			eq.Command, err = NewSQLCommand(common.RefID, q.Expression, h.sqlEngine)
+		}
+
+	case QueryTypeAnomaly:
+		q := &AnomalyQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = NewAnomalyCommand(common.RefID, *q)
+		}
+
+	case QueryTypeForecast:
+		q := &ForecastQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = NewForecastCommand(common.RefID, *q)
+		}
+
+	case QueryTypeJoin:
+		q := &JoinQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = NewJoinCommand(common.RefID, *q)
+		}
+
+	case QueryTypeSeriesToRows:
+		q := &SeriesToRowsQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = NewSeriesToRowsCommand(common.RefID, *q)
+		}
+
+	case QueryTypeLabelsToFields:
+		q := &LabelsToFieldsQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = NewLabelsToFieldsCommand(common.RefID, *q)
+		}
+
+	case QueryTypeRenameFields:
+		q := &RenameFieldsQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = NewRenameFieldsCommand(common.RefID, *q)
 		}
 
 	case QueryTypeThreshold:
@@ -144,7 +208,7 @@ func (h *ExpressionQueryReader) ReadQuery(
 			}
 			firstCondition := q.Conditions[0]
 
-			threshold, err := NewThresholdCommand(common.RefID, referenceVar, firstCondition.Evaluator.Type, firstCondition.Evaluator.Params)
+			threshold, err := NewThresholdCommandFromEvaluator(common.RefID, referenceVar, firstCondition.Evaluator)
 			if err != nil {
 				return eq, fmt.Errorf("invalid condition: %w", err)
 			}
@@ -152,11 +216,11 @@ func (h *ExpressionQueryReader) ReadQuery(
 			eq.Properties = q
 
 			if firstCondition.UnloadEvaluator != nil && h.features.IsEnabledGlobally(featuremgmt.FlagRecoveryThreshold) {
-				unloading, err := NewThresholdCommand(common.RefID, referenceVar, firstCondition.UnloadEvaluator.Type, firstCondition.UnloadEvaluator.Params)
-				unloading.Invert = true
+				unloading, err := NewThresholdCommandFromEvaluator(common.RefID, referenceVar, *firstCondition.UnloadEvaluator)
 				if err != nil {
 					return eq, fmt.Errorf("invalid unloadCondition: %w", err)
 				}
+				unloading.Invert = true
 				var d Fingerprints
 				if firstCondition.LoadedDimensions != nil {
 					d, err = FingerprintsFromFrame(firstCondition.LoadedDimensions)
@@ -177,6 +241,18 @@ func (h *ExpressionQueryReader) ReadQuery(
 	return eq, err
 }
 
+func getReduceOptions(reducer mathexp.ReducerID, percentile *float64) (mathexp.ReduceOptions, error) {
+	opts := mathexp.ReduceOptions{}
+	if reducer != mathexp.ReducerPercentile {
+		return opts, nil
+	}
+	if percentile == nil {
+		return opts, fmt.Errorf("percentile must be specified when reducer is '%s'", reducer)
+	}
+	opts.Percentile = *percentile
+	return opts, nil
+}
+
 func getReferenceVar(exp string, refId string) (string, error) {
 	exp = strings.TrimPrefix(exp, "$")
 	if exp == "" {
@@ -0,0 +1,371 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// SeriesToRowsCommand converts the series or numbers of a query or expression into a single long table
+// with a row for each point, like the series to rows transformation of the frontend.
+// The Metric field has the name and labels of the series the point comes from.
+type SeriesToRowsCommand struct {
+	RefID          string
+	VarToTransform string
+}
+
+// NewSeriesToRowsCommand creates a new SeriesToRowsCommand.
+func NewSeriesToRowsCommand(refID string, q SeriesToRowsQuery) (*SeriesToRowsCommand, error) {
+	varToTransform, err := getReferenceVar(q.Expression, refID)
+	if err != nil {
+		return nil, err
+	}
+	return &SeriesToRowsCommand{
+		RefID:          refID,
+		VarToTransform: varToTransform,
+	}, nil
+}
+
+// UnmarshalSeriesToRowsCommand creates a SeriesToRowsCommand from Grafana's frontend query.
+func UnmarshalSeriesToRowsCommand(rn *rawNode) (*SeriesToRowsCommand, error) {
+	q := SeriesToRowsQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the series to rows command: %w", err)
+	}
+	return NewSeriesToRowsCommand(rn.RefID, q)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (sc *SeriesToRowsCommand) NeedsVars() []string {
+	return []string{sc.VarToTransform}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (sc *SeriesToRowsCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteSeriesToRows")
+	defer span.End()
+
+	vals, noData, err := seriesOrNumbers(vars[sc.VarToTransform], sc.VarToTransform)
+	if err != nil || noData {
+		return vals, err
+	}
+	table := longTable(sc.RefID, vals.Values, []string{"Metric"}, func(v mathexp.Value) []*string {
+		name := valueName(v)
+		if name == "" {
+			name = sc.VarToTransform
+		}
+		if labels := v.GetLabels(); len(labels) > 0 {
+			name = fmt.Sprintf("%s {%s}", name, labels.String())
+		}
+		return []*string{&name}
+	})
+	return mathexp.Results{Values: mathexp.Values{table}}, nil
+}
+
+func (sc *SeriesToRowsCommand) Type() string {
+	return TypeSeriesToRows.String()
+}
+
+// LabelsToFieldsCommand converts the series or numbers of a query or expression into a single long table
+// with a field for each label, like the labels to fields transformation of the frontend.
+// The fields of the labels named Time or Value are prefixed with "label_".
+type LabelsToFieldsCommand struct {
+	RefID          string
+	VarToTransform string
+	// Labels are the labels to convert in the order of the fields. All labels are converted when empty.
+	Labels []string
+}
+
+// NewLabelsToFieldsCommand creates a new LabelsToFieldsCommand.
+func NewLabelsToFieldsCommand(refID string, q LabelsToFieldsQuery) (*LabelsToFieldsCommand, error) {
+	varToTransform, err := getReferenceVar(q.Expression, refID)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range q.Labels {
+		if l == "" {
+			return nil, fmt.Errorf("label names must not be empty")
+		}
+	}
+	return &LabelsToFieldsCommand{
+		RefID:          refID,
+		VarToTransform: varToTransform,
+		Labels:         q.Labels,
+	}, nil
+}
+
+// UnmarshalLabelsToFieldsCommand creates a LabelsToFieldsCommand from Grafana's frontend query.
+func UnmarshalLabelsToFieldsCommand(rn *rawNode) (*LabelsToFieldsCommand, error) {
+	q := LabelsToFieldsQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the labels to fields command: %w", err)
+	}
+	return NewLabelsToFieldsCommand(rn.RefID, q)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (lc *LabelsToFieldsCommand) NeedsVars() []string {
+	return []string{lc.VarToTransform}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (lc *LabelsToFieldsCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteLabelsToFields")
+	defer span.End()
+
+	vals, noData, err := seriesOrNumbers(vars[lc.VarToTransform], lc.VarToTransform)
+	if err != nil || noData {
+		return vals, err
+	}
+
+	names := lc.Labels
+	if len(names) == 0 {
+		keys := make(map[string]struct{})
+		for _, v := range vals.Values {
+			for k := range v.GetLabels() {
+				keys[k] = struct{}{}
+			}
+		}
+		names = make([]string, 0, len(keys))
+		for k := range keys {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+	}
+
+	// the labels named like the Time and Value fields of the table are prefixed, so that the field names are unique.
+	fieldNames := make([]string, len(names))
+	for i, name := range names {
+		fieldNames[i] = name
+		if name == "Time" || name == "Value" {
+			fieldNames[i] = "label_" + name
+		}
+	}
+
+	table := longTable(lc.RefID, vals.Values, fieldNames, func(v mathexp.Value) []*string {
+		labels := v.GetLabels()
+		dims := make([]*string, len(names))
+		for i, name := range names {
+			if value, ok := labels[name]; ok {
+				dims[i] = &value
+			}
+		}
+		return dims
+	})
+	return mathexp.Results{Values: mathexp.Values{table}}, nil
+}
+
+func (lc *LabelsToFieldsCommand) Type() string {
+	return TypeLabelsToFields.String()
+}
+
+// RenameFieldsCommand renames the fields of the results of a query or expression. The value fields of
+// series and numbers are renamed as well as the fields of tables.
+type RenameFieldsCommand struct {
+	RefID       string
+	VarToRename string
+	Renames     map[string]string
+}
+
+// NewRenameFieldsCommand creates a new RenameFieldsCommand.
+func NewRenameFieldsCommand(refID string, q RenameFieldsQuery) (*RenameFieldsCommand, error) {
+	varToRename, err := getReferenceVar(q.Expression, refID)
+	if err != nil {
+		return nil, err
+	}
+	if len(q.Renames) == 0 {
+		return nil, fmt.Errorf("at least one field must be renamed")
+	}
+	for from, to := range q.Renames {
+		if to == "" {
+			return nil, fmt.Errorf("the new name of the field '%s' must not be empty", from)
+		}
+	}
+	return &RenameFieldsCommand{
+		RefID:       refID,
+		VarToRename: varToRename,
+		Renames:     q.Renames,
+	}, nil
+}
+
+// UnmarshalRenameFieldsCommand creates a RenameFieldsCommand from Grafana's frontend query.
+func UnmarshalRenameFieldsCommand(rn *rawNode) (*RenameFieldsCommand, error) {
+	q := RenameFieldsQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the rename fields command: %w", err)
+	}
+	return NewRenameFieldsCommand(rn.RefID, q)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (rc *RenameFieldsCommand) NeedsVars() []string {
+	return []string{rc.VarToRename}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (rc *RenameFieldsCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteRenameFields")
+	defer span.End()
+
+	newRes := mathexp.Results{}
+	for _, val := range vars[rc.VarToRename].Values {
+		if val == nil {
+			continue
+		}
+		switch v := val.(type) {
+		case mathexp.Series:
+			newRes.Values = append(newRes.Values, mathexp.Series{Frame: rc.rename(v.Frame)})
+		case mathexp.Number:
+			newRes.Values = append(newRes.Values, mathexp.Number{Frame: rc.rename(v.Frame)})
+		case mathexp.TableData:
+			newRes.Values = append(newRes.Values, mathexp.TableData{Frame: rc.rename(v.Frame)})
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+			return newRes, nil
+		default:
+			return newRes, fmt.Errorf("can only rename fields of type series, number or table, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// rename returns a copy of the frame with the fields renamed and the refID of the command.
+// The values of the fields are not copied.
+func (rc *RenameFieldsCommand) rename(frame *data.Frame) *data.Frame {
+	out := &data.Frame{
+		Name:   frame.Name,
+		RefID:  rc.RefID,
+		Meta:   frame.Meta,
+		Fields: make([]*data.Field, len(frame.Fields)),
+	}
+	for i, f := range frame.Fields {
+		if to, ok := rc.Renames[f.Name]; ok {
+			renamed := *f
+			renamed.Name = to
+			f = &renamed
+		}
+		out.Fields[i] = f
+	}
+	return out
+}
+
+func (rc *RenameFieldsCommand) Type() string {
+	return TypeRenameFields.String()
+}
+
+// seriesOrNumbers returns the results if they are series or numbers, and returns true if they are no data.
+// The results can not contain both series and numbers.
+func seriesOrNumbers(res mathexp.Results, refID string) (mathexp.Results, bool, error) {
+	var series, numbers int
+	vals := mathexp.Results{}
+	for _, val := range res.Values {
+		if val == nil {
+			continue
+		}
+		switch v := val.(type) {
+		case mathexp.Series:
+			series++
+		case mathexp.Number:
+			numbers++
+		case mathexp.NoData:
+			return mathexp.Results{Values: mathexp.Values{v.New()}}, true, nil
+		default:
+			return vals, false, fmt.Errorf("can only convert type series or number, got type %v in '%s'", val.Type(), refID)
+		}
+		vals.Values = append(vals.Values, val)
+	}
+	if series > 0 && numbers > 0 {
+		return vals, false, fmt.Errorf("can not convert both series and numbers in '%s'", refID)
+	}
+	if len(vals.Values) == 0 {
+		return mathexp.Results{Values: mathexp.Values{mathexp.NoData{}.New()}}, true, nil
+	}
+	return vals, false, nil
+}
+
+// valueName returns the name of the value field of a series or number.
+func valueName(v mathexp.Value) string {
+	switch t := v.(type) {
+	case mathexp.Series:
+		return t.GetName()
+	case mathexp.Number:
+		return t.Frame.Fields[0].Name
+	default:
+		return ""
+	}
+}
+
+// longTable returns a table with a row for each point of the series, sorted by time, or a row for each number.
+// The rows have a string field for each dimension, with the values returned by dims for the series or number
+// the row comes from, followed by the Value field.
+func longTable(refID string, vals mathexp.Values, dimNames []string, dims func(mathexp.Value) []*string) mathexp.TableData {
+	type row struct {
+		time  time.Time
+		dims  []*string
+		value *float64
+	}
+	_, isSeries := vals[0].(mathexp.Series)
+
+	var rows []row
+	for _, val := range vals {
+		d := dims(val)
+		switch v := val.(type) {
+		case mathexp.Series:
+			for i := 0; i < v.Len(); i++ {
+				t, value := v.GetPoint(i)
+				rows = append(rows, row{time: t, dims: d, value: value})
+			}
+		case mathexp.Number:
+			rows = append(rows, row{dims: d, value: v.GetFloat64Value()})
+		}
+	}
+	if isSeries {
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i].time.Before(rows[j].time) })
+	}
+
+	fields := make([]*data.Field, 0, len(dimNames)+2)
+	if isSeries {
+		times := make([]time.Time, len(rows))
+		for i, r := range rows {
+			times[i] = r.time
+		}
+		fields = append(fields, data.NewField("Time", nil, times))
+	}
+	for i, name := range dimNames {
+		values := make([]*string, len(rows))
+		for j, r := range rows {
+			values[j] = r.dims[i]
+		}
+		fields = append(fields, data.NewField(name, nil, values))
+	}
+	values := make([]*float64, len(rows))
+	for i, r := range rows {
+		values[i] = r.value
+	}
+	fields = append(fields, data.NewField("Value", nil, values))
+
+	frame := data.NewFrame(refID, fields...)
+	frame.RefID = refID
+	frameType := data.FrameTypeNumericLong
+	if isSeries {
+		frameType = data.FrameTypeTimeSeriesLong
+	}
+	frame.SetMeta(&data.FrameMeta{
+		Type:        frameType,
+		TypeVersion: data.FrameTypeVersion{0, 1},
+	})
+	return mathexp.TableData{Frame: frame}
+}
@@ -0,0 +1,151 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestSeriesToRowsCommandExecute(t *testing.T) {
+	cmd, err := NewSeriesToRowsCommand("B", SeriesToRowsQuery{Expression: "$A"})
+	require.NoError(t, err)
+
+	t.Run("series are converted to rows sorted by time", func(t *testing.T) {
+		vars := mathexp.Vars{"A": newResults(
+			newSeriesWithLabels(data.Labels{"host": "a"}, util.Pointer(1.0), util.Pointer(2.0)),
+			newSeriesWithLabels(nil, util.Pointer(10.0)),
+		)}
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, parse.TypeTableData, res.Values[0].Type())
+
+		frame := res.Values[0].AsDataFrame()
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, []string{"Time", "Metric", "Value"}, fieldNames(frame))
+		require.Equal(t, "A {host=a}", *frame.Fields[1].At(0).(*string))
+		require.Equal(t, "A", *frame.Fields[1].At(1).(*string))
+		require.Equal(t, 10.0, *frame.Fields[2].At(1).(*float64))
+		require.Equal(t, time.Unix(1, 0), frame.Fields[0].At(2))
+		require.Equal(t, data.FrameTypeTimeSeriesLong, frame.Meta.Type)
+	})
+
+	t.Run("numbers are converted to rows without time", func(t *testing.T) {
+		vars := mathexp.Vars{"A": newResults(newNumber(data.Labels{"host": "a"}, util.Pointer(1.0)))}
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		frame := res.Values[0].AsDataFrame()
+		require.Equal(t, []string{"Metric", "Value"}, fieldNames(frame))
+		require.Equal(t, data.FrameTypeNumericLong, frame.Meta.Type)
+	})
+
+	t.Run("returns no data when input is no data", func(t *testing.T) {
+		vars := mathexp.Vars{"A": newResults(mathexp.NoData{}.New())}
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Equal(t, parse.TypeNoData, res.Values[0].Type())
+	})
+
+	t.Run("fails when input has series and numbers", func(t *testing.T) {
+		vars := mathexp.Vars{"A": newResults(newSeries(1), newNumber(nil, util.Pointer(1.0)))}
+		_, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.Error(t, err)
+	})
+}
+
+func TestLabelsToFieldsCommandExecute(t *testing.T) {
+	vars := mathexp.Vars{"A": newResults(
+		newNumber(data.Labels{"host": "a", "dc": "eu"}, util.Pointer(1.0)),
+		newNumber(data.Labels{"host": "b"}, util.Pointer(2.0)),
+	)}
+
+	t.Run("all labels are converted", func(t *testing.T) {
+		cmd, err := NewLabelsToFieldsCommand("B", LabelsToFieldsQuery{Expression: "$A"})
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+
+		frame := res.Values[0].AsDataFrame()
+		require.Equal(t, []string{"dc", "host", "Value"}, fieldNames(frame))
+		require.Equal(t, "eu", *frame.Fields[0].At(0).(*string))
+		require.Nil(t, frame.Fields[0].At(1))
+		require.Equal(t, "b", *frame.Fields[1].At(1).(*string))
+		require.Equal(t, 2.0, *frame.Fields[2].At(1).(*float64))
+	})
+
+	t.Run("only the selected labels are converted", func(t *testing.T) {
+		cmd, err := NewLabelsToFieldsCommand("B", LabelsToFieldsQuery{Expression: "$A", Labels: []string{"host"}})
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Equal(t, []string{"host", "Value"}, fieldNames(res.Values[0].AsDataFrame()))
+	})
+
+	t.Run("labels named like the time and value fields are prefixed", func(t *testing.T) {
+		cmd, err := NewLabelsToFieldsCommand("B", LabelsToFieldsQuery{Expression: "$A"})
+		require.NoError(t, err)
+		vars := mathexp.Vars{"A": newResults(newSeriesWithLabels(data.Labels{"Time": "t", "Value": "v"}, util.Pointer(1.0)))}
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+
+		frame := res.Values[0].AsDataFrame()
+		require.Equal(t, []string{"Time", "label_Time", "label_Value", "Value"}, fieldNames(frame))
+		require.Equal(t, "t", *frame.Fields[1].At(0).(*string))
+		require.Equal(t, "v", *frame.Fields[2].At(0).(*string))
+		require.Equal(t, 1.0, *frame.Fields[3].At(0).(*float64))
+	})
+
+	t.Run("fails when label is empty", func(t *testing.T) {
+		_, err := NewLabelsToFieldsCommand("B", LabelsToFieldsQuery{Expression: "$A", Labels: []string{""}})
+		require.Error(t, err)
+	})
+}
+
+func TestRenameFieldsCommandExecute(t *testing.T) {
+	t.Run("fails without renames", func(t *testing.T) {
+		_, err := NewRenameFieldsCommand("B", RenameFieldsQuery{Expression: "$A"})
+		require.Error(t, err)
+		_, err = NewRenameFieldsCommand("B", RenameFieldsQuery{Expression: "$A", Renames: map[string]string{"A": ""}})
+		require.Error(t, err)
+	})
+
+	t.Run("renames fields without modifying the input", func(t *testing.T) {
+		cmd, err := NewRenameFieldsCommand("B", RenameFieldsQuery{Expression: "$A", Renames: map[string]string{"A": "cpu"}})
+		require.NoError(t, err)
+
+		series := mathexp.NewSeries("A", data.Labels{"host": "a"}, 1)
+		series.SetPoint(0, time.Unix(0, 0), util.Pointer(1.0))
+		table := mathexp.TableData{Frame: data.NewFrame("", data.NewField("A", nil, []string{"x"}), data.NewField("other", nil, []string{"y"}))}
+		vars := mathexp.Vars{"A": newResults(series, table)}
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+		renamed := res.Values[0].(mathexp.Series)
+		require.Equal(t, "cpu", renamed.GetName())
+		require.Equal(t, data.Labels{"host": "a"}, renamed.GetLabels())
+		require.Equal(t, 1.0, *renamed.GetValue(0))
+		require.Equal(t, []string{"cpu", "other"}, fieldNames(res.Values[1].AsDataFrame()))
+		require.Equal(t, "B", renamed.Frame.RefID)
+		require.Equal(t, "B", res.Values[1].AsDataFrame().RefID)
+
+		require.Equal(t, "A", series.GetName())
+		require.Equal(t, []string{"A", "other"}, fieldNames(table.Frame))
+	})
+}
+
+func fieldNames(frame *data.Frame) []string {
+	names := make([]string, len(frame.Fields))
+	for i, f := range frame.Fields {
+		names[i] = f.Name
+	}
+	return names
+}
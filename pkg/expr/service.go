@@ -11,6 +11,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr/sql"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -67,6 +68,8 @@ type Service struct {
 	tracer          tracing.Tracer
 	metrics         *metrics
 	allowLongFrames bool
+	sqlEngine       sql.Engine
+	queryCache      *queryCache
 }
 
 type pluginContextProvider interface {
@@ -76,13 +79,16 @@ type pluginContextProvider interface {
 
 func ProvideService(cfg *setting.Cfg, pluginClient plugins.Client, pCtxProvider *plugincontext.Provider,
 	features featuremgmt.FeatureToggles, registerer prometheus.Registerer, tracer tracing.Tracer) *Service {
+	m := newMetrics(registerer)
 	return &Service{
+		sqlEngine:     newSQLEngine(cfg),
+		queryCache:    newQueryCache(cfg, m),
 		cfg:           cfg,
 		dataService:   pluginClient,
 		pCtxProvider:  pCtxProvider,
 		features:      features,
 		tracer:        tracer,
-		metrics:       newMetrics(registerer),
+		metrics:       m,
 		pluginsClient: pluginClient,
 		converter: &ResultConverter{
 			Features: features,
@@ -91,6 +97,25 @@ func ProvideService(cfg *setting.Cfg, pluginClient plugins.Client, pCtxProvider
 	}
 }
 
+// newSQLEngine returns the engine configured to run SQL expressions.
+// If the configuration is invalid, the embedded engine is used.
+func newSQLEngine(cfg *setting.Cfg) sql.Engine {
+	if cfg == nil {
+		return sql.NewEmbeddedEngine(sql.DefaultLimits)
+	}
+	limits := sql.Limits{
+		MaxInputRows:  cfg.SQLExpressionMaxInputRows,
+		MaxOutputRows: cfg.SQLExpressionMaxOutputRows,
+		MaxInputBytes: cfg.SQLExpressionMaxInputBytes,
+	}
+	engine, err := sql.NewEngine(cfg.SQLExpressionEngine, limits)
+	if err != nil {
+		logger.Error("Invalid SQL expression engine, using the embedded engine", "error", err)
+		return sql.NewEmbeddedEngine(limits)
+	}
+	return engine
+}
+
 func (s *Service) isDisabled() bool {
 	if s.cfg == nil {
 		return true
@@ -121,6 +146,19 @@ func (s *Service) ExecutePipeline(ctx context.Context, now time.Time, pipeline D
 	return res, nil
 }
 
+// ExplainPipeline executes an expression pipeline like ExecutePipeline, and also returns
+// the intermediate results, notices and execution time of each node.
+func (s *Service) ExplainPipeline(ctx context.Context, now time.Time, pipeline DataPipeline) (*backend.QueryDataResponse, *PipelineExplanation, error) {
+	explanation := newPipelineExplanation(pipeline)
+	start := time.Now()
+	res, err := s.ExecutePipeline(withExplanation(ctx, explanation), now, pipeline)
+	if err != nil {
+		return nil, nil, err
+	}
+	explanation.DurationMs = float64(time.Since(start).Nanoseconds()) / float64(time.Millisecond)
+	return res, explanation, nil
+}
+
 // Create a datasources.DataSource struct from NodeType. Returns error if kind is TypeDatasourceNode or unknown one.
 func DataSourceModelFromNodeType(kind NodeType) (*datasources.DataSource, error) {
 	switch kind {
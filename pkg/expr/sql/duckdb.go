@@ -0,0 +1,40 @@
+package sql
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/scottlepp/go-duck/duck"
+)
+
+// DuckDBEngine runs queries with an in-memory DuckDB database.
+// It requires the DuckDB binary to be available at runtime.
+type DuckDBEngine struct {
+	limits Limits
+}
+
+func NewDuckDBEngine(limits Limits) *DuckDBEngine {
+	return &DuckDBEngine{limits: limits}
+}
+
+// Tables returns the names of the tables referenced by the query using the DuckDB parser.
+func (d *DuckDBEngine) Tables(rawSQL string) ([]string, error) {
+	return TablesList(rawSQL)
+}
+
+// QueryFrames runs the query over the frames in an in-memory DuckDB database.
+// The limit of the output rows is checked after the query, since DuckDB returns the whole result at once.
+func (d *DuckDBEngine) QueryFrames(_ context.Context, name string, rawSQL string, frames []*data.Frame) (*data.Frame, error) {
+	if err := d.limits.checkInput(frames); err != nil {
+		return nil, err
+	}
+	duckDB := duck.NewInMemoryDB()
+	frame := &data.Frame{}
+	if err := duckDB.QueryFramesInto(name, rawSQL, frames, frame); err != nil {
+		return nil, err
+	}
+	if err := d.limits.checkOutput(int64(frame.Rows())); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
@@ -0,0 +1,456 @@
+package sql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/mithrandie/csvq/lib/parser"
+
+	// registers the csvq database/sql driver
+	_ "github.com/mithrandie/csvq-driver"
+)
+
+// insertBatchSize is the number of rows inserted into a table with a single statement.
+const insertBatchSize = 500
+
+// EmbeddedEngine runs queries in-process with the csvq SQL engine.
+// Every query runs in its own in-memory database that is discarded afterwards. The repository of the database,
+// from which csvq reads files, is an empty directory of its own, so that queries cannot read any file.
+type EmbeddedEngine struct {
+	limits Limits
+}
+
+func NewEmbeddedEngine(limits Limits) *EmbeddedEngine {
+	return &EmbeddedEngine{limits: limits}
+}
+
+// Tables returns the names of the tables referenced by the query. Names of common table expressions are not included.
+// Only a single SELECT statement is supported, and tables can only be read from the results of other queries or expressions.
+func (e *EmbeddedEngine) Tables(rawSQL string) ([]string, error) {
+	statements, _, err := parser.Parse(rawSQL, "", false, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(statements) != 1 {
+		return nil, fmt.Errorf("expected a single statement, got %d", len(statements))
+	}
+	if _, ok := statements[0].(parser.SelectQuery); !ok {
+		return nil, errors.New("only SELECT statements are supported")
+	}
+
+	w := tableWalker{tables: map[string]struct{}{}, inline: map[string]struct{}{}}
+	if err := w.walk(reflect.ValueOf(statements[0])); err != nil {
+		return nil, err
+	}
+	tables := make([]string, 0, len(w.tables))
+	for t := range w.tables {
+		if _, ok := w.inline[strings.ToUpper(t)]; ok {
+			continue
+		}
+		tables = append(tables, t)
+	}
+	sort.Strings(tables)
+	return tables, nil
+}
+
+// QueryFrames loads the frames into tables named by their RefID and runs the query.
+// Frames with the same RefID are loaded into the same table, with their labels added as string columns.
+func (e *EmbeddedEngine) QueryFrames(ctx context.Context, name string, rawSQL string, frames []*data.Frame) (*data.Frame, error) {
+	tableNames, err := e.Tables(rawSQL)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.limits.checkInput(frames); err != nil {
+		return nil, err
+	}
+
+	tables, err := tablesFromFrames(frames)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*table, len(tables))
+	for _, t := range tables {
+		byName[strings.ToUpper(t.name)] = t
+	}
+	for _, n := range tableNames {
+		if _, ok := byName[strings.ToUpper(n)]; !ok {
+			return nil, fmt.Errorf("table %q not found or has no data", n)
+		}
+	}
+
+	repository, err := os.MkdirTemp("", "grafana-sql-expression-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the sql expression repository: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(repository); err != nil {
+			logger.Warn("failed to remove sql expression repository", "error", err)
+		}
+	}()
+
+	// a new database is opened for every query because tables declared in one connection
+	// are visible to all connections of the same database.
+	db, err := dbsql.Open("csvq", repository)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			logger.Warn("failed to close sql expression database", "error", err)
+		}
+	}()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	for _, n := range tableNames {
+		if err := byName[strings.ToUpper(n)].load(ctx, conn); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := conn.QueryContext(ctx, rawSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	return e.frameFromRows(name, rows)
+}
+
+// tableWalker collects the tables referenced in a parsed statement.
+type tableWalker struct {
+	tables map[string]struct{}
+	// inline holds the upper cased names of common table expressions
+	inline map[string]struct{}
+}
+
+func (w *tableWalker) walk(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return w.walk(v.Elem())
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := w.walk(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+	default:
+		return nil
+	}
+
+	switch node := v.Interface().(type) {
+	case parser.Table:
+		if id, ok := node.Object.(parser.Identifier); ok {
+			w.tables[id.Literal] = struct{}{}
+			return nil
+		}
+	case parser.InlineTable:
+		w.inline[strings.ToUpper(node.Name.Literal)] = struct{}{}
+	case parser.Url, parser.TableFunction, parser.Stdin, parser.FormatSpecifiedFunction, parser.JsonQuery:
+		return fmt.Errorf("unsupported table source %q, only the results of other queries or expressions can be queried", node)
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+		if err := w.walk(v.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// table is the data of all frames of a RefID.
+type table struct {
+	name    string
+	columns []string
+	rows    [][]any
+}
+
+func tablesFromFrames(frames []*data.Frame) ([]*table, error) {
+	tables := make([]*table, 0)
+	byRef := map[string]*table{}
+	for _, frame := range frames {
+		if frame == nil || len(frame.Fields) == 0 {
+			continue
+		}
+		t, ok := byRef[frame.RefID]
+		if !ok {
+			t = &table{name: frame.RefID}
+			byRef[frame.RefID] = t
+			tables = append(tables, t)
+		}
+
+		columns := make([]string, 0, len(frame.Fields))
+		labels := data.Labels{}
+		for i, f := range frame.Fields {
+			name := f.Name
+			if name == "" {
+				name = fmt.Sprintf("column%d", i)
+			}
+			columns = append(columns, name)
+			for k, v := range f.Labels {
+				labels[k] = v
+			}
+		}
+		labelKeys := make([]string, 0, len(labels))
+		for k := range labels {
+			if !contains(columns, k) {
+				labelKeys = append(labelKeys, k)
+			}
+		}
+		sort.Strings(labelKeys)
+
+		idx := make([]int, 0, len(columns)+len(labelKeys))
+		for _, c := range append(columns, labelKeys...) {
+			idx = append(idx, t.column(c))
+		}
+
+		rows := frame.Rows()
+		for r := 0; r < rows; r++ {
+			row := make([]any, len(idx))
+			for i, f := range frame.Fields {
+				v, err := inputValue(f, r)
+				if err != nil {
+					return nil, err
+				}
+				row[i] = v
+			}
+			for i, k := range labelKeys {
+				row[len(frame.Fields)+i] = labels[k]
+			}
+			t.rows = append(t.rows, reorder(row, idx))
+		}
+	}
+
+	// rows of earlier frames do not have the columns added by later frames
+	for _, t := range tables {
+		for i, row := range t.rows {
+			if len(row) < len(t.columns) {
+				t.rows[i] = append(row, make([]any, len(t.columns)-len(row))...)
+			}
+		}
+	}
+	return tables, nil
+}
+
+// column returns the index of the column with the given name, adding it if needed.
+func (t *table) column(name string) int {
+	for i, c := range t.columns {
+		if c == name {
+			return i
+		}
+	}
+	t.columns = append(t.columns, name)
+	return len(t.columns) - 1
+}
+
+// load declares the table as a view and inserts its rows.
+func (t *table) load(ctx context.Context, conn *dbsql.Conn) error {
+	columns := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		columns[i] = quoteIdentifier(c)
+	}
+	name := quoteIdentifier(t.name)
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("DECLARE %s VIEW (%s)", name, strings.Join(columns, ", "))); err != nil {
+		return fmt.Errorf("failed to create table %q: %w", t.name, err)
+	}
+
+	placeholder := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(t.columns)), ", ") + ")"
+	for start := 0; start < len(t.rows); start += insertBatchSize {
+		end := start + insertBatchSize
+		if end > len(t.rows) {
+			end = len(t.rows)
+		}
+		values := make([]string, 0, end-start)
+		args := make([]any, 0, (end-start)*len(t.columns))
+		for _, row := range t.rows[start:end] {
+			values = append(values, placeholder)
+			args = append(args, row...)
+		}
+		query := fmt.Sprintf("INSERT INTO %s VALUES %s", name, strings.Join(values, ", "))
+		if _, err := conn.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to insert into table %q: %w", t.name, err)
+		}
+	}
+	return nil
+}
+
+func (e *EmbeddedEngine) frameFromRows(name string, rows *dbsql.Rows) (*data.Frame, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	values := make([][]any, len(columns))
+	var count int64
+	for rows.Next() {
+		count++
+		if err := e.limits.checkOutput(count); err != nil {
+			return nil, err
+		}
+		row := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range row {
+			ptrs[i] = &row[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		for i, v := range row {
+			values[i] = append(values[i], v)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	frame := data.NewFrame(name)
+	for i, c := range columns {
+		frame.Fields = append(frame.Fields, outputField(c, values[i]))
+	}
+	return frame, nil
+}
+
+// inputValue converts the value of a field to a type supported by the engine.
+func inputValue(f *data.Field, idx int) (any, error) {
+	v, ok := f.ConcreteAt(idx)
+	if !ok {
+		return nil, nil
+	}
+	switch val := v.(type) {
+	case time.Time, string, bool, float64, float32, int64, int32, int16, int8, uint64, uint32, uint16, uint8:
+		return val, nil
+	case json.RawMessage:
+		return string(val), nil
+	default:
+		return nil, fmt.Errorf("unsupported field type %s of field %q", f.Type(), f.Name)
+	}
+}
+
+// outputField creates a field from the values of a result column. The type of the field is inferred from the values:
+// integers, numbers, times and booleans keep their type, anything else is converted to a string.
+func outputField(name string, values []any) *data.Field {
+	allInt, allNumber, allTime, allBool := true, true, true, true
+	for _, v := range values {
+		switch v.(type) {
+		case nil:
+		case int64:
+			allTime, allBool = false, false
+		case float64:
+			allInt, allTime, allBool = false, false, false
+		case time.Time:
+			allInt, allNumber, allBool = false, false, false
+		case bool:
+			allInt, allNumber, allTime = false, false, false
+		default:
+			allInt, allNumber, allTime, allBool = false, false, false, false
+		}
+	}
+
+	switch {
+	case allInt && !allNil(values):
+		vals := make([]*int64, len(values))
+		for i, v := range values {
+			if n, ok := v.(int64); ok {
+				vals[i] = &n
+			}
+		}
+		return data.NewField(name, nil, vals)
+	case allNumber:
+		vals := make([]*float64, len(values))
+		for i, v := range values {
+			switch n := v.(type) {
+			case int64:
+				f := float64(n)
+				vals[i] = &f
+			case float64:
+				vals[i] = &n
+			}
+		}
+		return data.NewField(name, nil, vals)
+	case allTime:
+		vals := make([]*time.Time, len(values))
+		for i, v := range values {
+			if t, ok := v.(time.Time); ok {
+				vals[i] = &t
+			}
+		}
+		return data.NewField(name, nil, vals)
+	case allBool:
+		vals := make([]*bool, len(values))
+		for i, v := range values {
+			if b, ok := v.(bool); ok {
+				vals[i] = &b
+			}
+		}
+		return data.NewField(name, nil, vals)
+	default:
+		vals := make([]*string, len(values))
+		for i, v := range values {
+			if v != nil {
+				s := fmt.Sprint(v)
+				vals[i] = &s
+			}
+		}
+		return data.NewField(name, nil, vals)
+	}
+}
+
+// allNil returns true if all values are nil. Such columns are returned as nullable floats.
+func allNil(values []any) bool {
+	for _, v := range values {
+		if v != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "\\`") + "`"
+}
+
+func reorder(row []any, idx []int) []any {
+	result := make([]any, maxIndex(idx)+1)
+	for i, v := range row {
+		result[idx[i]] = v
+	}
+	return result
+}
+
+func maxIndex(idx []int) int {
+	m := -1
+	for _, i := range idx {
+		if i > m {
+			m = i
+		}
+	}
+	return m
+}
+
+func contains(s []string, v string) bool {
+	for _, item := range s {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedTables(t *testing.T) {
+	e := NewEmbeddedEngine(Limits{})
+
+	tests := []struct {
+		name     string
+		sql      string
+		expected []string
+		isError  bool
+	}{
+		{
+			name:     "single table",
+			sql:      "SELECT * FROM A",
+			expected: []string{"A"},
+		},
+		{
+			name:     "join",
+			sql:      "SELECT A.value, B.value FROM A JOIN B ON A.time = B.time",
+			expected: []string{"A", "B"},
+		},
+		{
+			name:     "subquery",
+			sql:      "SELECT * FROM (SELECT * FROM A) a WHERE a.value IN (SELECT value FROM B)",
+			expected: []string{"A", "B"},
+		},
+		{
+			name:     "common table expression is not a table",
+			sql:      "WITH c AS (SELECT * FROM A) SELECT * FROM c",
+			expected: []string{"A"},
+		},
+		{
+			name:     "no tables",
+			sql:      "SELECT 1",
+			expected: []string{},
+		},
+		{
+			name:    "invalid sql",
+			sql:     "SELECT FROM WHERE",
+			isError: true,
+		},
+		{
+			name:    "multiple statements",
+			sql:     "SELECT * FROM A; SELECT * FROM B",
+			isError: true,
+		},
+		{
+			name:    "not a select",
+			sql:     "DELETE FROM A",
+			isError: true,
+		},
+		{
+			name:    "table function",
+			sql:     "SELECT * FROM HTTP::`https://example.com/data.csv`",
+			isError: true,
+		},
+		{
+			name:    "stdin",
+			sql:     "SELECT * FROM STDIN",
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables, err := e.Tables(tt.sql)
+			if tt.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tables)
+		})
+	}
+}
+
+func TestEmbeddedQueryFrames(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	series := func(refID, host string, values ...float64) *data.Frame {
+		times := make([]time.Time, len(values))
+		vals := make([]*float64, len(values))
+		for i := range values {
+			times[i] = start.Add(time.Duration(i) * time.Minute)
+			vals[i] = &values[i]
+		}
+		frame := data.NewFrame("",
+			data.NewField("time", nil, times),
+			data.NewField("value", data.Labels{"host": host}, vals),
+		)
+		frame.RefID = refID
+		return frame
+	}
+	frames := []*data.Frame{
+		series("A", "a", 1, 2, 3),
+		series("A", "b", 10, 20, 30),
+		series("B", "a", 100, 200, 300),
+	}
+
+	t.Run("group by labels", func(t *testing.T) {
+		e := NewEmbeddedEngine(Limits{})
+		frame, err := e.QueryFrames(context.Background(), "C", "SELECT host, SUM(value) AS total, COUNT(*) AS n FROM A GROUP BY host ORDER BY host", frames)
+		require.NoError(t, err)
+		require.Equal(t, "C", frame.Name)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[0].Type())
+		require.Equal(t, "a", *frame.Fields[0].At(0).(*string))
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[1].Type())
+		require.Equal(t, float64(60), *frame.Fields[1].At(1).(*float64))
+		require.Equal(t, data.FieldTypeNullableInt64, frame.Fields[2].Type())
+		require.Equal(t, int64(3), *frame.Fields[2].At(0).(*int64))
+	})
+
+	t.Run("join", func(t *testing.T) {
+		e := NewEmbeddedEngine(Limits{})
+		frame, err := e.QueryFrames(context.Background(), "C",
+			"SELECT A.time, A.value + B.value AS total FROM A JOIN B ON A.time = B.time AND A.host = B.host ORDER BY A.time", frames)
+		require.NoError(t, err)
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+		require.Equal(t, start, *frame.Fields[0].At(0).(*time.Time))
+		require.Equal(t, float64(303), *frame.Fields[1].At(2).(*float64))
+	})
+
+	t.Run("window function", func(t *testing.T) {
+		e := NewEmbeddedEngine(Limits{})
+		frame, err := e.QueryFrames(context.Background(), "C",
+			"SELECT host, value, ROW_NUMBER() OVER (PARTITION BY host ORDER BY value DESC) AS pos FROM A ORDER BY host, pos", frames)
+		require.NoError(t, err)
+		require.Equal(t, 6, frame.Rows())
+		require.Equal(t, float64(3), *frame.Fields[1].At(0).(*float64))
+		require.Equal(t, int64(1), *frame.Fields[2].At(0).(*int64))
+	})
+
+	t.Run("no rows", func(t *testing.T) {
+		e := NewEmbeddedEngine(Limits{})
+		frame, err := e.QueryFrames(context.Background(), "C", "SELECT * FROM A WHERE value > 1000", frames)
+		require.NoError(t, err)
+		require.Equal(t, 0, frame.Rows())
+	})
+
+	t.Run("error when a table has no data", func(t *testing.T) {
+		e := NewEmbeddedEngine(Limits{})
+		_, err := e.QueryFrames(context.Background(), "C", "SELECT * FROM D", frames)
+		require.ErrorContains(t, err, "not found")
+	})
+
+	t.Run("error when input rows exceed the limit", func(t *testing.T) {
+		e := NewEmbeddedEngine(Limits{MaxInputRows: 5})
+		_, err := e.QueryFrames(context.Background(), "C", "SELECT * FROM A", frames)
+		require.True(t, errors.Is(err, ErrInputRowsLimit))
+	})
+
+	t.Run("error when input size exceeds the limit", func(t *testing.T) {
+		e := NewEmbeddedEngine(Limits{MaxInputBytes: 64})
+		_, err := e.QueryFrames(context.Background(), "C", "SELECT * FROM A", frames)
+		require.True(t, errors.Is(err, ErrInputBytesLimit))
+	})
+
+	t.Run("error when output rows exceed the limit", func(t *testing.T) {
+		e := NewEmbeddedEngine(Limits{MaxOutputRows: 2})
+		_, err := e.QueryFrames(context.Background(), "C", "SELECT * FROM A", frames)
+		require.True(t, errors.Is(err, ErrOutputRowsLimit))
+	})
+
+	t.Run("tables do not leak between queries", func(t *testing.T) {
+		e := NewEmbeddedEngine(Limits{})
+		_, err := e.QueryFrames(context.Background(), "C", "SELECT * FROM B", frames)
+		require.NoError(t, err)
+		_, err = e.QueryFrames(context.Background(), "C", "SELECT * FROM B", frames[:2])
+		require.Error(t, err)
+	})
+}
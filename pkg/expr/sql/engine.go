@@ -0,0 +1,113 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Engine runs SQL queries over data frames. Each frame is available to the query
+// as a table named after the RefID of the frame.
+type Engine interface {
+	// Tables returns the names of the tables referenced by the query.
+	Tables(rawSQL string) ([]string, error)
+	// QueryFrames runs the query over the frames and returns the result as a frame with the given name.
+	QueryFrames(ctx context.Context, name string, rawSQL string, frames []*data.Frame) (*data.Frame, error)
+}
+
+const (
+	// EngineEmbedded runs queries in-process without any external dependency.
+	EngineEmbedded = "embedded"
+	// EngineDuckDB runs queries with the DuckDB binary, which must be available at runtime.
+	EngineDuckDB = "duckdb"
+)
+
+var (
+	ErrInputRowsLimit  = errors.New("sql expression input exceeds the maximum number of rows")
+	ErrInputBytesLimit = errors.New("sql expression input exceeds the maximum size")
+	ErrOutputRowsLimit = errors.New("sql expression result exceeds the maximum number of rows")
+)
+
+// Limits bound the resources used by a query. A zero value means no limit.
+type Limits struct {
+	// MaxInputRows is the maximum number of rows of all input tables.
+	MaxInputRows int64
+	// MaxOutputRows is the maximum number of rows of the result.
+	MaxOutputRows int64
+	// MaxInputBytes is the maximum estimated size in memory of all input tables.
+	MaxInputBytes int64
+}
+
+// DefaultLimits are the limits used when no configuration is available. They are the defaults of the configuration.
+var DefaultLimits = Limits{
+	MaxInputRows:  100000,
+	MaxOutputRows: 100000,
+	MaxInputBytes: 100 * 1024 * 1024,
+}
+
+// checkInput returns an error if the frames exceed the limits of the input rows or size.
+// The size of a row includes the labels of the fields, since they are added to the tables as columns.
+func (l Limits) checkInput(frames []*data.Frame) error {
+	var rowCount, size int64
+	for _, frame := range frames {
+		if frame == nil {
+			continue
+		}
+		rows := frame.Rows()
+		rowCount += int64(rows)
+		if l.MaxInputRows > 0 && rowCount > l.MaxInputRows {
+			return fmt.Errorf("%w: %d", ErrInputRowsLimit, l.MaxInputRows)
+		}
+		if l.MaxInputBytes <= 0 {
+			continue
+		}
+		for _, f := range frame.Fields {
+			var labelsSize int64
+			for k, v := range f.Labels {
+				labelsSize += valueSize(k) + valueSize(v)
+			}
+			for r := 0; r < rows; r++ {
+				v, _ := f.ConcreteAt(r)
+				size += valueSize(v) + labelsSize
+			}
+			if size > l.MaxInputBytes {
+				return fmt.Errorf("%w: %d bytes", ErrInputBytesLimit, l.MaxInputBytes)
+			}
+		}
+	}
+	return nil
+}
+
+// checkOutput returns an error if the number of rows exceeds the limit of the output rows.
+func (l Limits) checkOutput(rows int64) error {
+	if l.MaxOutputRows > 0 && rows > l.MaxOutputRows {
+		return fmt.Errorf("%w: %d", ErrOutputRowsLimit, l.MaxOutputRows)
+	}
+	return nil
+}
+
+// valueSize is a rough estimate of the memory used by a value.
+func valueSize(v any) int64 {
+	switch val := v.(type) {
+	case string:
+		return int64(len(val)) + 16
+	case []byte:
+		return int64(len(val)) + 24
+	default:
+		return 8
+	}
+}
+
+// NewEngine returns the engine of the given type.
+func NewEngine(engineType string, limits Limits) (Engine, error) {
+	switch engineType {
+	case EngineEmbedded, "":
+		return NewEmbeddedEngine(limits), nil
+	case EngineDuckDB:
+		return NewDuckDBEngine(limits), nil
+	default:
+		return nil, fmt.Errorf("unknown sql expression engine %q, supported engines are [%s, %s]", engineType, EngineEmbedded, EngineDuckDB)
+	}
+}
@@ -0,0 +1,43 @@
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimits(t *testing.T) {
+	frame := data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b", "c"}),
+		data.NewField("value", data.Labels{"env": "prod"}, []float64{1, 2, 3}))
+	frames := []*data.Frame{frame, frame}
+
+	t.Run("frames within the limits", func(t *testing.T) {
+		require.NoError(t, Limits{}.checkInput(frames))
+		require.NoError(t, Limits{MaxInputRows: 6, MaxInputBytes: 1024}.checkInput(frames))
+		require.NoError(t, Limits{MaxOutputRows: 6}.checkOutput(6))
+	})
+
+	t.Run("input rows of all frames are counted", func(t *testing.T) {
+		require.ErrorIs(t, Limits{MaxInputRows: 5}.checkInput(frames), ErrInputRowsLimit)
+	})
+
+	t.Run("input size includes the labels", func(t *testing.T) {
+		// 3 strings of 17 bytes, and 3 numbers of 8 bytes with labels of 39 bytes
+		require.NoError(t, Limits{MaxInputBytes: 192}.checkInput(frames[:1]))
+		require.ErrorIs(t, Limits{MaxInputBytes: 191}.checkInput(frames[:1]), ErrInputBytesLimit)
+	})
+
+	t.Run("output rows", func(t *testing.T) {
+		require.ErrorIs(t, Limits{MaxOutputRows: 5}.checkOutput(6), ErrOutputRowsLimit)
+	})
+
+	t.Run("limits are applied by the duckdb engine", func(t *testing.T) {
+		engine, err := NewEngine(EngineDuckDB, Limits{MaxInputRows: 5})
+		require.NoError(t, err)
+		_, err = engine.QueryFrames(context.Background(), "C", "SELECT * FROM A", frames)
+		require.ErrorIs(t, err, ErrInputRowsLimit)
+	})
+}
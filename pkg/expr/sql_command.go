@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
-	"github.com/scottlepp/go-duck/duck"
 
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
@@ -20,19 +19,24 @@ type SQLCommand struct {
 	query       string
 	varsToQuery []string
 	refID       string
+	engine      sql.Engine
 }
 
-// NewSQLCommand creates a new SQLCommand.
-func NewSQLCommand(refID, rawSQL string) (*SQLCommand, error) {
+// NewSQLCommand creates a new SQLCommand that runs the query with the given engine.
+// If engine is nil, the embedded engine with the default limits is used.
+func NewSQLCommand(refID, rawSQL string, engine sql.Engine) (*SQLCommand, error) {
 	if rawSQL == "" {
 		return nil, errutil.BadRequest("sql-missing-query",
 			errutil.WithPublicMessage("missing SQL query"))
 	}
-	tables, err := sql.TablesList(rawSQL)
+	if engine == nil {
+		engine = sql.NewEmbeddedEngine(sql.DefaultLimits)
+	}
+	tables, err := engine.Tables(rawSQL)
 	if err != nil {
 		logger.Warn("invalid sql query", "sql", rawSQL, "error", err)
 		return nil, errutil.BadRequest("sql-invalid-sql",
-			errutil.WithPublicMessage("error reading SQL command"),
+			errutil.WithPublicMessage(fmt.Sprintf("error reading SQL command: %s", err)),
 		)
 	}
 	if len(tables) == 0 {
@@ -45,11 +49,12 @@ func NewSQLCommand(refID, rawSQL string) (*SQLCommand, error) {
 		query:       rawSQL,
 		varsToQuery: tables,
 		refID:       refID,
+		engine:      engine,
 	}, nil
 }
 
 // UnmarshalSQLCommand creates a SQLCommand from Grafana's frontend query.
-func UnmarshalSQLCommand(rn *rawNode) (*SQLCommand, error) {
+func UnmarshalSQLCommand(rn *rawNode, engine sql.Engine) (*SQLCommand, error) {
 	if rn.TimeRange == nil {
 		logger.Error("time range must be specified for refID", "refID", rn.RefID)
 		return nil, fmt.Errorf("time range must be specified for refID %s", rn.RefID)
@@ -66,7 +71,7 @@ func UnmarshalSQLCommand(rn *rawNode) (*SQLCommand, error) {
 		return nil, fmt.Errorf("expected sql expression to be type string, but got type %T", expressionRaw)
 	}
 
-	return NewSQLCommand(rn.RefID, expression)
+	return NewSQLCommand(rn.RefID, expression, engine)
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -78,7 +83,7 @@ func (gr *SQLCommand) NeedsVars() []string {
 // Execute runs the command and returns the results or an error if the command
 // failed to execute.
 func (gr *SQLCommand) Execute(ctx context.Context, now time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
-	_, span := tracer.Start(ctx, "SSE.ExecuteSQL")
+	ctx, span := tracer.Start(ctx, "SSE.ExecuteSQL")
 	defer span.End()
 
 	allFrames := []*data.Frame{}
@@ -94,11 +99,8 @@ func (gr *SQLCommand) Execute(ctx context.Context, now time.Time, vars mathexp.V
 
 	rsp := mathexp.Results{}
 
-	duckDB := duck.NewInMemoryDB()
-	var frame = &data.Frame{}
-
 	logger.Debug("Executing query", "query", gr.query, "frames", len(allFrames))
-	err := duckDB.QueryFramesInto(gr.refID, gr.query, allFrames, frame)
+	frame, err := gr.engine.QueryFrames(ctx, gr.refID, gr.query, allFrames)
 	if err != nil {
 		logger.Error("Failed to query frames", "error", err.Error())
 		rsp.Error = err
@@ -112,6 +114,7 @@ func (gr *SQLCommand) Execute(ctx context.Context, now time.Time, vars mathexp.V
 		rsp.Values = mathexp.Values{
 			mathexp.NoData{Frame: frame},
 		}
+		return rsp, nil
 	}
 
 	rsp.Values = mathexp.Values{
@@ -1,27 +1,85 @@
 package expr
 
 import (
-	"strings"
+	"context"
+	"fmt"
 	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/expr/sql"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func TestNewCommand(t *testing.T) {
-	t.Skip()
-	cmd, err := NewSQLCommand("a", "select a from foo, bar")
-	if err != nil && strings.Contains(err.Error(), "feature is not enabled") {
-		return
-	}
+	cmd, err := NewSQLCommand("a", "select a from foo, bar", nil)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"foo", "bar"}, cmd.varsToQuery)
+
+	_, err = NewSQLCommand("a", "", nil)
+	require.Error(t, err)
+
+	_, err = NewSQLCommand("a", "select from", nil)
+	require.Error(t, err)
+}
+
+func TestSQLCommandExecute(t *testing.T) {
+	cmd, err := NewSQLCommand("B", "SELECT SUM(A) AS total FROM A WHERE A > 1", nil)
+	require.NoError(t, err)
 
-	if err != nil {
-		t.Fail()
-		return
+	number := func(v float64) mathexp.Value {
+		n := mathexp.NewNumber("A", data.Labels{"host": "a"})
+		n.SetValue(&v)
+		return n
 	}
 
-	for _, v := range cmd.varsToQuery {
-		if strings.Contains("foo bar", v) {
-			continue
-		}
-		t.Fail()
-		return
+	t.Run("should return a table", func(t *testing.T) {
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{number(1), number(2), number(3)}}}
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		require.Len(t, res.Values, 1)
+		require.IsType(t, mathexp.TableData{}, res.Values[0])
+		frame := res.Values[0].AsDataFrame()
+		require.Equal(t, "B", frame.RefID)
+		require.Equal(t, float64(5), *frame.Fields[0].At(0).(*float64))
+	})
+
+	t.Run("should return no data when the result has no rows", func(t *testing.T) {
+		cmd, err := NewSQLCommand("B", "SELECT A FROM A WHERE A > 10", nil)
+		require.NoError(t, err)
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{number(1)}}}
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, parse.TypeNoData, res.Values[0].Type())
+	})
+}
+
+func TestExpressionQueryReaderSQLEngine(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.SQLExpressionMaxInputRows = 2
+	reader := NewExpressionQueryReader(featuremgmt.WithFeatures(), cfg)
+
+	cmd, err := NewSQLCommand("B", "SELECT SUM(A) AS total FROM A", reader.sqlEngine)
+	require.NoError(t, err)
+
+	values := mathexp.Values{}
+	for i := 0; i < 3; i++ {
+		n := mathexp.NewNumber("A", data.Labels{"host": fmt.Sprint(i)})
+		n.SetValue(util.Pointer(float64(i)))
+		values = append(values, n)
+	}
+	res, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{"A": mathexp.Results{Values: values}}, tracing.InitializeTracerForTest())
+	if err == nil {
+		err = res.Error
 	}
+	require.ErrorIs(t, err, sql.ErrInputRowsLimit)
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -25,7 +26,11 @@ type ThresholdCommand struct {
 	RefID         string
 	ThresholdFunc ThresholdType
 	Invert        bool
-	predicate     predicate
+	// Consecutive is the number of last points of a series that must meet the condition. 0 means disabled.
+	Consecutive int
+	// Percent is the percentage of the points of a series that must meet the condition. 0 means disabled.
+	Percent   float64
+	predicate predicate
 }
 
 // +enum
@@ -82,9 +87,41 @@ func NewThresholdCommand(refID, referenceVar string, thresholdFunc ThresholdType
 	}, nil
 }
 
+// NewThresholdCommandFromEvaluator creates a new ThresholdCommand from the evaluator of a threshold condition.
+func NewThresholdCommandFromEvaluator(refID, referenceVar string, evaluator ConditionEvalJSON) (*ThresholdCommand, error) {
+	cmd, err := NewThresholdCommand(refID, referenceVar, evaluator.Type, evaluator.Params)
+	if err != nil {
+		return nil, err
+	}
+	if evaluator.Consecutive != nil && evaluator.Percent != nil {
+		return nil, errors.New("only one of consecutive and percent can be set")
+	}
+	if evaluator.Consecutive != nil {
+		if *evaluator.Consecutive < 1 {
+			return nil, fmt.Errorf("consecutive must be greater than 0, got %d", *evaluator.Consecutive)
+		}
+		cmd.Consecutive = *evaluator.Consecutive
+	}
+	if evaluator.Percent != nil {
+		if *evaluator.Percent <= 0 || *evaluator.Percent > 100 {
+			return nil, fmt.Errorf("percent must be greater than 0 and less than or equal to 100, got %v", *evaluator.Percent)
+		}
+		cmd.Percent = *evaluator.Percent
+	}
+	return cmd, nil
+}
+
 type ConditionEvalJSON struct {
 	Params []float64     `json:"params"`
 	Type   ThresholdType `json:"type"` // e.g. "gt"
+
+	// The condition is met by a series when it holds for this number of consecutive points at the end of the series.
+	// The result is a number for each series instead of a series
+	Consecutive *int `json:"consecutive,omitempty" jsonschema:"minimum=1,example=3"`
+
+	// The condition is met by a series when it holds for at least this percentage of the points with a value.
+	// The result is a number for each series instead of a series
+	Percent *float64 `json:"percent,omitempty" jsonschema:"minimum=0,maximum=100,example=80"`
 }
 
 // UnmarshalResampleCommand creates a ResampleCMD from Grafana's frontend query.
@@ -104,12 +141,12 @@ func UnmarshalThresholdCommand(rn *rawNode, features featuremgmt.FeatureToggles)
 	}
 	firstCondition := cmdConfig.Conditions[0]
 
-	threshold, err := NewThresholdCommand(rn.RefID, referenceVar, firstCondition.Evaluator.Type, firstCondition.Evaluator.Params)
+	threshold, err := NewThresholdCommandFromEvaluator(rn.RefID, referenceVar, firstCondition.Evaluator)
 	if err != nil {
 		return nil, fmt.Errorf("invalid condition: %w", err)
 	}
 	if firstCondition.UnloadEvaluator != nil && features.IsEnabledGlobally(featuremgmt.FlagRecoveryThreshold) {
-		unloading, err := NewThresholdCommand(rn.RefID, referenceVar, firstCondition.UnloadEvaluator.Type, firstCondition.UnloadEvaluator.Params)
+		unloading, err := NewThresholdCommandFromEvaluator(rn.RefID, referenceVar, *firstCondition.UnloadEvaluator)
 		if err != nil {
 			return nil, fmt.Errorf("invalid unloadCondition: %w", err)
 		}
@@ -137,14 +174,7 @@ func (tc *ThresholdCommand) Execute(_ context.Context, _ time.Time, vars mathexp
 		if maybeValue == nil {
 			return nil
 		}
-		result := tc.predicate.Eval(*maybeValue)
-		if tc.Invert {
-			result = !result
-		}
-		if result {
-			return util.Pointer(float64(1))
-		}
-		return util.Pointer(float64(0))
+		return tc.result(tc.predicate.Eval(*maybeValue))
 	}
 
 	refVarResult := vars[tc.ReferenceVar]
@@ -152,6 +182,12 @@ func (tc *ThresholdCommand) Execute(_ context.Context, _ time.Time, vars mathexp
 	for _, val := range refVarResult.Values {
 		switch v := val.(type) {
 		case mathexp.Series:
+			if tc.Consecutive > 0 || tc.Percent > 0 {
+				n := mathexp.NewNumber(tc.RefID, v.GetLabels())
+				n.SetValue(tc.evalPoints(v))
+				newRes.Values = append(newRes.Values, n)
+				continue
+			}
 			s := mathexp.NewSeries(tc.RefID, v.GetLabels(), v.Len())
 			for i := 0; i < v.Len(); i++ {
 				t, value := v.GetPoint(i)
@@ -174,6 +210,47 @@ func (tc *ThresholdCommand) Execute(_ context.Context, _ time.Time, vars mathexp
 	return newRes, nil
 }
 
+// evalPoints checks whether the last points of the series meet the condition for the required number of consecutive points
+// or the required percentage of points. Points without a value do not meet the condition and are not counted in the percentage.
+// The points are evaluated from oldest to newest. Returns nil if the series has no points with a value.
+func (tc *ThresholdCommand) evalPoints(s mathexp.Series) *float64 {
+	s = s.SortedByTime()
+	var matched, total, run int
+	for i := 0; i < s.Len(); i++ {
+		v := s.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			run = 0
+			continue
+		}
+		total++
+		if !tc.predicate.Eval(*v) {
+			run = 0
+			continue
+		}
+		matched++
+		run++
+	}
+	if total == 0 {
+		return nil
+	}
+	if tc.Consecutive > 0 {
+		// only the trailing run counts, so that a condition that held in the past but no longer holds does not fire
+		return tc.result(run >= tc.Consecutive)
+	}
+	return tc.result(float64(matched)/float64(total)*100 >= tc.Percent)
+}
+
+// result converts the outcome of the condition to 1 or 0, inverting it if needed.
+func (tc *ThresholdCommand) result(ok bool) *float64 {
+	if tc.Invert {
+		ok = !ok
+	}
+	if ok {
+		return util.Pointer(float64(1))
+	}
+	return util.Pointer(float64(0))
+}
+
 func (tc *ThresholdCommand) Type() string {
 	return TypeThreshold.String()
 }
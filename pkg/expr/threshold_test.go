@@ -228,6 +228,164 @@ func TestUnmarshalThresholdCommand(t *testing.T) {
 	}
 }
 
+func TestNewThresholdCommandFromEvaluator(t *testing.T) {
+	cases := []struct {
+		name                string
+		evaluator           ConditionEvalJSON
+		expectedConsecutive int
+		expectedPercent     float64
+		expectedError       string
+	}{
+		{
+			name:      "without points condition",
+			evaluator: ConditionEvalJSON{Type: ThresholdIsAbove, Params: []float64{1}},
+		},
+		{
+			name:                "consecutive",
+			evaluator:           ConditionEvalJSON{Type: ThresholdIsAbove, Params: []float64{1}, Consecutive: util.Pointer(3)},
+			expectedConsecutive: 3,
+		},
+		{
+			name:            "percent",
+			evaluator:       ConditionEvalJSON{Type: ThresholdIsWithinRange, Params: []float64{1, 2}, Percent: util.Pointer(50.0)},
+			expectedPercent: 50,
+		},
+		{
+			name:          "consecutive and percent",
+			evaluator:     ConditionEvalJSON{Type: ThresholdIsAbove, Params: []float64{1}, Consecutive: util.Pointer(3), Percent: util.Pointer(50.0)},
+			expectedError: "only one of consecutive and percent can be set",
+		},
+		{
+			name:          "consecutive is not positive",
+			evaluator:     ConditionEvalJSON{Type: ThresholdIsAbove, Params: []float64{1}, Consecutive: util.Pointer(0)},
+			expectedError: "consecutive must be greater than 0",
+		},
+		{
+			name:          "percent is out of range",
+			evaluator:     ConditionEvalJSON{Type: ThresholdIsAbove, Params: []float64{1}, Percent: util.Pointer(120.0)},
+			expectedError: "percent must be greater than 0",
+		},
+		{
+			name:          "invalid threshold function",
+			evaluator:     ConditionEvalJSON{Type: "foo", Params: []float64{1}, Consecutive: util.Pointer(3)},
+			expectedError: "expected threshold function to be one of",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, err := NewThresholdCommandFromEvaluator("B", "A", tc.evaluator)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedConsecutive, cmd.Consecutive)
+			require.Equal(t, tc.expectedPercent, cmd.Percent)
+		})
+	}
+}
+
+func TestThresholdExecutePoints(t *testing.T) {
+	labels := data.Labels{"host": "a"}
+	series := newSeriesWithLabels(labels,
+		util.Pointer(5.0), util.Pointer(11.0), util.Pointer(12.0), nil, util.Pointer(13.0), util.Pointer(14.0), util.Pointer(15.0))
+	newestFirst := newSeriesWithLabels(labels, util.Pointer(1.0), util.Pointer(13.0), util.Pointer(14.0), util.Pointer(15.0))
+	newestFirst.SortByTime(true)
+
+	cases := []struct {
+		name        string
+		consecutive int
+		percent     float64
+		invert      bool
+		threshold   float64
+		input       mathexp.Value
+		expected    mathexp.Value
+	}{
+		{
+			name:        "condition holds for consecutive points",
+			consecutive: 3,
+			input:       series,
+			expected:    newNumber(labels, util.Pointer(1.0)),
+		},
+		{
+			name:        "condition must hold for the last points",
+			consecutive: 2,
+			threshold:   90,
+			input:       newSeriesWithLabels(labels, util.Pointer(50.0), util.Pointer(95.0), util.Pointer(95.0), util.Pointer(50.0)),
+			expected:    newNumber(labels, util.Pointer(0.0)),
+		},
+		{
+			name:        "earlier points do not extend the last points",
+			consecutive: 3,
+			input:       newSeriesWithLabels(labels, util.Pointer(11.0), util.Pointer(12.0), util.Pointer(13.0), util.Pointer(1.0), util.Pointer(14.0), util.Pointer(15.0)),
+			expected:    newNumber(labels, util.Pointer(0.0)),
+		},
+		{
+			name:        "points are evaluated from oldest to newest",
+			consecutive: 3,
+			input:       newestFirst,
+			expected:    newNumber(labels, util.Pointer(1.0)),
+		},
+		{
+			name:        "points without value break consecutive points",
+			consecutive: 4,
+			input:       series,
+			expected:    newNumber(labels, util.Pointer(0.0)),
+		},
+		{
+			name:        "inverted consecutive points",
+			consecutive: 4,
+			invert:      true,
+			input:       series,
+			expected:    newNumber(labels, util.Pointer(1.0)),
+		},
+		{
+			name:     "condition holds for percentage of points",
+			percent:  80,
+			input:    series,
+			expected: newNumber(labels, util.Pointer(1.0)),
+		},
+		{
+			name:     "condition does not hold for percentage of points",
+			percent:  85,
+			input:    series,
+			expected: newNumber(labels, util.Pointer(0.0)),
+		},
+		{
+			name:        "series without values",
+			consecutive: 1,
+			input:       newSeriesWithLabels(labels, nil, nil),
+			expected:    newNumber(labels, nil),
+		},
+		{
+			name:        "numbers are evaluated as usual",
+			consecutive: 3,
+			input:       newNumber(labels, util.Pointer(11.0)),
+			expected:    newNumber(labels, util.Pointer(1.0)),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			threshold := tc.threshold
+			if threshold == 0 {
+				threshold = 10
+			}
+			cmd := ThresholdCommand{
+				ReferenceVar: "A",
+				Consecutive:  tc.consecutive,
+				Percent:      tc.percent,
+				Invert:       tc.invert,
+				predicate:    greaterThanPredicate{threshold},
+			}
+			result, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+				"A": newResults(tc.input),
+			}, tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+			require.Equal(t, newResults(tc.expected), result)
+		})
+	}
+}
+
 func TestThresholdCommandVars(t *testing.T) {
 	cmd, err := NewThresholdCommand("B", "A", "lt", []float64{1.0})
 	require.Nil(t, err)
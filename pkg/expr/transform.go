@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -16,9 +17,13 @@ import (
 type Request struct {
 	Headers map[string]string
 	Debug   bool
-	OrgId   int64
-	Queries []Query
-	User    identity.Requester
+	// Explain adds the explanation of the pipeline to the response with the refID ExplainRefID.
+	Explain bool
+	// ExplainHidden includes the hidden queries in the explanation.
+	ExplainHidden bool
+	OrgId         int64
+	Queries       []Query
+	User          identity.Requester
 }
 
 // Query is like plugins.DataSubQuery, but with a a time range, and only the UID
@@ -86,26 +91,47 @@ func (s *Service) TransformData(ctx context.Context, now time.Time, req *Request
 		span.End()
 	}()
 
-	// Build the pipeline from the request, checking for ordering issues (e.g. loops)
-	// and parsing graph nodes from the queries.
-	pipeline, err := s.BuildPipeline(req)
-	if err != nil {
-		return nil, err
+	if req.Explain {
+		for _, q := range req.Queries {
+			if q.RefID == ExplainRefID {
+				return nil, fmt.Errorf("refId %s is reserved for the explanation of the pipeline", ExplainRefID)
+			}
+		}
 	}
 
-	// Execute the pipeline
-	responses, err := s.ExecutePipeline(ctx, now, pipeline)
+	// Get which queries have the Hide property so they those queries' results
+	// can be excluded from the response.
+	hidden, err := hiddenRefIDs(req.Queries)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get which queries have the Hide property so they those queries' results
-	// can be excluded from the response.
-	hidden, err := hiddenRefIDs(req.Queries)
+	// Build the pipeline from the request, checking for ordering issues (e.g. loops)
+	// and parsing graph nodes from the queries.
+	pipeline, err := s.BuildPipeline(req)
 	if err != nil {
 		return nil, err
 	}
 
+	// Execute the pipeline
+	var responses *backend.QueryDataResponse
+	if req.Explain {
+		var explanation *PipelineExplanation
+		responses, explanation, err = s.ExplainPipeline(ctx, now, pipeline)
+		if err != nil {
+			return nil, err
+		}
+		if !req.ExplainHidden {
+			explanation.removeNodes(hidden)
+		}
+		responses.Responses[ExplainRefID] = backend.DataResponse{Frames: data.Frames{explanation.Frame()}}
+	} else {
+		responses, err = s.ExecutePipeline(ctx, now, pipeline)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if len(hidden) != 0 {
 		filteredRes := backend.NewQueryDataResponse()
 		for refID, res := range responses.Responses {
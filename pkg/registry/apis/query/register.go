@@ -30,6 +30,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 var _ builder.APIGroupBuilder = (*QueryAPIBuilder)(nil)
@@ -51,13 +52,14 @@ type QueryAPIBuilder struct {
 }
 
 func NewQueryAPIBuilder(features featuremgmt.FeatureToggles,
+	cfg *setting.Cfg,
 	client DataSourceClientSupplier,
 	registry query.DataSourceApiServerRegistry,
 	legacy service.LegacyDataSourceLookup,
 	registerer prometheus.Registerer,
 	tracer tracing.Tracer,
 ) (*QueryAPIBuilder, error) {
-	reader := expr.NewExpressionQueryReader(features)
+	reader := expr.NewExpressionQueryReader(features, cfg)
 
 	// Include well typed query definitions
 	var queryTypes *query.QueryTypeDefinitionList
@@ -93,6 +95,7 @@ func NewQueryAPIBuilder(features featuremgmt.FeatureToggles,
 }
 
 func RegisterAPIService(features featuremgmt.FeatureToggles,
+	cfg *setting.Cfg,
 	apiregistration builder.APIRegistrar,
 	dataSourcesService datasources.DataSourceService,
 	pluginStore pluginstore.Store,
@@ -110,6 +113,7 @@ func RegisterAPIService(features featuremgmt.FeatureToggles,
 
 	builder, err := NewQueryAPIBuilder(
 		features,
+		cfg,
 		&CommonDataSourceClientSupplier{
 			Client: client.NewQueryClientForPluginClient(pluginClient, pCtxProvider),
 		},
@@ -84,6 +84,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/librarypanels"
 	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/live/pushhttp"
+	"github.com/grafana/grafana/pkg/services/live/pushmqtt"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/login/authinfoimpl"
 	"github.com/grafana/grafana/pkg/services/loginattempt"
@@ -233,6 +234,7 @@ var wireBasicSet = wire.NewSet(
 	store.ProvideSystemUsersService,
 	live.ProvideService,
 	pushhttp.ProvideService,
+	pushmqtt.ProvideService,
 	contexthandler.ProvideService,
 	ldapservice.ProvideService,
 	wire.Bind(new(ldapservice.LDAP), new(*ldapservice.LDAPImpl)),
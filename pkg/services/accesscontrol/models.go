@@ -475,6 +475,10 @@ const (
 	ActionFeatureManagementRead  = "featuremgmt.read"
 	ActionFeatureManagementWrite = "featuremgmt.write"
 
+	// Live pipeline actions
+	ActionLivePipelineRead  = "live.pipeline:read"
+	ActionLivePipelineWrite = "live.pipeline:write"
+
 	// Library Panel actions
 	ActionLibraryPanelsCreate = "library.panels:create"
 	ActionLibraryPanelsRead   = "library.panels:read"
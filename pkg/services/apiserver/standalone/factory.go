@@ -78,6 +78,7 @@ func (p *DummyAPIFactory) MakeAPIServer(_ context.Context, tracer tracing.Tracer
 	case "query.grafana.app":
 		return query.NewQueryAPIBuilder(
 			featuremgmt.WithFeatures(),
+			&setting.Cfg{},
 			&query.CommonDataSourceClientSupplier{
 				Client: client.NewTestDataClient(),
 			},
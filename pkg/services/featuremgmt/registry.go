@@ -1378,6 +1378,13 @@ var (
 			Owner:        awsDatasourcesSquad,
 			FrontendOnly: true,
 		},
+		{
+			Name:            "livePipeline",
+			Description:     "Enables the Grafana Live pipeline with channel rules managed over the API",
+			Stage:           FeatureStageExperimental,
+			Owner:           grafanaAppPlatformSquad,
+			RequiresRestart: true,
+		},
 	}
 )
 
@@ -738,4 +738,8 @@ const (
 	// FlagCloudwatchMetricInsightsCrossAccount
 	// Enables cross account observability for Cloudwatch Metric Insights
 	FlagCloudwatchMetricInsightsCrossAccount = "cloudwatchMetricInsightsCrossAccount"
+
+	// FlagLivePipeline
+	// Enables the Grafana Live pipeline with channel rules managed over the API
+	FlagLivePipeline = "livePipeline"
 )
@@ -5,12 +5,27 @@ import (
 	"fmt"
 
 	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/csv"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/otlp"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/prometheus"
 	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
 )
 
+// Input formats of pushed data.
+const (
+	InputFormatInflux     = "influx"
+	InputFormatPrometheus = "prometheus"
+	InputFormatOTLP       = "otlp"
+	InputFormatCSV        = "csv"
+)
+
 type Converter struct {
-	telegrafConverterWide         *telegraf.Converter
-	telegrafConverterLabelsColumn *telegraf.Converter
+	telegrafConverterWide           *telegraf.Converter
+	telegrafConverterLabelsColumn   *telegraf.Converter
+	prometheusConverterWide         *prometheus.Converter
+	prometheusConverterLabelsColumn *prometheus.Converter
+	otlpConverterWide               *otlp.Converter
+	otlpConverterLabelsColumn       *otlp.Converter
 }
 
 func NewConverter() *Converter {
@@ -22,22 +37,75 @@ func NewConverter() *Converter {
 			telegraf.WithUseLabelsColumn(true),
 			telegraf.WithFloat64Numbers(true),
 		),
+		prometheusConverterWide: prometheus.NewConverter(),
+		prometheusConverterLabelsColumn: prometheus.NewConverter(
+			prometheus.WithUseLabelsColumn(true),
+		),
+		otlpConverterWide: otlp.NewConverter(),
+		otlpConverterLabelsColumn: otlp.NewConverter(
+			otlp.WithUseLabelsColumn(true),
+		),
 	}
 }
 
-var ErrUnsupportedFrameFormat = errors.New("unsupported frame format")
+var (
+	ErrUnsupportedFrameFormat = errors.New("unsupported frame format")
+	ErrUnsupportedInputFormat = errors.New("unsupported input format")
+)
+
+// Input describes the format of the data to convert.
+type Input struct {
+	// Format is one of the input formats, Influx line protocol if empty.
+	Format string
+	// FrameFormat is either "wide" or "labels_column".
+	FrameFormat string
+	// LabelColumns are the columns of CSV input which values are used as labels.
+	LabelColumns []string
+}
 
+// Convert converts Influx line protocol to frames.
 func (c *Converter) Convert(data []byte, frameFormat string) ([]telemetry.FrameWrapper, error) {
-	var converter telemetry.Converter
-	switch frameFormat {
+	return c.ConvertInput(data, Input{Format: InputFormatInflux, FrameFormat: frameFormat})
+}
+
+// ConvertInput converts data in any of the input formats to frames.
+func (c *Converter) ConvertInput(data []byte, input Input) ([]telemetry.FrameWrapper, error) {
+	var useLabelsColumn bool
+	switch input.FrameFormat {
 	case "wide":
-		converter = c.telegrafConverterWide
 	case "labels_column":
-		converter = c.telegrafConverterLabelsColumn
+		useLabelsColumn = true
 	default:
 		return nil, ErrUnsupportedFrameFormat
 	}
 
+	var converter telemetry.Converter
+	switch input.Format {
+	case "", InputFormatInflux:
+		converter = c.telegrafConverterWide
+		if useLabelsColumn {
+			converter = c.telegrafConverterLabelsColumn
+		}
+	case InputFormatPrometheus:
+		converter = c.prometheusConverterWide
+		if useLabelsColumn {
+			converter = c.prometheusConverterLabelsColumn
+		}
+	case InputFormatOTLP:
+		converter = c.otlpConverterWide
+		if useLabelsColumn {
+			converter = c.otlpConverterLabelsColumn
+		}
+	case InputFormatCSV:
+		// Label columns differ between inputs, CSV converter is cheap to create.
+		converter = csv.NewConverter(
+			csv.WithUseLabelsColumn(useLabelsColumn),
+			csv.WithLabelColumns(input.LabelColumns...),
+		)
+	default:
+		return nil, ErrUnsupportedInputFormat
+	}
+
 	metricFrames, err := converter.Convert(data)
 	if err != nil {
 		return nil, fmt.Errorf("error converting metrics: %w", err)
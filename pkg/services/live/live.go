@@ -148,22 +148,47 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		}
 	}
 
+	historyCfg := managedstream.HistoryConfig{
+		MaxAge:  g.Cfg.LiveManagedStreamHistoryMaxAge,
+		MaxRows: g.Cfg.LiveManagedStreamHistoryMaxRows,
+	}
 	if redisClient != nil {
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
-			managedstream.NewRedisFrameCache(redisClient),
+			managedstream.NewRedisFrameCache(redisClient, historyCfg),
 		)
 	} else {
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
-			managedstream.NewMemoryFrameCache(),
+			managedstream.NewMemoryFrameCache(historyCfg),
 		)
 	}
 
 	g.ManagedStreamRunner = managedStreamRunner
 
+	if g.Features.IsEnabledGlobally(featuremgmt.FlagLivePipeline) {
+		storage := pipeline.NewSQLStorage(sqlStore, secretsService)
+		g.pipelineStorage = storage
+		g.pipelineRuleCache = pipeline.NewCacheSegmentedTree(&pipeline.StorageRuleBuilder{
+			Node:                 node,
+			ManagedStream:        g.ManagedStreamRunner,
+			FrameStorage:         pipeline.NewFrameStorage(),
+			AggregateStorage:     pipeline.NewAggregateStorage(),
+			Storage:              storage,
+			ChannelHandlerGetter: g,
+			SecretsService:       secretsService,
+		})
+		g.Pipeline, err = pipeline.New(g.pipelineRuleCache)
+		if err != nil {
+			return nil, fmt.Errorf("error creating pipeline: %w", err)
+		}
+		// Rules are cached by every node, changes made over the API on one node
+		// are propagated to others with notifications.
+		node.OnNotification(g.handlePipelineNotification)
+	}
+
 	g.contextGetter = liveplugin.NewContextGetter(g.PluginContextProvider, g.DataSourceCache)
 	pipelinedChannelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, g.Pipeline)
 	numLocalSubscribersGetter := liveplugin.NewNumLocalSubscribersGetter(node)
@@ -318,9 +343,13 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 
 	g.RouteRegister.Group("/api/live", func(group routing.RouteRegister) {
 		group.Get("/push/:streamId", g.pushWebsocketHandler)
-		group.Get("/pipeline/push/*", g.pushPipelineWebsocketHandler)
 	}, middleware.ReqOrgAdmin, requestmeta.SetSLOGroup(requestmeta.SLOGroupNone))
 
+	authorize := accesscontrol.Middleware(accessControl)
+	g.RouteRegister.Group("/api/live", func(group routing.RouteRegister) {
+		group.Get("/pipeline/push/*", g.pushPipelineWebsocketHandler)
+	}, middleware.ReqSignedIn, authorize(accesscontrol.EvalPermission(accesscontrol.ActionLivePipelineWrite)), requestmeta.SetSLOGroup(requestmeta.SLOGroupNone))
+
 	g.registerUsageMetrics()
 
 	return g, nil
@@ -403,6 +432,7 @@ type GrafanaLive struct {
 	ManagedStreamRunner *managedstream.Runner
 	Pipeline            *pipeline.Pipeline
 	pipelineStorage     pipeline.Storage
+	pipelineRuleCache   *pipeline.CacheSegmentedTree
 
 	contextGetter    *liveplugin.ContextGetter
 	runStreamManager *runstream.Manager
@@ -1075,6 +1105,7 @@ type ConvertDryRunResponse struct {
 
 type DryRunRuleStorage struct {
 	ChannelRules []pipeline.ChannelRule
+	WriteConfigs []pipeline.WriteConfig
 }
 
 func (s *DryRunRuleStorage) GetWriteConfig(_ context.Context, _ int64, _ pipeline.WriteConfigGetCmd) (pipeline.WriteConfig, bool, error) {
@@ -1105,8 +1136,12 @@ func (s *DryRunRuleStorage) DeleteChannelRule(_ context.Context, _ int64, _ pipe
 	return errors.New("not implemented by dry run rule storage")
 }
 
+func (s *DryRunRuleStorage) ListChannelRuleVersions(_ context.Context, _ int64, _ pipeline.ChannelRuleVersionsCmd) ([]pipeline.ChannelRuleVersion, error) {
+	return nil, errors.New("not implemented by dry run rule storage")
+}
+
 func (s *DryRunRuleStorage) ListWriteConfigs(_ context.Context, _ int64) ([]pipeline.WriteConfig, error) {
-	return nil, nil
+	return s.WriteConfigs, nil
 }
 
 func (s *DryRunRuleStorage) ListChannelRules(_ context.Context, _ int64) ([]pipeline.ChannelRule, error) {
@@ -1169,10 +1204,15 @@ func (g *GrafanaLive) HandleChannelRulesPostHTTP(c *contextmodel.ReqContext) res
 	if err != nil {
 		return response.Error(http.StatusBadRequest, "Error decoding channel rule", err)
 	}
+	if err := g.checkChannelRuleBuild(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd.Pattern, cmd.Settings); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	cmd.UserID = c.SignedInUser.UserID
 	rule, err := g.pipelineStorage.CreateChannelRule(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to create channel rule", err)
+		return pipelineStorageErrorResponse("Failed to create channel rule", err)
 	}
+	g.notifyPipelineRulesChanged(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"rule": rule,
 	})
@@ -1192,10 +1232,15 @@ func (g *GrafanaLive) HandleChannelRulesPutHTTP(c *contextmodel.ReqContext) resp
 	if cmd.Pattern == "" {
 		return response.Error(http.StatusBadRequest, "Rule pattern required", nil)
 	}
+	if err := g.checkChannelRuleBuild(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd.Pattern, cmd.Settings); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	cmd.UserID = c.SignedInUser.UserID
 	rule, err := g.pipelineStorage.UpdateChannelRule(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to update channel rule", err)
+		return pipelineStorageErrorResponse("Failed to update channel rule", err)
 	}
+	g.notifyPipelineRulesChanged(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"rule": rule,
 	})
@@ -1215,13 +1260,33 @@ func (g *GrafanaLive) HandleChannelRulesDeleteHTTP(c *contextmodel.ReqContext) r
 	if cmd.Pattern == "" {
 		return response.Error(http.StatusBadRequest, "Rule pattern required", nil)
 	}
+	cmd.UserID = c.SignedInUser.UserID
 	err = g.pipelineStorage.DeleteChannelRule(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to delete channel rule", err)
+		return pipelineStorageErrorResponse("Failed to delete channel rule", err)
 	}
+	g.notifyPipelineRulesChanged(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{})
 }
 
+// HandleChannelRuleVersionsListHTTP returns the history of the channel rule with
+// the pattern from the query, the latest version first.
+func (g *GrafanaLive) HandleChannelRuleVersionsListHTTP(c *contextmodel.ReqContext) response.Response {
+	pattern := c.Query("pattern")
+	if pattern == "" {
+		return response.Error(http.StatusBadRequest, "Rule pattern required", nil)
+	}
+	versions, err := g.pipelineStorage.ListChannelRuleVersions(c.Req.Context(), c.SignedInUser.GetOrgID(), pipeline.ChannelRuleVersionsCmd{
+		Pattern: pattern,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get channel rule versions", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{
+		"versions": versions,
+	})
+}
+
 // HandlePipelineEntitiesListHTTP ...
 func (g *GrafanaLive) HandlePipelineEntitiesListHTTP(_ *contextmodel.ReqContext) response.Response {
 	return response.JSON(http.StatusOK, util.DynMap{
@@ -1261,8 +1326,9 @@ func (g *GrafanaLive) HandleWriteConfigsPostHTTP(c *contextmodel.ReqContext) res
 	}
 	result, err := g.pipelineStorage.CreateWriteConfig(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to create write config", err)
+		return pipelineStorageErrorResponse("Failed to create write config", err)
 	}
+	g.notifyPipelineRulesChanged(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"writeConfig": pipeline.WriteConfigToDto(result),
 	})
@@ -1305,8 +1371,9 @@ func (g *GrafanaLive) HandleWriteConfigsPutHTTP(c *contextmodel.ReqContext) resp
 	}
 	result, err := g.pipelineStorage.UpdateWriteConfig(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to update write config", err)
+		return pipelineStorageErrorResponse("Failed to update write config", err)
 	}
+	g.notifyPipelineRulesChanged(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"writeConfig": pipeline.WriteConfigToDto(result),
 	})
@@ -1328,11 +1395,92 @@ func (g *GrafanaLive) HandleWriteConfigsDeleteHTTP(c *contextmodel.ReqContext) r
 	}
 	err = g.pipelineStorage.DeleteWriteConfig(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to delete write config", err)
+		return pipelineStorageErrorResponse("Failed to delete write config", err)
 	}
+	g.notifyPipelineRulesChanged(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{})
 }
 
+// pipelineStorageErrorResponse returns an error response with a status matching
+// the pipeline storage error.
+func pipelineStorageErrorResponse(message string, err error) response.Response {
+	switch {
+	case errors.Is(err, pipeline.ErrChannelRuleNotFound), errors.Is(err, pipeline.ErrWriteConfigNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, pipeline.ErrInvalidChannelRule), errors.Is(err, pipeline.ErrInvalidWriteConfig):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, pipeline.ErrChannelRuleExists), errors.Is(err, pipeline.ErrWriteConfigExists), errors.Is(err, pipeline.ErrWriteConfigInUse),
+		errors.Is(err, pipeline.ErrVersionConflict):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
+
+// checkChannelRuleBuild checks the entities of a channel rule can be built with
+// their settings and the write configs of the organization, so that invalid
+// rules are rejected before they are saved.
+func (g *GrafanaLive) checkChannelRuleBuild(ctx context.Context, orgID int64, pattern string, settings pipeline.ChannelRuleSettings) error {
+	writeConfigs, err := g.pipelineStorage.ListWriteConfigs(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("can't list write configs: %w", err)
+	}
+	builder := &pipeline.StorageRuleBuilder{
+		Node:          g.node,
+		ManagedStream: g.ManagedStreamRunner,
+		FrameStorage:  pipeline.NewFrameStorage(),
+		Storage: &DryRunRuleStorage{
+			ChannelRules: []pipeline.ChannelRule{{OrgId: orgID, Pattern: pattern, Settings: settings}},
+			WriteConfigs: writeConfigs,
+		},
+		ChannelHandlerGetter: g,
+		SecretsService:       g.SecretsService,
+	}
+	if _, err := builder.BuildRules(ctx, orgID); err != nil {
+		return fmt.Errorf("%w: %s", pipeline.ErrInvalidChannelRule, err)
+	}
+	return nil
+}
+
+const pipelineRulesChangedOp = "pipeline_rules_changed"
+
+type pipelineRulesChangedNotification struct {
+	OrgID int64 `json:"orgId"`
+}
+
+// notifyPipelineRulesChanged makes all nodes, including the current one, rebuild
+// cached pipeline rules of the organization. The change is saved already, so a failed
+// notification does not fail the request: the current node rebuilds its rules directly,
+// and other nodes apply the change when they refresh cached rules periodically.
+func (g *GrafanaLive) notifyPipelineRulesChanged(orgID int64) {
+	data, err := json.Marshal(pipelineRulesChangedNotification{OrgID: orgID})
+	if err == nil {
+		err = g.node.Notify(pipelineRulesChangedOp, data, "")
+	}
+	if err == nil {
+		return
+	}
+	logger.Warn("Error notifying nodes about pipeline rules change, other nodes apply it on the next refresh of their rules", "error", err, "orgId", orgID)
+	if err := g.pipelineRuleCache.Invalidate(orgID); err != nil {
+		logger.Error("Error rebuilding pipeline rules", "error", err, "orgId", orgID)
+	}
+}
+
+func (g *GrafanaLive) handlePipelineNotification(e centrifuge.NotificationEvent) {
+	if e.Op != pipelineRulesChangedOp {
+		return
+	}
+	var n pipelineRulesChangedNotification
+	if err := json.Unmarshal(e.Data, &n); err != nil {
+		logger.Error("Error decoding pipeline rules notification", "error", err, "fromNode", e.FromNodeID)
+		return
+	}
+	logger.Debug("Rebuilding pipeline rules", "orgId", n.OrgID, "fromNode", e.FromNodeID)
+	// Previous rules are kept if the new ones can't be built.
+	if err := g.pipelineRuleCache.Invalidate(n.OrgID); err != nil {
+		logger.Error("Error rebuilding pipeline rules", "error", err, "orgId", n.OrgID)
+	}
+}
+
 // Write to the standard log15 logger
 func handleLog(msg centrifuge.LogEntry) {
 	arr := make([]interface{}, 0)
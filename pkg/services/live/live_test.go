@@ -17,6 +17,9 @@ import (
 	"github.com/grafana/grafana/pkg/services/authz/zanzana"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tests/testsuite"
 )
@@ -43,6 +46,39 @@ func Test_provideLiveService_RedisUnavailable(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func Test_provideLiveService_PipelineRulesChanged(t *testing.T) {
+	features := featuremgmt.WithFeatures(featuremgmt.FlagLivePipeline)
+	g, err := ProvideService(nil, setting.NewCfg(),
+		routing.NewRouteRegister(),
+		nil, nil, nil, nil,
+		db.InitTestDB(t),
+		secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore()),
+		&usagestats.UsageStatsMock{T: t},
+		nil,
+		features, acimpl.ProvideAccessControl(features, zanzana.NewNoopClient()), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil)
+	require.NoError(t, err)
+	require.NotNil(t, g.Pipeline)
+
+	_, ok, err := g.Pipeline.Get(1, "stream/test/cpu")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = g.pipelineStorage.CreateChannelRule(context.Background(), 1, pipeline.ChannelRuleCreateCmd{
+		Pattern: "stream/test/:metric",
+		Settings: pipeline.ChannelRuleSettings{
+			Converter: &pipeline.ConverterConfig{Type: pipeline.ConverterTypeJsonAuto},
+		},
+	})
+	require.NoError(t, err)
+
+	// Rules are rebuilt once nodes are notified about the change.
+	g.notifyPipelineRulesChanged(1)
+	rule, ok, err := g.Pipeline.Get(1, "stream/test/cpu")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "stream/test/:metric", rule.Pattern)
+}
+
 func Test_runConcurrentlyIfNeeded_Concurrent(t *testing.T) {
 	doneCh := make(chan struct{})
 	f := func() {
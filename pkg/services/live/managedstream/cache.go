@@ -13,6 +13,8 @@ type FrameCache interface {
 	GetActiveChannels(orgID int64) (map[string]json.RawMessage, error)
 	// GetFrame returns full JSON frame for a channel in org.
 	GetFrame(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error)
+	// GetHistory returns full JSON frame with the rows of the history of a channel in org.
+	GetHistory(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error)
 	// Update updates frame cache and returns true if schema changed.
 	Update(ctx context.Context, orgID int64, channel string, frameJson data.FrameJSONCache) (bool, error)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
@@ -12,16 +13,23 @@ import (
 
 // MemoryFrameCache ...
 type MemoryFrameCache struct {
-	mu     sync.RWMutex
-	frames map[int64]map[string]data.FrameJSONCache
-	log    log.Logger
+	mu        sync.RWMutex
+	frames    map[int64]map[string]data.FrameJSONCache
+	history   map[int64]map[string][]historyEntry
+	lastEvict time.Time
+	cfg       HistoryConfig
+	log       log.Logger
+	now       func() time.Time
 }
 
 // NewMemoryFrameCache ...
-func NewMemoryFrameCache() *MemoryFrameCache {
+func NewMemoryFrameCache(cfg HistoryConfig) *MemoryFrameCache {
 	return &MemoryFrameCache{
-		frames: map[int64]map[string]data.FrameJSONCache{},
-		log:    log.New("live.memoryframecache"),
+		frames:  map[int64]map[string]data.FrameJSONCache{},
+		history: map[int64]map[string][]historyEntry{},
+		cfg:     cfg,
+		log:     log.New("live.memoryframecache"),
+		now:     time.Now,
 	}
 }
 
@@ -52,6 +60,16 @@ func (c *MemoryFrameCache) GetFrame(ctx context.Context, orgID int64, channel st
 	return raw, ok, nil
 }
 
+func (c *MemoryFrameCache) GetHistory(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error) {
+	c.mu.RLock()
+	entries := c.history[orgID][channel]
+	c.mu.RUnlock()
+	if len(entries) == 0 {
+		return nil, false, nil
+	}
+	return mergeHistory(c.cfg, entries, c.now())
+}
+
 func (c *MemoryFrameCache) Update(ctx context.Context, orgID int64, channel string, jsonFrame data.FrameJSONCache) (bool, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -61,6 +79,9 @@ func (c *MemoryFrameCache) Update(ctx context.Context, orgID int64, channel stri
 	cachedJsonFrame, exists := c.frames[orgID][channel]
 	schemaUpdated := !exists || !cachedJsonFrame.SameSchema(&jsonFrame)
 	c.frames[orgID][channel] = jsonFrame
+	if c.cfg.Enabled() {
+		c.updateHistory(orgID, channel, jsonFrame, schemaUpdated)
+	}
 	c.log.Debug("Cache update",
 		"orgId", orgID,
 		"channel", channel,
@@ -68,3 +89,50 @@ func (c *MemoryFrameCache) Update(ctx context.Context, orgID int64, channel stri
 	)
 	return schemaUpdated, nil
 }
+
+// updateHistory appends the frame to the history of the channel and removes the frames
+// that are expired or no longer fit into it. Non-empty frames have at least one row, so
+// at most MaxRows frames are kept, and the rows are trimmed exactly on replay.
+// Must be called with the lock held.
+func (c *MemoryFrameCache) updateHistory(orgID int64, channel string, jsonFrame data.FrameJSONCache, schemaUpdated bool) {
+	now := c.now()
+	c.evictIdleHistory(now)
+	if _, ok := c.history[orgID]; !ok {
+		c.history[orgID] = map[string][]historyEntry{}
+	}
+	entries := c.history[orgID][channel]
+	if schemaUpdated {
+		// Frames with the previous schema cannot be merged with the new ones.
+		entries = nil
+	}
+	entries = append(entries, historyEntry{Time: now.UnixMilli(), Frame: jsonFrame.Bytes(data.IncludeAll)})
+	start := 0
+	for start < len(entries) && c.cfg.expired(entries[start], now) {
+		start++
+	}
+	if len(entries)-start > c.cfg.MaxRows {
+		start = len(entries) - c.cfg.MaxRows
+	}
+	c.history[orgID][channel] = entries[start:]
+}
+
+// evictIdleHistory drops the history of channels which received no frames for the ttl of the history,
+// like the history keys expire in Redis. Channels are checked at most once per historyEvictInterval.
+// Must be called with the lock held.
+func (c *MemoryFrameCache) evictIdleHistory(now time.Time) {
+	if now.Sub(c.lastEvict) < historyEvictInterval {
+		return
+	}
+	c.lastEvict = now
+	ttl := c.cfg.ttl()
+	for orgID, channels := range c.history {
+		for channel, entries := range channels {
+			if len(entries) == 0 || now.Sub(time.UnixMilli(entries[len(entries)-1].Time)) > ttl {
+				delete(channels, channel)
+			}
+		}
+		if len(channels) == 0 {
+			delete(c.history, orgID)
+		}
+	}
+}
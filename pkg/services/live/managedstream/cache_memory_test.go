@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
@@ -62,8 +63,124 @@ func testFrameCache(t *testing.T, c FrameCache) {
 	require.NotEqual(t, string(channels["test"]), string(schema))
 }
 
+func pushHistoryFrame(t *testing.T, c FrameCache, channel string, values ...float64) {
+	t.Helper()
+	frame := data.NewFrame("hello", data.NewField("value", nil, values))
+	frameJsonCache, err := data.FrameToJSONCache(frame)
+	require.NoError(t, err)
+	_, err = c.Update(context.Background(), 1, channel, frameJsonCache)
+	require.NoError(t, err)
+}
+
+func requireHistory(t *testing.T, c FrameCache, channel string, expected ...float64) {
+	t.Helper()
+	frameJSON, ok, err := c.GetHistory(context.Background(), 1, channel)
+	require.NoError(t, err)
+	if len(expected) == 0 {
+		require.False(t, ok)
+		return
+	}
+	require.True(t, ok)
+
+	var f data.Frame
+	err = json.Unmarshal(frameJSON, &f)
+	require.NoError(t, err)
+	require.Len(t, f.Fields, 1)
+	values := make([]float64, 0, f.Rows())
+	for i := 0; i < f.Rows(); i++ {
+		values = append(values, f.Fields[0].At(i).(float64))
+	}
+	require.Equal(t, expected, values)
+}
+
+// testFrameCacheHistory expects a cache that keeps at most 5 rows.
+func testFrameCacheHistory(t *testing.T, c FrameCache) {
+	// Channel without frames has no history.
+	requireHistory(t, c, "history")
+
+	// Frames are merged.
+	pushHistoryFrame(t, c, "history", 1)
+	pushHistoryFrame(t, c, "history", 2, 3)
+	requireHistory(t, c, "history", 1, 2, 3)
+
+	// Oldest rows are dropped when history is full.
+	pushHistoryFrame(t, c, "history", 4, 5)
+	pushHistoryFrame(t, c, "history", 6)
+	requireHistory(t, c, "history", 2, 3, 4, 5, 6)
+
+	// History is reset when schema changes.
+	frame := data.NewFrame("hello", data.NewField("other", nil, []float64{7}))
+	frameJsonCache, err := data.FrameToJSONCache(frame)
+	require.NoError(t, err)
+	_, err = c.Update(context.Background(), 1, "history", frameJsonCache)
+	require.NoError(t, err)
+	frameJSON, ok, err := c.GetHistory(context.Background(), 1, "history")
+	require.NoError(t, err)
+	require.True(t, ok)
+	var f data.Frame
+	require.NoError(t, json.Unmarshal(frameJSON, &f))
+	require.Equal(t, "other", f.Fields[0].Name)
+	require.Equal(t, 1, f.Rows())
+
+	// Other orgs do not see the history.
+	_, ok, err = c.GetHistory(context.Background(), 2, "history")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
 func TestMemoryFrameCache(t *testing.T) {
-	c := NewMemoryFrameCache()
+	c := NewMemoryFrameCache(HistoryConfig{})
 	require.NotNil(t, c)
 	testFrameCache(t, c)
 }
+
+func TestMemoryFrameCacheHistory(t *testing.T) {
+	t.Run("history is kept", func(t *testing.T) {
+		c := NewMemoryFrameCache(HistoryConfig{MaxRows: 5})
+		testFrameCacheHistory(t, c)
+	})
+
+	t.Run("history is not kept when disabled", func(t *testing.T) {
+		c := NewMemoryFrameCache(HistoryConfig{})
+		pushHistoryFrame(t, c, "history", 1)
+		requireHistory(t, c, "history")
+	})
+
+	t.Run("expired frames are not replayed", func(t *testing.T) {
+		c := NewMemoryFrameCache(HistoryConfig{MaxRows: 5, MaxAge: time.Minute})
+		now := time.Now()
+		c.now = func() time.Time { return now }
+
+		pushHistoryFrame(t, c, "history", 1)
+		now = now.Add(30 * time.Second)
+		pushHistoryFrame(t, c, "history", 2)
+		requireHistory(t, c, "history", 1, 2)
+
+		now = now.Add(45 * time.Second)
+		requireHistory(t, c, "history", 2)
+
+		now = now.Add(time.Minute)
+		requireHistory(t, c, "history")
+		frameJSON, ok, err := c.GetFrame(context.Background(), 1, "history")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.NotEmpty(t, frameJSON)
+	})
+
+	t.Run("history of idle channels is evicted", func(t *testing.T) {
+		c := NewMemoryFrameCache(HistoryConfig{MaxRows: 5, MaxAge: time.Minute})
+		now := time.Now()
+		c.now = func() time.Time { return now }
+
+		pushHistoryFrame(t, c, "idle", 1)
+		pushHistoryFrame(t, c, "active", 1)
+		now = now.Add(30 * time.Second)
+		pushHistoryFrame(t, c, "active", 2)
+		require.Contains(t, c.history[1], "idle")
+
+		now = now.Add(2 * time.Minute)
+		pushHistoryFrame(t, c, "active", 3)
+		require.NotContains(t, c.history[1], "idle")
+		requireHistory(t, c, "active", 3)
+	})
+}
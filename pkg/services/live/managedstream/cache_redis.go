@@ -18,13 +18,17 @@ type RedisFrameCache struct {
 	mu          sync.RWMutex
 	redisClient *redis.Client
 	frames      map[int64]map[string]data.FrameJSONCache
+	cfg         HistoryConfig
+	now         func() time.Time
 }
 
 // NewRedisFrameCache ...
-func NewRedisFrameCache(redisClient *redis.Client) *RedisFrameCache {
+func NewRedisFrameCache(redisClient *redis.Client, cfg HistoryConfig) *RedisFrameCache {
 	return &RedisFrameCache{
 		frames:      map[int64]map[string]data.FrameJSONCache{},
 		redisClient: redisClient,
+		cfg:         cfg,
+		now:         time.Now,
 	}
 }
 
@@ -55,10 +59,33 @@ func (c *RedisFrameCache) GetFrame(ctx context.Context, orgID int64, channel str
 	return json.RawMessage(result["frame"]), true, nil
 }
 
+func (c *RedisFrameCache) GetHistory(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error) {
+	key := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+	result, err := c.redisClient.LRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if len(result) == 0 {
+		return nil, false, nil
+	}
+	entries := make([]historyEntry, 0, len(result))
+	for _, item := range result {
+		var entry historyEntry
+		if err := json.Unmarshal([]byte(item), &entry); err != nil {
+			return nil, false, err
+		}
+		entries = append(entries, entry)
+	}
+	return mergeHistory(c.cfg, entries, c.now())
+}
+
 const (
 	frameCacheTTL = 7 * 24 * time.Hour
 )
 
+// updateMaxRetries is the number of attempts to update a channel that is updated concurrently.
+const updateMaxRetries = 10
+
 func (c *RedisFrameCache) Update(ctx context.Context, orgID int64, channel string, jsonFrame data.FrameJSONCache) (bool, error) {
 	c.mu.Lock()
 	if _, ok := c.frames[orgID]; !ok {
@@ -70,43 +97,71 @@ func (c *RedisFrameCache) Update(ctx context.Context, orgID int64, channel strin
 	stringSchema := string(jsonFrame.Bytes(data.IncludeSchemaOnly))
 
 	key := getCacheKey(orgchannel.PrependOrgID(orgID, channel))
-
-	pipe := c.redisClient.TxPipeline()
-	defer func() { _ = pipe.Close() }()
-
-	pipe.HGetAll(ctx, key)
-	pipe.HMSet(ctx, key, map[string]string{
-		"schema": stringSchema,
-		"frame":  string(jsonFrame.Bytes(data.IncludeAll)),
-	})
-	pipe.Expire(ctx, key, frameCacheTTL)
-
-	replies, err := pipe.Exec(ctx)
-	if err != nil {
-		return false, err
-	}
-	if len(replies) == 0 {
-		return false, errors.New("no replies in response")
+	historyKey := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+
+	var entry []byte
+	if c.cfg.Enabled() {
+		var err error
+		entry, err = json.Marshal(historyEntry{
+			Time:  c.now().UnixMilli(),
+			Frame: jsonFrame.Bytes(data.IncludeAll),
+		})
+		if err != nil {
+			return false, err
+		}
 	}
-	reply := replies[0]
 
-	if reply.Err() != nil {
-		return false, err
+	var schemaUpdated bool
+	// The schema is read before the frame is written, so that the history can be trimmed in the same
+	// transaction. The transaction fails and is retried if the frame is updated concurrently.
+	update := func(tx *redis.Tx) error {
+		schema, err := tx.HGet(ctx, key, "schema").Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		schemaUpdated = errors.Is(err, redis.Nil) || schema != stringSchema
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HMSet(ctx, key, map[string]string{
+				"schema": stringSchema,
+				"frame":  string(jsonFrame.Bytes(data.IncludeAll)),
+			})
+			pipe.Expire(ctx, key, frameCacheTTL)
+
+			if entry != nil {
+				pipe.RPush(ctx, historyKey, entry)
+				if schemaUpdated {
+					// Frames with the previous schema cannot be merged with the new ones.
+					pipe.LTrim(ctx, historyKey, -1, -1)
+				} else {
+					// Non-empty frames have at least one row, so at most MaxRows frames are kept,
+					// and the rows are trimmed exactly on replay.
+					pipe.LTrim(ctx, historyKey, -int64(c.cfg.MaxRows), -1)
+				}
+				pipe.Expire(ctx, historyKey, c.cfg.ttl())
+			}
+			return nil
+		})
+		return err
 	}
 
-	if mapReply, ok := reply.(*redis.StringStringMapCmd); ok {
-		result, err := mapReply.Result()
+	for i := 0; i < updateMaxRetries; i++ {
+		err := c.redisClient.Watch(ctx, update, key)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
 		if err != nil {
 			return false, err
 		}
-		if len(result) == 0 {
-			return true, nil
-		}
-		return result["schema"] != stringSchema, nil
+		return schemaUpdated, nil
 	}
-	return true, nil
+	return false, errors.New("failed to update frame cache: too many concurrent updates")
 }
 
 func getCacheKey(channelID string) string {
 	return "gf_live.managed_stream." + channelID
 }
+
+func getHistoryKey(channelID string) string {
+	return "gf_live.managed_stream_history." + channelID
+}
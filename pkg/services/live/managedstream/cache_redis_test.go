@@ -1,11 +1,14 @@
 package managedstream
 
 import (
+	"context"
 	"os"
 	"testing"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
 )
 
 func TestIntegrationRedisCacheStorage(t *testing.T) {
@@ -30,7 +33,12 @@ func TestIntegrationRedisCacheStorage(t *testing.T) {
 		Addr: addr,
 		DB:   db,
 	})
-	c := NewRedisFrameCache(redisClient)
+	c := NewRedisFrameCache(redisClient, HistoryConfig{})
 	require.NotNil(t, c)
 	testFrameCache(t, c)
+
+	err = redisClient.Del(context.Background(), getHistoryKey(orgchannel.PrependOrgID(1, "history"))).Err()
+	require.NoError(t, err)
+	c = NewRedisFrameCache(redisClient, HistoryConfig{MaxRows: 5})
+	testFrameCacheHistory(t, c)
 }
@@ -0,0 +1,106 @@
+package managedstream
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// HistoryConfig bounds the short-term history of frames that is kept for every
+// managed stream channel and replayed to new subscribers.
+type HistoryConfig struct {
+	// MaxAge is the maximum age of the frames in the history. 0 means no limit.
+	MaxAge time.Duration
+	// MaxRows is the maximum number of rows in the history. 0 disables the history.
+	MaxRows int
+}
+
+// Enabled returns true if history should be kept.
+func (c HistoryConfig) Enabled() bool {
+	return c.MaxRows > 0
+}
+
+// historyEvictInterval is the minimum interval between checks for the history of idle channels.
+const historyEvictInterval = time.Minute
+
+// ttl returns how long the history of a channel is kept after the last frame was pushed to it.
+func (c HistoryConfig) ttl() time.Duration {
+	if c.MaxAge > 0 {
+		return c.MaxAge
+	}
+	return frameCacheTTL
+}
+
+// historyEntry is a frame pushed to a channel.
+type historyEntry struct {
+	// Time is the Unix time in milliseconds when the frame was pushed.
+	Time int64 `json:"time"`
+	// Frame is the full JSON frame.
+	Frame json.RawMessage `json:"frame"`
+}
+
+// expired returns true if the entry is older than the max age at the given time.
+func (c HistoryConfig) expired(e historyEntry, now time.Time) bool {
+	return c.MaxAge > 0 && now.Sub(time.UnixMilli(e.Time)) > c.MaxAge
+}
+
+// mergeHistory merges the entries, ordered from the oldest to the newest, into
+// a single JSON frame with the rows of the newest frames that fit into the history.
+// Only the newest frames that have the same schema as the latest one are merged.
+// Returns false if there are no frames to replay.
+func mergeHistory(cfg HistoryConfig, entries []historyEntry, now time.Time) (json.RawMessage, bool, error) {
+	var frames []*data.Frame
+	rows := 0
+	for i := len(entries) - 1; i >= 0 && rows < cfg.MaxRows; i-- {
+		if cfg.expired(entries[i], now) {
+			break
+		}
+		var frame data.Frame
+		if err := json.Unmarshal(entries[i].Frame, &frame); err != nil {
+			return nil, false, err
+		}
+		if len(frames) > 0 && !SameFields(frames[0], &frame) {
+			break
+		}
+		frames = append(frames, &frame)
+		rows += frame.Rows()
+	}
+	if len(frames) == 0 {
+		return nil, false, nil
+	}
+
+	merged := frames[0].EmptyCopy()
+	// Rows of the oldest frame that do not fit into the history.
+	skip := rows - cfg.MaxRows
+	for i := len(frames) - 1; i >= 0; i-- {
+		for rowIdx := 0; rowIdx < frames[i].Rows(); rowIdx++ {
+			if skip > 0 {
+				skip--
+				continue
+			}
+			for fieldIdx, field := range frames[i].Fields {
+				merged.Fields[fieldIdx].Append(field.CopyAt(rowIdx))
+			}
+		}
+	}
+	frameJSON, err := data.FrameToJSON(merged, data.IncludeAll)
+	if err != nil {
+		return nil, false, err
+	}
+	return frameJSON, true, nil
+}
+
+// SameFields returns true if rows of b can be appended to a, i.e. if both frames
+// have fields of the same names and types.
+func SameFields(a, b *data.Frame) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name || a.Fields[i].Type() != b.Fields[i].Type() {
+			return false
+		}
+	}
+	return true
+}
@@ -241,10 +241,17 @@ func (s *NamespaceStream) GetHandlerForPath(_ string) (model.ChannelHandler, err
 
 func (s *NamespaceStream) OnSubscribe(ctx context.Context, u identity.Requester, e model.SubscribeEvent) (model.SubscribeReply, backend.SubscribeStreamStatus, error) {
 	reply := model.SubscribeReply{}
-	frameJSON, ok, err := s.frameCache.GetFrame(ctx, u.GetOrgID(), e.Channel)
+	// Replay the recent history of the channel, or the last frame if there is no history.
+	frameJSON, ok, err := s.frameCache.GetHistory(ctx, u.GetOrgID(), e.Channel)
 	if err != nil {
 		return reply, 0, err
 	}
+	if !ok {
+		frameJSON, ok, err = s.frameCache.GetFrame(ctx, u.GetOrgID(), e.Channel)
+		if err != nil {
+			return reply, 0, err
+		}
+	}
 	if ok {
 		reply.Data = frameJSON
 	}
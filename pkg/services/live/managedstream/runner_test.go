@@ -2,11 +2,16 @@ package managedstream
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/model"
+	"github.com/grafana/grafana/pkg/services/user"
 )
 
 type testPublisher struct {
@@ -19,13 +24,13 @@ func (p *testPublisher) publish(_ int64, _ string, _ []byte) error {
 
 func TestNewManagedStream(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache())
+	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(HistoryConfig{}))
 	require.NotNil(t, c)
 }
 
 func TestManagedStreamMinuteRate(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache())
+	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(HistoryConfig{}))
 	require.NotNil(t, c)
 
 	c.incRate("test1", time.Now().Unix())
@@ -47,7 +52,7 @@ func TestManagedStreamMinuteRate(t *testing.T) {
 
 func TestGetManagedStreams(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	frameCache := NewMemoryFrameCache()
+	frameCache := NewMemoryFrameCache(HistoryConfig{})
 	runner := NewRunner(publisher.publish, nil, frameCache)
 	s1, err := runner.GetOrCreateStream(1, "stream", "test1")
 	require.NoError(t, err)
@@ -83,3 +88,40 @@ func TestGetManagedStreams(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, managedChannels, 7) // Not affected by other org.
 }
+
+func TestManagedStreamOnSubscribeReplaysHistory(t *testing.T) {
+	publisher := &testPublisher{t: t}
+	u := &user.SignedInUser{OrgID: 1}
+	e := model.SubscribeEvent{Channel: "stream/a/cpu"}
+
+	t.Run("history is replayed", func(t *testing.T) {
+		c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(HistoryConfig{MaxRows: 10}))
+		for i := 0; i < 3; i++ {
+			err := c.Push(context.Background(), "cpu", data.NewFrame("cpu", data.NewField("value", nil, []float64{float64(i)})))
+			require.NoError(t, err)
+		}
+
+		reply, status, err := c.OnSubscribe(context.Background(), u, e)
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusOK, status)
+		var f data.Frame
+		require.NoError(t, json.Unmarshal(reply.Data, &f))
+		require.Equal(t, 3, f.Rows())
+	})
+
+	t.Run("last frame is replayed without history", func(t *testing.T) {
+		c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(HistoryConfig{}))
+		for i := 0; i < 3; i++ {
+			err := c.Push(context.Background(), "cpu", data.NewFrame("cpu", data.NewField("value", nil, []float64{float64(i)})))
+			require.NoError(t, err)
+		}
+
+		reply, status, err := c.OnSubscribe(context.Background(), u, e)
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusOK, status)
+		var f data.Frame
+		require.NoError(t, json.Unmarshal(reply.Data, &f))
+		require.Equal(t, 1, f.Rows())
+		require.Equal(t, 2.0, f.Fields[0].At(0))
+	})
+}
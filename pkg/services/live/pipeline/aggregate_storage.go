@@ -0,0 +1,37 @@
+package pipeline
+
+import (
+	"sync"
+	"time"
+)
+
+// AggregateStorage keeps the rows collected by aggregate frame processors in memory,
+// so that they are not lost when the processors are rebuilt with the channel rules.
+// Not usable in HA setup.
+type AggregateStorage struct {
+	mu        sync.Mutex
+	windows   map[string]*aggregateWindow
+	lastEvict time.Time
+	now       func() time.Time
+}
+
+func NewAggregateStorage() *AggregateStorage {
+	return &AggregateStorage{
+		windows: map[string]*aggregateWindow{},
+		now:     time.Now,
+	}
+}
+
+// evictIdle drops the windows which received no frames for their idle duration.
+// Windows are checked at most once per interval. Must be called with the lock held.
+func (s *AggregateStorage) evictIdle(now time.Time, interval time.Duration) {
+	if now.Sub(s.lastEvict) < interval {
+		return
+	}
+	s.lastEvict = now
+	for key, w := range s.windows {
+		if now.Sub(w.updated) > w.idle {
+			delete(s.windows, key)
+		}
+	}
+}
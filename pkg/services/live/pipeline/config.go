@@ -29,8 +29,10 @@ type ChannelRuleSettings struct {
 }
 
 type ChannelRule struct {
-	OrgId    int64               `json:"-"`
-	Pattern  string              `json:"pattern"`
+	OrgId   int64  `json:"-"`
+	Pattern string `json:"pattern"`
+	// Version is increased on every change of a rule stored in the database.
+	Version  int64               `json:"version,omitempty"`
 	Settings ChannelRuleSettings `json:"settings"`
 }
 
@@ -40,6 +42,10 @@ type ConverterConfig struct {
 	ExactJsonConverterConfig  *ExactJsonConverterConfig  `json:"jsonExact,omitempty"`
 	AutoInfluxConverterConfig *AutoInfluxConverterConfig `json:"influxAuto,omitempty"`
 	JsonFrameConverterConfig  *JsonFrameConverterConfig  `json:"jsonFrame,omitempty"`
+
+	AutoPrometheusConverterConfig *AutoPrometheusConverterConfig `json:"prometheusAuto,omitempty"`
+	AutoOTLPConverterConfig       *AutoOTLPConverterConfig       `json:"otlpAuto,omitempty"`
+	AutoCSVConverterConfig        *AutoCSVConverterConfig        `json:"csvAuto,omitempty"`
 }
 
 type DropFieldsFrameProcessorConfig struct {
@@ -50,11 +56,41 @@ type KeepFieldsFrameProcessorConfig struct {
 	FieldNames []string `json:"fieldNames"`
 }
 
+type RenameFieldsFrameProcessorConfig struct {
+	// Renames maps current field names to new ones.
+	Renames map[string]string `json:"renames"`
+}
+
+type ComputeFieldFrameProcessorConfig struct {
+	FieldName string `json:"fieldName"`
+	// Expression is a math expression with fields as variables, i.e. `$temperature * 1.8 + 32`.
+	Expression string `json:"expression"`
+}
+
+type LabelsFrameProcessorConfig struct {
+	// Labels to attach, values can reference ${orgId}, ${channel}, ${scope}, ${namespace} and ${path}.
+	Labels map[string]string `json:"labels"`
+	// FieldNames are the fields to attach labels to, all fields except time if empty.
+	FieldNames []string `json:"fieldNames,omitempty"`
+}
+
+type AggregateFrameProcessorConfig struct {
+	WindowMilliseconds int64 `json:"windowMilliseconds"`
+	// Reducer of numeric fields, mean if empty.
+	Reducer string `json:"reducer,omitempty"`
+	// FieldReducers overrides Reducer for some fields.
+	FieldReducers map[string]string `json:"fieldReducers,omitempty"`
+}
+
 type FrameProcessorConfig struct {
-	Type                      string                          `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
-	DropFieldsProcessorConfig *DropFieldsFrameProcessorConfig `json:"dropFields,omitempty"`
-	KeepFieldsProcessorConfig *KeepFieldsFrameProcessorConfig `json:"keepFields,omitempty"`
-	MultipleProcessorConfig   *MultipleFrameProcessorConfig   `json:"multiple,omitempty"`
+	Type                        string                            `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
+	DropFieldsProcessorConfig   *DropFieldsFrameProcessorConfig   `json:"dropFields,omitempty"`
+	KeepFieldsProcessorConfig   *KeepFieldsFrameProcessorConfig   `json:"keepFields,omitempty"`
+	MultipleProcessorConfig     *MultipleFrameProcessorConfig     `json:"multiple,omitempty"`
+	RenameFieldsProcessorConfig *RenameFieldsFrameProcessorConfig `json:"renameFields,omitempty"`
+	ComputeFieldProcessorConfig *ComputeFieldFrameProcessorConfig `json:"computeField,omitempty"`
+	LabelsProcessorConfig       *LabelsFrameProcessorConfig       `json:"labels,omitempty"`
+	AggregateProcessorConfig    *AggregateFrameProcessorConfig    `json:"aggregate,omitempty"`
 }
 
 type MultipleFrameProcessorConfig struct {
@@ -150,6 +186,23 @@ type AutoInfluxConverterConfig struct {
 	FrameFormat string `json:"frameFormat"`
 }
 
+// AutoPrometheusConverterConfig ...
+type AutoPrometheusConverterConfig struct {
+	FrameFormat string `json:"frameFormat"`
+}
+
+// AutoOTLPConverterConfig ...
+type AutoOTLPConverterConfig struct {
+	FrameFormat string `json:"frameFormat"`
+}
+
+// AutoCSVConverterConfig ...
+type AutoCSVConverterConfig struct {
+	FrameFormat string `json:"frameFormat"`
+	// LabelColumns are the columns which values are used as labels.
+	LabelColumns []string `json:"labelColumns,omitempty"`
+}
+
 type JsonFrameConverterConfig struct{}
 
 type ManagedStreamOutputConfig struct{}
@@ -0,0 +1,52 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/live/convert"
+)
+
+const (
+	ConverterTypePrometheusAuto = "prometheusAuto"
+	ConverterTypeOTLPAuto       = "otlpAuto"
+	ConverterTypeCSVAuto        = "csvAuto"
+)
+
+// autoFormatConverterTypes maps input formats to the types of converters accepting them.
+var autoFormatConverterTypes = map[string]string{
+	convert.InputFormatPrometheus: ConverterTypePrometheusAuto,
+	convert.InputFormatOTLP:       ConverterTypeOTLPAuto,
+	convert.InputFormatCSV:        ConverterTypeCSVAuto,
+}
+
+// AutoFormatConverter decodes input in one of the Prometheus text exposition, OTLP/HTTP
+// or CSV formats and transforms it to several ChannelFrame objects where Channel is
+// constructed from original channel + / + <metric_name>, the same way as AutoInfluxConverter.
+type AutoFormatConverter struct {
+	input     convert.Input
+	converter *convert.Converter
+}
+
+// NewAutoFormatConverter creates new AutoFormatConverter for the format of input.
+func NewAutoFormatConverter(input convert.Input) *AutoFormatConverter {
+	return &AutoFormatConverter{input: input, converter: convert.NewConverter()}
+}
+
+func (c *AutoFormatConverter) Type() string {
+	return autoFormatConverterTypes[c.input.Format]
+}
+
+func (c *AutoFormatConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	frameWrappers, err := c.converter.ConvertInput(body, c.input)
+	if err != nil {
+		return nil, err
+	}
+	channelFrames := make([]*ChannelFrame, 0, len(frameWrappers))
+	for _, fw := range frameWrappers {
+		channelFrames = append(channelFrames, &ChannelFrame{
+			Channel: vars.Channel + "/" + fw.Key(),
+			Frame:   fw.Frame(),
+		})
+	}
+	return channelFrames, nil
+}
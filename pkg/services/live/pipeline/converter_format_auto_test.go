@@ -0,0 +1,26 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/convert"
+)
+
+func TestAutoFormatConverter(t *testing.T) {
+	t.Run("type depends on the input format", func(t *testing.T) {
+		require.Equal(t, ConverterTypePrometheusAuto, NewAutoFormatConverter(convert.Input{Format: convert.InputFormatPrometheus}).Type())
+		require.Equal(t, ConverterTypeOTLPAuto, NewAutoFormatConverter(convert.Input{Format: convert.InputFormatOTLP}).Type())
+		require.Equal(t, ConverterTypeCSVAuto, NewAutoFormatConverter(convert.Input{Format: convert.InputFormatCSV}).Type())
+	})
+
+	t.Run("channels are suffixed with the metric name", func(t *testing.T) {
+		converter := NewAutoFormatConverter(convert.Input{Format: convert.InputFormatPrometheus, FrameFormat: "labels_column"})
+		channelFrames, err := converter.Convert(context.Background(), Vars{Channel: "stream/test"}, []byte("cpu_usage{host=\"a\"} 1\n"))
+		require.NoError(t, err)
+		require.Len(t, channelFrames, 1)
+		require.Equal(t, "stream/test/cpu_usage", channelFrames[0].Channel)
+	})
+}
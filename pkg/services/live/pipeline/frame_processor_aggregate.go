@@ -0,0 +1,233 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+)
+
+// AggregateFrameProcessor downsamples frames by aggregating rows over time windows.
+// Rows are collected per channel until a row of another window arrives, then the
+// collected rows are output as a single row per window with the start of the window
+// as time. Numeric fields are reduced, rows with different values of other fields,
+// i.e. labels of frames in labels column format, are aggregated separately.
+// Processing stops for frames which do not complete a window. Rows of channels which
+// receive no frames for aggregateIdleWindows windows are dropped.
+// The rows are kept in the storage by channel and config, so that processors rebuilt
+// with the same config continue the windows of the previous ones.
+type AggregateFrameProcessor struct {
+	config        AggregateFrameProcessorConfig
+	configKey     string
+	window        time.Duration
+	reducer       mathexp.ReducerFunc
+	fieldReducers map[string]mathexp.ReducerFunc
+	storage       *AggregateStorage
+}
+
+// aggregateIdleWindows is the number of windows without frames after which the
+// rows collected for a channel are dropped.
+const aggregateIdleWindows = 10
+
+// aggregateWindow holds rows of a channel collected in a time window.
+type aggregateWindow struct {
+	start time.Time
+	// updated is the time the last frame of the channel was processed.
+	updated time.Time
+	// idle is the duration without frames after which the window is dropped.
+	idle time.Duration
+	// schema is an empty copy of the frame the rows belong to.
+	schema *data.Frame
+	rows   []aggregateRow
+}
+
+type aggregateRow struct {
+	// key is the values of non-numeric fields.
+	key string
+	// values are *float64 for numeric fields, row values for other fields.
+	values []any
+}
+
+func NewAggregateFrameProcessor(config AggregateFrameProcessorConfig, storage *AggregateStorage) (*AggregateFrameProcessor, error) {
+	if config.WindowMilliseconds <= 0 {
+		return nil, errors.New("window must be positive")
+	}
+	reducer, err := aggregateReducer(config.Reducer)
+	if err != nil {
+		return nil, err
+	}
+	fieldReducers := make(map[string]mathexp.ReducerFunc, len(config.FieldReducers))
+	for name, id := range config.FieldReducers {
+		fieldReducers[name], err = aggregateReducer(id)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", name, err)
+		}
+	}
+	configKey, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	return &AggregateFrameProcessor{
+		config:        config,
+		configKey:     string(configKey),
+		window:        time.Duration(config.WindowMilliseconds) * time.Millisecond,
+		reducer:       reducer,
+		fieldReducers: fieldReducers,
+		storage:       storage,
+	}, nil
+}
+
+func aggregateReducer(id string) (mathexp.ReducerFunc, error) {
+	switch mathexp.ReducerID(id) {
+	case "":
+		return mathexp.Avg, nil
+	case mathexp.ReducerRate:
+		// Rate depends on timestamps which are not kept for aggregated rows.
+		return nil, fmt.Errorf("unsupported reducer: %s", id)
+	}
+	return mathexp.GetReduceFunc(mathexp.ReducerID(id))
+}
+
+const FrameProcessorTypeAggregate = "aggregate"
+
+func (p *AggregateFrameProcessor) Type() string {
+	return FrameProcessorTypeAggregate
+}
+
+func (p *AggregateFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	timeIdx := -1
+	for i, field := range frame.Fields {
+		if field.Type().Time() {
+			timeIdx = i
+			break
+		}
+	}
+	if timeIdx < 0 {
+		return nil, errors.New("frame has no time field")
+	}
+	rows, err := frame.RowLen()
+	if err != nil {
+		return nil, err
+	}
+
+	p.storage.mu.Lock()
+	defer p.storage.mu.Unlock()
+
+	now := p.storage.now()
+	p.storage.evictIdle(now, p.window)
+
+	key := fmt.Sprintf("%d/%s/%s", vars.OrgID, vars.Channel, p.configKey)
+	w, ok := p.storage.windows[key]
+	if ok && !managedstream.SameFields(w.schema, frame) {
+		logger.Debug("Frame schema changed, dropping aggregated rows", "channel", vars.Channel)
+		ok = false
+	}
+	if !ok {
+		w = &aggregateWindow{schema: frame.EmptyCopy(), idle: aggregateIdleWindows * p.window}
+		p.storage.windows[key] = w
+	}
+
+	var out *data.Frame
+	for rowIdx := 0; rowIdx < rows; rowIdx++ {
+		t, ok := frame.Fields[timeIdx].ConcreteAt(rowIdx)
+		if !ok {
+			continue
+		}
+		start := t.(time.Time).Truncate(p.window)
+		if len(w.rows) > 0 && !start.Equal(w.start) {
+			if out == nil {
+				out = w.schema.EmptyCopy()
+			}
+			p.flush(w, timeIdx, out)
+		}
+		w.start = start
+		row, err := newAggregateRow(frame, rowIdx, timeIdx)
+		if err != nil {
+			return nil, err
+		}
+		w.rows = append(w.rows, row)
+	}
+	if len(w.rows) == 0 {
+		delete(p.storage.windows, key)
+	} else {
+		w.updated = now
+	}
+	return out, nil
+}
+
+func newAggregateRow(frame *data.Frame, rowIdx int, timeIdx int) (aggregateRow, error) {
+	var key strings.Builder
+	values := make([]any, len(frame.Fields))
+	for i, field := range frame.Fields {
+		switch {
+		case i == timeIdx:
+		case field.Type().Numeric():
+			v, err := field.NullableFloatAt(rowIdx)
+			if err != nil {
+				return aggregateRow{}, err
+			}
+			values[i] = v
+		default:
+			values[i] = field.CopyAt(rowIdx)
+			v, _ := field.ConcreteAt(rowIdx)
+			_, _ = fmt.Fprintf(&key, "%v\xff", v)
+		}
+	}
+	return aggregateRow{key: key.String(), values: values}, nil
+}
+
+// flush appends a row per group of the window rows to the out frame and resets the window.
+func (p *AggregateFrameProcessor) flush(w *aggregateWindow, timeIdx int, out *data.Frame) {
+	var keys []string
+	groups := map[string][]aggregateRow{}
+	for _, row := range w.rows {
+		if _, ok := groups[row.key]; !ok {
+			keys = append(keys, row.key)
+		}
+		groups[row.key] = append(groups[row.key], row)
+	}
+	w.rows = nil
+
+	for i, field := range w.schema.Fields {
+		if i != timeIdx && field.Type().Numeric() && out.Fields[i].Type() != data.FieldTypeNullableFloat64 {
+			// Aggregated values are floats whatever the type of the field is.
+			out.Fields[i] = data.NewField(field.Name, field.Labels, []*float64{})
+			out.Fields[i].Config = field.Config
+		}
+	}
+
+	for _, key := range keys {
+		group := groups[key]
+		for i, field := range w.schema.Fields {
+			switch {
+			case i == timeIdx:
+				if field.Type() == data.FieldTypeNullableTime {
+					start := w.start
+					out.Fields[i].Append(&start)
+				} else {
+					out.Fields[i].Append(w.start)
+				}
+			case field.Type().Numeric():
+				values := make([]*float64, len(group))
+				for rowIdx, row := range group {
+					values[rowIdx] = row.values[i].(*float64)
+				}
+				reducer := p.reducer
+				if r, ok := p.fieldReducers[field.Name]; ok {
+					reducer = r
+				}
+				valuesField := data.NewField("", nil, values)
+				out.Fields[i].Append(reducer((*mathexp.Float64Field)(valuesField)))
+			default:
+				out.Fields[i].Append(group[len(group)-1].values[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateFrameProcessor(t *testing.T) {
+	processor, err := NewAggregateFrameProcessor(AggregateFrameProcessorConfig{
+		WindowMilliseconds: 10000,
+		FieldReducers:      map[string]string{"count": "sum"},
+	}, NewAggregateStorage())
+	require.NoError(t, err)
+
+	start := time.Unix(1636111200, 0)
+	newFrame := func(offsets []time.Duration, values []float64, counts []int64) *data.Frame {
+		times := make([]time.Time, len(offsets))
+		for i, offset := range offsets {
+			times[i] = start.Add(offset)
+		}
+		return data.NewFrame("test",
+			data.NewField("time", nil, times),
+			data.NewField("value", nil, values),
+			data.NewField("count", nil, counts),
+		)
+	}
+	vars := Vars{OrgID: 1, Channel: "stream/test/aggregate"}
+
+	out, err := processor.ProcessFrame(context.Background(), vars, newFrame([]time.Duration{0, time.Second}, []float64{1, 2}, []int64{1, 1}))
+	require.NoError(t, err)
+	require.Nil(t, out)
+
+	out, err = processor.ProcessFrame(context.Background(), vars, newFrame([]time.Duration{2 * time.Second}, []float64{6}, []int64{1}))
+	require.NoError(t, err)
+	require.Nil(t, out)
+
+	// Other channels are aggregated separately.
+	out, err = processor.ProcessFrame(context.Background(), Vars{OrgID: 2, Channel: "stream/test/aggregate"}, newFrame([]time.Duration{11 * time.Second}, []float64{10}, []int64{1}))
+	require.NoError(t, err)
+	require.Nil(t, out)
+
+	out, err = processor.ProcessFrame(context.Background(), vars, newFrame([]time.Duration{11 * time.Second, 21 * time.Second}, []float64{4, 5}, []int64{2, 2}))
+	require.NoError(t, err)
+	require.NotNil(t, out)
+	require.Equal(t, 2, out.Rows())
+	require.Equal(t, start, out.Fields[0].At(0))
+	require.Equal(t, 3.0, *out.Fields[1].At(0).(*float64))
+	require.Equal(t, 3.0, *out.Fields[2].At(0).(*float64))
+	require.Equal(t, start.Add(10*time.Second), out.Fields[0].At(1))
+	require.Equal(t, 4.0, *out.Fields[1].At(1).(*float64))
+	require.Equal(t, 2.0, *out.Fields[2].At(1).(*float64))
+}
+
+func TestAggregateFrameProcessor_LabelsColumn(t *testing.T) {
+	processor, err := NewAggregateFrameProcessor(AggregateFrameProcessorConfig{
+		WindowMilliseconds: 10000,
+		Reducer:            "max",
+	}, NewAggregateStorage())
+	require.NoError(t, err)
+
+	start := time.Unix(1636111200, 0)
+	vars := Vars{OrgID: 1, Channel: "stream/test/aggregate"}
+	frame := data.NewFrame("test",
+		data.NewField("labels", nil, []string{"host=a", "host=b", "host=a", "host=a"}),
+		data.NewField("time", nil, []time.Time{start, start, start.Add(time.Second), start.Add(10 * time.Second)}),
+		data.NewField("value", nil, []float64{1, 2, 3, 4}),
+	)
+
+	out, err := processor.ProcessFrame(context.Background(), vars, frame)
+	require.NoError(t, err)
+	require.NotNil(t, out)
+	require.Equal(t, 2, out.Rows())
+	require.Equal(t, "host=a", out.Fields[0].At(0))
+	require.Equal(t, 3.0, *out.Fields[2].At(0).(*float64))
+	require.Equal(t, "host=b", out.Fields[0].At(1))
+	require.Equal(t, 2.0, *out.Fields[2].At(1).(*float64))
+}
+
+func TestAggregateFrameProcessor_Config(t *testing.T) {
+	_, err := NewAggregateFrameProcessor(AggregateFrameProcessorConfig{}, NewAggregateStorage())
+	require.Error(t, err)
+
+	_, err = NewAggregateFrameProcessor(AggregateFrameProcessorConfig{WindowMilliseconds: 1000, Reducer: "unknown"}, NewAggregateStorage())
+	require.Error(t, err)
+
+	_, err = NewAggregateFrameProcessor(AggregateFrameProcessorConfig{WindowMilliseconds: 1000, FieldReducers: map[string]string{"value": "rate"}}, NewAggregateStorage())
+	require.Error(t, err)
+
+	_, err = NewAggregateFrameProcessor(AggregateFrameProcessorConfig{WindowMilliseconds: 1000, Reducer: "percentile"}, NewAggregateStorage())
+	require.Error(t, err)
+}
+
+func TestAggregateFrameProcessor_EvictsIdleChannels(t *testing.T) {
+	storage := NewAggregateStorage()
+	processor, err := NewAggregateFrameProcessor(AggregateFrameProcessorConfig{WindowMilliseconds: 1000}, storage)
+	require.NoError(t, err)
+	now := time.Unix(1636111200, 0)
+	storage.now = func() time.Time { return now }
+
+	newFrame := func(times ...time.Time) *data.Frame {
+		return data.NewFrame("test",
+			data.NewField("time", nil, times),
+			data.NewField("value", nil, make([]float64, len(times))),
+		)
+	}
+	idle := Vars{OrgID: 1, Channel: "stream/test/idle"}
+	active := Vars{OrgID: 1, Channel: "stream/test/active"}
+
+	_, err = processor.ProcessFrame(context.Background(), idle, newFrame(now))
+	require.NoError(t, err)
+	// Frames without rows do not keep a window.
+	_, err = processor.ProcessFrame(context.Background(), Vars{OrgID: 1, Channel: "stream/test/empty"}, newFrame())
+	require.NoError(t, err)
+	require.Len(t, storage.windows, 1)
+
+	now = now.Add(aggregateIdleWindows*time.Second + time.Second)
+	_, err = processor.ProcessFrame(context.Background(), active, newFrame(now))
+	require.NoError(t, err)
+	require.Len(t, storage.windows, 1)
+	require.Contains(t, storage.windows, "1/stream/test/active/"+processor.configKey)
+}
+
+// aggregateRulesStorage is a Storage with a single channel rule.
+type aggregateRulesStorage struct {
+	Storage
+	rule ChannelRule
+}
+
+func (s *aggregateRulesStorage) ListChannelRules(_ context.Context, _ int64) ([]ChannelRule, error) {
+	return []ChannelRule{s.rule}, nil
+}
+
+func (s *aggregateRulesStorage) ListWriteConfigs(_ context.Context, _ int64) ([]WriteConfig, error) {
+	return nil, nil
+}
+
+func TestAggregateFrameProcessor_RulesRebuiltInWindow(t *testing.T) {
+	builder := &StorageRuleBuilder{
+		AggregateStorage: NewAggregateStorage(),
+		Storage: &aggregateRulesStorage{rule: ChannelRule{
+			OrgId:   1,
+			Pattern: "stream/test/aggregate",
+			Settings: ChannelRuleSettings{
+				FrameProcessors: []*FrameProcessorConfig{{
+					Type:                     FrameProcessorTypeAggregate,
+					AggregateProcessorConfig: &AggregateFrameProcessorConfig{WindowMilliseconds: 10000},
+				}},
+			},
+		}},
+	}
+	build := func() FrameProcessor {
+		rules, err := builder.BuildRules(context.Background(), 1)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		return rules[0].FrameProcessors[0]
+	}
+
+	start := time.Unix(1636111200, 0)
+	vars := Vars{OrgID: 1, Channel: "stream/test/aggregate"}
+	newFrame := func(offset time.Duration, value float64) *data.Frame {
+		return data.NewFrame("test",
+			data.NewField("time", nil, []time.Time{start.Add(offset)}),
+			data.NewField("value", nil, []float64{value}),
+		)
+	}
+
+	out, err := build().ProcessFrame(context.Background(), vars, newFrame(0, 1))
+	require.NoError(t, err)
+	require.Nil(t, out)
+
+	// The rules are rebuilt in the middle of the window.
+	out, err = build().ProcessFrame(context.Background(), vars, newFrame(5*time.Second, 3))
+	require.NoError(t, err)
+	require.Nil(t, out)
+
+	out, err = build().ProcessFrame(context.Background(), vars, newFrame(10*time.Second, 10))
+	require.NoError(t, err)
+	require.NotNil(t, out)
+	require.Equal(t, 1, out.Rows())
+	require.Equal(t, 2.0, *out.Fields[1].At(0).(*float64))
+}
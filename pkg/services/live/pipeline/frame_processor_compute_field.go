@@ -0,0 +1,99 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// ComputeFieldFrameProcessor adds a field with values of a math expression
+// over other fields of a data.Frame, i.e. `$temperature * 1.8 + 32`. The
+// expression is evaluated for every row, variables are the values of the
+// fields in the row.
+type ComputeFieldFrameProcessor struct {
+	config ComputeFieldFrameProcessorConfig
+	expr   *mathexp.Expr
+}
+
+func NewComputeFieldFrameProcessor(config ComputeFieldFrameProcessorConfig) (*ComputeFieldFrameProcessor, error) {
+	if config.FieldName == "" {
+		return nil, fmt.Errorf("field name is required")
+	}
+	expr, err := mathexp.New(config.Expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %w", err)
+	}
+	return &ComputeFieldFrameProcessor{config: config, expr: expr}, nil
+}
+
+const FrameProcessorTypeComputeField = "computeField"
+
+func (p *ComputeFieldFrameProcessor) Type() string {
+	return FrameProcessorTypeComputeField
+}
+
+func (p *ComputeFieldFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	varFields := make(map[string]*data.Field, len(p.expr.VarNames))
+	for _, name := range p.expr.VarNames {
+		field, _ := frame.FieldByName(name)
+		if field == nil {
+			return nil, fmt.Errorf("field %s not found", name)
+		}
+		if !field.Type().Numeric() {
+			return nil, fmt.Errorf("field %s is not numeric", name)
+		}
+		varFields[name] = field
+	}
+
+	rows, err := frame.RowLen()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]*float64, rows)
+	for rowIdx := 0; rowIdx < rows; rowIdx++ {
+		vars := make(mathexp.Vars, len(varFields))
+		for name, field := range varFields {
+			value, err := field.NullableFloatAt(rowIdx)
+			if err != nil {
+				return nil, err
+			}
+			vars[name] = mathexp.NewScalarResults(name, value)
+		}
+		results, err := p.expr.Execute(p.config.FieldName, vars, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error executing expression: %w", err)
+		}
+		values[rowIdx], err = resultValue(results)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	field := data.NewField(p.config.FieldName, nil, values)
+	if _, idx := frame.FieldByName(p.config.FieldName); idx >= 0 {
+		frame.Fields[idx] = field
+	} else {
+		frame.Fields = append(frame.Fields, field)
+	}
+	return frame, nil
+}
+
+func resultValue(results mathexp.Results) (*float64, error) {
+	if results.IsNoData() {
+		return nil, nil
+	}
+	if len(results.Values) != 1 {
+		return nil, fmt.Errorf("expression must return a single value, got %d", len(results.Values))
+	}
+	switch v := results.Values[0].(type) {
+	case mathexp.Scalar:
+		return v.GetFloat64Value(), nil
+	case mathexp.Number:
+		return v.GetFloat64Value(), nil
+	default:
+		return nil, fmt.Errorf("expression must return a number, got %s", v.Type())
+	}
+}
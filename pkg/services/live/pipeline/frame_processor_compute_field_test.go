@@ -0,0 +1,78 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeFieldFrameProcessor(t *testing.T) {
+	processor, err := NewComputeFieldFrameProcessor(ComputeFieldFrameProcessorConfig{
+		FieldName:  "temperature_f",
+		Expression: "$temperature * 1.8 + 32",
+	})
+	require.NoError(t, err)
+
+	temperature := 100.0
+	frame := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{time.Now(), time.Now()}),
+		data.NewField("temperature", nil, []*float64{&temperature, nil}),
+	)
+
+	frame, err = processor.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, "temperature_f", frame.Fields[2].Name)
+	require.Equal(t, 212.0, *frame.Fields[2].At(0).(*float64))
+	require.Nil(t, frame.Fields[2].At(1))
+}
+
+func TestComputeFieldFrameProcessor_ReplacesField(t *testing.T) {
+	processor, err := NewComputeFieldFrameProcessor(ComputeFieldFrameProcessorConfig{
+		FieldName:  "value",
+		Expression: "abs($value) + ${other value}",
+	})
+	require.NoError(t, err)
+
+	frame := data.NewFrame("test",
+		data.NewField("value", nil, []int64{-2}),
+		data.NewField("other value", nil, []float64{0.5}),
+	)
+
+	frame, err = processor.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+	require.Len(t, frame.Fields, 2)
+	require.Equal(t, "value", frame.Fields[0].Name)
+	require.Equal(t, 2.5, *frame.Fields[0].At(0).(*float64))
+}
+
+func TestComputeFieldFrameProcessor_Errors(t *testing.T) {
+	_, err := NewComputeFieldFrameProcessor(ComputeFieldFrameProcessorConfig{
+		FieldName:  "value",
+		Expression: "$value +",
+	})
+	require.Error(t, err)
+
+	processor, err := NewComputeFieldFrameProcessor(ComputeFieldFrameProcessorConfig{
+		FieldName:  "value",
+		Expression: "$missing * 2",
+	})
+	require.NoError(t, err)
+	_, err = processor.ProcessFrame(context.Background(), Vars{}, data.NewFrame("test",
+		data.NewField("value", nil, []float64{1}),
+	))
+	require.Error(t, err)
+
+	processor, err = NewComputeFieldFrameProcessor(ComputeFieldFrameProcessorConfig{
+		FieldName:  "value",
+		Expression: "$host * 2",
+	})
+	require.NoError(t, err)
+	_, err = processor.ProcessFrame(context.Background(), Vars{}, data.NewFrame("test",
+		data.NewField("host", nil, []string{"a"}),
+	))
+	require.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"strconv"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// LabelsFrameProcessor can attach labels to fields of a data.Frame. Label values
+// can reference the channel the frame belongs to, i.e. `${namespace}`, see labelVar.
+type LabelsFrameProcessor struct {
+	config LabelsFrameProcessorConfig
+}
+
+func NewLabelsFrameProcessor(config LabelsFrameProcessorConfig) *LabelsFrameProcessor {
+	return &LabelsFrameProcessor{config: config}
+}
+
+const FrameProcessorTypeLabels = "labels"
+
+func (p *LabelsFrameProcessor) Type() string {
+	return FrameProcessorTypeLabels
+}
+
+func (p *LabelsFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	labels := make(data.Labels, len(p.config.Labels))
+	for name, value := range p.config.Labels {
+		labels[name] = os.Expand(value, func(name string) string {
+			return labelVar(vars, name)
+		})
+	}
+	for _, field := range frame.Fields {
+		if len(p.config.FieldNames) > 0 {
+			if !stringInSlice(field.Name, p.config.FieldNames) {
+				continue
+			}
+		} else if field.Type().Time() {
+			continue
+		}
+		// Labels can be shared between fields, so they are copied.
+		fieldLabels := make(data.Labels, len(field.Labels)+len(labels))
+		for name, value := range field.Labels {
+			fieldLabels[name] = value
+		}
+		for name, value := range labels {
+			fieldLabels[name] = value
+		}
+		field.Labels = fieldLabels
+	}
+	return frame, nil
+}
+
+// labelVar returns the value of a variable available in label templates.
+func labelVar(vars Vars, name string) string {
+	switch name {
+	case "orgId":
+		return strconv.FormatInt(vars.OrgID, 10)
+	case "channel":
+		return vars.Channel
+	case "scope":
+		return vars.Scope
+	case "namespace":
+		return vars.Namespace
+	case "path":
+		return vars.Path
+	}
+	return ""
+}
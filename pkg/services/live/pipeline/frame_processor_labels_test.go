@@ -0,0 +1,54 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelsFrameProcessor(t *testing.T) {
+	processor := NewLabelsFrameProcessor(LabelsFrameProcessorConfig{
+		Labels: map[string]string{
+			"source": "mqtt",
+			"device": "${namespace}-${path}",
+			"org":    "${orgId}",
+		},
+	})
+
+	labels := data.Labels{"host": "a"}
+	frame := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{time.Now()}),
+		data.NewField("temperature", labels, []float64{1}),
+		data.NewField("humidity", labels, []float64{2}),
+	)
+	vars := Vars{OrgID: 2, Channel: "stream/devices/kitchen", Scope: "stream", Namespace: "devices", Path: "kitchen"}
+
+	frame, err := processor.ProcessFrame(context.Background(), vars, frame)
+	require.NoError(t, err)
+	require.Nil(t, frame.Fields[0].Labels)
+	expected := data.Labels{"host": "a", "source": "mqtt", "device": "devices-kitchen", "org": "2"}
+	require.Equal(t, expected, frame.Fields[1].Labels)
+	require.Equal(t, expected, frame.Fields[2].Labels)
+	require.Equal(t, data.Labels{"host": "a"}, labels)
+}
+
+func TestLabelsFrameProcessor_FieldNames(t *testing.T) {
+	processor := NewLabelsFrameProcessor(LabelsFrameProcessorConfig{
+		Labels:     map[string]string{"unit": "celsius"},
+		FieldNames: []string{"temperature"},
+	})
+
+	frame := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{time.Now()}),
+		data.NewField("temperature", nil, []float64{1}),
+		data.NewField("humidity", nil, []float64{2}),
+	)
+
+	frame, err := processor.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+	require.Equal(t, data.Labels{"unit": "celsius"}, frame.Fields[1].Labels)
+	require.Nil(t, frame.Fields[2].Labels)
+}
@@ -7,7 +7,8 @@ import (
 )
 
 // MultipleFrameProcessor can combine several FrameProcessor and
-// execute them sequentially.
+// execute them sequentially. Processing stops as soon as a processor
+// returns no frame.
 type MultipleFrameProcessor struct {
 	Processors []FrameProcessor
 }
@@ -26,6 +27,9 @@ func (p *MultipleFrameProcessor) ProcessFrame(ctx context.Context, vars Vars, fr
 			logger.Error("Error processing frame", "error", err)
 			return nil, err
 		}
+		if frame == nil {
+			return nil, nil
+		}
 	}
 	return frame, nil
 }
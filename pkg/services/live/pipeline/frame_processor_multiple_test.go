@@ -0,0 +1,39 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipleFrameProcessor_StopsOnNilFrame(t *testing.T) {
+	aggregate, err := NewAggregateFrameProcessor(AggregateFrameProcessorConfig{WindowMilliseconds: 10000}, NewAggregateStorage())
+	require.NoError(t, err)
+	processor := NewMultipleFrameProcessor(
+		aggregate,
+		NewRenameFieldsFrameProcessor(RenameFieldsFrameProcessorConfig{Renames: map[string]string{"value": "avg"}}),
+	)
+
+	start := time.Unix(1636111200, 0)
+	vars := Vars{OrgID: 1, Channel: "stream/test/multiple"}
+	newFrame := func(offset time.Duration, value float64) *data.Frame {
+		return data.NewFrame("test",
+			data.NewField("time", nil, []time.Time{start.Add(offset)}),
+			data.NewField("value", nil, []float64{value}),
+		)
+	}
+
+	// The aggregate processor returns no frame until the window is complete.
+	out, err := processor.ProcessFrame(context.Background(), vars, newFrame(0, 1))
+	require.NoError(t, err)
+	require.Nil(t, out)
+
+	out, err = processor.ProcessFrame(context.Background(), vars, newFrame(10*time.Second, 2))
+	require.NoError(t, err)
+	require.NotNil(t, out)
+	require.Equal(t, "avg", out.Fields[1].Name)
+	require.Equal(t, 1.0, *out.Fields[1].At(0).(*float64))
+}
@@ -0,0 +1,26 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenameFieldsFrameProcessor(t *testing.T) {
+	processor := NewRenameFieldsFrameProcessor(RenameFieldsFrameProcessorConfig{
+		Renames: map[string]string{"cpu": "cpu_usage", "missing": "other"},
+	})
+	frame := data.NewFrame("test",
+		data.NewField("time", nil, []int64{1}),
+		data.NewField("cpu", nil, []float64{0.5}),
+	)
+
+	out, err := processor.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+	require.Len(t, out.Fields, 2)
+	require.Equal(t, "time", out.Fields[0].Name)
+	require.Equal(t, "cpu_usage", out.Fields[1].Name)
+	require.Equal(t, 0.5, out.Fields[1].At(0))
+}
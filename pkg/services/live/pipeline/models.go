@@ -1,12 +1,76 @@
 package pipeline
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/live/pipeline/pattern"
 	"github.com/grafana/grafana/pkg/services/live/pipeline/tree"
 )
 
+var (
+	ErrChannelRuleNotFound = errors.New("channel rule not found")
+	ErrChannelRuleExists   = errors.New("channel rule already exists")
+	ErrInvalidChannelRule  = errors.New("invalid channel rule")
+	ErrWriteConfigNotFound = errors.New("write config not found")
+	ErrWriteConfigExists   = errors.New("write config already exists")
+	ErrInvalidWriteConfig  = errors.New("invalid write config")
+	// ErrWriteConfigInUse is returned when a write config which is referenced
+	// by channel rules is deleted.
+	ErrWriteConfigInUse = errors.New("write config is used by channel rules")
+	// ErrVersionConflict is returned when an update is based on a version
+	// which is not the current one.
+	ErrVersionConflict = errors.New("version conflict")
+)
+
+// UsesWriteConfig returns true if an output of the rule writes with the write config.
+func (r ChannelRule) UsesWriteConfig(uid string) bool {
+	for _, out := range r.Settings.DataOutputters {
+		if out.LokiOutputConfig != nil && out.LokiOutputConfig.UID == uid {
+			return true
+		}
+	}
+	for _, out := range r.Settings.FrameOutputters {
+		if frameOutputterUsesWriteConfig(out, uid) {
+			return true
+		}
+	}
+	return false
+}
+
+func frameOutputterUsesWriteConfig(out *FrameOutputterConfig, uid string) bool {
+	if out == nil {
+		return false
+	}
+	switch {
+	case out.RemoteWriteOutputConfig != nil && out.RemoteWriteOutputConfig.UID == uid:
+		return true
+	case out.LokiOutputConfig != nil && out.LokiOutputConfig.UID == uid:
+		return true
+	case out.ConditionalOutputConfig != nil && frameOutputterUsesWriteConfig(out.ConditionalOutputConfig.Outputter, uid):
+		return true
+	}
+	if out.MultipleOutputterConfig != nil {
+		for i := range out.MultipleOutputterConfig.Outputters {
+			if frameOutputterUsesWriteConfig(&out.MultipleOutputterConfig.Outputters[i], uid) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkWriteConfigUnused returns ErrWriteConfigInUse if any of the rules uses the write config.
+func checkWriteConfigUnused(rules []ChannelRule, uid string) error {
+	for _, rule := range rules {
+		if rule.UsesWriteConfig(uid) {
+			return fmt.Errorf("%w: %s is used by channel rule %s", ErrWriteConfigInUse, uid, rule.Pattern)
+		}
+	}
+	return nil
+}
+
 func (r ChannelRule) Valid() (bool, string) {
 	ok, reason := pattern.Valid(r.Pattern)
 	if !ok {
@@ -24,6 +88,13 @@ func (r ChannelRule) Valid() (bool, string) {
 			}
 		}
 	}
+	if len(r.Settings.DataOutputters) > 0 {
+		for _, out := range r.Settings.DataOutputters {
+			if !typeRegistered(out.Type, DataOutputsRegistry) {
+				return false, fmt.Sprintf("unknown data output type: %s", out.Type)
+			}
+		}
+	}
 	if len(r.Settings.FrameProcessors) > 0 {
 		for _, proc := range r.Settings.FrameProcessors {
 			if !typeRegistered(proc.Type, FrameProcessorsRegistry) {
@@ -57,6 +128,7 @@ func WriteConfigToDto(b WriteConfig) WriteConfigDto {
 	}
 	return WriteConfigDto{
 		UID:          b.UID,
+		Version:      b.Version,
 		Settings:     b.Settings,
 		SecureFields: secureFields,
 	}
@@ -64,6 +136,7 @@ func WriteConfigToDto(b WriteConfig) WriteConfigDto {
 
 type WriteConfigDto struct {
 	UID          string          `json:"uid"`
+	Version      int64           `json:"version,omitempty"`
 	Settings     WriteSettings   `json:"settings"`
 	SecureFields map[string]bool `json:"secureFields"`
 }
@@ -78,11 +151,13 @@ type WriteConfigCreateCmd struct {
 	SecureSettings map[string]string `json:"secureSettings"`
 }
 
-// TODO: add version field later.
 type WriteConfigUpdateCmd struct {
 	UID            string            `json:"uid"`
 	Settings       WriteSettings     `json:"settings"`
 	SecureSettings map[string]string `json:"secureSettings"`
+	// Version is the version the update is based on. Updates of another
+	// version are rejected with ErrVersionConflict, zero skips the check.
+	Version int64 `json:"version,omitempty"`
 }
 
 type WriteConfigDeleteCmd struct {
@@ -92,6 +167,7 @@ type WriteConfigDeleteCmd struct {
 type WriteConfig struct {
 	OrgId          int64             `json:"-"`
 	UID            string            `json:"uid"`
+	Version        int64             `json:"version,omitempty"`
 	Settings       WriteSettings     `json:"settings"`
 	SecureSettings map[string][]byte `json:"secureSettings,omitempty"`
 }
@@ -149,13 +225,35 @@ func checkRulesValid(orgID int64, rules []ChannelRule) (ok bool, reason string)
 type ChannelRuleCreateCmd struct {
 	Pattern  string              `json:"pattern"`
 	Settings ChannelRuleSettings `json:"settings"`
+	// UserID is the user making the change, it is kept in the rule history.
+	UserID int64 `json:"-"`
 }
 
 type ChannelRuleUpdateCmd struct {
 	Pattern  string              `json:"pattern"`
 	Settings ChannelRuleSettings `json:"settings"`
+	// Version is the version the update is based on. Updates of another
+	// version are rejected with ErrVersionConflict, zero skips the check.
+	Version int64 `json:"version,omitempty"`
+	UserID  int64 `json:"-"`
 }
 
 type ChannelRuleDeleteCmd struct {
 	Pattern string `json:"pattern"`
+	UserID  int64  `json:"-"`
+}
+
+type ChannelRuleVersionsCmd struct {
+	Pattern string `json:"pattern"`
+}
+
+// ChannelRuleVersion is a channel rule as it was saved by a change, the
+// last version of a deleted rule has Deleted set.
+type ChannelRuleVersion struct {
+	Pattern   string              `json:"pattern"`
+	Version   int64               `json:"version"`
+	Settings  ChannelRuleSettings `json:"settings"`
+	Deleted   bool                `json:"deleted"`
+	Created   time.Time           `json:"created"`
+	CreatedBy int64               `json:"createdBy"`
 }
@@ -66,6 +66,28 @@ var ConvertersRegistry = []EntityInfo{
 		Type:        ConverterTypeJsonFrame,
 		Description: "JSON-encoded Grafana data frame",
 	},
+	{
+		Type:        ConverterTypePrometheusAuto,
+		Description: "accept Prometheus text exposition format",
+		Example: AutoPrometheusConverterConfig{
+			FrameFormat: "labels_column",
+		},
+	},
+	{
+		Type:        ConverterTypeOTLPAuto,
+		Description: "accept OTLP/HTTP metrics in protobuf or JSON encoding",
+		Example: AutoOTLPConverterConfig{
+			FrameFormat: "labels_column",
+		},
+	},
+	{
+		Type:        ConverterTypeCSVAuto,
+		Description: "accept CSV with a header line, time and name columns are optional",
+		Example: AutoCSVConverterConfig{
+			FrameFormat:  "labels_column",
+			LabelColumns: []string{"host"},
+		},
+	},
 }
 
 var FrameProcessorsRegistry = []EntityInfo{
@@ -79,6 +101,36 @@ var FrameProcessorsRegistry = []EntityInfo{
 		Description: "list the fields that should be removed",
 		Example:     DropFieldsFrameProcessorConfig{},
 	},
+	{
+		Type:        FrameProcessorTypeRenameFields,
+		Description: "rename fields",
+		Example: RenameFieldsFrameProcessorConfig{
+			Renames: map[string]string{"temp": "temperature"},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeComputeField,
+		Description: "add a field computed with a math expression over other fields",
+		Example: ComputeFieldFrameProcessorConfig{
+			FieldName:  "temperature_f",
+			Expression: "$temperature * 1.8 + 32",
+		},
+	},
+	{
+		Type:        FrameProcessorTypeLabels,
+		Description: "attach static or templated labels to fields",
+		Example: LabelsFrameProcessorConfig{
+			Labels: map[string]string{"device": "${path}"},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeAggregate,
+		Description: "downsample rows by aggregating them over a time window",
+		Example: AggregateFrameProcessorConfig{
+			WindowMilliseconds: 10000,
+			Reducer:            "mean",
+		},
+	},
 }
 
 var DataOutputsRegistry = []EntityInfo{
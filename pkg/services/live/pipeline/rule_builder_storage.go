@@ -6,6 +6,7 @@ import (
 
 	"github.com/centrifugal/centrifuge"
 
+	"github.com/grafana/grafana/pkg/services/live/convert"
 	"github.com/grafana/grafana/pkg/services/live/managedstream"
 	"github.com/grafana/grafana/pkg/services/secrets"
 )
@@ -14,6 +15,7 @@ type StorageRuleBuilder struct {
 	Node                 *centrifuge.Node
 	ManagedStream        *managedstream.Runner
 	FrameStorage         *FrameStorage
+	AggregateStorage     *AggregateStorage
 	Storage              Storage
 	ChannelHandlerGetter ChannelHandlerGetter
 	SecretsService       secrets.Service
@@ -69,6 +71,31 @@ func (f *StorageRuleBuilder) extractConverter(config *ConverterConfig) (Converte
 			return nil, missingConfiguration
 		}
 		return NewAutoInfluxConverter(*config.AutoInfluxConverterConfig), nil
+	case ConverterTypePrometheusAuto:
+		if config.AutoPrometheusConverterConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewAutoFormatConverter(convert.Input{
+			Format:      convert.InputFormatPrometheus,
+			FrameFormat: config.AutoPrometheusConverterConfig.FrameFormat,
+		}), nil
+	case ConverterTypeOTLPAuto:
+		if config.AutoOTLPConverterConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewAutoFormatConverter(convert.Input{
+			Format:      convert.InputFormatOTLP,
+			FrameFormat: config.AutoOTLPConverterConfig.FrameFormat,
+		}), nil
+	case ConverterTypeCSVAuto:
+		if config.AutoCSVConverterConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewAutoFormatConverter(convert.Input{
+			Format:       convert.InputFormatCSV,
+			FrameFormat:  config.AutoCSVConverterConfig.FrameFormat,
+			LabelColumns: config.AutoCSVConverterConfig.LabelColumns,
+		}), nil
 	default:
 		return nil, fmt.Errorf("unknown converter type: %s", config.Type)
 	}
@@ -104,6 +131,30 @@ func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig)
 			processors = append(processors, proc)
 		}
 		return NewMultipleFrameProcessor(processors...), nil
+	case FrameProcessorTypeRenameFields:
+		if config.RenameFieldsProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewRenameFieldsFrameProcessor(*config.RenameFieldsProcessorConfig), nil
+	case FrameProcessorTypeComputeField:
+		if config.ComputeFieldProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewComputeFieldFrameProcessor(*config.ComputeFieldProcessorConfig)
+	case FrameProcessorTypeLabels:
+		if config.LabelsProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewLabelsFrameProcessor(*config.LabelsProcessorConfig), nil
+	case FrameProcessorTypeAggregate:
+		if config.AggregateProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		storage := f.AggregateStorage
+		if storage == nil {
+			storage = NewAggregateStorage()
+		}
+		return NewAggregateFrameProcessor(*config.AggregateProcessorConfig, storage)
 	default:
 		return nil, fmt.Errorf("unknown processor type: %s", config.Type)
 	}
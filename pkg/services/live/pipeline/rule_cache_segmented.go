@@ -9,6 +9,9 @@ import (
 	"github.com/grafana/grafana/pkg/services/live/pipeline/tree"
 )
 
+// ruleCacheRefreshInterval is how often cached rules are rebuilt from storage.
+const ruleCacheRefreshInterval = 20 * time.Second
+
 // CacheSegmentedTree provides a fast access to channel rule configuration.
 type CacheSegmentedTree struct {
 	radixMu     sync.RWMutex
@@ -39,7 +42,7 @@ func (s *CacheSegmentedTree) updatePeriodically() {
 				logger.Error("Error filling orgId", "error", err, "orgId", orgID)
 			}
 		}
-		time.Sleep(20 * time.Second)
+		time.Sleep(ruleCacheRefreshInterval)
 	}
 }
 
@@ -81,3 +84,15 @@ func (s *CacheSegmentedTree) Get(orgID int64, channel string) (*LiveChannelRule,
 	}
 	return nodeValue.Handler.(*LiveChannelRule), true, nil
 }
+
+// Invalidate rebuilds cached rules of the organization, i.e. after rules were
+// changed in storage. Rules which were not cached yet are built on first use.
+func (s *CacheSegmentedTree) Invalidate(orgID int64) error {
+	s.radixMu.RLock()
+	_, ok := s.radix[orgID]
+	s.radixMu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return s.fillOrg(orgID)
+}
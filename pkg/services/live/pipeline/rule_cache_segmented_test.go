@@ -53,6 +53,44 @@ func TestStorage_Get(t *testing.T) {
 	require.Equal(t, "stream/boom:er", rule.Pattern)
 }
 
+type patternsBuilder struct {
+	patterns []string
+}
+
+func (b *patternsBuilder) BuildRules(_ context.Context, orgID int64) ([]*LiveChannelRule, error) {
+	rules := make([]*LiveChannelRule, 0, len(b.patterns))
+	for _, p := range b.patterns {
+		rules = append(rules, &LiveChannelRule{OrgId: orgID, Pattern: p})
+	}
+	return rules, nil
+}
+
+func TestStorage_Invalidate(t *testing.T) {
+	builder := &patternsBuilder{patterns: []string{"stream/test/:metric"}}
+	s := NewCacheSegmentedTree(builder)
+
+	rule, ok, err := s.Get(1, "stream/test/cpu")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "stream/test/:metric", rule.Pattern)
+
+	builder.patterns = []string{"stream/test/cpu"}
+	rule, ok, err = s.Get(1, "stream/test/cpu")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "stream/test/:metric", rule.Pattern, "rules are cached")
+
+	require.NoError(t, s.Invalidate(1))
+	rule, ok, err = s.Get(1, "stream/test/cpu")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "stream/test/cpu", rule.Pattern)
+
+	_, ok, err = s.Get(1, "stream/test/mem")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
 func BenchmarkRuleGet(b *testing.B) {
 	s := NewCacheSegmentedTree(&testBuilder{})
 	for i := 0; i < b.N; i++ {
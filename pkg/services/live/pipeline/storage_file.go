@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 
@@ -68,11 +69,11 @@ func (f *FileStorage) CreateWriteConfig(ctx context.Context, orgID int64, cmd Wr
 
 	ok, reason := backend.Valid()
 	if !ok {
-		return WriteConfig{}, fmt.Errorf("invalid write config: %s", reason)
+		return WriteConfig{}, fmt.Errorf("%w: %s", ErrInvalidWriteConfig, reason)
 	}
 	for _, existingBackend := range writeConfigs.Configs {
 		if uidMatch(orgID, backend.UID, existingBackend) {
-			return WriteConfig{}, fmt.Errorf("backend already exists in org: %s", backend.UID)
+			return WriteConfig{}, fmt.Errorf("%w in org: %s", ErrWriteConfigExists, backend.UID)
 		}
 	}
 	writeConfigs.Configs = append(writeConfigs.Configs, backend)
@@ -100,7 +101,7 @@ func (f *FileStorage) UpdateWriteConfig(ctx context.Context, orgID int64, cmd Wr
 
 	ok, reason := backend.Valid()
 	if !ok {
-		return WriteConfig{}, fmt.Errorf("invalid channel rule: %s", reason)
+		return WriteConfig{}, fmt.Errorf("%w: %s", ErrInvalidWriteConfig, reason)
 	}
 
 	index := -1
@@ -114,14 +115,26 @@ func (f *FileStorage) UpdateWriteConfig(ctx context.Context, orgID int64, cmd Wr
 	if index > -1 {
 		writeConfigs.Configs[index] = backend
 	} else {
-		return f.CreateWriteConfig(ctx, orgID, WriteConfigCreateCmd(cmd))
+		return f.CreateWriteConfig(ctx, orgID, WriteConfigCreateCmd{
+			UID:            cmd.UID,
+			Settings:       cmd.Settings,
+			SecureSettings: cmd.SecureSettings,
+		})
 	}
 
 	err = f.saveWriteConfigs(orgID, writeConfigs)
 	return backend, err
 }
 
-func (f *FileStorage) DeleteWriteConfig(_ context.Context, orgID int64, cmd WriteConfigDeleteCmd) error {
+func (f *FileStorage) DeleteWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigDeleteCmd) error {
+	rules, err := f.ListChannelRules(ctx, orgID)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := checkWriteConfigUnused(rules, cmd.UID); err != nil {
+		return err
+	}
+
 	writeConfigs, err := f.readWriteConfigs()
 	if err != nil {
 		return fmt.Errorf("can't read write configs: %w", err)
@@ -138,7 +151,7 @@ func (f *FileStorage) DeleteWriteConfig(_ context.Context, orgID int64, cmd Writ
 	if index > -1 {
 		writeConfigs.Configs = removeWriteConfigByIndex(writeConfigs.Configs, index)
 	} else {
-		return fmt.Errorf("write config not found")
+		return ErrWriteConfigNotFound
 	}
 
 	return f.saveWriteConfigs(orgID, writeConfigs)
@@ -172,11 +185,11 @@ func (f *FileStorage) CreateChannelRule(_ context.Context, orgID int64, cmd Chan
 
 	ok, reason := rule.Valid()
 	if !ok {
-		return rule, fmt.Errorf("invalid channel rule: %s", reason)
+		return rule, fmt.Errorf("%w: %s", ErrInvalidChannelRule, reason)
 	}
 	for _, existingRule := range channelRules.Rules {
 		if patternMatch(orgID, rule.Pattern, existingRule) {
-			return rule, fmt.Errorf("pattern already exists in org: %s", rule.Pattern)
+			return rule, fmt.Errorf("%w in org: %s", ErrChannelRuleExists, rule.Pattern)
 		}
 	}
 	channelRules.Rules = append(channelRules.Rules, rule)
@@ -206,7 +219,7 @@ func (f *FileStorage) UpdateChannelRule(ctx context.Context, orgID int64, cmd Ch
 
 	ok, reason := rule.Valid()
 	if !ok {
-		return rule, fmt.Errorf("invalid channel rule: %s", reason)
+		return rule, fmt.Errorf("%w: %s", ErrInvalidChannelRule, reason)
 	}
 
 	index := -1
@@ -220,7 +233,10 @@ func (f *FileStorage) UpdateChannelRule(ctx context.Context, orgID int64, cmd Ch
 	if index > -1 {
 		channelRules.Rules[index] = rule
 	} else {
-		return f.CreateChannelRule(ctx, orgID, ChannelRuleCreateCmd(cmd))
+		return f.CreateChannelRule(ctx, orgID, ChannelRuleCreateCmd{
+			Pattern:  cmd.Pattern,
+			Settings: cmd.Settings,
+		})
 	}
 
 	err = f.saveChannelRules(orgID, channelRules)
@@ -254,7 +270,7 @@ func (f *FileStorage) readRules() (ChannelRules, error) {
 func (f *FileStorage) saveChannelRules(orgID int64, rules ChannelRules) error {
 	ok, reason := checkRulesValid(orgID, rules.Rules)
 	if !ok {
-		return errors.New(reason)
+		return fmt.Errorf("%w: %s", ErrInvalidChannelRule, reason)
 	}
 	ruleFile := f.ruleFilePath()
 	// Safe to ignore gosec warning G304.
@@ -290,12 +306,17 @@ func (f *FileStorage) DeleteChannelRule(_ context.Context, orgID int64, cmd Chan
 	if index > -1 {
 		channelRules.Rules = removeChannelRuleByIndex(channelRules.Rules, index)
 	} else {
-		return fmt.Errorf("rule not found")
+		return ErrChannelRuleNotFound
 	}
 
 	return f.saveChannelRules(orgID, channelRules)
 }
 
+// ListChannelRuleVersions is not supported since rule history is not kept in a file.
+func (f *FileStorage) ListChannelRuleVersions(_ context.Context, _ int64, _ ChannelRuleVersionsCmd) ([]ChannelRuleVersion, error) {
+	return nil, errors.New("channel rule versions are not supported by file storage")
+}
+
 func removeWriteConfigByIndex(s []WriteConfig, index int) []WriteConfig {
 	return append(s[:index], s[index+1:]...)
 }
@@ -0,0 +1,498 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// SQLStorage keeps channel rules and write configs in the database. Every change
+// of a channel rule is also saved as a new version to keep the rule history.
+type SQLStorage struct {
+	store          db.DB
+	secretsService secrets.Service
+}
+
+func NewSQLStorage(store db.DB, secretsService secrets.Service) *SQLStorage {
+	return &SQLStorage{store: store, secretsService: secretsService}
+}
+
+type channelRuleRecord struct {
+	ID       int64     `xorm:"pk autoincr 'id'"`
+	OrgID    int64     `xorm:"'org_id'"`
+	Pattern  string    `xorm:"'pattern'"`
+	Version  int64     `xorm:"'version'"`
+	Settings string    `xorm:"'settings'"`
+	Created  time.Time `xorm:"'created'"`
+	Updated  time.Time `xorm:"'updated'"`
+}
+
+func (r channelRuleRecord) TableName() string {
+	return "live_channel_rule"
+}
+
+func (r channelRuleRecord) toChannelRule() (ChannelRule, error) {
+	var settings ChannelRuleSettings
+	if err := json.Unmarshal([]byte(r.Settings), &settings); err != nil {
+		return ChannelRule{}, fmt.Errorf("can't unmarshal settings of channel rule %s: %w", r.Pattern, err)
+	}
+	return ChannelRule{
+		OrgId:    r.OrgID,
+		Pattern:  r.Pattern,
+		Version:  r.Version,
+		Settings: settings,
+	}, nil
+}
+
+type channelRuleVersionRecord struct {
+	ID        int64     `xorm:"pk autoincr 'id'"`
+	OrgID     int64     `xorm:"'org_id'"`
+	Pattern   string    `xorm:"'pattern'"`
+	Version   int64     `xorm:"'version'"`
+	Settings  string    `xorm:"'settings'"`
+	Deleted   bool      `xorm:"'deleted'"`
+	Created   time.Time `xorm:"'created'"`
+	CreatedBy int64     `xorm:"'created_by'"`
+}
+
+func (r channelRuleVersionRecord) TableName() string {
+	return "live_channel_rule_version"
+}
+
+type writeConfigRecord struct {
+	ID             int64     `xorm:"pk autoincr 'id'"`
+	OrgID          int64     `xorm:"'org_id'"`
+	UID            string    `xorm:"'uid'"`
+	Version        int64     `xorm:"'version'"`
+	Settings       string    `xorm:"'settings'"`
+	SecureSettings string    `xorm:"'secure_settings'"`
+	Created        time.Time `xorm:"'created'"`
+	Updated        time.Time `xorm:"'updated'"`
+}
+
+func (r writeConfigRecord) TableName() string {
+	return "live_write_config"
+}
+
+func (r writeConfigRecord) toWriteConfig() (WriteConfig, error) {
+	var settings WriteSettings
+	if err := json.Unmarshal([]byte(r.Settings), &settings); err != nil {
+		return WriteConfig{}, fmt.Errorf("can't unmarshal settings of write config %s: %w", r.UID, err)
+	}
+	var secureSettings map[string][]byte
+	if r.SecureSettings != "" {
+		if err := json.Unmarshal([]byte(r.SecureSettings), &secureSettings); err != nil {
+			return WriteConfig{}, fmt.Errorf("can't unmarshal secure settings of write config %s: %w", r.UID, err)
+		}
+	}
+	return WriteConfig{
+		OrgId:          r.OrgID,
+		UID:            r.UID,
+		Version:        r.Version,
+		Settings:       settings,
+		SecureSettings: secureSettings,
+	}, nil
+}
+
+func (s *SQLStorage) ListWriteConfigs(ctx context.Context, orgID int64) ([]WriteConfig, error) {
+	var records []writeConfigRecord
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("uid").Find(&records)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't list write configs: %w", err)
+	}
+	writeConfigs := make([]WriteConfig, 0, len(records))
+	for _, r := range records {
+		writeConfig, err := r.toWriteConfig()
+		if err != nil {
+			return nil, err
+		}
+		writeConfigs = append(writeConfigs, writeConfig)
+	}
+	return writeConfigs, nil
+}
+
+func (s *SQLStorage) GetWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigGetCmd) (WriteConfig, bool, error) {
+	var record writeConfigRecord
+	var ok bool
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		ok, err = sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Get(&record)
+		return err
+	})
+	if err != nil {
+		return WriteConfig{}, false, fmt.Errorf("can't get write config: %w", err)
+	}
+	if !ok {
+		return WriteConfig{}, false, nil
+	}
+	writeConfig, err := record.toWriteConfig()
+	return writeConfig, err == nil, err
+}
+
+func (s *SQLStorage) CreateWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigCreateCmd) (WriteConfig, error) {
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	}
+	writeConfig, err := s.newWriteConfig(ctx, orgID, cmd.UID, cmd.Settings, cmd.SecureSettings)
+	if err != nil {
+		return WriteConfig{}, err
+	}
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		return s.insertWriteConfig(sess, &writeConfig)
+	})
+	return writeConfig, err
+}
+
+func (s *SQLStorage) UpdateWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigUpdateCmd) (WriteConfig, error) {
+	writeConfig, err := s.newWriteConfig(ctx, orgID, cmd.UID, cmd.Settings, cmd.SecureSettings)
+	if err != nil {
+		return WriteConfig{}, err
+	}
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var existing writeConfigRecord
+		ok, err := sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if cmd.Version > 0 {
+				return ErrWriteConfigNotFound
+			}
+			// Same as with file storage, a missing write config is created.
+			return s.insertWriteConfig(sess, &writeConfig)
+		}
+		if cmd.Version > 0 && cmd.Version != existing.Version {
+			return fmt.Errorf("%w: write config %s has version %d", ErrVersionConflict, cmd.UID, existing.Version)
+		}
+		record, err := newWriteConfigRecord(writeConfig)
+		if err != nil {
+			return err
+		}
+		record.Version = existing.Version + 1
+		// The version condition protects from concurrent updates.
+		updated, err := sess.Where("id = ? AND version = ?", existing.ID, existing.Version).
+			Cols("version", "settings", "secure_settings", "updated").Update(&record)
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return fmt.Errorf("%w: write config %s was changed concurrently", ErrVersionConflict, cmd.UID)
+		}
+		writeConfig.Version = record.Version
+		return nil
+	})
+	return writeConfig, err
+}
+
+func (s *SQLStorage) DeleteWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigDeleteCmd) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var records []channelRuleRecord
+		if err := sess.Where("org_id = ?", orgID).Find(&records); err != nil {
+			return err
+		}
+		rules := make([]ChannelRule, 0, len(records))
+		for _, r := range records {
+			rule, err := r.toChannelRule()
+			if err != nil {
+				return err
+			}
+			rules = append(rules, rule)
+		}
+		if err := checkWriteConfigUnused(rules, cmd.UID); err != nil {
+			return err
+		}
+		deleted, err := sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Delete(&writeConfigRecord{})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return ErrWriteConfigNotFound
+		}
+		return nil
+	})
+}
+
+// newWriteConfig encrypts secure settings and validates the resulting write config.
+func (s *SQLStorage) newWriteConfig(ctx context.Context, orgID int64, uid string, settings WriteSettings, secureSettings map[string]string) (WriteConfig, error) {
+	encrypted, err := s.secretsService.EncryptJsonData(ctx, secureSettings, secrets.WithoutScope())
+	if err != nil {
+		return WriteConfig{}, fmt.Errorf("error encrypting data: %w", err)
+	}
+	writeConfig := WriteConfig{
+		OrgId:          orgID,
+		UID:            uid,
+		Settings:       settings,
+		SecureSettings: encrypted,
+	}
+	if ok, reason := writeConfig.Valid(); !ok {
+		return WriteConfig{}, fmt.Errorf("%w: %s", ErrInvalidWriteConfig, reason)
+	}
+	return writeConfig, nil
+}
+
+func (s *SQLStorage) insertWriteConfig(sess *db.Session, writeConfig *WriteConfig) error {
+	ok, err := sess.Exist(&writeConfigRecord{OrgID: writeConfig.OrgId, UID: writeConfig.UID})
+	if err != nil {
+		return err
+	}
+	if ok {
+		return fmt.Errorf("%w in org: %s", ErrWriteConfigExists, writeConfig.UID)
+	}
+	record, err := newWriteConfigRecord(*writeConfig)
+	if err != nil {
+		return err
+	}
+	record.Version = 1
+	record.Created = record.Updated
+	if _, err := sess.Insert(&record); err != nil {
+		if s.store.GetDialect().IsUniqueConstraintViolation(err) {
+			return fmt.Errorf("%w in org: %s", ErrWriteConfigExists, writeConfig.UID)
+		}
+		return err
+	}
+	writeConfig.Version = record.Version
+	return nil
+}
+
+func newWriteConfigRecord(writeConfig WriteConfig) (writeConfigRecord, error) {
+	settings, err := json.Marshal(writeConfig.Settings)
+	if err != nil {
+		return writeConfigRecord{}, fmt.Errorf("can't marshal write config settings: %w", err)
+	}
+	secureSettings, err := json.Marshal(writeConfig.SecureSettings)
+	if err != nil {
+		return writeConfigRecord{}, fmt.Errorf("can't marshal write config secure settings: %w", err)
+	}
+	return writeConfigRecord{
+		OrgID:          writeConfig.OrgId,
+		UID:            writeConfig.UID,
+		Settings:       string(settings),
+		SecureSettings: string(secureSettings),
+		Updated:        time.Now(),
+	}, nil
+}
+
+func (s *SQLStorage) ListChannelRules(ctx context.Context, orgID int64) ([]ChannelRule, error) {
+	var records []channelRuleRecord
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("pattern").Find(&records)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't list channel rules: %w", err)
+	}
+	rules := make([]ChannelRule, 0, len(records))
+	for _, r := range records {
+		rule, err := r.toChannelRule()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (s *SQLStorage) CreateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleCreateCmd) (ChannelRule, error) {
+	rule := ChannelRule{
+		OrgId:    orgID,
+		Pattern:  cmd.Pattern,
+		Settings: cmd.Settings,
+	}
+	if ok, reason := rule.Valid(); !ok {
+		return rule, fmt.Errorf("%w: %s", ErrInvalidChannelRule, reason)
+	}
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		ok, err := sess.Exist(&channelRuleRecord{OrgID: orgID, Pattern: cmd.Pattern})
+		if err != nil {
+			return err
+		}
+		if ok {
+			return fmt.Errorf("%w in org: %s", ErrChannelRuleExists, cmd.Pattern)
+		}
+		return s.saveChannelRule(sess, &rule, nil, cmd.UserID)
+	})
+	return rule, err
+}
+
+func (s *SQLStorage) UpdateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleUpdateCmd) (ChannelRule, error) {
+	rule := ChannelRule{
+		OrgId:    orgID,
+		Pattern:  cmd.Pattern,
+		Settings: cmd.Settings,
+	}
+	if ok, reason := rule.Valid(); !ok {
+		return rule, fmt.Errorf("%w: %s", ErrInvalidChannelRule, reason)
+	}
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var existing channelRuleRecord
+		ok, err := sess.Where("org_id = ? AND pattern = ?", orgID, cmd.Pattern).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if cmd.Version > 0 {
+				return ErrChannelRuleNotFound
+			}
+			// Same as with file storage, a missing rule is created.
+			return s.saveChannelRule(sess, &rule, nil, cmd.UserID)
+		}
+		if cmd.Version > 0 && cmd.Version != existing.Version {
+			return fmt.Errorf("%w: channel rule %s has version %d", ErrVersionConflict, cmd.Pattern, existing.Version)
+		}
+		return s.saveChannelRule(sess, &rule, &existing, cmd.UserID)
+	})
+	return rule, err
+}
+
+func (s *SQLStorage) DeleteChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleDeleteCmd) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var existing channelRuleRecord
+		ok, err := sess.Where("org_id = ? AND pattern = ?", orgID, cmd.Pattern).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrChannelRuleNotFound
+		}
+		if _, err := sess.ID(existing.ID).Delete(&channelRuleRecord{}); err != nil {
+			return err
+		}
+		// Deletion is kept in the history as well, so that the rule can be restored.
+		version, err := lastChannelRuleVersion(sess, orgID, cmd.Pattern)
+		if err != nil {
+			return err
+		}
+		return s.insertChannelRuleVersion(sess, channelRuleVersionRecord{
+			OrgID:     orgID,
+			Pattern:   cmd.Pattern,
+			Version:   version + 1,
+			Settings:  existing.Settings,
+			Deleted:   true,
+			Created:   time.Now(),
+			CreatedBy: cmd.UserID,
+		})
+	})
+}
+
+// ListChannelRuleVersions returns the history of the channel rule, the latest version first.
+func (s *SQLStorage) ListChannelRuleVersions(ctx context.Context, orgID int64, cmd ChannelRuleVersionsCmd) ([]ChannelRuleVersion, error) {
+	var records []channelRuleVersionRecord
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND pattern = ?", orgID, cmd.Pattern).Desc("version").Find(&records)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't list channel rule versions: %w", err)
+	}
+	versions := make([]ChannelRuleVersion, 0, len(records))
+	for _, r := range records {
+		var settings ChannelRuleSettings
+		if err := json.Unmarshal([]byte(r.Settings), &settings); err != nil {
+			return nil, fmt.Errorf("can't unmarshal settings of channel rule %s version %d: %w", r.Pattern, r.Version, err)
+		}
+		versions = append(versions, ChannelRuleVersion{
+			Pattern:   r.Pattern,
+			Version:   r.Version,
+			Settings:  settings,
+			Deleted:   r.Deleted,
+			Created:   r.Created,
+			CreatedBy: r.CreatedBy,
+		})
+	}
+	return versions, nil
+}
+
+// saveChannelRule inserts the rule, or updates the existing record if it is not nil,
+// and adds the new version to the rule history.
+func (s *SQLStorage) saveChannelRule(sess *db.Session, rule *ChannelRule, existing *channelRuleRecord, userID int64) error {
+	if err := checkOrgChannelRules(sess, *rule); err != nil {
+		return err
+	}
+	settings, err := json.Marshal(rule.Settings)
+	if err != nil {
+		return fmt.Errorf("can't marshal channel rule settings: %w", err)
+	}
+	// Versions continue after the history of a deleted rule with the same pattern.
+	version, err := lastChannelRuleVersion(sess, rule.OrgId, rule.Pattern)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	record := channelRuleRecord{
+		OrgID:    rule.OrgId,
+		Pattern:  rule.Pattern,
+		Version:  version + 1,
+		Settings: string(settings),
+		Created:  now,
+		Updated:  now,
+	}
+	if existing == nil {
+		if _, err := sess.Insert(&record); err != nil {
+			if s.store.GetDialect().IsUniqueConstraintViolation(err) {
+				return fmt.Errorf("%w in org: %s", ErrChannelRuleExists, rule.Pattern)
+			}
+			return err
+		}
+	} else {
+		// The version condition protects from concurrent updates.
+		updated, err := sess.Where("id = ? AND version = ?", existing.ID, existing.Version).
+			Cols("version", "settings", "updated").Update(&record)
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return fmt.Errorf("%w: channel rule %s was changed concurrently", ErrVersionConflict, rule.Pattern)
+		}
+	}
+	rule.Version = record.Version
+	return s.insertChannelRuleVersion(sess, channelRuleVersionRecord{
+		OrgID:     rule.OrgId,
+		Pattern:   rule.Pattern,
+		Version:   record.Version,
+		Settings:  record.Settings,
+		Created:   now,
+		CreatedBy: userID,
+	})
+}
+
+func (s *SQLStorage) insertChannelRuleVersion(sess *db.Session, record channelRuleVersionRecord) error {
+	if _, err := sess.Insert(&record); err != nil {
+		if s.store.GetDialect().IsUniqueConstraintViolation(err) {
+			return fmt.Errorf("%w: channel rule %s was changed concurrently", ErrVersionConflict, record.Pattern)
+		}
+		return err
+	}
+	return nil
+}
+
+func lastChannelRuleVersion(sess *db.Session, orgID int64, pattern string) (int64, error) {
+	var version int64
+	_, err := sess.SQL("SELECT COALESCE(MAX(version), 0) FROM live_channel_rule_version WHERE org_id = ? AND pattern = ?", orgID, pattern).Get(&version)
+	if err != nil {
+		return 0, fmt.Errorf("can't get last version of channel rule %s: %w", pattern, err)
+	}
+	return version, nil
+}
+
+// checkOrgChannelRules checks the rule does not conflict with other rules of the organization.
+func checkOrgChannelRules(sess *db.Session, rule ChannelRule) error {
+	var patterns []string
+	err := sess.Table("live_channel_rule").Where("org_id = ? AND pattern <> ?", rule.OrgId, rule.Pattern).Cols("pattern").Find(&patterns)
+	if err != nil {
+		return err
+	}
+	rules := make([]ChannelRule, 0, len(patterns)+1)
+	for _, p := range patterns {
+		rules = append(rules, ChannelRule{OrgId: rule.OrgId, Pattern: p})
+	}
+	rules = append(rules, rule)
+	if ok, reason := checkRulesValid(rule.OrgId, rules); !ok {
+		return fmt.Errorf("%w: %s", ErrInvalidChannelRule, reason)
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func setupTestSQLStorage(t *testing.T) *SQLStorage {
+	t.Helper()
+	return NewSQLStorage(db.InitTestDB(t), secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore()))
+}
+
+func managedStreamRuleSettings() ChannelRuleSettings {
+	return ChannelRuleSettings{
+		Converter: &ConverterConfig{Type: ConverterTypeJsonAuto},
+		FrameOutputters: []*FrameOutputterConfig{
+			{Type: FrameOutputTypeManagedStream},
+		},
+	}
+}
+
+func TestIntegrationSQLStorage_ChannelRules(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	s := setupTestSQLStorage(t)
+
+	rule, err := s.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{
+		Pattern:  "stream/test/:metric",
+		Settings: managedStreamRuleSettings(),
+		UserID:   10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rule.Version)
+
+	t.Run("rules are listed per organization", func(t *testing.T) {
+		rules, err := s.ListChannelRules(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		require.Equal(t, "stream/test/:metric", rules[0].Pattern)
+		require.Equal(t, ConverterTypeJsonAuto, rules[0].Settings.Converter.Type)
+
+		rules, err = s.ListChannelRules(ctx, 2)
+		require.NoError(t, err)
+		require.Empty(t, rules)
+	})
+
+	t.Run("invalid rules are rejected", func(t *testing.T) {
+		_, err := s.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{Pattern: "stream/test/:metric"})
+		require.ErrorIs(t, err, ErrChannelRuleExists)
+
+		_, err = s.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{
+			Pattern:  "stream/unknown",
+			Settings: ChannelRuleSettings{Converter: &ConverterConfig{Type: "unknown"}},
+		})
+		require.ErrorIs(t, err, ErrInvalidChannelRule)
+
+		_, err = s.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{
+			Pattern:  "stream/unknown",
+			Settings: ChannelRuleSettings{DataOutputters: []*DataOutputterConfig{{Type: "unknown"}}},
+		})
+		require.ErrorIs(t, err, ErrInvalidChannelRule)
+
+		// Conflicts with the wildcard of stream/test/:metric.
+		_, err = s.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{Pattern: "stream/test/:other"})
+		require.ErrorIs(t, err, ErrInvalidChannelRule)
+	})
+
+	t.Run("updates are versioned", func(t *testing.T) {
+		settings := managedStreamRuleSettings()
+		settings.Converter.Type = ConverterTypeInfluxAuto
+		updated, err := s.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{
+			Pattern:  "stream/test/:metric",
+			Settings: settings,
+			Version:  1,
+			UserID:   20,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), updated.Version)
+
+		_, err = s.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{
+			Pattern:  "stream/test/:metric",
+			Settings: settings,
+			Version:  1,
+		})
+		require.ErrorIs(t, err, ErrVersionConflict)
+
+		_, err = s.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{
+			Pattern: "stream/missing",
+			Version: 1,
+		})
+		require.ErrorIs(t, err, ErrChannelRuleNotFound)
+
+		versions, err := s.ListChannelRuleVersions(ctx, 1, ChannelRuleVersionsCmd{Pattern: "stream/test/:metric"})
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		require.Equal(t, int64(2), versions[0].Version)
+		require.Equal(t, ConverterTypeInfluxAuto, versions[0].Settings.Converter.Type)
+		require.Equal(t, int64(20), versions[0].CreatedBy)
+		require.Equal(t, int64(1), versions[1].Version)
+		require.Equal(t, ConverterTypeJsonAuto, versions[1].Settings.Converter.Type)
+		require.Equal(t, int64(10), versions[1].CreatedBy)
+	})
+
+	t.Run("deletion is kept in history", func(t *testing.T) {
+		err := s.DeleteChannelRule(ctx, 1, ChannelRuleDeleteCmd{Pattern: "stream/test/:metric", UserID: 30})
+		require.NoError(t, err)
+
+		err = s.DeleteChannelRule(ctx, 1, ChannelRuleDeleteCmd{Pattern: "stream/test/:metric"})
+		require.ErrorIs(t, err, ErrChannelRuleNotFound)
+
+		rules, err := s.ListChannelRules(ctx, 1)
+		require.NoError(t, err)
+		require.Empty(t, rules)
+
+		versions, err := s.ListChannelRuleVersions(ctx, 1, ChannelRuleVersionsCmd{Pattern: "stream/test/:metric"})
+		require.NoError(t, err)
+		require.Len(t, versions, 3)
+		require.True(t, versions[0].Deleted)
+		require.Equal(t, int64(30), versions[0].CreatedBy)
+
+		// Versions of a recreated rule continue the history.
+		rule, err := s.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{
+			Pattern:  "stream/test/:metric",
+			Settings: managedStreamRuleSettings(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(4), rule.Version)
+	})
+}
+
+func TestIntegrationSQLStorage_WriteConfigs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	s := setupTestSQLStorage(t)
+
+	created, err := s.CreateWriteConfig(ctx, 1, WriteConfigCreateCmd{
+		Settings:       WriteSettings{Endpoint: "http://localhost:9090/api/v1/write"},
+		SecureSettings: map[string]string{"basicAuthPassword": "secret"},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, created.UID)
+	require.Equal(t, int64(1), created.Version)
+
+	writeConfig, ok, err := s.GetWriteConfig(ctx, 1, WriteConfigGetCmd{UID: created.UID})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "http://localhost:9090/api/v1/write", writeConfig.Settings.Endpoint)
+	secureSettings, err := s.secretsService.DecryptJsonData(ctx, writeConfig.SecureSettings)
+	require.NoError(t, err)
+	require.Equal(t, "secret", secureSettings["basicAuthPassword"])
+
+	_, ok, err = s.GetWriteConfig(ctx, 2, WriteConfigGetCmd{UID: created.UID})
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = s.CreateWriteConfig(ctx, 1, WriteConfigCreateCmd{UID: created.UID, Settings: writeConfig.Settings})
+	require.ErrorIs(t, err, ErrWriteConfigExists)
+
+	_, err = s.CreateWriteConfig(ctx, 1, WriteConfigCreateCmd{})
+	require.ErrorIs(t, err, ErrInvalidWriteConfig)
+
+	updated, err := s.UpdateWriteConfig(ctx, 1, WriteConfigUpdateCmd{
+		UID:      created.UID,
+		Settings: WriteSettings{Endpoint: "http://localhost:9091/api/v1/write"},
+		Version:  1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), updated.Version)
+
+	_, err = s.UpdateWriteConfig(ctx, 1, WriteConfigUpdateCmd{
+		UID:      created.UID,
+		Settings: WriteSettings{Endpoint: "http://localhost:9092/api/v1/write"},
+		Version:  1,
+	})
+	require.ErrorIs(t, err, ErrVersionConflict)
+
+	writeConfigs, err := s.ListWriteConfigs(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, writeConfigs, 1)
+	require.Equal(t, "http://localhost:9091/api/v1/write", writeConfigs[0].Settings.Endpoint)
+
+	t.Run("write configs used by channel rules cannot be deleted", func(t *testing.T) {
+		_, err := s.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{
+			Pattern: "stream/test/remote_write",
+			Settings: ChannelRuleSettings{
+				FrameOutputters: []*FrameOutputterConfig{
+					{Type: FrameOutputTypeManagedStream},
+					{
+						Type: FrameOutputTypeConditional,
+						ConditionalOutputConfig: &ConditionalOutputConfig{
+							Outputter: &FrameOutputterConfig{
+								Type:                    FrameOutputTypeRemoteWrite,
+								RemoteWriteOutputConfig: &RemoteWriteOutputConfig{UID: created.UID},
+							},
+						},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.ErrorIs(t, s.DeleteWriteConfig(ctx, 1, WriteConfigDeleteCmd{UID: created.UID}), ErrWriteConfigInUse)
+
+		require.NoError(t, s.DeleteChannelRule(ctx, 1, ChannelRuleDeleteCmd{Pattern: "stream/test/remote_write"}))
+	})
+
+	require.NoError(t, s.DeleteWriteConfig(ctx, 1, WriteConfigDeleteCmd{UID: created.UID}))
+	require.ErrorIs(t, s.DeleteWriteConfig(ctx, 1, WriteConfigDeleteCmd{UID: created.UID}), ErrWriteConfigNotFound)
+}
@@ -57,7 +57,11 @@ func (g *Gateway) Handle(ctx *contextmodel.ReqContext) {
 
 	// TODO Grafana 8: decide which formats to use or keep all.
 	urlValues := ctx.Req.URL.Query()
-	frameFormat := pushurl.FrameFormatFromValues(urlValues)
+	input := convert.Input{
+		Format:       pushurl.InputFormatFromValues(urlValues),
+		FrameFormat:  pushurl.FrameFormatFromValues(urlValues),
+		LabelColumns: pushurl.LabelColumnsFromValues(urlValues),
+	}
 
 	body, err := io.ReadAll(ctx.Req.Body)
 	if err != nil {
@@ -69,13 +73,14 @@ func (g *Gateway) Handle(ctx *contextmodel.ReqContext) {
 		"protocol", "http",
 		"streamId", streamID,
 		"bodyLength", len(body),
-		"frameFormat", frameFormat,
+		"inputFormat", input.Format,
+		"frameFormat", input.FrameFormat,
 	)
 
-	metricFrames, err := g.converter.Convert(body, frameFormat)
+	metricFrames, err := g.converter.ConvertInput(body, input)
 	if err != nil {
-		logger.Error("Error converting metrics", "error", err, "frameFormat", frameFormat)
-		if errors.Is(err, convert.ErrUnsupportedFrameFormat) {
+		logger.Error("Error converting metrics", "error", err, "inputFormat", input.Format, "frameFormat", input.FrameFormat)
+		if errors.Is(err, convert.ErrUnsupportedFrameFormat) || errors.Is(err, convert.ErrUnsupportedInputFormat) {
 			ctx.Resp.WriteHeader(http.StatusBadRequest)
 		} else {
 			ctx.Resp.WriteHeader(http.StatusInternalServerError)
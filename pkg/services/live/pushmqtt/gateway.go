@@ -0,0 +1,394 @@
+package pushmqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	liveDto "github.com/grafana/grafana-plugin-sdk-go/live"
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/log/slogadapter"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/live/convert"
+	"github.com/grafana/grafana/pkg/services/live/pushurl"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	logger = log.New("live.push_mqtt")
+)
+
+const (
+	listenerID = "grafana-live"
+	// publishTimeout is a maximum time to process a single MQTT publication.
+	publishTimeout = 10 * time.Second
+	// reauthenticateInterval is how often tokens of connected clients are checked again,
+	// so that clients are disconnected once their token is revoked or expires.
+	reauthenticateInterval = time.Minute
+)
+
+// Authenticator authenticates service account tokens of MQTT clients.
+type Authenticator interface {
+	Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error)
+}
+
+func ProvideService(cfg *setting.Cfg, live *live.GrafanaLive, authnService authn.Service) *Gateway {
+	return NewGateway(cfg, live, authnService)
+}
+
+// NewGateway creates new Gateway.
+func NewGateway(cfg *setting.Cfg, live *live.GrafanaLive, authenticator Authenticator) *Gateway {
+	return &Gateway{
+		Cfg:           cfg,
+		GrafanaLive:   live,
+		authenticator: authenticator,
+		converter:     convert.NewConverter(),
+		sessions:      map[*mqtt.Client]session{},
+	}
+}
+
+// Gateway is an MQTT listener which receives data from devices and translates it to
+// Grafana Live publications. Clients authenticate with a service account token sent
+// as the CONNECT password and publish into the org of the service account. Tokens are
+// checked again every reauthenticateInterval, clients with a token which is no longer
+// valid are disconnected. Publications are processed depending on the topic:
+//   - stream/<streamId> topics are pushed to managed streams, the same way as
+//     /api/live/push/:streamId does. Input and frame formats are taken from the
+//     gf_live_* user properties of MQTT 5 publications.
+//   - Topics which are Live channels with a pipeline rule are processed by the pipeline.
+//
+// Clients can not subscribe to topics, and publications are never retained or
+// forwarded to other MQTT clients.
+type Gateway struct {
+	Cfg         *setting.Cfg
+	GrafanaLive *live.GrafanaLive
+
+	authenticator Authenticator
+	converter     *convert.Converter
+
+	mu       sync.RWMutex
+	sessions map[*mqtt.Client]session
+	// addr is the address of the listener once it is started.
+	addr string
+}
+
+// IsDisabled returns true if MQTT listener is not enabled.
+func (g *Gateway) IsDisabled() bool {
+	return !g.Cfg.LiveMQTTEnabled
+}
+
+// Run Gateway.
+func (g *Gateway) Run(ctx context.Context) error {
+	server, err := g.start()
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(reauthenticateInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			g.reauthenticate(server)
+		case <-ctx.Done():
+			if err := server.Close(); err != nil {
+				logger.Error("Error closing MQTT server", "error", err)
+			}
+			return ctx.Err()
+		}
+	}
+}
+
+func (g *Gateway) start() (*mqtt.Server, error) {
+	server := mqtt.New(&mqtt.Options{
+		Logger: slog.New(slogadapter.New(logger)),
+	})
+	// Publications are consumed by Grafana, there is nothing to retain.
+	server.Options.Capabilities.RetainAvailable = 0
+
+	if err := server.AddHook(&hook{gateway: g}, nil); err != nil {
+		return nil, err
+	}
+
+	var tlsConfig *tls.Config
+	if g.Cfg.LiveMQTTCertFile != "" || g.Cfg.LiveMQTTCertKey != "" {
+		cert, err := tls.LoadX509KeyPair(g.Cfg.LiveMQTTCertFile, g.Cfg.LiveMQTTCertKey)
+		if err != nil {
+			return nil, fmt.Errorf("could not load MQTT certificate: %w", err)
+		}
+		tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+	listener := listeners.NewTCP(listeners.Config{
+		ID:        listenerID,
+		Address:   g.Cfg.LiveMQTTAddress,
+		TLSConfig: tlsConfig,
+	})
+	if err := server.AddListener(listener); err != nil {
+		return nil, fmt.Errorf("could not start MQTT listener: %w", err)
+	}
+	if err := server.Serve(); err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	g.addr = listener.Address()
+	g.mu.Unlock()
+	logger.Info("Live MQTT Gateway started", "address", listener.Address())
+	return server, nil
+}
+
+// Addr returns the address of the listener, empty until the listener is started.
+func (g *Gateway) Addr() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.addr
+}
+
+// session is the identity of a connected client and the token it was authenticated with.
+type session struct {
+	identity *authn.Identity
+	token    string
+}
+
+func (g *Gateway) authenticate(cl *mqtt.Client, token string) bool {
+	id, ok := g.authenticateToken(cl, token)
+	if !ok {
+		return false
+	}
+	g.mu.Lock()
+	g.sessions[cl] = session{identity: id, token: token}
+	g.mu.Unlock()
+	return true
+}
+
+func (g *Gateway) authenticateToken(cl *mqtt.Client, token string) (*authn.Identity, bool) {
+	if token == "" {
+		return nil, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+	if err != nil {
+		return nil, false
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.RemoteAddr = cl.Net.Remote
+
+	id, err := g.authenticator.Authenticate(ctx, &authn.Request{HTTPRequest: req})
+	if err != nil {
+		logger.Debug("MQTT client authentication failed", "client", cl.ID, "error", err)
+		return nil, false
+	}
+	if !id.GetID().IsNamespace(authn.NamespaceServiceAccount) {
+		logger.Debug("MQTT client is not a service account", "client", cl.ID, "id", id.GetID())
+		return nil, false
+	}
+	return id, true
+}
+
+// reauthenticate checks tokens of connected clients again, the identity of a client
+// is refreshed if its token is still valid, otherwise the client is disconnected.
+func (g *Gateway) reauthenticate(server *mqtt.Server) {
+	g.mu.RLock()
+	sessions := make(map[*mqtt.Client]session, len(g.sessions))
+	for cl, s := range g.sessions {
+		sessions[cl] = s
+	}
+	g.mu.RUnlock()
+
+	for cl, s := range sessions {
+		id, ok := g.authenticateToken(cl, s.token)
+		if !ok {
+			logger.Info("Disconnecting MQTT client with invalid token", "client", cl.ID)
+			g.forget(cl)
+			if err := server.DisconnectClient(cl, packets.ErrNotAuthorized); err != nil {
+				logger.Debug("Error disconnecting MQTT client", "client", cl.ID, "error", err)
+			}
+			continue
+		}
+		g.mu.Lock()
+		if _, ok := g.sessions[cl]; ok {
+			g.sessions[cl] = session{identity: id, token: s.token}
+		}
+		g.mu.Unlock()
+	}
+}
+
+func (g *Gateway) identity(cl *mqtt.Client) (*authn.Identity, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	s, ok := g.sessions[cl]
+	return s.identity, ok
+}
+
+func (g *Gateway) forget(cl *mqtt.Client) {
+	g.mu.Lock()
+	delete(g.sessions, cl)
+	g.mu.Unlock()
+}
+
+// canPublish checks whether the client is allowed to publish into the topic.
+func (g *Gateway) canPublish(ctx context.Context, cl *mqtt.Client, topic string) bool {
+	id, ok := g.identity(cl)
+	if !ok {
+		return false
+	}
+	if _, ok := streamIDFromTopic(topic); ok {
+		return true
+	}
+	if g.GrafanaLive.Pipeline == nil {
+		return false
+	}
+	rule, ok, err := g.GrafanaLive.Pipeline.Get(id.GetOrgID(), topic)
+	if err != nil {
+		logger.Error("Error getting channel rule", "topic", topic, "error", err)
+		return false
+	}
+	if !ok {
+		return false
+	}
+	if rule.PublishAuth != nil {
+		ok, err := rule.PublishAuth.CanPublish(ctx, id)
+		if err != nil {
+			logger.Error("Error checking publish permissions", "topic", topic, "error", err)
+			return false
+		}
+		return ok
+	}
+	return id.HasRole(org.RoleAdmin)
+}
+
+func (g *Gateway) publish(ctx context.Context, cl *mqtt.Client, pk packets.Packet) error {
+	id, ok := g.identity(cl)
+	if !ok {
+		return packets.ErrNotAuthorized
+	}
+	topic := pk.TopicName
+	logger.Debug("Live Push request",
+		"protocol", "mqtt",
+		"topic", topic,
+		"bodyLength", len(pk.Payload),
+	)
+
+	if streamID, ok := streamIDFromTopic(topic); ok {
+		return g.pushStream(ctx, id.GetOrgID(), streamID, pk)
+	}
+
+	ruleFound, err := g.GrafanaLive.Pipeline.ProcessInput(ctx, id.GetOrgID(), topic, pk.Payload)
+	if err != nil {
+		logger.Error("Pipeline input processing error", "error", err, "topic", topic)
+		if errors.Is(err, liveDto.ErrInvalidChannelID) {
+			return packets.ErrTopicNameInvalid
+		}
+		return packets.ErrImplementationSpecificError
+	}
+	if !ruleFound {
+		logger.Error("No conversion rule for a channel", "topic", topic)
+		return packets.ErrTopicNameInvalid
+	}
+	return nil
+}
+
+func (g *Gateway) pushStream(ctx context.Context, orgID int64, streamID string, pk packets.Packet) error {
+	stream, err := g.GrafanaLive.ManagedStreamRunner.GetOrCreateStream(orgID, liveDto.ScopeStream, streamID)
+	if err != nil {
+		logger.Error("Error getting stream", "error", err)
+		return packets.ErrImplementationSpecificError
+	}
+
+	values := url.Values{}
+	for _, p := range pk.Properties.User {
+		values.Add(p.Key, p.Val)
+	}
+	input := convert.Input{
+		Format:       pushurl.InputFormatFromValues(values),
+		FrameFormat:  pushurl.FrameFormatFromValues(values),
+		LabelColumns: pushurl.LabelColumnsFromValues(values),
+	}
+
+	metricFrames, err := g.converter.ConvertInput(pk.Payload, input)
+	if err != nil {
+		logger.Error("Error converting metrics", "error", err, "inputFormat", input.Format, "frameFormat", input.FrameFormat)
+		return packets.ErrPayloadFormatInvalid
+	}
+	for _, mf := range metricFrames {
+		if err := stream.Push(ctx, mf.Key(), mf.Frame()); err != nil {
+			logger.Error("Error pushing frame", "error", err, "streamId", streamID)
+			return packets.ErrImplementationSpecificError
+		}
+	}
+	return nil
+}
+
+// streamIDFromTopic returns the stream ID of stream/<streamId> topics.
+func streamIDFromTopic(topic string) (string, bool) {
+	scope, streamID, ok := strings.Cut(topic, "/")
+	if !ok || scope != liveDto.ScopeStream || streamID == "" || strings.Contains(streamID, "/") {
+		return "", false
+	}
+	return streamID, true
+}
+
+// hook connects the MQTT server to the Gateway.
+type hook struct {
+	mqtt.HookBase
+	gateway *Gateway
+}
+
+func (h *hook) ID() string {
+	return "grafana-live"
+}
+
+func (h *hook) Provides(b byte) bool {
+	switch b {
+	case mqtt.OnConnectAuthenticate, mqtt.OnACLCheck, mqtt.OnPublish, mqtt.OnDisconnect:
+		return true
+	}
+	return false
+}
+
+// OnConnectAuthenticate authenticates clients with a service account token sent as password.
+func (h *hook) OnConnectAuthenticate(cl *mqtt.Client, pk packets.Packet) bool {
+	return h.gateway.authenticate(cl, string(pk.Connect.Password))
+}
+
+// OnACLCheck denies all subscriptions, publications are checked against the
+// identity of the client.
+func (h *hook) OnACLCheck(cl *mqtt.Client, topic string, write bool) bool {
+	if !write {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+	return h.gateway.canPublish(ctx, cl, topic)
+}
+
+// OnPublish processes publications, they are never forwarded to other clients.
+func (h *hook) OnPublish(cl *mqtt.Client, pk packets.Packet) (packets.Packet, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+	if err := h.gateway.publish(ctx, cl, pk); err != nil {
+		return pk, err
+	}
+	return pk, packets.CodeSuccessIgnore
+}
+
+func (h *hook) OnDisconnect(cl *mqtt.Client, _ error, _ bool) {
+	h.gateway.forget(cl)
+}
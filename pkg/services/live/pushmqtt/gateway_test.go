@@ -0,0 +1,189 @@
+package pushmqtt
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type testAuthenticator struct {
+	revoked atomic.Bool
+}
+
+func (a *testAuthenticator) Authenticate(_ context.Context, r *authn.Request) (*authn.Identity, error) {
+	if a.revoked.Load() {
+		return nil, errors.New("revoked token")
+	}
+	switch r.HTTPRequest.Header.Get("Authorization") {
+	case "Bearer sa-token":
+		return &authn.Identity{
+			ID:       authn.NewNamespaceID(authn.NamespaceServiceAccount, 10),
+			OrgID:    2,
+			OrgRoles: map[int64]org.RoleType{2: org.RoleEditor},
+		}, nil
+	case "Bearer user-token":
+		return &authn.Identity{
+			ID:       authn.NewNamespaceID(authn.NamespaceUser, 1),
+			OrgID:    2,
+			OrgRoles: map[int64]org.RoleType{2: org.RoleAdmin},
+		}, nil
+	}
+	return nil, errors.New("invalid token")
+}
+
+type publication struct {
+	orgID   int64
+	channel string
+	data    []byte
+}
+
+func setupTestGateway(t *testing.T) (*Gateway, *mqtt.Server, chan publication) {
+	t.Helper()
+
+	publications := make(chan publication, 10)
+	publisher := func(orgID int64, channel string, data []byte) error {
+		publications <- publication{orgID: orgID, channel: channel, data: data}
+		return nil
+	}
+
+	cfg := setting.NewCfg()
+	cfg.LiveMQTTEnabled = true
+	cfg.LiveMQTTAddress = "127.0.0.1:0"
+	g := NewGateway(cfg, &live.GrafanaLive{
+		ManagedStreamRunner: managedstream.NewRunner(publisher, nil, managedstream.NewMemoryFrameCache(managedstream.HistoryConfig{})),
+	}, &testAuthenticator{})
+	require.False(t, g.IsDisabled())
+
+	server, err := g.start()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+	return g, server, publications
+}
+
+func connect(t *testing.T, g *Gateway, password string) (paho.Client, error) {
+	t.Helper()
+
+	opts := paho.NewClientOptions().
+		AddBroker("tcp://" + g.Addr()).
+		SetClientID(t.Name()).
+		SetUsername("grafana").
+		SetPassword(password).
+		SetAutoReconnect(false).
+		SetConnectRetry(false)
+	client := paho.NewClient(opts)
+	token := client.Connect()
+	if !token.WaitTimeout(5 * time.Second) {
+		return nil, errors.New("connect timeout")
+	}
+	if err := token.Error(); err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() {
+		client.Disconnect(0)
+	})
+	return client, nil
+}
+
+func TestGateway_PushStream(t *testing.T) {
+	g, _, publications := setupTestGateway(t)
+
+	client, err := connect(t, g, "sa-token")
+	require.NoError(t, err)
+
+	token := client.Publish("stream/test", 1, false, "cpu,host=a usage=1.5 1636111200000000000")
+	require.True(t, token.WaitTimeout(5*time.Second))
+	require.NoError(t, token.Error())
+
+	select {
+	case p := <-publications:
+		require.Equal(t, int64(2), p.orgID)
+		require.Equal(t, "stream/test/cpu", p.channel)
+		require.Contains(t, string(p.data), "usage")
+	case <-time.After(5 * time.Second):
+		t.Fatal("no publication")
+	}
+}
+
+func TestGateway_Authentication(t *testing.T) {
+	g, _, _ := setupTestGateway(t)
+
+	t.Run("invalid token", func(t *testing.T) {
+		_, err := connect(t, g, "invalid")
+		require.Error(t, err)
+	})
+
+	t.Run("empty token", func(t *testing.T) {
+		_, err := connect(t, g, "")
+		require.Error(t, err)
+	})
+
+	t.Run("not a service account", func(t *testing.T) {
+		_, err := connect(t, g, "user-token")
+		require.Error(t, err)
+	})
+}
+
+func TestGateway_Reauthenticate(t *testing.T) {
+	g, server, _ := setupTestGateway(t)
+
+	client, err := connect(t, g, "sa-token")
+	require.NoError(t, err)
+
+	g.reauthenticate(server)
+	require.True(t, client.IsConnected())
+
+	g.authenticator.(*testAuthenticator).revoked.Store(true)
+	g.reauthenticate(server)
+	require.Eventually(t, func() bool { return !client.IsConnected() }, 5*time.Second, 10*time.Millisecond)
+	g.mu.RLock()
+	require.Empty(t, g.sessions)
+	g.mu.RUnlock()
+}
+
+func TestGateway_SubscribeDenied(t *testing.T) {
+	g, _, _ := setupTestGateway(t)
+
+	client, err := connect(t, g, "sa-token")
+	require.NoError(t, err)
+
+	token := client.Subscribe("stream/test", 0, nil)
+	require.True(t, token.WaitTimeout(5*time.Second))
+	st, ok := token.(*paho.SubscribeToken)
+	require.True(t, ok)
+	require.Equal(t, byte(0x80), st.Result()["stream/test"])
+}
+
+func TestStreamIDFromTopic(t *testing.T) {
+	tests := []struct {
+		topic    string
+		streamID string
+		ok       bool
+	}{
+		{topic: "stream/test", streamID: "test", ok: true},
+		{topic: "stream/", ok: false},
+		{topic: "stream", ok: false},
+		{topic: "stream/test/cpu", ok: false},
+		{topic: "plugin/test", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			streamID, ok := streamIDFromTopic(tt.topic)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.streamID, streamID)
+		})
+	}
+}
@@ -6,7 +6,9 @@ import (
 )
 
 const (
-	frameFormatParam = "gf_live_frame_format"
+	frameFormatParam  = "gf_live_frame_format"
+	inputFormatParam  = "gf_live_input_format"
+	labelColumnsParam = "gf_live_label_columns"
 )
 
 // FrameFormatFromValues extracts frame format tip from url values.
@@ -17,3 +19,24 @@ func FrameFormatFromValues(values url.Values) string {
 	}
 	return frameFormat
 }
+
+// InputFormatFromValues extracts input format from url values.
+func InputFormatFromValues(values url.Values) string {
+	inputFormat := strings.ToLower(values.Get(inputFormatParam))
+	if inputFormat == "" {
+		inputFormat = "influx"
+	}
+	return inputFormat
+}
+
+// LabelColumnsFromValues extracts comma-separated CSV label columns from url values.
+func LabelColumnsFromValues(values url.Values) []string {
+	var columns []string
+	for _, column := range strings.Split(values.Get(labelColumnsParam), ",") {
+		column = strings.TrimSpace(column)
+		if column != "" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
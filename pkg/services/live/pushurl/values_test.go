@@ -13,3 +13,17 @@ func TestFrameFormatFromValues(t *testing.T) {
 	values.Set(frameFormatParam, "wide")
 	require.Equal(t, "wide", FrameFormatFromValues(values))
 }
+
+func TestInputFormatFromValues(t *testing.T) {
+	values := url.Values{}
+	require.Equal(t, "influx", InputFormatFromValues(values))
+	values.Set(inputFormatParam, "Prometheus")
+	require.Equal(t, "prometheus", InputFormatFromValues(values))
+}
+
+func TestLabelColumnsFromValues(t *testing.T) {
+	values := url.Values{}
+	require.Empty(t, LabelColumnsFromValues(values))
+	values.Set(labelColumnsParam, "host, region,,")
+	require.Equal(t, []string{"host", "region"}, LabelColumnsFromValues(values))
+}
@@ -78,19 +78,24 @@ func (s *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 
 		// TODO Grafana 8: decide which formats to use or keep all.
 		urlValues := r.URL.Query()
-		frameFormat := pushurl.FrameFormatFromValues(urlValues)
+		input := convert.Input{
+			Format:       pushurl.InputFormatFromValues(urlValues),
+			FrameFormat:  pushurl.FrameFormatFromValues(urlValues),
+			LabelColumns: pushurl.LabelColumnsFromValues(urlValues),
+		}
 
 		logger.Debug("Live Push request",
 			"protocol", "ws",
 			"streamId", streamID,
 			"bodyLength", len(body),
-			"frameFormat", frameFormat,
+			"inputFormat", input.Format,
+			"frameFormat", input.FrameFormat,
 			"duration", time.Since(started).String(),
 		)
 
-		metricFrames, err := s.converter.Convert(body, frameFormat)
+		metricFrames, err := s.converter.ConvertInput(body, input)
 		if err != nil {
-			logger.Error("Error converting metrics", "error", err, "frameFormat", frameFormat)
+			logger.Error("Error converting metrics", "error", err, "inputFormat", input.Format, "frameFormat", input.FrameFormat)
 			continue
 		}
 
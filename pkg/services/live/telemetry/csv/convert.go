@@ -0,0 +1,162 @@
+package csv
+
+import (
+	"bytes"
+	stdcsv "encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	influx "github.com/influxdata/line-protocol"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
+)
+
+var _ telemetry.Converter = (*Converter)(nil)
+
+const (
+	// TimeColumn is the column with the time of a line, either in RFC3339 format or in Unix milliseconds.
+	TimeColumn = "time"
+	// NameColumn is the column with the metric name of a line.
+	NameColumn = "name"
+	// DefaultName is the metric name of lines without name column.
+	DefaultName = "csv"
+)
+
+// Converter converts CSV lines to Grafana frames.
+type Converter struct {
+	useLabelsColumn bool
+	labelColumns    map[string]struct{}
+	converter       *telegraf.Converter
+	now             func() time.Time
+}
+
+// ConverterOption ...
+type ConverterOption func(*Converter)
+
+// WithUseLabelsColumn ...
+func WithUseLabelsColumn(enabled bool) ConverterOption {
+	return func(h *Converter) {
+		h.useLabelsColumn = enabled
+	}
+}
+
+// WithLabelColumns sets the columns which values are used as labels instead of fields.
+func WithLabelColumns(columns ...string) ConverterOption {
+	return func(h *Converter) {
+		for _, column := range columns {
+			h.labelColumns[column] = struct{}{}
+		}
+	}
+}
+
+// NewConverter creates new Converter from CSV to Grafana Data Frames. The first line is a header with
+// the names of the columns. Every other line becomes a metric with label columns as labels and all other
+// columns, except time and name, as fields, and metrics are mapped to frames the same way as Influx line
+// protocol. Values of fields are numbers or booleans if they can be parsed as such, strings otherwise,
+// and empty values are skipped.
+func NewConverter(opts ...ConverterOption) *Converter {
+	c := &Converter{
+		labelColumns: map[string]struct{}{},
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.converter = telegraf.NewConverter(
+		telegraf.WithUseLabelsColumn(c.useLabelsColumn),
+		telegraf.WithFloat64Numbers(true),
+	)
+	return c
+}
+
+// Convert metrics.
+func (c *Converter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	r := stdcsv.NewReader(bytes.NewReader(body))
+	r.TrimLeadingSpace = true
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing header: %w", err)
+	}
+
+	// Lines without time get the same time, so that they are put into the same frame.
+	now := c.now()
+	var metrics []influx.Metric
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing metrics: %w", err)
+		}
+		m, err := c.recordToMetric(header, record, now)
+		if err != nil {
+			line, _ := r.FieldPos(0)
+			return nil, fmt.Errorf("error parsing metrics: line %d: %w", line, err)
+		}
+		metrics = append(metrics, m)
+	}
+	return c.converter.ConvertMetrics(metrics)
+}
+
+func (c *Converter) recordToMetric(header []string, record []string, now time.Time) (influx.Metric, error) {
+	name := DefaultName
+	t := now
+	labels := map[string]string{}
+	fields := map[string]any{}
+	for i, column := range header {
+		value := record[i]
+		if value == "" {
+			continue
+		}
+		switch column {
+		case TimeColumn:
+			var err error
+			if t, err = parseTime(value); err != nil {
+				return nil, err
+			}
+			continue
+		case NameColumn:
+			name = value
+			continue
+		}
+		if _, ok := c.labelColumns[column]; ok {
+			labels[column] = value
+			continue
+		}
+		fields[column] = parseValue(value)
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("no fields")
+	}
+	return influx.New(name, labels, fields, t)
+}
+
+func parseTime(value string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected RFC3339 or Unix milliseconds", value)
+	}
+	return t, nil
+}
+
+func parseValue(value string) any {
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+	switch strings.ToLower(value) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return value
+}
@@ -0,0 +1,56 @@
+package csv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConverter_Convert(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	t.Run("wide", func(t *testing.T) {
+		c := NewConverter(WithLabelColumns("host"))
+		c.now = func() time.Time { return now }
+		_, err := c.Convert([]byte("name,host,cpu,ok\ncpu,a,0.5,true\ncpu,b,0.7,false\nmem,a,,\n"))
+		require.Error(t, err) // the last line has no fields.
+
+		frameWrappers, err := c.Convert([]byte("name,host,cpu,ok\ncpu,a,0.5,true\ncpu,b,0.7,false\n"))
+		require.NoError(t, err)
+		require.Len(t, frameWrappers, 1)
+		require.Equal(t, "cpu", frameWrappers[0].Key())
+
+		frame := frameWrappers[0].Frame()
+		require.Len(t, frame.Fields, 5)
+		require.Equal(t, now, frame.Fields[0].At(0))
+		require.Equal(t, "cpu", frame.Fields[1].Name)
+		require.Equal(t, data.Labels{"host": "a"}, frame.Fields[1].Labels)
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[1].Type())
+		require.Equal(t, "ok", frame.Fields[2].Name)
+		require.Equal(t, data.FieldTypeNullableBool, frame.Fields[2].Type())
+		require.Equal(t, data.Labels{"host": "b"}, frame.Fields[3].Labels)
+	})
+
+	t.Run("labels column", func(t *testing.T) {
+		c := NewConverter(WithUseLabelsColumn(true), WithLabelColumns("host"))
+		frameWrappers, err := c.Convert([]byte("time,host,cpu,state\n1600000000000,a,0.5,idle\n2020-09-13T12:26:40Z,b,0.7,busy\n"))
+		require.NoError(t, err)
+		require.Len(t, frameWrappers, 1)
+		require.Equal(t, DefaultName, frameWrappers[0].Key())
+
+		frame := frameWrappers[0].Frame()
+		require.Len(t, frame.Fields, 4)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, "host=a", frame.Fields[0].At(0))
+		require.True(t, time.UnixMilli(1600000000000).Equal(frame.Fields[1].At(0).(time.Time)))
+		require.True(t, time.UnixMilli(1600000000000).Equal(frame.Fields[1].At(1).(time.Time)))
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[3].Type())
+	})
+
+	t.Run("invalid time", func(t *testing.T) {
+		_, err := NewConverter().Convert([]byte("time,value\nyesterday,1\n"))
+		require.ErrorContains(t, err, "line 2")
+	})
+}
@@ -0,0 +1,218 @@
+package otlp
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	influx "github.com/influxdata/line-protocol"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
+)
+
+var _ telemetry.Converter = (*Converter)(nil)
+
+// Converter converts OTLP metrics to Grafana frames.
+type Converter struct {
+	useLabelsColumn bool
+	converter       *telegraf.Converter
+	now             func() time.Time
+}
+
+// ConverterOption ...
+type ConverterOption func(*Converter)
+
+// WithUseLabelsColumn ...
+func WithUseLabelsColumn(enabled bool) ConverterOption {
+	return func(h *Converter) {
+		h.useLabelsColumn = enabled
+	}
+}
+
+// NewConverter creates new Converter from OTLP/HTTP metrics export requests, encoded as protobuf
+// or JSON, to Grafana Data Frames. Every data point becomes a metric with the resource and data
+// point attributes as labels and a single value field, and metrics are mapped to frames the same
+// way as Influx line protocol. Histograms and summaries are flattened into _bucket, _sum and _count
+// metrics like in Prometheus.
+func NewConverter(opts ...ConverterOption) *Converter {
+	c := &Converter{now: time.Now}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.converter = telegraf.NewConverter(
+		telegraf.WithUseLabelsColumn(c.useLabelsColumn),
+		telegraf.WithFloat64Numbers(true),
+	)
+	return c
+}
+
+// Convert metrics.
+func (c *Converter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	req := pmetricotlp.NewExportRequest()
+	var err error
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '{' {
+		err = req.UnmarshalJSON(body)
+	} else {
+		err = req.UnmarshalProto(body)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metrics: %w", err)
+	}
+
+	b := &metricsBuilder{now: c.now()}
+	resourceMetrics := req.Metrics().ResourceMetrics()
+	for i := 0; i < resourceMetrics.Len(); i++ {
+		rm := resourceMetrics.At(i)
+		scopeMetrics := rm.ScopeMetrics()
+		for j := 0; j < scopeMetrics.Len(); j++ {
+			metrics := scopeMetrics.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				if err := b.addMetric(rm.Resource().Attributes(), metrics.At(k)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return c.converter.ConvertMetrics(b.metrics)
+}
+
+type metricsBuilder struct {
+	// now is the time of data points without timestamp, the same for all of them,
+	// so that they are put into the same frame.
+	now     time.Time
+	metrics []influx.Metric
+}
+
+func (b *metricsBuilder) addMetric(resourceAttrs pcommon.Map, m pmetric.Metric) error {
+	name := m.Name()
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		return b.addNumberDataPoints(name, resourceAttrs, m.Gauge().DataPoints())
+	case pmetric.MetricTypeSum:
+		return b.addNumberDataPoints(name, resourceAttrs, m.Sum().DataPoints())
+	case pmetric.MetricTypeHistogram:
+		dps := m.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			labels := attributesToLabels(resourceAttrs, dp.Attributes())
+			t := b.timestamp(dp.Timestamp())
+			bounds := dp.ExplicitBounds()
+			counts := dp.BucketCounts()
+			var cumulative uint64
+			for j := 0; j < counts.Len(); j++ {
+				cumulative += counts.At(j)
+				le := math.Inf(1)
+				if j < bounds.Len() {
+					le = bounds.At(j)
+				}
+				if err := b.add(name+"_bucket", withLabel(labels, "le", formatFloat(le)), float64(cumulative), t); err != nil {
+					return err
+				}
+			}
+			if err := b.addSumAndCount(name, labels, dp.HasSum(), dp.Sum(), dp.Count(), t); err != nil {
+				return err
+			}
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := m.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			labels := attributesToLabels(resourceAttrs, dp.Attributes())
+			if err := b.addSumAndCount(name, labels, dp.HasSum(), dp.Sum(), dp.Count(), b.timestamp(dp.Timestamp())); err != nil {
+				return err
+			}
+		}
+	case pmetric.MetricTypeSummary:
+		dps := m.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			labels := attributesToLabels(resourceAttrs, dp.Attributes())
+			t := b.timestamp(dp.Timestamp())
+			quantiles := dp.QuantileValues()
+			for j := 0; j < quantiles.Len(); j++ {
+				q := quantiles.At(j)
+				if err := b.add(name, withLabel(labels, "quantile", formatFloat(q.Quantile())), q.Value(), t); err != nil {
+					return err
+				}
+			}
+			if err := b.addSumAndCount(name, labels, true, dp.Sum(), dp.Count(), t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (b *metricsBuilder) addNumberDataPoints(name string, resourceAttrs pcommon.Map, dps pmetric.NumberDataPointSlice) error {
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		value := dp.DoubleValue()
+		if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+			value = float64(dp.IntValue())
+		}
+		if err := b.add(name, attributesToLabels(resourceAttrs, dp.Attributes()), value, b.timestamp(dp.Timestamp())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *metricsBuilder) addSumAndCount(name string, labels map[string]string, hasSum bool, sum float64, count uint64, t time.Time) error {
+	if hasSum {
+		if err := b.add(name+"_sum", labels, sum, t); err != nil {
+			return err
+		}
+	}
+	return b.add(name+"_count", labels, float64(count), t)
+}
+
+func (b *metricsBuilder) add(name string, labels map[string]string, value float64, t time.Time) error {
+	m, err := influx.New(name, labels, map[string]any{"value": value}, t)
+	if err != nil {
+		return err
+	}
+	b.metrics = append(b.metrics, m)
+	return nil
+}
+
+func (b *metricsBuilder) timestamp(ts pcommon.Timestamp) time.Time {
+	if ts == 0 {
+		return b.now
+	}
+	return ts.AsTime()
+}
+
+// attributesToLabels merges resource and data point attributes, data point attributes take precedence.
+func attributesToLabels(resourceAttrs pcommon.Map, attrs pcommon.Map) map[string]string {
+	labels := make(map[string]string, resourceAttrs.Len()+attrs.Len())
+	for _, m := range []pcommon.Map{resourceAttrs, attrs} {
+		m.Range(func(k string, v pcommon.Value) bool {
+			labels[k] = v.AsString()
+			return true
+		})
+	}
+	return labels
+}
+
+func withLabel(labels map[string]string, name, value string) map[string]string {
+	result := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		result[k] = v
+	}
+	result[name] = value
+	return result
+}
+
+// formatFloat formats bucket bounds and quantiles the same way as Prometheus.
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
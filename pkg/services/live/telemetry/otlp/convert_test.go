@@ -0,0 +1,87 @@
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+)
+
+func testRequest(ts time.Time) pmetricotlp.ExportRequest {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "device")
+	metrics := rm.ScopeMetrics().AppendEmpty().Metrics()
+
+	gauge := metrics.AppendEmpty()
+	gauge.SetName("temperature")
+	dp := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetDoubleValue(21.5)
+	dp.Attributes().PutStr("room", "kitchen")
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+
+	sum := metrics.AppendEmpty()
+	sum.SetName("requests")
+	sdp := sum.SetEmptySum().DataPoints().AppendEmpty()
+	sdp.SetIntValue(10)
+
+	histogram := metrics.AppendEmpty()
+	histogram.SetName("duration")
+	hdp := histogram.SetEmptyHistogram().DataPoints().AppendEmpty()
+	hdp.ExplicitBounds().FromRaw([]float64{0.1})
+	hdp.BucketCounts().FromRaw([]uint64{2, 1})
+	hdp.SetCount(3)
+	hdp.SetSum(0.35)
+	return pmetricotlp.NewExportRequestFromMetrics(md)
+}
+
+func TestConverter_Convert(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	ts := time.Unix(1600000000, 0)
+	req := testRequest(ts)
+	protoBody, err := req.MarshalProto()
+	require.NoError(t, err)
+	jsonBody, err := req.MarshalJSON()
+	require.NoError(t, err)
+
+	for name, body := range map[string][]byte{"protobuf": protoBody, "json": jsonBody} {
+		t.Run(name, func(t *testing.T) {
+			c := NewConverter()
+			c.now = func() time.Time { return now }
+			frameWrappers, err := c.Convert(body)
+			require.NoError(t, err)
+
+			keys := make([]string, 0, len(frameWrappers))
+			for _, fw := range frameWrappers {
+				keys = append(keys, fw.Key())
+			}
+			require.Equal(t, []string{"temperature", "requests", "duration_bucket", "duration_sum", "duration_count"}, keys)
+
+			frame := frameWrappers[0].Frame()
+			require.True(t, ts.Equal(frame.Fields[0].At(0).(time.Time)))
+			require.Equal(t, data.Labels{"service.name": "device", "room": "kitchen"}, frame.Fields[1].Labels)
+
+			frame = frameWrappers[1].Frame()
+			require.Equal(t, now, frame.Fields[0].At(0))
+			v, ok := frame.Fields[1].ConcreteAt(0)
+			require.True(t, ok)
+			require.Equal(t, 10.0, v)
+
+			frame = frameWrappers[2].Frame()
+			require.Len(t, frame.Fields, 3)
+			require.Equal(t, "+Inf", frame.Fields[2].Labels["le"])
+			v, ok = frame.Fields[2].ConcreteAt(0)
+			require.True(t, ok)
+			require.Equal(t, 3.0, v)
+		})
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := NewConverter().Convert([]byte("{not json"))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,152 @@
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	influx "github.com/influxdata/line-protocol"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
+)
+
+var _ telemetry.Converter = (*Converter)(nil)
+
+// Converter converts metrics in Prometheus text exposition format to Grafana frames.
+type Converter struct {
+	useLabelsColumn bool
+	converter       *telegraf.Converter
+	now             func() time.Time
+}
+
+// ConverterOption ...
+type ConverterOption func(*Converter)
+
+// WithUseLabelsColumn ...
+func WithUseLabelsColumn(enabled bool) ConverterOption {
+	return func(h *Converter) {
+		h.useLabelsColumn = enabled
+	}
+}
+
+// NewConverter creates new Converter from Prometheus text exposition format to Grafana Data Frames.
+// Every sample becomes a metric with the labels of the sample and a single value field, and metrics
+// are mapped to frames the same way as Influx line protocol. Histograms and summaries are flattened
+// into _bucket, _sum and _count metrics like in the exposition format.
+func NewConverter(opts ...ConverterOption) *Converter {
+	c := &Converter{now: time.Now}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.converter = telegraf.NewConverter(
+		telegraf.WithUseLabelsColumn(c.useLabelsColumn),
+		telegraf.WithFloat64Numbers(true),
+	)
+	return c
+}
+
+// Convert metrics.
+func (c *Converter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metrics: %w", err)
+	}
+
+	// Families are returned as a map, sort them to keep the order of frames stable.
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b := &metricsBuilder{now: c.now()}
+	for _, name := range names {
+		for _, m := range families[name].GetMetric() {
+			if err := b.addMetric(name, families[name].GetType(), m); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return c.converter.ConvertMetrics(b.metrics)
+}
+
+type metricsBuilder struct {
+	// now is the time of samples without timestamp, the same for all of them,
+	// so that they are put into the same frame.
+	now     time.Time
+	metrics []influx.Metric
+}
+
+func (b *metricsBuilder) addMetric(name string, metricType dto.MetricType, m *dto.Metric) error {
+	labels := make(map[string]string, len(m.GetLabel()))
+	for _, l := range m.GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+	t := b.now
+	if m.TimestampMs != nil {
+		t = time.UnixMilli(m.GetTimestampMs())
+	}
+
+	switch metricType {
+	case dto.MetricType_COUNTER:
+		return b.add(name, labels, m.GetCounter().GetValue(), t)
+	case dto.MetricType_GAUGE:
+		return b.add(name, labels, m.GetGauge().GetValue(), t)
+	case dto.MetricType_SUMMARY:
+		for _, q := range m.GetSummary().GetQuantile() {
+			if err := b.add(name, withLabel(labels, "quantile", formatFloat(q.GetQuantile())), q.GetValue(), t); err != nil {
+				return err
+			}
+		}
+		return b.addSumAndCount(name, labels, m.GetSummary().GetSampleSum(), m.GetSummary().GetSampleCount(), t)
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		for _, bucket := range m.GetHistogram().GetBucket() {
+			if err := b.add(name+"_bucket", withLabel(labels, "le", formatFloat(bucket.GetUpperBound())), float64(bucket.GetCumulativeCount()), t); err != nil {
+				return err
+			}
+		}
+		return b.addSumAndCount(name, labels, m.GetHistogram().GetSampleSum(), m.GetHistogram().GetSampleCount(), t)
+	default:
+		return b.add(name, labels, m.GetUntyped().GetValue(), t)
+	}
+}
+
+func (b *metricsBuilder) addSumAndCount(name string, labels map[string]string, sum float64, count uint64, t time.Time) error {
+	if err := b.add(name+"_sum", labels, sum, t); err != nil {
+		return err
+	}
+	return b.add(name+"_count", labels, float64(count), t)
+}
+
+func (b *metricsBuilder) add(name string, labels map[string]string, value float64, t time.Time) error {
+	m, err := influx.New(name, labels, map[string]any{"value": value}, t)
+	if err != nil {
+		return err
+	}
+	b.metrics = append(b.metrics, m)
+	return nil
+}
+
+func withLabel(labels map[string]string, name, value string) map[string]string {
+	result := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		result[k] = v
+	}
+	result[name] = value
+	return result
+}
+
+// formatFloat formats bucket bounds and quantiles the same way as the exposition format.
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
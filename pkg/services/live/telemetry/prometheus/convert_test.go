@@ -0,0 +1,79 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+const testMetrics = `# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027 1395066363000
+http_requests_total{method="post",code="400"} 3 1395066363000
+# TYPE temperature gauge
+temperature 21.5
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.1"} 2
+request_duration_seconds_bucket{le="+Inf"} 3
+request_duration_seconds_sum 0.35
+request_duration_seconds_count 3
+`
+
+func TestConverter_Convert(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	t.Run("wide", func(t *testing.T) {
+		c := NewConverter()
+		c.now = func() time.Time { return now }
+		frameWrappers, err := c.Convert([]byte(testMetrics))
+		require.NoError(t, err)
+
+		keys := make([]string, 0, len(frameWrappers))
+		for _, fw := range frameWrappers {
+			keys = append(keys, fw.Key())
+		}
+		require.Equal(t, []string{
+			"http_requests_total",
+			"request_duration_seconds_bucket",
+			"request_duration_seconds_sum",
+			"request_duration_seconds_count",
+			"temperature",
+		}, keys)
+
+		frame := frameWrappers[0].Frame()
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, time.UnixMilli(1395066363000), frame.Fields[0].At(0))
+		require.Equal(t, "value", frame.Fields[1].Name)
+		require.Equal(t, data.Labels{"method": "post", "code": "200"}, frame.Fields[1].Labels)
+		v, ok := frame.Fields[1].ConcreteAt(0)
+		require.True(t, ok)
+		require.Equal(t, 1027.0, v)
+
+		frame = frameWrappers[1].Frame()
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, now, frame.Fields[0].At(0))
+		require.Equal(t, data.Labels{"le": "0.1"}, frame.Fields[1].Labels)
+		require.Equal(t, data.Labels{"le": "+Inf"}, frame.Fields[2].Labels)
+	})
+
+	t.Run("labels column", func(t *testing.T) {
+		c := NewConverter(WithUseLabelsColumn(true))
+		frameWrappers, err := c.Convert([]byte(testMetrics))
+		require.NoError(t, err)
+		require.Len(t, frameWrappers, 5)
+
+		frame := frameWrappers[0].Frame()
+		require.Equal(t, "http_requests_total", frame.Name)
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, "labels", frame.Fields[0].Name)
+		require.Equal(t, 2, frame.Fields[0].Len())
+		require.Equal(t, "code=200, method=post", frame.Fields[0].At(0))
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := NewConverter().Convert([]byte("not a metric{"))
+		require.Error(t, err)
+	})
+}
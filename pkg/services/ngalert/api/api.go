@@ -70,6 +70,7 @@ type API struct {
 	Templates            *provisioning.TemplateService
 	MuteTimings          *provisioning.MuteTimingService
 	AlertRules           *provisioning.AlertRuleService
+	RuleTemplates        *provisioning.RuleTemplateService
 	AlertsRouter         *sender.AlertsRouter
 	EvaluatorFactory     eval.EvaluatorFactory
 	FeatureManager       featuremgmt.FeatureToggles
@@ -146,6 +147,8 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			appUrl:          api.AppUrl,
 			tracer:          api.Tracer,
 			folderService:   api.RuleStore,
+			ruleStore:       api.RuleStore,
+			amConfig:        api.MultiOrgAlertmanager,
 		}), m)
 	api.RegisterConfigurationApiEndpoints(NewConfiguration(
 		&ConfigSrv{
@@ -164,6 +167,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		templates:           api.Templates,
 		muteTimings:         api.MuteTimings,
 		alertRules:          api.AlertRules,
+		ruleTemplates:       api.RuleTemplates,
 		// XXX: Used to flag recording rules, remove when FT is removed
 		featureManager: api.FeatureManager,
 	}), m)
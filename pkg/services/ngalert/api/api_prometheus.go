@@ -102,9 +102,10 @@ func PrepareAlertStatuses(manager state.AlertInstanceManager, opts AlertStatuses
 
 			// TODO: or should we make this two fields? Using one field lets the
 			// frontend use the same logic for parsing text on annotations and this.
-			State:    state.FormatStateAndReason(alertState.State, alertState.StateReason),
-			ActiveAt: &startsAt,
-			Value:    valString,
+			State:           state.FormatStateAndReason(alertState.State, alertState.StateReason),
+			ActiveAt:        &startsAt,
+			KeepFiringSince: alertState.KeepFiringSince,
+			Value:           valString,
 		})
 	}
 
@@ -444,11 +445,12 @@ func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, groupKey ng
 	ngmodels.RulesGroup(rules).SortByGroupIndex()
 	for _, rule := range rules {
 		alertingRule := apimodels.AlertingRule{
-			State:       "inactive",
-			Name:        rule.Title,
-			Query:       ruleToQuery(log, rule),
-			Duration:    rule.For.Seconds(),
-			Annotations: apimodels.LabelsFromMap(rule.Annotations),
+			State:         "inactive",
+			Name:          rule.Title,
+			Query:         ruleToQuery(log, rule),
+			Duration:      rule.For.Seconds(),
+			KeepFiringFor: rule.KeepFiringFor.Seconds(),
+			Annotations:   apimodels.LabelsFromMap(rule.Annotations),
 		}
 
 		newRule := apimodels.Rule{
@@ -480,9 +482,11 @@ func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, groupKey ng
 
 				// TODO: or should we make this two fields? Using one field lets the
 				// frontend use the same logic for parsing text on annotations and this.
-				State:    state.FormatStateAndReason(alertState.State, alertState.StateReason),
-				ActiveAt: &activeAt,
-				Value:    valString,
+				State:           state.FormatStateAndReason(alertState.State, alertState.StateReason),
+				ActiveAt:        &activeAt,
+				KeepFiringSince: alertState.KeepFiringSince,
+				KeepFiringUntil: alertState.KeepFiringUntil(rule.KeepFiringFor),
+				Value:           valString,
 			}
 
 			if alertState.LastEvaluationTime.After(newRule.LastEvaluation) {
@@ -30,6 +30,7 @@ type ProvisioningSrv struct {
 	templates           TemplateService
 	muteTimings         MuteTimingService
 	alertRules          AlertRuleService
+	ruleTemplates       RuleTemplateService
 	folderSvc           folder.Service
 
 	// XXX: Used to flag recording rules, remove when FT is removed
@@ -77,6 +78,14 @@ type AlertRuleService interface {
 	GetAlertGroupsWithFolderFullpath(ctx context.Context, u identity.Requester, folderUIDs []string) ([]alerting_models.AlertRuleGroupWithFolderFullpath, error)
 }
 
+type RuleTemplateService interface {
+	GetRuleTemplates(ctx context.Context, orgID int64) ([]*alerting_models.AlertRuleTemplate, map[string]alerting_models.Provenance, error)
+	GetRuleTemplate(ctx context.Context, orgID int64, uid string) (alerting_models.AlertRuleTemplate, alerting_models.Provenance, error)
+	CreateRuleTemplate(ctx context.Context, template alerting_models.AlertRuleTemplate, provenance alerting_models.Provenance) (alerting_models.AlertRuleTemplate, error)
+	UpdateRuleTemplate(ctx context.Context, user identity.Requester, template alerting_models.AlertRuleTemplate, provenance alerting_models.Provenance) (alerting_models.AlertRuleTemplate, error)
+	DeleteRuleTemplate(ctx context.Context, orgID int64, uid string, provenance alerting_models.Provenance) error
+}
+
 func (srv *ProvisioningSrv) RouteGetPolicyTree(c *contextmodel.ReqContext) response.Response {
 	policies, err := srv.policies.GetPolicyTree(c.Req.Context(), c.SignedInUser.GetOrgID())
 	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
@@ -531,6 +540,69 @@ func (srv *ProvisioningSrv) RouteDeleteAlertRuleGroup(c *contextmodel.ReqContext
 	return response.JSON(http.StatusNoContent, "")
 }
 
+func (srv *ProvisioningSrv) RouteGetRuleTemplates(c *contextmodel.ReqContext) response.Response {
+	templates, provenances, err := srv.ruleTemplates.GetRuleTemplates(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get alert rule templates", err)
+	}
+	result := make(definitions.RuleTemplates, 0, len(templates))
+	for _, t := range templates {
+		result = append(result, RuleTemplateFromAlertRuleTemplate(*t, provenances[t.UID]))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv *ProvisioningSrv) RouteGetRuleTemplate(c *contextmodel.ReqContext, UID string) response.Response {
+	template, provenance, err := srv.ruleTemplates.GetRuleTemplate(c.Req.Context(), c.SignedInUser.GetOrgID(), UID)
+	if err != nil {
+		return ruleTemplateErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, RuleTemplateFromAlertRuleTemplate(template, provenance))
+}
+
+func (srv *ProvisioningSrv) RoutePostRuleTemplate(c *contextmodel.ReqContext, t definitions.RuleTemplate) response.Response {
+	provenance := alerting_models.Provenance(determineProvenance(c))
+	created, err := srv.ruleTemplates.CreateRuleTemplate(c.Req.Context(), AlertRuleTemplateFromRuleTemplate(c.SignedInUser.GetOrgID(), t), provenance)
+	if err != nil {
+		return ruleTemplateErrorResponse(err)
+	}
+	return response.JSON(http.StatusCreated, RuleTemplateFromAlertRuleTemplate(created, provenance))
+}
+
+func (srv *ProvisioningSrv) RoutePutRuleTemplate(c *contextmodel.ReqContext, t definitions.RuleTemplate, UID string) response.Response {
+	t.UID = UID
+	provenance := alerting_models.Provenance(determineProvenance(c))
+	template := AlertRuleTemplateFromRuleTemplate(c.SignedInUser.GetOrgID(), t)
+	template.Version = t.Version
+	updated, err := srv.ruleTemplates.UpdateRuleTemplate(c.Req.Context(), c.SignedInUser, template, provenance)
+	if err != nil {
+		return ruleTemplateErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, RuleTemplateFromAlertRuleTemplate(updated, provenance))
+}
+
+func (srv *ProvisioningSrv) RouteDeleteRuleTemplate(c *contextmodel.ReqContext, UID string) response.Response {
+	provenance := alerting_models.Provenance(determineProvenance(c))
+	err := srv.ruleTemplates.DeleteRuleTemplate(c.Req.Context(), c.SignedInUser.GetOrgID(), UID, provenance)
+	if err != nil {
+		return ruleTemplateErrorResponse(err)
+	}
+	return response.JSON(http.StatusNoContent, "")
+}
+
+func ruleTemplateErrorResponse(err error) response.Response {
+	if errors.Is(err, alerting_models.ErrAlertRuleTemplateNotFound) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if errors.Is(err, alerting_models.ErrAlertRuleTemplateFailedValidation) || errors.Is(err, alerting_models.ErrAlertRuleFailedValidation) {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if errors.Is(err, alerting_models.ErrAlertRuleTemplateInUse) || errors.Is(err, store.ErrOptimisticLock) {
+		return ErrResp(http.StatusConflict, err, "")
+	}
+	return response.ErrOrFallback(http.StatusInternalServerError, "", err)
+}
+
 func determineProvenance(ctx *contextmodel.ReqContext) definitions.Provenance {
 	if _, disabled := ctx.Req.Header[disableProvenanceHeaderName]; disabled {
 		return definitions.Provenance(alerting_models.ProvenanceNone)
@@ -122,6 +122,7 @@ func (srv RulerSrv) RouteDeleteAlertRules(c *contextmodel.ReqContext, namespaceU
 			}
 		}
 		rulesToDelete := make([]string, 0)
+		deletedRules := make([]*ngmodels.AlertRule, 0)
 		provisioned := false
 		auth := true
 		for groupKey, rules := range deletionCandidates {
@@ -145,8 +146,12 @@ func (srv RulerSrv) RouteDeleteAlertRules(c *contextmodel.ReqContext, namespaceU
 				uid = append(uid, rule.UID)
 			}
 			rulesToDelete = append(rulesToDelete, uid...)
+			deletedRules = append(deletedRules, rules...)
 		}
 		if len(rulesToDelete) > 0 {
+			if err := store.ValidateNoDependents(ctx, srv.store, c.SignedInUser.GetOrgID(), deletedRules); err != nil {
+				return err
+			}
 			err := srv.store.DeleteAlertRulesByUID(ctx, c.SignedInUser.GetOrgID(), rulesToDelete...)
 			if err != nil {
 				return err
@@ -325,6 +330,10 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *contextmodel.ReqContext, ruleGro
 		return ErrResp(http.StatusBadRequest, err, "")
 	}
 
+	if err := renderRuleTemplates(c.Req.Context(), srv.store, c.SignedInUser.GetOrgID(), &ruleGroupConfig); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
 	rules, err := ValidateRuleGroup(&ruleGroupConfig, c.SignedInUser.GetOrgID(), namespace.UID, RuleLimitsFromConfig(srv.cfg, srv.featureManager))
 	if err != nil {
 		return ErrResp(http.StatusBadRequest, err, "")
@@ -382,6 +391,10 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 			return err
 		}
 
+		if err := store.ValidateDependencies(tranCtx, srv.store, groupChanges); err != nil {
+			return err
+		}
+
 		newOrUpdatedNotificationSettings := groupChanges.NewOrUpdatedNotificationSettings()
 		if len(newOrUpdatedNotificationSettings) > 0 {
 			dbConfig, err = srv.amConfigStore.GetLatestAlertmanagerConfiguration(c.Req.Context(), groupChanges.GroupKey.OrgID)
@@ -404,95 +417,105 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 			return err
 		}
 
-		finalChanges = store.UpdateCalculatedRuleFields(groupChanges)
-		logger.Debug("Updating database with the authorized changes", "add", len(finalChanges.New), "update", len(finalChanges.New), "delete", len(finalChanges.Delete))
+		finalChanges, err = srv.saveGroupChanges(tranCtx, c.SignedInUser, logger, groupChanges)
+		return err
+	})
 
-		// Delete first as this could prevent future unique constraint violations.
-		if len(finalChanges.Delete) > 0 {
-			UIDs := make([]string, 0, len(finalChanges.Delete))
-			for _, rule := range finalChanges.Delete {
-				UIDs = append(UIDs, rule.UID)
-			}
+	if err != nil {
+		return groupUpdateErrorResponse(err)
+	}
 
-			if err = srv.store.DeleteAlertRulesByUID(tranCtx, c.SignedInUser.GetOrgID(), UIDs...); err != nil {
-				return fmt.Errorf("failed to delete rules: %w", err)
-			}
+	if srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingSimplifiedRouting) && dbConfig != nil {
+		// This isn't strictly necessary since the alertmanager config is periodically synced.
+		err := srv.amRefresher.ApplyConfig(c.Req.Context(), groupKey.OrgID, dbConfig)
+		if err != nil {
+			srv.log.Warn("Failed to refresh Alertmanager config for org after change in notification settings", "org", c.SignedInUser.GetOrgID(), "error", err)
 		}
+	}
 
-		if len(finalChanges.Update) > 0 {
-			updates := make([]ngmodels.UpdateRule, 0, len(finalChanges.Update))
-			for _, update := range finalChanges.Update {
-				logger.Debug("Updating rule", "rule_uid", update.New.UID, "diff", update.Diff.String())
-				updates = append(updates, ngmodels.UpdateRule{
-					Existing: update.Existing,
-					New:      *update.New,
-				})
-			}
-			err = srv.store.UpdateAlertRules(tranCtx, updates)
-			if err != nil {
-				return fmt.Errorf("failed to update rules: %w", err)
-			}
+	return changesToResponse(finalChanges)
+}
+
+// saveGroupChanges refreshes the calculated fields of the authorized changes and applies them to the database.
+// It must be called in a transaction.
+func (srv RulerSrv) saveGroupChanges(ctx context.Context, user identity.Requester, logger log.Logger, groupChanges *store.GroupDelta) (*store.GroupDelta, error) {
+	finalChanges := store.UpdateCalculatedRuleFields(groupChanges)
+	logger.Debug("Updating database with the authorized changes", "add", len(finalChanges.New), "update", len(finalChanges.New), "delete", len(finalChanges.Delete))
+
+	// Delete first as this could prevent future unique constraint violations.
+	if len(finalChanges.Delete) > 0 {
+		UIDs := make([]string, 0, len(finalChanges.Delete))
+		for _, rule := range finalChanges.Delete {
+			UIDs = append(UIDs, rule.UID)
 		}
 
-		if len(finalChanges.New) > 0 {
-			inserts := make([]ngmodels.AlertRule, 0, len(finalChanges.New))
-			for _, rule := range finalChanges.New {
-				inserts = append(inserts, *rule)
-			}
-			added, err := srv.store.InsertAlertRules(tranCtx, inserts)
-			if err != nil {
-				return fmt.Errorf("failed to add rules: %w", err)
-			}
-			if len(added) != len(finalChanges.New) {
-				logger.Error("Cannot match inserted rules with final changes", "insertedCount", len(added), "changes", len(finalChanges.New))
-			} else {
-				for i, newRule := range finalChanges.New {
-					newRule.ID = added[i].ID
-					newRule.UID = added[i].UID
-				}
-			}
+		if err := srv.store.DeleteAlertRulesByUID(ctx, user.GetOrgID(), UIDs...); err != nil {
+			return nil, fmt.Errorf("failed to delete rules: %w", err)
 		}
+	}
 
-		if len(finalChanges.New) > 0 {
-			userID, _ := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
-			limitReached, err := srv.QuotaService.CheckQuotaReached(tranCtx, ngmodels.QuotaTargetSrv, &quota.ScopeParameters{
-				OrgID:  c.SignedInUser.GetOrgID(),
-				UserID: userID,
-			}) // alert rule is table name
-			if err != nil {
-				return fmt.Errorf("failed to get alert rules quota: %w", err)
-			}
-			if limitReached {
-				return ngmodels.ErrQuotaReached
-			}
+	if len(finalChanges.Update) > 0 {
+		updates := make([]ngmodels.UpdateRule, 0, len(finalChanges.Update))
+		for _, update := range finalChanges.Update {
+			logger.Debug("Updating rule", "rule_uid", update.New.UID, "diff", update.Diff.String())
+			updates = append(updates, ngmodels.UpdateRule{
+				Existing: update.Existing,
+				New:      *update.New,
+			})
 		}
-		return nil
-	})
+		if err := srv.store.UpdateAlertRules(ctx, updates); err != nil {
+			return nil, fmt.Errorf("failed to update rules: %w", err)
+		}
+	}
 
-	if err != nil {
-		if errors.As(err, &errutil.Error{}) {
-			return response.Err(err)
-		} else if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
-			return ErrResp(http.StatusNotFound, err, "failed to update rule group")
-		} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) || errors.Is(err, errProvisionedResource) {
-			return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
-		} else if errors.Is(err, ngmodels.ErrQuotaReached) {
-			return ErrResp(http.StatusForbidden, err, "")
-		} else if errors.Is(err, store.ErrOptimisticLock) {
-			return ErrResp(http.StatusConflict, err, "")
+	if len(finalChanges.New) > 0 {
+		inserts := make([]ngmodels.AlertRule, 0, len(finalChanges.New))
+		for _, rule := range finalChanges.New {
+			inserts = append(inserts, *rule)
+		}
+		added, err := srv.store.InsertAlertRules(ctx, inserts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add rules: %w", err)
+		}
+		if len(added) != len(finalChanges.New) {
+			logger.Error("Cannot match inserted rules with final changes", "insertedCount", len(added), "changes", len(finalChanges.New))
+		} else {
+			for i, newRule := range finalChanges.New {
+				newRule.ID = added[i].ID
+				newRule.UID = added[i].UID
+			}
 		}
-		return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
 	}
 
-	if srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingSimplifiedRouting) && dbConfig != nil {
-		// This isn't strictly necessary since the alertmanager config is periodically synced.
-		err := srv.amRefresher.ApplyConfig(c.Req.Context(), groupKey.OrgID, dbConfig)
+	if len(finalChanges.New) > 0 {
+		userID, _ := identity.UserIdentifier(user.GetNamespacedID())
+		limitReached, err := srv.QuotaService.CheckQuotaReached(ctx, ngmodels.QuotaTargetSrv, &quota.ScopeParameters{
+			OrgID:  user.GetOrgID(),
+			UserID: userID,
+		}) // alert rule is table name
 		if err != nil {
-			srv.log.Warn("Failed to refresh Alertmanager config for org after change in notification settings", "org", c.SignedInUser.GetOrgID(), "error", err)
+			return nil, fmt.Errorf("failed to get alert rules quota: %w", err)
+		}
+		if limitReached {
+			return nil, ngmodels.ErrQuotaReached
 		}
 	}
+	return finalChanges, nil
+}
 
-	return changesToResponse(finalChanges)
+func groupUpdateErrorResponse(err error) response.Response {
+	if errors.As(err, &errutil.Error{}) {
+		return response.Err(err)
+	} else if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+		return ErrResp(http.StatusNotFound, err, "failed to update rule group")
+	} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) || errors.Is(err, errProvisionedResource) {
+		return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
+	} else if errors.Is(err, ngmodels.ErrQuotaReached) {
+		return ErrResp(http.StatusForbidden, err, "")
+	} else if errors.Is(err, store.ErrOptimisticLock) {
+		return ErrResp(http.StatusConflict, err, "")
+	}
+	return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
 }
 
 func changesToResponse(finalChanges *store.GroupDelta) response.Response {
@@ -560,6 +583,8 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, provenanceRecords map[stri
 			IsPaused:             r.IsPaused,
 			NotificationSettings: AlertRuleNotificationSettingsFromNotificationSettings(r.NotificationSettings),
 			Record:               ApiRecordFromModelRecord(r.Record),
+			DependsOn:            r.DependsOn,
+			Template:             ApiRuleTemplateRefFromModelRuleTemplateRef(r.Template),
 		},
 	}
 	forDuration := model.Duration(r.For)
@@ -568,6 +593,10 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, provenanceRecords map[stri
 		Annotations: r.Annotations,
 		Labels:      r.Labels,
 	}
+	if r.KeepFiringFor > 0 {
+		keepFiringFor := model.Duration(r.KeepFiringFor)
+		gettableExtendedRuleNode.ApiRuleNode.KeepFiringFor = &keepFiringFor
+	}
 	return gettableExtendedRuleNode
 }
 
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// ImportPrometheusRules converts the Prometheus rule groups of the payload to Grafana-managed rules that query the data source,
+// and replaces the rule groups with the same names in the namespace. Converted rules are matched with the existing rules of the namespace by title.
+// If the payload is a dry run, or some rules cannot be converted, the changes are calculated and returned but not saved.
+func (srv RulerSrv) ImportPrometheusRules(c *contextmodel.ReqContext, payload apimodels.PostablePrometheusRules, namespaceUID string, ds *datasources.DataSource) response.Response {
+	namespace, err := srv.store.GetNamespaceByUID(c.Req.Context(), namespaceUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	converter, err := prom.NewConverter(prom.Config{
+		DatasourceUID:   ds.UID,
+		DatasourceType:  ds.Type,
+		DefaultInterval: srv.cfg.DefaultRuleEvaluationInterval,
+		Validate:        srv.validateImportedRule,
+	})
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	groups, ruleErrs, err := converter.Convert(c.SignedInUser.GetOrgID(), namespace.UID, []byte(payload.Rules))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to parse rule groups")
+	}
+
+	result := apimodels.ImportRulesResponse{
+		DryRun: payload.DryRun,
+		Groups: make([]apimodels.ImportedRuleGroup, 0, len(groups)),
+	}
+	for _, e := range ruleErrs {
+		result.Errors = append(result.Errors, apimodels.RuleImportError{
+			Group: e.Group,
+			Rule:  e.Rule,
+			Index: e.Index,
+			Error: e.Err.Error(),
+		})
+	}
+	save := !payload.DryRun && len(result.Errors) == 0
+
+	err = srv.xactManager.InTransaction(c.Req.Context(), func(tranCtx context.Context) error {
+		submitted, err := srv.matchImportedRules(tranCtx, c.SignedInUser.GetOrgID(), namespace.UID, groups)
+		if err != nil {
+			return err
+		}
+		userNamespace, id := c.SignedInUser.GetNamespacedID()
+		for _, group := range groups {
+			groupKey := ngmodels.AlertRuleGroupKey{
+				OrgID:        c.SignedInUser.GetOrgID(),
+				NamespaceUID: namespace.UID,
+				RuleGroup:    group.Title,
+			}
+			logger := srv.log.New("namespace_uid", groupKey.NamespaceUID, "group",
+				groupKey.RuleGroup, "org_id", groupKey.OrgID, "user_id", id, "userNamespace", userNamespace)
+
+			rules := make([]*ngmodels.AlertRuleWithOptionals, 0, len(group.Rules))
+			for _, rule := range group.Rules {
+				rules = append(rules, &ngmodels.AlertRuleWithOptionals{AlertRule: rule})
+			}
+			groupChanges, err := store.CalculateChanges(tranCtx, srv.store, groupKey, rules)
+			if err != nil {
+				return err
+			}
+			// rules that move to another imported group are not deleted, the other group updates them
+			groupChanges.Delete = slices.DeleteFunc(groupChanges.Delete, func(r *ngmodels.AlertRule) bool {
+				_, ok := submitted[r.UID]
+				return ok
+			})
+			result.Groups = append(result.Groups, toImportedRuleGroup(group.Title, groupChanges))
+			if groupChanges.IsEmpty() {
+				continue
+			}
+
+			if err := srv.authz.AuthorizeRuleChanges(c.Req.Context(), c.SignedInUser, groupChanges); err != nil {
+				return err
+			}
+			if !save {
+				continue
+			}
+			if err := validateQueries(c.Req.Context(), groupChanges, srv.conditionValidator, c.SignedInUser); err != nil {
+				return err
+			}
+			if err := verifyProvisionedRulesNotAffected(c.Req.Context(), srv.provenanceStore, c.SignedInUser.GetOrgID(), groupChanges); err != nil {
+				return err
+			}
+			if _, err := srv.saveGroupChanges(tranCtx, c.SignedInUser, logger, groupChanges); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return groupUpdateErrorResponse(err)
+	}
+
+	switch {
+	case len(result.Errors) > 0:
+		result.Message = "rule groups were not imported because some rules could not be converted"
+		return response.JSON(http.StatusBadRequest, result)
+	case payload.DryRun:
+		result.Message = "rule groups were not imported because the request is a dry run"
+		return response.JSON(http.StatusOK, result)
+	}
+	result.Message = "rule groups imported successfully"
+	return response.JSON(http.StatusAccepted, result)
+}
+
+// validateImportedRule checks that the converted rule is a valid Grafana-managed rule.
+func (srv RulerSrv) validateImportedRule(rule ngmodels.AlertRule) error {
+	if rule.Type() == ngmodels.RuleTypeRecording && !RuleLimitsFromConfig(srv.cfg, srv.featureManager).RecordingRulesAllowed {
+		return errors.New("recording rules cannot be created on this instance")
+	}
+	if len(rule.Title) > store.AlertRuleMaxTitleLength {
+		return fmt.Errorf("name length should not be greater than %d", store.AlertRuleMaxTitleLength)
+	}
+	return rule.ValidateAlertRule(*srv.cfg)
+}
+
+// matchImportedRules sets the UIDs of the existing rules in the namespace to the imported rules with the same titles,
+// so that they are updated instead of recreated. It returns the set of matched UIDs.
+func (srv RulerSrv) matchImportedRules(ctx context.Context, orgID int64, namespaceUID string, groups []ngmodels.AlertRuleGroup) (map[string]struct{}, error) {
+	existing, err := srv.store.ListAlertRules(ctx, &ngmodels.ListAlertRulesQuery{
+		OrgID:         orgID,
+		NamespaceUIDs: []string{namespaceUID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query database for rules in the namespace %s: %w", namespaceUID, err)
+	}
+	byTitle := make(map[string]string, len(existing))
+	for _, rule := range existing {
+		byTitle[rule.Title] = rule.UID
+	}
+
+	matched := make(map[string]struct{})
+	for i := range groups {
+		for j := range groups[i].Rules {
+			rule := &groups[i].Rules[j]
+			if uid, ok := byTitle[rule.Title]; ok {
+				rule.UID = uid
+				matched[uid] = struct{}{}
+			}
+		}
+	}
+	return matched, nil
+}
+
+func toImportedRuleGroup(name string, changes *store.GroupDelta) apimodels.ImportedRuleGroup {
+	group := apimodels.ImportedRuleGroup{Name: name}
+	for _, r := range changes.New {
+		group.Created = append(group.Created, r.Title)
+	}
+	for _, d := range changes.Update {
+		diff := apimodels.ImportedRuleDiff{UID: d.Existing.UID, Title: d.New.Title}
+		seen := make(map[string]struct{})
+		for _, path := range d.Diff.Paths() {
+			// only report the top level fields
+			field := path
+			if i := strings.IndexAny(path, ".["); i > 0 {
+				field = path[:i]
+			}
+			if _, ok := seen[field]; ok {
+				continue
+			}
+			seen[field] = struct{}{}
+			diff.Fields = append(diff.Fields, field)
+		}
+		group.Updated = append(group.Updated, diff)
+	}
+	for _, r := range changes.Delete {
+		group.Deleted = append(group.Deleted, r.Title)
+	}
+	return group
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+)
+
+func TestImportPrometheusRules(t *testing.T) {
+	const rules = `
+groups:
+  - name: node
+    interval: 30s
+    rules:
+      - alert: HighCPU
+        expr: node_cpu_usage > 0.9
+        for: 5m
+      - record: instance:node_cpu:rate5m
+        expr: rate(node_cpu_seconds_total[5m])
+  - name: availability
+    rules:
+      - alert: Down
+        expr: up == 0
+`
+	ds := &datasources.DataSource{UID: "prom", Type: datasources.DS_PROMETHEUS}
+	orgID := rand.Int63()
+	folder := randFolder()
+
+	setup := func(t *testing.T) (*RulerSrv, *fakes.RuleStore, map[string]*models.AlertRule) {
+		t.Helper()
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		gen := models.RuleGen.With(models.RuleGen.WithOrgID(orgID), models.RuleGen.WithNamespace(folder), models.RuleGen.WithGroupName("node"))
+		existing := map[string]*models.AlertRule{}
+		for _, title := range []string{"HighCPU", "Stale", "Down"} {
+			rule := gen.With(gen.WithTitle(title)).GenerateRef()
+			existing[title] = rule
+			ruleStore.PutRule(context.Background(), rule)
+		}
+
+		svc := createService(ruleStore)
+		svc.authz = fakeRuleAccessControlService{}
+		svc.conditionValidator = &recordingConditionValidator{}
+		svc.QuotaService = quotatest.New(false, nil)
+		svc.cfg.DefaultRuleEvaluationInterval = time.Minute
+		svc.featureManager = featuremgmt.WithFeatures(featuremgmt.FlagGrafanaManagedRecordingRules)
+		return svc, ruleStore, existing
+	}
+
+	inserted := func(ruleStore *fakes.RuleStore) []models.AlertRule {
+		var result []models.AlertRule
+		for _, op := range ruleStore.RecordedOps {
+			if rules, ok := op.([]models.AlertRule); ok {
+				result = append(result, rules...)
+			}
+		}
+		return result
+	}
+
+	t.Run("dry run returns the changes without saving them", func(t *testing.T) {
+		svc, ruleStore, existing := setup(t)
+		payload := apimodels.PostablePrometheusRules{DatasourceUID: ds.UID, Rules: rules, DryRun: true}
+		response := svc.ImportPrometheusRules(createRequestContext(orgID, nil), payload, folder.UID, ds)
+		require.Equal(t, http.StatusOK, response.Status())
+
+		result := apimodels.ImportRulesResponse{}
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.True(t, result.DryRun)
+		require.Empty(t, result.Errors)
+		require.Len(t, result.Groups, 2)
+
+		node := result.Groups[0]
+		require.Equal(t, "node", node.Name)
+		require.Equal(t, []string{"instance:node_cpu:rate5m"}, node.Created)
+		require.Len(t, node.Updated, 1)
+		require.Equal(t, existing["HighCPU"].UID, node.Updated[0].UID)
+		require.Contains(t, node.Updated[0].Fields, "Data")
+		// Down moves to the other group, so it is not deleted
+		require.Equal(t, []string{"Stale"}, node.Deleted)
+
+		availability := result.Groups[1]
+		require.Empty(t, availability.Created)
+		require.Len(t, availability.Updated, 1)
+		require.Equal(t, existing["Down"].UID, availability.Updated[0].UID)
+		require.Contains(t, availability.Updated[0].Fields, "RuleGroup")
+
+		require.Empty(t, inserted(ruleStore))
+	})
+
+	t.Run("import saves the changes", func(t *testing.T) {
+		svc, ruleStore, _ := setup(t)
+		payload := apimodels.PostablePrometheusRules{DatasourceUID: ds.UID, Rules: rules}
+		response := svc.ImportPrometheusRules(createRequestContext(orgID, nil), payload, folder.UID, ds)
+		require.Equal(t, http.StatusAccepted, response.Status())
+
+		added := inserted(ruleStore)
+		require.Len(t, added, 1)
+		require.Equal(t, "instance:node_cpu:rate5m", added[0].Title)
+		require.Equal(t, folder.UID, added[0].NamespaceUID)
+		require.NotNil(t, added[0].Record)
+	})
+
+	t.Run("nothing is saved if a rule cannot be imported", func(t *testing.T) {
+		svc, ruleStore, _ := setup(t)
+		svc.featureManager = featuremgmt.WithFeatures()
+		payload := apimodels.PostablePrometheusRules{DatasourceUID: ds.UID, Rules: rules}
+		response := svc.ImportPrometheusRules(createRequestContext(orgID, nil), payload, folder.UID, ds)
+		require.Equal(t, http.StatusBadRequest, response.Status())
+
+		result := apimodels.ImportRulesResponse{}
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Equal(t, []apimodels.RuleImportError{{
+			Group: "node",
+			Rule:  "instance:node_cpu:rate5m",
+			Index: 2,
+			Error: "recording rules cannot be created on this instance",
+		}}, result.Errors)
+		require.Empty(t, inserted(ruleStore))
+	})
+
+	t.Run("invalid rule files are rejected", func(t *testing.T) {
+		svc, _, _ := setup(t)
+		payload := apimodels.PostablePrometheusRules{DatasourceUID: ds.UID, Rules: "groups: [{name: a, unknown: b}]"}
+		response := svc.ImportPrometheusRules(createRequestContext(orgID, nil), payload, folder.UID, ds)
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// renderRuleTemplates replaces the fields of the rules of the group that are derived from a template by the rendered template,
+// so that the rules are validated and stored as if they were submitted with these fields.
+func renderRuleTemplates(ctx context.Context, reader store.RuleTemplateReader, orgID int64, group *apimodels.PostableRuleGroupConfig) error {
+	nodes := make([]*apimodels.PostableExtendedRuleNode, 0)
+	rendered := make([]*ngmodels.AlertRule, 0)
+	for i := range group.Rules {
+		node := &group.Rules[i]
+		if node.GrafanaManagedAlert == nil || node.GrafanaManagedAlert.Template == nil {
+			continue
+		}
+		nodes = append(nodes, node)
+		rendered = append(rendered, &ngmodels.AlertRule{
+			OrgID:    orgID,
+			Title:    node.GrafanaManagedAlert.Title,
+			Record:   ModelRecordFromApiRecord(node.GrafanaManagedAlert.Record),
+			Template: ModelRuleTemplateRefFromApiRuleTemplateRef(node.GrafanaManagedAlert.Template),
+		})
+	}
+	if len(rendered) == 0 {
+		return nil
+	}
+	if err := store.ApplyRuleTemplates(ctx, reader, rendered...); err != nil {
+		return err
+	}
+
+	for i, node := range nodes {
+		rule := rendered[i]
+		node.GrafanaManagedAlert.Title = rule.Title
+		node.GrafanaManagedAlert.Condition = rule.Condition
+		node.GrafanaManagedAlert.Data = ApiAlertQueriesFromAlertQueries(rule.Data)
+		node.GrafanaManagedAlert.NoDataState = apimodels.NoDataState(rule.NoDataState)
+		node.GrafanaManagedAlert.ExecErrState = apimodels.ExecutionErrorState(rule.ExecErrState)
+		if node.ApiRuleNode == nil {
+			node.ApiRuleNode = &apimodels.ApiRuleNode{}
+		}
+		forDuration := model.Duration(rule.For)
+		node.ApiRuleNode.For = &forDuration
+		node.ApiRuleNode.Labels = rule.Labels
+		node.ApiRuleNode.Annotations = rule.Annotations
+	}
+	return nil
+}
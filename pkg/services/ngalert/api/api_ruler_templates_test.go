@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+func TestRenderRuleTemplates(t *testing.T) {
+	orgID := int64(1)
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.Templates[orgID] = []*ngmodels.AlertRuleTemplate{{
+		OrgID:      orgID,
+		UID:        "template",
+		Title:      "High CPU usage",
+		RuleTitle:  "High CPU usage on ${instance}",
+		Parameters: []ngmodels.AlertRuleTemplateParameter{{Name: "instance", Type: ngmodels.AlertRuleTemplateParameterString}},
+		Condition:  "A",
+		Data: []ngmodels.AlertQuery{{
+			RefID:         "A",
+			DatasourceUID: "__expr__",
+			Model:         json.RawMessage(`{"type":"math","expression":"1 == 1"}`),
+		}},
+		NoDataState:  ngmodels.OK,
+		ExecErrState: ngmodels.ErrorErrState,
+		For:          time.Minute,
+		Labels:       map[string]string{"instance": "${instance}"},
+	}}
+
+	t.Run("renders templated rules and keeps other rules", func(t *testing.T) {
+		group := apimodels.PostableRuleGroupConfig{
+			Name: "group",
+			Rules: []apimodels.PostableExtendedRuleNode{
+				{
+					GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+						UID:      "rule",
+						Template: &apimodels.RuleTemplateRef{UID: "template", Parameters: map[string]string{"instance": "web-1"}},
+					},
+				},
+				{
+					GrafanaManagedAlert: &apimodels.PostableGrafanaRule{Title: "other", Condition: "B"},
+				},
+			},
+		}
+		require.NoError(t, renderRuleTemplates(context.Background(), ruleStore, orgID, &group))
+
+		rendered := group.Rules[0]
+		require.Equal(t, "High CPU usage on web-1", rendered.GrafanaManagedAlert.Title)
+		require.Equal(t, "A", rendered.GrafanaManagedAlert.Condition)
+		require.Len(t, rendered.GrafanaManagedAlert.Data, 1)
+		require.Equal(t, apimodels.OK, rendered.GrafanaManagedAlert.NoDataState)
+		require.Equal(t, apimodels.ErrorErrState, rendered.GrafanaManagedAlert.ExecErrState)
+		require.Equal(t, model.Duration(time.Minute), *rendered.ApiRuleNode.For)
+		require.Equal(t, map[string]string{"instance": "web-1"}, rendered.ApiRuleNode.Labels)
+		require.Equal(t, "other", group.Rules[1].GrafanaManagedAlert.Title)
+		require.Nil(t, group.Rules[1].ApiRuleNode)
+	})
+
+	t.Run("fails if template does not exist", func(t *testing.T) {
+		group := apimodels.PostableRuleGroupConfig{
+			Name: "group",
+			Rules: []apimodels.PostableExtendedRuleNode{{
+				GrafanaManagedAlert: &apimodels.PostableGrafanaRule{Template: &apimodels.RuleTemplateRef{UID: "missing"}},
+			}},
+		}
+		require.ErrorIs(t, renderRuleTemplates(context.Background(), ruleStore, orgID, &group), ngmodels.ErrAlertRuleFailedValidation)
+	})
+}
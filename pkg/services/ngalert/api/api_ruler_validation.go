@@ -72,6 +72,8 @@ func validateRuleNode(
 		IntervalSeconds: intervalSeconds,
 		NamespaceUID:    namespaceUID,
 		RuleGroup:       groupName,
+		DependsOn:       ruleNode.GrafanaManagedAlert.DependsOn,
+		Template:        ModelRuleTemplateRefFromApiRuleTemplateRef(ruleNode.GrafanaManagedAlert.Template),
 	}
 
 	if isRecordingRule {
@@ -149,6 +151,11 @@ func validateAlertingRuleFields(in *apimodels.PostableExtendedRuleNode, newRule
 		return ngmodels.AlertRule{}, err
 	}
 
+	newRule.KeepFiringFor, err = validateKeepFiringFor(in)
+	if err != nil {
+		return ngmodels.AlertRule{}, err
+	}
+
 	return newRule, nil
 }
 
@@ -177,6 +184,7 @@ func validateRecordingRuleFields(in *apimodels.PostableExtendedRuleNode, newRule
 	newRule.ExecErrState = ""
 	newRule.Condition = ""
 	newRule.For = 0
+	newRule.KeepFiringFor = 0
 	newRule.NotificationSettings = nil
 
 	return newRule, nil
@@ -264,6 +272,21 @@ func validateForInterval(ruleNode *apimodels.PostableExtendedRuleNode) (time.Dur
 	return duration, nil
 }
 
+// validateKeepFiringFor validates ApiRuleNode.KeepFiringFor and converts it to time.Duration. If the field is not specified returns 0 if GrafanaManagedAlert.UID is empty and -1 if it is not.
+func validateKeepFiringFor(ruleNode *apimodels.PostableExtendedRuleNode) (time.Duration, error) {
+	if ruleNode.ApiRuleNode == nil || ruleNode.ApiRuleNode.KeepFiringFor == nil {
+		if ruleNode.GrafanaManagedAlert.UID != "" {
+			return -1, nil // will be patched later with the real value of the current version of the rule
+		}
+		return 0, nil
+	}
+	duration := time.Duration(*ruleNode.ApiRuleNode.KeepFiringFor)
+	if duration < 0 {
+		return 0, fmt.Errorf("field `keep_firing_for` cannot be negative [%v]. 0 or any positive duration are allowed", *ruleNode.ApiRuleNode.KeepFiringFor)
+	}
+	return duration, nil
+}
+
 // ValidateRuleGroup validates API model (definitions.PostableRuleGroupConfig) and converts it to a collection of models.AlertRule.
 // Returns a slice that contains all rules described by API model or error if either group specification or an alert definition is not valid.
 // It also returns a map containing current existing alerts that don't contain the is_paused field in the body of the call.
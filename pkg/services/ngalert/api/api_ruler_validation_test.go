@@ -370,6 +370,17 @@ func TestValidateRuleNode_NoUID(t *testing.T) {
 				require.Nil(t, alert.Labels)
 			},
 		},
+		{
+			name: "converts keep firing for",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.ApiRuleNode.KeepFiringFor = func() *model.Duration { d := model.Duration(5 * time.Minute); return &d }()
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, 5*time.Minute, alert.KeepFiringFor)
+			},
+		},
 		{
 			name: "defaults to NoData if NoDataState is empty",
 			rule: func() *apimodels.PostableExtendedRuleNode {
@@ -460,6 +471,7 @@ func TestValidateRuleNode_NoUID(t *testing.T) {
 				r.GrafanaManagedAlert.ExecErrState = apimodels.AlertingErrState
 				r.GrafanaManagedAlert.NotificationSettings = &apimodels.AlertRuleNotificationSettings{}
 				r.ApiRuleNode.For = func() *model.Duration { five := model.Duration(time.Second * 5); return &five }()
+				r.ApiRuleNode.KeepFiringFor = func() *model.Duration { five := model.Duration(time.Second * 5); return &five }()
 				return &r
 			},
 			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
@@ -468,6 +480,7 @@ func TestValidateRuleNode_NoUID(t *testing.T) {
 				require.Empty(t, alert.ExecErrState)
 				require.Nil(t, alert.NotificationSettings)
 				require.Zero(t, alert.For)
+				require.Zero(t, alert.KeepFiringFor)
 			},
 		},
 	}
@@ -599,6 +612,14 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 				return &r
 			},
 		},
+		{
+			name: "fail if keep firing for is negative",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.ApiRuleNode.KeepFiringFor = func() *model.Duration { d := model.Duration(-time.Second); return &d }()
+				return &r
+			},
+		},
 		{
 			name: "fail if Condition is empty",
 			rule: func() *apimodels.PostableExtendedRuleNode {
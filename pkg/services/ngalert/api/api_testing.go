@@ -10,6 +10,7 @@ import (
 
 	"github.com/benbjohnson/clock"
 	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	prommodel "github.com/prometheus/common/model"
 
 	"github.com/grafana/alerting/models"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -39,6 +40,14 @@ type folderService interface {
 	GetNamespaceByUID(ctx context.Context, uid string, orgID int64, user identity.Requester) (*folder.Folder, error)
 }
 
+type ruleReader interface {
+	ListAlertRules(ctx context.Context, query *ngmodels.ListAlertRulesQuery) (ngmodels.RulesGroup, error)
+}
+
+type alertmanagerConfigProvider interface {
+	GetAlertmanagerConfiguration(ctx context.Context, org int64, withAutogen bool) (apimodels.GettableUserConfig, error)
+}
+
 type TestingApiSrv struct {
 	*AlertingProxy
 	DatasourceCache datasources.CacheService
@@ -51,6 +60,8 @@ type TestingApiSrv struct {
 	appUrl          *url.URL
 	tracer          tracing.Tracer
 	folderService   folderService
+	ruleStore       ruleReader
+	amConfig        alertmanagerConfigProvider
 }
 
 // RouteTestGrafanaRuleConfig returns a list of potential alerts for a given rule configuration. This is intended to be
@@ -286,3 +297,129 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 	}
 	return response.JSON(http.StatusOK, body)
 }
+
+// BacktestNotifications evaluates existing rules over a time range and simulates the notifications that their alerts
+// would have produced with the notification policy tree and the mute timings of the current Alertmanager configuration,
+// or with the ones in the request.
+func (srv TestingApiSrv) BacktestNotifications(c *contextmodel.ReqContext, cmd apimodels.BacktestNotificationsConfig) response.Response {
+	if !srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingBacktesting) {
+		return ErrResp(http.StatusNotFound, nil, "Backtesting API is not enabled")
+	}
+	if !cmd.From.Before(cmd.To) {
+		return ErrResp(http.StatusBadRequest, nil, "From must be before To")
+	}
+	if len(cmd.RuleUIDs) == 0 && (cmd.NamespaceUID == "" || cmd.RuleGroup == "") {
+		return ErrResp(http.StatusBadRequest, nil, "either rule UIDs or a namespace and a rule group must be specified")
+	}
+
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+	query := &ngmodels.ListAlertRulesQuery{OrgID: orgID}
+	if len(cmd.RuleUIDs) > 0 {
+		query.RuleUIDs = cmd.RuleUIDs
+	} else {
+		query.NamespaceUIDs = []string{cmd.NamespaceUID}
+		query.RuleGroups = []string{cmd.RuleGroup}
+	}
+	rules, err := srv.ruleStore.ListAlertRules(ctx, query)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get rules")
+	}
+	if len(rules) == 0 {
+		return ErrResp(http.StatusNotFound, nil, "no rules found")
+	}
+	if len(cmd.RuleUIDs) > 0 && len(rules) != len(cmd.RuleUIDs) {
+		return ErrResp(http.StatusNotFound, nil, "some of the rules were not found")
+	}
+	if err := srv.authz.AuthorizeAccessToRuleGroup(ctx, c.SignedInUser, rules); err != nil {
+		return errorToResponse(err)
+	}
+	if err := srv.authz.AuthorizeDatasourceAccessForRuleGroup(ctx, c.SignedInUser, rules); err != nil {
+		return errorToResponse(err)
+	}
+
+	cfg, err := srv.amConfig.GetAlertmanagerConfiguration(ctx, orgID, true)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the Alertmanager configuration")
+	}
+	route := cfg.AlertmanagerConfig.Route
+	if cmd.Route != nil {
+		if err := cmd.Route.Validate(); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "invalid notification policy tree")
+		}
+		route = cmd.Route
+	}
+	policy := backtesting.NewNotificationPolicy(route, cfg.AlertmanagerConfig.TimeIntervals, cfg.AlertmanagerConfig.MuteTimeIntervals)
+	for _, ti := range cmd.TimeIntervals {
+		policy.TimeIntervals[ti.Name] = ti.TimeIntervals
+	}
+
+	includeFolder := !srv.cfg.ReservedLabels.IsReservedLabelDisabled(models.FolderTitleLabel)
+	folders := make(map[string]*folder.Folder)
+	simulated := make([]backtesting.SimulatedRule, 0, len(rules))
+	for _, rule := range rules {
+		f, ok := folders[rule.NamespaceUID]
+		if !ok {
+			f, err = srv.folderService.GetNamespaceByUID(ctx, rule.NamespaceUID, orgID, c.SignedInUser)
+			if err != nil {
+				return toNamespaceErrorResponse(err)
+			}
+			folders[rule.NamespaceUID] = f
+		}
+		simulated = append(simulated, backtesting.SimulatedRule{
+			Rule:        rule,
+			ExtraLabels: state.GetRuleExtraLabels(srv.log, rule, f.Fullpath, includeFolder),
+		})
+	}
+
+	result, err := srv.backtesting.TestNotifications(ctx, c.SignedInUser, simulated, policy, cmd.From, cmd.To)
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidInputData) {
+			return ErrResp(http.StatusBadRequest, err, "Failed to evaluate")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "Failed to evaluate")
+	}
+	return response.JSON(http.StatusOK, toBacktestNotificationsResult(result))
+}
+
+func toBacktestNotificationsResult(notifications map[string][]backtesting.Notification) apimodels.BacktestNotificationsResult {
+	toAlerts := func(alerts []backtesting.NotifiedAlert) []apimodels.BacktestNotificationAlert {
+		result := make([]apimodels.BacktestNotificationAlert, 0, len(alerts))
+		for _, a := range alerts {
+			result = append(result, apimodels.BacktestNotificationAlert{
+				Labels:   toLabelsMap(a.Labels),
+				StartsAt: a.StartsAt,
+				EndsAt:   a.EndsAt,
+			})
+		}
+		return result
+	}
+
+	result := apimodels.BacktestNotificationsResult{
+		Receivers: make(map[string][]apimodels.BacktestNotification, len(notifications)),
+	}
+	for receiver, ns := range notifications {
+		converted := make([]apimodels.BacktestNotification, 0, len(ns))
+		for _, n := range ns {
+			converted = append(converted, apimodels.BacktestNotification{
+				Time:        n.Time,
+				Receiver:    n.Receiver,
+				GroupKey:    n.GroupKey,
+				GroupLabels: toLabelsMap(n.GroupLabels),
+				Muted:       n.Muted,
+				Firing:      toAlerts(n.Firing),
+				Resolved:    toAlerts(n.Resolved),
+			})
+		}
+		result.Receivers[receiver] = converted
+	}
+	return result
+}
+
+func toLabelsMap(labels prommodel.LabelSet) map[string]string {
+	result := make(map[string]string, len(labels))
+	for name, value := range labels {
+		result[string(name)] = string(value)
+	}
+	return result
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"testing"
@@ -12,6 +13,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	acMock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
@@ -23,6 +25,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -391,6 +394,105 @@ func TestRouteEvalQueries(t *testing.T) {
 	})
 }
 
+type fakeAlertmanagerConfigProvider struct {
+	cfg definitions.GettableUserConfig
+}
+
+func (f *fakeAlertmanagerConfigProvider) GetAlertmanagerConfiguration(_ context.Context, _ int64, _ bool) (definitions.GettableUserConfig, error) {
+	return f.cfg, nil
+}
+
+func TestBacktestNotifications(t *testing.T) {
+	rc := &contextmodel.ReqContext{
+		Context: &web.Context{
+			Req: &http.Request{},
+		},
+		SignedInUser: &user.SignedInUser{
+			OrgID: 1,
+		},
+	}
+	from := time.Unix(0, 0)
+	f := randFolder()
+	gen := models.RuleGen
+	rules := gen.With(gen.WithOrgID(1), gen.WithNamespace(f), gen.WithGroupName("group"), gen.WithInterval(time.Minute), gen.WithFor(0), gen.WithNoNotificationSettings()).GenerateManyRef(2)
+
+	createSrv := func(t *testing.T, permissions []ac.Permission) *TestingApiSrv {
+		evaluator := &eval_mocks.ConditionEvaluatorMock{}
+		evaluator.On("Evaluate", mock.Anything, mock.Anything).Return(func(_ context.Context, now time.Time) eval.Results {
+			return eval.Results{{State: eval.Alerting, Instance: data.Labels{}, EvaluatedAt: now}}
+		}, nil)
+		ruleStore := fakes2.NewRuleStore(t)
+		ruleStore.Folders[rc.OrgID] = []*folder.Folder{f}
+		ruleStore.PutRule(context.Background(), rules...)
+
+		srv := createTestingApiSrv(t, nil, acMock.New().WithPermissions(permissions), eval_mocks.NewEvaluatorFactory(evaluator), featuremgmt.WithFeatures(featuremgmt.FlagAlertingBacktesting), ruleStore)
+		srv.backtesting = backtesting.NewEngine(nil, srv.evaluator, srv.tracer)
+		srv.log = log.NewNopLogger()
+		srv.amConfig = &fakeAlertmanagerConfigProvider{cfg: definitions.GettableUserConfig{
+			AlertmanagerConfig: definitions.GettableApiAlertingConfig{
+				Config: definitions.Config{Route: &definitions.Route{Receiver: "default"}},
+			},
+		}}
+		return srv
+	}
+	allowed := []ac.Permission{
+		{Action: dashboards.ActionFoldersRead, Scope: dashboards.ScopeFoldersAll},
+		{Action: ac.ActionAlertingRuleRead, Scope: dashboards.ScopeFoldersAll},
+		{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceAllScope()},
+	}
+
+	t.Run("should return 404 if backtesting is disabled", func(t *testing.T) {
+		srv := createSrv(t, allowed)
+		srv.featureManager = featuremgmt.WithFeatures()
+		response := srv.BacktestNotifications(rc, definitions.BacktestNotificationsConfig{From: from, To: from.Add(time.Hour), RuleUIDs: []string{rules[0].UID}})
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("should return 400 if request is invalid", func(t *testing.T) {
+		srv := createSrv(t, allowed)
+		response := srv.BacktestNotifications(rc, definitions.BacktestNotificationsConfig{From: from, To: from, RuleUIDs: []string{rules[0].UID}})
+		require.Equal(t, http.StatusBadRequest, response.Status())
+		response = srv.BacktestNotifications(rc, definitions.BacktestNotificationsConfig{From: from, To: from.Add(time.Hour), NamespaceUID: f.UID})
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("should return 404 if rules do not exist", func(t *testing.T) {
+		srv := createSrv(t, allowed)
+		response := srv.BacktestNotifications(rc, definitions.BacktestNotificationsConfig{From: from, To: from.Add(time.Hour), RuleUIDs: []string{rules[0].UID, "missing"}})
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("should return Forbidden if user cannot query a data source", func(t *testing.T) {
+		srv := createSrv(t, allowed[:2])
+		response := srv.BacktestNotifications(rc, definitions.BacktestNotificationsConfig{From: from, To: from.Add(time.Hour), NamespaceUID: f.UID, RuleGroup: "group"})
+		require.Equal(t, http.StatusForbidden, response.Status())
+	})
+
+	t.Run("should return notifications of the rule group", func(t *testing.T) {
+		srv := createSrv(t, allowed)
+		response := srv.BacktestNotifications(rc, definitions.BacktestNotificationsConfig{
+			From:         from,
+			To:           from.Add(time.Hour),
+			NamespaceUID: f.UID,
+			RuleGroup:    "group",
+			Route:        &definitions.Route{Receiver: "override", GroupByStr: []string{"alertname"}},
+		})
+		require.Equal(t, http.StatusOK, response.Status())
+
+		var result definitions.BacktestNotificationsResult
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result.Receivers, 1)
+		notifications := result.Receivers["override"]
+		// one group for every rule
+		require.Len(t, notifications, 2)
+		for _, n := range notifications {
+			require.Len(t, n.Firing, 1)
+			require.Equal(t, n.GroupLabels["alertname"], n.Firing[0].Labels["alertname"])
+			require.Equal(t, f.Fullpath, n.Firing[0].Labels[models.FolderTitleLabel])
+		}
+	})
+}
+
 func createTestingApiSrv(t *testing.T, ds *fakes.FakeCacheService, ac *acMock.Mock, evaluator eval.EvaluatorFactory, featureManager featuremgmt.FeatureToggles, ruleStore RuleStore) *TestingApiSrv {
 	if ac == nil {
 		ac = acMock.New()
@@ -404,5 +506,6 @@ func createTestingApiSrv(t *testing.T, ds *fakes.FakeCacheService, ac *acMock.Mo
 		tracer:          tracing.InitializeTracerForTest(),
 		featureManager:  featureManager,
 		folderService:   ruleStore,
+		ruleStore:       ruleStore,
 	}
 }
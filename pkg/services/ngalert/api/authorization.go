@@ -51,7 +51,8 @@ func (api *API) authorize(method, path string) web.Handler {
 		eval = ac.EvalAll(ac.EvalPermission(ac.ActionAlertingRuleRead, scope),
 			ac.EvalPermission(dashboards.ActionFoldersRead, scope),
 		)
-	case http.MethodPost + "/api/ruler/grafana/api/v1/rules/{Namespace}":
+	case http.MethodPost + "/api/ruler/grafana/api/v1/rules/{Namespace}",
+		http.MethodPost + "/api/ruler/grafana/api/v1/rules/{Namespace}/import":
 		scope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(ac.Parameter(":Namespace"))
 		// more granular permissions are enforced by the handler via "authorizeRuleChanges"
 		eval = ac.EvalAll(
@@ -96,6 +97,12 @@ func (api *API) authorize(method, path string) web.Handler {
 	case http.MethodPost + "/api/v1/rule/backtest":
 		// additional authorization is done in the request handler
 		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
+	case http.MethodPost + "/api/v1/rule/backtest/notifications":
+		// additional authorization is done in the request handler
+		eval = ac.EvalAll(
+			ac.EvalPermission(ac.ActionAlertingRuleRead),
+			ac.EvalPermission(ac.ActionAlertingNotificationsRead),
+		)
 	case http.MethodPost + "/api/v1/eval":
 		// additional authorization is done in the request handler
 		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
@@ -291,6 +298,14 @@ func (api *API) authorize(method, path string) web.Handler {
 			),
 		)
 
+	case http.MethodGet + "/api/v1/provisioning/rule-templates",
+		http.MethodGet + "/api/v1/provisioning/rule-templates/{UID}":
+		eval = ac.EvalAny(
+			ac.EvalPermission(ac.ActionAlertingProvisioningRead),
+			ac.EvalPermission(ac.ActionAlertingRulesProvisioningRead),
+			ac.EvalPermission(ac.ActionAlertingProvisioningReadSecrets),
+		)
+
 	case http.MethodGet + "/api/v1/provisioning/policies",
 		http.MethodGet + "/api/v1/provisioning/contact-points",
 		http.MethodGet + "/api/v1/provisioning/templates",
@@ -361,6 +376,15 @@ func (api *API) authorize(method, path string) web.Handler {
 			),
 		)
 
+	// Updating a template updates the rules that are derived from it in all folders.
+	case http.MethodPost + "/api/v1/provisioning/rule-templates",
+		http.MethodPut + "/api/v1/provisioning/rule-templates/{UID}",
+		http.MethodDelete + "/api/v1/provisioning/rule-templates/{UID}":
+		eval = ac.EvalAny(
+			ac.EvalPermission(ac.ActionAlertingProvisioningWrite),
+			ac.EvalPermission(ac.ActionAlertingRulesProvisioningWrite),
+		)
+
 	case http.MethodPut + "/api/v1/provisioning/policies",
 		http.MethodDelete + "/api/v1/provisioning/policies",
 		http.MethodPost + "/api/v1/provisioning/contact-points",
@@ -28,11 +28,14 @@ func AlertRuleFromProvisionedAlertRule(a definitions.ProvisionedAlertRule) (mode
 		NoDataState:          models.NoDataState(a.NoDataState),          // TODO there must be a validation
 		ExecErrState:         models.ExecutionErrorState(a.ExecErrState), // TODO there must be a validation
 		For:                  time.Duration(a.For),
+		KeepFiringFor:        time.Duration(a.KeepFiringFor),
 		Annotations:          a.Annotations,
 		Labels:               a.Labels,
 		IsPaused:             a.IsPaused,
 		NotificationSettings: NotificationSettingsFromAlertRuleNotificationSettings(a.NotificationSettings),
 		Record:               ModelRecordFromApiRecord(a.Record),
+		DependsOn:            a.DependsOn,
+		Template:             ModelRuleTemplateRefFromApiRuleTemplateRef(a.Template),
 	}, nil
 }
 
@@ -46,6 +49,7 @@ func ProvisionedAlertRuleFromAlertRule(rule models.AlertRule, provenance models.
 		RuleGroup:            rule.RuleGroup,
 		Title:                rule.Title,
 		For:                  model.Duration(rule.For),
+		KeepFiringFor:        model.Duration(rule.KeepFiringFor),
 		Condition:            rule.Condition,
 		Data:                 ApiAlertQueriesFromAlertQueries(rule.Data),
 		Updated:              rule.Updated,
@@ -57,6 +61,8 @@ func ProvisionedAlertRuleFromAlertRule(rule models.AlertRule, provenance models.
 		IsPaused:             rule.IsPaused,
 		NotificationSettings: AlertRuleNotificationSettingsFromNotificationSettings(rule.NotificationSettings),
 		Record:               ApiRecordFromModelRecord(rule.Record),
+		DependsOn:            rule.DependsOn,
+		Template:             ApiRuleTemplateRefFromModelRuleTemplateRef(rule.Template),
 	}
 }
 
@@ -183,6 +189,7 @@ func AlertRuleExportFromAlertRule(rule models.AlertRule) (definitions.AlertRuleE
 		UID:                  rule.UID,
 		Title:                rule.Title,
 		For:                  model.Duration(rule.For),
+		KeepFiringFor:        model.Duration(rule.KeepFiringFor),
 		Condition:            rule.Condition,
 		Data:                 data,
 		DashboardUID:         rule.DashboardUID,
@@ -192,10 +199,17 @@ func AlertRuleExportFromAlertRule(rule models.AlertRule) (definitions.AlertRuleE
 		IsPaused:             rule.IsPaused,
 		NotificationSettings: AlertRuleNotificationSettingsExportFromNotificationSettings(rule.NotificationSettings),
 		Record:               AlertRuleRecordExportFromRecord(rule.Record),
+		DependsOn:            rule.DependsOn,
 	}
 	if rule.For.Seconds() > 0 {
 		result.ForString = util.Pointer(model.Duration(rule.For).String())
 	}
+	if rule.KeepFiringFor.Seconds() > 0 {
+		result.KeepFiringForString = util.Pointer(model.Duration(rule.KeepFiringFor).String())
+	}
+	if len(rule.DependsOn) > 0 {
+		result.DependsOnHCL = &rule.DependsOn
+	}
 	if rule.Annotations != nil {
 		result.Annotations = &rule.Annotations
 	}
@@ -485,3 +499,80 @@ func ApiRecordFromModelRecord(r *models.Record) *definitions.Record {
 		From:   r.From,
 	}
 }
+
+func ModelRuleTemplateRefFromApiRuleTemplateRef(r *definitions.RuleTemplateRef) *models.AlertRuleTemplateRef {
+	if r == nil {
+		return nil
+	}
+	return &models.AlertRuleTemplateRef{
+		UID:        r.UID,
+		Parameters: r.Parameters,
+	}
+}
+
+func ApiRuleTemplateRefFromModelRuleTemplateRef(r *models.AlertRuleTemplateRef) *definitions.RuleTemplateRef {
+	if r == nil {
+		return nil
+	}
+	return &definitions.RuleTemplateRef{
+		UID:        r.UID,
+		Parameters: r.Parameters,
+	}
+}
+
+// AlertRuleTemplateFromRuleTemplate converts definitions.RuleTemplate to models.AlertRuleTemplate
+func AlertRuleTemplateFromRuleTemplate(orgID int64, t definitions.RuleTemplate) models.AlertRuleTemplate {
+	params := make([]models.AlertRuleTemplateParameter, 0, len(t.Parameters))
+	for _, p := range t.Parameters {
+		params = append(params, models.AlertRuleTemplateParameter{
+			Name:        p.Name,
+			Type:        models.AlertRuleTemplateParameterType(p.Type),
+			Description: p.Description,
+			Default:     p.Default,
+		})
+	}
+	return models.AlertRuleTemplate{
+		OrgID:        orgID,
+		UID:          t.UID,
+		Title:        t.Title,
+		Description:  t.Description,
+		Parameters:   params,
+		RuleTitle:    t.RuleTitle,
+		Condition:    t.Condition,
+		Data:         AlertQueriesFromApiAlertQueries(t.Data),
+		NoDataState:  models.NoDataState(t.NoDataState),
+		ExecErrState: models.ExecutionErrorState(t.ExecErrState),
+		For:          time.Duration(t.For),
+		Annotations:  t.Annotations,
+		Labels:       t.Labels,
+	}
+}
+
+// RuleTemplateFromAlertRuleTemplate converts models.AlertRuleTemplate to definitions.RuleTemplate and sets provided provenance status
+func RuleTemplateFromAlertRuleTemplate(t models.AlertRuleTemplate, provenance models.Provenance) definitions.RuleTemplate {
+	params := make([]definitions.RuleTemplateParameter, 0, len(t.Parameters))
+	for _, p := range t.Parameters {
+		params = append(params, definitions.RuleTemplateParameter{
+			Name:        p.Name,
+			Type:        string(p.Type),
+			Description: p.Description,
+			Default:     p.Default,
+		})
+	}
+	return definitions.RuleTemplate{
+		UID:          t.UID,
+		Title:        t.Title,
+		Description:  t.Description,
+		Parameters:   params,
+		RuleTitle:    t.RuleTitle,
+		Condition:    t.Condition,
+		Data:         ApiAlertQueriesFromAlertQueries(t.Data),
+		NoDataState:  definitions.NoDataState(t.NoDataState),
+		ExecErrState: definitions.ExecutionErrorState(t.ExecErrState),
+		For:          model.Duration(t.For),
+		Annotations:  t.Annotations,
+		Labels:       t.Labels,
+		Version:      t.Version,
+		Provenance:   definitions.Provenance(provenance),
+	}
+}
@@ -51,7 +51,7 @@ func ContactPointToContactPointExport(cp definitions.ContactPoint) (notify.APIRe
 		len(cp.Pagerduty) + len(cp.OnCall) + len(cp.Pushover) + len(cp.Sensugo) +
 		len(cp.Sns) + len(cp.Slack) + len(cp.Teams) + len(cp.Telegram) +
 		len(cp.Threema) + len(cp.Victorops) + len(cp.Webhook) + len(cp.Wecom) +
-		len(cp.Webex)
+		len(cp.Webex) + len(cp.TeamsWorkflows) + len(cp.Matrix) + len(cp.Zulip) + len(cp.Ntfy)
 
 	integration := make([]*notify.GrafanaIntegrationConfig, 0, contactPointsLength)
 
@@ -203,6 +203,34 @@ func ContactPointToContactPointExport(cp definitions.ContactPoint) (notify.APIRe
 		}
 		integration = append(integration, el)
 	}
+	for _, i := range cp.TeamsWorkflows {
+		el, err := marshallIntegration(j, "teams_workflows", i, i.DisableResolveMessage)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		integration = append(integration, el)
+	}
+	for _, i := range cp.Matrix {
+		el, err := marshallIntegration(j, "matrix", i, i.DisableResolveMessage)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		integration = append(integration, el)
+	}
+	for _, i := range cp.Zulip {
+		el, err := marshallIntegration(j, "zulip", i, i.DisableResolveMessage)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		integration = append(integration, el)
+	}
+	for _, i := range cp.Ntfy {
+		el, err := marshallIntegration(j, "ntfy", i, i.DisableResolveMessage)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		integration = append(integration, el)
+	}
 
 	if len(errs) > 0 {
 		return notify.APIReceiver{}, errors.Join(errs...)
@@ -344,6 +372,26 @@ func parseIntegration(json jsoniter.API, result *definitions.ContactPoint, recei
 		if err = json.Unmarshal(data, &integration); err == nil {
 			result.Webex = append(result.Webex, integration)
 		}
+	case "teams_workflows":
+		integration := definitions.TeamsWorkflowsIntegration{DisableResolveMessage: disable}
+		if err = json.Unmarshal(data, &integration); err == nil {
+			result.TeamsWorkflows = append(result.TeamsWorkflows, integration)
+		}
+	case "matrix":
+		integration := definitions.MatrixIntegration{DisableResolveMessage: disable}
+		if err = json.Unmarshal(data, &integration); err == nil {
+			result.Matrix = append(result.Matrix, integration)
+		}
+	case "zulip":
+		integration := definitions.ZulipIntegration{DisableResolveMessage: disable}
+		if err = json.Unmarshal(data, &integration); err == nil {
+			result.Zulip = append(result.Zulip, integration)
+		}
+	case "ntfy":
+		integration := definitions.NtfyIntegration{DisableResolveMessage: disable}
+		if err = json.Unmarshal(data, &integration); err == nil {
+			result.Ntfy = append(result.Ntfy, integration)
+		}
 	default:
 		err = fmt.Errorf("integration %s is not supported", receiverType)
 	}
@@ -383,6 +431,12 @@ func (c contactPointsExtension) UpdateStructDescriptor(structDescriptor *jsonite
 		desc.Decoder = codec
 		desc.Encoder = codec
 	}
+	if structDescriptor.Type == reflect2.TypeOf(definitions.NtfyIntegration{}) {
+		codec := &numberAsStringCodec{}
+		desc := structDescriptor.GetField("Priority")
+		desc.Decoder = codec
+		desc.Encoder = codec
+	}
 	if structDescriptor.Type == reflect2.TypeOf(definitions.OnCallIntegration{}) {
 		codec := &numberAsStringCodec{ignoreError: true}
 		desc := structDescriptor.GetField("MaxAlerts")
@@ -13,6 +13,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 )
 
@@ -89,6 +90,35 @@ func TestContactPointFromContactPointExports(t *testing.T) {
 		})
 	}
 
+	for integrationType, cfg := range notifier.GrafanaIntegrationConfigsForTesting {
+		t.Run(integrationType, func(t *testing.T) {
+			recCfg := &notify.APIReceiver{
+				ConfigReceiver: notify.ConfigReceiver{Name: "test-receiver"},
+				GrafanaIntegrations: notify.GrafanaIntegrations{
+					Integrations: []*notify.GrafanaIntegrationConfig{
+						cfg.GetRawNotifierConfig("test"),
+					},
+				},
+			}
+
+			expected, err := notifier.BuildReceiverConfiguration(context.Background(), recCfg, notify.GetDecryptedValueFnForTesting)
+			require.NoError(t, err)
+
+			result, err := ContactPointFromContactPointExport(getContactPointExport(t, recCfg))
+			require.NoError(t, err)
+
+			back, err := ContactPointToContactPointExport(result)
+			require.NoError(t, err)
+
+			actual, err := notifier.BuildReceiverConfiguration(context.Background(), &back, notify.GetDecryptedValueFnForTesting)
+			require.NoError(t, err)
+
+			require.Len(t, actual.GrafanaIntegrationConfigs, 1)
+			require.Equal(t, expected.GrafanaIntegrationConfigs[0].Settings, actual.GrafanaIntegrationConfigs[0].Settings)
+			require.Equal(t, expected.GrafanaIntegrationConfigs[0].DisableResolveMessage, actual.GrafanaIntegrationConfigs[0].DisableResolveMessage)
+		})
+	}
+
 	t.Run("pushover optional numbers as string", func(t *testing.T) {
 		export := definitions.ContactPointExport{
 			Name: "test",
@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 func TestToModel(t *testing.T) {
@@ -35,3 +36,18 @@ func TestToModel(t *testing.T) {
 		require.Len(t, tm.Rules, 1)
 	})
 }
+
+func TestAlertRuleExportFromAlertRule(t *testing.T) {
+	t.Run("depends on is exported for HCL only if the rule has dependencies", func(t *testing.T) {
+		rule := models.RuleGen.With(models.RuleGen.WithDependsOn()).Generate()
+		export, err := AlertRuleExportFromAlertRule(rule)
+		require.NoError(t, err)
+		require.Nil(t, export.DependsOnHCL)
+
+		rule.DependsOn = []string{"upstream"}
+		export, err = AlertRuleExportFromAlertRule(rule)
+		require.NoError(t, err)
+		require.Equal(t, []string{"upstream"}, export.DependsOn)
+		require.Equal(t, &[]string{"upstream"}, export.DependsOnHCL)
+	})
+}
@@ -113,6 +113,17 @@ func (f *RulerApiHandler) handleRoutePostRulesGroupForExport(ctx *contextmodel.R
 	return f.GrafanaRuler.ExportFromPayload(ctx, conf, namespace)
 }
 
+func (f *RulerApiHandler) handleRoutePostRulesImport(ctx *contextmodel.ReqContext, conf apimodels.PostablePrometheusRules, namespace string) response.Response {
+	ds, err := f.DatasourceCache.GetDatasourceByUID(ctx.Req.Context(), conf.DatasourceUID, ctx.SignedInUser, ctx.SkipDSCache)
+	if err != nil {
+		return errorToResponse(err)
+	}
+	if ds.Type != datasources.DS_PROMETHEUS && ds.Type != datasources.DS_LOKI {
+		return errorToResponse(unexpectedDatasourceTypeError(ds.Type, "loki, prometheus"))
+	}
+	return f.GrafanaRuler.ImportPrometheusRules(ctx, conf, namespace, ds)
+}
+
 func (f *RulerApiHandler) handleRouteGetRulesForExport(ctx *contextmodel.ReqContext) response.Response {
 	return f.GrafanaRuler.ExportRules(ctx)
 }
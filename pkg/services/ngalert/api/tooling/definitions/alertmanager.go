@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/go-openapi/strfmt"
@@ -250,21 +251,20 @@ import (
 // Alias all the needed Alertmanager types, functions and constants so that they can be imported directly from grafana/alerting
 // without having to modify any of the usage within Grafana.
 type (
-	Config                    = definition.Config
-	Route                     = definition.Route
-	PostableGrafanaReceiver   = definition.PostableGrafanaReceiver
-	PostableApiAlertingConfig = definition.PostableApiAlertingConfig
-	RawMessage                = definition.RawMessage
-	Provenance                = definition.Provenance
-	ObjectMatchers            = definition.ObjectMatchers
-	PostableApiReceiver       = definition.PostableApiReceiver
-	PostableGrafanaReceivers  = definition.PostableGrafanaReceivers
-	ReceiverType              = definition.ReceiverType
+	Config                   = definition.Config
+	Route                    = definition.Route
+	PostableGrafanaReceiver  = definition.PostableGrafanaReceiver
+	RawMessage               = definition.RawMessage
+	Provenance               = definition.Provenance
+	ObjectMatchers           = definition.ObjectMatchers
+	PostableGrafanaReceivers = definition.PostableGrafanaReceivers
+	ReceiverType             = definition.ReceiverType
 )
 
 const (
 	GrafanaReceiverType      = definition.GrafanaReceiverType
 	AlertmanagerReceiverType = definition.AlertmanagerReceiverType
+	EmptyReceiverType        = definition.EmptyReceiverType
 )
 
 var (
@@ -670,6 +670,34 @@ func (c *PostableUserConfig) validate() error {
 	return nil
 }
 
+// ReceiverNotificationLimits limits the notifications that are sent by the integrations of a receiver.
+type ReceiverNotificationLimits struct {
+	// RateLimit is the maximum number of notifications that every integration of the receiver sends per
+	// RateLimitInterval. Notifications over the limit are dropped. Zero means no limit.
+	RateLimit int `yaml:"rate_limit,omitempty" json:"rate_limit,omitempty"`
+	// RateLimitInterval is the interval of the rate limit. Defaults to one hour.
+	RateLimitInterval model.Duration `yaml:"rate_limit_interval,omitempty" json:"rate_limit_interval,omitempty"`
+	// DigestWindow enables the digest mode of the receiver. The notifications that are sent within the window are
+	// batched into a single notification that summarizes the latest state of all their alerts.
+	DigestWindow model.Duration `yaml:"digest_window,omitempty" json:"digest_window,omitempty"`
+}
+
+func (l ReceiverNotificationLimits) Validate() error {
+	if l.RateLimit < 0 {
+		return fmt.Errorf("rate limit must not be negative")
+	}
+	if l.RateLimitInterval < 0 {
+		return fmt.Errorf("rate limit interval must not be negative")
+	}
+	if l.RateLimitInterval > 0 && l.RateLimit == 0 {
+		return fmt.Errorf("rate limit interval is set but rate limit is not")
+	}
+	if l.DigestWindow < 0 {
+		return fmt.Errorf("digest window must not be negative")
+	}
+	return nil
+}
+
 // Decrypt returns a copy of the configuration struct with decrypted secure settings in receivers.
 func (c *PostableUserConfig) Decrypt(decryptFn func(payload []byte) ([]byte, error)) (PostableUserConfig, error) {
 	newCfg, ok := deepcopy.Copy(c).(*PostableUserConfig)
@@ -831,6 +859,117 @@ type GettableHistoricUserConfigs struct {
 	Body []GettableHistoricUserConfig
 }
 
+// PostableApiAlertingConfig is the Alertmanager configuration with the Grafana receivers, which can have
+// notification limits.
+type PostableApiAlertingConfig struct {
+	Config `yaml:",inline"`
+
+	// Override with our superset receiver type
+	Receivers []*PostableApiReceiver `yaml:"receivers,omitempty" json:"receivers,omitempty"`
+}
+
+func (c *PostableApiAlertingConfig) GetReceivers() []*PostableApiReceiver {
+	return c.Receivers
+}
+
+func (c *PostableApiAlertingConfig) GetMuteTimeIntervals() []config.MuteTimeInterval {
+	return c.MuteTimeIntervals
+}
+
+func (c *PostableApiAlertingConfig) GetTimeIntervals() []config.TimeInterval { return c.TimeIntervals }
+
+func (c *PostableApiAlertingConfig) GetRoute() *Route {
+	return c.Route
+}
+
+func (c *PostableApiAlertingConfig) UnmarshalJSON(b []byte) error {
+	return yaml.Unmarshal(b, c)
+}
+
+func (c *PostableApiAlertingConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain PostableApiAlertingConfig
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+
+	// Since Config implements yaml.Unmarshaler, we must handle _all_ other fields independently.
+	// Otherwise, the json decoder will detect this and only use the embedded type.
+	// Additionally, we'll use pointers to slices in order to reference the intended target.
+	type overrides struct {
+		Receivers *[]*PostableApiReceiver `yaml:"receivers" json:"receivers,omitempty"`
+	}
+
+	if err := unmarshal(&overrides{Receivers: &c.Receivers}); err != nil {
+		return err
+	}
+
+	return c.Validate()
+}
+
+// Validate ensures that the two routing trees use the correct receiver types.
+func (c *PostableApiAlertingConfig) Validate() error {
+	receivers := make(map[string]struct{}, len(c.Receivers))
+
+	var hasGrafReceivers, hasAMReceivers bool
+	for _, r := range c.Receivers {
+		receivers[r.Name] = struct{}{}
+		if r.NotificationLimits != nil {
+			if err := r.NotificationLimits.Validate(); err != nil {
+				return fmt.Errorf("invalid notification limits of receiver %q: %w", r.Name, err)
+			}
+		}
+		switch r.Type() {
+		case GrafanaReceiverType:
+			hasGrafReceivers = true
+		case AlertmanagerReceiverType:
+			hasAMReceivers = true
+		default:
+			continue
+		}
+	}
+
+	if hasGrafReceivers && hasAMReceivers {
+		return fmt.Errorf("cannot mix Alertmanager & Grafana receiver types")
+	}
+
+	// Taken from https://github.com/prometheus/alertmanager/blob/14cbe6301c732658d6fe877ec55ad5b738abcf06/config/config.go#L171-L192
+	// Check if we have a root route. We cannot check for it in the
+	// UnmarshalYAML method because it won't be called if the input is empty
+	// (e.g. the config file is empty or only contains whitespace).
+	if c.Route == nil {
+		return fmt.Errorf("no route provided in config")
+	}
+
+	// Check if continue in root route.
+	if c.Route.Continue {
+		return fmt.Errorf("cannot have continue in root route")
+	}
+
+	for _, receiver := range AllReceivers(c.Route.AsAMRoute()) {
+		_, ok := receivers[receiver]
+		if !ok {
+			return fmt.Errorf("unexpected receiver (%s) is undefined", receiver)
+		}
+	}
+
+	return nil
+}
+
+// ReceiverType requires validate has been called and just checks the first receiver type
+func (c *PostableApiAlertingConfig) ReceiverType() ReceiverType {
+	for _, r := range c.Receivers {
+		switch r.Type() {
+		case GrafanaReceiverType:
+			return GrafanaReceiverType
+		case AlertmanagerReceiverType:
+			return AlertmanagerReceiverType
+		default:
+			continue
+		}
+	}
+	return EmptyReceiverType
+}
+
 type GettableApiAlertingConfig struct {
 	Config              `yaml:",inline"`
 	MuteTimeProvenances map[string]Provenance `yaml:"muteTimeProvenances,omitempty" json:"muteTimeProvenances,omitempty"`
@@ -933,9 +1072,93 @@ type GettableGrafanaReceiver struct {
 	Provenance            Provenance      `json:"provenance,omitempty"`
 }
 
+// PostableApiReceiver is a receiver of the Alertmanager configuration. Unlike the receivers of the upstream
+// Alertmanager, Grafana receivers can limit the notifications of their integrations.
+type PostableApiReceiver struct {
+	config.Receiver          `yaml:",inline"`
+	PostableGrafanaReceivers `yaml:",inline"`
+	// NotificationLimits limits the notifications that are sent by the integrations of the receiver.
+	NotificationLimits *ReceiverNotificationLimits `yaml:"notification_limits,omitempty" json:"notification_limits,omitempty"`
+}
+
+func (r *PostableApiReceiver) UnmarshalJSON(b []byte) error {
+	return yaml.Unmarshal(b, r)
+}
+
+func (r *PostableApiReceiver) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&r.PostableGrafanaReceivers); err != nil {
+		return err
+	}
+
+	type plain config.Receiver
+	if err := unmarshal((*plain)(&r.Receiver)); err != nil {
+		return err
+	}
+
+	// config.Receiver implements yaml.Unmarshaler, so the fields of the superset type are decoded independently.
+	type overrides struct {
+		NotificationLimits *ReceiverNotificationLimits `yaml:"notification_limits,omitempty"`
+	}
+	var o overrides
+	if err := unmarshal(&o); err != nil {
+		return err
+	}
+	r.NotificationLimits = o.NotificationLimits
+
+	hasGrafanaReceivers := len(r.PostableGrafanaReceivers.GrafanaManagedReceivers) > 0
+
+	if hasGrafanaReceivers {
+		if len(r.EmailConfigs) > 0 {
+			return fmt.Errorf("cannot have both Alertmanager EmailConfigs & Grafana receivers together")
+		}
+		if len(r.PagerdutyConfigs) > 0 {
+			return fmt.Errorf("cannot have both Alertmanager PagerdutyConfigs & Grafana receivers together")
+		}
+		if len(r.SlackConfigs) > 0 {
+			return fmt.Errorf("cannot have both Alertmanager SlackConfigs & Grafana receivers together")
+		}
+		if len(r.WebhookConfigs) > 0 {
+			return fmt.Errorf("cannot have both Alertmanager WebhookConfigs & Grafana receivers together")
+		}
+		if len(r.OpsGenieConfigs) > 0 {
+			return fmt.Errorf("cannot have both Alertmanager OpsGenieConfigs & Grafana receivers together")
+		}
+		if len(r.WechatConfigs) > 0 {
+			return fmt.Errorf("cannot have both Alertmanager WechatConfigs & Grafana receivers together")
+		}
+		if len(r.PushoverConfigs) > 0 {
+			return fmt.Errorf("cannot have both Alertmanager PushoverConfigs & Grafana receivers together")
+		}
+		if len(r.VictorOpsConfigs) > 0 {
+			return fmt.Errorf("cannot have both Alertmanager VictorOpsConfigs & Grafana receivers together")
+		}
+	}
+	return nil
+}
+
+func (r *PostableApiReceiver) Type() ReceiverType {
+	if len(r.PostableGrafanaReceivers.GrafanaManagedReceivers) > 0 {
+		return GrafanaReceiverType
+	}
+
+	cpy := r.Receiver
+	cpy.Name = ""
+	if reflect.ValueOf(cpy).IsZero() {
+		return EmptyReceiverType
+	}
+
+	return AlertmanagerReceiverType
+}
+
+func (r *PostableApiReceiver) GetName() string {
+	return r.Receiver.Name
+}
+
 type GettableApiReceiver struct {
 	config.Receiver          `yaml:",inline"`
 	GettableGrafanaReceivers `yaml:",inline"`
+	// NotificationLimits limits the notifications that are sent by the integrations of the receiver.
+	NotificationLimits *ReceiverNotificationLimits `yaml:"notification_limits,omitempty" json:"notification_limits,omitempty"`
 }
 
 func (r *GettableApiReceiver) UnmarshalJSON(b []byte) error {
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/prometheus/alertmanager/config"
 	"github.com/prometheus/common/model"
@@ -215,3 +216,28 @@ func Test_RawMessageMarshaling(t *testing.T) {
 		assert.Equal(t, RawMessage(`{"data":"test"}`), n.Field)
 	})
 }
+
+func Test_PostableApiReceiverNotificationLimits(t *testing.T) {
+	in := `{
+		"name": "receiver",
+		"notification_limits": {"rate_limit": 10, "rate_limit_interval": "1h", "digest_window": "5m"},
+		"grafana_managed_receiver_configs": [{"uid": "uid", "name": "receiver", "type": "slack", "settings": {}}]
+	}`
+
+	var r PostableApiReceiver
+	require.NoError(t, json.Unmarshal([]byte(in), &r))
+	require.Equal(t, "receiver", r.Name)
+	require.Len(t, r.GrafanaManagedReceivers, 1)
+	expected := &ReceiverNotificationLimits{
+		RateLimit:         10,
+		RateLimitInterval: model.Duration(time.Hour),
+		DigestWindow:      model.Duration(5 * time.Minute),
+	}
+	require.Equal(t, expected, r.NotificationLimits)
+
+	out, err := json.Marshal(&r)
+	require.NoError(t, err)
+	var roundtrip PostableApiReceiver
+	require.NoError(t, json.Unmarshal(out, &roundtrip))
+	require.Equal(t, r, roundtrip)
+}
@@ -3,6 +3,7 @@ package definitions
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/prometheus/alertmanager/config"
 	"github.com/prometheus/alertmanager/timeinterval"
@@ -479,3 +480,71 @@ func TestValidateNotificationTemplates(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateReceiverNotificationLimits(t *testing.T) {
+	cases := []struct {
+		desc   string
+		limits ReceiverNotificationLimits
+		expMsg string
+	}{
+		{
+			desc:   "empty",
+			limits: ReceiverNotificationLimits{},
+		},
+		{
+			desc: "rate limit and digest",
+			limits: ReceiverNotificationLimits{
+				RateLimit:         10,
+				RateLimitInterval: model.Duration(time.Hour),
+				DigestWindow:      model.Duration(5 * time.Minute),
+			},
+		},
+		{
+			desc:   "negative rate limit",
+			limits: ReceiverNotificationLimits{RateLimit: -1},
+			expMsg: "rate limit must not be negative",
+		},
+		{
+			desc:   "negative rate limit interval",
+			limits: ReceiverNotificationLimits{RateLimit: 1, RateLimitInterval: model.Duration(-time.Hour)},
+			expMsg: "rate limit interval must not be negative",
+		},
+		{
+			desc:   "rate limit interval without rate limit",
+			limits: ReceiverNotificationLimits{RateLimitInterval: model.Duration(time.Hour)},
+			expMsg: "rate limit interval is set but rate limit is not",
+		},
+		{
+			desc:   "negative digest window",
+			limits: ReceiverNotificationLimits{DigestWindow: model.Duration(-time.Minute)},
+			expMsg: "digest window must not be negative",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			err := c.limits.Validate()
+			if c.expMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, c.expMsg)
+		})
+	}
+}
+
+func TestValidateReceiverNotificationLimitsOfReceivers(t *testing.T) {
+	cfg := PostableApiAlertingConfig{
+		Config: Config{Route: &Route{Receiver: "receiver"}},
+		Receivers: []*PostableApiReceiver{
+			{
+				Receiver:           config.Receiver{Name: "receiver"},
+				NotificationLimits: &ReceiverNotificationLimits{RateLimit: 10},
+			},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Receivers[0].NotificationLimits.RateLimit = -1
+	require.EqualError(t, cfg.Validate(), `invalid notification limits of receiver "receiver": rate limit must not be negative`)
+}
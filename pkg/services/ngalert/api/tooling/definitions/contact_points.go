@@ -100,6 +100,33 @@ type OnCallIntegration struct {
 	Message                  *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
 }
 
+type MatrixIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	HomeserverURL string `json:"homeserver_url" yaml:"homeserver_url" hcl:"homeserver_url"`
+	RoomID        string `json:"room_id" yaml:"room_id" hcl:"room_id"`
+	AccessToken   Secret `json:"access_token" yaml:"access_token" hcl:"access_token"`
+
+	MessageType *string `json:"msg_type,omitempty" yaml:"msg_type,omitempty" hcl:"msg_type"`
+	Title       *string `json:"title,omitempty" yaml:"title,omitempty" hcl:"title"`
+	Message     *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
+}
+
+type NtfyIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	Topic string `json:"topic" yaml:"topic" hcl:"topic"`
+
+	URL      *string `json:"url,omitempty" yaml:"url,omitempty" hcl:"url"`
+	Token    *Secret `json:"token,omitempty" yaml:"token,omitempty" hcl:"token"`
+	Username *string `json:"username,omitempty" yaml:"username,omitempty" hcl:"basic_auth_user"`
+	Password *Secret `json:"password,omitempty" yaml:"password,omitempty" hcl:"basic_auth_password"`
+	Priority *int64  `json:"priority,omitempty" yaml:"priority,omitempty" hcl:"priority"`
+	Tags     *string `json:"tags,omitempty" yaml:"tags,omitempty" hcl:"tags"`
+	Title    *string `json:"title,omitempty" yaml:"title,omitempty" hcl:"title"`
+	Message  *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
+}
+
 type OpsgenieIntegrationResponder struct {
 	ID       *string `json:"id,omitempty" yaml:"id,omitempty" hcl:"id"`
 	Name     *string `json:"name,omitempty" yaml:"name,omitempty" hcl:"name"`
@@ -231,6 +258,15 @@ type TeamsIntegration struct {
 	SectionTitle *string `json:"sectiontitle,omitempty" yaml:"sectiontitle,omitempty" hcl:"section_title"`
 }
 
+type TeamsWorkflowsIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	URL Secret `json:"url,omitempty" yaml:"url,omitempty" hcl:"url"`
+
+	Message *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
+	Title   *string `json:"title,omitempty" yaml:"title,omitempty" hcl:"title"`
+}
+
 type ThreemaIntegration struct {
 	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
 
@@ -290,6 +326,18 @@ type WecomIntegration struct {
 	ToUser  *string `json:"touser,omitempty" yaml:"touser,omitempty" hcl:"to_user"`
 }
 
+type ZulipIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	URL      string `json:"url" yaml:"url" hcl:"url"`
+	BotEmail string `json:"bot_email" yaml:"bot_email" hcl:"bot_email"`
+	APIKey   Secret `json:"api_key" yaml:"api_key" hcl:"api_key"`
+	Stream   string `json:"stream" yaml:"stream" hcl:"stream"`
+
+	Topic   *string `json:"topic,omitempty" yaml:"topic,omitempty" hcl:"topic"`
+	Message *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
+}
+
 type ContactPoint struct {
 	Name         string                    `json:"name" yaml:"name" hcl:"name"`
 	Alertmanager []AlertmanagerIntegration `json:"alertmanager" yaml:"alertmanager" hcl:"alertmanager,block"`
@@ -313,4 +361,9 @@ type ContactPoint struct {
 	Webhook      []WebhookIntegration      `json:"webhook" yaml:"webhook" hcl:"webhook,block"`
 	Wecom        []WecomIntegration        `json:"wecom" yaml:"wecom" hcl:"wecom,block"`
 	Webex        []WebexIntegration        `json:"webex" yaml:"webex" hcl:"webex,block"`
+	// Integrations that are implemented in Grafana.
+	TeamsWorkflows []TeamsWorkflowsIntegration `json:"teams_workflows" yaml:"teams_workflows" hcl:"teams_workflows,block"`
+	Matrix         []MatrixIntegration         `json:"matrix" yaml:"matrix" hcl:"matrix,block"`
+	Zulip          []ZulipIntegration          `json:"zulip" yaml:"zulip" hcl:"zulip,block"`
+	Ntfy           []NtfyIntegration           `json:"ntfy" yaml:"ntfy" hcl:"ntfy,block"`
 }
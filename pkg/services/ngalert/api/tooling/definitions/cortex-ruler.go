@@ -92,6 +92,23 @@ import (
 //       403: ForbiddenError
 //       404: description: Not found.
 
+// swagger:route POST /ruler/grafana/api/v1/rules/{Namespace}/import ruler RoutePostRulesImport
+//
+// Imports Prometheus rule groups as Grafana-managed alert and recording rules
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: ImportRulesResponse
+//       202: ImportRulesResponse
+//       400: ImportRulesResponse
+//       403: ForbiddenError
+//       404: NotFound
+
 // swagger:route POST /ruler/{DatasourceUID}/api/v1/rules/{Namespace} ruler RoutePostNameRulesConfig
 //
 // Creates or updates a rule group
@@ -194,6 +211,15 @@ type NamespaceConfig struct {
 	Body PostableRuleGroupConfig
 }
 
+// swagger:parameters RoutePostRulesImport
+type ImportRulesParams struct {
+	// The UID of the rule folder
+	// in:path
+	Namespace string
+	// in:body
+	Body PostablePrometheusRules
+}
+
 // swagger:parameters RouteGetNamespaceRulesConfig RouteDeleteNamespaceRulesConfig RouteGetNamespaceGrafanaRulesConfig RouteDeleteNamespaceGrafanaRulesConfig
 type PathNamespaceConfig struct {
 	// The UID of the rule folder
@@ -500,6 +526,8 @@ type PostableGrafanaRule struct {
 	IsPaused             *bool                          `json:"is_paused" yaml:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings" yaml:"notification_settings"`
 	Record               *Record                        `json:"record" yaml:"record"`
+	DependsOn            []string                       `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+	Template             *RuleTemplateRef               `json:"template,omitempty" yaml:"template,omitempty"`
 }
 
 // swagger:model
@@ -521,6 +549,8 @@ type GettableGrafanaRule struct {
 	IsPaused             bool                           `json:"is_paused" yaml:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
 	Record               *Record                        `json:"record,omitempty" yaml:"record,omitempty"`
+	DependsOn            []string                       `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+	Template             *RuleTemplateRef               `json:"template,omitempty" yaml:"template,omitempty"`
 }
 
 // AlertQuery represents a single query associated with an alert definition.
@@ -597,3 +627,48 @@ type UpdateRuleGroupResponse struct {
 	Updated []string `json:"updated,omitempty"`
 	Deleted []string `json:"deleted,omitempty"`
 }
+
+// swagger:model
+type PostablePrometheusRules struct {
+	// The UID of the Prometheus or Loki data source that the imported rules query.
+	// required: true
+	DatasourceUID string `json:"datasourceUid"`
+	// The rule groups in the Prometheus rule file format.
+	// required: true
+	Rules string `json:"rules"`
+	// If true, the changes are calculated but not saved.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// swagger:model
+type ImportRulesResponse struct {
+	Message string              `json:"message"`
+	DryRun  bool                `json:"dryRun,omitempty"`
+	Groups  []ImportedRuleGroup `json:"groups"`
+	// Rules and groups that could not be imported. If there are any, no changes are saved.
+	Errors []RuleImportError `json:"errors,omitempty"`
+}
+
+// ImportedRuleGroup describes the changes to a rule group. Rules are identified by their titles.
+type ImportedRuleGroup struct {
+	Name    string             `json:"name"`
+	Created []string           `json:"created,omitempty"`
+	Updated []ImportedRuleDiff `json:"updated,omitempty"`
+	Deleted []string           `json:"deleted,omitempty"`
+}
+
+type ImportedRuleDiff struct {
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+	// The fields of the rule that change.
+	Fields []string `json:"fields"`
+}
+
+type RuleImportError struct {
+	Group string `json:"group"`
+	// The name of the alert or the recorded metric. Empty if the whole group could not be imported.
+	Rule string `json:"rule,omitempty"`
+	// The position of the rule in the group, starting at 1.
+	Index int    `json:"index,omitempty"`
+	Error string `json:"error"`
+}
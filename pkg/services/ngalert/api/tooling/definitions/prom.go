@@ -149,8 +149,9 @@ type AlertingRule struct {
 	// required: true
 	Name string `json:"name,omitempty"`
 	// required: true
-	Query    string  `json:"query,omitempty"`
-	Duration float64 `json:"duration,omitempty"`
+	Query         string  `json:"query,omitempty"`
+	Duration      float64 `json:"duration,omitempty"`
+	KeepFiringFor float64 `json:"keepFiringFor,omitempty"`
 	// required: true
 	Annotations promlabels.Labels `json:"annotations,omitempty"`
 	// required: true
@@ -188,6 +189,10 @@ type Alert struct {
 	// required: true
 	State    string     `json:"state"`
 	ActiveAt *time.Time `json:"activeAt"`
+	// KeepFiringSince is the time since when the alert keeps firing although its condition is no longer met.
+	KeepFiringSince *time.Time `json:"keepFiringSince,omitempty"`
+	// KeepFiringUntil is the time until which the alert keeps firing, unless its condition is met again.
+	KeepFiringUntil *time.Time `json:"keepFiringUntil,omitempty"`
 	// required: true
 	Value string `json:"value"`
 }
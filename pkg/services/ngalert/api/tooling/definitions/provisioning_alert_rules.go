@@ -157,6 +157,8 @@ type ProvisionedAlertRule struct {
 	ExecErrState ExecutionErrorState `json:"execErrState"`
 	// required: true
 	For model.Duration `json:"for"`
+	// example: 5m
+	KeepFiringFor model.Duration `json:"keepFiringFor,omitempty"`
 	// example: {"runbook_url": "https://supercoolrunbook.com/page/13"}
 	Annotations map[string]string `json:"annotations,omitempty"`
 	// example: {"team": "sre-team-1"}
@@ -169,6 +171,12 @@ type ProvisionedAlertRule struct {
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings"`
 	//example: {"metric":"grafana_alerts_ratio", "from":"A"}
 	Record *Record `json:"record"`
+	// UIDs of the rules that must be evaluated successfully before this rule is evaluated.
+	// example: ["recording_rule_uid"]
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// Template the rule is derived from. If set, the title, condition, queries, no data and error states, pending period,
+	// labels and annotations of the rule are rendered from the template.
+	Template *RuleTemplateRef `json:"template,omitempty"`
 }
 
 // swagger:route GET /v1/provisioning/folder/{FolderUID}/rule-groups/{Group} provisioning stable RouteGetAlertRuleGroup
@@ -270,12 +278,18 @@ type AlertRuleExport struct {
 	// ForString is used to:
 	// - Only export the for field for HCL if it is non-zero.
 	// - Format the Prometheus model.Duration type properly for HCL.
-	ForString            *string                              `json:"-" yaml:"-" hcl:"for"`
+	ForString     *string        `json:"-" yaml:"-" hcl:"for"`
+	KeepFiringFor model.Duration `json:"keepFiringFor,omitempty" yaml:"keepFiringFor,omitempty"`
+	// KeepFiringForString is used to only export the keep firing for field for HCL if it is non-zero.
+	KeepFiringForString  *string                              `json:"-" yaml:"-" hcl:"keep_firing_for"`
 	Annotations          *map[string]string                   `json:"annotations,omitempty" yaml:"annotations,omitempty" hcl:"annotations"`
 	Labels               *map[string]string                   `json:"labels,omitempty" yaml:"labels,omitempty" hcl:"labels"`
 	IsPaused             bool                                 `json:"isPaused" yaml:"isPaused" hcl:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettingsExport `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty" hcl:"notification_settings,block"`
 	Record               *AlertRuleRecordExport               `json:"record,omitempty" yaml:"record,omitempty" hcl:"record"`
+	DependsOn            []string                             `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+	// DependsOnHCL is used to only export the depends on field for HCL if the rule has dependencies.
+	DependsOnHCL *[]string `json:"-" yaml:"-" hcl:"depends_on"`
 }
 
 // AlertQueryExport is the provisioned export of models.AlertQuery.
@@ -0,0 +1,136 @@
+package definitions
+
+import (
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route GET /v1/provisioning/rule-templates provisioning stable RouteGetRuleTemplates
+//
+// Get all alert rule templates.
+//
+//     Responses:
+//       200: RuleTemplates
+
+// swagger:route GET /v1/provisioning/rule-templates/{UID} provisioning stable RouteGetRuleTemplate
+//
+// Get an alert rule template.
+//
+//     Responses:
+//       200: RuleTemplate
+//       404: description: Not found.
+
+// swagger:route POST /v1/provisioning/rule-templates provisioning stable RoutePostRuleTemplate
+//
+// Create a new alert rule template.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: RuleTemplate
+//       400: ValidationError
+
+// swagger:route PUT /v1/provisioning/rule-templates/{UID} provisioning stable RoutePutRuleTemplate
+//
+// Update an existing alert rule template. All rules that are derived from the template are updated.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: RuleTemplate
+//       400: ValidationError
+//       404: description: Not found.
+//       409: GenericPublicError
+
+// swagger:route DELETE /v1/provisioning/rule-templates/{UID} provisioning stable RouteDeleteRuleTemplate
+//
+// Delete an alert rule template. Templates that alert rules are derived from cannot be deleted.
+//
+//     Responses:
+//       204: description: The alert rule template was deleted successfully.
+//       409: GenericPublicError
+
+// swagger:parameters RouteGetRuleTemplate RoutePutRuleTemplate RouteDeleteRuleTemplate
+type RuleTemplateUIDParam struct {
+	// Alert rule template UID
+	// in:path
+	UID string
+}
+
+// swagger:parameters RoutePostRuleTemplate RoutePutRuleTemplate
+type RuleTemplatePayload struct {
+	// in:body
+	Body RuleTemplate
+}
+
+// swagger:parameters RoutePostRuleTemplate RoutePutRuleTemplate RouteDeleteRuleTemplate
+type RuleTemplateHeaders struct {
+	// in:header
+	XDisableProvenance string `json:"X-Disable-Provenance"`
+}
+
+// swagger:model
+type RuleTemplates []RuleTemplate
+
+// RuleTemplate is a reusable definition of alert rules that differ only by the values of its parameters.
+// The placeholders of the parameters, e.g. ${threshold}, are replaced by their values in the rule title,
+// queries, labels and annotations of the rules that are derived from the template.
+// swagger:model
+type RuleTemplate struct {
+	// required: false
+	// minLength: 1
+	// maxLength: 40
+	// pattern: ^[a-zA-Z0-9-_]+$
+	UID string `json:"uid"`
+	// required: true
+	// example: High CPU usage
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	// example: [{"name":"instance","type":"string"},{"name":"threshold","type":"number","default":"90"}]
+	Parameters []RuleTemplateParameter `json:"parameters,omitempty"`
+	// required: true
+	// example: High CPU usage on ${instance}
+	RuleTitle string `json:"ruleTitle"`
+	// required: true
+	// example: A
+	Condition string `json:"condition"`
+	// required: true
+	Data []AlertQuery `json:"data"`
+	// required: true
+	NoDataState NoDataState `json:"noDataState"`
+	// required: true
+	ExecErrState ExecutionErrorState `json:"execErrState"`
+	For          model.Duration      `json:"for"`
+	// example: {"summary": "CPU usage on ${instance} is above ${threshold}%"}
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// example: {"instance": "${instance}"}
+	Labels map[string]string `json:"labels,omitempty"`
+	// readonly: true
+	Version int64 `json:"version,omitempty"`
+	// readonly: true
+	Provenance Provenance `json:"provenance,omitempty"`
+}
+
+// swagger:model
+type RuleTemplateParameter struct {
+	// required: true
+	// pattern: ^[a-zA-Z_][a-zA-Z0-9_]*$
+	Name string `json:"name"`
+	// required: true
+	// enum: string,number
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	// Default is the value of the parameter if the rule does not set it. Parameters without default are required.
+	Default *string `json:"default,omitempty"`
+}
+
+// RuleTemplateRef links an alert rule to the template it is derived from.
+// swagger:model
+type RuleTemplateRef struct {
+	// required: true
+	UID string `json:"uid" yaml:"uid"`
+	// Values of the parameters of the template.
+	// example: {"instance": "web-1", "threshold": "95"}
+	Parameters map[string]string `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+}
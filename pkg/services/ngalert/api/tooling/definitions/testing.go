@@ -68,6 +68,19 @@ import (
 //     Responses:
 //       200: BacktestResult
 
+// swagger:route Post /v1/rule/backtest/notifications testing BacktestNotificationsConfig
+//
+// Simulate the notifications of rules
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestNotificationsResult
+
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
@@ -234,3 +247,49 @@ type BacktestConfig struct {
 
 // swagger:model
 type BacktestResult data.Frame
+
+// swagger:parameters BacktestNotificationsConfig
+type BacktestNotificationsConfigRequest struct {
+	// in:body
+	Body BacktestNotificationsConfig
+}
+
+// swagger:model
+type BacktestNotificationsConfig struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	// UIDs of the rules to test.
+	RuleUIDs []string `json:"rule_uids,omitempty"`
+	// NamespaceUID and RuleGroup select all rules of a rule group to test.
+	NamespaceUID string `json:"namespace_uid,omitempty"`
+	RuleGroup    string `json:"rule_group,omitempty"`
+
+	// Route replaces the notification policy tree of the current Alertmanager configuration.
+	Route *Route `json:"route,omitempty"`
+	// TimeIntervals are added to the time intervals of the current Alertmanager configuration, and replace the ones with the same name.
+	TimeIntervals []config.TimeInterval `json:"time_intervals,omitempty"`
+}
+
+// swagger:model
+type BacktestNotificationsResult struct {
+	// Notifications that would have been sent to every receiver, in order of time.
+	Receivers map[string][]BacktestNotification `json:"receivers"`
+}
+
+type BacktestNotification struct {
+	Time        time.Time         `json:"time"`
+	Receiver    string            `json:"receiver"`
+	GroupKey    string            `json:"group_key"`
+	GroupLabels map[string]string `json:"group_labels"`
+	// Muted is true if the notification was not sent because the route was in a mute timing.
+	Muted    bool                        `json:"muted,omitempty"`
+	Firing   []BacktestNotificationAlert `json:"firing"`
+	Resolved []BacktestNotificationAlert `json:"resolved"`
+}
+
+type BacktestNotificationAlert struct {
+	Labels   map[string]string `json:"labels"`
+	StartsAt time.Time         `json:"starts_at"`
+	EndsAt   time.Time         `json:"ends_at"`
+}
@@ -0,0 +1,238 @@
+package backtesting
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+)
+
+// NotifiedAlert is an alert that is part of a simulated notification.
+type NotifiedAlert struct {
+	Labels   model.LabelSet
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+// resolved returns true if the alert is resolved at the given time, in the same way as the Alertmanager does.
+func (a NotifiedAlert) resolved(now time.Time) bool {
+	return !a.EndsAt.IsZero() && !a.EndsAt.After(now)
+}
+
+// Notification is a notification that the Alertmanager would have sent to a receiver.
+type Notification struct {
+	Time        time.Time
+	Receiver    string
+	GroupKey    string
+	GroupLabels model.LabelSet
+	// Muted is true if the notification was not sent because the route was in a mute timing.
+	Muted    bool
+	Firing   []NotifiedAlert
+	Resolved []NotifiedAlert
+}
+
+// notificationEntry is the last notification sent for an aggregation group, like an entry of the notification log.
+type notificationEntry struct {
+	time     time.Time
+	firing   map[model.Fingerprint]struct{}
+	resolved map[model.Fingerprint]struct{}
+}
+
+// aggregationGroup is a group of alerts that are routed by the same route and have the same group labels.
+type aggregationGroup struct {
+	key        string
+	route      *dispatch.Route
+	labels     model.LabelSet
+	alerts     map[model.Fingerprint]NotifiedAlert
+	next       time.Time
+	hasFlushed bool
+	// last is the last notification that was sent, and muted is the last notification that was muted since then.
+	last  *notificationEntry
+	muted *notificationEntry
+}
+
+// dispatcher simulates the dispatching of alerts by the Alertmanager in virtual time. It groups the alerts by the
+// notification policy tree, flushes the groups after group_wait and every group_interval, repeats notifications after
+// repeat_interval, and suppresses notifications during the mute timings of the routes.
+type dispatcher struct {
+	route     *dispatch.Route
+	intervals *timeinterval.Intervener
+	groups    map[string]*aggregationGroup
+
+	notifications []Notification
+}
+
+func newDispatcher(route *dispatch.Route, intervals map[string][]timeinterval.TimeInterval) *dispatcher {
+	return &dispatcher{
+		route:     route,
+		intervals: timeinterval.NewIntervener(intervals),
+		groups:    make(map[string]*aggregationGroup),
+	}
+}
+
+// insert adds the alerts received at the given time to the groups of the routes that match them. Groups that are due
+// before that time are flushed first.
+func (d *dispatcher) insert(now time.Time, alerts ...NotifiedAlert) error {
+	if err := d.flush(now); err != nil {
+		return err
+	}
+	for _, alert := range alerts {
+		fp := alert.Labels.Fingerprint()
+		for _, route := range d.route.Match(alert.Labels) {
+			labels := groupLabels(alert.Labels, route)
+			id := route.ID() + ":" + labels.String()
+			group, ok := d.groups[id]
+			if !ok {
+				group = &aggregationGroup{
+					key:    fmt.Sprintf("%s:%s", route.Key(), labels),
+					route:  route,
+					labels: labels,
+					alerts: make(map[model.Fingerprint]NotifiedAlert),
+					next:   now.Add(route.RouteOpts.GroupWait),
+				}
+				d.groups[id] = group
+			}
+
+			if existing, ok := group.alerts[fp]; ok && !existing.resolved(now) && existing.StartsAt.Before(alert.StartsAt) {
+				alert.StartsAt = existing.StartsAt
+			}
+			group.alerts[fp] = alert
+
+			// the group is flushed immediately if the wait duration for the alert is already over.
+			if !group.hasFlushed && alert.StartsAt.Add(route.RouteOpts.GroupWait).Before(now) {
+				group.next = now
+			}
+		}
+	}
+	return nil
+}
+
+// flush flushes, in order of time, all groups that are due at or before the given time.
+func (d *dispatcher) flush(until time.Time) error {
+	for {
+		var next *aggregationGroup
+		var nextID string
+		for id, group := range d.groups {
+			if group.next.After(until) {
+				continue
+			}
+			if next == nil || group.next.Before(next.next) || (group.next.Equal(next.next) && id < nextID) {
+				next, nextID = group, id
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		if err := d.flushGroup(next); err != nil {
+			return err
+		}
+		if len(next.alerts) == 0 {
+			delete(d.groups, nextID)
+		}
+	}
+}
+
+func (d *dispatcher) flushGroup(group *aggregationGroup) error {
+	now := group.next
+	group.hasFlushed = true
+	group.next = now.Add(group.route.RouteOpts.GroupInterval)
+
+	firing := make([]NotifiedAlert, 0, len(group.alerts))
+	resolved := make([]NotifiedAlert, 0, len(group.alerts))
+	for fp, alert := range group.alerts {
+		if alert.resolved(now) {
+			resolved = append(resolved, alert)
+			// resolved alerts are removed from the group once they are flushed.
+			delete(group.alerts, fp)
+			continue
+		}
+		firing = append(firing, alert)
+	}
+	if !needsUpdate(group.last, now, firing, resolved, group.route.RouteOpts.RepeatInterval) {
+		return nil
+	}
+
+	muted, err := d.intervals.Mutes(group.route.RouteOpts.MuteTimeIntervals, now)
+	if err != nil {
+		return err
+	}
+	if muted {
+		// muted notifications are not deduplicated by the Alertmanager, but they are only reported once.
+		if !needsUpdate(group.muted, now, firing, resolved, group.route.RouteOpts.RepeatInterval) {
+			return nil
+		}
+		group.muted = newNotificationEntry(now, firing, resolved)
+	} else {
+		group.last = newNotificationEntry(now, firing, resolved)
+		group.muted = nil
+	}
+
+	sortAlerts(firing)
+	sortAlerts(resolved)
+	d.notifications = append(d.notifications, Notification{
+		Time:        now,
+		Receiver:    group.route.RouteOpts.Receiver,
+		GroupKey:    group.key,
+		GroupLabels: group.labels,
+		Muted:       muted,
+		Firing:      firing,
+		Resolved:    resolved,
+	})
+	return nil
+}
+
+// needsUpdate returns true if a notification is sent for the flushed alerts, in the same way as the deduplication of
+// notifications in the Alertmanager. It assumes that all integrations of receivers send resolved notifications.
+func needsUpdate(last *notificationEntry, now time.Time, firing, resolved []NotifiedAlert, repeatInterval time.Duration) bool {
+	if last == nil {
+		return len(firing) > 0
+	}
+	for _, alert := range firing {
+		if _, ok := last.firing[alert.Labels.Fingerprint()]; !ok {
+			return true
+		}
+	}
+	if len(firing) == 0 {
+		return len(last.firing) > 0
+	}
+	for _, alert := range resolved {
+		if _, ok := last.resolved[alert.Labels.Fingerprint()]; !ok {
+			return true
+		}
+	}
+	return last.time.Before(now.Add(-repeatInterval))
+}
+
+func newNotificationEntry(now time.Time, firing, resolved []NotifiedAlert) *notificationEntry {
+	entry := &notificationEntry{
+		time:     now,
+		firing:   make(map[model.Fingerprint]struct{}, len(firing)),
+		resolved: make(map[model.Fingerprint]struct{}, len(resolved)),
+	}
+	for _, alert := range firing {
+		entry.firing[alert.Labels.Fingerprint()] = struct{}{}
+	}
+	for _, alert := range resolved {
+		entry.resolved[alert.Labels.Fingerprint()] = struct{}{}
+	}
+	return entry
+}
+
+func groupLabels(labels model.LabelSet, route *dispatch.Route) model.LabelSet {
+	result := model.LabelSet{}
+	for name, value := range labels {
+		if _, ok := route.RouteOpts.GroupBy[name]; ok || route.RouteOpts.GroupByAll {
+			result[name] = value
+		}
+	}
+	return result
+}
+
+func sortAlerts(alerts []NotifiedAlert) {
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Labels.Before(alerts[j].Labels)
+	})
+}
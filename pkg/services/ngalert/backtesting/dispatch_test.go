@@ -0,0 +1,124 @@
+package backtesting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestDispatcher(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	groupWait := model.Duration(30 * time.Second)
+	groupInterval := model.Duration(5 * time.Minute)
+	repeatInterval := model.Duration(time.Hour)
+
+	teamA, err := labels.NewMatcher(labels.MatchEqual, "team", "a")
+	require.NoError(t, err)
+	newRoute := func(muteTimings ...string) *dispatch.Route {
+		route := &definitions.Route{
+			Receiver:       "default",
+			GroupByStr:     []string{"alertname"},
+			GroupWait:      &groupWait,
+			GroupInterval:  &groupInterval,
+			RepeatInterval: &repeatInterval,
+			Routes: []*definitions.Route{
+				{
+					Receiver:          "team",
+					ObjectMatchers:    definitions.ObjectMatchers{teamA},
+					MuteTimeIntervals: muteTimings,
+				},
+			},
+		}
+		require.NoError(t, route.Validate())
+		return dispatch.NewRoute(route.AsAMRoute(), nil)
+	}
+	alert := func(name, team string, startsAt, endsAt time.Time) NotifiedAlert {
+		return NotifiedAlert{
+			Labels:   model.LabelSet{"alertname": model.LabelValue(name), "team": model.LabelValue(team)},
+			StartsAt: startsAt,
+			EndsAt:   endsAt,
+		}
+	}
+
+	t.Run("alerts are grouped and flushed after group wait and group interval", func(t *testing.T) {
+		d := newDispatcher(newRoute(), nil)
+		require.NoError(t, d.insert(from, alert("a", "b", from, from.Add(time.Hour))))
+		require.NoError(t, d.insert(from.Add(10*time.Second), alert("a", "c", from, from.Add(time.Hour))))
+		require.NoError(t, d.insert(from.Add(time.Minute), alert("a", "d", from.Add(time.Minute), from.Add(time.Hour))))
+		require.NoError(t, d.flush(from.Add(10*time.Minute)))
+
+		require.Len(t, d.notifications, 2)
+		first := d.notifications[0]
+		require.Equal(t, from.Add(30*time.Second), first.Time)
+		require.Equal(t, "default", first.Receiver)
+		require.Equal(t, model.LabelSet{"alertname": "a"}, first.GroupLabels)
+		require.Len(t, first.Firing, 2)
+		require.Empty(t, first.Resolved)
+		require.False(t, first.Muted)
+
+		second := d.notifications[1]
+		require.Equal(t, from.Add(30*time.Second+5*time.Minute), second.Time)
+		require.Len(t, second.Firing, 3)
+	})
+
+	t.Run("notifications are repeated after repeat interval and resolved", func(t *testing.T) {
+		d := newDispatcher(newRoute(), nil)
+		require.NoError(t, d.insert(from, alert("a", "b", from, from.Add(90*time.Minute))))
+		require.NoError(t, d.flush(from.Add(3*time.Hour)))
+
+		times := make([]time.Time, 0, len(d.notifications))
+		for _, n := range d.notifications {
+			times = append(times, n.Time)
+		}
+		require.Equal(t, []time.Time{
+			from.Add(30 * time.Second),
+			from.Add(30*time.Second + 65*time.Minute),
+			from.Add(30*time.Second + 90*time.Minute),
+		}, times)
+		last := d.notifications[2]
+		require.Empty(t, last.Firing)
+		require.Len(t, last.Resolved, 1)
+		require.Empty(t, d.groups)
+	})
+
+	t.Run("notifications of muted routes are muted", func(t *testing.T) {
+		intervals := map[string][]timeinterval.TimeInterval{
+			"night": {{Times: []timeinterval.TimeRange{{StartMinute: 0, EndMinute: 60}}}},
+		}
+		d := newDispatcher(newRoute("night"), intervals)
+		require.NoError(t, d.insert(from, alert("a", "a", from, from.Add(3*time.Hour))))
+		require.NoError(t, d.flush(from.Add(70*time.Minute)))
+
+		require.Len(t, d.notifications, 2)
+		require.Equal(t, "team", d.notifications[0].Receiver)
+		require.True(t, d.notifications[0].Muted)
+		// the alert is notified as soon as the mute timing ends.
+		require.Equal(t, from.Add(30*time.Second+60*time.Minute), d.notifications[1].Time)
+		require.False(t, d.notifications[1].Muted)
+	})
+
+	t.Run("mute timings that do not exist fail", func(t *testing.T) {
+		d := newDispatcher(newRoute("missing"), nil)
+		require.NoError(t, d.insert(from, alert("a", "a", from, from.Add(time.Hour))))
+		require.Error(t, d.flush(from.Add(time.Hour)))
+	})
+}
+
+func TestNewNotificationPolicy(t *testing.T) {
+	route := &definitions.Route{Receiver: "default"}
+	policy := NewNotificationPolicy(route,
+		[]config.TimeInterval{{Name: "a", TimeIntervals: []timeinterval.TimeInterval{{}}}},
+		[]config.MuteTimeInterval{{Name: "b", TimeIntervals: []timeinterval.TimeInterval{{}, {}}}},
+	)
+	require.Equal(t, route, policy.Route)
+	require.Len(t, policy.TimeIntervals["a"], 1)
+	require.Len(t, policy.TimeIntervals["b"], 2)
+}
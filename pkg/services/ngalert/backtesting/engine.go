@@ -67,13 +67,10 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
 	logger := logger.FromContext(ctx)
 
-	if !from.Before(to) {
-		return nil, fmt.Errorf("%w: invalid interval of the backtesting [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
-	}
-	if to.Sub(from).Seconds() < float64(rule.IntervalSeconds) {
-		return nil, fmt.Errorf("%w: interval of the backtesting [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), rule.IntervalSeconds)
+	length, err := evaluations(rule, from, to)
+	if err != nil {
+		return nil, err
 	}
-	length := int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds)
 
 	stateManager := e.createStateManager()
 
@@ -130,6 +127,17 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 	return result, nil
 }
 
+// evaluations returns the number of evaluations of the rule in the time range.
+func evaluations(rule *models.AlertRule, from, to time.Time) (int, error) {
+	if !from.Before(to) {
+		return 0, fmt.Errorf("%w: invalid interval of the backtesting [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
+	}
+	if to.Sub(from).Seconds() < float64(rule.IntervalSeconds) {
+		return 0, fmt.Errorf("%w: interval of the backtesting [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), rule.IntervalSeconds)
+	}
+	return int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds), nil
+}
+
 func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition, reader eval.AlertingResultsReader) (backtestingEvaluator, error) {
 	for _, q := range condition.Data {
 		if q.DatasourceUID == "__data__" || q.QueryType == "__data__" {
@@ -9,11 +9,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -411,3 +415,78 @@ func (f *fakeBacktestingEvaluator) Eval(_ context.Context, from time.Time, inter
 	}
 	return nil
 }
+
+func TestEngineTestNotifications(t *testing.T) {
+	backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition, r eval.AlertingResultsReader) (backtestingEvaluator, error) {
+		// the rule fires during the first 10 minutes.
+		return &fakeBacktestingEvaluator{
+			evalCallback: func(now time.Time) (eval.Results, error) {
+				s := eval.Normal
+				if now.Before(time.Unix(0, 0).Add(10 * time.Minute)) {
+					s = eval.Alerting
+				}
+				return eval.Results{eval.ResultGen(eval.WithState(s), eval.WithLabels(data.Labels{"instance": "a"}), eval.WithEvaluatedAt(now))()}, nil
+			},
+		}, nil
+	}
+	t.Cleanup(func() {
+		backtestingEvaluatorFactory = newBacktestingEvaluator
+	})
+
+	engine := NewEngine(nil, nil, tracing.InitializeTracerForTest())
+	gen := models.RuleGen
+	rules := []SimulatedRule{
+		{
+			Rule:        gen.With(gen.WithInterval(time.Minute), gen.WithFor(0), gen.WithTitle("first")).GenerateRef(),
+			ExtraLabels: data.Labels{"alertname": "first", "team": "a"},
+		},
+		{
+			Rule:        gen.With(gen.WithInterval(time.Minute), gen.WithFor(0), gen.WithTitle("second")).GenerateRef(),
+			ExtraLabels: data.Labels{"alertname": "second"},
+		},
+	}
+	teamA, err := labels.NewMatcher(labels.MatchEqual, "team", "a")
+	require.NoError(t, err)
+	groupWait := model.Duration(30 * time.Second)
+	policy := NewNotificationPolicy(&definitions.Route{
+		Receiver:   "default",
+		GroupByStr: []string{"alertname"},
+		GroupWait:  &groupWait,
+		Routes: []*definitions.Route{
+			{Receiver: "team", ObjectMatchers: definitions.ObjectMatchers{teamA}},
+		},
+	}, nil, nil)
+	from := time.Unix(0, 0)
+
+	t.Run("returns the notifications of every receiver", func(t *testing.T) {
+		result, err := engine.TestNotifications(context.Background(), nil, rules, policy, from, from.Add(30*time.Minute))
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+
+		for receiver, name := range map[string]string{"team": "first", "default": "second"} {
+			notifications := result[receiver]
+			require.Len(t, notifications, 2)
+			require.Equal(t, from.Add(30*time.Second), notifications[0].Time)
+			require.Len(t, notifications[0].Firing, 1)
+			require.Equal(t, model.LabelValue(name), notifications[0].Firing[0].Labels["alertname"])
+			require.Equal(t, model.LabelValue("a"), notifications[0].Firing[0].Labels["instance"])
+			require.Empty(t, notifications[1].Firing)
+			require.Len(t, notifications[1].Resolved, 1)
+			require.True(t, notifications[1].Time.After(from.Add(10*time.Minute)))
+		}
+	})
+
+	t.Run("fails if the policy is invalid", func(t *testing.T) {
+		_, err := engine.TestNotifications(context.Background(), nil, rules, NotificationPolicy{}, from, from.Add(time.Hour))
+		require.ErrorIs(t, err, ErrInvalidInputData)
+
+		muted := NewNotificationPolicy(&definitions.Route{Receiver: "default", MuteTimeIntervals: []string{"missing"}}, nil, nil)
+		_, err = engine.TestNotifications(context.Background(), nil, rules, muted, from, from.Add(time.Hour))
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+
+	t.Run("fails if the time range is invalid", func(t *testing.T) {
+		_, err := engine.TestNotifications(context.Background(), nil, rules, policy, from, from)
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+}
@@ -0,0 +1,166 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// NotificationPolicy is the notification policy tree, and the time intervals that its routes refer to, that the alerts
+// are routed with when notifications are simulated.
+type NotificationPolicy struct {
+	Route         *definitions.Route
+	TimeIntervals map[string][]timeinterval.TimeInterval
+}
+
+// NewNotificationPolicy creates a notification policy from the route and the time intervals of an Alertmanager configuration.
+func NewNotificationPolicy(route *definitions.Route, timeIntervals []config.TimeInterval, muteTimeIntervals []config.MuteTimeInterval) NotificationPolicy {
+	intervals := make(map[string][]timeinterval.TimeInterval, len(timeIntervals)+len(muteTimeIntervals))
+	for _, ti := range timeIntervals {
+		intervals[ti.Name] = ti.TimeIntervals
+	}
+	for _, ti := range muteTimeIntervals {
+		intervals[ti.Name] = ti.TimeIntervals
+	}
+	return NotificationPolicy{
+		Route:         route,
+		TimeIntervals: intervals,
+	}
+}
+
+// SimulatedRule is a rule whose alerts are routed when notifications are simulated.
+type SimulatedRule struct {
+	Rule *models.AlertRule
+	// ExtraLabels are added to the alerts of the rule, like the built-in labels that are added by the scheduler.
+	ExtraLabels data.Labels
+}
+
+// TestNotifications evaluates the rules over the time range and replays their results through the state manager and
+// the notification policy. It returns the notifications that would have been sent, or muted, for every receiver, in order of time.
+func (e *Engine) TestNotifications(ctx context.Context, user identity.Requester, rules []SimulatedRule, policy NotificationPolicy, from, to time.Time) (map[string][]Notification, error) {
+	logger := logger.FromContext(ctx)
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("%w: no rules to test", ErrInvalidInputData)
+	}
+	if policy.Route == nil {
+		return nil, fmt.Errorf("%w: notification policy tree is empty", ErrInvalidInputData)
+	}
+	if policy.Route.Receiver == "" {
+		return nil, fmt.Errorf("%w: root of the notification policy tree must have a receiver", ErrInvalidInputData)
+	}
+	for _, name := range mutedTimeIntervals(policy.Route) {
+		if _, ok := policy.TimeIntervals[name]; !ok {
+			return nil, fmt.Errorf("%w: mute timing %s does not exist", ErrInvalidInputData, name)
+		}
+	}
+
+	logger.Info("Start testing notifications", "from", from, "to", to, "rules", len(rules))
+	start := time.Now()
+
+	var sent []sentAlerts
+	for _, r := range rules {
+		alerts, err := e.sendAlerts(ctx, user, r, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to test rule %s: %w", r.Rule.UID, err)
+		}
+		sent = append(sent, alerts...)
+	}
+	// the alerts of all rules are dispatched in order of time, alerts of rules that are evaluated at the same time keep the order of the rules.
+	sort.SliceStable(sent, func(i, j int) bool {
+		return sent[i].time.Before(sent[j].time)
+	})
+
+	d := newDispatcher(dispatch.NewRoute(policy.Route.AsAMRoute(), nil), policy.TimeIntervals)
+	for _, s := range sent {
+		if err := d.insert(s.time, s.alerts...); err != nil {
+			return nil, err
+		}
+	}
+	if err := d.flush(to); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]Notification)
+	for _, n := range d.notifications {
+		result[n.Receiver] = append(result[n.Receiver], n)
+	}
+	logger.Info("Notification testing finished successfully", "duration", time.Since(start), "notifications", len(d.notifications))
+	return result, nil
+}
+
+// sentAlerts are the alerts that a rule sends to the Alertmanager after an evaluation.
+type sentAlerts struct {
+	time   time.Time
+	alerts []NotifiedAlert
+}
+
+// sendAlerts evaluates the rule over the time range, and returns the alerts that the rule sends after every evaluation.
+func (e *Engine) sendAlerts(ctx context.Context, user identity.Requester, r SimulatedRule, from, to time.Time) ([]sentAlerts, error) {
+	rule := r.Rule
+	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
+	length, err := evaluations(rule, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	stateManager := e.createStateManager()
+	evaluator, err := backtestingEvaluatorFactory(ruleCtx, e.evalFactory, user, rule.GetEvalCondition(), &schedule.AlertingResultsFromRuleState{
+		Manager: stateManager,
+		Rule:    rule,
+	})
+	if err != nil {
+		return nil, errors.Join(ErrInvalidInputData, err)
+	}
+
+	var result []sentAlerts
+	err = evaluator.Eval(ruleCtx, from, time.Duration(rule.IntervalSeconds)*time.Second, length, func(_ int, now time.Time, results eval.Results) error {
+		stateManager.ProcessEvalResults(ruleCtx, now, rule, results, r.ExtraLabels, func(_ context.Context, transitions state.StateTransitions) {
+			if len(transitions) == 0 {
+				return
+			}
+			alerts := make([]NotifiedAlert, 0, len(transitions))
+			for _, t := range transitions {
+				alert := state.StateToPostableAlert(t, nil)
+				labels := make(model.LabelSet, len(alert.Labels))
+				for name, value := range alert.Labels {
+					labels[model.LabelName(name)] = model.LabelValue(value)
+				}
+				alerts = append(alerts, NotifiedAlert{
+					Labels:   labels,
+					StartsAt: time.Time(alert.StartsAt),
+					EndsAt:   time.Time(alert.EndsAt),
+				})
+			}
+			result = append(result, sentAlerts{time: now, alerts: alerts})
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// mutedTimeIntervals returns the names of the mute timings of all routes of the tree.
+func mutedTimeIntervals(route *definitions.Route) []string {
+	result := append([]string{}, route.MuteTimeIntervals...)
+	for _, r := range route.Routes {
+		result = append(result, mutedTimeIntervals(r)...)
+	}
+	return result
+}
@@ -206,6 +206,14 @@ func IsNonRetryableError(err error) bool {
 	return false
 }
 
+// ErrDependencyFailed is the error of the result of a rule that is not evaluated because a rule it depends on failed.
+var ErrDependencyFailed = errors.New("dependency failed")
+
+// IsDependencyFailed returns true when Results contains a single error that wraps ErrDependencyFailed.
+func (evalResults Results) IsDependencyFailed() bool {
+	return len(evalResults) == 1 && evalResults[0].State == Error && errors.Is(evalResults[0].Error, ErrDependencyFailed)
+}
+
 // HasErrors returns true when Results contains at least one element and all elements are errors
 func (evalResults Results) IsError() bool {
 	for _, r := range evalResults {
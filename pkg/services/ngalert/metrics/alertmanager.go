@@ -11,6 +11,7 @@ type Alertmanager struct {
 	Registerer prometheus.Registerer
 	*metrics.Alerts
 	*AlertmanagerConfigMetrics
+	*NotificationLimitsMetrics
 }
 
 // NewAlertmanagerMetrics creates a set of metrics for the Alertmanager of each organization.
@@ -20,9 +21,32 @@ func NewAlertmanagerMetrics(r prometheus.Registerer) *Alertmanager {
 		Registerer:                r,
 		Alerts:                    metrics.NewAlerts(other),
 		AlertmanagerConfigMetrics: NewAlertmanagerConfigMetrics(r),
+		NotificationLimitsMetrics: NewNotificationLimitsMetrics(r),
 	}
 }
 
+type NotificationLimitsMetrics struct {
+	RateLimitedNotifications *prometheus.CounterVec
+	DigestedNotifications    *prometheus.CounterVec
+}
+
+func NewNotificationLimitsMetrics(r prometheus.Registerer) *NotificationLimitsMetrics {
+	m := &NotificationLimitsMetrics{
+		RateLimitedNotifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "alertmanager_notifications_rate_limited_total",
+			Help: "The total number of notifications that were not sent because the rate limit of the receiver was reached.",
+		}, []string{"integration"}),
+		DigestedNotifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "alertmanager_notifications_digested_total",
+			Help: "The total number of notifications that were batched into digests.",
+		}, []string{"integration"}),
+	}
+	if r != nil {
+		r.MustRegister(m.RateLimitedNotifications, m.DigestedNotifications)
+	}
+	return m
+}
+
 type AlertmanagerConfigMetrics struct {
 	ConfigHash     *prometheus.GaugeVec
 	Matchers       prometheus.Gauge
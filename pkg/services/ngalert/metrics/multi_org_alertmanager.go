@@ -86,6 +86,10 @@ type AlertmanagerAggregatedMetrics struct {
 	numNotificationRequestsFailedTotal *prometheus.Desc
 	notificationLatencySeconds         *prometheus.Desc
 
+	// exported metrics, gathered from the notification limits of receivers
+	numRateLimitedNotifications *prometheus.Desc
+	numDigestedNotifications    *prometheus.Desc
+
 	// exported metrics, gathered from Alertmanager nflog
 	nflogGCDuration              *prometheus.Desc
 	nflogSnapshotDuration        *prometheus.Desc
@@ -168,6 +172,15 @@ func NewAlertmanagerAggregatedMetrics(registries *metrics.TenantRegistries) *Ale
 			"The latency of notifications in seconds.",
 			nil, nil),
 
+		numRateLimitedNotifications: prometheus.NewDesc(
+			fmt.Sprintf("%s_%s_notifications_rate_limited_total", Namespace, Subsystem),
+			"The total number of notifications that were not sent because the rate limit of the receiver was reached.",
+			[]string{"org", "integration"}, nil),
+		numDigestedNotifications: prometheus.NewDesc(
+			fmt.Sprintf("%s_%s_notifications_digested_total", Namespace, Subsystem),
+			"The total number of notifications that were batched into digests.",
+			[]string{"org", "integration"}, nil),
+
 		nflogGCDuration: prometheus.NewDesc(
 			fmt.Sprintf("%s_%s_nflog_gc_duration_seconds", Namespace, Subsystem),
 			"Duration of the last notification log garbage collection cycle.",
@@ -283,6 +296,9 @@ func (a *AlertmanagerAggregatedMetrics) Describe(out chan<- *prometheus.Desc) {
 	out <- a.numNotificationRequestsFailedTotal
 	out <- a.notificationLatencySeconds
 
+	out <- a.numRateLimitedNotifications
+	out <- a.numDigestedNotifications
+
 	out <- a.nflogGCDuration
 	out <- a.nflogSnapshotDuration
 	out <- a.nflogSnapshotSize
@@ -328,6 +344,9 @@ func (a *AlertmanagerAggregatedMetrics) Collect(out chan<- prometheus.Metric) {
 	data.SendSumOfCountersPerTenant(out, a.numNotificationRequestsFailedTotal, "alertmanager_notification_requests_failed_total", metrics.WithLabels("integration"), metrics.WithSkipZeroValueMetrics)
 	data.SendSumOfHistograms(out, a.notificationLatencySeconds, "alertmanager_notification_latency_seconds")
 
+	data.SendSumOfCountersPerTenant(out, a.numRateLimitedNotifications, "alertmanager_notifications_rate_limited_total", metrics.WithLabels("integration"), metrics.WithSkipZeroValueMetrics)
+	data.SendSumOfCountersPerTenant(out, a.numDigestedNotifications, "alertmanager_notifications_digested_total", metrics.WithLabels("integration"), metrics.WithSkipZeroValueMetrics)
+
 	data.SendSumOfSummaries(out, a.nflogGCDuration, "alertmanager_nflog_gc_duration_seconds")
 	data.SendSumOfSummaries(out, a.nflogSnapshotDuration, "alertmanager_nflog_snapshot_duration_seconds")
 	data.SendSumOfGauges(out, a.nflogSnapshotSize, "alertmanager_nflog_snapshot_size_bytes")
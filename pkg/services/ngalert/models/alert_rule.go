@@ -163,6 +163,9 @@ const (
 	StateReasonUpdated       = "Updated"
 	StateReasonRuleDeleted   = "RuleDeleted"
 	StateReasonKeepLast      = "KeepLast"
+	StateReasonKeepFiring    = "KeepFiring"
+	// StateReasonDependencyFailed is the reason of the states of a rule that is not evaluated because a rule it depends on failed.
+	StateReasonDependencyFailed = "DependencyFailed"
 )
 
 func ConcatReasons(reasons ...string) string {
@@ -264,10 +267,15 @@ type AlertRule struct {
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
 	For                  time.Duration
+	KeepFiringFor        time.Duration
 	Annotations          map[string]string
 	Labels               map[string]string
 	IsPaused             bool
 	NotificationSettings []NotificationSettings `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	// DependsOn contains the UIDs of the rules of the same organization that must be evaluated successfully before this rule is evaluated.
+	DependsOn []string `xorm:"depends_on"`
+	// Template is the template the rule is derived from, if any.
+	Template *AlertRuleTemplateRef `xorm:"'template_ref' json"`
 }
 
 // Namespaced describes a class of resources that are stored in a specific namespace.
@@ -548,6 +556,10 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 		return fmt.Errorf("%w: field `for` cannot be negative", ErrAlertRuleFailedValidation)
 	}
 
+	if alertRule.KeepFiringFor < 0 {
+		return fmt.Errorf("%w: field `keep_firing_for` cannot be negative", ErrAlertRuleFailedValidation)
+	}
+
 	if len(alertRule.Labels) > 0 {
 		for label := range alertRule.Labels {
 			if _, ok := LabelsUserCannotSpecify[label]; ok {
@@ -556,6 +568,10 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 		}
 	}
 
+	if err := validateDependsOn(alertRule); err != nil {
+		return err
+	}
+
 	if len(alertRule.NotificationSettings) > 0 {
 		if len(alertRule.NotificationSettings) != 1 {
 			return fmt.Errorf("%w: only one notification settings entry is allowed", ErrAlertRuleFailedValidation)
@@ -567,6 +583,24 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 	return nil
 }
 
+// validateDependsOn checks that the rule does not depend on itself, and that every dependency is declared once.
+func validateDependsOn(rule *AlertRule) error {
+	seen := make(map[string]struct{}, len(rule.DependsOn))
+	for _, uid := range rule.DependsOn {
+		if uid == "" {
+			return fmt.Errorf("%w: field `depends_on` cannot contain empty UIDs", ErrAlertRuleFailedValidation)
+		}
+		if rule.UID != "" && uid == rule.UID {
+			return fmt.Errorf("%w: rule cannot depend on itself", ErrAlertRuleFailedValidation)
+		}
+		if _, ok := seen[uid]; ok {
+			return fmt.Errorf("%w: dependency %s is declared more than once", ErrAlertRuleFailedValidation, uid)
+		}
+		seen[uid] = struct{}{}
+	}
+	return nil
+}
+
 func validateAlertRuleFields(rule *AlertRule) error {
 	if _, err := ErrStateFromString(string(rule.ExecErrState)); err != nil {
 		return err
@@ -587,6 +621,9 @@ func validateRecordingRuleFields(rule *AlertRule) error {
 	if !prommodels.IsValidMetricName(metricName) {
 		return fmt.Errorf("%w: %s", ErrAlertRuleFailedValidation, "metric name for recording rule must be a valid Prometheus metric name")
 	}
+	if rule.KeepFiringFor != 0 {
+		return fmt.Errorf("%w: %s", ErrAlertRuleFailedValidation, "recording rules cannot keep firing")
+	}
 	return nil
 }
 
@@ -639,10 +676,15 @@ type AlertRuleVersion struct {
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
 	For                  time.Duration
+	KeepFiringFor        time.Duration
 	Annotations          map[string]string
 	Labels               map[string]string
 	IsPaused             bool
 	NotificationSettings []NotificationSettings `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	// DependsOn contains the UIDs of the rules of the same organization that must be evaluated successfully before this rule is evaluated.
+	DependsOn []string `xorm:"depends_on"`
+	// Template is the template the rule is derived from, if any.
+	Template *AlertRuleTemplateRef `xorm:"'template_ref' json"`
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -671,6 +713,12 @@ type ListAlertRulesQuery struct {
 	PanelID      int64
 
 	ReceiverName string
+
+	// TemplateUID is optional and allows filtering rules to return just those derived from the alert rule template.
+	TemplateUID string
+
+	// DependsOnUIDs is optional and allows filtering rules to return just those that depend on any of the rules.
+	DependsOnUIDs []string
 }
 
 // CountAlertRulesQuery is the query for counting alert rules
@@ -759,6 +807,9 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOp
 	if ruleToPatch.For == -1 {
 		ruleToPatch.For = existingRule.For
 	}
+	if ruleToPatch.KeepFiringFor == -1 {
+		ruleToPatch.KeepFiringFor = existingRule.KeepFiringFor
+	}
 	if !ruleToPatch.HasPause {
 		ruleToPatch.IsPaused = existingRule.IsPaused
 	}
@@ -0,0 +1,275 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrAlertRuleTemplateNotFound is an error for an unknown alert rule template.
+	ErrAlertRuleTemplateNotFound = errors.New("could not find alert rule template")
+	// ErrAlertRuleTemplateFailedValidation is an error for an invalid alert rule template.
+	ErrAlertRuleTemplateFailedValidation = errors.New("invalid alert rule template")
+	// ErrAlertRuleTemplateInUse is an error for deleting an alert rule template that rules are derived from.
+	ErrAlertRuleTemplateInUse = errors.New("alert rule template is used by alert rules")
+)
+
+// AlertRuleTemplateParameterType is the type of the value of a parameter of an alert rule template.
+type AlertRuleTemplateParameterType string
+
+const (
+	AlertRuleTemplateParameterString AlertRuleTemplateParameterType = "string"
+	AlertRuleTemplateParameterNumber AlertRuleTemplateParameterType = "number"
+)
+
+var (
+	templateParameterNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	// templatePlaceholderRegexp matches the placeholders of the parameters of a template, e.g. ${threshold}.
+	templatePlaceholderRegexp = regexp.MustCompile(`\$\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+)
+
+// AlertRuleTemplateParameter is a parameter of an alert rule template.
+type AlertRuleTemplateParameter struct {
+	Name        string                         `json:"name"`
+	Type        AlertRuleTemplateParameterType `json:"type"`
+	Description string                         `json:"description,omitempty"`
+	// Default is the value of the parameter if the rule does not set it. Parameters without default are required.
+	Default *string `json:"default,omitempty"`
+}
+
+// AlertRuleTemplate is a reusable definition of alert rules that differ only by the values of its parameters.
+// The placeholders of the parameters, e.g. ${threshold}, are replaced by their values in the title, queries,
+// labels and annotations of the rules that are derived from the template.
+type AlertRuleTemplate struct {
+	ID          int64  `xorm:"pk autoincr 'id'"`
+	OrgID       int64  `xorm:"org_id"`
+	UID         string `xorm:"uid"`
+	Title       string
+	Description string
+	Version     int64 `xorm:"version"` // this tag makes xorm add optimistic lock (see https://xorm.io/docs/chapter-06/1.lock/)
+	Updated     time.Time
+	Parameters  []AlertRuleTemplateParameter
+
+	// RuleTitle is the title of the rules that are derived from the template.
+	RuleTitle    string
+	Condition    string
+	Data         []AlertQuery
+	NoDataState  NoDataState
+	ExecErrState ExecutionErrorState
+	For          time.Duration
+	Annotations  map[string]string
+	Labels       map[string]string
+}
+
+func (t *AlertRuleTemplate) ResourceType() string {
+	return "alertRuleTemplate"
+}
+
+func (t *AlertRuleTemplate) ResourceID() string {
+	return t.UID
+}
+
+// AlertRuleTemplateRef links an alert rule to the template it is derived from.
+type AlertRuleTemplateRef struct {
+	UID        string            `json:"uid"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// Validate checks that the template has a title, that the rules derived from it have a title, condition and queries,
+// and that its parameters are valid.
+func (t *AlertRuleTemplate) Validate() error {
+	if t.Title == "" {
+		return fmt.Errorf("%w: title is empty", ErrAlertRuleTemplateFailedValidation)
+	}
+	if t.RuleTitle == "" {
+		return fmt.Errorf("%w: rule title is empty", ErrAlertRuleTemplateFailedValidation)
+	}
+	if t.Condition == "" {
+		return fmt.Errorf("%w: condition is empty", ErrAlertRuleTemplateFailedValidation)
+	}
+	if len(t.Data) == 0 {
+		return fmt.Errorf("%w: no queries or expressions are found", ErrAlertRuleTemplateFailedValidation)
+	}
+	if !slices.ContainsFunc(t.Data, func(q AlertQuery) bool { return q.RefID == t.Condition }) {
+		return fmt.Errorf("%w: condition %s does not exist, must be one of the queries or expressions", ErrAlertRuleTemplateFailedValidation, t.Condition)
+	}
+	if _, err := NoDataStateFromString(string(t.NoDataState)); err != nil {
+		return fmt.Errorf("%w: %s", ErrAlertRuleTemplateFailedValidation, err)
+	}
+	if _, err := ErrStateFromString(string(t.ExecErrState)); err != nil {
+		return fmt.Errorf("%w: %s", ErrAlertRuleTemplateFailedValidation, err)
+	}
+	if t.For < 0 {
+		return fmt.Errorf("%w: field `for` cannot be negative", ErrAlertRuleTemplateFailedValidation)
+	}
+
+	seen := make(map[string]struct{}, len(t.Parameters))
+	for _, p := range t.Parameters {
+		if !templateParameterNameRegexp.MatchString(p.Name) {
+			return fmt.Errorf("%w: invalid parameter name %q", ErrAlertRuleTemplateFailedValidation, p.Name)
+		}
+		if _, ok := seen[p.Name]; ok {
+			return fmt.Errorf("%w: parameter %s is declared more than once", ErrAlertRuleTemplateFailedValidation, p.Name)
+		}
+		seen[p.Name] = struct{}{}
+		switch p.Type {
+		case AlertRuleTemplateParameterString, AlertRuleTemplateParameterNumber:
+		default:
+			return fmt.Errorf("%w: parameter %s has unknown type %q", ErrAlertRuleTemplateFailedValidation, p.Name, p.Type)
+		}
+		if p.Default != nil {
+			if err := p.validateValue(*p.Default); err != nil {
+				return fmt.Errorf("%w: invalid default value: %s", ErrAlertRuleTemplateFailedValidation, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (p AlertRuleTemplateParameter) validateValue(v string) error {
+	if p.Type == AlertRuleTemplateParameterNumber {
+		if _, err := strconv.ParseFloat(v, 64); err != nil || !json.Valid([]byte(v)) {
+			return fmt.Errorf("value %q of parameter %s is not a number", v, p.Name)
+		}
+	}
+	return nil
+}
+
+// values returns the values of all parameters of the template given the values that are set by a rule.
+func (t *AlertRuleTemplate) values(params map[string]string) (map[string]string, error) {
+	result := make(map[string]string, len(t.Parameters))
+	for _, p := range t.Parameters {
+		v, ok := params[p.Name]
+		if !ok {
+			if p.Default == nil {
+				return nil, fmt.Errorf("parameter %s of template %s is required", p.Name, t.UID)
+			}
+			v = *p.Default
+		}
+		if err := p.validateValue(v); err != nil {
+			return nil, err
+		}
+		result[p.Name] = v
+	}
+	for name := range params {
+		if _, ok := result[name]; !ok {
+			return nil, fmt.Errorf("template %s has no parameter %s", t.UID, name)
+		}
+	}
+	return result, nil
+}
+
+// Apply renders the template with the parameters of the rule's template reference, and replaces the fields of the rule
+// that are defined by the template: title, condition, queries, no data and error states, pending period, labels and annotations.
+func (t *AlertRuleTemplate) Apply(rule *AlertRule) error {
+	if rule.Template == nil || rule.Template.UID != t.UID {
+		return fmt.Errorf("%w: rule is not derived from template %s", ErrAlertRuleFailedValidation, t.UID)
+	}
+	if rule.Record != nil {
+		return fmt.Errorf("%w: recording rules cannot be derived from a template", ErrAlertRuleFailedValidation)
+	}
+	values, err := t.values(rule.Template.Parameters)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrAlertRuleFailedValidation, err)
+	}
+	r := templateRenderer{parameters: t.Parameters, values: values}
+
+	data := make([]AlertQuery, 0, len(t.Data))
+	for _, q := range t.Data {
+		model, err := r.renderJSON(q.Model)
+		if err != nil {
+			return fmt.Errorf("%w: failed to render query %s: %s", ErrAlertRuleFailedValidation, q.RefID, err)
+		}
+		data = append(data, AlertQuery{
+			RefID:             q.RefID,
+			QueryType:         q.QueryType,
+			RelativeTimeRange: q.RelativeTimeRange,
+			DatasourceUID:     r.render(q.DatasourceUID),
+			Model:             model,
+		})
+	}
+
+	rule.Title = r.render(t.RuleTitle)
+	rule.Condition = t.Condition
+	rule.Data = data
+	rule.NoDataState = t.NoDataState
+	rule.ExecErrState = t.ExecErrState
+	rule.For = t.For
+	rule.Labels = r.renderMap(t.Labels)
+	rule.Annotations = r.renderMap(t.Annotations)
+	return nil
+}
+
+// templateRenderer replaces the placeholders of the parameters of a template by their values.
+// Placeholders of unknown parameters are kept as they are, so that other variables of the same syntax can be used in queries.
+type templateRenderer struct {
+	parameters []AlertRuleTemplateParameter
+	values     map[string]string
+}
+
+func (r templateRenderer) render(s string) string {
+	return templatePlaceholderRegexp.ReplaceAllStringFunc(s, func(placeholder string) string {
+		if v, ok := r.values[placeholder[2:len(placeholder)-1]]; ok {
+			return v
+		}
+		return placeholder
+	})
+}
+
+func (r templateRenderer) renderMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = r.render(v)
+	}
+	return result
+}
+
+// renderJSON renders all strings of the JSON document. A string that is a single placeholder of a number parameter
+// is replaced by the number, so that numeric fields of queries, such as thresholds, can be parameterized.
+func (r templateRenderer) renderJSON(raw json.RawMessage) (json.RawMessage, error) {
+	if len(raw) == 0 {
+		return raw, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(r.renderValue(doc))
+}
+
+func (r templateRenderer) renderValue(v any) any {
+	switch value := v.(type) {
+	case string:
+		if m := templatePlaceholderRegexp.FindStringSubmatch(value); m != nil && m[0] == value {
+			for _, p := range r.parameters {
+				if p.Name == m[1] && p.Type == AlertRuleTemplateParameterNumber {
+					return json.Number(r.values[p.Name])
+				}
+			}
+		}
+		return r.render(value)
+	case map[string]any:
+		for k, item := range value {
+			value[k] = r.renderValue(item)
+		}
+		return value
+	case []any:
+		for i, item := range value {
+			value[i] = r.renderValue(item)
+		}
+		return value
+	default:
+		return v
+	}
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func ruleTemplateForTest() AlertRuleTemplate {
+	return AlertRuleTemplate{
+		OrgID:     1,
+		UID:       "template",
+		Title:     "High CPU usage",
+		RuleTitle: "High CPU usage on ${instance}",
+		Parameters: []AlertRuleTemplateParameter{
+			{Name: "instance", Type: AlertRuleTemplateParameterString},
+			{Name: "threshold", Type: AlertRuleTemplateParameterNumber, Default: util.Pointer("90")},
+		},
+		Condition: "B",
+		Data: []AlertQuery{
+			{
+				RefID:         "A",
+				DatasourceUID: "prometheus",
+				Model:         json.RawMessage(`{"expr":"cpu_usage{instance=\"${instance}\"}","interval":"${__interval}"}`),
+			},
+			{
+				RefID:         "B",
+				DatasourceUID: "__expr__",
+				Model:         json.RawMessage(`{"type":"threshold","expression":"A","conditions":[{"evaluator":{"params":["${threshold}"],"type":"gt"}}]}`),
+			},
+		},
+		NoDataState:  NoData,
+		ExecErrState: ErrorErrState,
+		For:          5 * time.Minute,
+		Labels:       map[string]string{"instance": "${instance}"},
+		Annotations:  map[string]string{"summary": "CPU usage on ${instance} is above ${threshold}%"},
+	}
+}
+
+func TestAlertRuleTemplateValidate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		mutate func(t *AlertRuleTemplate)
+	}{
+		{name: "empty title", mutate: func(t *AlertRuleTemplate) { t.Title = "" }},
+		{name: "empty rule title", mutate: func(t *AlertRuleTemplate) { t.RuleTitle = "" }},
+		{name: "empty condition", mutate: func(t *AlertRuleTemplate) { t.Condition = "" }},
+		{name: "no queries", mutate: func(t *AlertRuleTemplate) { t.Data = nil }},
+		{name: "invalid no data state", mutate: func(t *AlertRuleTemplate) { t.NoDataState = "invalid" }},
+		{name: "invalid parameter name", mutate: func(t *AlertRuleTemplate) { t.Parameters[0].Name = "in-stance" }},
+		{name: "duplicate parameter", mutate: func(t *AlertRuleTemplate) { t.Parameters[1].Name = "instance" }},
+		{name: "unknown parameter type", mutate: func(t *AlertRuleTemplate) { t.Parameters[0].Type = "bool" }},
+		{name: "invalid default value", mutate: func(t *AlertRuleTemplate) { t.Parameters[1].Default = util.Pointer("high") }},
+	}
+
+	template := ruleTemplateForTest()
+	require.NoError(t, template.Validate())
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			template := ruleTemplateForTest()
+			tc.mutate(&template)
+			require.ErrorIs(t, template.Validate(), ErrAlertRuleTemplateFailedValidation)
+		})
+	}
+}
+
+func TestAlertRuleTemplateApply(t *testing.T) {
+	template := ruleTemplateForTest()
+
+	t.Run("renders the fields of the rule", func(t *testing.T) {
+		rule := &AlertRule{
+			Title:    "ignored",
+			Template: &AlertRuleTemplateRef{UID: "template", Parameters: map[string]string{"instance": "web-1", "threshold": "95.5"}},
+		}
+		require.NoError(t, template.Apply(rule))
+
+		require.Equal(t, "High CPU usage on web-1", rule.Title)
+		require.Equal(t, "B", rule.Condition)
+		require.Equal(t, NoData, rule.NoDataState)
+		require.Equal(t, ErrorErrState, rule.ExecErrState)
+		require.Equal(t, 5*time.Minute, rule.For)
+		require.Equal(t, map[string]string{"instance": "web-1"}, rule.Labels)
+		require.Equal(t, map[string]string{"summary": "CPU usage on web-1 is above 95.5%"}, rule.Annotations)
+		require.Len(t, rule.Data, 2)
+		// placeholders of unknown parameters are kept
+		require.JSONEq(t, `{"expr":"cpu_usage{instance=\"web-1\"}","interval":"${__interval}"}`, string(rule.Data[0].Model))
+		// number parameters are rendered as numbers
+		require.JSONEq(t, `{"type":"threshold","expression":"A","conditions":[{"evaluator":{"params":[95.5],"type":"gt"}}]}`, string(rule.Data[1].Model))
+		// the template is not changed
+		require.Contains(t, string(template.Data[1].Model), "${threshold}")
+	})
+
+	t.Run("uses default values", func(t *testing.T) {
+		rule := &AlertRule{Template: &AlertRuleTemplateRef{UID: "template", Parameters: map[string]string{"instance": "web-1"}}}
+		require.NoError(t, template.Apply(rule))
+		require.Equal(t, map[string]string{"summary": "CPU usage on web-1 is above 90%"}, rule.Annotations)
+	})
+
+	t.Run("fails if parameters are invalid", func(t *testing.T) {
+		for name, params := range map[string]map[string]string{
+			"missing required parameter": {},
+			"unknown parameter":          {"instance": "web-1", "cluster": "a"},
+			"invalid number":             {"instance": "web-1", "threshold": "high"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				rule := &AlertRule{Template: &AlertRuleTemplateRef{UID: "template", Parameters: params}}
+				require.ErrorIs(t, template.Apply(rule), ErrAlertRuleFailedValidation)
+			})
+		}
+	})
+
+	t.Run("fails for rules of other templates and recording rules", func(t *testing.T) {
+		rule := &AlertRule{Template: &AlertRuleTemplateRef{UID: "other", Parameters: map[string]string{"instance": "web-1"}}}
+		require.ErrorIs(t, template.Apply(rule), ErrAlertRuleFailedValidation)
+
+		rule = &AlertRule{Record: &Record{Metric: "m", From: "A"}, Template: &AlertRuleTemplateRef{UID: "template", Parameters: map[string]string{"instance": "web-1"}}}
+		require.ErrorIs(t, template.Apply(rule), ErrAlertRuleFailedValidation)
+	})
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"strings"
+
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 )
 
@@ -10,12 +12,19 @@ var (
 					MustTemplate(errAlertRuleConflictMsg, errutil.WithPublic(errAlertRuleConflictMsg))
 	ErrAlertRuleGroupNotFound       = errutil.NotFound("alerting.alert-rule.notFound")
 	ErrInvalidRelativeTimeRangeBase = errutil.BadRequest("alerting.alert-rule.invalidRelativeTime").MustTemplate("Invalid alert rule query {{ .Public.RefID }}: invalid relative time range [From: {{ .Public.From }}, To: {{ .Public.To }}]")
+	errAlertRuleHasDependentsMsg    = "alert rule {{ .Public.RuleUID }} cannot be deleted because other alert rules depend on it: {{ .Public.Dependents }}"
+	ErrAlertRuleHasDependentsBase   = errutil.Conflict("alerting.alert-rule.hasDependents").
+					MustTemplate(errAlertRuleHasDependentsMsg, errutil.WithPublic(errAlertRuleHasDependentsMsg))
 )
 
 func ErrAlertRuleConflict(rule AlertRule, underlying error) error {
 	return ErrAlertRuleConflictBase.Build(errutil.TemplateData{Public: map[string]any{"RuleUID": rule.UID, "Title": rule.Title, "NamespaceUID": rule.NamespaceUID, "Error": underlying.Error()}, Error: underlying})
 }
 
+func ErrAlertRuleHasDependents(ruleUID string, dependents []string) error {
+	return ErrAlertRuleHasDependentsBase.Build(errutil.TemplateData{Public: map[string]any{"RuleUID": ruleUID, "Dependents": strings.Join(dependents, ", ")}})
+}
+
 func ErrInvalidRelativeTimeRange(refID string, rtr RelativeTimeRange) error {
 	return ErrInvalidRelativeTimeRangeBase.Build(errutil.TemplateData{Public: map[string]any{"RefID": refID, "From": rtr.From, "To": rtr.To}})
 }
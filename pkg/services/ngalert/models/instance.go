@@ -15,6 +15,8 @@ type AlertInstance struct {
 	CurrentStateEnd   time.Time
 	LastEvalTime      time.Time
 	ResultFingerprint string
+	// KeepFiringSince is the time since when an Alerting instance keeps firing although its condition is no longer met.
+	KeepFiringSince *time.Time
 }
 
 type AlertInstanceKey struct {
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // GetReceiverQuery represents a query for a single receiver.
 type GetReceiverQuery struct {
 	OrgID   int64
@@ -15,3 +17,22 @@ type GetReceiversQuery struct {
 	Offset  int
 	Decrypt bool
 }
+
+// DefaultNotificationRateLimitInterval is the interval of the notification rate limit of a receiver if it is not set.
+const DefaultNotificationRateLimitInterval = time.Hour
+
+// ReceiverNotificationLimits limits the notifications that are sent by the integrations of a receiver.
+type ReceiverNotificationLimits struct {
+	// RateLimit is the maximum number of notifications that every integration of the receiver sends per RateLimitInterval.
+	// Zero means no limit.
+	RateLimit         int
+	RateLimitInterval time.Duration
+	// DigestWindow is the window over which the notifications of the receiver are batched into a single notification.
+	// Zero means that notifications are sent as they come.
+	DigestWindow time.Duration
+}
+
+// IsZero returns true if the notifications of the receiver are not limited.
+func (l ReceiverNotificationLimits) IsZero() bool {
+	return l.RateLimit <= 0 && l.DigestWindow <= 0
+}
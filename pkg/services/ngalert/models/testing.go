@@ -304,6 +304,18 @@ func (a *AlertRuleMutators) WithFor(duration time.Duration) AlertRuleMutator {
 	}
 }
 
+func (a *AlertRuleMutators) WithKeepFiringFor(duration time.Duration) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.KeepFiringFor = duration
+	}
+}
+
+func (a *AlertRuleMutators) WithDependsOn(uids ...string) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.DependsOn = uids
+	}
+}
+
 func (a *AlertRuleMutators) WithForNTimes(timesOfInterval int64) AlertRuleMutator {
 	return func(rule *AlertRule) {
 		rule.For = time.Duration(rule.IntervalSeconds*timesOfInterval) * time.Second
@@ -603,6 +615,7 @@ func CopyRule(r *AlertRule, mutators ...AlertRuleMutator) *AlertRule {
 		NoDataState:     r.NoDataState,
 		ExecErrState:    r.ExecErrState,
 		For:             r.For,
+		KeepFiringFor:   r.KeepFiringFor,
 		Record:          r.Record,
 	}
 
@@ -641,6 +654,11 @@ func CopyRule(r *AlertRule, mutators ...AlertRuleMutator) *AlertRule {
 		}
 	}
 
+	if r.DependsOn != nil {
+		result.DependsOn = make([]string, len(r.DependsOn))
+		copy(result.DependsOn, r.DependsOn)
+	}
+
 	if r.Record != nil {
 		result.Record = &Record{
 			From:   r.Record.From,
@@ -648,6 +666,16 @@ func CopyRule(r *AlertRule, mutators ...AlertRuleMutator) *AlertRule {
 		}
 	}
 
+	if r.Template != nil {
+		result.Template = &AlertRuleTemplateRef{UID: r.Template.UID}
+		if r.Template.Parameters != nil {
+			result.Template.Parameters = make(map[string]string, len(r.Template.Parameters))
+			for k, v := range r.Template.Parameters {
+				result.Template.Parameters[k] = v
+			}
+		}
+	}
+
 	for _, s := range r.NotificationSettings {
 		result.NotificationSettings = append(result.NotificationSettings, CopyNotificationSettings(s))
 	}
@@ -1100,5 +1128,6 @@ func convertToRecordingRule(rule *AlertRule) {
 	rule.NoDataState = ""
 	rule.ExecErrState = ""
 	rule.For = 0
+	rule.KeepFiringFor = 0
 	rule.NotificationSettings = nil
 }
@@ -136,6 +136,7 @@ type AlertNG struct {
 	ImageService        image.ImageService
 	schedule            schedule.ScheduleService
 	stateManager        *state.Manager
+	historian           Historian
 	folderService       folder.Service
 	dashboardService    dashboards.DashboardService
 	Api                 *api.API
@@ -365,14 +366,26 @@ func (ng *AlertNG) init() error {
 		Log:                  log.New("ngalert.scheduler"),
 		RecordingWriter:      recordingWriter,
 	}
+	if ng.Cfg.UnifiedAlerting.HAEvaluationSharding {
+		if ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingSaveStatePeriodic) {
+			// The periodic save replaces the states of all rules in the database with the states of this instance.
+			ng.Log.Warn("Sharding of alert rule evaluation is disabled because it requires the state to be saved on every evaluation", "featureToggle", featuremgmt.FlagAlertingSaveStatePeriodic)
+		} else {
+			schedCfg.ClusterMembership = ng.MultiOrgAlertmanager
+		}
+	}
 
 	// There are a set of feature toggles available that act as short-circuits for common configurations.
 	// If any are set, override the config accordingly.
 	ApplyStateHistoryFeatureToggles(&ng.Cfg.UnifiedAlerting.StateHistory, ng.FeatureToggles, ng.Log)
-	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.Metrics.GetHistorianMetrics(), ng.Log, ng.tracer, ac.NewRuleService(ng.accesscontrol))
+	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.SQLStore, ng.store, ng.Metrics.GetHistorianMetrics(), ng.Log, ng.tracer, ac.NewRuleService(ng.accesscontrol))
 	if err != nil {
 		return err
 	}
+	alertStateWriter, err := createAlertStateWriter(ng.Cfg.UnifiedAlerting.StateMetrics, ng.httpClientProvider, ng.tracer, ng.Metrics.GetRemoteWriterMetrics())
+	if err != nil {
+		return fmt.Errorf("failed to initialize alert state writer: %w", err)
+	}
 	cfg := state.ManagerCfg{
 		Metrics:                        ng.Metrics.GetStateMetrics(),
 		ExternalURL:                    appUrl,
@@ -381,6 +394,7 @@ func (ng *AlertNG) init() error {
 		Images:                         ng.ImageService,
 		Clock:                          clk,
 		Historian:                      history,
+		AlertStateWriter:               alertStateWriter,
 		DoNotSaveNormalState:           ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingNoNormalState),
 		ApplyNoDataAndErrorToAllStates: ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingNoDataErrorExecution),
 		MaxStateSaveConcurrency:        ng.Cfg.UnifiedAlerting.MaxStateSaveConcurrency,
@@ -404,6 +418,7 @@ func (ng *AlertNG) init() error {
 	}
 
 	ng.stateManager = stateManager
+	ng.historian = history
 	ng.schedule = scheduler
 
 	receiverService := notifier.NewReceiverService(ng.accesscontrol, ng.store, ng.store, ng.SecretsService, ng.store, ng.Log)
@@ -418,6 +433,8 @@ func (ng *AlertNG) init() error {
 		int64(ng.Cfg.UnifiedAlerting.BaseInterval.Seconds()),
 		ng.Cfg.UnifiedAlerting.RulesPerRuleGroupLimit, ng.Log, notifier.NewNotificationSettingsValidationService(ng.store),
 		ac.NewRuleService(ng.accesscontrol))
+	ruleTemplateService := provisioning.NewRuleTemplateService(ng.store, ng.store, ng.store, ng.store,
+		ac.NewRuleService(ng.accesscontrol), evalFactory, ng.Cfg.UnifiedAlerting, ng.Log)
 
 	ng.Api = &api.API{
 		Cfg:                  ng.Cfg,
@@ -440,6 +457,7 @@ func (ng *AlertNG) init() error {
 		Templates:            templateService,
 		MuteTimings:          muteTimingService,
 		AlertRules:           alertRuleService,
+		RuleTemplates:        ruleTemplateService,
 		AlertsRouter:         alertsRouter,
 		EvaluatorFactory:     evalFactory,
 		FeatureManager:       ng.FeatureToggles,
@@ -491,6 +509,12 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	children.Go(func() error {
 		return ng.AlertsRouter.Run(subCtx)
 	})
+	// Some state history backends, like the SQL backend, clean up the history in the background.
+	if h, ok := ng.historian.(interface{ Run(context.Context) error }); ok {
+		children.Go(func() error {
+			return h.Run(subCtx)
+		})
+	}
 
 	if ng.Cfg.UnifiedAlerting.ExecuteAlerts {
 		// Only Warm() the state manager if we are actually executing alerts.
@@ -534,7 +558,7 @@ type Historian interface {
 	state.Historian
 }
 
-func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, met *metrics.Historian, l log.Logger, tracer tracing.Tracer, ac historian.AccessControl) (Historian, error) {
+func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, sqlStore db.DB, rs historian.RuleStore, met *metrics.Historian, l log.Logger, tracer tracing.Tracer, ac historian.AccessControl) (Historian, error) {
 	if !cfg.Enabled {
 		met.Info.WithLabelValues("noop").Set(0)
 		return historian.NewNopHistorian(), nil
@@ -549,7 +573,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 	if backend == historian.BackendTypeMultiple {
 		primaryCfg := cfg
 		primaryCfg.Backend = cfg.MultiPrimary
-		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, rs, met, l, tracer, ac)
+		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, sqlStore, rs, met, l, tracer, ac)
 		if err != nil {
 			return nil, fmt.Errorf("multi-backend target \"%s\" was misconfigured: %w", cfg.MultiPrimary, err)
 		}
@@ -558,7 +582,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		for _, b := range cfg.MultiSecondaries {
 			secCfg := cfg
 			secCfg.Backend = b
-			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, rs, met, l, tracer, ac)
+			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, sqlStore, rs, met, l, tracer, ac)
 			if err != nil {
 				return nil, fmt.Errorf("multi-backend target \"%s\" was miconfigured: %w", b, err)
 			}
@@ -589,6 +613,14 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		}
 		return backend, nil
 	}
+	if backend == historian.BackendTypeSQL {
+		scfg, err := historian.NewSQLConfig(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sql state history configuration: %w", err)
+		}
+		sqlBackendLogger := log.New("ngalert.state.historian", "backend", "sql")
+		return historian.NewSQLBackend(sqlBackendLogger, scfg, sqlStore, met, rs, ac), nil
+	}
 
 	return nil, fmt.Errorf("unrecognized state history backend: %s", backend)
 }
@@ -651,3 +683,10 @@ func createRecordingWriter(featureToggles featuremgmt.FeatureToggles, settings s
 
 	return writer.NoopWriter{}, nil
 }
+
+func createAlertStateWriter(settings setting.AlertStateMetricsSettings, httpClientProvider httpclient.Provider, tracer tracing.Tracer, m *metrics.RemoteWriter) (state.AlertStateWriter, error) {
+	if !settings.Enabled {
+		return nil, nil
+	}
+	return writer.NewPrometheusAlertStateWriter(settings.RemoteWrite, httpClientProvider, tracer, log.New("ngalert.writer.alert-states"), m)
+}
@@ -59,6 +59,9 @@ type alertmanager struct {
 	decryptFn alertingNotify.GetDecryptedValueFn
 	orgID     int64
 
+	// limiters limit the notifications of the receivers that have notification limits.
+	limiters *notificationLimiters
+
 	withAutogen bool
 }
 
@@ -145,6 +148,7 @@ func NewAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store A
 		decryptFn:           decryptFn,
 		stateStore:          stateStore,
 		logger:              l,
+		limiters:            newNotificationLimiters(l, m.NotificationLimitsMetrics),
 
 		// TODO: Preferably, logic around autogen would be outside of the specific alertmanager implementation so that remote alertmanager will get it for free.
 		withAutogen: withAutogen,
@@ -162,6 +166,7 @@ func (am *alertmanager) Ready() bool {
 
 func (am *alertmanager) StopAndWait() {
 	am.Base.StopAndWait()
+	am.limiters.stop()
 }
 
 // SaveAndApplyDefaultConfig saves the default configuration to the database and applies it to the Alertmanager.
@@ -333,20 +338,30 @@ func (am *alertmanager) applyConfig(cfg *apimodels.PostableUserConfig) (bool, er
 	}
 
 	am.logger.Info("Applying new configuration to Alertmanager", "configHash", fmt.Sprintf("%x", configHash))
+	limits := PostableUserConfigToReceiverNotificationLimits(cfg)
+	limiters := make(map[string]*notificationLimiter)
 	err = am.Base.ApplyConfig(AlertingConfiguration{
-		rawAlertmanagerConfig:    rawConfig,
-		configHash:               configHash,
-		route:                    cfg.AlertmanagerConfig.Route.AsAMRoute(),
-		inhibitRules:             cfg.AlertmanagerConfig.InhibitRules,
-		muteTimeIntervals:        cfg.AlertmanagerConfig.MuteTimeIntervals,
-		timeIntervals:            cfg.AlertmanagerConfig.TimeIntervals,
-		templates:                ToTemplateDefinitions(cfg),
-		receivers:                PostableApiAlertingConfigToApiReceivers(cfg.AlertmanagerConfig),
-		receiverIntegrationsFunc: am.buildReceiverIntegrations,
+		rawAlertmanagerConfig: rawConfig,
+		configHash:            configHash,
+		route:                 cfg.AlertmanagerConfig.Route.AsAMRoute(),
+		inhibitRules:          cfg.AlertmanagerConfig.InhibitRules,
+		muteTimeIntervals:     cfg.AlertmanagerConfig.MuteTimeIntervals,
+		timeIntervals:         cfg.AlertmanagerConfig.TimeIntervals,
+		templates:             ToTemplateDefinitions(cfg),
+		receivers:             PostableApiAlertingConfigToApiReceivers(cfg.AlertmanagerConfig),
+		receiverIntegrationsFunc: func(r *alertingNotify.APIReceiver, tmpl *alertingTemplates.Template) ([]*alertingNotify.Integration, error) {
+			integrations, err := am.buildReceiverIntegrations(r, tmpl)
+			if err != nil {
+				return nil, err
+			}
+			return am.limiters.wrap(r.Name, limits[r.Name], integrations, limiters), nil
+		},
 	})
 	if err != nil {
 		return false, err
 	}
+	// the limiters are swapped only now, so that the current ones are unchanged if the configuration is invalid.
+	am.limiters.swap(limiters)
 
 	am.updateConfigMetrics(cfg)
 	return true, nil
@@ -376,20 +391,21 @@ func (am *alertmanager) AppURL() string {
 
 // buildReceiverIntegrations builds a list of integration notifiers off of a receiver config.
 func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIReceiver, tmpl *alertingTemplates.Template) ([]*alertingNotify.Integration, error) {
-	receiverCfg, err := alertingNotify.BuildReceiverConfiguration(context.Background(), receiver, am.decryptFn)
+	receiverCfg, err := BuildReceiverConfiguration(context.Background(), receiver, am.decryptFn)
 	if err != nil {
 		return nil, err
 	}
 	s := &sender{am.NotificationService}
 	img := newImageProvider(am.Store, log.New("ngalert.notifier.image-provider"))
+	newWebhookSender := func(n receivers.Metadata) (receivers.WebhookSender, error) {
+		return s, nil
+	}
 	integrations, err := alertingNotify.BuildReceiverIntegrations(
-		receiverCfg,
+		receiverCfg.GrafanaReceiverConfig,
 		tmpl,
 		img,
 		LoggerFactory,
-		func(n receivers.Metadata) (receivers.WebhookSender, error) {
-			return s, nil
-		},
+		newWebhookSender,
 		func(n receivers.Metadata) (receivers.EmailSender, error) {
 			return s, nil
 		},
@@ -399,7 +415,11 @@ func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIRe
 	if err != nil {
 		return nil, err
 	}
-	return integrations, nil
+	grafanaIntegrations, err := buildGrafanaIntegrations(receiverCfg.GrafanaIntegrationConfigs, tmpl, img, LoggerFactory, newWebhookSender)
+	if err != nil {
+		return nil, err
+	}
+	return append(integrations, grafanaIntegrations...), nil
 }
 
 // PutAlerts receives the alerts and then sends them through the corresponding route based on whenever the alert has a receiver embedded or not
@@ -193,6 +193,7 @@ func (moa *MultiOrgAlertmanager) gettableUserConfigFromAMConfigString(ctx contex
 			GettableGrafanaReceivers: definitions.GettableGrafanaReceivers{
 				GrafanaManagedReceivers: receivers,
 			},
+			NotificationLimits: recv.NotificationLimits,
 		}
 		gettableApiReceiver.Name = recv.Name
 		result.AlertmanagerConfig.Receivers = append(result.AlertmanagerConfig.Receivers, &gettableApiReceiver)
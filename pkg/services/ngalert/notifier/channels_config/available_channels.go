@@ -802,6 +802,39 @@ func GetAvailableNotifiers() []*NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "teams_workflows",
+			Name:        "Microsoft Teams Workflows",
+			Description: "Sends notifications to a Microsoft Teams workflow that is triggered by a webhook request",
+			Heading:     "Teams Workflows settings",
+			Info:        "Use the URL of a workflow that posts Adaptive Cards to a channel when a webhook request is received. Workflows replace the Office 365 connectors of Microsoft Teams.",
+			Options: []NotifierOption{
+				{
+					Label:        "URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "Teams workflow webhook url",
+					PropertyName: "url",
+					Required:     true,
+					Secure:       true,
+				},
+				{
+					Label:        "Title",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Templated title of the Teams message.",
+					PropertyName: "title",
+					Placeholder:  alertingTemplates.DefaultMessageTitleEmbed,
+				},
+				{
+					Label:        "Message",
+					Element:      ElementTypeTextArea,
+					Description:  "Templated message of the Teams message.",
+					Placeholder:  `{{ template "teams.default.message" .}}`,
+					PropertyName: "message",
+				},
+			},
+		},
 		{
 			Type:        "telegram",
 			Name:        "Telegram",
@@ -1506,6 +1539,230 @@ func GetAvailableNotifiers() []*NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "matrix",
+			Name:        "Matrix",
+			Description: "Sends notifications to a Matrix room",
+			Heading:     "Matrix settings",
+			Info:        "The user of the access token must have joined the room.",
+			Options: []NotifierOption{
+				{
+					Label:        "Homeserver URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "https://matrix.org",
+					Description:  "Base URL of the client-server API of the homeserver.",
+					PropertyName: "homeserver_url",
+					Required:     true,
+				},
+				{
+					Label:        "Room ID",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "!qporfwt:matrix.org",
+					Description:  "Internal ID of the room to send messages to.",
+					PropertyName: "room_id",
+					Required:     true,
+				},
+				{
+					Label:        "Access Token",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					Description:  "Access token of the user that sends the messages.",
+					PropertyName: "access_token",
+					Required:     true,
+					Secure:       true,
+				},
+				{
+					Label:        "Message Type",
+					Element:      ElementTypeSelect,
+					Description:  "Notices are not expected to be answered, and are not shown as new messages by some clients.",
+					PropertyName: "msg_type",
+					SelectOptions: []SelectOption{
+						{
+							Value: "m.notice",
+							Label: "Notice",
+						},
+						{
+							Value: "m.text",
+							Label: "Text",
+						},
+					},
+				},
+				{
+					Label:        "Title",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Templated title of the message.",
+					PropertyName: "title",
+					Placeholder:  alertingTemplates.DefaultMessageTitleEmbed,
+				},
+				{
+					Label:        "Message",
+					Element:      ElementTypeTextArea,
+					Description:  "Templated message.",
+					PropertyName: "message",
+					Placeholder:  alertingTemplates.DefaultMessageEmbed,
+				},
+			},
+		},
+		{
+			Type:        "zulip",
+			Name:        "Zulip",
+			Description: "Sends notifications to a topic of a Zulip stream",
+			Heading:     "Zulip settings",
+			Info:        "Messages are sent by a bot, which must be subscribed to the stream if the stream is private.",
+			Options: []NotifierOption{
+				{
+					Label:        "Zulip URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "https://example.zulipchat.com",
+					Description:  "Base URL of the Zulip organization.",
+					PropertyName: "url",
+					Required:     true,
+				},
+				{
+					Label:        "Bot Email",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "grafana-bot@example.zulipchat.com",
+					Description:  "Email address of the bot.",
+					PropertyName: "bot_email",
+					Required:     true,
+				},
+				{
+					Label:        "API Key",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					Description:  "API key of the bot.",
+					PropertyName: "api_key",
+					Required:     true,
+					Secure:       true,
+				},
+				{
+					Label:        "Stream",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Name of the stream to send messages to.",
+					PropertyName: "stream",
+					Required:     true,
+				},
+				{
+					Label:        "Topic",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Templated topic of the message. Topics longer than 60 characters are truncated.",
+					PropertyName: "topic",
+					Placeholder:  alertingTemplates.DefaultMessageTitleEmbed,
+				},
+				{
+					Label:        "Message",
+					Element:      ElementTypeTextArea,
+					Description:  "Templated message. Markdown is supported.",
+					PropertyName: "message",
+					Placeholder:  alertingTemplates.DefaultMessageEmbed,
+				},
+			},
+		},
+		{
+			Type:        "ntfy",
+			Name:        "ntfy",
+			Description: "Sends notifications to a topic of a ntfy server",
+			Heading:     "ntfy settings",
+			Options: []NotifierOption{
+				{
+					Label:        "Server URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "https://ntfy.sh",
+					Description:  "URL of the ntfy server.",
+					PropertyName: "url",
+				},
+				{
+					Label:        "Topic",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Topic to publish messages to.",
+					PropertyName: "topic",
+					Required:     true,
+				},
+				{
+					Label:        "Access Token",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					Description:  "Access token, if the topic requires authentication. Leave blank to use a username and password.",
+					PropertyName: "token",
+					Secure:       true,
+				},
+				{
+					Label:        "Username",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Username, if the topic requires authentication.",
+					PropertyName: "username",
+				},
+				{
+					Label:        "Password",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					Description:  "Password, if the topic requires authentication.",
+					PropertyName: "password",
+					Secure:       true,
+				},
+				{
+					Label:        "Priority",
+					Element:      ElementTypeSelect,
+					Description:  "Priority of the messages. Leave blank to use the default priority of the server.",
+					PropertyName: "priority",
+					SelectOptions: []SelectOption{
+						{
+							Value: "5",
+							Label: "Max",
+						},
+						{
+							Value: "4",
+							Label: "High",
+						},
+						{
+							Value: "3",
+							Label: "Default",
+						},
+						{
+							Value: "2",
+							Label: "Low",
+						},
+						{
+							Value: "1",
+							Label: "Min",
+						},
+					},
+				},
+				{
+					Label:        "Tags",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "warning,grafana",
+					Description:  "Comma-separated list of tags. Tags that match an emoji short code are shown as emojis.",
+					PropertyName: "tags",
+				},
+				{
+					Label:        "Title",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Templated title of the message.",
+					PropertyName: "title",
+					Placeholder:  alertingTemplates.DefaultMessageTitleEmbed,
+				},
+				{
+					Label:        "Message",
+					Element:      ElementTypeTextArea,
+					Description:  "Templated message.",
+					PropertyName: "message",
+					Placeholder:  alertingTemplates.DefaultMessageEmbed,
+				},
+			},
+		},
 	}
 }
 
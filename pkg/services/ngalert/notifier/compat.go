@@ -2,6 +2,7 @@ package notifier
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/prometheus/alertmanager/config"
 
@@ -46,6 +47,27 @@ func PostableApiAlertingConfigToApiReceivers(c apimodels.PostableApiAlertingConf
 	return apiReceivers
 }
 
+// PostableUserConfigToReceiverNotificationLimits returns the notification limits of the receivers of the configuration by receiver name.
+func PostableUserConfigToReceiverNotificationLimits(c *apimodels.PostableUserConfig) map[string]models.ReceiverNotificationLimits {
+	result := make(map[string]models.ReceiverNotificationLimits)
+	for _, r := range c.AlertmanagerConfig.Receivers {
+		l := r.NotificationLimits
+		if l == nil {
+			continue
+		}
+		limits := models.ReceiverNotificationLimits{
+			RateLimit:         l.RateLimit,
+			RateLimitInterval: time.Duration(l.RateLimitInterval),
+			DigestWindow:      time.Duration(l.DigestWindow),
+		}
+		if limits.RateLimitInterval == 0 {
+			limits.RateLimitInterval = models.DefaultNotificationRateLimitInterval
+		}
+		result[r.Name] = limits
+	}
+	return result
+}
+
 type DecryptFn = func(value string) string
 
 func PostableToGettableGrafanaReceiver(r *apimodels.PostableGrafanaReceiver, provenance *models.Provenance, decryptFn DecryptFn, listOnly bool) (apimodels.GettableGrafanaReceiver, error) {
@@ -94,6 +116,7 @@ func PostableToGettableApiReceiver(r *apimodels.PostableApiReceiver, provenances
 		Receiver: config.Receiver{
 			Name: r.Receiver.Name,
 		},
+		NotificationLimits: r.NotificationLimits,
 	}
 
 	for _, gr := range r.GrafanaManagedReceivers {
@@ -0,0 +1,167 @@
+package notifier
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+	alertingTemplates "github.com/grafana/alerting/templates"
+	"github.com/prometheus/alertmanager/notify"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/matrix"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/ntfy"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/teamsworkflows"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/zulip"
+)
+
+// notificationChannel is a notifier of an integration.
+type notificationChannel interface {
+	notify.Notifier
+	notify.ResolvedSender
+}
+
+// notifierFactory creates the notifier of an integration from its parsed configuration.
+type notifierFactory func(meta receivers.Metadata, tmpl *alertingTemplates.Template, sender receivers.WebhookSender, img images.Provider, logger logging.Logger) notificationChannel
+
+// integrationFactory parses and validates the settings of an integration.
+type integrationFactory func(settings json.RawMessage, decryptFn receivers.DecryptFunc) (any, notifierFactory, error)
+
+func newIntegrationFactory[C any, N notificationChannel](
+	newConfig func(json.RawMessage, receivers.DecryptFunc) (C, error),
+	newNotifier func(C, receivers.Metadata, *alertingTemplates.Template, receivers.WebhookSender, images.Provider, logging.Logger) N,
+) integrationFactory {
+	return func(settings json.RawMessage, decryptFn receivers.DecryptFunc) (any, notifierFactory, error) {
+		cfg, err := newConfig(settings, decryptFn)
+		if err != nil {
+			return nil, nil, err
+		}
+		return cfg, func(meta receivers.Metadata, tmpl *alertingTemplates.Template, sender receivers.WebhookSender, img images.Provider, logger logging.Logger) notificationChannel {
+			return newNotifier(cfg, meta, tmpl, sender, img, logger)
+		}, nil
+	}
+}
+
+// grafanaIntegrations are the integrations that are implemented in Grafana rather than in the alerting package,
+// by their type.
+var grafanaIntegrations = map[string]integrationFactory{
+	matrix.Type:         newIntegrationFactory(matrix.NewConfig, matrix.New),
+	ntfy.Type:           newIntegrationFactory(ntfy.NewConfig, ntfy.New),
+	teamsworkflows.Type: newIntegrationFactory(teamsworkflows.NewConfig, teamsworkflows.New),
+	zulip.Type:          newIntegrationFactory(zulip.NewConfig, zulip.New),
+}
+
+// GrafanaIntegrationConfig is the parsed configuration of an integration that is implemented in Grafana.
+type GrafanaIntegrationConfig struct {
+	receivers.Metadata
+	// Settings is the parsed and decrypted configuration of the integration, e.g. matrix.Config.
+	Settings    any
+	newNotifier notifierFactory
+}
+
+// ReceiverConfig is the parsed configuration of a receiver. It includes the integrations that are implemented in
+// Grafana in addition to the ones of the alerting package.
+type ReceiverConfig struct {
+	alertingNotify.GrafanaReceiverConfig
+	GrafanaIntegrationConfigs []GrafanaIntegrationConfig
+}
+
+// BuildReceiverConfiguration parses, decrypts and validates the APIReceiver, like alertingNotify.BuildReceiverConfiguration
+// does, and supports the integrations that are implemented in Grafana.
+func BuildReceiverConfiguration(ctx context.Context, api *alertingNotify.APIReceiver, decrypt alertingNotify.GetDecryptedValueFn) (ReceiverConfig, error) {
+	// The integrations of the alerting package are parsed by the package, the others are parsed here.
+	alertingReceiver := *api
+	alertingReceiver.Integrations = make([]*alertingNotify.GrafanaIntegrationConfig, 0, len(api.Integrations))
+	var grafanaConfigs []GrafanaIntegrationConfig
+	for _, integration := range api.Integrations {
+		factory, ok := grafanaIntegrations[strings.ToLower(integration.Type)]
+		if !ok {
+			alertingReceiver.Integrations = append(alertingReceiver.Integrations, integration)
+			continue
+		}
+		cfg, err := parseGrafanaIntegration(ctx, integration, factory, decrypt)
+		if err != nil {
+			return ReceiverConfig{}, alertingNotify.IntegrationValidationError{
+				Integration: integration,
+				Err:         err,
+			}
+		}
+		grafanaConfigs = append(grafanaConfigs, cfg)
+	}
+
+	result, err := alertingNotify.BuildReceiverConfiguration(ctx, &alertingReceiver, decrypt)
+	if err != nil {
+		return ReceiverConfig{}, err
+	}
+	return ReceiverConfig{
+		GrafanaReceiverConfig:     result,
+		GrafanaIntegrationConfigs: grafanaConfigs,
+	}, nil
+}
+
+func parseGrafanaIntegration(ctx context.Context, integration *alertingNotify.GrafanaIntegrationConfig, factory integrationFactory, decrypt alertingNotify.GetDecryptedValueFn) (GrafanaIntegrationConfig, error) {
+	secureSettings := decodeSecureSettings(integration.SecureSettings)
+	decryptFn := func(key string, fallback string) string {
+		return decrypt(ctx, secureSettings, key, fallback)
+	}
+
+	settings, newNotifier, err := factory(integration.Settings, decryptFn)
+	if err != nil {
+		return GrafanaIntegrationConfig{}, err
+	}
+	return GrafanaIntegrationConfig{
+		Metadata: receivers.Metadata{
+			UID:                   integration.UID,
+			Name:                  integration.Name,
+			Type:                  integration.Type,
+			DisableResolveMessage: integration.DisableResolveMessage,
+		},
+		Settings:    settings,
+		newNotifier: newNotifier,
+	}, nil
+}
+
+// decodeSecureSettings decodes base-64 encoded secure settings. Like in the alerting package, secure settings that
+// are not base-64 encoded are used as is.
+func decodeSecureSettings(secrets map[string]string) map[string][]byte {
+	decoded := make(map[string][]byte, len(secrets))
+	for k, v := range secrets {
+		d, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			raw := make(map[string][]byte, len(secrets))
+			for k, v := range secrets {
+				raw[k] = []byte(v)
+			}
+			return raw
+		}
+		decoded[k] = d
+	}
+	return decoded
+}
+
+// buildGrafanaIntegrations creates the integrations that are implemented in Grafana.
+func buildGrafanaIntegrations(
+	configs []GrafanaIntegrationConfig,
+	tmpl *alertingTemplates.Template,
+	img images.Provider,
+	loggerFactory logging.LoggerFactory,
+	newWebhookSender func(n receivers.Metadata) (receivers.WebhookSender, error),
+) ([]*alertingNotify.Integration, error) {
+	integrations := make([]*alertingNotify.Integration, 0, len(configs))
+	indexByType := make(map[string]int)
+	for _, cfg := range configs {
+		sender, err := newWebhookSender(cfg.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("unable to build webhook client for %s notifier %s (UID: %s): %w ", cfg.Type, cfg.Name, cfg.UID, err)
+		}
+		n := cfg.newNotifier(cfg.Metadata, tmpl, sender, img, loggerFactory("ngalert.notifier."+cfg.Type, "notifierUID", cfg.UID))
+		integrations = append(integrations, alertingNotify.NewIntegration(n, n, cfg.Type, indexByType[cfg.Type], cfg.Name))
+		indexByType[cfg.Type]++
+	}
+	return integrations, nil
+}
@@ -0,0 +1,64 @@
+package matrix
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+const (
+	// Type is the type of the integration.
+	Type = "matrix"
+
+	MessageTypeText   = "m.text"
+	MessageTypeNotice = "m.notice"
+)
+
+type Config struct {
+	HomeserverURL string `json:"homeserver_url,omitempty" yaml:"homeserver_url,omitempty"`
+	RoomID        string `json:"room_id,omitempty" yaml:"room_id,omitempty"`
+	AccessToken   string `json:"access_token,omitempty" yaml:"access_token,omitempty"`
+	MessageType   string `json:"msg_type,omitempty" yaml:"msg_type,omitempty"`
+	Title         string `json:"title,omitempty" yaml:"title,omitempty"`
+	Message       string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+func NewConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (Config, error) {
+	settings := Config{}
+	err := json.Unmarshal(jsonData, &settings)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	if settings.HomeserverURL == "" {
+		return Config{}, errors.New("could not find homeserver_url property in settings")
+	}
+	u, err := url.Parse(settings.HomeserverURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return Config{}, fmt.Errorf("invalid homeserver URL %q", settings.HomeserverURL)
+	}
+	if settings.RoomID == "" {
+		return Config{}, errors.New("could not find room_id property in settings")
+	}
+	settings.AccessToken = decryptFn("access_token", settings.AccessToken)
+	if settings.AccessToken == "" {
+		return Config{}, errors.New("could not find access_token property in settings")
+	}
+	switch settings.MessageType {
+	case "":
+		settings.MessageType = MessageTypeNotice
+	case MessageTypeText, MessageTypeNotice:
+	default:
+		return Config{}, fmt.Errorf("invalid message type %q, must be %s or %s", settings.MessageType, MessageTypeText, MessageTypeNotice)
+	}
+	if settings.Title == "" {
+		settings.Title = templates.DefaultMessageTitleEmbed
+	}
+	if settings.Message == "" {
+		settings.Message = templates.DefaultMessageEmbed
+	}
+	return settings, nil
+}
@@ -0,0 +1,102 @@
+package matrix
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/grafana/alerting/templates"
+)
+
+func TestNewConfig(t *testing.T) {
+	cases := []struct {
+		name              string
+		settings          string
+		secureSettings    map[string][]byte
+		expectedConfig    Config
+		expectedInitError string
+	}{
+		{
+			name:              "Error if empty",
+			settings:          "",
+			expectedInitError: `failed to unmarshal settings`,
+		},
+		{
+			name:              "Error if homeserver URL is missing",
+			settings:          `{ "room_id": "!room:localhost", "access_token": "token" }`,
+			expectedInitError: `could not find homeserver_url property in settings`,
+		},
+		{
+			name:              "Error if homeserver URL is not valid",
+			settings:          `{ "homeserver_url": "localhost", "room_id": "!room:localhost", "access_token": "token" }`,
+			expectedInitError: `invalid homeserver URL "localhost"`,
+		},
+		{
+			name:              "Error if room ID is missing",
+			settings:          `{ "homeserver_url": "http://localhost", "access_token": "token" }`,
+			expectedInitError: `could not find room_id property in settings`,
+		},
+		{
+			name:              "Error if access token is missing",
+			settings:          `{ "homeserver_url": "http://localhost", "room_id": "!room:localhost" }`,
+			expectedInitError: `could not find access_token property in settings`,
+		},
+		{
+			name:              "Error if message type is not valid",
+			settings:          `{ "homeserver_url": "http://localhost", "room_id": "!room:localhost", "access_token": "token", "msg_type": "m.emote" }`,
+			expectedInitError: `invalid message type "m.emote", must be m.text or m.notice`,
+		},
+		{
+			name:     "Minimal valid configuration",
+			settings: `{ "homeserver_url": "http://localhost", "room_id": "!room:localhost", "access_token": "token" }`,
+			expectedConfig: Config{
+				HomeserverURL: "http://localhost",
+				RoomID:        "!room:localhost",
+				AccessToken:   "token",
+				MessageType:   MessageTypeNotice,
+				Title:         templates.DefaultMessageTitleEmbed,
+				Message:       templates.DefaultMessageEmbed,
+			},
+		},
+		{
+			name:     "Extracts all fields",
+			settings: FullValidConfigForTesting,
+			expectedConfig: Config{
+				HomeserverURL: "http://localhost",
+				RoomID:        "!test:localhost",
+				AccessToken:   "test-token",
+				MessageType:   MessageTypeText,
+				Title:         "test-title",
+				Message:       "test-message",
+			},
+		},
+		{
+			name:           "Extracts all fields + override from secrets",
+			settings:       FullValidConfigForTesting,
+			secureSettings: receiversTesting.ReadSecretsJSONForTesting(FullValidSecretsForTesting),
+			expectedConfig: Config{
+				HomeserverURL: "http://localhost",
+				RoomID:        "!test:localhost",
+				AccessToken:   "test-secret-token",
+				MessageType:   MessageTypeText,
+				Title:         "test-title",
+				Message:       "test-message",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := NewConfig(json.RawMessage(c.settings), receiversTesting.DecryptForTesting(c.secureSettings))
+
+			if c.expectedInitError != "" {
+				require.ErrorContains(t, err, c.expectedInitError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedConfig, actual)
+		})
+	}
+}
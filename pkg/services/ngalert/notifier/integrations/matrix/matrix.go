@@ -0,0 +1,135 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+// maxMessageSize is the maximum size of the text of a message. The size of a Matrix event is limited to 65536 bytes,
+// and the text is sent twice, as plain text and as HTML.
+const maxMessageSize = 30000
+
+// Notifier sends alert notifications as messages to a Matrix room, using the client-server API of the homeserver.
+type Notifier struct {
+	*receivers.Base
+	tmpl     *templates.Template
+	log      logging.Logger
+	ns       receivers.WebhookSender
+	images   images.Provider
+	settings Config
+	now      func() time.Time
+}
+
+func New(cfg Config, meta receivers.Metadata, template *templates.Template, sender receivers.WebhookSender, images images.Provider, logger logging.Logger) *Notifier {
+	return &Notifier{
+		Base:     receivers.NewBase(meta),
+		log:      logger,
+		ns:       sender,
+		images:   images,
+		tmpl:     template,
+		settings: cfg,
+		now:      time.Now,
+	}
+}
+
+// roomMessage is the content of an m.room.message event.
+type roomMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+}
+
+// Notify sends the alert notification to the room.
+func (n *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, _ := templates.TmplText(ctx, n.tmpl, as, n.log, &tmplErr)
+
+	title := tmpl(n.settings.Title)
+	message, truncated := receivers.TruncateInBytes(tmpl(n.settings.Message), maxMessageSize)
+	if truncated {
+		n.log.Warn("Truncated message", "maxSize", maxMessageSize)
+	}
+	if tmplErr != nil {
+		n.log.Warn("Failed to template Matrix message", "error", tmplErr.Error())
+	}
+
+	// Images are linked, since Matrix clients only show images that are uploaded to the homeserver.
+	var imageURLs []string
+	_ = images.WithStoredImages(ctx, n.log, n.images, func(_ int, image images.Image) error {
+		if image.HasURL() {
+			imageURLs = append(imageURLs, image.URL)
+		}
+		return nil
+	}, as...)
+
+	body := strings.TrimSpace(title + "\n\n" + message)
+	for _, u := range imageURLs {
+		body += "\n" + u
+	}
+	msg := roomMessage{
+		MsgType:       n.settings.MessageType,
+		Body:          body,
+		Format:        "org.matrix.custom.html",
+		FormattedBody: formatHTML(title, message, imageURLs),
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	cmd := &receivers.SendWebhookSettings{
+		URL:        n.sendURL(),
+		Body:       string(data),
+		HTTPMethod: http.MethodPut,
+		HTTPHeader: map[string]string{
+			"Authorization": fmt.Sprintf("Bearer %s", n.settings.AccessToken),
+		},
+	}
+	if err := n.ns.SendWebhook(ctx, cmd); err != nil {
+		return false, fmt.Errorf("send notification to Matrix: %w", err)
+	}
+	return true, nil
+}
+
+func (n *Notifier) SendResolved() bool {
+	return !n.GetDisableResolveMessage()
+}
+
+// sendURL returns the URL of the endpoint that sends a message event to the room. Every notification uses a new
+// transaction ID, so that the homeserver does not deduplicate it.
+func (n *Notifier) sendURL() string {
+	txnID := fmt.Sprintf("grafana-%s-%d", n.UID, n.now().UnixNano())
+	return fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimSuffix(n.settings.HomeserverURL, "/"), url.PathEscape(n.settings.RoomID), url.PathEscape(txnID))
+}
+
+// formatHTML returns the HTML version of the message, in which the title is in bold and the images are links.
+func formatHTML(title, message string, imageURLs []string) string {
+	b := strings.Builder{}
+	if title != "" {
+		b.WriteString("<strong>")
+		b.WriteString(html.EscapeString(title))
+		b.WriteString("</strong><br>")
+	}
+	b.WriteString(strings.ReplaceAll(html.EscapeString(message), "\n", "<br>"))
+	for _, u := range imageURLs {
+		b.WriteString(`<br><a href="`)
+		b.WriteString(html.EscapeString(u))
+		b.WriteString(`">Image</a>`)
+	}
+	return b.String()
+}
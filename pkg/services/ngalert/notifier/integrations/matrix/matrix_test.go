@@ -0,0 +1,124 @@
+package matrix
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func TestNotify(t *testing.T) {
+	tmpl := templates.ForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	now := time.Unix(1700000000, 0)
+	cases := []struct {
+		name     string
+		settings Config
+		alerts   []*types.Alert
+		expURL   string
+		expMsg   string
+	}{
+		{
+			name: "A single alert with custom template",
+			settings: Config{
+				HomeserverURL: "http://localhost/",
+				RoomID:        "!room:localhost",
+				AccessToken:   "token",
+				MessageType:   MessageTypeNotice,
+				Title:         "[{{ .Status | toUpper }}] {{ .CommonLabels.alertname }}",
+				Message:       "{{ range .Alerts }}{{ .Labels.lbl1 }} < 1\n{{ end }}",
+			},
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expURL: "http://localhost/_matrix/client/v3/rooms/%21room:localhost/send/m.room.message/grafana-uid-1700000000000000000",
+			expMsg: `{"msgtype":"m.notice","body":"[FIRING] alert1\n\nval1 < 1","format":"org.matrix.custom.html","formatted_body":"<strong>[FIRING] alert1</strong><br>val1 &lt; 1<br>"}`,
+		},
+		{
+			name: "Message without title",
+			settings: Config{
+				HomeserverURL: "http://localhost",
+				RoomID:        "!room:localhost",
+				AccessToken:   "token",
+				MessageType:   MessageTypeText,
+				Message:       "{{ .CommonLabels.alertname }}",
+			},
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+					},
+				},
+			},
+			expURL: "http://localhost/_matrix/client/v3/rooms/%21room:localhost/send/m.room.message/grafana-uid-1700000000000000000",
+			expMsg: `{"msgtype":"m.text","body":"alert1","format":"org.matrix.custom.html","formatted_body":"alert1"}`,
+		},
+		{
+			name: "Images are linked in the message",
+			settings: Config{
+				HomeserverURL: "http://localhost",
+				RoomID:        "!room:localhost",
+				AccessToken:   "token",
+				MessageType:   MessageTypeText,
+				Message:       "{{ .CommonLabels.alertname }}",
+			},
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1"},
+						Annotations: model.LabelSet{"__alertImageToken__": "test-image-1"},
+					},
+				},
+			},
+			expURL: "http://localhost/_matrix/client/v3/rooms/%21room:localhost/send/m.room.message/grafana-uid-1700000000000000000",
+			expMsg: `{"msgtype":"m.text","body":"alert1\nhttps://www.example.com/test-image-1.jpg","format":"org.matrix.custom.html","formatted_body":"alert1<br><a href=\"https://www.example.com/test-image-1.jpg\">Image</a>"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			notificationService := receivers.MockNotificationService()
+
+			n := &Notifier{
+				Base:     &receivers.Base{UID: "uid"},
+				log:      &logging.FakeLogger{},
+				ns:       notificationService,
+				tmpl:     tmpl,
+				settings: c.settings,
+				images:   images.NewFakeProvider(2),
+				now:      func() time.Time { return now },
+			}
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := n.Notify(ctx, c.alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			require.Equal(t, c.expURL, notificationService.Webhook.URL)
+			require.Equal(t, http.MethodPut, notificationService.Webhook.HTTPMethod)
+			require.Equal(t, map[string]string{"Authorization": "Bearer token"}, notificationService.Webhook.HTTPHeader)
+			require.JSONEq(t, c.expMsg, notificationService.Webhook.Body)
+		})
+	}
+}
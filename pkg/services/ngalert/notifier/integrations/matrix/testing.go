@@ -0,0 +1,16 @@
+package matrix
+
+// FullValidConfigForTesting is a string representation of a JSON object that contains all fields supported by the notifier Config. It can be used without secrets.
+const FullValidConfigForTesting = `{
+	"homeserver_url": "http://localhost",
+	"room_id": "!test:localhost",
+	"access_token": "test-token",
+	"msg_type": "m.text",
+	"title": "test-title",
+	"message": "test-message"
+}`
+
+// FullValidSecretsForTesting is a string representation of JSON object that contains all fields that can be overridden from secrets
+const FullValidSecretsForTesting = `{
+	"access_token": "test-secret-token"
+}`
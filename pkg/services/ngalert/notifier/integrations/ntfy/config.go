@@ -0,0 +1,68 @@
+package ntfy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+const (
+	// Type is the type of the integration.
+	Type = "ntfy"
+
+	DefaultURL = "https://ntfy.sh"
+)
+
+type Config struct {
+	URL      string                   `json:"url,omitempty" yaml:"url,omitempty"`
+	Topic    string                   `json:"topic,omitempty" yaml:"topic,omitempty"`
+	Token    string                   `json:"token,omitempty" yaml:"token,omitempty"`
+	Username string                   `json:"username,omitempty" yaml:"username,omitempty"`
+	Password string                   `json:"password,omitempty" yaml:"password,omitempty"`
+	Priority receivers.OptionalNumber `json:"priority,omitempty" yaml:"priority,omitempty"`
+	Tags     string                   `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Title    string                   `json:"title,omitempty" yaml:"title,omitempty"`
+	Message  string                   `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+func NewConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (Config, error) {
+	settings := Config{}
+	err := json.Unmarshal(jsonData, &settings)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	if settings.URL == "" {
+		settings.URL = DefaultURL
+	}
+	u, err := url.Parse(settings.URL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return Config{}, fmt.Errorf("invalid URL %q", settings.URL)
+	}
+	if settings.Topic == "" {
+		return Config{}, errors.New("could not find topic property in settings")
+	}
+	if strings.Contains(settings.Topic, "/") {
+		return Config{}, fmt.Errorf("invalid topic %q", settings.Topic)
+	}
+	settings.Token = decryptFn("token", settings.Token)
+	settings.Password = decryptFn("password", settings.Password)
+	if settings.Token != "" && settings.Username != "" {
+		return Config{}, errors.New("either an access token or a username and password can be used, not both")
+	}
+	priority, err := settings.Priority.Int64()
+	if err != nil || priority < 0 || priority > 5 {
+		return Config{}, fmt.Errorf("invalid priority %q, must be between 1 and 5", settings.Priority)
+	}
+	if settings.Title == "" {
+		settings.Title = templates.DefaultMessageTitleEmbed
+	}
+	if settings.Message == "" {
+		settings.Message = templates.DefaultMessageEmbed
+	}
+	return settings, nil
+}
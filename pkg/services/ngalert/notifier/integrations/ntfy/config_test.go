@@ -0,0 +1,102 @@
+package ntfy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/receivers"
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/grafana/alerting/templates"
+)
+
+func TestNewConfig(t *testing.T) {
+	cases := []struct {
+		name              string
+		settings          string
+		secureSettings    map[string][]byte
+		expectedConfig    Config
+		expectedInitError string
+	}{
+		{
+			name:              "Error if empty",
+			settings:          "",
+			expectedInitError: `failed to unmarshal settings`,
+		},
+		{
+			name:              "Error if topic is missing",
+			settings:          `{}`,
+			expectedInitError: `could not find topic property in settings`,
+		},
+		{
+			name:              "Error if topic is not valid",
+			settings:          `{ "topic": "alerts/json" }`,
+			expectedInitError: `invalid topic "alerts/json"`,
+		},
+		{
+			name:              "Error if URL is not valid",
+			settings:          `{ "url": "localhost", "topic": "alerts" }`,
+			expectedInitError: `invalid URL "localhost"`,
+		},
+		{
+			name:              "Error if priority is not valid",
+			settings:          `{ "topic": "alerts", "priority": "6" }`,
+			expectedInitError: `invalid priority "6"`,
+		},
+		{
+			name:              "Error if both token and username are set",
+			settings:          `{ "topic": "alerts", "token": "tk_test", "username": "user" }`,
+			expectedInitError: `either an access token or a username and password can be used, not both`,
+		},
+		{
+			name:     "Minimal valid configuration",
+			settings: `{ "topic": "alerts" }`,
+			expectedConfig: Config{
+				URL:     DefaultURL,
+				Topic:   "alerts",
+				Title:   templates.DefaultMessageTitleEmbed,
+				Message: templates.DefaultMessageEmbed,
+			},
+		},
+		{
+			name:     "Priority as number",
+			settings: `{ "topic": "alerts", "priority": 5 }`,
+			expectedConfig: Config{
+				URL:      DefaultURL,
+				Topic:    "alerts",
+				Priority: receivers.OptionalNumber("5"),
+				Title:    templates.DefaultMessageTitleEmbed,
+				Message:  templates.DefaultMessageEmbed,
+			},
+		},
+		{
+			name:           "Extracts all fields + override from secrets",
+			settings:       FullValidConfigForTesting,
+			secureSettings: receiversTesting.ReadSecretsJSONForTesting(FullValidSecretsForTesting),
+			expectedConfig: Config{
+				URL:      "http://localhost",
+				Topic:    "alerts",
+				Username: "test-user",
+				Password: "test-secret-password",
+				Priority: receivers.OptionalNumber("4"),
+				Tags:     "warning,grafana",
+				Title:    "test-title",
+				Message:  "test-message",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := NewConfig(json.RawMessage(c.settings), receiversTesting.DecryptForTesting(c.secureSettings))
+
+			if c.expectedInitError != "" {
+				require.ErrorContains(t, err, c.expectedInitError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedConfig, actual)
+		})
+	}
+}
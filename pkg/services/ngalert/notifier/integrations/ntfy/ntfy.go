@@ -0,0 +1,130 @@
+package ntfy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+const (
+	// https://docs.ntfy.sh/publish/#limitations
+	maxMessageLenBytes = 4096
+
+	tagFiring   = "rotating_light"
+	tagResolved = "white_check_mark"
+)
+
+// Notifier publishes alert notifications to a topic of a ntfy server.
+type Notifier struct {
+	*receivers.Base
+	tmpl     *templates.Template
+	log      logging.Logger
+	ns       receivers.WebhookSender
+	images   images.Provider
+	settings Config
+}
+
+func New(cfg Config, meta receivers.Metadata, template *templates.Template, sender receivers.WebhookSender, images images.Provider, logger logging.Logger) *Notifier {
+	return &Notifier{
+		Base:     receivers.NewBase(meta),
+		log:      logger,
+		ns:       sender,
+		images:   images,
+		tmpl:     template,
+		settings: cfg,
+	}
+}
+
+// publishMessage is the message that is published as JSON. https://docs.ntfy.sh/publish/#publish-as-json
+type publishMessage struct {
+	Topic    string   `json:"topic"`
+	Title    string   `json:"title,omitempty"`
+	Message  string   `json:"message"`
+	Priority int64    `json:"priority,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Click    string   `json:"click,omitempty"`
+	Attach   string   `json:"attach,omitempty"`
+}
+
+// Notify publishes the alert notification to the topic.
+func (n *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, _ := templates.TmplText(ctx, n.tmpl, as, n.log, &tmplErr)
+
+	message, truncated := receivers.TruncateInBytes(tmpl(n.settings.Message), maxMessageLenBytes)
+	if truncated {
+		n.log.Warn("Truncated message", "maxSize", maxMessageLenBytes)
+	}
+	// The priority is validated when the configuration is parsed.
+	priority, _ := n.settings.Priority.Int64()
+	msg := publishMessage{
+		Topic:    n.settings.Topic,
+		Title:    tmpl(n.settings.Title),
+		Message:  message,
+		Priority: priority,
+		Tags:     n.tags(types.Alerts(as...)),
+		Click:    receivers.JoinURLPath(n.tmpl.ExternalURL.String(), "/alerting/list", n.log),
+	}
+	if tmplErr != nil {
+		n.log.Warn("Failed to template ntfy message", "error", tmplErr.Error())
+	}
+
+	// ntfy shows a single attachment per message.
+	_ = images.WithStoredImages(ctx, n.log, n.images, func(_ int, image images.Image) error {
+		if image.HasURL() {
+			msg.Attach = image.URL
+			return images.ErrImagesDone
+		}
+		return nil
+	}, as...)
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	// Messages that are published as JSON are sent to the root URL of the server, which takes the topic from the body.
+	cmd := &receivers.SendWebhookSettings{
+		URL:  n.settings.URL,
+		Body: string(body),
+	}
+	if n.settings.Token != "" {
+		cmd.HTTPHeader = map[string]string{
+			"Authorization": fmt.Sprintf("Bearer %s", n.settings.Token),
+		}
+	} else if n.settings.Username != "" {
+		cmd.User = n.settings.Username
+		cmd.Password = n.settings.Password
+	}
+	if err := n.ns.SendWebhook(ctx, cmd); err != nil {
+		return false, fmt.Errorf("send notification to ntfy: %w", err)
+	}
+	return true, nil
+}
+
+func (n *Notifier) SendResolved() bool {
+	return !n.GetDisableResolveMessage()
+}
+
+// tags returns the configured tags, preceded by an emoji tag that tells whether the alerts are firing or resolved.
+func (n *Notifier) tags(as model.Alerts) []string {
+	tags := []string{tagResolved}
+	if as.Status() == model.AlertFiring {
+		tags = []string{tagFiring}
+	}
+	for _, tag := range strings.Split(n.settings.Tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,110 @@
+package ntfy
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	images2 "github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func TestNotify(t *testing.T) {
+	tmpl := templates.ForTests(t)
+	images := images2.NewFakeProvider(2)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name       string
+		settings   Config
+		alerts     []*types.Alert
+		expMsg     string
+		expHeaders map[string]string
+		expUser    string
+		expPass    string
+	}{
+		{
+			name: "A firing alert with token",
+			settings: Config{
+				URL:      "http://localhost",
+				Topic:    "alerts",
+				Token:    "tk_test",
+				Priority: receivers.OptionalNumber("4"),
+				Tags:     "grafana, warning",
+				Title:    "[{{ .Status | toUpper }}] {{ .CommonLabels.alertname }}",
+				Message:  "{{ len .Alerts.Firing }} firing",
+			},
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1", "__alertImageToken__": "test-image-1"},
+					},
+				},
+			},
+			expMsg:     `{"topic":"alerts","title":"[FIRING] alert1","message":"1 firing","priority":4,"tags":["rotating_light","grafana","warning"],"click":"http://localhost/alerting/list","attach":"https://www.example.com/test-image-1.jpg"}`,
+			expHeaders: map[string]string{"Authorization": "Bearer tk_test"},
+		},
+		{
+			name: "A resolved alert with basic auth",
+			settings: Config{
+				URL:      "http://localhost",
+				Topic:    "alerts",
+				Username: "user",
+				Password: "pass",
+				Title:    "[{{ .Status | toUpper }}] {{ .CommonLabels.alertname }}",
+				Message:  "{{ len .Alerts.Resolved }} resolved",
+			},
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:   model.LabelSet{"alertname": "alert1"},
+						StartsAt: time.Now().Add(-time.Hour),
+						EndsAt:   time.Now().Add(-time.Minute),
+					},
+				},
+			},
+			expMsg:  `{"topic":"alerts","title":"[RESOLVED] alert1","message":"1 resolved","tags":["white_check_mark"],"click":"http://localhost/alerting/list"}`,
+			expUser: "user",
+			expPass: "pass",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			notificationService := receivers.MockNotificationService()
+
+			n := &Notifier{
+				Base:     &receivers.Base{},
+				log:      &logging.FakeLogger{},
+				ns:       notificationService,
+				tmpl:     tmpl,
+				settings: c.settings,
+				images:   images,
+			}
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := n.Notify(ctx, c.alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			require.Equal(t, "http://localhost", notificationService.Webhook.URL)
+			require.Equal(t, c.expHeaders, notificationService.Webhook.HTTPHeader)
+			require.Equal(t, c.expUser, notificationService.Webhook.User)
+			require.Equal(t, c.expPass, notificationService.Webhook.Password)
+			require.JSONEq(t, c.expMsg, notificationService.Webhook.Body)
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package ntfy
+
+// FullValidConfigForTesting is a string representation of a JSON object that contains all fields supported by the notifier Config. It can be used without secrets.
+const FullValidConfigForTesting = `{
+	"url": "http://localhost",
+	"topic": "alerts",
+	"username": "test-user",
+	"password": "test-password",
+	"priority": "4",
+	"tags": "warning,grafana",
+	"title": "test-title",
+	"message": "test-message"
+}`
+
+// FullValidSecretsForTesting is a string representation of JSON object that contains all fields that can be overridden from secrets
+const FullValidSecretsForTesting = `{
+	"password": "test-secret-password"
+}`
@@ -0,0 +1,38 @@
+package teamsworkflows
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+// Type is the type of the integration.
+const Type = "teams_workflows"
+
+type Config struct {
+	URL     string `json:"url,omitempty" yaml:"url,omitempty"`
+	Title   string `json:"title,omitempty" yaml:"title,omitempty"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+func NewConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (Config, error) {
+	settings := Config{}
+	err := json.Unmarshal(jsonData, &settings)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	settings.URL = decryptFn("url", settings.URL)
+	if settings.URL == "" {
+		return Config{}, errors.New("could not find url property in settings")
+	}
+	if settings.Title == "" {
+		settings.Title = templates.DefaultMessageTitleEmbed
+	}
+	if settings.Message == "" {
+		settings.Message = `{{ template "teams.default.message" .}}`
+	}
+	return settings, nil
+}
@@ -0,0 +1,73 @@
+package teamsworkflows
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/grafana/alerting/templates"
+)
+
+func TestNewConfig(t *testing.T) {
+	cases := []struct {
+		name              string
+		settings          string
+		secureSettings    map[string][]byte
+		expectedConfig    Config
+		expectedInitError string
+	}{
+		{
+			name:              "Error if empty",
+			settings:          "",
+			expectedInitError: `failed to unmarshal settings`,
+		},
+		{
+			name:              "Error if URL is missing",
+			settings:          `{}`,
+			expectedInitError: `could not find url property in settings`,
+		},
+		{
+			name:     "Minimal valid configuration",
+			settings: `{ "url": "http://localhost" }`,
+			expectedConfig: Config{
+				URL:     "http://localhost",
+				Title:   templates.DefaultMessageTitleEmbed,
+				Message: `{{ template "teams.default.message" .}}`,
+			},
+		},
+		{
+			name:           "URL from secrets",
+			settings:       `{}`,
+			secureSettings: receiversTesting.ReadSecretsJSONForTesting(FullValidSecretsForTesting),
+			expectedConfig: Config{
+				URL:     "http://localhost/secret",
+				Title:   templates.DefaultMessageTitleEmbed,
+				Message: `{{ template "teams.default.message" .}}`,
+			},
+		},
+		{
+			name:     "Extracts all fields",
+			settings: FullValidConfigForTesting,
+			expectedConfig: Config{
+				URL:     "http://localhost",
+				Title:   "test-title",
+				Message: "test-message",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := NewConfig(json.RawMessage(c.settings), receiversTesting.DecryptForTesting(c.secureSettings))
+
+			if c.expectedInitError != "" {
+				require.ErrorContains(t, err, c.expectedInitError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedConfig, actual)
+		})
+	}
+}
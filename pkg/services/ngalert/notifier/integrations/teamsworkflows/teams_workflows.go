@@ -0,0 +1,122 @@
+package teamsworkflows
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/receivers/teams"
+	"github.com/grafana/alerting/templates"
+)
+
+// Notifier sends alert notifications as Adaptive Cards to a Microsoft Teams workflow that is triggered by a webhook
+// request. Workflows (Power Automate) replace the Office 365 connectors of Microsoft Teams.
+type Notifier struct {
+	*receivers.Base
+	tmpl     *templates.Template
+	log      logging.Logger
+	ns       receivers.WebhookSender
+	images   images.Provider
+	settings Config
+}
+
+func New(cfg Config, meta receivers.Metadata, template *templates.Template, sender receivers.WebhookSender, images images.Provider, logger logging.Logger) *Notifier {
+	return &Notifier{
+		Base:     receivers.NewBase(meta),
+		log:      logger,
+		ns:       sender,
+		images:   images,
+		tmpl:     template,
+		settings: cfg,
+	}
+}
+
+// Notify sends the alert notification to the workflow.
+func (n *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, _ := templates.TmplText(ctx, n.tmpl, as, n.log, &tmplErr)
+
+	title := tmpl(n.settings.Title)
+	card := teams.NewAdaptiveCard()
+	card.AppendItem(teams.AdaptiveCardTextBlockItem{
+		Color:  textColor(types.Alerts(as...)),
+		Text:   title,
+		Size:   teams.TextSizeLarge,
+		Weight: teams.TextWeightBolder,
+		Wrap:   true,
+	})
+	card.AppendItem(teams.AdaptiveCardTextBlockItem{
+		Text: tmpl(n.settings.Message),
+		Wrap: true,
+	})
+
+	var s teams.AdaptiveCardImageSetItem
+	_ = images.WithStoredImages(ctx, n.log, n.images,
+		func(_ int, image images.Image) error {
+			if image.URL != "" {
+				s.AppendImage(teams.AdaptiveCardImageItem{URL: image.URL})
+			}
+			return nil
+		},
+		as...)
+	if len(s.Images) > 2 {
+		s.Size = teams.ImageSizeMedium
+		card.AppendItem(s)
+	} else if len(s.Images) > 0 {
+		s.Size = teams.ImageSizeLarge
+		card.AppendItem(s)
+	}
+
+	card.AppendItem(teams.AdaptiveCardActionSetItem{
+		Actions: []teams.AdaptiveCardActionItem{
+			teams.AdaptiveCardOpenURLActionItem{
+				Title: "View URL",
+				URL:   receivers.JoinURLPath(n.tmpl.ExternalURL.String(), "/alerting/list", n.log),
+			},
+		},
+	})
+
+	msg := teams.NewAdaptiveCardsMessage(card)
+	msg.Summary = title
+
+	if tmplErr != nil {
+		n.log.Warn("Failed to template Teams Workflows message", "error", tmplErr.Error())
+		tmplErr = nil
+	}
+
+	u := tmpl(n.settings.URL)
+	if tmplErr != nil {
+		n.log.Warn("Failed to template Teams Workflows URL", "error", tmplErr.Error(), "fallback", n.settings.URL)
+		u = n.settings.URL
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	// Unlike connectors, workflows accept the request with 202 Accepted and an empty body, which is what the
+	// default validation of the sender expects.
+	if err := n.ns.SendWebhook(ctx, &receivers.SendWebhookSettings{URL: u, Body: string(b)}); err != nil {
+		return false, fmt.Errorf("send notification to Teams Workflows: %w", err)
+	}
+	return true, nil
+}
+
+func (n *Notifier) SendResolved() bool {
+	return !n.GetDisableResolveMessage()
+}
+
+// textColor returns the color of the title of the card, which is the same as in the cards of the Teams integration.
+func textColor(as model.Alerts) string {
+	if as.Status() == model.AlertFiring {
+		return teams.TextColorAttention
+	}
+	return teams.TextColorGood
+}
@@ -0,0 +1,136 @@
+package teamsworkflows
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func TestNotify(t *testing.T) {
+	tmpl := templates.ForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	card := func(color, title, text string) map[string]interface{} {
+		return map[string]interface{}{
+			"attachments": []map[string]interface{}{{
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"body": []map[string]interface{}{{
+						"color":  color,
+						"size":   "large",
+						"text":   title,
+						"type":   "TextBlock",
+						"weight": "bolder",
+						"wrap":   true,
+					}, {
+						"text": text,
+						"type": "TextBlock",
+						"wrap": true,
+					}, {
+						"actions": []map[string]interface{}{{
+							"title": "View URL",
+							"type":  "Action.OpenUrl",
+							"url":   "http://localhost/alerting/list",
+						}},
+						"type": "ActionSet",
+					}},
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"msTeams": map[string]interface{}{
+						"width": "Full",
+					},
+				},
+				"contentType": "application/vnd.microsoft.card.adaptive",
+			}},
+			"summary": title,
+			"type":    "message",
+		}
+	}
+
+	cases := []struct {
+		name     string
+		settings Config
+		alerts   []*types.Alert
+		expURL   string
+		expMsg   map[string]interface{}
+	}{
+		{
+			name: "Default config with one alert",
+			settings: Config{
+				URL:     "http://localhost",
+				Title:   templates.DefaultMessageTitleEmbed,
+				Message: `{{ template "teams.default.message" .}}`,
+			},
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1", "__dashboardUid__": "abcd", "__panelId__": "efgh"},
+					},
+				},
+			},
+			expURL: "http://localhost",
+			expMsg: card("attention", "[FIRING:1]  (val1)", "**Firing**\n\nValue: [no value]\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSilence: http://localhost/alerting/silence/new?alertmanager=grafana&matcher=alertname%3Dalert1&matcher=lbl1%3Dval1\nDashboard: http://localhost/d/abcd\nPanel: http://localhost/d/abcd?viewPanel=efgh\n"),
+		},
+		{
+			name: "Custom config with resolved alert and templated URL",
+			settings: Config{
+				URL:     "http://localhost/{{ .CommonLabels.team }}",
+				Title:   "{{ .CommonLabels.alertname }} is {{ .Status }}",
+				Message: "{{ len .Alerts.Resolved }} resolved",
+			},
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:   model.LabelSet{"alertname": "alert1", "team": "ops"},
+						StartsAt: time.Now().Add(-time.Hour),
+						EndsAt:   time.Now().Add(-time.Minute),
+					},
+				},
+			},
+			expURL: "http://localhost/ops",
+			expMsg: card("good", "alert1 is resolved", "1 resolved"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			notificationService := receivers.MockNotificationService()
+
+			n := &Notifier{
+				Base:     &receivers.Base{},
+				log:      &logging.FakeLogger{},
+				ns:       notificationService,
+				tmpl:     tmpl,
+				settings: c.settings,
+				images:   &images.UnavailableProvider{},
+			}
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := n.Notify(ctx, c.alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			require.Equal(t, c.expURL, notificationService.Webhook.URL)
+			expBody, err := json.Marshal(c.expMsg)
+			require.NoError(t, err)
+			require.JSONEq(t, string(expBody), notificationService.Webhook.Body)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package zulip
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+// Type is the type of the integration.
+const Type = "zulip"
+
+type Config struct {
+	URL      string `json:"url,omitempty" yaml:"url,omitempty"`
+	BotEmail string `json:"bot_email,omitempty" yaml:"bot_email,omitempty"`
+	APIKey   string `json:"api_key,omitempty" yaml:"api_key,omitempty"`
+	Stream   string `json:"stream,omitempty" yaml:"stream,omitempty"`
+	Topic    string `json:"topic,omitempty" yaml:"topic,omitempty"`
+	Message  string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+func NewConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (Config, error) {
+	settings := Config{}
+	err := json.Unmarshal(jsonData, &settings)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	if settings.URL == "" {
+		return Config{}, errors.New("could not find url property in settings")
+	}
+	u, err := url.Parse(settings.URL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return Config{}, fmt.Errorf("invalid URL %q", settings.URL)
+	}
+	if settings.BotEmail == "" {
+		return Config{}, errors.New("could not find bot_email property in settings")
+	}
+	settings.APIKey = decryptFn("api_key", settings.APIKey)
+	if settings.APIKey == "" {
+		return Config{}, errors.New("could not find api_key property in settings")
+	}
+	if settings.Stream == "" {
+		return Config{}, errors.New("could not find stream property in settings")
+	}
+	if settings.Topic == "" {
+		settings.Topic = templates.DefaultMessageTitleEmbed
+	}
+	if settings.Message == "" {
+		settings.Message = templates.DefaultMessageEmbed
+	}
+	return settings, nil
+}
@@ -0,0 +1,90 @@
+package zulip
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/grafana/alerting/templates"
+)
+
+func TestNewConfig(t *testing.T) {
+	cases := []struct {
+		name              string
+		settings          string
+		secureSettings    map[string][]byte
+		expectedConfig    Config
+		expectedInitError string
+	}{
+		{
+			name:              "Error if empty",
+			settings:          "",
+			expectedInitError: `failed to unmarshal settings`,
+		},
+		{
+			name:              "Error if URL is missing",
+			settings:          `{ "bot_email": "bot@localhost", "api_key": "key", "stream": "alerts" }`,
+			expectedInitError: `could not find url property in settings`,
+		},
+		{
+			name:              "Error if URL is not valid",
+			settings:          `{ "url": "localhost", "bot_email": "bot@localhost", "api_key": "key", "stream": "alerts" }`,
+			expectedInitError: `invalid URL "localhost"`,
+		},
+		{
+			name:              "Error if bot email is missing",
+			settings:          `{ "url": "http://localhost", "api_key": "key", "stream": "alerts" }`,
+			expectedInitError: `could not find bot_email property in settings`,
+		},
+		{
+			name:              "Error if API key is missing",
+			settings:          `{ "url": "http://localhost", "bot_email": "bot@localhost", "stream": "alerts" }`,
+			expectedInitError: `could not find api_key property in settings`,
+		},
+		{
+			name:              "Error if stream is missing",
+			settings:          `{ "url": "http://localhost", "bot_email": "bot@localhost", "api_key": "key" }`,
+			expectedInitError: `could not find stream property in settings`,
+		},
+		{
+			name:     "Minimal valid configuration",
+			settings: `{ "url": "http://localhost", "bot_email": "bot@localhost", "api_key": "key", "stream": "alerts" }`,
+			expectedConfig: Config{
+				URL:      "http://localhost",
+				BotEmail: "bot@localhost",
+				APIKey:   "key",
+				Stream:   "alerts",
+				Topic:    templates.DefaultMessageTitleEmbed,
+				Message:  templates.DefaultMessageEmbed,
+			},
+		},
+		{
+			name:           "Extracts all fields + override from secrets",
+			settings:       FullValidConfigForTesting,
+			secureSettings: receiversTesting.ReadSecretsJSONForTesting(FullValidSecretsForTesting),
+			expectedConfig: Config{
+				URL:      "http://localhost",
+				BotEmail: "bot@localhost",
+				APIKey:   "test-secret-api-key",
+				Stream:   "alerts",
+				Topic:    "test-topic",
+				Message:  "test-message",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := NewConfig(json.RawMessage(c.settings), receiversTesting.DecryptForTesting(c.secureSettings))
+
+			if c.expectedInitError != "" {
+				require.ErrorContains(t, err, c.expectedInitError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedConfig, actual)
+		})
+	}
+}
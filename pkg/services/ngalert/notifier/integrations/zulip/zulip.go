@@ -0,0 +1,90 @@
+package zulip
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+const (
+	// https://zulip.com/api/send-message
+	maxTopicLenRunes   = 60
+	maxMessageLenBytes = 10000
+)
+
+// Notifier sends alert notifications as messages to a topic of a Zulip stream, using the API of a Zulip bot.
+type Notifier struct {
+	*receivers.Base
+	tmpl     *templates.Template
+	log      logging.Logger
+	ns       receivers.WebhookSender
+	images   images.Provider
+	settings Config
+}
+
+func New(cfg Config, meta receivers.Metadata, template *templates.Template, sender receivers.WebhookSender, images images.Provider, logger logging.Logger) *Notifier {
+	return &Notifier{
+		Base:     receivers.NewBase(meta),
+		log:      logger,
+		ns:       sender,
+		images:   images,
+		tmpl:     template,
+		settings: cfg,
+	}
+}
+
+// Notify sends the alert notification to the stream.
+func (n *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, _ := templates.TmplText(ctx, n.tmpl, as, n.log, &tmplErr)
+
+	topic, truncated := receivers.TruncateInRunes(tmpl(n.settings.Topic), maxTopicLenRunes)
+	if truncated {
+		n.log.Warn("Truncated topic", "maxRunes", maxTopicLenRunes)
+	}
+	// Zulip shows a preview of the images that are linked in a message.
+	content := tmpl(n.settings.Message)
+	_ = images.WithStoredImages(ctx, n.log, n.images, func(_ int, image images.Image) error {
+		if image.HasURL() {
+			content += fmt.Sprintf("\n[Image](%s)", image.URL)
+		}
+		return nil
+	}, as...)
+	message, truncated := receivers.TruncateInBytes(content, maxMessageLenBytes)
+	if truncated {
+		n.log.Warn("Truncated message", "maxSize", maxMessageLenBytes)
+	}
+	if tmplErr != nil {
+		n.log.Warn("Failed to template Zulip message", "error", tmplErr.Error())
+	}
+
+	form := url.Values{}
+	form.Set("type", "stream")
+	form.Set("to", n.settings.Stream)
+	form.Set("topic", topic)
+	form.Set("content", message)
+
+	cmd := &receivers.SendWebhookSettings{
+		URL:         strings.TrimSuffix(n.settings.URL, "/") + "/api/v1/messages",
+		User:        n.settings.BotEmail,
+		Password:    n.settings.APIKey,
+		Body:        form.Encode(),
+		ContentType: "application/x-www-form-urlencoded",
+	}
+	if err := n.ns.SendWebhook(ctx, cmd); err != nil {
+		return false, fmt.Errorf("send notification to Zulip: %w", err)
+	}
+	return true, nil
+}
+
+func (n *Notifier) SendResolved() bool {
+	return !n.GetDisableResolveMessage()
+}
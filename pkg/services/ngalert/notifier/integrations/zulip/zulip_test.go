@@ -0,0 +1,137 @@
+package zulip
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func TestNotify(t *testing.T) {
+	tmpl := templates.ForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name     string
+		settings Config
+		alerts   []*types.Alert
+		expForm  url.Values
+	}{
+		{
+			name: "A single alert with custom template",
+			settings: Config{
+				URL:      "http://localhost/",
+				BotEmail: "bot@localhost",
+				APIKey:   "key",
+				Stream:   "alerts",
+				Topic:    "[{{ .Status | toUpper }}] {{ .CommonLabels.alertname }}",
+				Message:  "{{ len .Alerts.Firing }} firing",
+			},
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expForm: url.Values{
+				"type":    {"stream"},
+				"to":      {"alerts"},
+				"topic":   {"[FIRING] alert1"},
+				"content": {"1 firing"},
+			},
+		},
+		{
+			name: "Truncate long topic",
+			settings: Config{
+				URL:      "http://localhost",
+				BotEmail: "bot@localhost",
+				APIKey:   "key",
+				Stream:   "alerts",
+				Topic:    "{{ .CommonLabels.alertname }}",
+				Message:  "message",
+			},
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": model.LabelValue(strings.Repeat("1", 61))},
+					},
+				},
+			},
+			expForm: url.Values{
+				"type":    {"stream"},
+				"to":      {"alerts"},
+				"topic":   {strings.Repeat("1", 59) + "…"},
+				"content": {"message"},
+			},
+		},
+		{
+			name: "Images are linked in the message",
+			settings: Config{
+				URL:      "http://localhost",
+				BotEmail: "bot@localhost",
+				APIKey:   "key",
+				Stream:   "alerts",
+				Topic:    "{{ .CommonLabels.alertname }}",
+				Message:  "message",
+			},
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1"},
+						Annotations: model.LabelSet{"__alertImageToken__": "test-image-1"},
+					},
+				},
+			},
+			expForm: url.Values{
+				"type":    {"stream"},
+				"to":      {"alerts"},
+				"topic":   {"alert1"},
+				"content": {"message\n[Image](https://www.example.com/test-image-1.jpg)"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			notificationService := receivers.MockNotificationService()
+
+			n := &Notifier{
+				Base:     &receivers.Base{},
+				log:      &logging.FakeLogger{},
+				ns:       notificationService,
+				tmpl:     tmpl,
+				settings: c.settings,
+				images:   images.NewFakeProvider(2),
+			}
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := n.Notify(ctx, c.alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			require.Equal(t, "http://localhost/api/v1/messages", notificationService.Webhook.URL)
+			require.Equal(t, "bot@localhost", notificationService.Webhook.User)
+			require.Equal(t, "key", notificationService.Webhook.Password)
+			require.Equal(t, "application/x-www-form-urlencoded", notificationService.Webhook.ContentType)
+			form, err := url.ParseQuery(notificationService.Webhook.Body)
+			require.NoError(t, err)
+			require.Equal(t, c.expForm, form)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+	alertingTemplates "github.com/grafana/alerting/templates"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/matrix"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/ntfy"
+)
+
+func TestBuildReceiverConfiguration(t *testing.T) {
+	t.Run("parses integrations of Grafana and of the alerting package", func(t *testing.T) {
+		integrations := []*alertingNotify.GrafanaIntegrationConfig{
+			alertingNotify.AllKnownConfigsForTesting["slack"].GetRawNotifierConfig("slack"),
+		}
+		for _, cfg := range GrafanaIntegrationConfigsForTesting {
+			integrations = append(integrations, cfg.GetRawNotifierConfig(cfg.NotifierType))
+		}
+		api := &alertingNotify.APIReceiver{
+			GrafanaIntegrations: alertingNotify.GrafanaIntegrations{Integrations: integrations},
+		}
+
+		result, err := BuildReceiverConfiguration(context.Background(), api, alertingNotify.GetDecryptedValueFnForTesting)
+		require.NoError(t, err)
+
+		require.Len(t, result.SlackConfigs, 1)
+		require.Len(t, result.GrafanaIntegrationConfigs, len(GrafanaIntegrationConfigsForTesting))
+		for _, cfg := range result.GrafanaIntegrationConfigs {
+			require.Equal(t, cfg.Type, cfg.Name)
+			require.Equal(t, cfg.Type+"-uid", cfg.UID)
+			require.True(t, cfg.DisableResolveMessage)
+			if cfg.Type == matrix.Type {
+				require.Equal(t, "test-secret-token", cfg.Settings.(matrix.Config).AccessToken)
+			}
+		}
+	})
+
+	t.Run("returns validation error of Grafana integration", func(t *testing.T) {
+		integration := &alertingNotify.GrafanaIntegrationConfig{
+			UID:      "uid",
+			Name:     "ntfy",
+			Type:     ntfy.Type,
+			Settings: json.RawMessage(`{}`),
+		}
+		api := &alertingNotify.APIReceiver{
+			GrafanaIntegrations: alertingNotify.GrafanaIntegrations{
+				Integrations: []*alertingNotify.GrafanaIntegrationConfig{integration},
+			},
+		}
+
+		_, err := BuildReceiverConfiguration(context.Background(), api, alertingNotify.GetDecryptedValueFnForTesting)
+		var validationErr alertingNotify.IntegrationValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.Equal(t, integration, validationErr.Integration)
+		require.ErrorContains(t, err, "could not find topic property in settings")
+	})
+
+	t.Run("returns error for unknown type", func(t *testing.T) {
+		api := &alertingNotify.APIReceiver{
+			GrafanaIntegrations: alertingNotify.GrafanaIntegrations{
+				Integrations: []*alertingNotify.GrafanaIntegrationConfig{{
+					Type:     "unknown",
+					Settings: json.RawMessage(`{}`),
+				}},
+			},
+		}
+
+		_, err := BuildReceiverConfiguration(context.Background(), api, alertingNotify.GetDecryptedValueFnForTesting)
+		require.Error(t, err)
+	})
+}
+
+func TestBuildGrafanaIntegrations(t *testing.T) {
+	var configs []GrafanaIntegrationConfig
+	for _, name := range []string{"first", "second"} {
+		cfg := GrafanaIntegrationConfigsForTesting[matrix.Type]
+		api := &alertingNotify.APIReceiver{
+			GrafanaIntegrations: alertingNotify.GrafanaIntegrations{
+				Integrations: []*alertingNotify.GrafanaIntegrationConfig{cfg.GetRawNotifierConfig(name)},
+			},
+		}
+		result, err := BuildReceiverConfiguration(context.Background(), api, alertingNotify.GetDecryptedValueFnForTesting)
+		require.NoError(t, err)
+		configs = append(configs, result.GrafanaIntegrationConfigs...)
+	}
+
+	integrations, err := buildGrafanaIntegrations(configs, &alertingTemplates.Template{}, nil, LoggerFactory, func(n receivers.Metadata) (receivers.WebhookSender, error) {
+		return receivers.MockNotificationService(), nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, integrations, 2)
+	for i, integration := range integrations {
+		require.Equal(t, matrix.Type, integration.Name())
+		require.Equal(t, i, integration.Index())
+		require.False(t, integration.SendResolved())
+	}
+}
@@ -401,6 +401,24 @@ func (moa *MultiOrgAlertmanager) StopAndWait() {
 	}
 }
 
+// ClusterMembers returns the name of this instance and the names of the live members of the high availability
+// cluster, including this instance. It returns no members when Grafana does not run in high availability mode.
+func (moa *MultiOrgAlertmanager) ClusterMembers() (string, []string) {
+	switch p := moa.peer.(type) {
+	case *alertingCluster.Peer:
+		nodes := p.Peers()
+		members := make([]string, 0, len(nodes))
+		for _, n := range nodes {
+			members = append(members, n.Name())
+		}
+		return p.Name(), members
+	case *redisPeer:
+		return p.withPrefix(p.name), p.Members()
+	default:
+		return "", nil
+	}
+}
+
 // AlertmanagerFor returns the Alertmanager instance for the organization provided.
 // When the organization does not have an active Alertmanager, it returns a ErrNoAlertmanagerForOrg.
 // When the Alertmanager of the organization is not ready, it returns a ErrAlertmanagerNotReady.
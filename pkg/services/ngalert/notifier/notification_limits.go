@@ -0,0 +1,236 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// digestNotifyTimeout is the timeout of sending a digest.
+const digestNotifyTimeout = time.Minute
+
+// notificationLimiters keeps the notification limiters of the integrations of all receivers of an Alertmanager,
+// so that the rate limits and pending digests survive when the configuration is applied again.
+type notificationLimiters struct {
+	logger  log.Logger
+	metrics *metrics.NotificationLimitsMetrics
+
+	mtx      sync.Mutex
+	limiters map[string]*notificationLimiter
+}
+
+func newNotificationLimiters(logger log.Logger, m *metrics.NotificationLimitsMetrics) *notificationLimiters {
+	return &notificationLimiters{
+		logger:   logger,
+		metrics:  m,
+		limiters: make(map[string]*notificationLimiter),
+	}
+}
+
+// wrap returns the integrations of the receiver wrapped by new limiters of the given limits, and adds the limiters
+// to next by key. Integrations are returned as they are if the receiver has no limits. The new limiters replace
+// the current ones only when they are swapped in, after the configuration with them is applied.
+func (l *notificationLimiters) wrap(receiver string, limits models.ReceiverNotificationLimits, integrations []*alertingNotify.Integration, next map[string]*notificationLimiter) []*alertingNotify.Integration {
+	if receiver == "" || limits.IsZero() {
+		return integrations
+	}
+
+	result := make([]*alertingNotify.Integration, 0, len(integrations))
+	for _, integration := range integrations {
+		limiter := &notificationLimiter{
+			receiver:    receiver,
+			logger:      l.logger.New("receiver", receiver, "integration", integration.Name(), "index", integration.Index()),
+			metrics:     l.metrics,
+			integration: integration,
+			limits:      limits,
+		}
+		if limits.RateLimit > 0 {
+			limiter.rateLimiter = rate.NewLimiter(rate.Every(limits.RateLimitInterval/time.Duration(limits.RateLimit)), limits.RateLimit)
+		}
+		next[fmt.Sprintf("%s/%s/%d", receiver, integration.Name(), integration.Index())] = limiter
+		result = append(result, alertingNotify.NewIntegration(limiter, integration, integration.Name(), integration.Index(), receiver))
+	}
+	return result
+}
+
+// swap replaces the current limiters with next once the configuration that uses them is applied. The new limiters
+// take over the rate limits and pending digests of the limiters with the same keys. The pending digests of the
+// limiters that are removed are sent in the background.
+func (l *notificationLimiters) swap(next map[string]*notificationLimiter) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	for key, limiter := range l.limiters {
+		if replacement, ok := next[key]; ok {
+			replacement.takeOver(limiter)
+			continue
+		}
+		go limiter.flush()
+	}
+	l.limiters = next
+}
+
+// stop removes all limiters and waits until their pending digests are sent.
+func (l *notificationLimiters) stop() {
+	l.mtx.Lock()
+	limiters := l.limiters
+	l.limiters = make(map[string]*notificationLimiter)
+	l.mtx.Unlock()
+
+	var wg sync.WaitGroup
+	for _, limiter := range limiters {
+		wg.Add(1)
+		go func(limiter *notificationLimiter) {
+			defer wg.Done()
+			limiter.flush()
+		}(limiter)
+	}
+	wg.Wait()
+}
+
+// notificationLimiter is a notify.Notifier that limits the rate of the notifications of an integration,
+// and batches them into digests if the receiver has a digest window.
+type notificationLimiter struct {
+	receiver string
+	logger   log.Logger
+	metrics  *metrics.NotificationLimitsMetrics
+
+	mtx         sync.Mutex
+	integration *alertingNotify.Integration
+	limits      models.ReceiverNotificationLimits
+	rateLimiter *rate.Limiter
+	// digest has the latest version of every alert that was notified since the last digest, by fingerprint.
+	digest      map[model.Fingerprint]*types.Alert
+	digestTimer *time.Timer
+}
+
+// takeOver moves the rate limiter and the pending digest of the previous limiter of the integration to this limiter.
+// The rate limiter keeps its tokens if the rate limit is unchanged.
+func (n *notificationLimiter) takeOver(prev *notificationLimiter) {
+	prev.mtx.Lock()
+	defer prev.mtx.Unlock()
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+
+	if prev.rateLimiter != nil && prev.limits.RateLimit == n.limits.RateLimit && prev.limits.RateLimitInterval == n.limits.RateLimitInterval {
+		n.rateLimiter = prev.rateLimiter
+	}
+	if prev.digestTimer != nil {
+		prev.digestTimer.Stop()
+		prev.digestTimer = nil
+	}
+	digest := prev.digest
+	prev.digest = nil
+	if len(digest) == 0 {
+		return
+	}
+	if n.digest == nil {
+		n.digest = make(map[model.Fingerprint]*types.Alert, len(digest))
+	}
+	for fp, alert := range digest {
+		// alerts notified by this limiter are more recent.
+		if _, ok := n.digest[fp]; !ok {
+			n.digest[fp] = alert
+		}
+	}
+	if n.digestTimer == nil {
+		// the digest is sent right away if the receiver has no digest window anymore.
+		n.digestTimer = time.AfterFunc(max(n.limits.DigestWindow, 0), n.flush)
+	}
+}
+
+func (n *notificationLimiter) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	n.mtx.Lock()
+	integration := n.integration
+	if n.limits.DigestWindow <= 0 {
+		n.mtx.Unlock()
+		return n.send(ctx, integration, alerts)
+	}
+	if n.digest == nil {
+		n.digest = make(map[model.Fingerprint]*types.Alert, len(alerts))
+	}
+	for _, alert := range alerts {
+		n.digest[alert.Fingerprint()] = alert
+	}
+	if n.digestTimer == nil {
+		n.digestTimer = time.AfterFunc(n.limits.DigestWindow, n.flush)
+	}
+	n.mtx.Unlock()
+
+	n.metrics.DigestedNotifications.WithLabelValues(integration.Name()).Inc()
+	// The notification counts as sent for the notification log, the alerts are sent with the digest.
+	return false, nil
+}
+
+// flush sends the pending digest.
+func (n *notificationLimiter) flush() {
+	n.mtx.Lock()
+	if n.digestTimer != nil {
+		n.digestTimer.Stop()
+		n.digestTimer = nil
+	}
+	alerts := make(types.AlertSlice, 0, len(n.digest))
+	for _, alert := range n.digest {
+		alerts = append(alerts, alert)
+	}
+	n.digest = nil
+	integration := n.integration
+	n.mtx.Unlock()
+
+	if len(alerts) == 0 {
+		return
+	}
+	sort.Sort(alerts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), digestNotifyTimeout)
+	defer cancel()
+	ctx = notify.WithReceiverName(ctx, n.receiver)
+	ctx = notify.WithGroupKey(ctx, fmt.Sprintf("digest/%s/%s/%d", n.receiver, integration.Name(), integration.Index()))
+	ctx = notify.WithGroupLabels(ctx, commonLabels(alerts))
+	ctx = notify.WithNow(ctx, time.Now())
+
+	n.logger.Debug("Sending digest", "alerts", len(alerts))
+	if _, err := n.send(ctx, integration, alerts); err != nil {
+		n.logger.Error("Failed to send digest", "alerts", len(alerts), "error", err)
+	}
+}
+
+func (n *notificationLimiter) send(ctx context.Context, integration *alertingNotify.Integration, alerts []*types.Alert) (bool, error) {
+	n.mtx.Lock()
+	rateLimiter := n.rateLimiter
+	n.mtx.Unlock()
+	if rateLimiter != nil && !rateLimiter.Allow() {
+		// The notification is dropped rather than failed, so that it is neither retried nor counted as a failure.
+		n.metrics.RateLimitedNotifications.WithLabelValues(integration.Name()).Inc()
+		n.logger.Warn("Notification is dropped because the rate limit of the receiver is reached", "alerts", len(alerts))
+		return false, nil
+	}
+	return integration.Notify(ctx, alerts...)
+}
+
+// commonLabels returns the labels that all alerts have in common.
+func commonLabels(alerts []*types.Alert) model.LabelSet {
+	if len(alerts) == 0 {
+		return model.LabelSet{}
+	}
+	result := alerts[0].Labels.Clone()
+	for _, alert := range alerts[1:] {
+		for name, value := range result {
+			if v, ok := alert.Labels[name]; !ok || v != value {
+				delete(result, name)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,189 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeLimitedNotifier struct {
+	mtx   sync.Mutex
+	calls []fakeLimitedNotifierCall
+}
+
+type fakeLimitedNotifierCall struct {
+	groupKey    string
+	groupLabels model.LabelSet
+	alerts      []*types.Alert
+}
+
+func (f *fakeLimitedNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	groupKey, _ := notify.GroupKey(ctx)
+	groupLabels, _ := notify.GroupLabels(ctx)
+	f.calls = append(f.calls, fakeLimitedNotifierCall{groupKey: groupKey, groupLabels: groupLabels, alerts: alerts})
+	return false, nil
+}
+
+func (f *fakeLimitedNotifier) SendResolved() bool {
+	return true
+}
+
+func (f *fakeLimitedNotifier) getCalls() []fakeLimitedNotifierCall {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return append([]fakeLimitedNotifierCall(nil), f.calls...)
+}
+
+func TestNotificationLimiters(t *testing.T) {
+	newAlert := func(labels model.LabelSet) *types.Alert {
+		return &types.Alert{Alert: model.Alert{Labels: labels, StartsAt: time.Now()}}
+	}
+	setup := func() (*notificationLimiters, *metrics.NotificationLimitsMetrics) {
+		m := metrics.NewNotificationLimitsMetrics(prometheus.NewRegistry())
+		return newNotificationLimiters(log.NewNopLogger(), m), m
+	}
+	newIntegration := func(n *fakeLimitedNotifier) *alertingNotify.Integration {
+		return alertingNotify.NewIntegration(n, n, "slack", 0, "receiver")
+	}
+
+	t.Run("integrations of receivers without limits are not wrapped", func(t *testing.T) {
+		limiters, _ := setup()
+		integrations := []*alertingNotify.Integration{newIntegration(&fakeLimitedNotifier{})}
+		next := make(map[string]*notificationLimiter)
+
+		require.Equal(t, integrations, limiters.wrap("receiver", models.ReceiverNotificationLimits{}, integrations, next))
+		require.Empty(t, next)
+	})
+
+	t.Run("notifications over the rate limit are dropped", func(t *testing.T) {
+		limiters, m := setup()
+		n := &fakeLimitedNotifier{}
+		limits := models.ReceiverNotificationLimits{RateLimit: 2, RateLimitInterval: time.Hour}
+		integration := limiters.wrap("receiver", limits, []*alertingNotify.Integration{newIntegration(n)}, make(map[string]*notificationLimiter))[0]
+
+		for i := 0; i < 2; i++ {
+			_, err := integration.Notify(context.Background(), newAlert(model.LabelSet{"alertname": "a"}))
+			require.NoError(t, err)
+		}
+		retry, err := integration.Notify(context.Background(), newAlert(model.LabelSet{"alertname": "a"}))
+		require.NoError(t, err)
+		require.False(t, retry)
+
+		require.Len(t, n.getCalls(), 2)
+		require.Equal(t, 1.0, testutil.ToFloat64(m.RateLimitedNotifications.WithLabelValues("slack")))
+	})
+
+	t.Run("rate limit is kept when the configuration is applied again", func(t *testing.T) {
+		limiters, _ := setup()
+		limits := models.ReceiverNotificationLimits{RateLimit: 1, RateLimitInterval: time.Hour}
+		next := make(map[string]*notificationLimiter)
+		integration := limiters.wrap("receiver", limits, []*alertingNotify.Integration{newIntegration(&fakeLimitedNotifier{})}, next)[0]
+		limiters.swap(next)
+		_, err := integration.Notify(context.Background(), newAlert(model.LabelSet{"alertname": "a"}))
+		require.NoError(t, err)
+
+		n := &fakeLimitedNotifier{}
+		next = make(map[string]*notificationLimiter)
+		integration = limiters.wrap("receiver", limits, []*alertingNotify.Integration{newIntegration(n)}, next)[0]
+		limiters.swap(next)
+		_, err = integration.Notify(context.Background(), newAlert(model.LabelSet{"alertname": "a"}))
+		require.NoError(t, err)
+		require.Empty(t, n.getCalls())
+
+		limiters.swap(map[string]*notificationLimiter{})
+		require.Empty(t, limiters.limiters)
+	})
+
+	t.Run("limiters are unchanged until the new ones are swapped in", func(t *testing.T) {
+		limiters, _ := setup()
+		n := &fakeLimitedNotifier{}
+		next := make(map[string]*notificationLimiter)
+		limits := models.ReceiverNotificationLimits{RateLimit: 1, RateLimitInterval: time.Hour}
+		integration := limiters.wrap("receiver", limits, []*alertingNotify.Integration{newIntegration(n)}, next)[0]
+		limiters.swap(next)
+		current := limiters.limiters
+
+		// the configuration with the new limits is built but not applied
+		limiters.wrap("receiver", models.ReceiverNotificationLimits{DigestWindow: time.Hour}, []*alertingNotify.Integration{newIntegration(&fakeLimitedNotifier{})}, make(map[string]*notificationLimiter))
+		require.Equal(t, current, limiters.limiters)
+
+		_, err := integration.Notify(context.Background(), newAlert(model.LabelSet{"alertname": "a"}))
+		require.NoError(t, err)
+		require.Len(t, n.getCalls(), 1)
+	})
+
+	t.Run("pending digest is taken over when the configuration is applied again", func(t *testing.T) {
+		limiters, _ := setup()
+		limits := models.ReceiverNotificationLimits{DigestWindow: time.Hour}
+		next := make(map[string]*notificationLimiter)
+		integration := limiters.wrap("receiver", limits, []*alertingNotify.Integration{newIntegration(&fakeLimitedNotifier{})}, next)[0]
+		limiters.swap(next)
+		_, err := integration.Notify(context.Background(), newAlert(model.LabelSet{"alertname": "a"}))
+		require.NoError(t, err)
+
+		n := &fakeLimitedNotifier{}
+		next = make(map[string]*notificationLimiter)
+		limiters.wrap("receiver", limits, []*alertingNotify.Integration{newIntegration(n)}, next)
+		limiters.swap(next)
+		limiters.stop()
+
+		calls := n.getCalls()
+		require.Len(t, calls, 1)
+		require.Len(t, calls[0].alerts, 1)
+	})
+
+	t.Run("notifications are batched into a digest", func(t *testing.T) {
+		limiters, m := setup()
+		n := &fakeLimitedNotifier{}
+		limits := models.ReceiverNotificationLimits{DigestWindow: 50 * time.Millisecond}
+		integration := limiters.wrap("receiver", limits, []*alertingNotify.Integration{newIntegration(n)}, make(map[string]*notificationLimiter))[0]
+
+		_, err := integration.Notify(context.Background(), newAlert(model.LabelSet{"alertname": "a", "team": "x"}))
+		require.NoError(t, err)
+		latest := newAlert(model.LabelSet{"alertname": "a", "team": "x"})
+		latest.EndsAt = time.Now()
+		_, err = integration.Notify(context.Background(), latest, newAlert(model.LabelSet{"alertname": "b", "team": "x"}))
+		require.NoError(t, err)
+		require.Empty(t, n.getCalls())
+
+		require.Eventually(t, func() bool {
+			return len(n.getCalls()) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+		call := n.getCalls()[0]
+		require.Len(t, call.alerts, 2)
+		require.Equal(t, latest, call.alerts[0])
+		require.Equal(t, model.LabelSet{"team": "x"}, call.groupLabels)
+		require.Equal(t, "digest/receiver/slack/0", call.groupKey)
+		require.Equal(t, 2.0, testutil.ToFloat64(m.DigestedNotifications.WithLabelValues("slack")))
+	})
+
+	t.Run("pending digests are sent when limiters stop", func(t *testing.T) {
+		limiters, _ := setup()
+		n := &fakeLimitedNotifier{}
+		limits := models.ReceiverNotificationLimits{DigestWindow: time.Hour}
+		next := make(map[string]*notificationLimiter)
+		integration := limiters.wrap("receiver", limits, []*alertingNotify.Integration{newIntegration(n)}, next)[0]
+		limiters.swap(next)
+
+		_, err := integration.Notify(context.Background(), newAlert(model.LabelSet{"alertname": "a"}))
+		require.NoError(t, err)
+		limiters.stop()
+
+		require.Len(t, n.getCalls(), 1)
+	})
+}
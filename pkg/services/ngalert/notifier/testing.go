@@ -19,8 +19,39 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 
 	alertingImages "github.com/grafana/alerting/images"
+	alertingNotify "github.com/grafana/alerting/notify"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/matrix"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/ntfy"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/teamsworkflows"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/zulip"
 )
 
+// GrafanaIntegrationConfigsForTesting are valid configurations of the integrations that are implemented in Grafana,
+// like alertingNotify.AllKnownConfigsForTesting for the integrations of the alerting package.
+var GrafanaIntegrationConfigsForTesting = map[string]alertingNotify.NotifierConfigTest{
+	matrix.Type: {
+		NotifierType: matrix.Type,
+		Config:       matrix.FullValidConfigForTesting,
+		Secrets:      matrix.FullValidSecretsForTesting,
+	},
+	ntfy.Type: {
+		NotifierType: ntfy.Type,
+		Config:       ntfy.FullValidConfigForTesting,
+		Secrets:      ntfy.FullValidSecretsForTesting,
+	},
+	teamsworkflows.Type: {
+		NotifierType: teamsworkflows.Type,
+		Config:       teamsworkflows.FullValidConfigForTesting,
+		Secrets:      teamsworkflows.FullValidSecretsForTesting,
+	},
+	zulip.Type: {
+		NotifierType: zulip.Type,
+		Config:       zulip.FullValidConfigForTesting,
+		Secrets:      zulip.FullValidSecretsForTesting,
+	},
+}
+
 type fakeConfigStore struct {
 	configs map[int64]*models.AlertConfiguration
 
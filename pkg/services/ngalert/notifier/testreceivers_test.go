@@ -3,11 +3,18 @@ package notifier
 import (
 	"context"
 	"errors"
+	"net/http"
 	"net/url"
 	"testing"
 
 	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/config"
 	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/matrix"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestInvalidReceiverError_Error(t *testing.T) {
@@ -80,3 +87,48 @@ func TestProcessNotifierError(t *testing.T) {
 		require.Equal(t, err, alertingNotify.ProcessIntegrationError(r, err))
 	})
 }
+
+func TestTestReceivers_GrafanaIntegrations(t *testing.T) {
+	am := setupAMTest(t)
+	ns := &notifications.NotificationServiceMock{}
+	am.NotificationService = ns
+	am.Settings.UnifiedAlerting.DefaultConfiguration = setting.GetAlertmanagerDefaultConfiguration()
+	require.NoError(t, am.SaveAndApplyDefaultConfig(context.Background()))
+
+	result, err := am.TestReceivers(context.Background(), apimodels.TestReceiversConfigBodyParams{
+		Receivers: []*apimodels.PostableApiReceiver{{
+			Receiver: config.Receiver{Name: "matrix"},
+			PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
+				GrafanaManagedReceivers: []*apimodels.PostableGrafanaReceiver{
+					{
+						UID:      "valid",
+						Name:     "valid",
+						Type:     matrix.Type,
+						Settings: apimodels.RawMessage(`{"homeserver_url": "http://localhost", "room_id": "!room:localhost", "access_token": "token"}`),
+					},
+					{
+						UID:      "invalid",
+						Name:     "invalid",
+						Type:     matrix.Type,
+						Settings: apimodels.RawMessage(`{"homeserver_url": "http://localhost"}`),
+					},
+				},
+			},
+		}},
+	})
+	require.NoError(t, err)
+
+	statuses := make(map[string]TestReceiverConfigResult)
+	for _, r := range result.Receivers {
+		for _, c := range r.Configs {
+			statuses[c.UID] = c
+		}
+	}
+	require.Equal(t, "ok", statuses["valid"].Status)
+	require.NoError(t, statuses["valid"].Error)
+	require.Equal(t, "failed", statuses["invalid"].Status)
+	require.ErrorContains(t, statuses["invalid"].Error, "could not find room_id property in settings")
+
+	require.Equal(t, http.MethodPut, ns.Webhook.HttpMethod)
+	require.Equal(t, "Bearer token", ns.Webhook.HttpHeader["Authorization"])
+}
@@ -0,0 +1,296 @@
+package prom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/prometheus/model/rulefmt"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// QueryRefID is the refID of the data source query of the converted rules.
+	QueryRefID = "A"
+	// MathRefID is the refID of the math expression that maps every series returned by the query to 1.
+	MathRefID = "B"
+	// ThresholdRefID is the refID of the threshold expression that is the condition of converted alert rules.
+	ThresholdRefID = "C"
+
+	defaultFromTimeRange = 10 * time.Minute
+)
+
+var ErrUnsupportedDatasourceType = errors.New("unsupported data source type")
+
+// Config configures the conversion of Prometheus rules to Grafana-managed rules.
+type Config struct {
+	// DatasourceUID is the UID of the data source that the converted rules query.
+	DatasourceUID string
+	// DatasourceType is the type of the data source, either prometheus or loki. Defaults to prometheus.
+	DatasourceType string
+	// DefaultInterval is the evaluation interval of groups that do not have an interval.
+	DefaultInterval time.Duration
+	// FromTimeRange is how far back in time the queries of the rules look. Defaults to 10 minutes.
+	FromTimeRange time.Duration
+	// NoDataState is the state of converted alert rules when the query returns no data. Defaults to OK,
+	// because a Prometheus alert that returns nothing is not firing.
+	NoDataState models.NoDataState
+	// ExecErrState is the state of converted alert rules when the evaluation fails. Defaults to Error.
+	ExecErrState models.ExecutionErrorState
+	// Validate, if set, is called with every converted rule. Rules that fail the validation are reported as RuleError.
+	Validate func(rule models.AlertRule) error
+}
+
+// RuleError is an error of a single rule, or of a whole group if Index is 0, that could not be converted.
+type RuleError struct {
+	Group string
+	// Rule is the name of the alert or the recorded metric.
+	Rule string
+	// Index is the position of the rule in the group, starting at 1.
+	Index int
+	Err   error
+}
+
+func (e RuleError) Error() string {
+	if e.Index == 0 {
+		return fmt.Sprintf("group %q: %s", e.Group, e.Err)
+	}
+	return fmt.Sprintf("group %q, rule %d %q: %s", e.Group, e.Index, e.Rule, e.Err)
+}
+
+func (e RuleError) Unwrap() error {
+	return e.Err
+}
+
+// Converter converts Prometheus rule groups to Grafana-managed alert and recording rules.
+type Converter struct {
+	cfg Config
+}
+
+func NewConverter(cfg Config) (*Converter, error) {
+	if cfg.DatasourceUID == "" {
+		return nil, errors.New("data source UID is required")
+	}
+	if cfg.DatasourceType == "" {
+		cfg.DatasourceType = datasources.DS_PROMETHEUS
+	}
+	if cfg.DatasourceType != datasources.DS_PROMETHEUS && cfg.DatasourceType != datasources.DS_LOKI {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDatasourceType, cfg.DatasourceType)
+	}
+	if cfg.DefaultInterval <= 0 {
+		return nil, errors.New("default interval must be positive")
+	}
+	if cfg.FromTimeRange <= 0 {
+		cfg.FromTimeRange = defaultFromTimeRange
+	}
+	if cfg.NoDataState == "" {
+		cfg.NoDataState = models.OK
+	}
+	if cfg.ExecErrState == "" {
+		cfg.ExecErrState = models.ErrorErrState
+	}
+	return &Converter{cfg: cfg}, nil
+}
+
+// Convert parses the rule groups in the Prometheus rule file format and converts them to rule groups in the namespace.
+// It returns an error if the content cannot be parsed. Rules that are invalid or cannot be converted are left out of
+// their group and reported as RuleError. Groups that cannot be converted are left out entirely.
+func (c *Converter) Convert(orgID int64, namespaceUID string, content []byte) ([]models.AlertRuleGroup, []RuleError, error) {
+	groups, ruleErrs, err := Parse(content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	invalid := make(map[string]map[int]struct{}, len(ruleErrs))
+	for _, e := range ruleErrs {
+		if invalid[e.Group] == nil {
+			invalid[e.Group] = map[int]struct{}{}
+		}
+		invalid[e.Group][e.Index] = struct{}{}
+	}
+
+	result := make([]models.AlertRuleGroup, 0, len(groups.Groups))
+	titles := make(map[string]string)
+	for _, group := range groups.Groups {
+		if group.Limit > 0 {
+			ruleErrs = append(ruleErrs, RuleError{Group: group.Name, Err: errors.New("limit is not supported")})
+			continue
+		}
+		interval := time.Duration(group.Interval)
+		if interval == 0 {
+			interval = c.cfg.DefaultInterval
+		}
+
+		converted := models.AlertRuleGroup{
+			Title:     group.Name,
+			FolderUID: namespaceUID,
+			Interval:  int64(interval.Seconds()),
+		}
+		for i, node := range group.Rules {
+			index := i + 1
+			if _, ok := invalid[group.Name][index]; ok {
+				continue
+			}
+			rule, err := c.convertRule(node)
+			if err == nil {
+				rule.OrgID = orgID
+				rule.NamespaceUID = namespaceUID
+				rule.RuleGroup = group.Name
+				rule.RuleGroupIndex = len(converted.Rules) + 1
+				rule.IntervalSeconds = converted.Interval
+				err = c.validate(rule, titles)
+			}
+			if err != nil {
+				ruleErrs = append(ruleErrs, RuleError{Group: group.Name, Rule: ruleName(node), Index: index, Err: err})
+				continue
+			}
+			titles[rule.Title] = group.Name
+			converted.Rules = append(converted.Rules, rule)
+		}
+		result = append(result, converted)
+	}
+	return result, ruleErrs, nil
+}
+
+// Parse parses the rule groups in the Prometheus rule file format. It returns an error if the content is not a valid
+// rule file, and a RuleError for every rule that is invalid.
+func Parse(content []byte) (*rulefmt.RuleGroups, []RuleError, error) {
+	groups, errs := rulefmt.Parse(content)
+	if groups == nil {
+		return nil, nil, fmt.Errorf("failed to parse rule groups: %w", errors.Join(errs...))
+	}
+
+	ruleErrs := make([]RuleError, 0, len(errs))
+	for _, err := range errs {
+		var rerr *rulefmt.Error
+		if errors.As(err, &rerr) {
+			ruleErrs = append(ruleErrs, RuleError{Group: rerr.Group, Rule: rerr.RuleName, Index: rerr.Rule, Err: &rerr.Err})
+			continue
+		}
+		// other errors are errors of groups, e.g. repeated names, that cannot be attributed to a single group.
+		return nil, nil, fmt.Errorf("invalid rule groups: %w", err)
+	}
+	return groups, ruleErrs, nil
+}
+
+// validate checks that the title of the rule is unique in the namespace, and validates the rule if the config has a validation.
+func (c *Converter) validate(rule models.AlertRule, titles map[string]string) error {
+	if other, ok := titles[rule.Title]; ok {
+		return fmt.Errorf("a rule with the same name exists in group %q", other)
+	}
+	if c.cfg.Validate != nil {
+		return c.cfg.Validate(rule)
+	}
+	return nil
+}
+
+func (c *Converter) convertRule(node rulefmt.RuleNode) (models.AlertRule, error) {
+	query, err := c.query(node.Expr.Value)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+
+	rule := models.AlertRule{
+		Labels: node.Labels,
+	}
+	if node.Record.Value != "" {
+		rule.Title = node.Record.Value
+		rule.Data = []models.AlertQuery{query}
+		rule.Record = &models.Record{
+			Metric: node.Record.Value,
+			From:   QueryRefID,
+		}
+		return rule, nil
+	}
+
+	math, err := expression(MathRefID, map[string]any{
+		"type": "math",
+		// every series that is returned by the query fires, like in Prometheus, regardless of its value.
+		"expression": fmt.Sprintf("is_number($%[1]s) || is_nan($%[1]s) || is_inf($%[1]s)", QueryRefID),
+	})
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	threshold, err := expression(ThresholdRefID, map[string]any{
+		"type":       "threshold",
+		"expression": MathRefID,
+		"conditions": []any{
+			map[string]any{
+				"evaluator": map[string]any{
+					"type":   "gt",
+					"params": []float64{0},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+
+	rule.Title = node.Alert.Value
+	rule.Condition = ThresholdRefID
+	rule.Data = []models.AlertQuery{query, math, threshold}
+	rule.For = time.Duration(node.For)
+	rule.KeepFiringFor = time.Duration(node.KeepFiringFor)
+	rule.Annotations = node.Annotations
+	rule.NoDataState = c.cfg.NoDataState
+	rule.ExecErrState = c.cfg.ExecErrState
+	return rule, nil
+}
+
+func (c *Converter) query(promQL string) (models.AlertQuery, error) {
+	model := map[string]any{
+		"refId": QueryRefID,
+		"expr":  promQL,
+		"datasource": map[string]string{
+			"type": c.cfg.DatasourceType,
+			"uid":  c.cfg.DatasourceUID,
+		},
+	}
+	// rules are evaluated with instant queries, like in Prometheus.
+	if c.cfg.DatasourceType == datasources.DS_LOKI {
+		model["queryType"] = "instant"
+	} else {
+		model["instant"] = true
+		model["range"] = false
+	}
+	raw, err := json.Marshal(model)
+	if err != nil {
+		return models.AlertQuery{}, err
+	}
+	return models.AlertQuery{
+		RefID:             QueryRefID,
+		DatasourceUID:     c.cfg.DatasourceUID,
+		RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(c.cfg.FromTimeRange)},
+		Model:             raw,
+	}, nil
+}
+
+func expression(refID string, model map[string]any) (models.AlertQuery, error) {
+	model["refId"] = refID
+	model["datasource"] = map[string]string{
+		"type": expr.DatasourceType,
+		"uid":  expr.DatasourceUID,
+	}
+	raw, err := json.Marshal(model)
+	if err != nil {
+		return models.AlertQuery{}, err
+	}
+	return models.AlertQuery{
+		RefID:         refID,
+		QueryType:     expr.DatasourceType,
+		DatasourceUID: expr.DatasourceUID,
+		Model:         raw,
+	}, nil
+}
+
+func ruleName(node rulefmt.RuleNode) string {
+	if node.Record.Value != "" {
+		return node.Record.Value
+	}
+	return node.Alert.Value
+}
@@ -0,0 +1,181 @@
+package prom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const ruleFile = `
+groups:
+  - name: node
+    interval: 30s
+    rules:
+      - alert: HighCPU
+        expr: node_cpu_usage > 0.9
+        for: 5m
+        keep_firing_for: 10m
+        labels:
+          severity: critical
+        annotations:
+          summary: "CPU usage of {{ $labels.instance }} is high"
+      - record: instance:node_cpu:rate5m
+        expr: rate(node_cpu_seconds_total[5m])
+        labels:
+          source: node
+  - name: default-interval
+    rules:
+      - alert: Down
+        expr: up == 0
+`
+
+func newTestConverter(t *testing.T, dsType string) *Converter {
+	t.Helper()
+	c, err := NewConverter(Config{
+		DatasourceUID:   "prom",
+		DatasourceType:  dsType,
+		DefaultInterval: time.Minute,
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func TestNewConverter(t *testing.T) {
+	_, err := NewConverter(Config{DefaultInterval: time.Minute})
+	require.Error(t, err)
+	_, err = NewConverter(Config{DatasourceUID: "prom"})
+	require.Error(t, err)
+	_, err = NewConverter(Config{DatasourceUID: "prom", DatasourceType: "graphite", DefaultInterval: time.Minute})
+	require.ErrorIs(t, err, ErrUnsupportedDatasourceType)
+}
+
+func TestConverterConvert(t *testing.T) {
+	c := newTestConverter(t, "")
+	groups, ruleErrs, err := c.Convert(1, "folder", []byte(ruleFile))
+	require.NoError(t, err)
+	require.Empty(t, ruleErrs)
+	require.Len(t, groups, 2)
+
+	node := groups[0]
+	require.Equal(t, "node", node.Title)
+	require.Equal(t, "folder", node.FolderUID)
+	require.Equal(t, int64(30), node.Interval)
+	require.Len(t, node.Rules, 2)
+	require.Equal(t, int64(60), groups[1].Interval)
+
+	t.Run("alert rules query the data source and fire on every series", func(t *testing.T) {
+		rule := node.Rules[0]
+		require.Equal(t, "HighCPU", rule.Title)
+		require.Equal(t, int64(1), rule.OrgID)
+		require.Equal(t, "folder", rule.NamespaceUID)
+		require.Equal(t, "node", rule.RuleGroup)
+		require.Equal(t, 1, rule.RuleGroupIndex)
+		require.Equal(t, int64(30), rule.IntervalSeconds)
+		require.Equal(t, 5*time.Minute, rule.For)
+		require.Equal(t, map[string]string{"severity": "critical"}, rule.Labels)
+		require.Equal(t, map[string]string{"summary": "CPU usage of {{ $labels.instance }} is high"}, rule.Annotations)
+		require.Equal(t, models.OK, rule.NoDataState)
+		require.Equal(t, models.ErrorErrState, rule.ExecErrState)
+		require.Nil(t, rule.Record)
+		require.Equal(t, ThresholdRefID, rule.Condition)
+		require.Len(t, rule.Data, 3)
+
+		query := rule.Data[0]
+		require.Equal(t, QueryRefID, query.RefID)
+		require.Equal(t, "prom", query.DatasourceUID)
+		require.Equal(t, models.Duration(10*time.Minute), query.RelativeTimeRange.From)
+		require.JSONEq(t, `{
+			"refId": "A",
+			"expr": "node_cpu_usage > 0.9",
+			"instant": true,
+			"range": false,
+			"datasource": {"type": "prometheus", "uid": "prom"}
+		}`, string(query.Model))
+
+		for _, q := range rule.Data[1:] {
+			require.Equal(t, expr.DatasourceUID, q.DatasourceUID)
+		}
+		var threshold map[string]any
+		require.NoError(t, json.Unmarshal(rule.Data[2].Model, &threshold))
+		require.Equal(t, "threshold", threshold["type"])
+		require.Equal(t, MathRefID, threshold["expression"])
+	})
+
+	t.Run("recording rules record the query", func(t *testing.T) {
+		rule := node.Rules[1]
+		require.Equal(t, "instance:node_cpu:rate5m", rule.Title)
+		require.Equal(t, 2, rule.RuleGroupIndex)
+		require.Equal(t, &models.Record{Metric: "instance:node_cpu:rate5m", From: QueryRefID}, rule.Record)
+		require.Empty(t, rule.Condition)
+		require.Len(t, rule.Data, 1)
+		require.Equal(t, map[string]string{"source": "node"}, rule.Labels)
+	})
+}
+
+func TestConverterConvertLoki(t *testing.T) {
+	c := newTestConverter(t, datasources.DS_LOKI)
+	groups, _, err := c.Convert(1, "folder", []byte(ruleFile))
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"refId": "A",
+		"expr": "node_cpu_usage > 0.9",
+		"queryType": "instant",
+		"datasource": {"type": "loki", "uid": "prom"}
+	}`, string(groups[0].Rules[0].Data[0].Model))
+}
+
+func TestConverterConvertErrors(t *testing.T) {
+	c := newTestConverter(t, "")
+
+	t.Run("invalid files fail", func(t *testing.T) {
+		_, _, err := c.Convert(1, "folder", []byte(`groups: [{name: a, unknown: b}]`))
+		require.Error(t, err)
+		_, _, err = c.Convert(1, "folder", []byte("groups:\n  - name: a\n  - name: a\n"))
+		require.Error(t, err)
+	})
+
+	t.Run("invalid rules are reported and left out", func(t *testing.T) {
+		groups, ruleErrs, err := c.Convert(1, "folder", []byte(`
+groups:
+  - name: a
+    rules:
+      - alert: Invalid
+        expr: "sum("
+      - record: keep_firing
+        expr: up
+        keep_firing_for: 5m
+      - alert: Valid
+        expr: up
+  - name: b
+    rules:
+      - alert: Valid
+        expr: down
+  - name: limited
+    limit: 10
+    rules:
+      - alert: Limited
+        expr: up
+`))
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+		require.Len(t, groups[0].Rules, 1)
+		require.Equal(t, "Valid", groups[0].Rules[0].Title)
+		require.Equal(t, 1, groups[0].Rules[0].RuleGroupIndex)
+		require.Empty(t, groups[1].Rules)
+
+		require.Len(t, ruleErrs, 4)
+		require.Equal(t, RuleError{Group: "a", Rule: "Invalid", Index: 1, Err: ruleErrs[0].Err}, ruleErrs[0])
+		require.Equal(t, "keep_firing", ruleErrs[1].Rule)
+		require.Equal(t, 2, ruleErrs[1].Index)
+		require.Equal(t, RuleError{Group: "b", Rule: "Valid", Index: 1, Err: ruleErrs[2].Err}, ruleErrs[2])
+		require.ErrorContains(t, ruleErrs[2], `same name exists in group "a"`)
+		require.Equal(t, "limited", ruleErrs[3].Group)
+		require.Zero(t, ruleErrs[3].Index)
+	})
+}
@@ -183,6 +183,9 @@ func (service *AlertRuleService) CreateAlertRule(ctx context.Context, user ident
 	} else if err := util.ValidateUID(rule.UID); err != nil {
 		return models.AlertRule{}, errors.Join(models.ErrAlertRuleFailedValidation, fmt.Errorf("cannot create rule with UID '%s': %w", rule.UID, err))
 	}
+	if err := store.ApplyRuleTemplates(ctx, service.ruleStore, &rule); err != nil {
+		return models.AlertRule{}, err
+	}
 	var interval = service.defaultIntervalSeconds
 	// check if user can bypass fine-grained rule authorization checks. If it cannot, verfiy that the user can add rules to the group
 	canWriteAllRules, err := service.authz.CanWriteAllRules(ctx, user)
@@ -230,6 +233,9 @@ func (service *AlertRuleService) CreateAlertRule(ctx context.Context, user ident
 			}
 		}
 	}
+	if err := store.ValidateDependencies(ctx, service.ruleStore, &store.GroupDelta{GroupKey: rule.GetGroupKey(), New: []*models.AlertRule{&rule}}); err != nil {
+		return models.AlertRule{}, err
+	}
 	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
 		ids, err := service.ruleStore.InsertAlertRules(ctx, []models.AlertRule{
 			rule,
@@ -401,6 +407,10 @@ func (service *AlertRuleService) ReplaceRuleGroup(ctx context.Context, user iden
 		}
 	}
 
+	if err := store.ValidateDependencies(ctx, service.ruleStore, delta); err != nil {
+		return err
+	}
+
 	return service.persistDelta(ctx, user, delta, provenance)
 }
 
@@ -425,6 +435,10 @@ func (service *AlertRuleService) DeleteRuleGroup(ctx context.Context, user ident
 		}
 	}
 
+	if err := store.ValidateDependencies(ctx, service.ruleStore, delta); err != nil {
+		return err
+	}
+
 	return service.persistDelta(ctx, user, delta, provenance)
 }
 
@@ -460,6 +474,13 @@ func (service *AlertRuleService) calcDelta(ctx context.Context, user identity.Re
 	}
 	rules := make([]*models.AlertRuleWithOptionals, 0, len(group.Rules))
 	group = *syncGroupRuleFields(&group, user.GetOrgID())
+	groupRules := make([]*models.AlertRule, 0, len(group.Rules))
+	for i := range group.Rules {
+		groupRules = append(groupRules, &group.Rules[i])
+	}
+	if err := store.ApplyRuleTemplates(ctx, service.ruleStore, groupRules...); err != nil {
+		return nil, err
+	}
 	for i := range group.Rules {
 		if err := group.Rules[i].SetDashboardAndPanelFromAnnotations(); err != nil {
 			return nil, err
@@ -543,6 +564,9 @@ func (service *AlertRuleService) persistDelta(ctx context.Context, user identity
 // UpdateAlertRule updates an alert rule.
 func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, user identity.Requester, rule models.AlertRule, provenance models.Provenance) (models.AlertRule, error) {
 	var storedRule *models.AlertRule
+	if err := store.ApplyRuleTemplates(ctx, service.ruleStore, &rule); err != nil {
+		return models.AlertRule{}, err
+	}
 	// check if the user has full access to all rules and can bypass the regular authorization validations.
 	// If it cannot, calculate the changes to the group caused by this update and authorize them.
 	canWriteAllRules, err := service.authz.CanWriteAllRules(ctx, user)
@@ -601,6 +625,9 @@ func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, user ident
 	if err != nil {
 		return models.AlertRule{}, err
 	}
+	if err := store.ValidateDependencies(ctx, service.ruleStore, &store.GroupDelta{GroupKey: rule.GetGroupKey(), Update: []store.RuleDelta{{Existing: storedRule, New: &rule}}}); err != nil {
+		return models.AlertRule{}, err
+	}
 	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
 		err := service.ruleStore.UpdateAlertRules(ctx, []models.UpdateRule{
 			{
@@ -651,6 +678,9 @@ func (service *AlertRuleService) DeleteAlertRule(ctx context.Context, user ident
 	// This is different from deleting groups. We delete the rules directly rather than persisting a delta here to keep the semantics the same.
 	// TODO: Either persist a delta here as a breaking change, or deprecate this endpoint in favor of the group endpoint.
 	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := store.ValidateNoDependents(ctx, service.ruleStore, user.GetOrgID(), []*models.AlertRule{rule}); err != nil {
+			return err
+		}
 		return service.deleteRules(ctx, user.GetOrgID(), rule)
 	})
 }
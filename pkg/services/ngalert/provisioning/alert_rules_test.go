@@ -995,6 +995,20 @@ func TestDeleteAlertRule(t *testing.T) {
 			require.Empty(t, deletes)
 		})
 	})
+
+	t.Run("it should not delete rules that other rules depend on", func(t *testing.T) {
+		service, ruleStore, _, ac := initServiceWithData(t)
+		ac.CanWriteAllRulesFunc = func(ctx context.Context, user identity.Requester) (bool, error) {
+			return true, nil
+		}
+		dependent := gen.With(gen.WithOrgID(orgID), gen.WithDependsOn(rules[0].UID)).GenerateRef()
+		ruleStore.PutRule(context.Background(), dependent)
+
+		err := service.DeleteAlertRule(context.Background(), u, rules[0].UID, groupProvenance)
+		require.ErrorIs(t, err, models.ErrAlertRuleHasDependentsBase)
+		require.ErrorContains(t, err, dependent.UID)
+		require.Empty(t, getDeleteQueries(ruleStore))
+	})
 }
 
 func TestGetAlertRule(t *testing.T) {
@@ -514,7 +514,7 @@ func ValidateContactPoint(ctx context.Context, e apimodels.EmbeddedContactPoint,
 	if err != nil {
 		return err
 	}
-	_, err = alertingNotify.BuildReceiverConfiguration(ctx, &alertingNotify.APIReceiver{
+	_, err = notifier.BuildReceiverConfiguration(ctx, &alertingNotify.APIReceiver{
 		GrafanaIntegrations: alertingNotify.GrafanaIntegrations{
 			Integrations: []*alertingNotify.GrafanaIntegrationConfig{&integration},
 		},
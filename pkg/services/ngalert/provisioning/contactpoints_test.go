@@ -1166,6 +1166,17 @@ func TestStitchReceivers(t *testing.T) {
 			require.Equal(t, c.expCfg, cfg.AlertmanagerConfig)
 		})
 	}
+
+	t.Run("renaming a receiver keeps its notification limits", func(t *testing.T) {
+		cfg := createTestConfigWithReceivers()
+		limits := &definitions.ReceiverNotificationLimits{RateLimit: 10}
+		cfg.AlertmanagerConfig.Receivers[0].NotificationLimits = limits
+
+		stitchReceiver(cfg, &definitions.PostableGrafanaReceiver{UID: "abc", Name: "new-receiver", Type: "slack"})
+
+		require.Equal(t, "new-receiver", cfg.AlertmanagerConfig.Receivers[0].Name)
+		require.Equal(t, limits, cfg.AlertmanagerConfig.Receivers[0].NotificationLimits)
+	})
 }
 
 func createTestConfigWithReceivers() *definitions.PostableUserConfig {
@@ -42,6 +42,16 @@ type RuleStore interface {
 	UpdateAlertRules(ctx context.Context, rule []models.UpdateRule) error
 	DeleteAlertRulesByUID(ctx context.Context, orgID int64, ruleUID ...string) error
 	GetAlertRulesGroupByRuleUID(ctx context.Context, query *models.GetAlertRulesGroupByRuleUIDQuery) ([]*models.AlertRule, error)
+	GetAlertRuleTemplate(ctx context.Context, orgID int64, uid string) (*models.AlertRuleTemplate, error)
+}
+
+// RuleTemplateStore represents the ability to persist and query alert rule templates.
+type RuleTemplateStore interface {
+	ListAlertRuleTemplates(ctx context.Context, orgID int64) ([]*models.AlertRuleTemplate, error)
+	GetAlertRuleTemplate(ctx context.Context, orgID int64, uid string) (*models.AlertRuleTemplate, error)
+	InsertAlertRuleTemplate(ctx context.Context, template *models.AlertRuleTemplate) error
+	UpdateAlertRuleTemplate(ctx context.Context, template *models.AlertRuleTemplate) error
+	DeleteAlertRuleTemplate(ctx context.Context, orgID int64, uid string) error
 }
 
 // QuotaChecker represents the ability to evaluate whether quotas are met.
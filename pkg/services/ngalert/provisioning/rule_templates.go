@@ -0,0 +1,226 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// ConditionValidator validates the queries and expressions of the condition of an alert rule.
+type ConditionValidator interface {
+	// Validate validates that the condition is correct. Returns nil if the condition is correct. Otherwise, error that describes the failure
+	Validate(ctx eval.EvaluationContext, condition models.Condition) error
+}
+
+// RuleTemplateService manages alert rule templates, and keeps the rules that are derived from them up to date.
+type RuleTemplateService struct {
+	templateStore      RuleTemplateStore
+	ruleStore          RuleStore
+	provenanceStore    ProvisioningStore
+	xact               TransactionManager
+	authz              ruleAccessControlService
+	conditionValidator ConditionValidator
+	cfg                setting.UnifiedAlertingSettings
+	log                log.Logger
+}
+
+func NewRuleTemplateService(templateStore RuleTemplateStore, ruleStore RuleStore, prov ProvisioningStore, xact TransactionManager,
+	authz RuleAccessControlService, conditionValidator ConditionValidator, cfg setting.UnifiedAlertingSettings, log log.Logger) *RuleTemplateService {
+	return &RuleTemplateService{
+		templateStore:      templateStore,
+		ruleStore:          ruleStore,
+		provenanceStore:    prov,
+		xact:               xact,
+		authz:              newRuleAccessControlService(authz),
+		conditionValidator: conditionValidator,
+		cfg:                cfg,
+		log:                log,
+	}
+}
+
+// GetRuleTemplates returns all alert rule templates of the organization and their provenances.
+func (svc *RuleTemplateService) GetRuleTemplates(ctx context.Context, orgID int64) ([]*models.AlertRuleTemplate, map[string]models.Provenance, error) {
+	templates, err := svc.templateStore.ListAlertRuleTemplates(ctx, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	provenances, err := svc.provenanceStore.GetProvenances(ctx, orgID, (&models.AlertRuleTemplate{}).ResourceType())
+	if err != nil {
+		return nil, nil, err
+	}
+	return templates, provenances, nil
+}
+
+// GetRuleTemplate returns the alert rule template and its provenance. If the template does not exist, ErrAlertRuleTemplateNotFound is returned.
+func (svc *RuleTemplateService) GetRuleTemplate(ctx context.Context, orgID int64, uid string) (models.AlertRuleTemplate, models.Provenance, error) {
+	template, err := svc.templateStore.GetAlertRuleTemplate(ctx, orgID, uid)
+	if err != nil {
+		return models.AlertRuleTemplate{}, models.ProvenanceNone, err
+	}
+	provenance, err := svc.provenanceStore.GetProvenance(ctx, template, orgID)
+	if err != nil {
+		return models.AlertRuleTemplate{}, models.ProvenanceNone, err
+	}
+	return *template, provenance, nil
+}
+
+// CreateRuleTemplate creates a new alert rule template. The created template is returned.
+func (svc *RuleTemplateService) CreateRuleTemplate(ctx context.Context, template models.AlertRuleTemplate, provenance models.Provenance) (models.AlertRuleTemplate, error) {
+	if template.UID != "" {
+		if err := util.ValidateUID(template.UID); err != nil {
+			return models.AlertRuleTemplate{}, fmt.Errorf("%w: cannot create template with UID '%s': %s", models.ErrAlertRuleTemplateFailedValidation, template.UID, err)
+		}
+	}
+	if err := template.Validate(); err != nil {
+		return models.AlertRuleTemplate{}, err
+	}
+	err := svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.templateStore.InsertAlertRuleTemplate(ctx, &template); err != nil {
+			return err
+		}
+		return svc.provenanceStore.SetProvenance(ctx, &template, template.OrgID, provenance)
+	})
+	if err != nil {
+		return models.AlertRuleTemplate{}, err
+	}
+	return template, nil
+}
+
+// UpdateRuleTemplate replaces an existing alert rule template, and renders all rules that are derived from it again.
+// The update is rejected if any of the rules cannot be rendered with the new template, if a rendered rule is invalid,
+// if the rule is provisioned with a provenance that cannot be changed with the given one, or if the user is not
+// authorized to update it. The updated template is returned.
+func (svc *RuleTemplateService) UpdateRuleTemplate(ctx context.Context, user identity.Requester, template models.AlertRuleTemplate, provenance models.Provenance) (models.AlertRuleTemplate, error) {
+	if err := template.Validate(); err != nil {
+		return models.AlertRuleTemplate{}, err
+	}
+	existing, err := svc.templateStore.GetAlertRuleTemplate(ctx, template.OrgID, template.UID)
+	if err != nil {
+		return models.AlertRuleTemplate{}, err
+	}
+	if err := svc.checkProvenance(ctx, existing, provenance); err != nil {
+		return models.AlertRuleTemplate{}, err
+	}
+	if template.Version != 0 && template.Version != existing.Version {
+		return models.AlertRuleTemplate{}, ErrVersionConflict.Errorf("provided version %d of alert rule template %s does not match current version %d", template.Version, template.UID, existing.Version)
+	}
+	template.ID = existing.ID
+	template.Version = existing.Version
+
+	// check if the user has full access to all rules and can bypass the regular authorization validations.
+	canWriteAllRules, err := svc.authz.CanWriteAllRules(ctx, user)
+	if err != nil {
+		return models.AlertRuleTemplate{}, err
+	}
+
+	var updated int
+	err = svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		derived, err := svc.ruleStore.ListAlertRules(ctx, &models.ListAlertRulesQuery{OrgID: template.OrgID, TemplateUID: template.UID})
+		if err != nil {
+			return err
+		}
+		ruleProvenances, err := svc.provenanceStore.GetProvenances(ctx, template.OrgID, (&models.AlertRule{}).ResourceType())
+		if err != nil {
+			return err
+		}
+		updates := make([]models.UpdateRule, 0, len(derived))
+		for _, rule := range derived {
+			// the derived rules are updated with the provenance of the template, as if they were updated one by one.
+			if stored := ruleProvenances[rule.UID]; stored != provenance && stored != models.ProvenanceNone {
+				return fmt.Errorf("failed to update rule %s: %w", rule.UID, MakeErrProvenanceChangeNotAllowed(stored, provenance))
+			}
+			rendered, err := svc.renderRule(ctx, user, &template, rule, canWriteAllRules)
+			if err != nil {
+				return fmt.Errorf("failed to update rule %s: %w", rule.UID, err)
+			}
+			updates = append(updates, models.UpdateRule{Existing: rule, New: *rendered})
+		}
+		if err := svc.templateStore.UpdateAlertRuleTemplate(ctx, &template); err != nil {
+			return err
+		}
+		if len(updates) > 0 {
+			if err := svc.ruleStore.UpdateAlertRules(ctx, updates); err != nil {
+				return err
+			}
+		}
+		updated = len(updates)
+		return svc.provenanceStore.SetProvenance(ctx, &template, template.OrgID, provenance)
+	})
+	if err != nil {
+		return models.AlertRuleTemplate{}, err
+	}
+	svc.log.Debug("Alert rule template updated", "uid", template.UID, "org_id", template.OrgID, "rules", updated)
+	return template, nil
+}
+
+// renderRule renders the derived rule with the template, and validates and authorizes the result in the same way as a
+// regular update of the rule.
+func (svc *RuleTemplateService) renderRule(ctx context.Context, user identity.Requester, template *models.AlertRuleTemplate, rule *models.AlertRule, canWriteAllRules bool) (*models.AlertRule, error) {
+	rendered := models.CopyRule(rule)
+	if err := template.Apply(rendered); err != nil {
+		return nil, err
+	}
+	rendered.Updated = time.Now()
+	if err := rendered.ValidateAlertRule(svc.cfg); err != nil {
+		return nil, err
+	}
+	if !slices.ContainsFunc(rendered.Data, func(q models.AlertQuery) bool { return q.RefID == rendered.Condition }) {
+		return nil, fmt.Errorf("%w: condition %s does not exist, must be one of the queries or expressions", models.ErrAlertRuleFailedValidation, rendered.Condition)
+	}
+	if err := svc.conditionValidator.Validate(eval.NewContext(ctx, user), rendered.GetEvalCondition()); err != nil {
+		return nil, fmt.Errorf("%w '%s' (UID: %s): %s", models.ErrAlertRuleFailedValidation, rendered.Title, rendered.UID, err.Error())
+	}
+	if canWriteAllRules {
+		return rendered, nil
+	}
+	delta, err := store.CalculateRuleUpdate(ctx, svc.ruleStore, &models.AlertRuleWithOptionals{AlertRule: *rendered})
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.authz.AuthorizeRuleGroupWrite(ctx, user, delta); err != nil {
+		return nil, err
+	}
+	return rendered, nil
+}
+
+// DeleteRuleTemplate deletes the alert rule template. If rules are derived from the template, ErrAlertRuleTemplateInUse is returned.
+// If the template does not exist, no error is returned.
+func (svc *RuleTemplateService) DeleteRuleTemplate(ctx context.Context, orgID int64, uid string, provenance models.Provenance) error {
+	target := &models.AlertRuleTemplate{OrgID: orgID, UID: uid}
+	if err := svc.checkProvenance(ctx, target, provenance); err != nil {
+		return err
+	}
+	return svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		derived, err := svc.ruleStore.ListAlertRules(ctx, &models.ListAlertRulesQuery{OrgID: orgID, TemplateUID: uid})
+		if err != nil {
+			return err
+		}
+		if len(derived) > 0 {
+			return fmt.Errorf("%w: %d alert rules are derived from template %s", models.ErrAlertRuleTemplateInUse, len(derived), uid)
+		}
+		if err := svc.templateStore.DeleteAlertRuleTemplate(ctx, orgID, uid); err != nil {
+			return err
+		}
+		return svc.provenanceStore.DeleteProvenance(ctx, target, orgID)
+	})
+}
+
+func (svc *RuleTemplateService) checkProvenance(ctx context.Context, template *models.AlertRuleTemplate, provenance models.Provenance) error {
+	storedProvenance, err := svc.provenanceStore.GetProvenance(ctx, template, template.OrgID)
+	if err != nil {
+		return err
+	}
+	if storedProvenance != provenance && storedProvenance != models.ProvenanceNone {
+		return fmt.Errorf("cannot change provenance from '%s' to '%s'", storedProvenance, provenance)
+	}
+	return nil
+}
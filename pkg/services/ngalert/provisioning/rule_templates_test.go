@@ -0,0 +1,251 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	acfakes "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol/fakes"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRuleTemplateService(t *testing.T) {
+	orgID := int64(1)
+	u := &user.SignedInUser{OrgID: orgID}
+	newTemplate := func() models.AlertRuleTemplate {
+		return models.AlertRuleTemplate{
+			OrgID:      orgID,
+			UID:        "template",
+			Title:      "High CPU usage",
+			RuleTitle:  "High CPU usage on ${instance}",
+			Parameters: []models.AlertRuleTemplateParameter{{Name: "instance", Type: models.AlertRuleTemplateParameterString}},
+			Condition:  "A",
+			Data: []models.AlertQuery{{
+				RefID:         "A",
+				DatasourceUID: "__expr__",
+				Model:         json.RawMessage(`{"type":"math","expression":"1 == 1"}`),
+			}},
+			NoDataState:  models.NoData,
+			ExecErrState: models.ErrorErrState,
+			Labels:       map[string]string{"instance": "${instance}"},
+		}
+	}
+	derivedRule := func(instance string) *models.AlertRule {
+		rule := models.RuleGen.With(models.RuleGen.WithOrgID(orgID), models.RuleGen.WithNoNotificationSettings(), models.RuleGen.WithIntervalMatching(10*time.Second)).GenerateRef()
+		rule.Record = nil
+		rule.Template = &models.AlertRuleTemplateRef{UID: "template", Parameters: map[string]string{"instance": instance}}
+		return rule
+	}
+	setupWithAuthz := func(t *testing.T, ac *fakeRuleAccessControlService) (*RuleTemplateService, *fakes.RuleStore, *fakes.FakeProvisioningStore) {
+		ruleStore := fakes.NewRuleStore(t)
+		provenanceStore := fakes.NewFakeProvisioningStore()
+		cfg := setting.UnifiedAlertingSettings{BaseInterval: 10 * time.Second}
+		svc := NewRuleTemplateService(ruleStore, ruleStore, provenanceStore, newNopTransactionManager(), &acfakes.FakeRuleService{}, eval_mocks.NewEvaluatorFactory(nil), cfg, log.NewNopLogger())
+		svc.authz = ac
+		return svc, ruleStore, provenanceStore
+	}
+	setup := func(t *testing.T) (*RuleTemplateService, *fakes.RuleStore, *fakes.FakeProvisioningStore) {
+		return setupWithAuthz(t, &fakeRuleAccessControlService{})
+	}
+	updatedRules := func(ruleStore *fakes.RuleStore) []models.UpdateRule {
+		var result []models.UpdateRule
+		for _, op := range ruleStore.RecordedOps {
+			if updates, ok := op.([]models.UpdateRule); ok {
+				result = append(result, updates...)
+			}
+		}
+		return result
+	}
+
+	t.Run("creates template with provenance", func(t *testing.T) {
+		svc, _, provenanceStore := setup(t)
+
+		created, err := svc.CreateRuleTemplate(context.Background(), newTemplate(), models.ProvenanceAPI)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), created.Version)
+
+		template, provenance, err := svc.GetRuleTemplate(context.Background(), orgID, "template")
+		require.NoError(t, err)
+		require.Equal(t, "High CPU usage", template.Title)
+		require.Equal(t, models.ProvenanceAPI, provenance)
+		require.Equal(t, models.ProvenanceAPI, provenanceStore.Records[orgID]["template"+template.ResourceType()])
+	})
+
+	t.Run("rejects invalid template", func(t *testing.T) {
+		svc, _, _ := setup(t)
+		template := newTemplate()
+		template.RuleTitle = ""
+
+		_, err := svc.CreateRuleTemplate(context.Background(), template, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleTemplateFailedValidation)
+
+		template = newTemplate()
+		template.Condition = "B"
+		_, err = svc.CreateRuleTemplate(context.Background(), template, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleTemplateFailedValidation)
+	})
+
+	t.Run("update renders derived rules again", func(t *testing.T) {
+		svc, ruleStore, _ := setup(t)
+		_, err := svc.CreateRuleTemplate(context.Background(), newTemplate(), models.ProvenanceAPI)
+		require.NoError(t, err)
+		unrelated := models.RuleGen.With(models.RuleGen.WithOrgID(orgID)).GenerateRef()
+		ruleStore.PutRule(context.Background(), derivedRule("web-1"), derivedRule("web-2"), unrelated)
+
+		template := newTemplate()
+		template.RuleTitle = "CPU usage is high on ${instance}"
+		template.Annotations = map[string]string{"summary": "instance ${instance}"}
+		updated, err := svc.UpdateRuleTemplate(context.Background(), u, template, models.ProvenanceAPI)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), updated.Version)
+
+		updates := updatedRules(ruleStore)
+		require.Len(t, updates, 2)
+		titles := make([]string, 0, len(updates))
+		for _, upd := range updates {
+			require.NotEqual(t, unrelated.UID, upd.New.UID)
+			require.Equal(t, upd.Existing.UID, upd.New.UID)
+			require.Equal(t, upd.Existing.Template, upd.New.Template)
+			require.Equal(t, map[string]string{"summary": "instance " + upd.New.Template.Parameters["instance"]}, upd.New.Annotations)
+			titles = append(titles, upd.New.Title)
+		}
+		require.ElementsMatch(t, []string{"CPU usage is high on web-1", "CPU usage is high on web-2"}, titles)
+	})
+
+	t.Run("update is rejected if derived rules cannot be rendered", func(t *testing.T) {
+		svc, ruleStore, _ := setup(t)
+		_, err := svc.CreateRuleTemplate(context.Background(), newTemplate(), models.ProvenanceAPI)
+		require.NoError(t, err)
+		ruleStore.PutRule(context.Background(), derivedRule("web-1"))
+
+		template := newTemplate()
+		template.Parameters = append(template.Parameters, models.AlertRuleTemplateParameter{Name: "threshold", Type: models.AlertRuleTemplateParameterNumber})
+		_, err = svc.UpdateRuleTemplate(context.Background(), u, template, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.Empty(t, updatedRules(ruleStore))
+
+		stored, _, err := svc.GetRuleTemplate(context.Background(), orgID, "template")
+		require.NoError(t, err)
+		require.Len(t, stored.Parameters, 1)
+	})
+
+	t.Run("update is rejected if derived rules are invalid", func(t *testing.T) {
+		svc, ruleStore, _ := setup(t)
+		_, err := svc.CreateRuleTemplate(context.Background(), newTemplate(), models.ProvenanceAPI)
+		require.NoError(t, err)
+		ruleStore.PutRule(context.Background(), derivedRule("web-1"))
+
+		template := newTemplate()
+		template.Labels = map[string]string{models.AutogeneratedRouteLabel: "${instance}"}
+		_, err = svc.UpdateRuleTemplate(context.Background(), u, template, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.Empty(t, updatedRules(ruleStore))
+	})
+
+	t.Run("update is rejected if queries of derived rules are invalid", func(t *testing.T) {
+		svc, ruleStore, _ := setup(t)
+		_, err := svc.CreateRuleTemplate(context.Background(), newTemplate(), models.ProvenanceAPI)
+		require.NoError(t, err)
+		ruleStore.PutRule(context.Background(), derivedRule("web-1"))
+
+		svc.conditionValidator = eval_mocks.NewFailingEvaluatorFactory(errors.New("invalid query"))
+		_, err = svc.UpdateRuleTemplate(context.Background(), u, newTemplate(), models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "invalid query")
+		require.Empty(t, updatedRules(ruleStore))
+	})
+
+	t.Run("update is rejected if provenance of derived rules does not match", func(t *testing.T) {
+		svc, ruleStore, provenanceStore := setup(t)
+		_, err := svc.CreateRuleTemplate(context.Background(), newTemplate(), models.ProvenanceAPI)
+		require.NoError(t, err)
+		provisioned := derivedRule("web-2")
+		ruleStore.PutRule(context.Background(), derivedRule("web-1"), provisioned)
+		require.NoError(t, provenanceStore.SetProvenance(context.Background(), provisioned, orgID, models.ProvenanceFile))
+
+		_, err = svc.UpdateRuleTemplate(context.Background(), u, newTemplate(), models.ProvenanceAPI)
+		require.ErrorIs(t, err, ErrProvenanceChangeNotAllowed)
+		require.ErrorContains(t, err, provisioned.UID)
+		require.Empty(t, updatedRules(ruleStore))
+
+		stored, _, err := svc.GetRuleTemplate(context.Background(), orgID, "template")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), stored.Version)
+	})
+
+	t.Run("update is authorized for every derived rule", func(t *testing.T) {
+		ac := &fakeRuleAccessControlService{}
+		svc, ruleStore, _ := setupWithAuthz(t, ac)
+		_, err := svc.CreateRuleTemplate(context.Background(), newTemplate(), models.ProvenanceAPI)
+		require.NoError(t, err)
+		ruleStore.PutRule(context.Background(), derivedRule("web-1"), derivedRule("web-2"))
+
+		authzErr := errors.New("forbidden")
+		ac.AuthorizeRuleChangesFunc = func(ctx context.Context, user identity.Requester, change *store.GroupDelta) error {
+			for _, upd := range change.Update {
+				if upd.New.Template.Parameters["instance"] == "web-2" {
+					return authzErr
+				}
+			}
+			return nil
+		}
+		_, err = svc.UpdateRuleTemplate(context.Background(), u, newTemplate(), models.ProvenanceAPI)
+		require.ErrorIs(t, err, authzErr)
+		require.Empty(t, updatedRules(ruleStore))
+
+		stored, _, err := svc.GetRuleTemplate(context.Background(), orgID, "template")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), stored.Version)
+
+		t.Run("unless the user can write all rules", func(t *testing.T) {
+			ac.CanWriteAllRulesFunc = func(ctx context.Context, user identity.Requester) (bool, error) {
+				return true, nil
+			}
+			_, err = svc.UpdateRuleTemplate(context.Background(), u, newTemplate(), models.ProvenanceAPI)
+			require.NoError(t, err)
+			require.Len(t, updatedRules(ruleStore), 2)
+		})
+	})
+
+	t.Run("update is rejected if provenance does not match", func(t *testing.T) {
+		svc, _, _ := setup(t)
+		_, err := svc.CreateRuleTemplate(context.Background(), newTemplate(), models.ProvenanceFile)
+		require.NoError(t, err)
+
+		_, err = svc.UpdateRuleTemplate(context.Background(), u, newTemplate(), models.ProvenanceAPI)
+		require.ErrorContains(t, err, "cannot change provenance")
+	})
+
+	t.Run("update fails if template does not exist", func(t *testing.T) {
+		svc, _, _ := setup(t)
+		_, err := svc.UpdateRuleTemplate(context.Background(), u, newTemplate(), models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleTemplateNotFound)
+	})
+
+	t.Run("delete is rejected while rules are derived from template", func(t *testing.T) {
+		svc, ruleStore, provenanceStore := setup(t)
+		_, err := svc.CreateRuleTemplate(context.Background(), newTemplate(), models.ProvenanceAPI)
+		require.NoError(t, err)
+		ruleStore.PutRule(context.Background(), derivedRule("web-1"))
+
+		err = svc.DeleteRuleTemplate(context.Background(), orgID, "template", models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleTemplateInUse)
+
+		ruleStore.Rules[orgID] = nil
+		require.NoError(t, svc.DeleteRuleTemplate(context.Background(), orgID, "template", models.ProvenanceAPI))
+		_, _, err = svc.GetRuleTemplate(context.Background(), orgID, "template")
+		require.ErrorIs(t, err, models.ErrAlertRuleTemplateNotFound)
+		require.Empty(t, provenanceStore.Records[orgID])
+	})
+}
@@ -60,6 +60,7 @@ func newRuleFactory(
 	logger log.Logger,
 	tracer tracing.Tracer,
 	recordingWriter RecordingWriter,
+	dependencies *dependencyTracker,
 	evalAppliedHook evalAppliedFunc,
 	stopAppliedHook stopAppliedFunc,
 ) ruleFactoryFunc {
@@ -76,6 +77,7 @@ func newRuleFactory(
 				met,
 				tracer,
 				recordingWriter,
+				dependencies,
 			)
 		}
 		return newAlertRule(
@@ -92,6 +94,7 @@ func newRuleFactory(
 			met,
 			logger,
 			tracer,
+			dependencies,
 			evalAppliedHook,
 			stopAppliedHook,
 		)
@@ -122,6 +125,7 @@ type alertRule struct {
 	stateManager *state.Manager
 	evalFactory  eval.EvaluatorFactory
 	ruleProvider ruleProvider
+	dependencies *dependencyTracker
 
 	// Event hooks that are only used in tests.
 	evalAppliedHook evalAppliedFunc
@@ -146,6 +150,7 @@ func newAlertRule(
 	met *metrics.Scheduler,
 	logger log.Logger,
 	tracer tracing.Tracer,
+	dependencies *dependencyTracker,
 	evalAppliedHook func(ngmodels.AlertRuleKey, time.Time),
 	stopAppliedHook func(ngmodels.AlertRuleKey),
 ) *alertRule {
@@ -164,6 +169,7 @@ func newAlertRule(
 		stateManager:         stateManager,
 		evalFactory:          evalFactory,
 		ruleProvider:         ruleProvider,
+		dependencies:         dependencies,
 		evalAppliedHook:      evalAppliedHook,
 		stopAppliedHook:      stopAppliedHook,
 		metrics:              met,
@@ -282,6 +288,7 @@ func (a *alertRule) Run() error {
 					currentFingerprint = f
 					if isPaused {
 						logger.Debug("Skip rule evaluation because it is paused")
+						a.dependencies.record(ctx, nil)
 						return
 					}
 
@@ -350,10 +357,18 @@ func (a *alertRule) evaluate(ctx context.Context, f fingerprint, attempt int64,
 	evalAttemptTotal := a.metrics.EvalAttemptTotal.WithLabelValues(orgID)
 	evalAttemptFailures := a.metrics.EvalAttemptFailures.WithLabelValues(orgID)
 	evalTotalFailures := a.metrics.EvalFailures.WithLabelValues(orgID)
-	processDuration := a.metrics.ProcessDuration.WithLabelValues(orgID)
-	sendDuration := a.metrics.SendDuration.WithLabelValues(orgID)
 
 	logger := a.logger.FromContext(ctx).New("version", e.rule.Version, "fingerprint", f, "attempt", attempt, "now", e.scheduledAt).FromContext(ctx)
+
+	if err := a.dependencies.check(ctx, e); err != nil {
+		logger.Warn("Skip rule evaluation because a rule it depends on failed", "error", err)
+		span.SetStatus(codes.Error, "dependency failed")
+		span.RecordError(err)
+		a.dependencies.record(e, err)
+		a.processResults(ctx, logger, e, span, eval.Results{eval.NewResultFromError(err, e.scheduledAt, 0)})
+		return nil
+	}
+
 	start := a.clock.Now()
 
 	evalCtx := eval.NewContextWithPreviousResults(ctx, SchedulerUserFor(e.rule.OrgID), a.newLoadedMetricsReader(e.rule))
@@ -376,6 +391,11 @@ func (a *alertRule) evaluate(ctx context.Context, f fingerprint, attempt int64,
 	if ctx.Err() != nil { // check if the context is not cancelled. The evaluation can be a long-running task.
 		span.SetStatus(codes.Error, "rule evaluation cancelled")
 		logger.Debug("Skip updating the state because the context has been cancelled")
+		if err == nil {
+			err = fmt.Errorf("rule evaluation cancelled: %w", ctx.Err())
+		}
+		// record the outcome so that the rules depending on this one do not wait for it.
+		a.dependencies.record(e, err)
 		return nil
 	}
 
@@ -421,7 +441,18 @@ func (a *alertRule) evaluate(ctx context.Context, f fingerprint, attempt int64,
 			attribute.Int64("results", int64(len(results))),
 		))
 	}
-	start = a.clock.Now()
+	a.dependencies.record(e, err)
+	a.processResults(ctx, logger, e, span, results)
+	return nil
+}
+
+// processResults updates the state of the rule with the results of the evaluation and sends the alerts.
+func (a *alertRule) processResults(ctx context.Context, logger log.Logger, e *Evaluation, span trace.Span, results eval.Results) {
+	orgID := fmt.Sprint(a.key.OrgID)
+	processDuration := a.metrics.ProcessDuration.WithLabelValues(orgID)
+	sendDuration := a.metrics.SendDuration.WithLabelValues(orgID)
+
+	start := a.clock.Now()
 	_ = a.stateManager.ProcessEvalResults(
 		ctx,
 		e.scheduledAt,
@@ -438,8 +469,6 @@ func (a *alertRule) evaluate(ctx context.Context, f fingerprint, attempt int64,
 		},
 	)
 	processDuration.Observe(a.clock.Now().Sub(start).Seconds())
-
-	return nil
 }
 
 // send sends alerts for the given state transitions.
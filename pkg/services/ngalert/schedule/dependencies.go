@@ -0,0 +1,163 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ruleOutcome is the outcome of the latest evaluation of a rule.
+type ruleOutcome struct {
+	scheduledAt time.Time
+	err         error
+}
+
+// dependencyTracker keeps the outcome of the latest evaluation of every rule, so that rules that depend on other rules
+// are evaluated only when the rules they depend on are healthy.
+type dependencyTracker struct {
+	mtx      sync.Mutex
+	outcomes map[ngmodels.AlertRuleKey]ruleOutcome
+	// updated is closed and replaced every time an outcome is recorded.
+	updated chan struct{}
+	// timeout is how long a rule waits for the rules it depends on that are scheduled in the same tick.
+	timeout time.Duration
+}
+
+func newDependencyTracker(timeout time.Duration) *dependencyTracker {
+	return &dependencyTracker{
+		outcomes: make(map[ngmodels.AlertRuleKey]ruleOutcome),
+		updated:  make(chan struct{}),
+		timeout:  timeout,
+	}
+}
+
+// record saves the outcome of the evaluation of the rule and wakes up the rules that wait for it.
+func (t *dependencyTracker) record(e *Evaluation, err error) {
+	if t == nil {
+		return
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.outcomes[e.rule.GetKey()] = ruleOutcome{scheduledAt: e.scheduledAt, err: err}
+	close(t.updated)
+	t.updated = make(chan struct{})
+}
+
+// forget removes the outcome of the rule, e.g. when the rule is deleted.
+func (t *dependencyTracker) forget(key ngmodels.AlertRuleKey) {
+	if t == nil {
+		return
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	delete(t.outcomes, key)
+}
+
+// check waits until the rules that the evaluated rule depends on, and that are scheduled in the same tick, are evaluated.
+// It returns an error that wraps eval.ErrDependencyFailed if the latest evaluation of any of the dependencies failed.
+// Dependencies that have not been evaluated yet are considered healthy.
+func (t *dependencyTracker) check(ctx context.Context, e *Evaluation) error {
+	if t == nil || len(e.rule.DependsOn) == 0 {
+		return nil
+	}
+	if len(e.dependencies) > 0 {
+		t.wait(ctx, e.dependencies, e.scheduledAt)
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	for _, uid := range e.rule.DependsOn {
+		outcome, ok := t.outcomes[ngmodels.AlertRuleKey{OrgID: e.rule.OrgID, UID: uid}]
+		if ok && outcome.err != nil {
+			return fmt.Errorf("%w: rule %s failed: %s", eval.ErrDependencyFailed, uid, outcome.err)
+		}
+	}
+	return nil
+}
+
+// wait blocks until all rules are evaluated at or after scheduledAt, the timeout expires, or the context is cancelled.
+func (t *dependencyTracker) wait(ctx context.Context, keys []ngmodels.AlertRuleKey, scheduledAt time.Time) {
+	timer := time.NewTimer(t.timeout)
+	defer timer.Stop()
+	for {
+		t.mtx.Lock()
+		done := true
+		for _, key := range keys {
+			if outcome, ok := t.outcomes[key]; !ok || outcome.scheduledAt.Before(scheduledAt) {
+				done = false
+				break
+			}
+		}
+		updated := t.updated
+		t.mtx.Unlock()
+		if done {
+			return
+		}
+
+		select {
+		case <-updated:
+		case <-timer.C:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sortByDependencies orders the rules that are ready to run by UID, and moves every rule after the rules it depends on,
+// so that dependencies are evaluated first within the tick. It also sets the dependencies that every evaluation waits for.
+// Rules that are part of a dependency cycle are placed at the end and do not wait for each other.
+func sortByDependencies(items []readyToRunItem) []readyToRunItem {
+	slices.SortFunc(items, func(a, b readyToRunItem) int {
+		return strings.Compare(a.rule.UID, b.rule.UID)
+	})
+
+	index := make(map[ngmodels.AlertRuleKey]int, len(items))
+	for i, item := range items {
+		index[item.rule.GetKey()] = i
+	}
+	pending := make([]int, len(items))
+	dependents := make(map[int][]int)
+	for i, item := range items {
+		for _, uid := range item.rule.DependsOn {
+			key := ngmodels.AlertRuleKey{OrgID: item.rule.OrgID, UID: uid}
+			if j, ok := index[key]; ok && j != i {
+				pending[i]++
+				dependents[j] = append(dependents[j], i)
+				items[i].dependencies = append(items[i].dependencies, key)
+			}
+		}
+	}
+
+	result := make([]readyToRunItem, 0, len(items))
+	queue := make([]int, 0, len(items))
+	for i := range items {
+		if pending[i] == 0 {
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		result = append(result, items[i])
+		for _, j := range dependents[i] {
+			pending[j]--
+			if pending[j] == 0 {
+				queue = append(queue, j)
+			}
+		}
+	}
+	for i := range items {
+		if pending[i] > 0 {
+			items[i].dependencies = nil
+			result = append(result, items[i])
+		}
+	}
+	return result
+}
@@ -0,0 +1,115 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestSortByDependencies(t *testing.T) {
+	gen := ngmodels.RuleGen.With(ngmodels.RuleGen.WithOrgID(1))
+	item := func(uid string, dependsOn ...string) readyToRunItem {
+		rule := gen.With(gen.WithDependsOn(dependsOn...)).GenerateRef()
+		rule.UID = uid
+		return readyToRunItem{Evaluation: Evaluation{rule: rule}}
+	}
+	uids := func(items []readyToRunItem) []string {
+		result := make([]string, 0, len(items))
+		for _, i := range items {
+			result = append(result, i.rule.UID)
+		}
+		return result
+	}
+
+	t.Run("rules without dependencies are sorted by UID", func(t *testing.T) {
+		sorted := sortByDependencies([]readyToRunItem{item("c"), item("a"), item("b")})
+		require.Equal(t, []string{"a", "b", "c"}, uids(sorted))
+		for _, i := range sorted {
+			require.Empty(t, i.dependencies)
+		}
+	})
+
+	t.Run("rules are evaluated after their dependencies", func(t *testing.T) {
+		sorted := sortByDependencies([]readyToRunItem{item("a", "c"), item("b"), item("c", "d"), item("d")})
+		require.Equal(t, []string{"b", "d", "c", "a"}, uids(sorted))
+		require.Equal(t, []ngmodels.AlertRuleKey{{OrgID: 1, UID: "d"}}, sorted[2].dependencies)
+		require.Equal(t, []ngmodels.AlertRuleKey{{OrgID: 1, UID: "c"}}, sorted[3].dependencies)
+	})
+
+	t.Run("dependencies that are not scheduled in the tick are not awaited", func(t *testing.T) {
+		sorted := sortByDependencies([]readyToRunItem{item("a", "other")})
+		require.Empty(t, sorted[0].dependencies)
+	})
+
+	t.Run("rules in a cycle are placed last and do not wait", func(t *testing.T) {
+		sorted := sortByDependencies([]readyToRunItem{item("a", "b"), item("b", "a"), item("c")})
+		require.Equal(t, []string{"c", "a", "b"}, uids(sorted))
+		require.Empty(t, sorted[1].dependencies)
+		require.Empty(t, sorted[2].dependencies)
+	})
+}
+
+func TestDependencyTracker(t *testing.T) {
+	gen := ngmodels.RuleGen.With(ngmodels.RuleGen.WithOrgID(1))
+	upstream := gen.GenerateRef()
+	dependent := gen.With(gen.WithDependsOn(upstream.UID)).GenerateRef()
+	tick := time.Unix(100, 0)
+
+	t.Run("dependencies that were not evaluated are healthy", func(t *testing.T) {
+		tracker := newDependencyTracker(time.Second)
+		require.NoError(t, tracker.check(context.Background(), &Evaluation{scheduledAt: tick, rule: dependent}))
+	})
+
+	t.Run("failed dependencies fail the check", func(t *testing.T) {
+		tracker := newDependencyTracker(time.Second)
+		tracker.record(&Evaluation{scheduledAt: tick, rule: upstream}, errors.New("query failed"))
+		err := tracker.check(context.Background(), &Evaluation{scheduledAt: tick, rule: dependent})
+		require.ErrorIs(t, err, eval.ErrDependencyFailed)
+		require.ErrorContains(t, err, "query failed")
+
+		tracker.record(&Evaluation{scheduledAt: tick.Add(time.Minute), rule: upstream}, nil)
+		require.NoError(t, tracker.check(context.Background(), &Evaluation{scheduledAt: tick.Add(time.Minute), rule: dependent}))
+
+		tracker.record(&Evaluation{scheduledAt: tick, rule: upstream}, errors.New("query failed"))
+		tracker.forget(upstream.GetKey())
+		require.NoError(t, tracker.check(context.Background(), &Evaluation{scheduledAt: tick, rule: dependent}))
+	})
+
+	t.Run("waits for dependencies scheduled in the same tick", func(t *testing.T) {
+		tracker := newDependencyTracker(time.Minute)
+		tracker.record(&Evaluation{scheduledAt: tick, rule: upstream}, nil)
+
+		result := make(chan error)
+		go func() {
+			result <- tracker.check(context.Background(), &Evaluation{
+				scheduledAt:  tick.Add(time.Minute),
+				rule:         dependent,
+				dependencies: []ngmodels.AlertRuleKey{upstream.GetKey()},
+			})
+		}()
+
+		select {
+		case <-result:
+			require.Fail(t, "check should wait for the evaluation of the dependency in the same tick")
+		case <-time.After(50 * time.Millisecond):
+		}
+		tracker.record(&Evaluation{scheduledAt: tick.Add(time.Minute), rule: upstream}, errors.New("query failed"))
+		require.ErrorIs(t, <-result, eval.ErrDependencyFailed)
+	})
+
+	t.Run("stops waiting after the timeout", func(t *testing.T) {
+		tracker := newDependencyTracker(10 * time.Millisecond)
+		err := tracker.check(context.Background(), &Evaluation{
+			scheduledAt:  tick,
+			rule:         dependent,
+			dependencies: []ngmodels.AlertRuleKey{upstream.GetKey()},
+		})
+		require.NoError(t, err)
+	})
+}
@@ -42,10 +42,11 @@ type recordingRule struct {
 	metrics *metrics.Scheduler
 	tracer  tracing.Tracer
 
-	writer RecordingWriter
+	writer       RecordingWriter
+	dependencies *dependencyTracker
 }
 
-func newRecordingRule(parent context.Context, key ngmodels.AlertRuleKey, maxAttempts int64, clock clock.Clock, evalFactory eval.EvaluatorFactory, ft featuremgmt.FeatureToggles, logger log.Logger, metrics *metrics.Scheduler, tracer tracing.Tracer, writer RecordingWriter) *recordingRule {
+func newRecordingRule(parent context.Context, key ngmodels.AlertRuleKey, maxAttempts int64, clock clock.Clock, evalFactory eval.EvaluatorFactory, ft featuremgmt.FeatureToggles, logger log.Logger, metrics *metrics.Scheduler, tracer tracing.Tracer, writer RecordingWriter, dependencies *dependencyTracker) *recordingRule {
 	ctx, stop := util.WithCancelCause(ngmodels.WithRuleKey(parent, key))
 	return &recordingRule{
 		key:            key,
@@ -60,6 +61,7 @@ func newRecordingRule(parent context.Context, key ngmodels.AlertRuleKey, maxAtte
 		metrics:        metrics,
 		tracer:         tracer,
 		writer:         writer,
+		dependencies:   dependencies,
 	}
 }
 
@@ -133,6 +135,7 @@ func (r *recordingRule) doEvaluate(ctx context.Context, ev *Evaluation) {
 
 	if ev.rule.IsPaused {
 		logger.Debug("Skip recording rule evaluation because it is paused")
+		r.dependencies.record(ev, nil)
 		return
 	}
 
@@ -145,6 +148,14 @@ func (r *recordingRule) doEvaluate(ctx context.Context, ev *Evaluation) {
 	))
 	defer span.End()
 
+	if err := r.dependencies.check(ctx, ev); err != nil {
+		logger.Warn("Skip recording rule evaluation because a rule it depends on failed", "error", err)
+		span.SetStatus(codes.Error, "dependency failed")
+		span.RecordError(err)
+		r.dependencies.record(ev, err)
+		return
+	}
+
 	var latestError error
 	for attempt := int64(1); attempt <= r.maxAttempts; attempt++ {
 		logger := logger.New("attempt", attempt)
@@ -179,6 +190,7 @@ func (r *recordingRule) doEvaluate(ctx context.Context, ev *Evaluation) {
 		}
 	}
 
+	r.dependencies.record(ev, latestError)
 	if latestError != nil {
 		evalTotalFailures.Inc()
 		span.SetStatus(codes.Error, "rule evaluation failed")
@@ -15,7 +15,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
-var errRuleDeleted = errors.New("rule deleted")
+var (
+	errRuleDeleted    = errors.New("rule deleted")
+	errRuleHandedOver = errors.New("rule handed over to another instance")
+)
 
 type ruleFactory interface {
 	new(context.Context, *models.AlertRule) Rule
@@ -85,6 +88,8 @@ type Evaluation struct {
 	scheduledAt time.Time
 	rule        *models.AlertRule
 	folderTitle string
+	// dependencies are the rules that the rule depends on, which are scheduled in the same tick and evaluated first.
+	dependencies []models.AlertRuleKey
 }
 
 func (e *Evaluation) Fingerprint() fingerprint {
@@ -306,6 +311,10 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 	writeInt(rule.ID)
 	writeInt(rule.OrgID)
 	writeInt(int64(rule.For))
+	writeInt(int64(rule.KeepFiringFor))
+	for _, uid := range rule.DependsOn {
+		writeString(uid)
+	}
 	if rule.DashboardUID != nil {
 		writeString(*rule.DashboardUID)
 	}
@@ -320,6 +329,18 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 		binary.LittleEndian.PutUint64(tmp, uint64(rule.Record.Fingerprint()))
 		writeBytes(tmp)
 	}
+	if rule.Template != nil {
+		writeString(rule.Template.UID)
+		params := make([]string, 0, len(rule.Template.Parameters))
+		for name := range rule.Template.Parameters {
+			params = append(params, name)
+		}
+		sort.Strings(params)
+		for _, name := range params {
+			writeString(name)
+			writeString(rule.Template.Parameters[name])
+		}
+	}
 
 	return fingerprint(sum.Sum64())
 }
@@ -194,6 +194,7 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			ExecErrState:    "test-err",
 			Record:          &models.Record{Metric: "my_metric", From: "A"},
 			For:             12,
+			KeepFiringFor:   13,
 			Annotations: map[string]string{
 				"key-annotation": "value-annotation",
 			},
@@ -204,6 +205,8 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			NotificationSettings: []models.NotificationSettings{
 				models.NotificationSettingsGen()(),
 			},
+			DependsOn: []string{"test-dependency"},
+			Template:  &models.AlertRuleTemplateRef{UID: "template-1", Parameters: map[string]string{"threshold": "1"}},
 		}
 		r2 := &models.AlertRule{
 			ID:        2,
@@ -233,6 +236,7 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			ExecErrState:    "test-err2",
 			Record:          &models.Record{Metric: "my_metric2", From: "B"},
 			For:             1141,
+			KeepFiringFor:   1142,
 			Annotations: map[string]string{
 				"key-annotation2": "value-annotation",
 			},
@@ -243,6 +247,8 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			NotificationSettings: []models.NotificationSettings{
 				models.NotificationSettingsGen()(),
 			},
+			DependsOn: []string{"test-dependency2"},
+			Template:  &models.AlertRuleTemplateRef{UID: "template-2", Parameters: map[string]string{"threshold": "2"}},
 		}
 
 		excludedFields := map[string]struct{}{
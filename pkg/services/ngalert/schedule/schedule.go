@@ -4,11 +4,10 @@ import (
 	"context"
 	"fmt"
 	"net/url"
-	"slices"
-	"strings"
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/prometheus/alertmanager/api/v2/models"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -100,6 +99,12 @@ type schedule struct {
 	tracer tracing.Tracer
 
 	recordingWriter RecordingWriter
+
+	// dependencies keeps the outcome of the latest evaluation of the rules, for the rules that depend on them.
+	dependencies *dependencyTracker
+
+	// sharding assigns the rules to the members of the high availability cluster. It is nil if every member evaluates all rules.
+	sharding *ruleSharding
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -119,6 +124,8 @@ type SchedulerCfg struct {
 	Tracer               tracing.Tracer
 	Log                  log.Logger
 	RecordingWriter      RecordingWriter
+	// ClusterMembership enables the distribution of the evaluation of rules across the members of the cluster.
+	ClusterMembership ClusterMembership
 }
 
 // NewScheduler returns a new scheduler.
@@ -148,6 +155,8 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		alertsSender:          cfg.AlertSender,
 		tracer:                cfg.Tracer,
 		recordingWriter:       cfg.RecordingWriter,
+		dependencies:          newDependencyTracker(cfg.BaseInterval),
+		sharding:              newRuleSharding(cfg.ClusterMembership, cfg.Log),
 	}
 
 	return &sch
@@ -180,9 +189,15 @@ func (sch *schedule) deleteAlertRule(keys ...ngmodels.AlertRuleKey) {
 		if _, ok := sch.schedulableAlertRules.del(key); !ok {
 			sch.log.Info("Alert rule cannot be removed from the scheduler as it is not scheduled", key.LogContext()...)
 		}
+		sch.dependencies.forget(key)
 		// Delete the rule routine
 		ruleRoutine, ok := sch.registry.del(key)
 		if !ok {
+			if sch.sharding != nil {
+				// the rule can be evaluated by another member of the cluster, which deletes its saved state.
+				states := sch.stateManager.ForgetStateByRuleUID(key, ngmodels.StateReasonRuleDeleted)
+				sch.expireAndSend(context.Background(), key, states)
+			}
 			sch.log.Info("Alert rule cannot be stopped as it is not running", key.LogContext()...)
 			continue
 		}
@@ -194,6 +209,50 @@ func (sch *schedule) deleteAlertRule(keys ...ngmodels.AlertRuleKey) {
 	sch.updateRulesMetrics(alertRules)
 }
 
+// handOverAlertRules stops evaluation of the rules that are now evaluated by other members of the cluster.
+// Unlike for deleted rules, the state is kept in the database for the new owner, and in the cache, where it is
+// synchronized with the state that the new owner saves, see syncRuleStates.
+func (sch *schedule) handOverAlertRules(keys map[ngmodels.AlertRuleKey]struct{}) {
+	for key := range keys {
+		sch.dependencies.forget(key)
+		if ruleRoutine, ok := sch.registry.del(key); ok {
+			ruleRoutine.Stop(errRuleHandedOver)
+		}
+	}
+	if len(keys) > 0 {
+		sch.log.Info("Alert rules were handed over to other members of the cluster", "count", len(keys))
+	}
+}
+
+// syncRuleStates loads the states of rules evaluated by other members of the cluster, which they saved in the database,
+// and sends the alerts that need sending to the Alertmanager of this instance. The Alertmanagers of the cluster do not
+// share alerts, so otherwise the alerts of a rule that is handed over would expire in the Alertmanager of the previous
+// owner, which would send a resolved notification while the new owner still sends them as firing. The alerts are
+// resolved when the member that evaluates the rule resolves them, and the states are shown by the API of every member.
+func (sch *schedule) syncRuleStates(ctx context.Context, rules ...*ngmodels.AlertRule) {
+	rulesByOrg := make(map[int64][]*ngmodels.AlertRule)
+	for _, rule := range rules {
+		rulesByOrg[rule.OrgID] = append(rulesByOrg[rule.OrgID], rule)
+	}
+	for orgID, orgRules := range rulesByOrg {
+		for key, states := range sch.stateManager.SyncRuleStates(ctx, orgID, orgRules) {
+			alerts := definitions.PostableAlerts{PostableAlerts: make([]models.PostableAlert, 0, len(states))}
+			for _, s := range states {
+				alerts.PostableAlerts = append(alerts.PostableAlerts, *state.StateToPostableAlert(s, sch.appURL))
+			}
+			sch.alertsSender.Send(ctx, key, alerts)
+		}
+	}
+}
+
+// expireAndSend sends alerts to expire the previously firing alerts in the state transitions.
+func (sch *schedule) expireAndSend(ctx context.Context, key ngmodels.AlertRuleKey, states []state.StateTransition) {
+	expiredAlerts := state.FromAlertsStateToStoppedAlert(states, sch.appURL, sch.clock)
+	if len(expiredAlerts.PostableAlerts) > 0 {
+		sch.alertsSender.Send(ctx, key, expiredAlerts)
+	}
+}
+
 func (sch *schedule) schedulePeriodic(ctx context.Context, t *ticker.T) error {
 	dispatcherGroup, ctx := errgroup.WithContext(ctx)
 	for {
@@ -248,6 +307,9 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 
 	sch.updateRulesMetrics(alertRules)
 
+	assignment := sch.sharding.assign(alertRules)
+	sch.handOverAlertRules(assignment.handedOver)
+
 	readyToRun := make([]readyToRunItem, 0)
 	updatedRules := make([]ngmodels.AlertRuleKeyWithVersion, 0, len(updated)) // this is needed for tests only
 	missingFolder := make(map[string][]string)
@@ -265,12 +327,29 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		sch.log,
 		sch.tracer,
 		sch.recordingWriter,
+		sch.dependencies,
 		sch.evalAppliedFunc,
 		sch.stopAppliedFunc,
 	)
+	var notOwnedToSync []*ngmodels.AlertRule
 	for _, item := range alertRules {
-		ruleRoutine, newRoutine := sch.registry.getOrCreate(ctx, item, ruleFactory)
 		key := item.GetKey()
+		if !assignment.owns(key) {
+			// the rule is evaluated by another member of the cluster, its state is synchronized at the interval of the rule.
+			_, handedOver := assignment.handedOver[key]
+			interval := max(item.IntervalSeconds, int64(sch.minRuleInterval.Seconds()))
+			if frequency := interval / int64(sch.baseInterval.Seconds()); handedOver || (frequency > 0 && tickNum%frequency == 0) {
+				notOwnedToSync = append(notOwnedToSync, item)
+			}
+			delete(registeredDefinitions, key)
+			continue
+		}
+		if _, ok := assignment.takenOver[key]; ok {
+			// continue from the state that the previous owner saved
+			sch.syncRuleStates(ctx, item)
+		}
+
+		ruleRoutine, newRoutine := sch.registry.getOrCreate(ctx, item, ruleFactory)
 		logger := sch.log.FromContext(ctx).New(key.LogContext()...)
 
 		// enforce minimum evaluation interval
@@ -335,6 +414,8 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		delete(registeredDefinitions, key)
 	}
 
+	sch.syncRuleStates(ctx, notOwnedToSync...)
+
 	if len(missingFolder) > 0 { // if this happens then there can be problems with fetching folders from the database.
 		sch.log.Warn("Unable to obtain folder titles for some rules", "missingFolderUIDToRuleUID", missingFolder)
 	}
@@ -344,9 +425,7 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		step = sch.baseInterval.Nanoseconds() / int64(len(readyToRun))
 	}
 
-	slices.SortFunc(readyToRun, func(a, b readyToRunItem) int {
-		return strings.Compare(a.rule.UID, b.rule.UID)
-	})
+	readyToRun = sortByDependencies(readyToRun)
 	for i := range readyToRun {
 		item := readyToRun[i]
 
@@ -0,0 +1,166 @@
+package schedule
+
+import (
+	"hash/fnv"
+	"slices"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ClusterMembership provides the members of the high availability cluster that share the evaluation of alert rules.
+type ClusterMembership interface {
+	// ClusterMembers returns the name of this instance and the names of the live members of the cluster,
+	// including this instance.
+	ClusterMembers() (string, []string)
+}
+
+// ruleSharding distributes the evaluation of alert rules across the live members of the cluster using rendezvous
+// hashing, so that only the rules of the rule groups that change owner move when members join or leave the cluster.
+// All rules of a group are evaluated by the same member, as well as the groups whose rules depend on each other.
+type ruleSharding struct {
+	membership ClusterMembership
+	log        log.Logger
+
+	// members are the members of the cluster at the latest assignment.
+	members []string
+	// notOwned are the rules that were assigned to other members at the latest assignment.
+	notOwned map[ngmodels.AlertRuleKey]struct{}
+}
+
+func newRuleSharding(membership ClusterMembership, logger log.Logger) *ruleSharding {
+	if membership == nil {
+		return nil
+	}
+	return &ruleSharding{
+		membership: membership,
+		log:        logger,
+		notOwned:   make(map[ngmodels.AlertRuleKey]struct{}),
+	}
+}
+
+// shardAssignment is the result of assigning the rules to the members of the cluster.
+type shardAssignment struct {
+	// notOwned are the rules that are evaluated by other members.
+	notOwned map[ngmodels.AlertRuleKey]struct{}
+	// handedOver are the rules that were evaluated by this instance and are now evaluated by other members.
+	handedOver map[ngmodels.AlertRuleKey]struct{}
+	// takenOver are the rules that were evaluated by other members and are now evaluated by this instance.
+	takenOver map[ngmodels.AlertRuleKey]struct{}
+}
+
+// owns returns true if the rule is evaluated by this instance.
+func (a shardAssignment) owns(key ngmodels.AlertRuleKey) bool {
+	_, ok := a.notOwned[key]
+	return !ok
+}
+
+// assign assigns the rules to the current members of the cluster and returns the changes since the previous assignment.
+// This instance evaluates all rules if it is not a member of the cluster, e.g. when the cluster is not ready yet,
+// because evaluating a rule twice is better than not evaluating it at all.
+func (s *ruleSharding) assign(rules []*ngmodels.AlertRule) shardAssignment {
+	if s == nil {
+		return shardAssignment{}
+	}
+	self, members := s.membership.ClusterMembers()
+	members = slices.Clone(members)
+	slices.Sort(members)
+	members = slices.Compact(members)
+	if !slices.Equal(members, s.members) {
+		s.log.Info("Cluster members changed, rebalancing the evaluation of alert rules", "self", self, "members", members)
+		s.members = members
+	}
+
+	result := shardAssignment{
+		notOwned:   make(map[ngmodels.AlertRuleKey]struct{}),
+		handedOver: make(map[ngmodels.AlertRuleKey]struct{}),
+		takenOver:  make(map[ngmodels.AlertRuleKey]struct{}),
+	}
+	if len(members) > 1 && slices.Contains(members, self) {
+		shards := shardKeys(rules)
+		for _, rule := range rules {
+			key := rule.GetKey()
+			if ownerOf(shards[rule.GetGroupKey()], members) != self {
+				result.notOwned[key] = struct{}{}
+			}
+		}
+	}
+
+	for key := range result.notOwned {
+		if _, ok := s.notOwned[key]; !ok {
+			result.handedOver[key] = struct{}{}
+		}
+	}
+	for _, rule := range rules {
+		key := rule.GetKey()
+		if _, ok := s.notOwned[key]; ok && result.owns(key) {
+			result.takenOver[key] = struct{}{}
+		}
+	}
+	s.notOwned = result.notOwned
+	return result
+}
+
+// shardKeys returns the key by which every rule group is assigned to a member of the cluster.
+// Groups whose rules depend on rules of other groups share the same key.
+func shardKeys(rules []*ngmodels.AlertRule) map[ngmodels.AlertRuleGroupKey]string {
+	parent := make(map[ngmodels.AlertRuleGroupKey]ngmodels.AlertRuleGroupKey)
+	var find func(k ngmodels.AlertRuleGroupKey) ngmodels.AlertRuleGroupKey
+	find = func(k ngmodels.AlertRuleGroupKey) ngmodels.AlertRuleGroupKey {
+		p, ok := parent[k]
+		if !ok || p == k {
+			return k
+		}
+		root := find(p)
+		parent[k] = root
+		return root
+	}
+
+	groupByRule := make(map[ngmodels.AlertRuleKey]ngmodels.AlertRuleGroupKey, len(rules))
+	for _, rule := range rules {
+		groupByRule[rule.GetKey()] = rule.GetGroupKey()
+	}
+	for _, rule := range rules {
+		for _, uid := range rule.DependsOn {
+			dep, ok := groupByRule[ngmodels.AlertRuleKey{OrgID: rule.OrgID, UID: uid}]
+			if !ok {
+				continue
+			}
+			a, b := find(rule.GetGroupKey()), find(dep)
+			if a != b {
+				parent[a] = b
+			}
+		}
+	}
+
+	// The key of the groups that are joined together is the smallest key of the groups, so that it does not depend
+	// on the order of the rules and every member computes the same one.
+	keyByRoot := make(map[ngmodels.AlertRuleGroupKey]string)
+	for _, group := range groupByRule {
+		root := find(group)
+		if k, ok := keyByRoot[root]; !ok || group.String() < k {
+			keyByRoot[root] = group.String()
+		}
+	}
+	result := make(map[ngmodels.AlertRuleGroupKey]string, len(keyByRoot))
+	for _, group := range groupByRule {
+		result[group] = keyByRoot[find(group)]
+	}
+	return result
+}
+
+// ownerOf returns the member that has the highest score for the key.
+func ownerOf(key string, members []string) string {
+	var owner string
+	var maxScore uint64
+	for _, member := range members {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(member))
+		_, _ = h.Write([]byte{0xff})
+		_, _ = h.Write([]byte(key))
+		if score := h.Sum64(); owner == "" || score > maxScore {
+			owner, maxScore = member, score
+		}
+	}
+	return owner
+}
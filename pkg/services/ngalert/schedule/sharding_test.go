@@ -0,0 +1,313 @@
+package schedule
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+type fakeClusterMembership struct {
+	self    string
+	members []string
+}
+
+func (f *fakeClusterMembership) ClusterMembers() (string, []string) {
+	return f.self, f.members
+}
+
+func TestShardKeys(t *testing.T) {
+	gen := ngmodels.RuleGen.With(ngmodels.RuleGen.WithOrgID(1))
+	group := func(name string) ngmodels.AlertRuleGroupKey {
+		return ngmodels.AlertRuleGroupKey{OrgID: 1, NamespaceUID: "folder", RuleGroup: name}
+	}
+	rule := func(uid, groupName string, dependsOn ...string) *ngmodels.AlertRule {
+		r := gen.With(gen.WithGroupKey(group(groupName)), gen.WithDependsOn(dependsOn...)).GenerateRef()
+		r.UID = uid
+		return r
+	}
+	rules := []*ngmodels.AlertRule{
+		rule("a", "g1"),
+		rule("b", "g2", "a"),
+		rule("c", "g3", "b"),
+		rule("d", "g4"),
+		rule("e", "g4", "missing"),
+	}
+
+	keys := shardKeys(rules)
+
+	require.Equal(t, map[ngmodels.AlertRuleGroupKey]string{
+		group("g1"): group("g1").String(),
+		group("g2"): group("g1").String(),
+		group("g3"): group("g1").String(),
+		group("g4"): group("g4").String(),
+	}, keys)
+
+	t.Run("keys do not depend on the order of rules", func(t *testing.T) {
+		reversed := []*ngmodels.AlertRule{rules[4], rules[3], rules[2], rules[1], rules[0]}
+		require.Equal(t, keys, shardKeys(reversed))
+	})
+}
+
+func TestRuleShardingAssign(t *testing.T) {
+	gen := ngmodels.RuleGen.With(ngmodels.RuleGen.WithOrgID(1))
+	rules := gen.GenerateManyRef(50)
+	members := []string{"a", "b", "c"}
+	newSharding := func(self string, members ...string) (*ruleSharding, *fakeClusterMembership) {
+		m := &fakeClusterMembership{self: self, members: members}
+		return newRuleSharding(m, log.NewNopLogger()), m
+	}
+
+	t.Run("every rule is evaluated by exactly one member", func(t *testing.T) {
+		owners := make(map[ngmodels.AlertRuleKey]int)
+		for _, self := range members {
+			s, _ := newSharding(self, members...)
+			assignment := s.assign(rules)
+			for _, r := range rules {
+				if assignment.owns(r.GetKey()) {
+					owners[r.GetKey()]++
+				}
+			}
+		}
+		require.Len(t, owners, len(rules))
+		for key, count := range owners {
+			require.Equalf(t, 1, count, "rule %s", key)
+		}
+	})
+
+	t.Run("all rules are evaluated when sharding is disabled", func(t *testing.T) {
+		var s *ruleSharding
+		assignment := s.assign(rules)
+		for _, r := range rules {
+			require.True(t, assignment.owns(r.GetKey()))
+		}
+	})
+
+	t.Run("all rules are evaluated when the instance is not a member of the cluster", func(t *testing.T) {
+		s, _ := newSharding("d", members...)
+		require.Empty(t, s.assign(rules).notOwned)
+	})
+
+	t.Run("rules are rebalanced when members change", func(t *testing.T) {
+		s, m := newSharding("a", "a")
+		first := s.assign(rules)
+		require.Empty(t, first.notOwned)
+
+		m.members = members
+		second := s.assign(rules)
+		require.NotEmpty(t, second.notOwned)
+		require.Equal(t, second.notOwned, second.handedOver)
+		require.Empty(t, second.takenOver)
+
+		third := s.assign(rules)
+		require.Equal(t, second.notOwned, third.notOwned)
+		require.Empty(t, third.handedOver)
+		require.Empty(t, third.takenOver)
+
+		m.members = []string{"a", "b"}
+		fourth := s.assign(rules)
+		require.Empty(t, fourth.handedOver)
+		for key := range fourth.takenOver {
+			require.Contains(t, third.notOwned, key)
+			require.True(t, fourth.owns(key))
+		}
+		// only the rules of the member that left move
+		for key := range fourth.notOwned {
+			require.Contains(t, third.notOwned, key)
+		}
+		require.Len(t, fourth.takenOver, len(third.notOwned)-len(fourth.notOwned))
+	})
+}
+
+func TestProcessTickWithSharding(t *testing.T) {
+	ctx := context.Background()
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+	ruleStore := newFakeRulesStore()
+	instanceStore := &state.FakeInstanceStore{}
+	sch := setupScheduler(t, ruleStore, instanceStore, nil, nil, nil)
+
+	rule := ngmodels.RuleGen.With(ngmodels.RuleGen.WithInterval(time.Second)).GenerateRef()
+	ruleStore.PutRule(ctx, rule)
+	members := []string{"a", "b"}
+	owner := ownerOf(shardKeys([]*ngmodels.AlertRule{rule})[rule.GetGroupKey()], members)
+	other := "a"
+	if owner == other {
+		other = "b"
+	}
+	membership := &fakeClusterMembership{self: other, members: members}
+	sch.sharding = newRuleSharding(membership, log.NewNopLogger())
+
+	tick := time.Time{}
+	nextTick := func() ([]readyToRunItem, map[ngmodels.AlertRuleKey]struct{}) {
+		tick = tick.Add(time.Second)
+		scheduled, stopped, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		return scheduled, stopped
+	}
+
+	t.Run("rules of other members are not evaluated", func(t *testing.T) {
+		scheduled, stopped := nextTick()
+		require.Empty(t, scheduled)
+		require.Empty(t, stopped)
+		require.False(t, sch.registry.exists(rule.GetKey()))
+		// the rule is still known by the scheduler
+		rules, _ := sch.schedulableAlertRules.all()
+		require.Len(t, rules, 1)
+	})
+
+	t.Run("rule is taken over with its saved state", func(t *testing.T) {
+		membership.self = owner
+
+		scheduled, stopped := nextTick()
+		require.Len(t, scheduled, 1)
+		require.Empty(t, stopped)
+		require.True(t, sch.registry.exists(rule.GetKey()))
+		require.Contains(t, instanceStore.RecordedOps(), ngmodels.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID})
+	})
+
+	t.Run("rule is handed over without deleting its state", func(t *testing.T) {
+		ruleRoutine, _ := sch.registry.getOrCreate(ctx, rule, nil)
+		membership.self = other
+
+		scheduled, stopped := nextTick()
+		require.Empty(t, scheduled)
+		require.Empty(t, stopped)
+		require.False(t, sch.registry.exists(rule.GetKey()))
+		require.ErrorIs(t, ruleRoutine.(*alertRule).ctx.Err(), errRuleHandedOver)
+		for _, op := range instanceStore.RecordedOps() {
+			if o, ok := op.(state.FakeInstanceStoreOp); ok {
+				require.NotEqual(t, "DeleteAlertInstancesByRule", o.Name)
+			}
+		}
+	})
+}
+
+// savedInstanceStore is a FakeInstanceStore that lists the given instances, like the ones saved by another member.
+type savedInstanceStore struct {
+	*state.FakeInstanceStore
+	mtx       sync.Mutex
+	instances []*ngmodels.AlertInstance
+}
+
+func (f *savedInstanceStore) ListAlertInstances(ctx context.Context, q *ngmodels.ListAlertInstancesQuery) ([]*ngmodels.AlertInstance, error) {
+	_, _ = f.FakeInstanceStore.ListAlertInstances(ctx, q)
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.instances, nil
+}
+
+func (f *savedInstanceStore) save(instances ...*ngmodels.AlertInstance) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.instances = instances
+}
+
+func TestProcessTickWithShardingFiringAlerts(t *testing.T) {
+	ctx := context.Background()
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+	ruleStore := newFakeRulesStore()
+	instanceStore := &savedInstanceStore{FakeInstanceStore: &state.FakeInstanceStore{}}
+	sch := setupScheduler(t, ruleStore, instanceStore, nil, nil, nil)
+	sender := sch.alertsSender.(*SyncAlertsSenderMock)
+
+	// the rule is not evaluated at the ticks of the test, until it is due.
+	rule := ngmodels.RuleGen.With(ngmodels.RuleGen.WithInterval(time.Hour)).GenerateRef()
+	ruleStore.PutRule(ctx, rule)
+	members := []string{"a", "b"}
+	owner := ownerOf(shardKeys([]*ngmodels.AlertRule{rule})[rule.GetGroupKey()], members)
+	other := "a"
+	if owner == other {
+		other = "b"
+	}
+	membership := &fakeClusterMembership{self: owner, members: members}
+	sch.sharding = newRuleSharding(membership, log.NewNopLogger())
+
+	tick := time.Unix(1, 0)
+	firing := &ngmodels.AlertInstance{
+		AlertInstanceKey:  ngmodels.AlertInstanceKey{RuleOrgID: rule.OrgID, RuleUID: rule.UID, LabelsHash: "hash"},
+		Labels:            ngmodels.InstanceLabels{"instance": "a"},
+		CurrentState:      ngmodels.InstanceStateFiring,
+		CurrentStateSince: tick.Add(-time.Hour),
+		CurrentStateEnd:   tick.Add(4 * time.Hour),
+		LastEvalTime:      tick,
+	}
+	instanceStore.save(firing)
+
+	sent := func() []definitions.PostableAlerts {
+		var result []definitions.PostableAlerts
+		for _, call := range sender.Calls() {
+			result = append(result, call.Arguments[2].(definitions.PostableAlerts))
+		}
+		return result
+	}
+	requireFiring := func(t *testing.T) {
+		t.Helper()
+		states := sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		require.Len(t, states, 1)
+		require.Equal(t, "Alerting", states[0].State.String())
+		for _, alerts := range sent() {
+			for _, alert := range alerts.PostableAlerts {
+				require.Truef(t, time.Time(alert.EndsAt).After(tick), "alert resolved at %s", alert.EndsAt)
+			}
+		}
+	}
+
+	_, _, _ = sch.processTick(ctx, dispatcherGroup, tick)
+	require.True(t, sch.registry.exists(rule.GetKey()))
+	sch.stateManager.SyncRuleStates(ctx, rule.OrgID, []*ngmodels.AlertRule{rule})
+
+	t.Run("firing alerts are kept when the rule is handed over", func(t *testing.T) {
+		membership.self = other
+		tick = tick.Add(time.Second)
+		_, _, _ = sch.processTick(ctx, dispatcherGroup, tick)
+		require.False(t, sch.registry.exists(rule.GetKey()))
+		requireFiring(t)
+	})
+
+	t.Run("firing alerts are sent to the Alertmanager of every member", func(t *testing.T) {
+		// the new owner evaluates the rule and saves its state
+		tick = time.Unix(3600, 0)
+		firing.LastEvalTime = tick
+		firing.CurrentStateEnd = tick.Add(4 * time.Hour)
+		instanceStore.save(firing)
+
+		_, _, _ = sch.processTick(ctx, dispatcherGroup, tick)
+		requireFiring(t)
+		require.NotEmpty(t, sent())
+	})
+
+	t.Run("firing alerts are kept when the rule is taken over", func(t *testing.T) {
+		membership.self = owner
+		tick = tick.Add(time.Second)
+		_, _, _ = sch.processTick(ctx, dispatcherGroup, tick)
+		require.True(t, sch.registry.exists(rule.GetKey()))
+		requireFiring(t)
+
+		membership.self = other
+		tick = tick.Add(time.Second)
+		_, _, _ = sch.processTick(ctx, dispatcherGroup, tick)
+		requireFiring(t)
+	})
+
+	t.Run("alerts are resolved when the owner resolves them", func(t *testing.T) {
+		tick = time.Unix(7200, 0)
+		sch.clock.(*clock.Mock).Set(tick)
+		instanceStore.save()
+		_, _, _ = sch.processTick(ctx, dispatcherGroup, tick)
+
+		require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+		calls := sent()
+		last := calls[len(calls)-1]
+		require.Len(t, last.PostableAlerts, 1)
+		require.Equal(t, strfmt.DateTime(tick), last.PostableAlerts[0].EndsAt)
+	})
+}
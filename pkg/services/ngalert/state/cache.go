@@ -268,6 +268,15 @@ func (c *cache) setAllStates(newStates map[int64]map[string]*ruleStates) {
 	c.states = newStates
 }
 
+func (c *cache) setRuleStates(orgID int64, uid string, states *ruleStates) {
+	c.mtxStates.Lock()
+	defer c.mtxStates.Unlock()
+	if _, ok := c.states[orgID]; !ok {
+		c.states[orgID] = make(map[string]*ruleStates)
+	}
+	c.states[orgID][uid] = states
+}
+
 func (c *cache) set(entry *State) {
 	c.mtxStates.Lock()
 	defer c.mtxStates.Unlock()
@@ -377,6 +386,7 @@ func (c *cache) asInstances(skipNormalState bool) []ngModels.AlertInstance {
 					CurrentStateSince: v2.StartsAt,
 					CurrentStateEnd:   v2.EndsAt,
 					ResultFingerprint: v2.ResultFingerprint.String(),
+					KeepFiringSince:   v2.KeepFiringSince,
 				})
 			}
 		}
@@ -128,6 +128,15 @@ func TestShouldRecordAnnotation(t *testing.T) {
 		require.True(t, ShouldRecordAnnotation(missingSeriesBackward), "Normal(MissingSeries) -> Normal(NoData) should be true")
 	})
 
+	t.Run("transitions into and out of KeepFiring are recorded", func(t *testing.T) {
+		keepFiring := models.ConcatReasons(eval.Normal.String(), models.StateReasonKeepFiring)
+		forward := transition(eval.Alerting, "", eval.Alerting, keepFiring)
+		backward := transition(eval.Alerting, keepFiring, eval.Normal, "")
+
+		require.True(t, ShouldRecordAnnotation(forward), "Alerting -> Alerting(KeepFiring) should be true")
+		require.True(t, ShouldRecordAnnotation(backward), "Alerting(KeepFiring) -> Normal should be true")
+	})
+
 	t.Run("respects filters in shouldRecord()", func(t *testing.T) {
 		missingSeries := transition(eval.Normal, "", eval.Normal, models.StateReasonMissingSeries)
 		unpause := transition(eval.Normal, models.StateReasonPaused, eval.Normal, "")
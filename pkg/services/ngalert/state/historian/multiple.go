@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"golang.org/x/sync/errgroup"
+
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
@@ -53,6 +55,24 @@ func (h *MultipleBackend) Query(ctx context.Context, query ngmodels.HistoryQuery
 	return h.primary.Query(ctx, query)
 }
 
+// backgroundBackend is a backend that has work to do in the background, like the retention of the SQL backend.
+type backgroundBackend interface {
+	Run(ctx context.Context) error
+}
+
+// Run runs the background work of all backends until the context is cancelled.
+func (h *MultipleBackend) Run(ctx context.Context) error {
+	g, ctx := errgroup.WithContext(ctx)
+	for _, b := range append([]Backend{h.primary}, h.secondaries...) {
+		if bb, ok := b.(backgroundBackend); ok {
+			g.Go(func() error {
+				return bb.Run(ctx)
+			})
+		}
+	}
+	return g.Wait()
+}
+
 // TODO: This is vendored verbatim from the Go standard library.
 // TODO: The grafana project doesn't support go 1.20 yet, so we can't use errors.Join() directly.
 // TODO: Remove this and replace calls with "errors.Join(...)" when go 1.20 becomes the minimum supported version.
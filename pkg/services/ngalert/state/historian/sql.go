@@ -0,0 +1,421 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	stateHistoryTable = "alert_state_history"
+	// sqlCleanupInterval is how often the entries that are older than the retention are deleted.
+	sqlCleanupInterval = 10 * time.Minute
+	// sqlCleanupBatchSize is the maximum number of entries that are deleted at once.
+	sqlCleanupBatchSize = 1000
+)
+
+// stateHistoryEntry is a row of the alert_state_history table. It has a column for each field of a LokiEntry,
+// so that the history can be returned in the same format as the Loki backend.
+type stateHistoryEntry struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	OrgID        int64  `xorm:"org_id"`
+	RuleUID      string `xorm:"rule_uid"`
+	NamespaceUID string `xorm:"namespace_uid"`
+	RuleGroup    string `xorm:"rule_group"`
+	DashboardUID string `xorm:"dashboard_uid"`
+	PanelID      int64  `xorm:"panel_id"`
+	Fingerprint  string `xorm:"fingerprint"`
+	// Labels are the instance labels encoded as JSON.
+	Labels        string `xorm:"labels"`
+	RuleID        int64  `xorm:"rule_id"`
+	RuleTitle     string `xorm:"rule_title"`
+	RuleCondition string `xorm:"rule_condition"`
+	PreviousState string `xorm:"previous_state"`
+	CurrentState  string `xorm:"current_state"`
+	StateError    string `xorm:"state_error"`
+	// StateValues are the values of the evaluation encoded as JSON.
+	StateValues string `xorm:"state_values"`
+	// Epoch is the time of the state transition in milliseconds.
+	Epoch int64 `xorm:"epoch"`
+}
+
+func (stateHistoryEntry) TableName() string {
+	return stateHistoryTable
+}
+
+type SQLConfig struct {
+	// MaxAge is how long entries are kept. Zero keeps them forever.
+	MaxAge          time.Duration
+	CleanupInterval time.Duration
+	BatchSize       int
+}
+
+func NewSQLConfig(cfg setting.UnifiedAlertingStateHistorySettings) (SQLConfig, error) {
+	if cfg.SQLMaxAge < 0 {
+		return SQLConfig{}, fmt.Errorf("max age of state history must not be negative")
+	}
+	return SQLConfig{
+		MaxAge:          cfg.SQLMaxAge,
+		CleanupInterval: sqlCleanupInterval,
+		BatchSize:       sqlCleanupBatchSize,
+	}, nil
+}
+
+// SQLBackend is a state.Historian that records state history to a dedicated table in the Grafana database.
+type SQLBackend struct {
+	db        db.DB
+	cfg       SQLConfig
+	clock     clock.Clock
+	metrics   *metrics.Historian
+	log       log.Logger
+	ac        AccessControl
+	ruleStore RuleStore
+}
+
+func NewSQLBackend(logger log.Logger, cfg SQLConfig, store db.DB, metrics *metrics.Historian, ruleStore RuleStore, ac AccessControl) *SQLBackend {
+	return &SQLBackend{
+		db:        store,
+		cfg:       cfg,
+		clock:     clock.New(),
+		metrics:   metrics,
+		log:       logger,
+		ac:        ac,
+		ruleStore: ruleStore,
+	}
+}
+
+// Record writes a number of state transitions for a given rule to the database in a single bulk insert.
+func (h *SQLBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	logger := h.log.FromContext(ctx)
+	entries := statesToEntries(rule, states, logger)
+
+	errCh := make(chan error, 1)
+	if len(entries) == 0 {
+		close(errCh)
+		return errCh
+	}
+
+	// This is a new background job, so let's create a brand new context for it.
+	// We want it to be isolated, i.e. we don't want grafana shutdowns to interrupt this work
+	// immediately but rather try to flush writes.
+	// This also prevents timeouts or other lingering objects (like transactions) from being
+	// incorrectly propagated here from other areas.
+	writeCtx := context.Background()
+	writeCtx, cancel := context.WithTimeout(writeCtx, StateHistoryWriteTimeout)
+	writeCtx = history_model.WithRuleData(writeCtx, rule)
+	writeCtx = trace.ContextWithSpan(writeCtx, trace.SpanFromContext(ctx))
+
+	go func(ctx context.Context) {
+		defer cancel()
+		defer close(errCh)
+		logger := h.log.FromContext(ctx)
+
+		org := fmt.Sprint(rule.OrgID)
+		h.metrics.WritesTotal.WithLabelValues(org, BackendTypeSQL.String()).Inc()
+		h.metrics.TransitionsTotal.WithLabelValues(org).Add(float64(len(entries)))
+
+		err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.BulkInsert(stateHistoryTable, entries, sqlstore.NativeSettingsForDialect(h.db.GetDialect()))
+			return err
+		})
+		if err != nil {
+			logger.Error("Failed to save alert state history batch", "error", err)
+			h.metrics.WritesFailed.WithLabelValues(org, BackendTypeSQL.String()).Inc()
+			h.metrics.TransitionsFailed.WithLabelValues(org).Add(float64(len(entries)))
+			errCh <- fmt.Errorf("failed to save alert state history batch: %w", err)
+			return
+		}
+		logger.Debug("Done saving alert state history batch")
+	}(writeCtx)
+	return errCh
+}
+
+// Query retrieves the most recent state history entries that match the query, and formats them into a dataframe
+// in the same format as the Loki backend.
+func (h *SQLBackend) Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	uids, err := getFolderUIDsForFilter(ctx, h.ac, h.ruleStore, query)
+	if err != nil {
+		return nil, err
+	}
+
+	now := h.clock.Now().UTC()
+	if query.To.IsZero() {
+		query.To = now
+	}
+	if query.From.IsZero() {
+		query.From = now.Add(-defaultQueryRange)
+	}
+	limit := query.Limit
+	if limit < 1 {
+		limit = defaultPageSize
+	}
+	if limit > maximumPageSize {
+		limit = maximumPageSize
+	}
+
+	entries := make([]stateHistoryEntry, 0, limit)
+	// Labels are only pre-filtered in the database, so we fetch pages until enough entries match the query.
+	for offset := 0; len(entries) < limit; offset += limit {
+		page := make([]stateHistoryEntry, 0, limit)
+		err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+			q := sess.Table(stateHistoryTable).
+				Where("org_id = ?", query.OrgID).
+				And("epoch >= ?", query.From.UnixMilli()).
+				And("epoch <= ?", query.To.UnixMilli())
+			if query.RuleUID != "" {
+				q = q.And("rule_uid = ?", query.RuleUID)
+			}
+			if query.DashboardUID != "" {
+				q = q.And("dashboard_uid = ?", query.DashboardUID)
+			}
+			if query.PanelID != 0 {
+				q = q.And("panel_id = ?", query.PanelID)
+			}
+			if len(uids) > 0 {
+				q = q.In("namespace_uid", uids)
+			}
+			for _, pattern := range labelPatterns(query.Labels) {
+				q = q.And("labels LIKE ?", pattern)
+			}
+			return q.Desc("epoch", "id").Limit(limit, offset).Find(&page)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query state history: %w", err)
+		}
+
+		for _, e := range page {
+			ok, err := e.matches(query.Labels)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			entries = append(entries, e)
+			if len(entries) == limit {
+				break
+			}
+		}
+		if len(page) < limit {
+			break
+		}
+	}
+
+	return entriesToFrame(entries)
+}
+
+// Run deletes the entries that are older than the configured max age every cleanup interval, until the context is cancelled.
+func (h *SQLBackend) Run(ctx context.Context) error {
+	if h.cfg.MaxAge <= 0 {
+		return nil
+	}
+	ticker := h.clock.Ticker(h.cfg.CleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			deleted, err := h.deleteExpired(ctx)
+			if err != nil {
+				h.log.Error("Failed to delete expired alert state history", "error", err, "deleted", deleted)
+				continue
+			}
+			if deleted > 0 {
+				h.log.Debug("Deleted expired alert state history", "deleted", deleted)
+			}
+		}
+	}
+}
+
+// deleteExpired deletes the entries that are older than the max age in batches, so that the table is not locked for
+// a long time. It returns the number of deleted entries.
+func (h *SQLBackend) deleteExpired(ctx context.Context) (int64, error) {
+	cutoff := h.clock.Now().Add(-h.cfg.MaxAge).UnixMilli()
+	var total int64
+	for ctx.Err() == nil {
+		var affected int64
+		err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+			ids := make([]int64, 0, h.cfg.BatchSize)
+			if err := sess.Table(stateHistoryTable).Cols("id").Where("epoch < ?", cutoff).Limit(h.cfg.BatchSize).Find(&ids); err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				return nil
+			}
+			var err error
+			affected, err = sess.In("id", ids).Delete(&stateHistoryEntry{})
+			return err
+		})
+		total += affected
+		if err != nil {
+			return total, err
+		}
+		if affected == 0 {
+			break
+		}
+	}
+	return total, nil
+}
+
+func statesToEntries(rule history_model.RuleMeta, states []state.StateTransition, logger log.Logger) []stateHistoryEntry {
+	entries := make([]stateHistoryEntry, 0, len(states))
+	for _, state := range states {
+		if !shouldRecord(state) {
+			continue
+		}
+
+		sanitizedLabels := removePrivateLabels(state.Labels)
+		labelsJSON, err := json.Marshal(sanitizedLabels)
+		if err != nil {
+			logger.Error("Failed to serialize labels of state, skipping", "error", err)
+			continue
+		}
+		valuesJSON, err := json.Marshal(valuesAsDataBlob(state.State))
+		if err != nil {
+			logger.Error("Failed to serialize values of state, skipping", "error", err)
+			continue
+		}
+
+		entry := stateHistoryEntry{
+			OrgID:         rule.OrgID,
+			RuleUID:       rule.UID,
+			NamespaceUID:  rule.NamespaceUID,
+			RuleGroup:     rule.Group,
+			DashboardUID:  rule.DashboardUID,
+			PanelID:       rule.PanelID,
+			Fingerprint:   labelFingerprint(sanitizedLabels),
+			Labels:        string(labelsJSON),
+			RuleID:        rule.ID,
+			RuleTitle:     rule.Title,
+			RuleCondition: rule.Condition,
+			PreviousState: state.PreviousFormatted(),
+			CurrentState:  state.Formatted(),
+			StateValues:   string(valuesJSON),
+			Epoch:         state.State.LastEvaluationTime.UnixMilli(),
+		}
+		if state.State.State == eval.Error {
+			entry.StateError = state.Error.Error()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// labelPatterns returns the patterns for LIKE conditions that pre-filter the entries whose labels could match the
+// given labels. The patterns never exclude matching entries, but they can include entries that do not match.
+func labelPatterns(labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	// Ensure that all queries we build are deterministic.
+	sort.Strings(keys)
+
+	patterns := make([]string, 0, len(keys))
+	for _, k := range keys {
+		key, _ := json.Marshal(k)
+		value, _ := json.Marshal(labels[k])
+		pair := string(key) + ":" + string(value)
+		// Backslash is the default escape character of LIKE in some databases, such pairs are only filtered in memory.
+		if strings.Contains(pair, `\`) {
+			continue
+		}
+		patterns = append(patterns, "%"+pair+"%")
+	}
+	return patterns
+}
+
+// matches returns true if the instance labels of the entry contain all the given labels.
+func (e stateHistoryEntry) matches(labels map[string]string) (bool, error) {
+	if len(labels) == 0 {
+		return true, nil
+	}
+	var instanceLabels map[string]string
+	if err := json.Unmarshal([]byte(e.Labels), &instanceLabels); err != nil {
+		return false, fmt.Errorf("failed to unmarshal labels of entry: %w", err)
+	}
+	for k, v := range labels {
+		if instanceLabels[k] != v {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// entriesToFrame formats the entries, that are sorted from the newest to the oldest, into a dataframe with the same
+// fields as the one that is returned by the Loki backend, in order of time.
+func entriesToFrame(entries []stateHistoryEntry) (*data.Frame, error) {
+	frame := data.NewFrame("states")
+	lbls := data.Labels(map[string]string{})
+
+	times := make([]time.Time, 0, len(entries))
+	lines := make([]json.RawMessage, 0, len(entries))
+	labels := make([]json.RawMessage, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		line := LokiEntry{
+			SchemaVersion: 1,
+			Previous:      e.PreviousState,
+			Current:       e.CurrentState,
+			Error:         e.StateError,
+			Condition:     e.RuleCondition,
+			DashboardUID:  e.DashboardUID,
+			PanelID:       e.PanelID,
+			Fingerprint:   e.Fingerprint,
+			RuleTitle:     e.RuleTitle,
+			RuleID:        e.RuleID,
+			RuleUID:       e.RuleUID,
+		}
+		if e.StateValues != "" && e.StateValues != "null" {
+			values, err := simplejson.NewJson([]byte(e.StateValues))
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal values of entry: %w", err)
+			}
+			line.Values = values
+		}
+		if err := json.Unmarshal([]byte(e.Labels), &line.InstanceLabels); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal labels of entry: %w", err)
+		}
+		lineJSON, err := json.Marshal(line)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize entry: %w", err)
+		}
+		lblsJSON, err := json.Marshal(map[string]string{
+			StateHistoryLabelKey: StateHistoryLabelValue,
+			OrgIDLabel:           fmt.Sprint(e.OrgID),
+			GroupLabel:           e.RuleGroup,
+			FolderUIDLabel:       e.NamespaceUID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize stream labels: %w", err)
+		}
+
+		times = append(times, time.UnixMilli(e.Epoch))
+		lines = append(lines, lineJSON)
+		labels = append(labels, lblsJSON)
+	}
+
+	frame.Fields = append(frame.Fields, data.NewField(dfTime, lbls, times))
+	frame.Fields = append(frame.Fields, data.NewField(dfLine, lbls, lines))
+	frame.Fields = append(frame.Fields, data.NewField(dfLabels, lbls, labels))
+	return frame, nil
+}
@@ -0,0 +1,267 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/folder"
+	acfakes "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol/fakes"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestStatesToEntries(t *testing.T) {
+	rule := createTestRule()
+	l := log.NewNopLogger()
+
+	t.Run("skips non-transitory states", func(t *testing.T) {
+		res := statesToEntries(rule, singleFromNormal(&state.State{State: eval.Normal}), l)
+
+		require.Empty(t, res)
+	})
+
+	t.Run("stores each field of the state transition in its own column", func(t *testing.T) {
+		now := time.Now()
+		res := statesToEntries(rule, singleFromNormal(&state.State{
+			State:              eval.Error,
+			Error:              fmt.Errorf("oh no"),
+			Labels:             data.Labels{"a": "b", "__private__": "c"},
+			LastEvaluationTime: now,
+		}), l)
+
+		require.Len(t, res, 1)
+		entry := res[0]
+		require.Equal(t, rule.OrgID, entry.OrgID)
+		require.Equal(t, rule.UID, entry.RuleUID)
+		require.Equal(t, rule.NamespaceUID, entry.NamespaceUID)
+		require.Equal(t, rule.Group, entry.RuleGroup)
+		require.Equal(t, now.UnixMilli(), entry.Epoch)
+		require.JSONEq(t, `{"a":"b"}`, entry.Labels)
+
+		require.Equal(t, rule.ID, entry.RuleID)
+		require.Equal(t, rule.Title, entry.RuleTitle)
+		require.Equal(t, rule.Condition, entry.RuleCondition)
+		require.Equal(t, "Normal", entry.PreviousState)
+		require.Equal(t, "Error", entry.CurrentState)
+		require.Contains(t, entry.StateError, "oh no")
+		require.JSONEq(t, `{}`, entry.StateValues)
+		require.Equal(t, labelFingerprint(data.Labels{"a": "b"}), entry.Fingerprint)
+	})
+}
+
+func TestLabelPatterns(t *testing.T) {
+	patterns := labelPatterns(map[string]string{"b": "2", "a": "1", "c": `x"y`})
+
+	require.Equal(t, []string{`%"a":"1"%`, `%"b":"2"%`}, patterns)
+}
+
+func TestIntegrationSQLBackend(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	orgID := int64(1)
+	now := time.Now().Truncate(time.Second)
+	usr := accesscontrol.BackgroundUser("test", orgID, org.RoleNone, nil)
+
+	createSut := func(t *testing.T, ac AccessControl) *SQLBackend {
+		rules := fakes.NewRuleStore(t)
+		rules.Folders = map[int64][]*folder.Folder{
+			orgID: {{UID: "folder-1", OrgID: orgID}, {UID: "folder-2", OrgID: orgID}},
+		}
+		rules.Rules = map[int64][]*models.AlertRule{
+			orgID: models.RuleGen.With(models.RuleMuts.WithOrgID(orgID)).GenerateManyRef(1),
+		}
+		met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+		cfg := SQLConfig{MaxAge: time.Hour, CleanupInterval: time.Minute, BatchSize: 2}
+		return NewSQLBackend(log.NewNopLogger(), cfg, db.InitTestDB(t), met, rules, ac)
+	}
+	readAll := &acfakes.FakeRuleService{
+		CanReadAllRulesFunc: func(ctx context.Context, user identity.Requester) (bool, error) {
+			return true, nil
+		},
+	}
+	record := func(t *testing.T, sut *SQLBackend, rule history_model.RuleMeta, states ...state.StateTransition) {
+		t.Helper()
+		require.NoError(t, <-sut.Record(ctx, rule, states))
+	}
+	transition := func(current eval.State, at time.Time, labels data.Labels) state.StateTransition {
+		return state.StateTransition{
+			PreviousState: eval.Normal,
+			State:         &state.State{State: current, Labels: labels, LastEvaluationTime: at},
+		}
+	}
+	rule1 := createTestRule()
+	rule1.NamespaceUID = "folder-1"
+	rule2 := createTestRule()
+	rule2.UID = "rule-uid-2"
+	rule2.NamespaceUID = "folder-2"
+	rule2.DashboardUID = ""
+	rule2.PanelID = 0
+
+	seed := func(t *testing.T, sut *SQLBackend) {
+		t.Helper()
+		record(t, sut, rule1,
+			transition(eval.Alerting, now.Add(-3*time.Minute), data.Labels{"team": "a", "env": "prod"}),
+			transition(eval.Alerting, now.Add(-2*time.Minute), data.Labels{"team": "b", "env": "prod"}),
+		)
+		record(t, sut, rule2,
+			transition(eval.Pending, now.Add(-time.Minute), data.Labels{"team": "a", "env": "dev"}),
+		)
+	}
+	query := func(q models.HistoryQuery) models.HistoryQuery {
+		q.OrgID = orgID
+		q.SignedInUser = usr
+		q.From = now.Add(-time.Hour)
+		q.To = now
+		return q
+	}
+	frameTimes := func(t *testing.T, frame *data.Frame) []time.Time {
+		t.Helper()
+		require.Len(t, frame.Fields, 3)
+		times := make([]time.Time, 0, frame.Rows())
+		for i := 0; i < frame.Rows(); i++ {
+			times = append(times, frame.Fields[0].At(i).(time.Time))
+		}
+		return times
+	}
+
+	t.Run("records and queries history in order of time", func(t *testing.T) {
+		sut := createSut(t, readAll)
+		seed(t, sut)
+
+		frame, err := sut.Query(ctx, query(models.HistoryQuery{}))
+		require.NoError(t, err)
+		require.Equal(t, []time.Time{now.Add(-3 * time.Minute), now.Add(-2 * time.Minute), now.Add(-time.Minute)}, frameTimes(t, frame))
+
+		var line LokiEntry
+		require.NoError(t, json.Unmarshal(frame.Fields[1].At(0).(json.RawMessage), &line))
+		require.Equal(t, rule1.UID, line.RuleUID)
+		require.Equal(t, map[string]string{"team": "a", "env": "prod"}, line.InstanceLabels)
+
+		var lbls map[string]string
+		require.NoError(t, json.Unmarshal(frame.Fields[2].At(2).(json.RawMessage), &lbls))
+		require.Equal(t, map[string]string{
+			StateHistoryLabelKey: StateHistoryLabelValue,
+			OrgIDLabel:           "1",
+			GroupLabel:           rule2.Group,
+			FolderUIDLabel:       "folder-2",
+		}, lbls)
+	})
+
+	t.Run("filters history", func(t *testing.T) {
+		sut := createSut(t, readAll)
+		seed(t, sut)
+
+		testCases := []struct {
+			name     string
+			query    models.HistoryQuery
+			expected []time.Time
+		}{
+			{
+				name:     "by rule UID",
+				query:    models.HistoryQuery{RuleUID: rule2.UID},
+				expected: []time.Time{now.Add(-time.Minute)},
+			},
+			{
+				name:     "by dashboard and panel",
+				query:    models.HistoryQuery{DashboardUID: rule1.DashboardUID, PanelID: rule1.PanelID},
+				expected: []time.Time{now.Add(-3 * time.Minute), now.Add(-2 * time.Minute)},
+			},
+			{
+				name:     "by labels",
+				query:    models.HistoryQuery{Labels: map[string]string{"team": "a"}},
+				expected: []time.Time{now.Add(-3 * time.Minute), now.Add(-time.Minute)},
+			},
+			{
+				name:     "by multiple labels",
+				query:    models.HistoryQuery{Labels: map[string]string{"team": "a", "env": "dev"}},
+				expected: []time.Time{now.Add(-time.Minute)},
+			},
+			{
+				name:     "by labels that do not match",
+				query:    models.HistoryQuery{Labels: map[string]string{"team": "c"}},
+				expected: []time.Time{},
+			},
+			{
+				name:     "limit keeps the most recent entries",
+				query:    models.HistoryQuery{Limit: 2},
+				expected: []time.Time{now.Add(-2 * time.Minute), now.Add(-time.Minute)},
+			},
+			{
+				name:     "limit applies after filtering by labels",
+				query:    models.HistoryQuery{Labels: map[string]string{"env": "prod"}, Limit: 1},
+				expected: []time.Time{now.Add(-2 * time.Minute)},
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				frame, err := sut.Query(ctx, query(tc.query))
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, frameTimes(t, frame))
+			})
+		}
+	})
+
+	t.Run("filters history by folders the user can access", func(t *testing.T) {
+		ac := &acfakes.FakeRuleService{
+			CanReadAllRulesFunc: func(ctx context.Context, user identity.Requester) (bool, error) {
+				return false, nil
+			},
+			HasAccessInFolderFunc: func(ctx context.Context, user identity.Requester, namespaced models.Namespaced) (bool, error) {
+				return namespaced.GetNamespaceUID() == "folder-2", nil
+			},
+		}
+		sut := createSut(t, ac)
+		seed(t, sut)
+
+		frame, err := sut.Query(ctx, query(models.HistoryQuery{}))
+		require.NoError(t, err)
+		require.Equal(t, []time.Time{now.Add(-time.Minute)}, frameTimes(t, frame))
+	})
+
+	t.Run("deletes expired history in batches", func(t *testing.T) {
+		sut := createSut(t, readAll)
+		mockClock := clock.NewMock()
+		mockClock.Set(now)
+		sut.clock = mockClock
+		seed(t, sut)
+		record(t, sut, rule1,
+			transition(eval.Alerting, now.Add(-3*time.Hour), data.Labels{"team": "a"}),
+			transition(eval.Alerting, now.Add(-2*time.Hour), data.Labels{"team": "b"}),
+			transition(eval.Alerting, now.Add(-2*time.Hour), data.Labels{"team": "c"}),
+		)
+
+		deleted, err := sut.deleteExpired(ctx)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, deleted)
+
+		q := query(models.HistoryQuery{})
+		q.From = now.Add(-24 * time.Hour)
+		frame, err := sut.Query(ctx, q)
+		require.NoError(t, err)
+		require.Len(t, frameTimes(t, frame), 3)
+	})
+}
@@ -2,6 +2,7 @@ package state
 
 import (
 	"context"
+	"errors"
 	"net/url"
 	"strconv"
 	"time"
@@ -50,6 +51,7 @@ type Manager struct {
 	instanceStore InstanceStore
 	images        ImageCapturer
 	historian     Historian
+	stateWriter   AlertStateWriter
 	externalURL   *url.URL
 
 	doNotSaveNormalState           bool
@@ -66,6 +68,8 @@ type ManagerCfg struct {
 	Images        ImageCapturer
 	Clock         clock.Clock
 	Historian     Historian
+	// AlertStateWriter, if set, writes the states of the alert instances of every rule after every evaluation.
+	AlertStateWriter AlertStateWriter
 	// DoNotSaveNormalState controls whether eval.Normal state is persisted to the database and returned by get methods
 	DoNotSaveNormalState bool
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
@@ -101,6 +105,7 @@ func NewManager(cfg ManagerCfg, statePersister StatePersister) *Manager {
 		instanceStore:                  cfg.InstanceStore,
 		images:                         cfg.Images,
 		historian:                      cfg.Historian,
+		stateWriter:                    cfg.AlertStateWriter,
 		clock:                          cfg.Clock,
 		externalURL:                    cfg.ExternalURL,
 		doNotSaveNormalState:           cfg.DoNotSaveNormalState,
@@ -192,30 +197,8 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 				orgStates[entry.RuleUID] = rulesStates
 			}
 
-			lbs := map[string]string(entry.Labels)
-			cacheID := entry.Labels.Fingerprint()
-			var resultFp data.Fingerprint
-			if entry.ResultFingerprint != "" {
-				fp, err := strconv.ParseUint(entry.ResultFingerprint, 16, 64)
-				if err != nil {
-					st.log.Error("Failed to parse result fingerprint of alert instance", "error", err, "ruleUID", entry.RuleUID)
-				}
-				resultFp = data.Fingerprint(fp)
-			}
-			rulesStates.states[cacheID] = &State{
-				AlertRuleUID:         entry.RuleUID,
-				OrgID:                entry.RuleOrgID,
-				CacheID:              cacheID,
-				Labels:               lbs,
-				State:                translateInstanceState(entry.CurrentState),
-				StateReason:          entry.CurrentReason,
-				LastEvaluationString: "",
-				StartsAt:             entry.CurrentStateSince,
-				EndsAt:               entry.CurrentStateEnd,
-				LastEvaluationTime:   entry.LastEvalTime,
-				Annotations:          ruleForEntry.Annotations,
-				ResultFingerprint:    resultFp,
-			}
+			s := st.stateFromInstance(entry, ruleForEntry)
+			rulesStates.states[s.CacheID] = s
 			statesCount++
 		}
 	}
@@ -224,6 +207,122 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 	st.log.Info("State cache has been initialized", "states", statesCount, "duration", time.Since(startTime))
 }
 
+// SyncRuleStates replaces the states of the rules of the organization in the cache with the states saved in the
+// instance store by the members of the cluster that evaluate the rules, and returns the states of each rule that need
+// to be sent to the Alertmanager. The firing states that are no longer saved were resolved by the member that
+// evaluates the rule, so they are resolved as well.
+func (st *Manager) SyncRuleStates(ctx context.Context, orgID int64, rules []*ngModels.AlertRule) map[ngModels.AlertRuleKey]StateTransitions {
+	if st.instanceStore == nil || len(rules) == 0 {
+		return nil
+	}
+	logger := st.log.FromContext(ctx)
+	query := &ngModels.ListAlertInstancesQuery{RuleOrgID: orgID}
+	if len(rules) == 1 {
+		query.RuleUID = rules[0].UID
+	}
+	alertInstances, err := st.instanceStore.ListAlertInstances(ctx, query)
+	if err != nil {
+		logger.Error("Unable to fetch the state of the rules", "error", err)
+		return nil
+	}
+	instancesByRule := make(map[string][]*ngModels.AlertInstance)
+	for _, entry := range alertInstances {
+		instancesByRule[entry.RuleUID] = append(instancesByRule[entry.RuleUID], entry)
+	}
+
+	now := st.clock.Now()
+	result := make(map[ngModels.AlertRuleKey]StateTransitions)
+	for _, rule := range rules {
+		previous := make(map[data.Fingerprint]*State)
+		for _, s := range st.cache.getStatesForRuleUID(rule.OrgID, rule.UID, false) {
+			previous[s.CacheID] = s
+		}
+
+		rulesStates := &ruleStates{states: make(map[data.Fingerprint]*State, len(instancesByRule[rule.UID]))}
+		transitions := make(StateTransitions, 0, len(instancesByRule[rule.UID]))
+		for _, entry := range instancesByRule[rule.UID] {
+			s := st.stateFromInstance(entry, rule)
+			transition := StateTransition{State: s, PreviousState: eval.Normal}
+			if prev, ok := previous[s.CacheID]; ok {
+				delete(previous, s.CacheID)
+				transition.PreviousState, transition.PreviousStateReason = prev.State, prev.StateReason
+				s.LastSentAt = prev.LastSentAt
+				s.ResolvedAt = prev.ResolvedAt
+				if isFiring(prev.State) && s.State == eval.Normal {
+					resolvedAt := s.LastEvaluationTime
+					s.ResolvedAt = &resolvedAt
+				}
+			}
+			if s.State != eval.Normal && s.State != eval.Pending {
+				s.ResolvedAt = nil
+			}
+			rulesStates.states[s.CacheID] = s
+			transitions = append(transitions, transition)
+		}
+		for _, prev := range previous {
+			if !isFiring(prev.State) {
+				continue
+			}
+			resolved := *prev
+			resolved.SetNormal("", now, now)
+			resolved.ResolvedAt = &now
+			resolved.LastEvaluationTime = now
+			transitions = append(transitions, StateTransition{
+				State:               &resolved,
+				PreviousState:       prev.State,
+				PreviousStateReason: prev.StateReason,
+			})
+		}
+		st.cache.setRuleStates(rule.OrgID, rule.UID, rulesStates)
+
+		var toSend StateTransitions
+		for _, t := range transitions {
+			if t.NeedsSending(st.ResendDelay, st.ResolvedRetention) {
+				// the states are sent at the time the member that evaluates the rule evaluated them.
+				sentAt := t.LastEvaluationTime
+				t.LastSentAt = &sentAt
+				toSend = append(toSend, t)
+			}
+		}
+		if len(toSend) > 0 {
+			result[rule.GetKey()] = toSend
+		}
+	}
+	logger.Debug("State of the rules has been synchronized", "rules", len(rules), "states", len(alertInstances))
+	return result
+}
+
+// isFiring returns true if the alerts of the state are sent to the Alertmanager as firing alerts.
+func isFiring(s eval.State) bool {
+	return s == eval.Alerting || s == eval.Error || s == eval.NoData
+}
+
+func (st *Manager) stateFromInstance(entry *ngModels.AlertInstance, rule *ngModels.AlertRule) *State {
+	var resultFp data.Fingerprint
+	if entry.ResultFingerprint != "" {
+		fp, err := strconv.ParseUint(entry.ResultFingerprint, 16, 64)
+		if err != nil {
+			st.log.Error("Failed to parse result fingerprint of alert instance", "error", err, "ruleUID", entry.RuleUID)
+		}
+		resultFp = data.Fingerprint(fp)
+	}
+	return &State{
+		AlertRuleUID:         entry.RuleUID,
+		OrgID:                entry.RuleOrgID,
+		CacheID:              entry.Labels.Fingerprint(),
+		Labels:               map[string]string(entry.Labels),
+		State:                translateInstanceState(entry.CurrentState),
+		StateReason:          entry.CurrentReason,
+		LastEvaluationString: "",
+		StartsAt:             entry.CurrentStateSince,
+		EndsAt:               entry.CurrentStateEnd,
+		LastEvaluationTime:   entry.LastEvalTime,
+		Annotations:          rule.Annotations,
+		ResultFingerprint:    resultFp,
+		KeepFiringSince:      entry.KeepFiringSince,
+	}
+}
+
 func (st *Manager) Get(orgID int64, alertRuleUID string, stateId data.Fingerprint) *State {
 	return st.cache.get(orgID, alertRuleUID, stateId)
 }
@@ -237,11 +336,33 @@ func (st *Manager) DeleteStateByRuleUID(ctx context.Context, ruleKey ngModels.Al
 
 	states := st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
 
+	now := st.clock.Now()
+	if st.stateWriter != nil {
+		st.stateWriter.ResetAlertStates(ctx, ruleKey, now)
+	}
+
+	transitions := resetStates(states, reason, now)
+	if len(transitions) == 0 {
+		return nil
+	}
+
+	if st.instanceStore != nil {
+		err := st.instanceStore.DeleteAlertInstancesByRule(ctx, ruleKey)
+		if err != nil {
+			logger.Error("Failed to delete states that belong to a rule from database", "error", err)
+		}
+	}
+	logger.Info("Rules state was reset", "states", len(states))
+
+	return transitions
+}
+
+// resetStates sets the states to Normal and returns the transitions.
+func resetStates(states []*State, reason string, now time.Time) []StateTransition {
 	if len(states) == 0 {
 		return nil
 	}
 
-	now := st.clock.Now()
 	transitions := make([]StateTransition, 0, len(states))
 	for _, s := range states {
 		oldState := s.State
@@ -266,18 +387,17 @@ func (st *Manager) DeleteStateByRuleUID(ctx context.Context, ruleKey ngModels.Al
 			PreviousStateReason: oldReason,
 		})
 	}
-
-	if st.instanceStore != nil {
-		err := st.instanceStore.DeleteAlertInstancesByRule(ctx, ruleKey)
-		if err != nil {
-			logger.Error("Failed to delete states that belong to a rule from database", "error", err)
-		}
-	}
-	logger.Info("Rules state was reset", "states", len(states))
-
 	return transitions
 }
 
+// ForgetStateByRuleUID removes the rule instances from cache but keeps them in the instance store, and returns the
+// transitions of the removed states to Normal. It is used for rules evaluated by another member of the cluster,
+// which deletes the states from the instance store itself.
+func (st *Manager) ForgetStateByRuleUID(ruleKey ngModels.AlertRuleKey, reason string) []StateTransition {
+	states := st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+	return resetStates(states, reason, st.clock.Now())
+}
+
 // ResetStateByRuleUID removes the rule instances from cache and instanceStore and saves state history. If the state
 // history has to be saved, rule must not be nil.
 func (st *Manager) ResetStateByRuleUID(ctx context.Context, rule *ngModels.AlertRule, reason string) []StateTransition {
@@ -342,6 +462,10 @@ func (st *Manager) ProcessEvalResults(
 	if st.historian != nil {
 		st.historian.Record(ctx, history_model.NewRuleMeta(alertRule, logger), allChanges)
 	}
+	if st.stateWriter != nil {
+		// the states of all alert instances are written, including the ones that are not in the results of this evaluation.
+		st.stateWriter.WriteAlertStates(ctx, alertRule, evaluatedAt, st.cache.getStatesForRuleUID(alertRule.OrgID, alertRule.UID, false))
+	}
 
 	// Optional callback intended for sending the states to an alertmanager.
 	// Some uses ,such as backtesting or the testing api, do not send.
@@ -380,6 +504,13 @@ func (st *Manager) setNextStateForRule(ctx context.Context, alertRule *ngModels.
 			return transitions // if there are no current states for the rule. Create ones for each result
 		}
 	}
+	if results.IsDependencyFailed() {
+		// the rule was not evaluated, so all current states are kept instead of becoming stale.
+		transitions := st.setNextStateForAll(ctx, alertRule, results[0], logger)
+		if len(transitions) > 0 {
+			return transitions
+		}
+	}
 	transitions := make([]StateTransition, 0, len(results))
 	for _, result := range results {
 		currentState := st.cache.getOrCreate(ctx, logger, alertRule, result, extraLabels, st.externalURL)
@@ -447,6 +578,11 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 		logger.Debug("Setting next state", "handler", "resultAlerting")
 		resultAlerting(currentState, alertRule, result, logger, "")
 	case eval.Error:
+		if errors.Is(result.Error, eval.ErrDependencyFailed) {
+			logger.Debug("Setting next state", "handler", "resultDependencyFailed")
+			resultDependencyFailed(currentState, alertRule, result, logger)
+			break
+		}
 		logger.Debug("Setting next state", "handler", "resultError")
 		resultError(currentState, alertRule, result, logger)
 	case eval.NoData:
@@ -465,6 +601,14 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 		currentState.StateReason = resultStateReason(result, alertRule)
 	}
 
+	if currentState.KeepFiringSince != nil {
+		currentState.StateReason = ngModels.ConcatReasons(result.State.String(), ngModels.StateReasonKeepFiring)
+	}
+
+	if result.State == eval.Error && errors.Is(result.Error, eval.ErrDependencyFailed) {
+		currentState.StateReason = ngModels.StateReasonDependencyFailed
+	}
+
 	// Set Resolved property so the scheduler knows to send a postable alert
 	// to Alertmanager.
 	newlyResolved := false
@@ -544,10 +688,27 @@ func translateInstanceState(state ngModels.InstanceStateType) eval.State {
 func (st *Manager) deleteStaleStatesFromCache(ctx context.Context, logger log.Logger, evaluatedAt time.Time, alertRule *ngModels.AlertRule) []StateTransition {
 	// If we are removing two or more stale series it makes sense to share the resolved image as the alert rule is the same.
 	// TODO: We will need to change this when we support images without screenshots as each series will have a different image
+	var keptStates []StateTransition
 	staleStates := st.cache.deleteRuleStates(alertRule.GetKey(), func(s *State) bool {
-		return stateIsStale(evaluatedAt, s.LastEvaluationTime, alertRule.IntervalSeconds)
+		if !stateIsStale(evaluatedAt, s.LastEvaluationTime, alertRule.IntervalSeconds) {
+			return false
+		}
+		// Alerting states of missing series keep firing in the same way as states whose condition is no longer met.
+		if s.State == eval.Alerting && keepFiring(s, alertRule, evaluatedAt) {
+			oldReason := s.StateReason
+			s.StateReason = ngModels.ConcatReasons(ngModels.StateReasonMissingSeries, ngModels.StateReasonKeepFiring)
+			s.Maintain(alertRule.IntervalSeconds, evaluatedAt)
+			keptStates = append(keptStates, StateTransition{
+				State:               s,
+				PreviousState:       s.State,
+				PreviousStateReason: oldReason,
+			})
+			return false
+		}
+		return true
 	})
-	resolvedStates := make([]StateTransition, 0, len(staleStates))
+	resolvedStates := make([]StateTransition, 0, len(staleStates)+len(keptStates))
+	resolvedStates = append(resolvedStates, keptStates...)
 
 	for _, s := range staleStates {
 		logger.Info("Detected stale state entry", "cacheID", s.CacheID, "state", s.State, "reason", s.StateReason)
@@ -2127,3 +2127,286 @@ func mergeLabels(a, b data.Labels) data.Labels {
 	}
 	return result
 }
+
+func TestKeepFiringFor(t *testing.T) {
+	ctx := context.Background()
+
+	newManager := func(clk clock.Clock) *state.Manager {
+		cfg := state.ManagerCfg{
+			Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+			ExternalURL:   nil,
+			InstanceStore: &state.FakeInstanceStore{},
+			Images:        &state.NoopImageService{},
+			Clock:         clk,
+			Historian:     &state.FakeHistorian{},
+			Tracer:        tracing.InitializeTracerForTest(),
+			Log:           log.New("ngalert.state.manager"),
+		}
+		return state.NewManager(cfg, state.NewNoopPersister())
+	}
+
+	gen := models.RuleGen
+	rule := gen.With(gen.WithFor(0), gen.WithIntervalSeconds(10), gen.WithKeepFiringFor(30*time.Second)).GenerateRef()
+	interval := time.Duration(rule.IntervalSeconds) * time.Second
+	labels := data.Labels{"instance": "a"}
+
+	t.Run("alert keeps firing after the condition is no longer met", func(t *testing.T) {
+		clk := clock.NewMock()
+		st := newManager(clk)
+		evaluate := func(s eval.State) *state.State {
+			t.Helper()
+			result := eval.ResultGen(eval.WithState(s), eval.WithLabels(labels), eval.WithEvaluatedAt(clk.Now()))()
+			processed := st.ProcessEvalResults(ctx, clk.Now(), rule, eval.Results{result}, nil, nil)
+			require.Len(t, processed, 1)
+			return processed[0].State
+		}
+
+		s := evaluate(eval.Alerting)
+		require.Equal(t, eval.Alerting, s.State)
+		require.Nil(t, s.KeepFiringSince)
+
+		clk.Add(interval)
+		keepFiringSince := clk.Now()
+		s = evaluate(eval.Normal)
+		require.Equal(t, eval.Alerting, s.State)
+		require.Equal(t, "Normal, KeepFiring", s.StateReason)
+		require.Equal(t, keepFiringSince, *s.KeepFiringSince)
+		require.Equal(t, keepFiringSince.Add(rule.KeepFiringFor), *s.KeepFiringUntil(rule.KeepFiringFor))
+		require.Nil(t, s.ResolvedAt)
+
+		// the hold time starts again when the condition is met again
+		clk.Add(interval)
+		s = evaluate(eval.Alerting)
+		require.Equal(t, eval.Alerting, s.State)
+		require.Empty(t, s.StateReason)
+		require.Nil(t, s.KeepFiringSince)
+
+		clk.Add(interval)
+		keepFiringSince = clk.Now()
+		for clk.Now().Sub(keepFiringSince) < rule.KeepFiringFor {
+			s = evaluate(eval.Normal)
+			require.Equal(t, eval.Alerting, s.State)
+			require.Equal(t, keepFiringSince, *s.KeepFiringSince)
+			clk.Add(interval)
+		}
+
+		s = evaluate(eval.Normal)
+		require.Equal(t, eval.Normal, s.State)
+		require.Empty(t, s.StateReason)
+		require.Nil(t, s.KeepFiringSince)
+		require.Equal(t, clk.Now(), *s.ResolvedAt)
+	})
+
+	t.Run("time since the alert keeps firing is persisted and restored", func(t *testing.T) {
+		clk := clock.NewMock()
+		store := &instanceListStore{FakeInstanceStore: &state.FakeInstanceStore{}}
+		cfg := state.ManagerCfg{
+			Metrics:                 metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+			InstanceStore:           store,
+			Images:                  &state.NoopImageService{},
+			Clock:                   clk,
+			Historian:               &state.FakeHistorian{},
+			MaxStateSaveConcurrency: 1,
+			Tracer:                  tracing.InitializeTracerForTest(),
+			Log:                     log.New("ngalert.state.manager"),
+		}
+		st := state.NewManager(cfg, state.NewSyncStatePersisiter(log.New("ngalert.state.manager.persist"), cfg))
+		for _, s := range []eval.State{eval.Alerting, eval.Normal} {
+			result := eval.ResultGen(eval.WithState(s), eval.WithLabels(labels), eval.WithEvaluatedAt(clk.Now()))()
+			st.ProcessEvalResults(ctx, clk.Now(), rule, eval.Results{result}, nil, nil)
+			clk.Add(interval)
+		}
+		keepFiringSince := clk.Now().Add(-interval)
+
+		var saved *models.AlertInstance
+		for _, op := range store.RecordedOps() {
+			if instance, ok := op.(models.AlertInstance); ok {
+				saved = &instance
+			}
+		}
+		require.NotNil(t, saved)
+		require.Equal(t, keepFiringSince, *saved.KeepFiringSince)
+
+		store.instances = []*models.AlertInstance{saved}
+		cfg.Metrics = metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics()
+		restored := state.NewManager(cfg, state.NewNoopPersister())
+		restored.SyncRuleStates(ctx, rule.OrgID, []*models.AlertRule{rule})
+		states := restored.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		require.Len(t, states, 1)
+		require.Equal(t, keepFiringSince, *states[0].KeepFiringSince)
+	})
+
+	t.Run("missing series keep firing", func(t *testing.T) {
+		clk := clock.NewMock()
+		st := newManager(clk)
+		other := eval.ResultGen(eval.WithState(eval.Normal), eval.WithLabels(data.Labels{"instance": "b"}))
+		evaluate := func(results ...eval.Result) []state.StateTransition {
+			t.Helper()
+			results = append(results, other())
+			for i := range results {
+				results[i].EvaluatedAt = clk.Now()
+			}
+			return st.ProcessEvalResults(ctx, clk.Now(), rule, results, nil, nil)
+		}
+		find := func(transitions []state.StateTransition) *state.StateTransition {
+			for i := range transitions {
+				if transitions[i].Labels["instance"] == "a" {
+					return &transitions[i]
+				}
+			}
+			return nil
+		}
+
+		evaluate(eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(labels))())
+		clk.Add(2 * interval)
+		keepFiringSince := clk.Now()
+		for clk.Now().Sub(keepFiringSince) < rule.KeepFiringFor {
+			s := find(evaluate())
+			require.NotNil(t, s)
+			require.Equal(t, eval.Alerting, s.State.State)
+			require.Equal(t, "MissingSeries, KeepFiring", s.StateReason)
+			require.False(t, s.IsStale())
+			clk.Add(interval)
+		}
+
+		s := find(evaluate())
+		require.NotNil(t, s)
+		require.Equal(t, eval.Normal, s.State.State)
+		require.True(t, s.IsStale())
+		require.Equal(t, clk.Now(), *s.ResolvedAt)
+	})
+}
+
+func TestDependencyFailed(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+	cfg := state.ManagerCfg{
+		Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		ExternalURL:   nil,
+		InstanceStore: &state.FakeInstanceStore{},
+		Images:        &state.NoopImageService{},
+		Clock:         clk,
+		Historian:     &state.FakeHistorian{},
+		Tracer:        tracing.InitializeTracerForTest(),
+		Log:           log.New("ngalert.state.manager"),
+	}
+	st := state.NewManager(cfg, state.NewNoopPersister())
+
+	gen := models.RuleGen
+	rule := gen.With(gen.WithFor(0), gen.WithIntervalSeconds(10), gen.WithDependsOn("upstream")).GenerateRef()
+	labels := data.Labels{"instance": "a"}
+
+	result := eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(labels), eval.WithEvaluatedAt(clk.Now()))()
+	processed := st.ProcessEvalResults(ctx, clk.Now(), rule, eval.Results{result}, nil, nil)
+	require.Len(t, processed, 1)
+	require.Equal(t, eval.Alerting, processed[0].State.State)
+
+	clk.Add(10 * time.Second)
+	upstreamErr := fmt.Errorf("%w: rule upstream failed: query failed", eval.ErrDependencyFailed)
+	processed = st.ProcessEvalResults(ctx, clk.Now(), rule, eval.Results{eval.NewResultFromError(upstreamErr, clk.Now(), 0)}, nil, nil)
+	require.Len(t, processed, 1)
+	s := processed[0]
+	require.Equal(t, eval.Alerting, s.State.State)
+	require.Equal(t, models.StateReasonDependencyFailed, s.StateReason)
+	require.ErrorIs(t, s.Error, eval.ErrDependencyFailed)
+	require.Equal(t, labels["instance"], s.Labels["instance"])
+	require.Equal(t, clk.Now(), s.LastEvaluationTime)
+	require.Nil(t, s.ResolvedAt)
+
+	t.Run("firing state is maintained and resent", func(t *testing.T) {
+		var sent []state.StateTransition
+		send := func(_ context.Context, states state.StateTransitions) {
+			sent = append(sent, states...)
+		}
+		// the resend delay is three times the interval, so the state is resent every third evaluation.
+		for i := 1; i <= 6; i++ {
+			clk.Add(10 * time.Second)
+			processed := st.ProcessEvalResults(ctx, clk.Now(), rule, eval.Results{eval.NewResultFromError(upstreamErr, clk.Now(), 0)}, nil, send)
+			require.Len(t, processed, 1)
+			s := processed[0]
+			require.Equal(t, eval.Alerting, s.State.State)
+			require.Equal(t, models.StateReasonDependencyFailed, s.StateReason)
+			require.Equal(t, clk.Now().Add(4*state.ResendDelay), s.EndsAt)
+			require.Nil(t, s.ResolvedAt)
+		}
+		require.Len(t, sent, 2)
+		for _, s := range sent {
+			require.Equal(t, eval.Alerting, s.State.State)
+			require.True(t, s.EndsAt.After(clk.Now()))
+		}
+	})
+}
+
+// instanceListStore is a FakeInstanceStore that lists the given instances.
+type instanceListStore struct {
+	*state.FakeInstanceStore
+	instances []*models.AlertInstance
+}
+
+func (f *instanceListStore) ListAlertInstances(ctx context.Context, q *models.ListAlertInstancesQuery) ([]*models.AlertInstance, error) {
+	_, _ = f.FakeInstanceStore.ListAlertInstances(ctx, q)
+	return f.instances, nil
+}
+
+type fakeAlertStateWriter struct {
+	rules  []*models.AlertRule
+	times  []time.Time
+	states [][]*state.State
+	resets []models.AlertRuleKey
+}
+
+func (f *fakeAlertStateWriter) WriteAlertStates(_ context.Context, rule *models.AlertRule, evaluatedAt time.Time, states []*state.State) {
+	f.rules = append(f.rules, rule)
+	f.times = append(f.times, evaluatedAt)
+	f.states = append(f.states, states)
+}
+
+func (f *fakeAlertStateWriter) ResetAlertStates(_ context.Context, key models.AlertRuleKey, _ time.Time) {
+	f.resets = append(f.resets, key)
+}
+
+func TestProcessEvalResultsWritesAlertStates(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+	writer := &fakeAlertStateWriter{}
+	cfg := state.ManagerCfg{
+		Metrics:          metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore:    &state.FakeInstanceStore{},
+		Images:           &state.NoopImageService{},
+		Clock:            clk,
+		Historian:        &state.FakeHistorian{},
+		AlertStateWriter: writer,
+		Tracer:           tracing.InitializeTracerForTest(),
+		Log:              log.New("ngalert.state.manager"),
+	}
+	st := state.NewManager(cfg, state.NewNoopPersister())
+
+	rule := models.RuleGen.With(models.RuleGen.WithFor(0)).GenerateRef()
+	results := eval.Results{
+		eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(data.Labels{"instance": "a"}), eval.WithEvaluatedAt(clk.Now()))(),
+		eval.ResultGen(eval.WithState(eval.Normal), eval.WithLabels(data.Labels{"instance": "b"}), eval.WithEvaluatedAt(clk.Now()))(),
+	}
+	processed := st.ProcessEvalResults(ctx, clk.Now(), rule, results, nil, nil)
+
+	require.Equal(t, []*models.AlertRule{rule}, writer.rules)
+	require.Equal(t, []time.Time{clk.Now()}, writer.times)
+	require.Len(t, writer.states, 1)
+	require.ElementsMatch(t, []*state.State{processed[0].State, processed[1].State}, writer.states[0])
+
+	t.Run("states that are not in the results are written", func(t *testing.T) {
+		clk.Add(time.Duration(rule.IntervalSeconds) * time.Second)
+		results := eval.Results{
+			eval.ResultGen(eval.WithState(eval.Normal), eval.WithLabels(data.Labels{"instance": "b"}), eval.WithEvaluatedAt(clk.Now()))(),
+		}
+		processed := st.ProcessEvalResults(ctx, clk.Now(), rule, results, nil, nil)
+		require.Len(t, processed, 1)
+
+		require.Len(t, writer.states, 2)
+		written := writer.states[1]
+		require.Len(t, written, 2)
+		require.ElementsMatch(t, []eval.State{eval.Alerting, eval.Normal}, []eval.State{written[0].State, written[1].State})
+	})
+
+	st.ResetStateByRuleUID(ctx, rule, models.StateReasonPaused)
+	require.Equal(t, []models.AlertRuleKey{rule.GetKey()}, writer.resets)
+}
@@ -2,6 +2,7 @@ package state
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
@@ -30,6 +31,16 @@ type Historian interface {
 	Record(ctx context.Context, rule history_model.RuleMeta, states []StateTransition) <-chan error
 }
 
+// AlertStateWriter writes the states of the alert instances of a rule to an external system after every evaluation.
+type AlertStateWriter interface {
+	// WriteAlertStates writes the current states of all alert instances of a rule after the evaluation at the given time.
+	// It must not block the evaluation of the rule.
+	WriteAlertStates(ctx context.Context, rule *models.AlertRule, evaluatedAt time.Time, states []*State)
+	// ResetAlertStates tells that the alert instances of a rule are removed at the given time, e.g. because the rule
+	// is deleted or paused. It must not block.
+	ResetAlertStates(ctx context.Context, key models.AlertRuleKey, resetAt time.Time)
+}
+
 // ImageCapturer captures images.
 //
 //go:generate mockgen -destination=image_mock.go -package=state github.com/grafana/grafana/pkg/services/ngalert/state ImageCapturer
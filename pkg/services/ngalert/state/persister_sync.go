@@ -102,6 +102,7 @@ func (a *SyncStatePersister) saveAlertStates(ctx context.Context, states ...Stat
 			LastEvalTime:      s.LastEvaluationTime,
 			CurrentStateSince: s.StartsAt,
 			CurrentStateEnd:   s.EndsAt,
+			KeepFiringSince:   s.KeepFiringSince,
 		}
 
 		err = a.store.SaveAlertInstance(ctx, instance)
@@ -68,7 +68,10 @@ type State struct {
 	// ResolvedAt is set when the state is first resolved. That is to say, when the state first transitions
 	// from Alerting, NoData, or Error to Normal. It is reset to zero when the state transitions from Normal
 	// to any other state.
-	ResolvedAt           *time.Time
+	ResolvedAt *time.Time
+	// KeepFiringSince is set when the condition of an Alerting state is no longer met, but the rule keeps the state
+	// Alerting for its KeepFiringFor duration. It is reset to nil when the condition is met again or the state changes.
+	KeepFiringSince      *time.Time
 	LastSentAt           *time.Time
 	LastEvaluationString string
 	LastEvaluationTime   time.Time
@@ -98,6 +101,7 @@ func (a *State) SetAlerting(reason string, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = nil
+	a.KeepFiringSince = nil
 }
 
 // SetPending the state to Pending. It changes both the start and end time.
@@ -107,6 +111,7 @@ func (a *State) SetPending(reason string, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = nil
+	a.KeepFiringSince = nil
 }
 
 // SetNoData sets the state to NoData. It changes both the start and end time.
@@ -116,6 +121,7 @@ func (a *State) SetNoData(reason string, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = nil
+	a.KeepFiringSince = nil
 }
 
 // SetError sets the state to Error. It changes both the start and end time.
@@ -125,6 +131,7 @@ func (a *State) SetError(err error, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = err
+	a.KeepFiringSince = nil
 }
 
 // SetNormal sets the state to Normal. It changes both the start and end time.
@@ -134,6 +141,7 @@ func (a *State) SetNormal(reason string, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = nil
+	a.KeepFiringSince = nil
 }
 
 // Maintain updates the end time using the most recent evaluation.
@@ -163,6 +171,16 @@ func (a *State) AddErrorAnnotations(err error, rule *models.AlertRule) {
 	}
 }
 
+// KeepFiringUntil returns the time until which the state keeps firing, if its condition is no longer met
+// but the rule keeps it firing.
+func (a *State) KeepFiringUntil(keepFiringFor time.Duration) *time.Time {
+	if a.State != eval.Alerting || a.KeepFiringSince == nil {
+		return nil
+	}
+	until := a.KeepFiringSince.Add(keepFiringFor)
+	return &until
+}
+
 // IsNormalStateWithNoReason returns true if the state is Normal and reason is empty
 func IsNormalStateWithNoReason(s *State) bool {
 	return s.State == eval.Normal && s.StateReason == ""
@@ -220,9 +238,21 @@ func NewEvaluationValues(m map[string]eval.NumberValueCapture) map[string]*float
 	return result
 }
 
-func resultNormal(state *State, _ *models.AlertRule, result eval.Result, logger log.Logger, reason string) {
+func resultNormal(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger, reason string) {
 	if state.State == eval.Normal {
 		logger.Debug("Keeping state", "state", state.State)
+	} else if state.State == eval.Alerting && keepFiring(state, rule, result.EvaluatedAt) {
+		prevEndsAt := state.EndsAt
+		state.Maintain(rule.IntervalSeconds, result.EvaluatedAt)
+		logger.Debug("Keeping state firing",
+			"state",
+			state.State,
+			"keep_firing_since",
+			*state.KeepFiringSince,
+			"previous_ends_at",
+			prevEndsAt,
+			"next_ends_at",
+			state.EndsAt)
 	} else {
 		nextEndsAt := result.EvaluatedAt
 		logger.Debug("Changing state",
@@ -239,10 +269,45 @@ func resultNormal(state *State, _ *models.AlertRule, result eval.Result, logger
 	}
 }
 
+// resultDependencyFailed keeps the current state because the rule was not evaluated, as a rule that it depends on failed.
+// The error of the dependency is kept in the state, so that it is visible why the rule was not evaluated.
+// States other than Normal are maintained, so that they do not expire in the Alertmanager while they are kept.
+func resultDependencyFailed(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger) {
+	prevEndsAt := state.EndsAt
+	if state.State != eval.Normal {
+		state.Maintain(rule.IntervalSeconds, result.EvaluatedAt)
+	}
+	logger.Debug("Keeping state",
+		"state",
+		state.State,
+		"reason",
+		models.StateReasonDependencyFailed,
+		"previous_ends_at",
+		prevEndsAt,
+		"next_ends_at",
+		state.EndsAt)
+	state.StateReason = models.StateReasonDependencyFailed
+	state.Error = result.Error
+	state.LastEvaluationTime = result.EvaluatedAt
+}
+
+// keepFiring returns true if the Alerting state should keep firing although the condition is no longer met,
+// because the KeepFiringFor duration of the rule has not passed yet since the condition was last met.
+func keepFiring(state *State, rule *models.AlertRule, evaluatedAt time.Time) bool {
+	if rule.KeepFiringFor <= 0 {
+		return false
+	}
+	if state.KeepFiringSince == nil {
+		state.KeepFiringSince = &evaluatedAt
+	}
+	return evaluatedAt.Sub(*state.KeepFiringSince) < rule.KeepFiringFor
+}
+
 func resultAlerting(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger, reason string) {
 	switch state.State {
 	case eval.Alerting:
 		prevEndsAt := state.EndsAt
+		state.KeepFiringSince = nil
 		state.Maintain(rule.IntervalSeconds, result.EvaluatedAt)
 		logger.Debug("Keeping state",
 			"state",
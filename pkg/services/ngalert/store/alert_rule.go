@@ -175,10 +175,13 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				NoDataState:          r.NoDataState,
 				ExecErrState:         r.ExecErrState,
 				For:                  r.For,
+				KeepFiringFor:        r.KeepFiringFor,
 				Annotations:          r.Annotations,
 				Labels:               r.Labels,
 				Record:               r.Record,
 				NotificationSettings: r.NotificationSettings,
+				DependsOn:            r.DependsOn,
+				Template:             r.Template,
 			})
 		}
 		if len(newRules) > 0 {
@@ -257,9 +260,12 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				ExecErrState:         r.New.ExecErrState,
 				Record:               r.New.Record,
 				For:                  r.New.For,
+				KeepFiringFor:        r.New.KeepFiringFor,
 				Annotations:          r.New.Annotations,
 				Labels:               r.New.Labels,
 				NotificationSettings: r.New.NotificationSettings,
+				DependsOn:            r.New.DependsOn,
+				Template:             r.New.Template,
 			})
 		}
 		if len(ruleVersions) > 0 {
@@ -405,6 +411,20 @@ func (st DBstore) ListAlertRules(ctx context.Context, query *ngmodels.ListAlertR
 			}
 		}
 
+		if query.TemplateUID != "" {
+			q, err = st.filterByTemplateUID(query.TemplateUID, q)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(query.DependsOnUIDs) > 0 {
+			q, err = st.filterByDependsOn(query.DependsOnUIDs, q)
+			if err != nil {
+				return err
+			}
+		}
+
 		q = q.Asc("namespace_uid", "rule_group", "rule_group_idx", "id")
 
 		alertRules := make([]*ngmodels.AlertRule, 0)
@@ -432,6 +452,14 @@ func (st DBstore) ListAlertRules(ctx context.Context, query *ngmodels.ListAlertR
 					continue
 				}
 			}
+			if query.TemplateUID != "" && (rule.Template == nil || rule.Template.UID != query.TemplateUID) { // remove false-positive hits from the result
+				continue
+			}
+			if len(query.DependsOnUIDs) > 0 && !slices.ContainsFunc(rule.DependsOn, func(uid string) bool { // remove false-positive hits from the result
+				return slices.Contains(query.DependsOnUIDs, uid)
+			}) {
+				continue
+			}
 			// MySQL (and potentially other databases) can use case-insensitive comparison.
 			// This code makes sure we return groups that only exactly match the filter.
 			if groupsMap != nil {
@@ -775,6 +803,40 @@ func (st DBstore) filterByReceiverName(receiver string, sess *xorm.Session) (*xo
 	return sess.And(fmt.Sprintf("notification_settings %s ?", st.SQLStore.GetDialect().LikeStr()), "%"+search+"%"), nil
 }
 
+func (st DBstore) filterByTemplateUID(uid string, sess *xorm.Session) (*xorm.Session, error) {
+	// marshall string according to JSON rules so we follow escaping rules.
+	b, err := json.Marshal(uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshall template UID query: %w", err)
+	}
+	var search = `"uid":` + string(b)
+	if st.SQLStore.GetDialect().DriverName() != migrator.SQLite {
+		// this escapes escaped double quote (\") to \\\"
+		search = strings.ReplaceAll(strings.ReplaceAll(search, `\`, `\\`), `"`, `\"`)
+	}
+	return sess.And(fmt.Sprintf("template_ref %s ?", st.SQLStore.GetDialect().LikeStr()), "%"+search+"%"), nil
+}
+
+func (st DBstore) filterByDependsOn(uids []string, sess *xorm.Session) (*xorm.Session, error) {
+	conditions := make([]string, 0, len(uids))
+	args := make([]any, 0, len(uids))
+	for _, uid := range uids {
+		// marshall string according to JSON rules so we follow escaping rules.
+		b, err := json.Marshal(uid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshall depends on query: %w", err)
+		}
+		var search = string(b)
+		if st.SQLStore.GetDialect().DriverName() != migrator.SQLite {
+			// this escapes escaped double quote (\") to \\\"
+			search = strings.ReplaceAll(strings.ReplaceAll(search, `\`, `\\`), `"`, `\"`)
+		}
+		conditions = append(conditions, fmt.Sprintf("depends_on %s ?", st.SQLStore.GetDialect().LikeStr()))
+		args = append(args, "%"+search+"%")
+	}
+	return sess.And("("+strings.Join(conditions, " OR ")+")", args...), nil
+}
+
 func (st DBstore) RenameReceiverInNotificationSettings(ctx context.Context, orgID int64, oldReceiver, newReceiver string) (int, error) {
 	// fetch entire rules because Update method requires it because it copies rules to version table
 	rules, err := st.ListAlertRules(ctx, &ngmodels.ListAlertRulesQuery{
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// RuleTemplateReader is a store that reads alert rule templates.
+type RuleTemplateReader interface {
+	GetAlertRuleTemplate(ctx context.Context, orgID int64, uid string) (*ngmodels.AlertRuleTemplate, error)
+}
+
+// ListAlertRuleTemplates returns all alert rule templates of the organization.
+func (st DBstore) ListAlertRuleTemplates(ctx context.Context, orgID int64) ([]*ngmodels.AlertRuleTemplate, error) {
+	var result []*ngmodels.AlertRuleTemplate
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("title").Find(&result)
+	})
+	return result, err
+}
+
+// GetAlertRuleTemplate returns the alert rule template by its UID, or ErrAlertRuleTemplateNotFound if it does not exist.
+func (st DBstore) GetAlertRuleTemplate(ctx context.Context, orgID int64, uid string) (*ngmodels.AlertRuleTemplate, error) {
+	var result *ngmodels.AlertRuleTemplate
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		template := ngmodels.AlertRuleTemplate{OrgID: orgID, UID: uid}
+		has, err := sess.Get(&template)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ngmodels.ErrAlertRuleTemplateNotFound
+		}
+		result = &template
+		return nil
+	})
+	return result, err
+}
+
+// InsertAlertRuleTemplate creates the alert rule template, and generates its UID if it is empty.
+func (st DBstore) InsertAlertRuleTemplate(ctx context.Context, template *ngmodels.AlertRuleTemplate) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if template.UID == "" {
+			template.UID = util.GenerateShortUID()
+		}
+		template.ID = 0
+		template.Version = 0 // xorm sets the initial version
+		template.Updated = TimeNow()
+		if _, err := sess.Insert(template); err != nil {
+			if st.SQLStore.GetDialect().IsUniqueConstraintViolation(err) {
+				return fmt.Errorf("%w: alert rule template with UID %s already exists", ngmodels.ErrAlertRuleTemplateFailedValidation, template.UID)
+			}
+			return fmt.Errorf("failed to create alert rule template: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateAlertRuleTemplate updates the alert rule template if its version is the latest one.
+func (st DBstore) UpdateAlertRuleTemplate(ctx context.Context, template *ngmodels.AlertRuleTemplate) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		template.Updated = TimeNow()
+		// xorm increases the version, and does not update the template if the version is not the current one.
+		updated, err := sess.Where("org_id = ? AND uid = ?", template.OrgID, template.UID).AllCols().Omit("id").Update(template)
+		if err != nil {
+			return fmt.Errorf("failed to update alert rule template %s: %w", template.UID, err)
+		}
+		if updated == 0 {
+			return fmt.Errorf("%w: alert rule template UID %s version %d", ErrOptimisticLock, template.UID, template.Version)
+		}
+		return nil
+	})
+}
+
+// DeleteAlertRuleTemplate deletes the alert rule template. It does nothing if the template does not exist.
+func (st DBstore) DeleteAlertRuleTemplate(ctx context.Context, orgID int64, uid string) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&ngmodels.AlertRuleTemplate{})
+		return err
+	})
+}
+
+// ApplyRuleTemplates replaces the fields of the rules that are derived from a template by the rendered template.
+// Rules without template are not changed.
+func ApplyRuleTemplates(ctx context.Context, reader RuleTemplateReader, rules ...*ngmodels.AlertRule) error {
+	templates := make(map[string]*ngmodels.AlertRuleTemplate)
+	for _, rule := range rules {
+		if rule.Template == nil {
+			continue
+		}
+		template, ok := templates[rule.Template.UID]
+		if !ok {
+			var err error
+			template, err = reader.GetAlertRuleTemplate(ctx, rule.OrgID, rule.Template.UID)
+			if err != nil {
+				if errors.Is(err, ngmodels.ErrAlertRuleTemplateNotFound) {
+					return fmt.Errorf("%w: template %s of rule %q does not exist", ngmodels.ErrAlertRuleFailedValidation, rule.Template.UID, rule.Title)
+				}
+				return err
+			}
+			templates[rule.Template.UID] = template
+		}
+		if err := template.Apply(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log/logtest"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationAlertRuleTemplates(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting = setting.UnifiedAlertingSettings{BaseInterval: 10 * time.Second}
+	sqlStore := db.InitTestDB(t)
+	store := &DBstore{
+		SQLStore:      sqlStore,
+		Cfg:           cfg.UnifiedAlerting,
+		FolderService: setupFolderService(t, sqlStore, cfg, featuremgmt.WithFeatures()),
+		Logger:        &logtest.Fake{},
+	}
+	ctx := context.Background()
+	newTemplate := func() *models.AlertRuleTemplate {
+		return &models.AlertRuleTemplate{
+			OrgID:      1,
+			Title:      "High CPU usage",
+			RuleTitle:  "High CPU usage on ${instance}",
+			Parameters: []models.AlertRuleTemplateParameter{{Name: "instance", Type: models.AlertRuleTemplateParameterString}},
+			Condition:  "A",
+			Data: []models.AlertQuery{{
+				RefID:         "A",
+				DatasourceUID: "__expr__",
+				Model:         json.RawMessage(`{"type":"math","expression":"1 == 1"}`),
+			}},
+			NoDataState:  models.NoData,
+			ExecErrState: models.ErrorErrState,
+			For:          time.Minute,
+			Labels:       map[string]string{"instance": "${instance}"},
+		}
+	}
+
+	t.Run("should create, update and delete templates", func(t *testing.T) {
+		template := newTemplate()
+		require.NoError(t, store.InsertAlertRuleTemplate(ctx, template))
+		require.NotEmpty(t, template.UID)
+		require.Equal(t, int64(1), template.Version)
+
+		stored, err := store.GetAlertRuleTemplate(ctx, 1, template.UID)
+		require.NoError(t, err)
+		require.Equal(t, template.Parameters, stored.Parameters)
+		require.Equal(t, template.Labels, stored.Labels)
+		require.Equal(t, time.Minute, stored.For)
+
+		stored.RuleTitle = "CPU usage is high on ${instance}"
+		require.NoError(t, store.UpdateAlertRuleTemplate(ctx, stored))
+		require.Equal(t, int64(2), stored.Version)
+
+		template.RuleTitle = "outdated"
+		require.ErrorIs(t, store.UpdateAlertRuleTemplate(ctx, template), ErrOptimisticLock)
+
+		templates, err := store.ListAlertRuleTemplates(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, templates, 1)
+		require.Equal(t, "CPU usage is high on ${instance}", templates[0].RuleTitle)
+
+		require.NoError(t, store.DeleteAlertRuleTemplate(ctx, 1, template.UID))
+		_, err = store.GetAlertRuleTemplate(ctx, 1, template.UID)
+		require.ErrorIs(t, err, models.ErrAlertRuleTemplateNotFound)
+	})
+
+	t.Run("should store the template of rules", func(t *testing.T) {
+		template := newTemplate()
+		require.NoError(t, store.InsertAlertRuleTemplate(ctx, template))
+
+		gen := models.RuleGen.With(models.RuleGen.WithIntervalMatching(store.Cfg.BaseInterval), models.RuleGen.WithOrgID(1))
+		rule := gen.Generate()
+		rule.Record = nil
+		rule.Template = &models.AlertRuleTemplateRef{UID: template.UID, Parameters: map[string]string{"instance": "web-1"}}
+		require.NoError(t, ApplyRuleTemplates(ctx, store, &rule))
+		require.Equal(t, "High CPU usage on web-1", rule.Title)
+
+		ids, err := store.InsertAlertRules(ctx, []models.AlertRule{rule})
+		require.NoError(t, err)
+		stored, err := store.GetAlertRuleByUID(ctx, &models.GetAlertRuleByUIDQuery{OrgID: 1, UID: ids[0].UID})
+		require.NoError(t, err)
+		require.Equal(t, rule.Template, stored.Template)
+
+		derived, err := store.ListAlertRules(ctx, &models.ListAlertRulesQuery{OrgID: 1, TemplateUID: template.UID})
+		require.NoError(t, err)
+		require.Len(t, derived, 1)
+		require.Equal(t, ids[0].UID, derived[0].UID)
+
+		derived, err = store.ListAlertRules(ctx, &models.ListAlertRulesQuery{OrgID: 1, TemplateUID: template.UID[:len(template.UID)-1]})
+		require.NoError(t, err)
+		require.Empty(t, derived)
+
+		rule.Template = &models.AlertRuleTemplateRef{UID: "missing"}
+		require.ErrorIs(t, ApplyRuleTemplates(ctx, store, &rule), models.ErrAlertRuleFailedValidation)
+	})
+}
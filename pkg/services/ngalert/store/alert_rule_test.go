@@ -1133,3 +1133,46 @@ func setupFolderService(t *testing.T, sqlStore db.DB, cfg *setting.Cfg, features
 
 	return testutil.SetupFolderService(t, cfg, sqlStore, dashboardStore, folderStore, inProcBus, features, &actest.FakeAccessControl{})
 }
+
+func TestIntegrationListAlertRulesByDependsOn(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting.BaseInterval = 1 * time.Second
+	store := &DBstore{
+		SQLStore:      sqlStore,
+		FolderService: setupFolderService(t, sqlStore, cfg, featuremgmt.WithFeatures()),
+		Logger:        log.New("test-dbstore"),
+		Cfg:           cfg.UnifiedAlerting,
+	}
+
+	gen := models.RuleGen
+	gen = gen.With(gen.WithOrgID(1), gen.WithIntervalMatching(store.Cfg.BaseInterval))
+	upstream := createRule(t, store, gen)
+	other := createRule(t, store, gen)
+	dependent := createRule(t, store, gen.With(gen.WithDependsOn(upstream.UID, other.UID)))
+	// the UID of the upstream rule is a prefix of the UID of the dependency of this rule.
+	noise := createRule(t, store, gen.With(gen.WithDependsOn(upstream.UID+"-other")))
+
+	actual, err := store.ListAlertRules(context.Background(), &models.ListAlertRulesQuery{
+		OrgID:         1,
+		DependsOnUIDs: []string{upstream.UID},
+	})
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	require.Equal(t, dependent.UID, actual[0].UID)
+
+	actual, err = store.ListAlertRules(context.Background(), &models.ListAlertRulesQuery{
+		OrgID:         1,
+		DependsOnUIDs: []string{other.UID, upstream.UID + "-other"},
+	})
+	require.NoError(t, err)
+	uids := make([]string, 0, len(actual))
+	for _, rule := range actual {
+		uids = append(uids, rule.UID)
+	}
+	require.ElementsMatch(t, []string{dependent.UID, noise.UID}, uids)
+}
@@ -3,6 +3,8 @@ package store
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/util/cmputil"
@@ -154,6 +156,151 @@ func calculateChanges(ctx context.Context, ruleReader RuleReader, groupKey model
 	}, nil
 }
 
+// ValidateDependencies checks that the rules that the new and updated rules of the delta depend on exist in the organization,
+// are not deleted by the delta, that the rules deleted by the delta have no dependents, and that the rules do not depend
+// on each other in a cycle.
+func ValidateDependencies(ctx context.Context, ruleReader RuleReader, delta *GroupDelta) error {
+	rules := make(map[string]*models.AlertRule, len(delta.New)+len(delta.Update))
+	changed := make([]*models.AlertRule, 0, len(delta.New)+len(delta.Update))
+	changed = append(changed, delta.New...)
+	for _, upd := range delta.Update {
+		changed = append(changed, upd.New)
+	}
+	for _, rule := range changed {
+		if rule.UID != "" {
+			rules[rule.UID] = rule
+		}
+	}
+	deleted := make(map[string]struct{}, len(delta.Delete))
+	for _, rule := range delta.Delete {
+		deleted[rule.UID] = struct{}{}
+	}
+
+	// load the dependencies transitively, to find cycles that go through rules that are not changed.
+	missing := make(map[string]struct{})
+	toLoad := make([]string, 0)
+	for _, rule := range changed {
+		toLoad = append(toLoad, rule.DependsOn...)
+	}
+	for len(toLoad) > 0 {
+		uids := make([]string, 0, len(toLoad))
+		for _, uid := range toLoad {
+			if _, ok := rules[uid]; ok {
+				continue
+			}
+			if _, ok := deleted[uid]; ok {
+				continue
+			}
+			if _, ok := missing[uid]; ok {
+				continue
+			}
+			missing[uid] = struct{}{}
+			uids = append(uids, uid)
+		}
+		toLoad = toLoad[:0]
+		if len(uids) == 0 {
+			break
+		}
+		loaded, err := ruleReader.ListAlertRules(ctx, &models.ListAlertRulesQuery{OrgID: delta.GroupKey.OrgID, RuleUIDs: uids})
+		if err != nil {
+			return fmt.Errorf("failed to query database for rules that the rules depend on: %w", err)
+		}
+		for _, rule := range loaded {
+			delete(missing, rule.UID)
+			rules[rule.UID] = rule
+			toLoad = append(toLoad, rule.DependsOn...)
+		}
+	}
+
+	for _, rule := range changed {
+		for _, uid := range rule.DependsOn {
+			if _, ok := rules[uid]; !ok {
+				return fmt.Errorf("%w: rule '%s' depends on rule %s that does not exist", models.ErrAlertRuleFailedValidation, rule.Title, uid)
+			}
+		}
+	}
+
+	// the deleted rules cannot have dependents that are not changed by the delta.
+	if err := ValidateNoDependents(ctx, ruleReader, delta.GroupKey.OrgID, delta.Delete, changed...); err != nil {
+		return err
+	}
+
+	// depth-first search of the dependencies of the changed rules, a rule that is visited again on the path closes a cycle.
+	const (
+		visiting = iota + 1
+		visited
+	)
+	marks := make(map[string]int, len(rules))
+	var visit func(uid string, path []string) error
+	visit = func(uid string, path []string) error {
+		switch marks[uid] {
+		case visiting:
+			return fmt.Errorf("%w: rules depend on each other in a cycle: %s", models.ErrAlertRuleFailedValidation, strings.Join(append(path, uid), " -> "))
+		case visited:
+			return nil
+		}
+		rule, ok := rules[uid]
+		if !ok {
+			return nil
+		}
+		marks[uid] = visiting
+		for _, dep := range rule.DependsOn {
+			if err := visit(dep, append(path, uid)); err != nil {
+				return err
+			}
+		}
+		marks[uid] = visited
+		return nil
+	}
+	for _, rule := range changed {
+		if rule.UID == "" {
+			continue
+		}
+		if err := visit(rule.UID, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateNoDependents checks that no rules of the organization depend on the deleted rules, apart from the deleted rules themselves.
+// The dependencies of the rules that are updated along with the deletion are checked as they are after the update.
+func ValidateNoDependents(ctx context.Context, ruleReader RuleReader, orgID int64, deleted []*models.AlertRule, updated ...*models.AlertRule) error {
+	if len(deleted) == 0 {
+		return nil
+	}
+	deletedUIDs := make([]string, 0, len(deleted))
+	for _, rule := range deleted {
+		deletedUIDs = append(deletedUIDs, rule.UID)
+	}
+	updatedByUID := make(map[string]*models.AlertRule, len(updated))
+	for _, rule := range updated {
+		updatedByUID[rule.UID] = rule
+	}
+	dependents, err := ruleReader.ListAlertRules(ctx, &models.ListAlertRulesQuery{OrgID: orgID, DependsOnUIDs: deletedUIDs})
+	if err != nil {
+		return fmt.Errorf("failed to query database for rules that depend on the deleted rules: %w", err)
+	}
+	for _, uid := range deletedUIDs {
+		var found []string
+		for _, dependent := range dependents {
+			if slices.Contains(deletedUIDs, dependent.UID) {
+				continue
+			}
+			if upd, ok := updatedByUID[dependent.UID]; ok {
+				dependent = upd
+			}
+			if slices.Contains(dependent.DependsOn, uid) {
+				found = append(found, dependent.UID)
+			}
+		}
+		if len(found) > 0 {
+			return models.ErrAlertRuleHasDependents(uid, found)
+		}
+	}
+	return nil
+}
+
 // UpdateCalculatedRuleFields refreshes the calculated fields in a set of alert rule changes.
 // This may generate new changes to keep a group consistent, such as versions or rule indexes.
 func UpdateCalculatedRuleFields(ch *GroupDelta) *GroupDelta {
@@ -595,6 +595,77 @@ func TestCalculateRuleCreate(t *testing.T) {
 	})
 }
 
+func TestValidateDependencies(t *testing.T) {
+	orgID := int64(rand.Int31())
+	gen := models.RuleGen.With(models.RuleGen.WithOrgID(orgID))
+	groupKey := models.GenerateGroupKey(orgID)
+
+	setup := func(t *testing.T) (*fakes.RuleStore, *models.AlertRule, *models.AlertRule) {
+		t.Helper()
+		fakeStore := fakes.NewRuleStore(t)
+		upstream := gen.GenerateRef()
+		dependent := gen.With(gen.WithDependsOn(upstream.UID)).GenerateRef()
+		fakeStore.PutRule(context.Background(), upstream, dependent)
+		return fakeStore, upstream, dependent
+	}
+
+	t.Run("accepts rules that depend on existing rules", func(t *testing.T) {
+		fakeStore, _, dependent := setup(t)
+		rule := gen.With(gen.WithGroupKey(groupKey), gen.WithDependsOn(dependent.UID)).GenerateRef()
+		require.NoError(t, ValidateDependencies(context.Background(), fakeStore, &GroupDelta{GroupKey: groupKey, New: []*models.AlertRule{rule}}))
+	})
+
+	t.Run("rejects rules that depend on rules that do not exist", func(t *testing.T) {
+		fakeStore, _, _ := setup(t)
+		rule := gen.With(gen.WithGroupKey(groupKey), gen.WithDependsOn("missing")).GenerateRef()
+		err := ValidateDependencies(context.Background(), fakeStore, &GroupDelta{GroupKey: groupKey, New: []*models.AlertRule{rule}})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "missing")
+	})
+
+	t.Run("rejects rules that depend on deleted rules", func(t *testing.T) {
+		fakeStore, upstream, dependent := setup(t)
+		err := ValidateDependencies(context.Background(), fakeStore, &GroupDelta{
+			GroupKey: groupKey,
+			Update:   []RuleDelta{{Existing: dependent, New: dependent}},
+			Delete:   []*models.AlertRule{upstream},
+		})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("rejects deleting rules that other rules depend on", func(t *testing.T) {
+		fakeStore, upstream, dependent := setup(t)
+		err := ValidateDependencies(context.Background(), fakeStore, &GroupDelta{
+			GroupKey: groupKey,
+			Delete:   []*models.AlertRule{upstream},
+		})
+		require.ErrorIs(t, err, models.ErrAlertRuleHasDependentsBase)
+		require.ErrorContains(t, err, dependent.UID)
+
+		// the dependents can be deleted or stop depending on the rule at the same time.
+		require.NoError(t, ValidateDependencies(context.Background(), fakeStore, &GroupDelta{
+			GroupKey: groupKey,
+			Delete:   []*models.AlertRule{upstream, dependent},
+		}))
+		require.NoError(t, ValidateDependencies(context.Background(), fakeStore, &GroupDelta{
+			GroupKey: groupKey,
+			Update:   []RuleDelta{{Existing: dependent, New: models.CopyRule(dependent, gen.WithDependsOn())}},
+			Delete:   []*models.AlertRule{upstream},
+		}))
+	})
+
+	t.Run("rejects dependency cycles through stored rules", func(t *testing.T) {
+		fakeStore, upstream, dependent := setup(t)
+		updated := models.CopyRule(upstream, gen.WithDependsOn(dependent.UID))
+		err := ValidateDependencies(context.Background(), fakeStore, &GroupDelta{
+			GroupKey: groupKey,
+			Update:   []RuleDelta{{Existing: upstream, New: updated}},
+		})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, fmt.Sprintf("%s -> %s -> %s", upstream.UID, dependent.UID, upstream.UID))
+	})
+}
+
 // simulateSubmitted resets some fields of the structure that are not populated by API model to model conversion
 func simulateSubmitted(rule *models.AlertRule) {
 	rule.ID = 0
@@ -114,6 +114,47 @@ func TestIntegrationAlertInstanceOperations(t *testing.T) {
 		require.Equal(t, alertRule1.OrgID, alerts[0].RuleOrgID)
 		require.Equal(t, alertRule1.UID, alerts[0].RuleUID)
 		require.Equal(t, instance.CurrentReason, alerts[0].CurrentReason)
+		require.Nil(t, alerts[0].KeepFiringSince)
+	})
+
+	t.Run("can save and read the time since an alert instance keeps firing", func(t *testing.T) {
+		labels := models.InstanceLabels{"test": "keepFiring"}
+		_, hash, _ := labels.StringAndHash()
+		keepFiringSince := time.Unix(1700000000, 0)
+		instance := models.AlertInstance{
+			AlertInstanceKey: models.AlertInstanceKey{
+				RuleOrgID:  alertRule1.OrgID,
+				RuleUID:    alertRule1.UID,
+				LabelsHash: hash,
+			},
+			CurrentState:    models.InstanceStateFiring,
+			CurrentReason:   models.ConcatReasons(string(models.InstanceStateNormal), models.StateReasonKeepFiring),
+			Labels:          labels,
+			KeepFiringSince: &keepFiringSince,
+		}
+		require.NoError(t, dbstore.SaveAlertInstance(ctx, instance))
+
+		alerts, err := dbstore.ListAlertInstances(ctx, &models.ListAlertInstancesQuery{RuleOrgID: instance.RuleOrgID, RuleUID: instance.RuleUID})
+		require.NoError(t, err)
+		var saved *models.AlertInstance
+		for _, a := range alerts {
+			if a.LabelsHash == hash {
+				saved = a
+			}
+		}
+		require.NotNil(t, saved)
+		require.NotNil(t, saved.KeepFiringSince)
+		require.True(t, keepFiringSince.Equal(*saved.KeepFiringSince))
+
+		instance.CurrentReason = ""
+		instance.KeepFiringSince = nil
+		require.NoError(t, dbstore.SaveAlertInstance(ctx, instance))
+		alerts, err = dbstore.ListAlertInstances(ctx, &models.ListAlertInstancesQuery{RuleOrgID: instance.RuleOrgID, RuleUID: instance.RuleUID})
+		require.NoError(t, err)
+		for _, a := range alerts {
+			require.Nil(t, a.KeepFiringSince)
+		}
+		require.NoError(t, dbstore.DeleteAlertInstances(ctx, instance.AlertInstanceKey))
 	})
 
 	t.Run("can save and read new alert instance with no labels", func(t *testing.T) {
@@ -25,6 +25,7 @@ type RuleStore struct {
 	Hook        func(cmd any) error // use Hook if you need to intercept some query and return an error
 	RecordedOps []any
 	Folders     map[int64][]*folder.Folder
+	Templates   map[int64][]*models.AlertRuleTemplate
 }
 
 type GenericRecordedQuery struct {
@@ -39,7 +40,8 @@ func NewRuleStore(t *testing.T) *RuleStore {
 		Hook: func(any) error {
 			return nil
 		},
-		Folders: map[int64][]*folder.Folder{},
+		Folders:   map[int64][]*folder.Folder{},
+		Templates: map[int64][]*models.AlertRuleTemplate{},
 	}
 }
 
@@ -144,6 +146,83 @@ func (f *RuleStore) GetAlertRuleByUID(_ context.Context, q *models.GetAlertRuleB
 	return nil, models.ErrAlertRuleNotFound
 }
 
+func (f *RuleStore) GetAlertRuleTemplate(_ context.Context, orgID int64, uid string) (*models.AlertRuleTemplate, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	q := GenericRecordedQuery{Name: "GetAlertRuleTemplate", Params: []any{orgID, uid}}
+	f.RecordedOps = append(f.RecordedOps, q)
+	if err := f.Hook(q); err != nil {
+		return nil, err
+	}
+	for _, template := range f.Templates[orgID] {
+		if template.UID == uid {
+			return template, nil
+		}
+	}
+	return nil, models.ErrAlertRuleTemplateNotFound
+}
+
+func (f *RuleStore) ListAlertRuleTemplates(_ context.Context, orgID int64) ([]*models.AlertRuleTemplate, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	q := GenericRecordedQuery{Name: "ListAlertRuleTemplates", Params: []any{orgID}}
+	f.RecordedOps = append(f.RecordedOps, q)
+	if err := f.Hook(q); err != nil {
+		return nil, err
+	}
+	return append([]*models.AlertRuleTemplate(nil), f.Templates[orgID]...), nil
+}
+
+func (f *RuleStore) InsertAlertRuleTemplate(_ context.Context, template *models.AlertRuleTemplate) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.RecordedOps = append(f.RecordedOps, *template)
+	if err := f.Hook(*template); err != nil {
+		return err
+	}
+	if template.UID == "" {
+		template.UID = util.GenerateShortUID()
+	}
+	template.Version = 1
+	f.Templates[template.OrgID] = append(f.Templates[template.OrgID], template)
+	return nil
+}
+
+func (f *RuleStore) UpdateAlertRuleTemplate(_ context.Context, template *models.AlertRuleTemplate) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.RecordedOps = append(f.RecordedOps, *template)
+	if err := f.Hook(*template); err != nil {
+		return err
+	}
+	for i, existing := range f.Templates[template.OrgID] {
+		if existing.UID == template.UID {
+			template.Version++
+			f.Templates[template.OrgID][i] = template
+			return nil
+		}
+	}
+	return models.ErrAlertRuleTemplateNotFound
+}
+
+func (f *RuleStore) DeleteAlertRuleTemplate(_ context.Context, orgID int64, uid string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	q := GenericRecordedQuery{Name: "DeleteAlertRuleTemplate", Params: []any{orgID, uid}}
+	f.RecordedOps = append(f.RecordedOps, q)
+	if err := f.Hook(q); err != nil {
+		return err
+	}
+	templates := f.Templates[orgID]
+	for i, existing := range templates {
+		if existing.UID == uid {
+			f.Templates[orgID] = append(templates[:i], templates[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
 func (f *RuleStore) GetAlertRulesGroupByRuleUID(_ context.Context, q *models.GetAlertRulesGroupByRuleUIDQuery) ([]*models.AlertRule, error) {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
@@ -213,6 +292,12 @@ func (f *RuleStore) ListAlertRules(_ context.Context, q *models.ListAlertRulesQu
 		if len(q.RuleUIDs) > 0 && !slices.Contains(q.RuleUIDs, r.UID) {
 			continue
 		}
+		if q.TemplateUID != "" && (r.Template == nil || r.Template.UID != q.TemplateUID) {
+			continue
+		}
+		if len(q.DependsOnUIDs) > 0 && !slices.ContainsFunc(r.DependsOn, func(uid string) bool { return slices.Contains(q.DependsOnUIDs, uid) }) {
+			continue
+		}
 
 		ruleList = append(ruleList, r)
 	}
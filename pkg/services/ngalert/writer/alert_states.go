@@ -0,0 +1,241 @@
+package writer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m3db/prometheus_remote_client_golang/promremote"
+	"github.com/prometheus/prometheus/model/labels"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// AlertsMetricName is the name of the series that tells the state of every pending or firing alert, like in Prometheus.
+	AlertsMetricName = "ALERTS"
+	// AlertsForStateMetricName is the name of the series whose value is the time an alert became active, like in Prometheus.
+	AlertsForStateMetricName = "ALERTS_FOR_STATE"
+	// AlertStateLabel is the label of the ALERTS series that tells whether an alert is pending or firing.
+	AlertStateLabel = "alertstate"
+
+	alertStatePending = "pending"
+	alertStateFiring  = "firing"
+
+	alertStatesWriteTimeout = time.Minute
+)
+
+// staleNaN is the value that marks a series as stale in Prometheus.
+var staleNaN = math.Float64frombits(0x7ff0000000000002)
+
+// PrometheusAlertStateWriter is a state.AlertStateWriter that remote-writes the ALERTS and ALERTS_FOR_STATE series of
+// the alert instances of Grafana-managed rules after every evaluation, in the same way as Prometheus does for its own alerts.
+// Series of alerts that are not active anymore are marked as stale.
+type PrometheusAlertStateWriter struct {
+	client  promremote.Client
+	logger  log.Logger
+	metrics *metrics.RemoteWriter
+
+	mtx sync.Mutex
+	// active has the labels of the series that were written at the last evaluation of every rule.
+	active map[models.AlertRuleKey]map[string][]promremote.Label
+	// queues has the writes of every rule that have not started yet. The writes of a rule are done in order by a
+	// single goroutine, which exits when the queue of the rule is empty.
+	queues map[models.AlertRuleKey][]alertStatesWrite
+}
+
+type alertStatesWrite struct {
+	span   trace.Span
+	series []promremote.TimeSeries
+}
+
+func NewPrometheusAlertStateWriter(
+	settings setting.RecordingRuleSettings,
+	httpClientProvider HttpClientProvider,
+	tracer tracing.Tracer,
+	l log.Logger,
+	metrics *metrics.RemoteWriter,
+) (*PrometheusAlertStateWriter, error) {
+	client, err := newRemoteWriteClient(settings, "grafana-alert-states", httpClientProvider, tracer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrometheusAlertStateWriter{
+		client:  client,
+		logger:  l,
+		metrics: metrics,
+		active:  make(map[models.AlertRuleKey]map[string][]promremote.Label),
+		queues:  make(map[models.AlertRuleKey][]alertStatesWrite),
+	}, nil
+}
+
+// WriteAlertStates writes the series of the current states of all alert instances of a rule in the background.
+// The series of a rule are written in the order of the evaluations.
+func (w *PrometheusAlertStateWriter) WriteAlertStates(ctx context.Context, rule *models.AlertRule, evaluatedAt time.Time, states []*state.State) {
+	w.enqueue(ctx, rule.GetKey(), w.seriesForStates(rule.GetKey(), evaluatedAt, states))
+}
+
+// ResetAlertStates marks the series of the active alerts of a rule as stale in the background, and forgets them.
+// It is used when the state of the rule is deleted, e.g. because the rule is deleted or paused.
+func (w *PrometheusAlertStateWriter) ResetAlertStates(ctx context.Context, key models.AlertRuleKey, resetAt time.Time) {
+	w.enqueue(ctx, key, w.seriesForStates(key, resetAt, nil))
+}
+
+// enqueue adds the series to the queue of the rule, and starts writing the queue if it is not written already.
+func (w *PrometheusAlertStateWriter) enqueue(ctx context.Context, key models.AlertRuleKey, series []promremote.TimeSeries) {
+	if len(series) == 0 {
+		return
+	}
+
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	queue, running := w.queues[key]
+	w.queues[key] = append(queue, alertStatesWrite{span: trace.SpanFromContext(ctx), series: series})
+	if !running {
+		go w.writeQueue(key)
+	}
+}
+
+// writeQueue writes the queued series of the rule one after the other until the queue is empty.
+func (w *PrometheusAlertStateWriter) writeQueue(key models.AlertRuleKey) {
+	for {
+		w.mtx.Lock()
+		queue := w.queues[key]
+		if len(queue) == 0 {
+			delete(w.queues, key)
+			w.mtx.Unlock()
+			return
+		}
+		next := queue[0]
+		w.queues[key] = queue[1:]
+		w.mtx.Unlock()
+
+		// Like state history, the write is isolated from the evaluation so that it is not interrupted when the evaluation finishes.
+		ctx, cancel := context.WithTimeout(context.Background(), alertStatesWriteTimeout)
+		ctx = models.WithRuleKey(ctx, key)
+		ctx = trace.ContextWithSpan(ctx, next.span)
+		if err := w.write(ctx, key.OrgID, next.series); err != nil {
+			w.logger.FromContext(ctx).Error("Failed to write alert states", "error", err)
+		}
+		cancel()
+	}
+}
+
+func (w *PrometheusAlertStateWriter) write(ctx context.Context, orgID int64, series []promremote.TimeSeries) error {
+	l := w.logger.FromContext(ctx)
+	lvs := []string{fmt.Sprint(orgID), backendType}
+
+	l.Debug("Writing alert states", "series", len(series))
+	writeStart := time.Now()
+	res, writeErr := w.client.WriteTimeSeries(ctx, series, promremote.WriteOptions{})
+	w.metrics.WriteDuration.WithLabelValues(lvs...).Observe(time.Since(writeStart).Seconds())
+
+	lvs = append(lvs, fmt.Sprint(res.StatusCode))
+	w.metrics.WritesTotal.WithLabelValues(lvs...).Inc()
+
+	if err, ignored := checkWriteError(writeErr); err != nil {
+		return fmt.Errorf("failed to write time series: %w", err)
+	} else if ignored {
+		l.Debug("Ignored write error", "error", writeErr, "status_code", res.StatusCode)
+	}
+	return nil
+}
+
+// seriesForStates returns the series of the pending and firing alerts of the rule, and stale markers for the series
+// that were written at the previous evaluation of the rule but are not active anymore. The states must be all the
+// states of the rule, otherwise the series of the missing active alerts are marked as stale.
+func (w *PrometheusAlertStateWriter) seriesForStates(key models.AlertRuleKey, evaluatedAt time.Time, states []*state.State) []promremote.TimeSeries {
+	active := make(map[string][]promremote.Label)
+	series := make([]promremote.TimeSeries, 0, 2*len(states))
+	add := func(lbls []promremote.Label, value float64) {
+		active[seriesKey(lbls)] = lbls
+		series = append(series, promremote.TimeSeries{
+			Labels:    lbls,
+			Datapoint: promremote.Datapoint{Timestamp: evaluatedAt, Value: value},
+		})
+	}
+	for _, s := range states {
+		alertState, ok := alertStateOf(s)
+		if !ok {
+			continue
+		}
+		alert := state.StateToPostableAlert(state.StateTransition{State: s}, nil)
+		lbls := make(map[string]string, len(alert.Labels))
+		for k, v := range alert.Labels {
+			// Private labels, such as the UID of the rule, are reserved by Prometheus.
+			if strings.HasPrefix(k, "__") {
+				continue
+			}
+			lbls[k] = v
+		}
+		add(seriesLabels(AlertsForStateMetricName, lbls), float64(s.StartsAt.Unix()))
+		lbls[AlertStateLabel] = alertState
+		add(seriesLabels(AlertsMetricName, lbls), 1)
+	}
+
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	for k, lbls := range w.active[key] {
+		if _, ok := active[k]; ok {
+			continue
+		}
+		series = append(series, promremote.TimeSeries{
+			Labels:    lbls,
+			Datapoint: promremote.Datapoint{Timestamp: evaluatedAt, Value: staleNaN},
+		})
+	}
+	if len(active) == 0 {
+		delete(w.active, key)
+	} else {
+		w.active[key] = active
+	}
+	return series
+}
+
+// alertStateOf returns the value of the alertstate label of the alert of the state, or false if the alert is not active.
+func alertStateOf(s *state.State) (string, bool) {
+	switch s.State {
+	case eval.Pending:
+		return alertStatePending, true
+	case eval.Alerting, eval.Error, eval.NoData:
+		// Error and NoData states are sent to the Alertmanager as firing alerts.
+		return alertStateFiring, true
+	default:
+		return "", false
+	}
+}
+
+func seriesLabels(name string, lbls map[string]string) []promremote.Label {
+	result := make([]promremote.Label, 0, len(lbls)+1)
+	result = append(result, promremote.Label{Name: labels.MetricName, Value: name})
+	for k, v := range lbls {
+		result = append(result, promremote.Label{Name: k, Value: v})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func seriesKey(lbls []promremote.Label) string {
+	b := strings.Builder{}
+	for _, l := range lbls {
+		b.WriteString(l.Name)
+		b.WriteByte(0xff)
+		b.WriteString(l.Value)
+		b.WriteByte(0xff)
+	}
+	return b.String()
+}
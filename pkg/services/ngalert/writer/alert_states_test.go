@@ -0,0 +1,174 @@
+package writer
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/m3db/prometheus_remote_client_golang/promremote"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestPrometheusAlertStateWriter(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	startsAt := now.Add(-time.Minute)
+	key := ngmodels.GenerateRuleKey(1)
+	newWriter := func(client *testClient) *PrometheusAlertStateWriter {
+		return &PrometheusAlertStateWriter{
+			client:  client,
+			logger:  log.New("test"),
+			metrics: metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()),
+			active:  make(map[ngmodels.AlertRuleKey]map[string][]promremote.Label),
+			queues:  make(map[ngmodels.AlertRuleKey][]alertStatesWrite),
+		}
+	}
+	newState := func(s eval.State, lbls data.Labels) *state.State {
+		return &state.State{State: s, Labels: lbls, StartsAt: startsAt}
+	}
+	values := func(series []promremote.TimeSeries) map[string]float64 {
+		result := make(map[string]float64, len(series))
+		for _, s := range series {
+			require.Equal(t, now, s.Datapoint.Timestamp)
+			result[seriesKey(s.Labels)] = s.Datapoint.Value
+		}
+		return result
+	}
+	idOf := func(name string, lbls map[string]string) string {
+		return seriesKey(seriesLabels(name, lbls))
+	}
+
+	t.Run("writes series of pending and firing alerts", func(t *testing.T) {
+		w := newWriter(&testClient{})
+		series := w.seriesForStates(key, now, []*state.State{
+			newState(eval.Pending, data.Labels{"alertname": "a", "instance": "1", "__alert_rule_uid__": "uid"}),
+			newState(eval.Alerting, data.Labels{"alertname": "a", "instance": "2"}),
+			newState(eval.Normal, data.Labels{"alertname": "a", "instance": "3"}),
+		})
+
+		require.Equal(t, map[string]float64{
+			idOf(AlertsMetricName, map[string]string{"alertname": "a", "instance": "1", AlertStateLabel: "pending"}): 1,
+			idOf(AlertsForStateMetricName, map[string]string{"alertname": "a", "instance": "1"}):                     float64(startsAt.Unix()),
+			idOf(AlertsMetricName, map[string]string{"alertname": "a", "instance": "2", AlertStateLabel: "firing"}):  1,
+			idOf(AlertsForStateMetricName, map[string]string{"alertname": "a", "instance": "2"}):                     float64(startsAt.Unix()),
+		}, values(series))
+	})
+
+	t.Run("writes error alerts like they are sent to the Alertmanager", func(t *testing.T) {
+		w := newWriter(&testClient{})
+		series := w.seriesForStates(key, now, []*state.State{
+			newState(eval.Error, data.Labels{"alertname": "a"}),
+		})
+
+		require.Contains(t, values(series), idOf(AlertsMetricName, map[string]string{
+			"alertname": state.ErrorAlertName, state.Rulename: "a", AlertStateLabel: "firing",
+		}))
+	})
+
+	t.Run("marks series of alerts that are not active anymore as stale", func(t *testing.T) {
+		w := newWriter(&testClient{})
+		_ = w.seriesForStates(key, now, []*state.State{
+			newState(eval.Pending, data.Labels{"alertname": "a"}),
+		})
+		series := w.seriesForStates(key, now, []*state.State{
+			newState(eval.Alerting, data.Labels{"alertname": "a"}),
+		})
+
+		v := values(series)
+		require.Len(t, v, 3)
+		require.Equal(t, 1.0, v[idOf(AlertsMetricName, map[string]string{"alertname": "a", AlertStateLabel: "firing"})])
+		stale := v[idOf(AlertsMetricName, map[string]string{"alertname": "a", AlertStateLabel: "pending"})]
+		require.Equal(t, math.Float64bits(staleNaN), math.Float64bits(stale))
+
+		series = w.seriesForStates(key, now, []*state.State{
+			newState(eval.Normal, data.Labels{"alertname": "a"}),
+		})
+		require.Len(t, series, 2)
+		for _, s := range series {
+			require.Equal(t, math.Float64bits(staleNaN), math.Float64bits(s.Datapoint.Value))
+		}
+		require.Empty(t, w.active)
+	})
+
+	t.Run("marks all series of a rule as stale when it is reset", func(t *testing.T) {
+		w := newWriter(&testClient{})
+		_ = w.seriesForStates(key, now, []*state.State{
+			newState(eval.Alerting, data.Labels{"alertname": "a"}),
+		})
+		series := w.seriesForStates(key, now, nil)
+		require.Len(t, series, 2)
+		for _, s := range series {
+			require.Equal(t, math.Float64bits(staleNaN), math.Float64bits(s.Datapoint.Value))
+		}
+		require.Empty(t, w.active)
+	})
+
+	t.Run("writes the series of a rule in order", func(t *testing.T) {
+		release := make(chan struct{})
+		written := make(chan float64, 3)
+		client := &testClient{
+			writeSeriesFunc: func(ctx context.Context, ts promremote.TSList, opts promremote.WriteOptions) (promremote.WriteResult, promremote.WriteError) {
+				<-release
+				for _, s := range ts {
+					if s.Labels[0].Value == AlertsForStateMetricName {
+						written <- s.Datapoint.Value
+					}
+				}
+				return promremote.WriteResult{}, nil
+			},
+		}
+		w := newWriter(client)
+		rule := ngmodels.RuleGen.GenerateRef()
+		for i := 0; i < 3; i++ {
+			s := newState(eval.Alerting, data.Labels{"alertname": "a"})
+			s.StartsAt = startsAt.Add(time.Duration(i) * time.Second)
+			w.WriteAlertStates(context.Background(), rule, now, []*state.State{s})
+		}
+		close(release)
+
+		for i := 0; i < 3; i++ {
+			select {
+			case v := <-written:
+				require.Equal(t, float64(startsAt.Add(time.Duration(i)*time.Second).Unix()), v)
+			case <-time.After(5 * time.Second):
+				require.Fail(t, "series were not written")
+			}
+		}
+		require.Eventually(t, func() bool {
+			w.mtx.Lock()
+			defer w.mtx.Unlock()
+			return len(w.queues) == 0
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("writes series in the background", func(t *testing.T) {
+		written := make(chan promremote.TSList, 1)
+		client := &testClient{
+			writeSeriesFunc: func(ctx context.Context, ts promremote.TSList, opts promremote.WriteOptions) (promremote.WriteResult, promremote.WriteError) {
+				written <- ts
+				return promremote.WriteResult{}, nil
+			},
+		}
+		w := newWriter(client)
+		rule := ngmodels.RuleGen.GenerateRef()
+
+		w.WriteAlertStates(context.Background(), rule, now, []*state.State{
+			newState(eval.Alerting, data.Labels{"alertname": "a"}),
+		})
+
+		select {
+		case ts := <-written:
+			require.Len(t, ts, 2)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "series were not written")
+		}
+	})
+}
@@ -109,6 +109,20 @@ func NewPrometheusWriter(
 	l log.Logger,
 	metrics *metrics.RemoteWriter,
 ) (*PrometheusWriter, error) {
+	client, err := newRemoteWriteClient(settings, "grafana-recording-rule", httpClientProvider, tracer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrometheusWriter{
+		client:  client,
+		logger:  l,
+		metrics: metrics,
+	}, nil
+}
+
+// newRemoteWriteClient creates a Prometheus remote write client for the given settings.
+func newRemoteWriteClient(settings setting.RecordingRuleSettings, userAgent string, httpClientProvider HttpClientProvider, tracer tracing.Tracer) (promremote.Client, error) {
 	if err := validateSettings(settings); err != nil {
 		return nil, err
 	}
@@ -132,22 +146,13 @@ func NewPrometheusWriter(
 	}
 
 	clientCfg := promremote.NewConfig(
-		promremote.UserAgent("grafana-recording-rule"),
+		promremote.UserAgent(userAgent),
 		promremote.WriteURLOption(settings.URL),
 		promremote.HTTPClientTimeoutOption(settings.Timeout),
 		promremote.HTTPClientOption(cl),
 	)
 
-	client, err := promremote.NewClient(clientCfg)
-	if err != nil {
-		return nil, err
-	}
-
-	return &PrometheusWriter{
-		client:  client,
-		logger:  l,
-		metrics: metrics,
-	}, nil
+	return promremote.NewClient(clientCfg)
 }
 
 func validateSettings(settings setting.RecordingRuleSettings) error {
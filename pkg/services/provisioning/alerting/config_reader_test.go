@@ -18,6 +18,7 @@ const (
 	testFileCorrectPropertiesWithOrg    = "./testdata/alert_rules/correct-properties-with-org"
 	testFileMultipleRules               = "./testdata/alert_rules/multiple-rules"
 	testFileMultipleFiles               = "./testdata/alert_rules/multiple-files"
+	testFilePrometheusRules             = "./testdata/alert_rules/prometheus-rules"
 	testFileCorrectProperties_cp        = "./testdata/contact_points/correct-properties"
 	testFileCorrectPropertiesWithOrg_cp = "./testdata/contact_points/correct-properties-with-org"
 	testFileEmptyUID                    = "./testdata/contact_points/empty-uid"
@@ -74,6 +75,17 @@ func TestConfigReader(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, ruleFiles[0].Groups, 2)
 	})
+	t.Run("the config reader should convert prometheus rule groups", func(t *testing.T) {
+		ruleFiles, err := configReader.readConfig(ctx, testFilePrometheusRules)
+		require.NoError(t, err)
+		require.Len(t, ruleFiles[0].Groups, 1)
+		group := ruleFiles[0].Groups[0]
+		require.Equal(t, int64(1337), group.OrgID)
+		require.Equal(t, "Prometheus", group.FolderFullpath)
+		require.Len(t, group.Rules, 2)
+		require.Equal(t, "HighCPU", group.Rules[0].Title)
+		require.Equal(t, "instance:node_cpu:rate5m", group.Rules[1].Record.Metric)
+	})
 	t.Run("the config reader should support .yaml,.yml and .json files", func(t *testing.T) {
 		ruleFiles, err := configReader.readConfig(ctx, testFileSupportedFiletypes)
 		require.NoError(t, err)
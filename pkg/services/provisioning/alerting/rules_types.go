@@ -1,6 +1,8 @@
 package alerting
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,9 +10,12 @@ import (
 	"time"
 
 	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
 	"github.com/grafana/grafana/pkg/services/provisioning/values"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -71,11 +76,13 @@ type AlertRuleV1 struct {
 	NoDataState          values.StringValue      `json:"noDataState" yaml:"noDataState"`
 	ExecErrState         values.StringValue      `json:"execErrState" yaml:"execErrState"`
 	For                  values.StringValue      `json:"for" yaml:"for"`
+	KeepFiringFor        values.StringValue      `json:"keepFiringFor" yaml:"keepFiringFor"`
 	Annotations          values.StringMapValue   `json:"annotations" yaml:"annotations"`
 	Labels               values.StringMapValue   `json:"labels" yaml:"labels"`
 	IsPaused             values.BoolValue        `json:"isPaused" yaml:"isPaused"`
 	NotificationSettings *NotificationSettingsV1 `json:"notification_settings" yaml:"notification_settings"`
 	Record               *RecordV1               `json:"record" yaml:"record"`
+	DependsOn            []values.StringValue    `json:"dependsOn" yaml:"dependsOn"`
 }
 
 func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
@@ -94,6 +101,13 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
 	}
 	alertRule.For = time.Duration(duration)
+	if keepFiringFor := strings.TrimSpace(rule.KeepFiringFor.Value()); keepFiringFor != "" {
+		duration, err := model.ParseDuration(keepFiringFor)
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+		}
+		alertRule.KeepFiringFor = time.Duration(duration)
+	}
 	dashboardUID := rule.DashboardUID.Value()
 	alertRule.DashboardUID = &dashboardUID
 	panelID := rule.PanelID.Value()
@@ -147,6 +161,12 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 		}
 		alertRule.Record = &record
 	}
+	for _, uid := range rule.DependsOn {
+		if uid.Value() == "" {
+			continue
+		}
+		alertRule.DependsOn = append(alertRule.DependsOn, uid.Value())
+	}
 	return alertRule, nil
 }
 
@@ -266,3 +286,69 @@ func (record *RecordV1) mapToModel() (models.Record, error) {
 		From:   record.From.Value(),
 	}, nil
 }
+
+// PrometheusRulesV1 are rule groups in the Prometheus rule file format that are provisioned as Grafana-managed
+// alert and recording rules querying the data source.
+type PrometheusRulesV1 struct {
+	OrgID          values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Folder         values.StringValue `json:"folder" yaml:"folder"`
+	DatasourceUID  values.StringValue `json:"datasourceUid" yaml:"datasourceUid"`
+	DatasourceType values.StringValue `json:"datasourceType" yaml:"datasourceType"`
+	Groups         yaml.Node          `json:"groups" yaml:"groups"`
+}
+
+func (promRulesV1 *PrometheusRulesV1) MapToModel() ([]models.AlertRuleGroupWithFolderFullpath, error) {
+	orgID := promRulesV1.OrgID.Value()
+	if orgID < 1 {
+		orgID = 1
+	}
+	folder := promRulesV1.Folder.Value()
+	if strings.TrimSpace(folder) == "" {
+		return nil, errors.New("prometheus rules have no folder set")
+	}
+	converter, err := prom.NewConverter(prom.Config{
+		DatasourceUID:   promRulesV1.DatasourceUID.Value(),
+		DatasourceType:  promRulesV1.DatasourceType.Value(),
+		DefaultInterval: setting.DefaultRuleEvaluationInterval,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the groups are encoded again, so that they are parsed and validated like a Prometheus rule file
+	content, err := yaml.Marshal(map[string]*yaml.Node{"groups": &promRulesV1.Groups})
+	if err != nil {
+		return nil, err
+	}
+	groups, ruleErrs, err := converter.Convert(orgID, "", content)
+	if err != nil {
+		return nil, err
+	}
+	if len(ruleErrs) > 0 {
+		errs := make([]error, 0, len(ruleErrs))
+		for _, ruleErr := range ruleErrs {
+			errs = append(errs, ruleErr)
+		}
+		return nil, errors.Join(errs...)
+	}
+
+	result := make([]models.AlertRuleGroupWithFolderFullpath, 0, len(groups))
+	for i := range groups {
+		group := &groups[i]
+		for j := range group.Rules {
+			group.Rules[j].UID = prometheusRuleUID(orgID, folder, group.Rules[j].Title)
+		}
+		result = append(result, models.AlertRuleGroupWithFolderFullpath{
+			AlertRuleGroup: group,
+			OrgID:          orgID,
+			FolderFullpath: folder,
+		})
+	}
+	return result, nil
+}
+
+// prometheusRuleUID returns the UID of a provisioned Prometheus rule. The UID is derived from the title of the rule,
+// which is unique in the folder, so that the rule is updated when the file is provisioned again.
+func prometheusRuleUID(orgID int64, folder, title string) string {
+	h := sha256.Sum256([]byte(fmt.Sprintf("%d\x00%s\x00%s", orgID, folder, title)))
+	return hex.EncodeToString(h[:])[:util.MaxUIDLength]
+}
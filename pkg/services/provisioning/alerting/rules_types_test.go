@@ -10,6 +10,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/provisioning/values"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -127,6 +128,24 @@ func TestRules(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, 48*time.Hour, ruleMapped.For)
 	})
+	t.Run("a rule with a keep firing for duration should work", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.KeepFiringFor = stringToStringValue("5m")
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, 5*time.Minute, ruleMapped.KeepFiringFor)
+
+		rule.KeepFiringFor = stringToStringValue("5x")
+		_, err = rule.mapToModel(1)
+		require.Error(t, err)
+	})
+	t.Run("a rule with dependencies should work", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.DependsOn = []values.StringValue{stringToStringValue("upstream"), stringToStringValue("")}
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, []string{"upstream"}, ruleMapped.DependsOn)
+	})
 	t.Run("a rule with out a condition should error", func(t *testing.T) {
 		rule := validRuleV1(t)
 		rule.Condition = values.StringValue{}
@@ -294,6 +313,66 @@ func TestNotificationsSettingsV1MapToModel(t *testing.T) {
 	}
 }
 
+func TestPrometheusRulesV1(t *testing.T) {
+	parse := func(t *testing.T, content string) PrometheusRulesV1 {
+		t.Helper()
+		var rules PrometheusRulesV1
+		require.NoError(t, yaml.Unmarshal([]byte(content), &rules))
+		return rules
+	}
+
+	t.Run("rules get stable UIDs derived from the org, folder and title", func(t *testing.T) {
+		content := `
+folder: Prometheus
+datasourceUid: prometheus
+groups:
+  - name: node
+    rules:
+      - alert: HighCPU
+        expr: node_cpu_usage > 0.9
+      - alert: Down
+        expr: up == 0
+`
+		rules := parse(t, content)
+		groups, err := rules.MapToModel()
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		require.Equal(t, int64(1), groups[0].OrgID)
+		require.Equal(t, int64(setting.DefaultRuleEvaluationInterval.Seconds()), groups[0].Interval)
+		uids := []string{groups[0].Rules[0].UID, groups[0].Rules[1].UID}
+		require.NotEqual(t, uids[0], uids[1])
+		require.Len(t, uids[0], util.MaxUIDLength)
+
+		rules = parse(t, content)
+		again, err := rules.MapToModel()
+		require.NoError(t, err)
+		require.Equal(t, uids, []string{again[0].Rules[0].UID, again[0].Rules[1].UID})
+	})
+	t.Run("prometheus rules without a folder should error", func(t *testing.T) {
+		rules := parse(t, "datasourceUid: prometheus\ngroups: []")
+		_, err := rules.MapToModel()
+		require.Error(t, err)
+	})
+	t.Run("prometheus rules without a data source should error", func(t *testing.T) {
+		rules := parse(t, "folder: Prometheus\ngroups: []")
+		_, err := rules.MapToModel()
+		require.Error(t, err)
+	})
+	t.Run("prometheus rules that cannot be converted should error", func(t *testing.T) {
+		rules := parse(t, `
+folder: Prometheus
+datasourceUid: prometheus
+groups:
+  - name: node
+    rules:
+      - alert: Invalid
+        expr: "sum("
+`)
+		_, err := rules.MapToModel()
+		require.ErrorContains(t, err, `rule 1 "Invalid"`)
+	})
+}
+
 func validRuleGroupV1(t *testing.T) AlertRuleGroupV1 {
 	t.Helper()
 	var (
@@ -19,6 +19,8 @@ type parsedQuery struct {
 
 type parsedRequest struct {
 	hasExpression bool
+	explain       bool
+	explainHidden bool
 	parsedQueries map[string][]parsedQuery
 	dsTypes       map[string]bool
 }
@@ -201,7 +201,9 @@ func buildErrorResponses(err error, queries []*simplejson.Json) splitResponse {
 // handleExpressions handles POST /api/ds/query when there is an expression.
 func (s *ServiceImpl) handleExpressions(ctx context.Context, user identity.Requester, parsedReq *parsedRequest) (*backend.QueryDataResponse, error) {
 	exprReq := expr.Request{
-		Queries: []expr.Query{},
+		Explain:       parsedReq.explain,
+		ExplainHidden: parsedReq.explainHidden,
+		Queries:       []expr.Query{},
 	}
 
 	if user != nil { // for passthrough authentication, SSE does not authenticate
@@ -280,6 +282,8 @@ func (s *ServiceImpl) parseMetricRequest(ctx context.Context, user identity.Requ
 	timeRange := gtime.NewTimeRange(reqDTO.From, reqDTO.To)
 	req := &parsedRequest{
 		hasExpression: false,
+		explain:       reqDTO.Explain,
+		explainHidden: reqDTO.ExplainHidden,
 		parsedQueries: make(map[string][]parsedQuery),
 		dsTypes:       make(map[string]bool),
 	}
@@ -0,0 +1,64 @@
+package migrations
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addLivePipelineMigrations(mg *migrator.Migrator) {
+	channelRuleV1 := migrator.Table{
+		Name: "live_channel_rule",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "pattern", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "settings", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "pattern"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create live_channel_rule table", migrator.NewAddTableMigration(channelRuleV1))
+	mg.AddMigration("add unique index live_channel_rule.org_id-pattern", migrator.NewAddIndexMigration(channelRuleV1, channelRuleV1.Indices[0]))
+
+	channelRuleVersionV1 := migrator.Table{
+		Name: "live_channel_rule_version",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "pattern", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "settings", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "deleted", Type: migrator.DB_Bool, Nullable: false, Default: "0"},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "created_by", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "pattern", "version"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create live_channel_rule_version table", migrator.NewAddTableMigration(channelRuleVersionV1))
+	mg.AddMigration("add unique index live_channel_rule_version.org_id-pattern-version", migrator.NewAddIndexMigration(channelRuleVersionV1, channelRuleVersionV1.Indices[0]))
+
+	writeConfigV1 := migrator.Table{
+		Name: "live_write_config",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "settings", Type: migrator.DB_Text, Nullable: false},
+			{Name: "secure_settings", Type: migrator.DB_Text, Nullable: true},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create live_write_config table", migrator.NewAddTableMigration(writeConfigV1))
+	mg.AddMigration("add unique index live_write_config.org_id-uid", migrator.NewAddIndexMigration(writeConfigV1, writeConfigV1.Indices[0]))
+}
@@ -123,6 +123,13 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	accesscontrol.AddManagedFolderAlertingSilencesActionsMigrator(mg)
 
 	ualert.AddRecordingRuleColumns(mg)
+
+	ualert.AddKeepFiringForColumns(mg)
+	ualert.AddDependsOnColumns(mg)
+	ualert.AddStateHistoryTable(mg)
+	ualert.AddRuleTemplateMigrations(mg)
+
+	addLivePipelineMigrations(mg)
 }
 
 func addStarMigrations(mg *Migrator) {
@@ -0,0 +1,27 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddKeepFiringForColumns adds columns to alert_rule and alert_rule_version to store how long alerts keep firing,
+// and a column to alert_instance to store since when an alert keeps firing.
+func AddKeepFiringForColumns(mg *migrator.Migrator) {
+	mg.AddMigration("add keep_firing_for column to alert_rule table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+		Name:     "keep_firing_for",
+		Type:     migrator.DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add keep_firing_for column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name:     "keep_firing_for",
+		Type:     migrator.DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add keep_firing_since column to alert_instance table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_instance"}, &migrator.Column{
+		Name:     "keep_firing_since",
+		Type:     migrator.DB_BigInt,
+		Nullable: true,
+	}))
+}
@@ -0,0 +1,47 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddRuleTemplateMigrations creates the alert_rule_template table, and adds columns to alert_rule and alert_rule_version
+// to store the template a rule is derived from.
+func AddRuleTemplateMigrations(mg *migrator.Migrator) {
+	ruleTemplate := migrator.Table{
+		Name: "alert_rule_template",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "title", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "description", Type: migrator.DB_Text, Nullable: true},
+			{Name: "version", Type: migrator.DB_Int, Nullable: false, Default: "0"},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "parameters", Type: migrator.DB_Text, Nullable: true},
+			{Name: "rule_title", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "condition", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "data", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "no_data_state", Type: migrator.DB_NVarchar, Length: 15, Nullable: false, Default: "'NoData'"},
+			{Name: "exec_err_state", Type: migrator.DB_NVarchar, Length: 15, Nullable: false, Default: "'Alerting'"},
+			{Name: "for", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "annotations", Type: migrator.DB_Text, Nullable: true},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: true},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_rule_template table", migrator.NewAddTableMigration(ruleTemplate))
+	mg.AddMigration("add unique index in alert_rule_template on org_id and uid columns", migrator.NewAddIndexMigration(ruleTemplate, ruleTemplate.Indices[0]))
+
+	mg.AddMigration("add template_ref column to alert_rule table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+		Name:     "template_ref",
+		Type:     migrator.DB_Text,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add template_ref column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name:     "template_ref",
+		Type:     migrator.DB_Text,
+		Nullable: true,
+	}))
+}
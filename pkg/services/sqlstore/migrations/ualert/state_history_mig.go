@@ -0,0 +1,41 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddStateHistoryTable creates the alert_state_history table that is used by the "sql" state history backend.
+// Each row is a state transition of an alert instance. The table is not partitioned by time, since partitions are not
+// supported by all databases, instead the entries older than the retention are deleted in batches using the epoch index.
+func AddStateHistoryTable(mg *migrator.Migrator) {
+	stateHistory := migrator.Table{
+		Name: "alert_state_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "namespace_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "rule_group", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "dashboard_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: true},
+			{Name: "panel_id", Type: migrator.DB_BigInt, Nullable: true},
+			{Name: "fingerprint", Type: migrator.DB_NVarchar, Length: 16, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "rule_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_title", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "rule_condition", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "previous_state", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "current_state", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "state_error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "state_values", Type: migrator.DB_Text, Nullable: true},
+			{Name: "epoch", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "epoch"}, Type: migrator.IndexType},
+			{Cols: []string{"org_id", "rule_uid", "epoch"}, Type: migrator.IndexType},
+			{Cols: []string{"epoch"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_state_history table", migrator.NewAddTableMigration(stateHistory))
+	mg.AddMigration("add index in alert_state_history on org_id and epoch columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[0]))
+	mg.AddMigration("add index in alert_state_history on org_id, rule_uid and epoch columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[1]))
+	mg.AddMigration("add index in alert_state_history on epoch column", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[2]))
+}
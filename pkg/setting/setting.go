@@ -411,6 +411,26 @@ type Cfg struct {
 
 	// ExpressionsEnabled specifies whether expressions are enabled.
 	ExpressionsEnabled bool
+	// SQLExpressionEngine is the engine used to run SQL expressions, "embedded" or "duckdb".
+	SQLExpressionEngine string
+	// SQLExpressionMaxInputRows is the maximum number of input rows of a SQL expression, 0 means no limit.
+	SQLExpressionMaxInputRows int64
+	// SQLExpressionMaxOutputRows is the maximum number of rows returned by a SQL expression, 0 means no limit.
+	SQLExpressionMaxOutputRows int64
+	// SQLExpressionMaxInputBytes is the maximum size in bytes of the input of a SQL expression, 0 means no limit.
+	SQLExpressionMaxInputBytes int64
+	// ExpressionsQueryCacheEnabled enables the cache of datasource query results shared by expression requests.
+	ExpressionsQueryCacheEnabled bool
+	// ExpressionsQueryCacheTTL is how long the datasource query results are cached.
+	ExpressionsQueryCacheTTL time.Duration
+	// ExpressionsQueryCacheMaxBytes is the maximum size in bytes of the cached datasource query results.
+	ExpressionsQueryCacheMaxBytes int64
+	// ExpressionsQueryCacheTimeAlignment is the interval that the time ranges of queries are aligned to
+	// in the cache key, so that queries with almost the same time range share the cached results.
+	ExpressionsQueryCacheTimeAlignment time.Duration
+	// ExpressionsQueryCacheQueryTimeout is the timeout of the datasource queries shared by expression requests.
+	// The shared queries are not cancelled with the request that started them, since other requests wait for them.
+	ExpressionsQueryCacheQueryTimeout time.Duration
 
 	ImageUploadProvider string
 
@@ -427,6 +447,19 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LiveManagedStreamHistoryMaxAge is a maximum age of frames kept in the history
+	// of managed stream channels. 0 means no limit.
+	LiveManagedStreamHistoryMaxAge time.Duration
+	// LiveManagedStreamHistoryMaxRows is a maximum number of rows kept in the history
+	// of managed stream channels. 0 disables the history.
+	LiveManagedStreamHistoryMaxRows int
+	// LiveMQTTEnabled enables MQTT listener which receives data for Grafana Live.
+	LiveMQTTEnabled bool
+	// LiveMQTTAddress is an address of the MQTT listener.
+	LiveMQTTAddress string
+	// LiveMQTTCertFile and LiveMQTTCertKey enable TLS for the MQTT listener.
+	LiveMQTTCertFile string
+	LiveMQTTCertKey  string
 
 	// Grafana.com URL, used for OAuth redirect.
 	GrafanaComURL string
@@ -741,6 +774,15 @@ func (cfg *Cfg) readAnnotationSettings() error {
 func (cfg *Cfg) readExpressionsSettings() {
 	expressions := cfg.Raw.Section("expressions")
 	cfg.ExpressionsEnabled = expressions.Key("enabled").MustBool(true)
+	cfg.SQLExpressionEngine = valueAsString(expressions, "sql_engine", "embedded")
+	cfg.SQLExpressionMaxInputRows = expressions.Key("sql_max_input_rows").MustInt64(100000)
+	cfg.SQLExpressionMaxOutputRows = expressions.Key("sql_max_output_rows").MustInt64(100000)
+	cfg.SQLExpressionMaxInputBytes = expressions.Key("sql_max_input_bytes").MustInt64(100 * 1024 * 1024)
+	cfg.ExpressionsQueryCacheEnabled = expressions.Key("query_cache_enabled").MustBool(false)
+	cfg.ExpressionsQueryCacheTTL = expressions.Key("query_cache_ttl").MustDuration(10 * time.Second)
+	cfg.ExpressionsQueryCacheMaxBytes = expressions.Key("query_cache_max_bytes").MustInt64(64 * 1024 * 1024)
+	cfg.ExpressionsQueryCacheTimeAlignment = expressions.Key("query_cache_time_alignment").MustDuration(time.Second)
+	cfg.ExpressionsQueryCacheQueryTimeout = expressions.Key("query_cache_query_timeout").MustDuration(30 * time.Second)
 }
 
 type AnnotationCleanupSettings struct {
@@ -2004,6 +2046,23 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	cfg.LiveHAEngineAddress = section.Key("ha_engine_address").MustString("127.0.0.1:6379")
 	cfg.LiveHAEnginePassword = section.Key("ha_engine_password").MustString("")
 
+	var err error
+	cfg.LiveManagedStreamHistoryMaxAge, err = gtime.ParseDuration(valueAsString(section, "managed_stream_history_max_age", "5m"))
+	if err != nil {
+		return fmt.Errorf("invalid value for [live] managed_stream_history_max_age: %w", err)
+	}
+	if cfg.LiveManagedStreamHistoryMaxAge < 0 {
+		return fmt.Errorf("unexpected value %s for [live] managed_stream_history_max_age", cfg.LiveManagedStreamHistoryMaxAge)
+	}
+	cfg.LiveManagedStreamHistoryMaxRows = section.Key("managed_stream_history_max_rows").MustInt(1000)
+	if cfg.LiveManagedStreamHistoryMaxRows < 0 {
+		return fmt.Errorf("unexpected value %d for [live] managed_stream_history_max_rows", cfg.LiveManagedStreamHistoryMaxRows)
+	}
+	cfg.LiveMQTTEnabled = section.Key("mqtt_enabled").MustBool(false)
+	cfg.LiveMQTTAddress = section.Key("mqtt_address").MustString(":1883")
+	cfg.LiveMQTTCertFile = section.Key("mqtt_cert_file").MustString("")
+	cfg.LiveMQTTCertKey = section.Key("mqtt_cert_key").MustString("")
+
 	allowedOrigins := section.Key("allowed_origins").MustString("")
 	origins := strings.Split(allowedOrigins, ",")
 
@@ -2016,7 +2075,7 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 		originPatterns = append(originPatterns, originPattern)
 	}
 
-	_, err := GetAllowedOriginGlobs(originPatterns)
+	_, err = GetAllowedOriginGlobs(originPatterns)
 	if err != nil {
 		return err
 	}
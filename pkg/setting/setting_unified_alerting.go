@@ -65,6 +65,7 @@ const (
 	lokiDefaultMaxQueryLength      = 721 * time.Hour // 30d1h, matches the default value in Loki
 	defaultRecordingRequestTimeout = 10 * time.Second
 	lokiDefaultMaxQuerySize        = 65536 // 64kb
+	sqlStateHistoryDefaultMaxAge   = 30 * 24 * time.Hour
 )
 
 type UnifiedAlertingSettings struct {
@@ -88,6 +89,7 @@ type UnifiedAlertingSettings struct {
 	HARedisMaxConns                int
 	HARedisTLSEnabled              bool
 	HARedisTLSConfig               dstls.ClientConfig
+	HAEvaluationSharding           bool
 	MaxAttempts                    int64
 	MinInterval                    time.Duration
 	EvaluationTimeout              time.Duration
@@ -108,6 +110,7 @@ type UnifiedAlertingSettings struct {
 	StateHistory                  UnifiedAlertingStateHistorySettings
 	RemoteAlertmanager            RemoteAlertmanagerSettings
 	RecordingRules                RecordingRuleSettings
+	StateMetrics                  AlertStateMetricsSettings
 
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
 	MaxStateSaveConcurrency   int
@@ -129,6 +132,14 @@ type RecordingRuleSettings struct {
 	Timeout           time.Duration
 }
 
+// AlertStateMetricsSettings configures the remote write of the ALERTS and ALERTS_FOR_STATE series
+// of the alert instances of Grafana-managed alert rules.
+type AlertStateMetricsSettings struct {
+	Enabled bool
+	// RemoteWrite is the target of the series. It is configured in the same way as the target of recording rules.
+	RemoteWrite RecordingRuleSettings
+}
+
 // RemoteAlertmanagerSettings contains the configuration needed
 // to disable the internal Alertmanager and use an external one instead.
 type RemoteAlertmanagerSettings struct {
@@ -166,6 +177,8 @@ type UnifiedAlertingStateHistorySettings struct {
 	MultiPrimary          string
 	MultiSecondaries      []string
 	ExternalLabels        map[string]string
+	// SQLMaxAge is how long state history is kept by the "sql" backend. Zero keeps it forever.
+	SQLMaxAge time.Duration
 }
 
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
@@ -272,6 +285,7 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	uaCfg.HARedisTLSConfig.InsecureSkipVerify = ua.Key("ha_redis_tls_insecure_skip_verify").MustBool(false)
 	uaCfg.HARedisTLSConfig.CipherSuites = ua.Key("ha_redis_tls_cipher_suites").MustString("")
 	uaCfg.HARedisTLSConfig.MinVersion = ua.Key("ha_redis_tls_min_version").MustString("")
+	uaCfg.HAEvaluationSharding = ua.Key("ha_evaluation_sharding").MustBool(false)
 
 	// TODO load from ini file
 	uaCfg.DefaultConfiguration = alertmanagerDefaultConfiguration
@@ -412,6 +426,7 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		MultiPrimary:          stateHistory.Key("primary").MustString(""),
 		MultiSecondaries:      splitTrim(stateHistory.Key("secondaries").MustString(""), ","),
 		ExternalLabels:        stateHistoryLabels.KeysHash(),
+		SQLMaxAge:             stateHistory.Key("sql_max_age").MustDuration(sqlStateHistoryDefaultMaxAge),
 	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
@@ -432,6 +447,29 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 
 	uaCfg.RecordingRules = uaCfgRecordingRules
 
+	sm := iniFile.Section("alert_state_metrics")
+	uaCfgStateMetrics := AlertStateMetricsSettings{
+		Enabled: sm.Key("enabled").MustBool(false),
+		RemoteWrite: RecordingRuleSettings{
+			URL:               sm.Key("url").MustString(""),
+			BasicAuthUsername: sm.Key("basic_auth_username").MustString(""),
+			BasicAuthPassword: sm.Key("basic_auth_password").MustString(""),
+			Timeout:           sm.Key("timeout").MustDuration(defaultRecordingRequestTimeout),
+		},
+	}
+	if uaCfgStateMetrics.Enabled && uaCfgStateMetrics.RemoteWrite.URL == "" {
+		return fmt.Errorf("setting 'url' in section 'alert_state_metrics' is required if alert state metrics are enabled")
+	}
+
+	smHeaders := iniFile.Section("alert_state_metrics.custom_headers")
+	smHeadersKeys := smHeaders.Keys()
+	uaCfgStateMetrics.RemoteWrite.CustomHeaders = make(map[string]string, len(smHeadersKeys))
+	for _, key := range smHeadersKeys {
+		uaCfgStateMetrics.RemoteWrite.CustomHeaders[key.Name()] = key.Value()
+	}
+
+	uaCfg.StateMetrics = uaCfgStateMetrics
+
 	uaCfg.MaxStateSaveConcurrency = ua.Key("max_state_save_concurrency").MustInt(1)
 
 	uaCfg.StatePeriodicSaveInterval, err = gtime.ParseDuration(valueAsString(ua, "state_periodic_save_interval", (time.Minute * 5).String()))
@@ -73,6 +73,32 @@ func TestCfg_ReadUnifiedAlertingSettings(t *testing.T) {
 			require.Equal(t, SchedulerBaseInterval, cfg.UnifiedAlerting.BaseInterval)
 		})
 	})
+
+	t.Run("should read alert state metrics settings", func(t *testing.T) {
+		require.False(t, cfg.UnifiedAlerting.StateMetrics.Enabled)
+
+		s, err := cfg.Raw.NewSection("alert_state_metrics")
+		require.NoError(t, err)
+		_, err = s.NewKey("enabled", "true")
+		require.NoError(t, err)
+
+		t.Run("and fail if url is missing", func(t *testing.T) {
+			require.Error(t, cfg.ReadUnifiedAlertingSettings(cfg.Raw))
+		})
+
+		_, err = s.NewKey("url", "http://localhost:9090/api/v1/write")
+		require.NoError(t, err)
+		headers, err := cfg.Raw.NewSection("alert_state_metrics.custom_headers")
+		require.NoError(t, err)
+		_, err = headers.NewKey("X-Scope-OrgID", "tenant")
+		require.NoError(t, err)
+
+		require.NoError(t, cfg.ReadUnifiedAlertingSettings(cfg.Raw))
+		require.True(t, cfg.UnifiedAlerting.StateMetrics.Enabled)
+		require.Equal(t, "http://localhost:9090/api/v1/write", cfg.UnifiedAlerting.StateMetrics.RemoteWrite.URL)
+		require.Equal(t, 10*time.Second, cfg.UnifiedAlerting.StateMetrics.RemoteWrite.Timeout)
+		require.Equal(t, map[string]string{"X-Scope-OrgID": "tenant"}, cfg.UnifiedAlerting.StateMetrics.RemoteWrite.CustomHeaders)
+	})
 }
 
 func TestUnifiedAlertingSettings(t *testing.T) {